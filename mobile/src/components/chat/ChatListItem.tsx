@@ -40,7 +40,7 @@ function ChatListItemInner({ item, onPress, onLongPress }: Props) {
   // Time
   const timeText = lastMessage ? formatChatListTime(lastMessage.createdAt, t) : '';
 
-  const isPinned = chat.pinnedAt !== null;
+  const isPinned = item.settings.pinnedAt !== null;
 
   return (
     <Pressable
@@ -135,7 +135,7 @@ export const ChatListItem = React.memo(ChatListItemInner, (prev, next) => {
     prevChat.chat.id === nextChat.chat.id &&
     prevChat.unreadCount === nextChat.unreadCount &&
     prevChat.isOnline === nextChat.isOnline &&
-    prevChat.chat.pinnedAt === nextChat.chat.pinnedAt &&
+    prevChat.settings.pinnedAt === nextChat.settings.pinnedAt &&
     prevChat.lastMessage?.id === nextChat.lastMessage?.id &&
     prevChat.lastMessage?.content === nextChat.lastMessage?.content
   );
@@ -21,10 +21,13 @@ import { chatsApi } from '@/services/api/chats';
 import { formatLastSeen } from '@/lib/timeFormat';
 import { useTranslation } from 'react-i18next';
 import { colors, fontSize, fontFamily, spacing } from '@/theme';
-import type { MemberInfo, GroupInfo } from '@/types/chat';
+import type { MemberInfo, GroupInfo, MemberRole } from '@/types/chat';
 
 type Props = NativeStackScreenProps<ChatStackParamList, 'ChatInfo'>;
 
+// The group owner has every admin permission
+const isAdminOrOwner = (role?: MemberRole) => role === 'admin' || role === 'owner';
+
 export function ChatInfoScreen({ route, navigation }: Props) {
   const { chatId, chatType } = route.params;
   const { t } = useTranslation();
@@ -66,8 +69,8 @@ export function ChatInfoScreen({ route, navigation }: Props) {
   }, [navigation, isGroup]);
 
   const currentMember = groupInfo?.members.find((m) => m.user.id === currentUserId);
-  const isAdmin = currentMember?.role === 'admin';
-  const isCreator = groupInfo?.chat.createdBy === currentUserId;
+  const isAdmin = isAdminOrOwner(currentMember?.role);
+  const isOwner = currentMember?.role === 'owner';
 
   // --- Group Admin Actions ---
 
@@ -190,7 +193,8 @@ export function ChatInfoScreen({ route, navigation }: Props) {
   };
 
   const handleMemberPress = (member: MemberInfo) => {
-    if (!isAdmin || member.user.id === currentUserId) return;
+    // The owner can be neither removed nor demoted
+    if (!isAdmin || member.user.id === currentUserId || member.role === 'owner') return;
 
     const options: Array<{
       text: string;
@@ -198,7 +202,7 @@ export function ChatInfoScreen({ route, navigation }: Props) {
       style?: 'cancel' | 'destructive';
     }> = [];
 
-    if (member.role !== 'admin') {
+    if (!isAdminOrOwner(member.role)) {
       options.push({
         text: t('group.promoteAdmin'),
         onPress: () => handlePromoteToAdmin(member),
@@ -294,7 +298,11 @@ export function ChatInfoScreen({ route, navigation }: Props) {
             {isMe ? ` ${t('common.you')}` : ''}
           </Text>
           <Text style={styles.memberRole}>
-            {item.role === 'admin' ? t('group.admin') : t('group.member')}
+            {item.role === 'owner'
+              ? t('group.owner')
+              : item.role === 'admin'
+                ? t('group.admin')
+                : t('group.member')}
           </Text>
         </View>
       </Pressable>
@@ -399,12 +407,12 @@ export function ChatInfoScreen({ route, navigation }: Props) {
 
         {/* Actions */}
         <View style={styles.section}>
-          {!isCreator && (
+          {!isOwner && (
             <Pressable style={styles.dangerRow} onPress={handleLeaveGroup}>
               <Text style={styles.dangerText}>{t('group.leaveGroup')}</Text>
             </Pressable>
           )}
-          {isCreator && (
+          {isOwner && (
             <Pressable style={styles.dangerRow} onPress={handleDeleteGroup}>
               <Text style={styles.dangerText}>{t('group.deleteGroup')}</Text>
             </Pressable>
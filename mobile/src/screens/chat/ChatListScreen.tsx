@@ -60,7 +60,7 @@ export function ChatListScreen({ navigation }: Props) {
 
   const handleChatLongPress = useCallback(
     (item: ChatListItemType) => {
-      const isPinned = item.chat.pinnedAt !== null;
+      const isPinned = item.settings.pinnedAt !== null;
       const options = [
         {
           text: isPinned ? t('chat.unpinChat') : t('chat.pinChat'),
@@ -87,10 +87,13 @@ class SyncEngineService {
             ? new Date(item.lastMessage.createdAt).getTime()
             : null,
           unread_count: item.unreadCount,
-          is_muted: 0,
-          is_archived: 0,
-          pinned_at: item.chat.pinnedAt
-            ? new Date(item.chat.pinnedAt).getTime()
+          is_muted:
+            item.settings.mutedUntil && new Date(item.settings.mutedUntil).getTime() > Date.now()
+              ? 1
+              : 0,
+          is_archived: item.settings.archivedAt ? 1 : 0,
+          pinned_at: item.settings.pinnedAt
+            ? new Date(item.settings.pinnedAt).getTime()
             : null,
           synced_at: Date.now(),
           created_at: new Date(item.chat.createdAt).getTime(),
@@ -149,7 +149,8 @@ describe('SyncEngine', () => {
     it('upserts chats from server', async () => {
       const chatItems = [
         {
-          chat: { id: 'c1', type: 'personal', name: 'Alice', icon: null, pinnedAt: null, createdAt: '2024-01-01T00:00:00Z' },
+          chat: { id: 'c1', type: 'personal', name: 'Alice', icon: null, createdAt: '2024-01-01T00:00:00Z' },
+          settings: { pinnedAt: null, archivedAt: null, mutedUntil: null },
           lastMessage: { content: 'Hi', createdAt: '2024-01-01T00:00:00Z' },
           unreadCount: 2,
         },
@@ -168,7 +169,8 @@ describe('SyncEngine', () => {
     it('handles chat with no lastMessage', async () => {
       const chatItems = [
         {
-          chat: { id: 'c1', type: 'personal', name: 'Alice', icon: null, pinnedAt: null, createdAt: '2024-01-01T00:00:00Z' },
+          chat: { id: 'c1', type: 'personal', name: 'Alice', icon: null, createdAt: '2024-01-01T00:00:00Z' },
+          settings: { pinnedAt: null, archivedAt: null, mutedUntil: null },
           lastMessage: null,
           unreadCount: 0,
         },
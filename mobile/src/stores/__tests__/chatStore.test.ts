@@ -14,7 +14,8 @@ import { chatsApi } from '@/services/api/chats';
 const mockChatsApi = chatsApi as jest.Mocked<typeof chatsApi>;
 
 const makeChatItem = (id: string, name: string, unread = 0) => ({
-  chat: { id, type: 'personal' as const, name, icon: null, pinnedAt: null, createdAt: '2024-01-01' },
+  chat: { id, type: 'personal' as const, name, icon: null, createdAt: '2024-01-01' },
+  settings: { pinnedAt: null, archivedAt: null, mutedUntil: null },
   lastMessage: null,
   unreadCount: unread,
   otherUser: null,
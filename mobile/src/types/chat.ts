@@ -3,7 +3,7 @@
 export type ChatType = 'personal' | 'group';
 export type MessageType = 'text' | 'image' | 'file' | 'document_card' | 'system';
 export type DeliveryStatus = 'sent' | 'delivered' | 'read';
-export type MemberRole = 'admin' | 'member';
+export type MemberRole = 'owner' | 'admin' | 'member';
 
 export interface User {
   id: string;
@@ -21,7 +21,6 @@ export interface Chat {
   icon: string;
   description: string;
   createdBy: string;
-  pinnedAt: string | null;
   createdAt: string;
   updatedAt: string;
 }
@@ -39,8 +38,17 @@ export interface Message {
   createdAt: string;
 }
 
+// Per-member settings; each member pins, archives and mutes a chat independently
+export interface ChatSettings {
+  pinnedAt: string | null;
+  archivedAt: string | null;
+  mutedUntil: string | null;
+  notificationSound?: string;
+}
+
 export interface ChatListItem {
   chat: Chat;
+  settings: ChatSettings;
   lastMessage: Message | null;
   unreadCount: number;
   otherUser: User | null;
@@ -53,10 +53,16 @@ func main() {
 	}()
 
 	hub := ws.NewHub()
+	if err := hub.SetCluster(ws.NewRedisCluster(redisClient, cfg.NodeID, 0)); err != nil {
+		log.Fatal().Err(err).Msg("failed to join websocket cluster")
+	}
+	hub.SetEventLog(ws.NewRedisEventLog(redisClient, 0, 0))
 	go hub.Run()
 
 	deps := handler.NewDependencies(cfg, dbPool, redisClient, hub)
 	r := handler.NewRouter(cfg, deps)
+	go deps.ScheduledDispatcher.Run()
+	go deps.MessageReaper.Run()
 
 	srv := &http.Server{
 		Addr:         ":" + cfg.Port,
@@ -81,6 +87,11 @@ func main() {
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	deps.ScheduledDispatcher.Stop()
+	deps.MessageReaper.Stop()
+	if err := deps.WSHandler.FlushDocuments(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("failed to save document edits")
+	}
 	hub.Shutdown()
 
 	if err := srv.Shutdown(shutdownCtx); err != nil {
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 // Config holds all configuration values for the application.
@@ -32,6 +35,21 @@ type Config struct {
 
 	// CORS configuration
 	CORSOrigins string // comma-separated allowed origins
+
+	// MessageEditWindow is how long after sending a message its sender may edit it
+	MessageEditWindow time.Duration
+
+	// ScheduledDispatchInterval is how often each replica looks for due scheduled messages
+	ScheduledDispatchInterval time.Duration
+
+	// MessageReapInterval is how often each replica deletes expired disappearing messages
+	MessageReapInterval time.Duration
+
+	// SessionMode is "single" (one device per user) or "multi" (concurrent devices)
+	SessionMode string
+
+	// NodeID identifies this replica in the WebSocket cluster (defaults to hostname plus a random suffix)
+	NodeID string
 }
 
 // Load reads configuration from environment variables and returns a Config.
@@ -54,8 +72,13 @@ func Load() (*Config, error) {
 		S3SecretKey: getEnv("S3_SECRET_KEY", "minioadmin"),
 		S3Region:    getEnv("S3_REGION", "us-east-1"),
 
-		FCMCredentialsFile: getEnv("FCM_CREDENTIALS_FILE", ""),
-		CORSOrigins:        getEnv("CORS_ALLOWED_ORIGINS", "*"),
+		FCMCredentialsFile:        getEnv("FCM_CREDENTIALS_FILE", ""),
+		CORSOrigins:               getEnv("CORS_ALLOWED_ORIGINS", "*"),
+		MessageEditWindow:         getEnvDuration("MESSAGE_EDIT_WINDOW", 15*time.Minute),
+		ScheduledDispatchInterval: getEnvDuration("SCHEDULED_DISPATCH_INTERVAL", 15*time.Second),
+		MessageReapInterval:       getEnvDuration("MESSAGE_REAP_INTERVAL", time.Minute),
+		SessionMode:               getEnv("SESSION_MODE", "single"),
+		NodeID:                    getEnv("NODE_ID", defaultNodeID()),
 	}
 
 	if err := cfg.validate(); err != nil {
@@ -94,9 +117,28 @@ func (c *Config) validate() error {
 	return nil
 }
 
+// defaultNodeID returns a replica ID that stays unique even when several
+// processes share a host.
+func defaultNodeID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "chatat"
+	}
+	return host + "-" + uuid.NewString()[:8]
+}
+
 func getEnv(key, fallback string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return fallback
 }
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return fallback
+}
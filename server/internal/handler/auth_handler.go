@@ -74,9 +74,11 @@ func (h *AuthHandler) SendOTP(w http.ResponseWriter, r *http.Request) {
 }
 
 type verifyOTPRequest struct {
-	Phone    string `json:"phone"`
-	Code     string `json:"code"`
-	DeviceID string `json:"deviceId"`
+	Phone      string `json:"phone"`
+	Code       string `json:"code"`
+	DeviceID   string `json:"deviceId"`
+	DeviceName string `json:"deviceName"`
+	Platform   string `json:"platform"`
 }
 
 type authResponse struct {
@@ -110,7 +112,11 @@ func (h *AuthHandler) VerifyOTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.completeAuth(w, r, normalized, req.DeviceID)
+	h.completeAuth(w, r, normalized, service.SessionDevice{
+		DeviceID:   req.DeviceID,
+		DeviceName: req.DeviceName,
+		Platform:   req.Platform,
+	})
 }
 
 type initReverseOTPRequest struct {
@@ -157,8 +163,10 @@ func (h *AuthHandler) InitReverseOTP(w http.ResponseWriter, r *http.Request) {
 }
 
 type checkReverseOTPRequest struct {
-	SessionID string `json:"sessionId"`
-	DeviceID  string `json:"deviceId"`
+	SessionID  string `json:"sessionId"`
+	DeviceID   string `json:"deviceId"`
+	DeviceName string `json:"deviceName"`
+	Platform   string `json:"platform"`
 }
 
 // CheckReverseOTP handles POST /api/v1/auth/reverse-otp/check
@@ -184,7 +192,11 @@ func (h *AuthHandler) CheckReverseOTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.completeAuth(w, r, result.Phone, req.DeviceID)
+	h.completeAuth(w, r, result.Phone, service.SessionDevice{
+		DeviceID:   req.DeviceID,
+		DeviceName: req.DeviceName,
+		Platform:   req.Platform,
+	})
 }
 
 type refreshRequest struct {
@@ -209,6 +221,13 @@ func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Record activity on the device session
+	if h.sessionService != nil {
+		if claims, err := h.tokenService.Validate(tokens.AccessToken); err == nil && claims != nil && claims.DeviceID != "" {
+			_ = h.sessionService.Touch(r.Context(), claims.UserID, claims.DeviceID, GetClientIP(r))
+		}
+	}
+
 	response.OK(w, tokens)
 }
 
@@ -227,17 +246,82 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 
 	_ = h.tokenService.Revoke(r.Context(), accessToken, req.RefreshToken)
 
-	// Also invalidate device session
+	// Also end the device session; tokens without a device end them all
 	userID, err := GetUserID(r)
 	if err == nil {
-		_ = h.sessionService.Invalidate(r.Context(), userID)
+		if deviceID := GetDeviceID(r); deviceID != "" {
+			_ = h.sessionService.Revoke(r.Context(), userID, deviceID)
+		} else {
+			_ = h.sessionService.Invalidate(r.Context(), userID)
+		}
+	}
+
+	response.NoContent(w)
+}
+
+// ListSessions handles GET /api/v1/auth/sessions
+func (h *AuthHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	sessions, err := h.sessionService.List(r.Context(), userID, GetDeviceID(r))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, sessions)
+}
+
+// RevokeSession handles DELETE /api/v1/auth/sessions/{deviceId}
+func (h *AuthHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	deviceID := GetPathParam(r, "deviceId")
+	if deviceID == "" {
+		response.Error(w, apperror.BadRequest("deviceId is required"))
+		return
+	}
+
+	if err := h.sessionService.Revoke(r.Context(), userID, deviceID); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// RevokeOtherSessions handles POST /api/v1/auth/sessions/revoke-others
+func (h *AuthHandler) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	deviceID := GetDeviceID(r)
+	if deviceID == "" {
+		response.Error(w, apperror.BadRequest("current session is not bound to a device"))
+		return
+	}
+
+	if err := h.sessionService.RevokeOthers(r.Context(), userID, deviceID); err != nil {
+		handleServiceError(w, err)
+		return
 	}
 
 	response.NoContent(w)
 }
 
 // completeAuth finds or creates user and returns tokens.
-func (h *AuthHandler) completeAuth(w http.ResponseWriter, r *http.Request, phoneNumber string, deviceID string) {
+func (h *AuthHandler) completeAuth(w http.ResponseWriter, r *http.Request, phoneNumber string, device service.SessionDevice) {
 	ctx := r.Context()
 	isNewUser := false
 
@@ -257,16 +341,17 @@ func (h *AuthHandler) completeAuth(w http.ResponseWriter, r *http.Request, phone
 		isNewUser = true
 	}
 
-	// Generate tokens
-	tokens, err := h.tokenService.Generate(ctx, user.ID)
+	// Generate tokens bound to the device
+	tokens, err := h.tokenService.GenerateForDevice(ctx, user.ID, device.DeviceID)
 	if err != nil {
 		response.Error(w, apperror.Internal(err))
 		return
 	}
 
 	// Register device session
-	if deviceID != "" {
-		_ = h.sessionService.Register(ctx, user.ID, deviceID, tokens.RefreshToken)
+	if device.DeviceID != "" {
+		device.IP = GetClientIP(r)
+		_ = h.sessionService.Register(ctx, user.ID, device)
 	}
 
 	response.OK(w, authResponse{
@@ -2,6 +2,7 @@ package handler_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -372,3 +374,86 @@ func TestAuthHandler_Logout(t *testing.T) {
 		assert.Equal(t, http.StatusNoContent, w.Code)
 	})
 }
+
+func authReqWithDevice(method, url string, userID uuid.UUID, deviceID string) *http.Request {
+	r := authReqWithUserID(method, url, nil, userID)
+	return r.WithContext(middleware.WithDeviceID(r.Context(), deviceID))
+}
+
+func TestAuthHandler_Logout_RevokesCurrentDevice(t *testing.T) {
+	h := newAuthHandler(nil, nil, &mockTokenService{}, &mockSessionService{err: apperror.NotFound("session", "phone")}, nil)
+	w := httptest.NewRecorder()
+	h.Logout(w, authReqWithDevice(http.MethodPost, "/api/v1/auth/logout", uuid.New(), "phone"))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+// --- Session management Tests ---
+
+func TestAuthHandler_ListSessions(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		sessions := []*service.Session{
+			{SessionDevice: service.SessionDevice{DeviceID: "phone", DeviceName: "Pixel"}, Current: true},
+			{SessionDevice: service.SessionDevice{DeviceID: "laptop", DeviceName: "Browser"}},
+		}
+		h := newAuthHandler(nil, nil, nil, &mockSessionService{sessions: sessions}, nil)
+		w := httptest.NewRecorder()
+		h.ListSessions(w, authReqWithDevice(http.MethodGet, "/api/v1/auth/sessions", uuid.New(), "phone"))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Pixel")
+		assert.Contains(t, w.Body.String(), "laptop")
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		h := newAuthHandler(nil, nil, nil, &mockSessionService{}, nil)
+		w := httptest.NewRecorder()
+		h.ListSessions(w, authReqNoUser(http.MethodGet, "/api/v1/auth/sessions", nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestAuthHandler_RevokeSession(t *testing.T) {
+	withDeviceParam := func(r *http.Request, deviceID string) *http.Request {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("deviceId", deviceID)
+		return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+	}
+
+	t.Run("success", func(t *testing.T) {
+		h := newAuthHandler(nil, nil, nil, &mockSessionService{}, nil)
+		w := httptest.NewRecorder()
+		r := withDeviceParam(authReqWithUserID(http.MethodDelete, "/api/v1/auth/sessions/laptop", nil, uuid.New()), "laptop")
+		h.RevokeSession(w, r)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		h := newAuthHandler(nil, nil, nil, &mockSessionService{err: apperror.NotFound("session", "laptop")}, nil)
+		w := httptest.NewRecorder()
+		r := withDeviceParam(authReqWithUserID(http.MethodDelete, "/api/v1/auth/sessions/laptop", nil, uuid.New()), "laptop")
+		h.RevokeSession(w, r)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		h := newAuthHandler(nil, nil, nil, &mockSessionService{}, nil)
+		w := httptest.NewRecorder()
+		h.RevokeSession(w, withDeviceParam(authReqNoUser(http.MethodDelete, "/api/v1/auth/sessions/laptop", nil), "laptop"))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestAuthHandler_RevokeOtherSessions(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		h := newAuthHandler(nil, nil, nil, &mockSessionService{}, nil)
+		w := httptest.NewRecorder()
+		h.RevokeOtherSessions(w, authReqWithDevice(http.MethodPost, "/api/v1/auth/sessions/revoke-others", uuid.New(), "phone"))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("token without device", func(t *testing.T) {
+		h := newAuthHandler(nil, nil, nil, &mockSessionService{}, nil)
+		w := httptest.NewRecorder()
+		h.RevokeOtherSessions(w, authReqWithUserID(http.MethodPost, "/api/v1/auth/sessions/revoke-others", nil, uuid.New()))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
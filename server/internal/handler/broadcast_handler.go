@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/otoritech/chatat/internal/service"
+	"github.com/otoritech/chatat/pkg/apperror"
+	"github.com/otoritech/chatat/pkg/response"
+)
+
+// BroadcastHandler handles broadcast list endpoints.
+type BroadcastHandler struct {
+	broadcastService service.BroadcastService
+}
+
+// NewBroadcastHandler creates a new broadcast handler.
+func NewBroadcastHandler(broadcastService service.BroadcastService) *BroadcastHandler {
+	return &BroadcastHandler{broadcastService: broadcastService}
+}
+
+// CreateList handles POST /api/v1/broadcast-lists
+func (h *BroadcastHandler) CreateList(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	var input service.CreateBroadcastListInput
+	if err := DecodeJSON(r, &input); err != nil {
+		response.Error(w, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	list, err := h.broadcastService.CreateList(r.Context(), userID, input)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.Created(w, list)
+}
+
+// ListLists handles GET /api/v1/broadcast-lists
+func (h *BroadcastHandler) ListLists(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	lists, err := h.broadcastService.ListLists(r.Context(), userID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, lists)
+}
+
+// GetList handles GET /api/v1/broadcast-lists/{listId}
+func (h *BroadcastHandler) GetList(w http.ResponseWriter, r *http.Request) {
+	userID, listID, ok := broadcastListParams(w, r)
+	if !ok {
+		return
+	}
+
+	list, err := h.broadcastService.GetList(r.Context(), listID, userID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, list)
+}
+
+// RenameList handles PUT /api/v1/broadcast-lists/{listId}
+func (h *BroadcastHandler) RenameList(w http.ResponseWriter, r *http.Request) {
+	userID, listID, ok := broadcastListParams(w, r)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Name string `json:"name"`
+	}
+	if err := DecodeJSON(r, &input); err != nil {
+		response.Error(w, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	list, err := h.broadcastService.RenameList(r.Context(), listID, userID, input.Name)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, list)
+}
+
+// DeleteList handles DELETE /api/v1/broadcast-lists/{listId}
+func (h *BroadcastHandler) DeleteList(w http.ResponseWriter, r *http.Request) {
+	userID, listID, ok := broadcastListParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.broadcastService.DeleteList(r.Context(), listID, userID); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// AddRecipients handles POST /api/v1/broadcast-lists/{listId}/recipients
+func (h *BroadcastHandler) AddRecipients(w http.ResponseWriter, r *http.Request) {
+	userID, listID, ok := broadcastListParams(w, r)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		RecipientIDs []uuid.UUID `json:"recipientIds"`
+	}
+	if err := DecodeJSON(r, &input); err != nil {
+		response.Error(w, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	list, err := h.broadcastService.AddRecipients(r.Context(), listID, userID, input.RecipientIDs)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, list)
+}
+
+// RemoveRecipient handles DELETE /api/v1/broadcast-lists/{listId}/recipients/{userId}
+func (h *BroadcastHandler) RemoveRecipient(w http.ResponseWriter, r *http.Request) {
+	userID, listID, ok := broadcastListParams(w, r)
+	if !ok {
+		return
+	}
+
+	recipientID, err := GetPathUUID(r, "userId")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid user id"))
+		return
+	}
+
+	if err := h.broadcastService.RemoveRecipient(r.Context(), listID, userID, recipientID); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// Send handles POST /api/v1/broadcast-lists/{listId}/send
+func (h *BroadcastHandler) Send(w http.ResponseWriter, r *http.Request) {
+	userID, listID, ok := broadcastListParams(w, r)
+	if !ok {
+		return
+	}
+
+	var input service.SendBroadcastInput
+	if err := DecodeJSON(r, &input); err != nil {
+		response.Error(w, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	broadcast, err := h.broadcastService.Send(r.Context(), listID, userID, input)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.Created(w, broadcast)
+}
+
+// ListBroadcasts handles GET /api/v1/broadcast-lists/{listId}/broadcasts
+func (h *BroadcastHandler) ListBroadcasts(w http.ResponseWriter, r *http.Request) {
+	userID, listID, ok := broadcastListParams(w, r)
+	if !ok {
+		return
+	}
+
+	broadcasts, err := h.broadcastService.ListBroadcasts(r.Context(), listID, userID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, broadcasts)
+}
+
+// GetBroadcast handles GET /api/v1/broadcast-lists/{listId}/broadcasts/{broadcastId}
+func (h *BroadcastHandler) GetBroadcast(w http.ResponseWriter, r *http.Request) {
+	userID, listID, ok := broadcastListParams(w, r)
+	if !ok {
+		return
+	}
+
+	broadcastID, err := GetPathUUID(r, "broadcastId")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid broadcast id"))
+		return
+	}
+
+	broadcast, err := h.broadcastService.GetBroadcast(r.Context(), listID, broadcastID, userID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, broadcast)
+}
+
+// broadcastListParams reads the authenticated user and the list id, writing
+// an error response and returning false when either is missing.
+func broadcastListParams(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	listID, err := GetPathUUID(r, "listId")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid broadcast list id"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, listID, true
+}
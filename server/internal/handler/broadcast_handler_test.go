@@ -0,0 +1,165 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/otoritech/chatat/internal/handler"
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+func TestBroadcastHandler_CreateList(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		listID := uuid.New()
+		h := handler.NewBroadcastHandler(&mockBroadcastService{list: &model.BroadcastList{ID: listID, Name: "Pelanggan"}})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodPost, "/api/v1/broadcast-lists", []byte(`{"name":"Pelanggan","recipientIds":["`+uuid.New().String()+`"]}`), userID)
+		h.CreateList(w, r)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), listID.String())
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		h := handler.NewBroadcastHandler(&mockBroadcastService{})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodPost, "/api/v1/broadcast-lists", []byte(`{"owner":"x"}`), userID)
+		h.CreateList(w, r)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		h := handler.NewBroadcastHandler(&mockBroadcastService{})
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/broadcast-lists", nil)
+		h.CreateList(w, r)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestBroadcastHandler_Lists(t *testing.T) {
+	userID := uuid.New()
+	listID := uuid.New()
+
+	t.Run("list", func(t *testing.T) {
+		h := handler.NewBroadcastHandler(&mockBroadcastService{lists: []*model.BroadcastList{{ID: listID}}})
+		w := httptest.NewRecorder()
+		h.ListLists(w, chatAuthReq(http.MethodGet, "/api/v1/broadcast-lists", nil, userID))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), listID.String())
+	})
+
+	t.Run("get not found", func(t *testing.T) {
+		h := handler.NewBroadcastHandler(&mockBroadcastService{err: apperror.NotFound("broadcast list", listID.String())})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodGet, "/api/v1/broadcast-lists/"+listID.String(), nil, userID)
+		h.GetList(w, withRouteParams(r, map[string]string{"listId": listID.String()}))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid list id", func(t *testing.T) {
+		h := handler.NewBroadcastHandler(&mockBroadcastService{})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodGet, "/api/v1/broadcast-lists/x", nil, userID)
+		h.GetList(w, withRouteParams(r, map[string]string{"listId": "x"}))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		h := handler.NewBroadcastHandler(&mockBroadcastService{list: &model.BroadcastList{ID: listID, Name: "VIP"}})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodPut, "/api/v1/broadcast-lists/"+listID.String(), []byte(`{"name":"VIP"}`), userID)
+		h.RenameList(w, withRouteParams(r, map[string]string{"listId": listID.String()}))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "VIP")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		h := handler.NewBroadcastHandler(&mockBroadcastService{})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodDelete, "/api/v1/broadcast-lists/"+listID.String(), nil, userID)
+		h.DeleteList(w, withRouteParams(r, map[string]string{"listId": listID.String()}))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+}
+
+func TestBroadcastHandler_Recipients(t *testing.T) {
+	userID := uuid.New()
+	listID := uuid.New()
+	recipientID := uuid.New()
+
+	t.Run("add", func(t *testing.T) {
+		h := handler.NewBroadcastHandler(&mockBroadcastService{list: &model.BroadcastList{ID: listID, RecipientIDs: []uuid.UUID{recipientID}}})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodPost, "/api/v1/broadcast-lists/"+listID.String()+"/recipients", []byte(`{"recipientIds":["`+recipientID.String()+`"]}`), userID)
+		h.AddRecipients(w, withRouteParams(r, map[string]string{"listId": listID.String()}))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), recipientID.String())
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		mock := &mockBroadcastService{}
+		h := handler.NewBroadcastHandler(mock)
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodDelete, "/api/v1/broadcast-lists/"+listID.String()+"/recipients/"+recipientID.String(), nil, userID)
+		h.RemoveRecipient(w, withRouteParams(r, map[string]string{"listId": listID.String(), "userId": recipientID.String()}))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, recipientID, mock.removed)
+	})
+
+	t.Run("remove invalid user id", func(t *testing.T) {
+		h := handler.NewBroadcastHandler(&mockBroadcastService{})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodDelete, "/api/v1/broadcast-lists/"+listID.String()+"/recipients/x", nil, userID)
+		h.RemoveRecipient(w, withRouteParams(r, map[string]string{"listId": listID.String(), "userId": "x"}))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestBroadcastHandler_Send(t *testing.T) {
+	userID := uuid.New()
+	listID := uuid.New()
+	broadcastID := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		mock := &mockBroadcastService{broadcast: &model.Broadcast{ID: broadcastID, SkippedCount: 1, Stats: model.BroadcastStats{Sent: 2}}}
+		h := handler.NewBroadcastHandler(mock)
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodPost, "/api/v1/broadcast-lists/"+listID.String()+"/send", []byte(`{"content":"Promo"}`), userID)
+		h.Send(w, withRouteParams(r, map[string]string{"listId": listID.String()}))
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), broadcastID.String())
+		assert.Equal(t, "Promo", mock.sent.Content)
+	})
+
+	t.Run("no recipients", func(t *testing.T) {
+		h := handler.NewBroadcastHandler(&mockBroadcastService{err: apperror.BadRequest("broadcast list has no recipients")})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodPost, "/api/v1/broadcast-lists/"+listID.String()+"/send", []byte(`{"content":"Promo"}`), userID)
+		h.Send(w, withRouteParams(r, map[string]string{"listId": listID.String()}))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("list and get broadcasts", func(t *testing.T) {
+		h := handler.NewBroadcastHandler(&mockBroadcastService{
+			broadcast:  &model.Broadcast{ID: broadcastID},
+			broadcasts: []*model.Broadcast{{ID: broadcastID}},
+		})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodGet, "/api/v1/broadcast-lists/"+listID.String()+"/broadcasts", nil, userID)
+		h.ListBroadcasts(w, withRouteParams(r, map[string]string{"listId": listID.String()}))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), broadcastID.String())
+
+		w = httptest.NewRecorder()
+		r = chatAuthReq(http.MethodGet, "/api/v1/broadcast-lists/"+listID.String()+"/broadcasts/"+broadcastID.String(), nil, userID)
+		h.GetBroadcast(w, withRouteParams(r, map[string]string{"listId": listID.String(), "broadcastId": broadcastID.String()}))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/otoritech/chatat/internal/model"
 	"github.com/otoritech/chatat/internal/service"
 	"github.com/otoritech/chatat/pkg/apperror"
 	"github.com/otoritech/chatat/pkg/response"
@@ -35,7 +36,7 @@ type createChatRequest struct {
 	MemberIDs   []string `json:"memberIds"`
 }
 
-// List handles GET /api/v1/chats
+// List handles GET /api/v1/chats?archived=true&unread=true&type=group
 func (h *ChatHandler) List(w http.ResponseWriter, r *http.Request) {
 	userID, err := GetUserID(r)
 	if err != nil {
@@ -43,7 +44,18 @@ func (h *ChatHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	chats, err := h.chatService.ListChats(r.Context(), userID)
+	query := r.URL.Query()
+	filter := service.ChatListFilter{
+		Archived: query.Get("archived") == "true",
+		Unread:   query.Get("unread") == "true",
+		Type:     model.ChatType(query.Get("type")),
+	}
+	if filter.Type != "" && filter.Type != model.ChatTypePersonal && filter.Type != model.ChatTypeGroup {
+		response.Error(w, apperror.BadRequest("invalid chat type"))
+		return
+	}
+
+	chats, err := h.chatService.ListChats(r.Context(), userID, filter)
 	if err != nil {
 		handleServiceError(w, err)
 		return
@@ -231,6 +243,66 @@ func (h *ChatHandler) UnpinChat(w http.ResponseWriter, r *http.Request) {
 	response.NoContent(w)
 }
 
+// UpdateSettings handles PUT /api/v1/chats/{id}/settings
+func (h *ChatHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	chatID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid chat id"))
+		return
+	}
+
+	var input model.UpdateChatSettingsInput
+	if err := DecodeJSON(r, &input); err != nil {
+		response.Error(w, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	settings, err := h.chatService.UpdateSettings(r.Context(), chatID, userID, input)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, settings)
+}
+
+// SetDisappearingTimer handles PUT /api/v1/chats/{id}/disappearing
+func (h *ChatHandler) SetDisappearingTimer(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	chatID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid chat id"))
+		return
+	}
+
+	var req struct {
+		DisappearingTimer int `json:"disappearingTimer"`
+	}
+	if err := DecodeJSON(r, &req); err != nil {
+		response.Error(w, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	chat, err := h.chatService.SetDisappearingTimer(r.Context(), chatID, userID, req.DisappearingTimer)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, chat)
+}
+
 // SendMessage handles POST /api/v1/chats/{id}/messages
 func (h *ChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 	userID, err := GetUserID(r)
@@ -363,6 +435,325 @@ func (h *ChatHandler) ForwardMessage(w http.ResponseWriter, r *http.Request) {
 	response.Created(w, msg)
 }
 
+// EditMessage handles PUT /api/v1/chats/{id}/messages/{messageId}
+func (h *ChatHandler) EditMessage(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	chatID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid chat id"))
+		return
+	}
+
+	messageID, err := GetPathUUID(r, "messageId")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid message id"))
+		return
+	}
+
+	var req struct {
+		Content string `json:"content"`
+	}
+	if err := DecodeJSON(r, &req); err != nil {
+		response.Error(w, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	msg, err := h.messageService.EditMessage(r.Context(), chatID, messageID, userID, req.Content)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, msg)
+}
+
+// UpdateLiveLocation handles PUT /api/v1/chats/{id}/messages/{messageId}/location
+func (h *ChatHandler) UpdateLiveLocation(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	chatID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid chat id"))
+		return
+	}
+
+	messageID, err := GetPathUUID(r, "messageId")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid message id"))
+		return
+	}
+
+	var req struct {
+		Latitude  *float64 `json:"latitude"`
+		Longitude *float64 `json:"longitude"`
+	}
+	if err := DecodeJSON(r, &req); err != nil {
+		response.Error(w, apperror.BadRequest("invalid request body"))
+		return
+	}
+	if req.Latitude == nil || req.Longitude == nil {
+		response.Error(w, apperror.BadRequest("latitude and longitude are required"))
+		return
+	}
+
+	msg, err := h.messageService.UpdateLiveLocation(r.Context(), chatID, messageID, userID, *req.Latitude, *req.Longitude)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, msg)
+}
+
+// StopLiveLocation handles DELETE /api/v1/chats/{id}/messages/{messageId}/location
+func (h *ChatHandler) StopLiveLocation(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	chatID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid chat id"))
+		return
+	}
+
+	messageID, err := GetPathUUID(r, "messageId")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid message id"))
+		return
+	}
+
+	msg, err := h.messageService.StopLiveLocation(r.Context(), chatID, messageID, userID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, msg)
+}
+
+// GetEditHistory handles GET /api/v1/chats/{id}/messages/{messageId}/edits
+func (h *ChatHandler) GetEditHistory(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	chatID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid chat id"))
+		return
+	}
+
+	messageID, err := GetPathUUID(r, "messageId")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid message id"))
+		return
+	}
+
+	edits, err := h.messageService.GetEditHistory(r.Context(), chatID, messageID, userID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, edits)
+}
+
+// GetReceipts handles GET /api/v1/chats/{id}/messages/{messageId}/receipts
+func (h *ChatHandler) GetReceipts(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	chatID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid chat id"))
+		return
+	}
+
+	messageID, err := GetPathUUID(r, "messageId")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid message id"))
+		return
+	}
+
+	receipts, err := h.messageService.GetReceipts(r.Context(), chatID, messageID, userID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, receipts)
+}
+
+// GetThread handles GET /api/v1/chats/{id}/messages/{messageId}/thread
+func (h *ChatHandler) GetThread(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	chatID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid chat id"))
+		return
+	}
+
+	messageID, err := GetPathUUID(r, "messageId")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid message id"))
+		return
+	}
+
+	cursor, limit := ParsePagination(r)
+
+	page, err := h.messageService.GetThread(r.Context(), chatID, messageID, userID, cursor, limit)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, page)
+}
+
+// FollowThread handles POST /api/v1/chats/{id}/messages/{messageId}/thread/follow
+func (h *ChatHandler) FollowThread(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	chatID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid chat id"))
+		return
+	}
+
+	messageID, err := GetPathUUID(r, "messageId")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid message id"))
+		return
+	}
+
+	if err := h.messageService.FollowThread(r.Context(), chatID, messageID, userID); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// UnfollowThread handles DELETE /api/v1/chats/{id}/messages/{messageId}/thread/follow
+func (h *ChatHandler) UnfollowThread(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	chatID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid chat id"))
+		return
+	}
+
+	messageID, err := GetPathUUID(r, "messageId")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid message id"))
+		return
+	}
+
+	if err := h.messageService.UnfollowThread(r.Context(), chatID, messageID, userID); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// AddReaction handles POST /api/v1/chats/{id}/messages/{messageId}/reactions
+func (h *ChatHandler) AddReaction(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	chatID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid chat id"))
+		return
+	}
+
+	messageID, err := GetPathUUID(r, "messageId")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid message id"))
+		return
+	}
+
+	var req struct {
+		Emoji string `json:"emoji"`
+	}
+	if err := DecodeJSON(r, &req); err != nil {
+		response.Error(w, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	reactions, err := h.messageService.AddReaction(r.Context(), chatID, messageID, userID, req.Emoji)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, reactions)
+}
+
+// RemoveReaction handles DELETE /api/v1/chats/{id}/messages/{messageId}/reactions?emoji=
+func (h *ChatHandler) RemoveReaction(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	chatID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid chat id"))
+		return
+	}
+
+	messageID, err := GetPathUUID(r, "messageId")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid message id"))
+		return
+	}
+
+	reactions, err := h.messageService.RemoveReaction(r.Context(), chatID, messageID, userID, r.URL.Query().Get("emoji"))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, reactions)
+}
+
 // SearchMessages handles GET /api/v1/chats/{id}/messages/search
 func (h *ChatHandler) SearchMessages(w http.ResponseWriter, r *http.Request) {
 	userID, err := GetUserID(r)
@@ -495,6 +886,91 @@ func (h *ChatHandler) PromoteToAdmin(w http.ResponseWriter, r *http.Request) {
 	response.NoContent(w)
 }
 
+// DemoteAdmin handles DELETE /api/v1/chats/{id}/members/{memberID}/admin
+func (h *ChatHandler) DemoteAdmin(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	chatID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid chat id"))
+		return
+	}
+
+	memberID, err := GetPathUUID(r, "memberID")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid member id"))
+		return
+	}
+
+	if err := h.groupService.DemoteAdmin(r.Context(), chatID, memberID, userID); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// TransferOwnership handles PUT /api/v1/chats/{id}/members/{memberID}/owner
+func (h *ChatHandler) TransferOwnership(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	chatID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid chat id"))
+		return
+	}
+
+	memberID, err := GetPathUUID(r, "memberID")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid member id"))
+		return
+	}
+
+	if err := h.groupService.TransferOwnership(r.Context(), chatID, memberID, userID); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// UpdatePermissions handles PUT /api/v1/chats/{id}/permissions
+func (h *ChatHandler) UpdatePermissions(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	chatID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid chat id"))
+		return
+	}
+
+	var req model.UpdateGroupPermissionsInput
+	if err := DecodeJSON(r, &req); err != nil {
+		response.Error(w, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	chat, err := h.groupService.UpdatePermissions(r.Context(), chatID, userID, req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, chat)
+}
+
 // LeaveGroup handles POST /api/v1/chats/{id}/leave
 func (h *ChatHandler) LeaveGroup(w http.ResponseWriter, r *http.Request) {
 	userID, err := GetUserID(r)
@@ -554,7 +1030,18 @@ func (h *ChatHandler) MarkAsRead(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.messageService.MarkChatAsRead(r.Context(), chatID, userID); err != nil {
+	// The body is optional; without it everything up to the newest message is read
+	var req struct {
+		LastReadMessageID *uuid.UUID `json:"lastReadMessageId"`
+	}
+	if r.ContentLength > 0 {
+		if err := DecodeJSON(r, &req); err != nil {
+			response.Error(w, apperror.BadRequest("invalid request body"))
+			return
+		}
+	}
+
+	if err := h.messageService.MarkChatAsRead(r.Context(), chatID, userID, req.LastReadMessageID); err != nil {
 		handleServiceError(w, err)
 		return
 	}
@@ -60,6 +60,22 @@ func TestChatHandler_List(t *testing.T) {
 		assert.Equal(t, http.StatusOK, w.Code)
 	})
 
+	t.Run("filters", func(t *testing.T) {
+		svc := &mockChatService{chatList: items}
+		h := handler.NewChatHandler(svc, nil, nil)
+		w := httptest.NewRecorder()
+		h.List(w, chatAuthReq(http.MethodGet, "/api/v1/chats?archived=true&unread=true&type=group", nil, userID))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, service.ChatListFilter{Archived: true, Unread: true, Type: model.ChatTypeGroup}, svc.listFilter)
+	})
+
+	t.Run("invalid type filter", func(t *testing.T) {
+		h := handler.NewChatHandler(&mockChatService{}, nil, nil)
+		w := httptest.NewRecorder()
+		h.List(w, chatAuthReq(http.MethodGet, "/api/v1/chats?type=channel", nil, userID))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
 	t.Run("unauthorized", func(t *testing.T) {
 		h := handler.NewChatHandler(&mockChatService{}, nil, nil)
 		w := httptest.NewRecorder()
@@ -419,6 +435,80 @@ func TestChatHandler_PinUnpin(t *testing.T) {
 
 // --- SendMessage ---
 
+func TestChatHandler_UpdateSettings(t *testing.T) {
+	userID := uuid.New()
+	chatID := uuid.New()
+	url := "/api/v1/chats/" + chatID.String() + "/settings"
+
+	t.Run("success", func(t *testing.T) {
+		h := handler.NewChatHandler(&mockChatService{settings: &model.ChatSettings{NotificationSound: "chime"}}, nil, nil)
+		body, _ := json.Marshal(map[string]any{"archived": true, "notificationSound": "chime"})
+		w := httptest.NewRecorder()
+		h.UpdateSettings(w, withChatIDParam(chatAuthReq(http.MethodPut, url, body, userID), chatID))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"notificationSound":"chime"`)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		h := handler.NewChatHandler(&mockChatService{}, nil, nil)
+		w := httptest.NewRecorder()
+		h.UpdateSettings(w, withChatIDParam(httptest.NewRequest(http.MethodPut, url, nil), chatID))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		h := handler.NewChatHandler(&mockChatService{}, nil, nil)
+		w := httptest.NewRecorder()
+		h.UpdateSettings(w, withChatIDParam(chatAuthReq(http.MethodPut, url, []byte("bad"), userID), chatID))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("not a member", func(t *testing.T) {
+		h := handler.NewChatHandler(&mockChatService{err: apperror.Forbidden("you are not a member of this chat")}, nil, nil)
+		body, _ := json.Marshal(map[string]any{"pinned": true})
+		w := httptest.NewRecorder()
+		h.UpdateSettings(w, withChatIDParam(chatAuthReq(http.MethodPut, url, body, userID), chatID))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestChatHandler_SetDisappearingTimer(t *testing.T) {
+	userID := uuid.New()
+	chatID := uuid.New()
+	url := "/api/v1/chats/" + chatID.String() + "/disappearing"
+
+	t.Run("success", func(t *testing.T) {
+		h := handler.NewChatHandler(&mockChatService{chat: &model.Chat{ID: chatID, DisappearingTimer: 86400}}, nil, nil)
+		w := httptest.NewRecorder()
+		body, _ := json.Marshal(map[string]int{"disappearingTimer": 86400})
+		h.SetDisappearingTimer(w, withChatIDParam(chatAuthReq(http.MethodPut, url, body, userID), chatID))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"disappearingTimer":86400`)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		h := handler.NewChatHandler(&mockChatService{}, nil, nil)
+		w := httptest.NewRecorder()
+		h.SetDisappearingTimer(w, withChatIDParam(httptest.NewRequest(http.MethodPut, url, nil), chatID))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		h := handler.NewChatHandler(&mockChatService{}, nil, nil)
+		w := httptest.NewRecorder()
+		h.SetDisappearingTimer(w, withChatIDParam(chatAuthReq(http.MethodPut, url, []byte("bad"), userID), chatID))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("not an admin", func(t *testing.T) {
+		h := handler.NewChatHandler(&mockChatService{err: apperror.Forbidden("only admins can perform this action")}, nil, nil)
+		w := httptest.NewRecorder()
+		body, _ := json.Marshal(map[string]int{"disappearingTimer": 0})
+		h.SetDisappearingTimer(w, withChatIDParam(chatAuthReq(http.MethodPut, url, body, userID), chatID))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
 func TestChatHandler_SendMessage(t *testing.T) {
 	userID := uuid.New()
 	chatID := uuid.New()
@@ -982,6 +1072,119 @@ func TestChatHandler_PromoteToAdmin(t *testing.T) {
 	})
 }
 
+func TestChatHandler_DemoteAdmin(t *testing.T) {
+	userID := uuid.New()
+	chatID := uuid.New()
+	memberID := uuid.New()
+	url := "/api/v1/chats/" + chatID.String() + "/members/" + memberID.String() + "/admin"
+	params := map[string]string{"id": chatID.String(), "memberID": memberID.String()}
+
+	t.Run("success", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, nil, &mockGroupService{})
+		w := httptest.NewRecorder()
+		h.DemoteAdmin(w, withRouteParams(chatAuthReq(http.MethodDelete, url, nil, userID), params))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, nil, &mockGroupService{})
+		w := httptest.NewRecorder()
+		h.DemoteAdmin(w, withRouteParams(httptest.NewRequest(http.MethodDelete, url, nil), params))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("invalid member id", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, nil, &mockGroupService{})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodDelete, url, nil, userID)
+		h.DemoteAdmin(w, withRouteParams(r, map[string]string{"id": chatID.String(), "memberID": "invalid"}))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("owner cannot be demoted", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, nil, &mockGroupService{err: apperror.Forbidden("the group owner cannot be demoted")})
+		w := httptest.NewRecorder()
+		h.DemoteAdmin(w, withRouteParams(chatAuthReq(http.MethodDelete, url, nil, userID), params))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestChatHandler_TransferOwnership(t *testing.T) {
+	userID := uuid.New()
+	chatID := uuid.New()
+	memberID := uuid.New()
+	url := "/api/v1/chats/" + chatID.String() + "/members/" + memberID.String() + "/owner"
+	params := map[string]string{"id": chatID.String(), "memberID": memberID.String()}
+
+	t.Run("success", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, nil, &mockGroupService{})
+		w := httptest.NewRecorder()
+		h.TransferOwnership(w, withRouteParams(chatAuthReq(http.MethodPut, url, nil, userID), params))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, nil, &mockGroupService{})
+		w := httptest.NewRecorder()
+		h.TransferOwnership(w, withRouteParams(httptest.NewRequest(http.MethodPut, url, nil), params))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("invalid chat id", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, nil, &mockGroupService{})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodPut, url, nil, userID)
+		h.TransferOwnership(w, withRouteParams(r, map[string]string{"id": "invalid", "memberID": memberID.String()}))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("not the owner", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, nil, &mockGroupService{err: apperror.Forbidden("only the group owner can transfer ownership")})
+		w := httptest.NewRecorder()
+		h.TransferOwnership(w, withRouteParams(chatAuthReq(http.MethodPut, url, nil, userID), params))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestChatHandler_UpdatePermissions(t *testing.T) {
+	userID := uuid.New()
+	chatID := uuid.New()
+	url := "/api/v1/chats/" + chatID.String() + "/permissions"
+
+	t.Run("success", func(t *testing.T) {
+		chat := &model.Chat{ID: chatID, Type: model.ChatTypeGroup, Permissions: model.GroupPermissions{OnlyAdminsSend: true}}
+		h := handler.NewChatHandler(nil, nil, &mockGroupService{chat: chat})
+		w := httptest.NewRecorder()
+		body, _ := json.Marshal(map[string]bool{"onlyAdminsSend": true})
+		h.UpdatePermissions(w, withChatIDParam(chatAuthReq(http.MethodPut, url, body, userID), chatID))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"onlyAdminsSend":true`)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, nil, &mockGroupService{})
+		w := httptest.NewRecorder()
+		h.UpdatePermissions(w, withChatIDParam(httptest.NewRequest(http.MethodPut, url, nil), chatID))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, nil, &mockGroupService{})
+		w := httptest.NewRecorder()
+		body, _ := json.Marshal(map[string]bool{"onlyOwnerSends": true})
+		h.UpdatePermissions(w, withChatIDParam(chatAuthReq(http.MethodPut, url, body, userID), chatID))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("not an admin", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, nil, &mockGroupService{err: apperror.Forbidden("only admins can perform this action")})
+		w := httptest.NewRecorder()
+		body, _ := json.Marshal(map[string]bool{"onlyAdminsSend": true})
+		h.UpdatePermissions(w, withChatIDParam(chatAuthReq(http.MethodPut, url, body, userID), chatID))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
 func TestChatHandler_LeaveGroup(t *testing.T) {
 	userID := uuid.New()
 	chatID := uuid.New()
@@ -1091,6 +1294,26 @@ func TestChatHandler_MarkAsRead(t *testing.T) {
 		assert.Equal(t, http.StatusNoContent, w.Code)
 	})
 
+	t.Run("up to a message", func(t *testing.T) {
+		svc := &mockMessageService{}
+		h := handler.NewChatHandler(nil, svc, nil)
+		msgID := uuid.New()
+		body, _ := json.Marshal(map[string]string{"lastReadMessageId": msgID.String()})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodPost, "/api/v1/chats/"+chatID.String()+"/read", body, userID)
+		h.MarkAsRead(w, withChatIDParam(r, chatID))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, &msgID, svc.lastReadID)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, &mockMessageService{}, nil)
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodPost, "/api/v1/chats/"+chatID.String()+"/read", []byte(`{"lastReadMessageId":"nope"}`), userID)
+		h.MarkAsRead(w, withChatIDParam(r, chatID))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
 	t.Run("unauthorized", func(t *testing.T) {
 		h := handler.NewChatHandler(nil, &mockMessageService{}, nil)
 		w := httptest.NewRecorder()
@@ -1126,3 +1349,246 @@ func TestChatHandler_MarkAsRead(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
 }
+
+// --- Reactions ---
+
+func TestChatHandler_AddReaction(t *testing.T) {
+	userID := uuid.New()
+	chatID := uuid.New()
+	msgID := uuid.New()
+	url := "/api/v1/chats/" + chatID.String() + "/messages/" + msgID.String() + "/reactions"
+
+	t.Run("success", func(t *testing.T) {
+		reactions := []model.ReactionSummary{{Emoji: "👍", Count: 1, UserIDs: []uuid.UUID{userID}}}
+		h := handler.NewChatHandler(nil, &mockMessageService{reactions: reactions}, nil)
+		w := httptest.NewRecorder()
+		body, _ := json.Marshal(map[string]string{"emoji": "👍"})
+		h.AddReaction(w, withMsgIDParam(chatAuthReq(http.MethodPost, url, body, userID), chatID, msgID))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "👍")
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, &mockMessageService{}, nil)
+		w := httptest.NewRecorder()
+		h.AddReaction(w, withMsgIDParam(chatAuthReq(http.MethodPost, url, []byte("bad"), userID), chatID, msgID))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, &mockMessageService{err: apperror.Forbidden("not a member")}, nil)
+		w := httptest.NewRecorder()
+		body, _ := json.Marshal(map[string]string{"emoji": "👍"})
+		h.AddReaction(w, withMsgIDParam(chatAuthReq(http.MethodPost, url, body, userID), chatID, msgID))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, &mockMessageService{}, nil)
+		w := httptest.NewRecorder()
+		h.AddReaction(w, withMsgIDParam(httptest.NewRequest(http.MethodPost, url, nil), chatID, msgID))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestChatHandler_RemoveReaction(t *testing.T) {
+	userID := uuid.New()
+	chatID := uuid.New()
+	msgID := uuid.New()
+	url := "/api/v1/chats/" + chatID.String() + "/messages/" + msgID.String() + "/reactions?emoji=%F0%9F%91%8D"
+
+	t.Run("success", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, &mockMessageService{reactions: []model.ReactionSummary{}}, nil)
+		w := httptest.NewRecorder()
+		h.RemoveReaction(w, withMsgIDParam(chatAuthReq(http.MethodDelete, url, nil, userID), chatID, msgID))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, &mockMessageService{err: apperror.NotFound("reaction", "👍")}, nil)
+		w := httptest.NewRecorder()
+		h.RemoveReaction(w, withMsgIDParam(chatAuthReq(http.MethodDelete, url, nil, userID), chatID, msgID))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+// --- Edit ---
+
+func TestChatHandler_EditMessage(t *testing.T) {
+	userID := uuid.New()
+	chatID := uuid.New()
+	msgID := uuid.New()
+	url := "/api/v1/chats/" + chatID.String() + "/messages/" + msgID.String()
+
+	t.Run("success", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, &mockMessageService{message: &model.Message{ID: msgID, Content: "edited"}}, nil)
+		w := httptest.NewRecorder()
+		body, _ := json.Marshal(map[string]string{"content": "edited"})
+		h.EditMessage(w, withMsgIDParam(chatAuthReq(http.MethodPut, url, body, userID), chatID, msgID))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "edited")
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, &mockMessageService{}, nil)
+		w := httptest.NewRecorder()
+		h.EditMessage(w, withMsgIDParam(chatAuthReq(http.MethodPut, url, []byte("bad"), userID), chatID, msgID))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("not the sender", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, &mockMessageService{err: apperror.Forbidden("only the sender can edit a message")}, nil)
+		w := httptest.NewRecorder()
+		body, _ := json.Marshal(map[string]string{"content": "edited"})
+		h.EditMessage(w, withMsgIDParam(chatAuthReq(http.MethodPut, url, body, userID), chatID, msgID))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestChatHandler_LiveLocation(t *testing.T) {
+	userID := uuid.New()
+	chatID := uuid.New()
+	msgID := uuid.New()
+	url := "/api/v1/chats/" + chatID.String() + "/messages/" + msgID.String() + "/location"
+
+	t.Run("update", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, &mockMessageService{message: &model.Message{ID: msgID, Type: model.MessageTypeLocation}}, nil)
+		w := httptest.NewRecorder()
+		body := []byte(`{"latitude":-6.2,"longitude":106.8}`)
+		h.UpdateLiveLocation(w, withMsgIDParam(chatAuthReq(http.MethodPut, url, body, userID), chatID, msgID))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), msgID.String())
+	})
+
+	t.Run("missing coordinates", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, &mockMessageService{}, nil)
+		w := httptest.NewRecorder()
+		h.UpdateLiveLocation(w, withMsgIDParam(chatAuthReq(http.MethodPut, url, []byte(`{"latitude":1}`), userID), chatID, msgID))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("sharing ended", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, &mockMessageService{err: apperror.BadRequest("live location sharing has ended")}, nil)
+		w := httptest.NewRecorder()
+		body := []byte(`{"latitude":0,"longitude":0}`)
+		h.UpdateLiveLocation(w, withMsgIDParam(chatAuthReq(http.MethodPut, url, body, userID), chatID, msgID))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, &mockMessageService{message: &model.Message{ID: msgID, Type: model.MessageTypeLocation}}, nil)
+		w := httptest.NewRecorder()
+		h.StopLiveLocation(w, withMsgIDParam(chatAuthReq(http.MethodDelete, url, nil, userID), chatID, msgID))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("stop by other member", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, &mockMessageService{err: apperror.Forbidden("only the sender can update a live location")}, nil)
+		w := httptest.NewRecorder()
+		h.StopLiveLocation(w, withMsgIDParam(chatAuthReq(http.MethodDelete, url, nil, userID), chatID, msgID))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestChatHandler_GetEditHistory(t *testing.T) {
+	userID := uuid.New()
+	chatID := uuid.New()
+	msgID := uuid.New()
+	url := "/api/v1/chats/" + chatID.String() + "/messages/" + msgID.String() + "/edits"
+
+	h := handler.NewChatHandler(nil, &mockMessageService{edits: []*model.MessageEdit{{MessageID: msgID, Content: "before"}}}, nil)
+	w := httptest.NewRecorder()
+	h.GetEditHistory(w, withMsgIDParam(chatAuthReq(http.MethodGet, url, nil, userID), chatID, msgID))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "before")
+}
+
+func TestChatHandler_GetReceipts(t *testing.T) {
+	userID := uuid.New()
+	chatID := uuid.New()
+	msgID := uuid.New()
+	url := "/api/v1/chats/" + chatID.String() + "/messages/" + msgID.String() + "/receipts"
+
+	t.Run("success", func(t *testing.T) {
+		receipts := []*model.MessageReceipt{{User: model.User{ID: uuid.New(), Name: "Rina"}, Status: model.DeliveryStatusRead}}
+		h := handler.NewChatHandler(nil, &mockMessageService{receipts: receipts}, nil)
+		w := httptest.NewRecorder()
+		h.GetReceipts(w, withMsgIDParam(chatAuthReq(http.MethodGet, url, nil, userID), chatID, msgID))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Rina")
+		assert.Contains(t, w.Body.String(), `"status":"read"`)
+	})
+
+	t.Run("not the sender", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, &mockMessageService{err: apperror.Forbidden("only the sender can view message receipts")}, nil)
+		w := httptest.NewRecorder()
+		h.GetReceipts(w, withMsgIDParam(chatAuthReq(http.MethodGet, url, nil, userID), chatID, msgID))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, &mockMessageService{}, nil)
+		w := httptest.NewRecorder()
+		h.GetReceipts(w, withMsgIDParam(httptest.NewRequest(http.MethodGet, url, nil), chatID, msgID))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+// --- Threads ---
+
+func TestChatHandler_GetThread(t *testing.T) {
+	userID := uuid.New()
+	chatID := uuid.New()
+	msgID := uuid.New()
+	url := "/api/v1/chats/" + chatID.String() + "/messages/" + msgID.String() + "/thread"
+
+	t.Run("success", func(t *testing.T) {
+		page := &service.ThreadPage{
+			Root:      &model.Message{ID: msgID, Content: "root", ReplyCount: 1},
+			Replies:   []*model.Message{{ID: uuid.New(), Content: "first reply"}},
+			Following: true,
+		}
+		h := handler.NewChatHandler(nil, &mockMessageService{thread: page}, nil)
+		w := httptest.NewRecorder()
+		h.GetThread(w, withMsgIDParam(chatAuthReq(http.MethodGet, url+"?limit=10", nil, userID), chatID, msgID))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "first reply")
+		assert.Contains(t, w.Body.String(), `"replyCount":1`)
+		assert.Contains(t, w.Body.String(), `"following":true`)
+	})
+
+	t.Run("not a member", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, &mockMessageService{err: apperror.Forbidden("you are not a member of this chat")}, nil)
+		w := httptest.NewRecorder()
+		h.GetThread(w, withMsgIDParam(chatAuthReq(http.MethodGet, url, nil, userID), chatID, msgID))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestChatHandler_FollowThread(t *testing.T) {
+	userID := uuid.New()
+	chatID := uuid.New()
+	msgID := uuid.New()
+	url := "/api/v1/chats/" + chatID.String() + "/messages/" + msgID.String() + "/thread/follow"
+
+	t.Run("follow", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, &mockMessageService{}, nil)
+		w := httptest.NewRecorder()
+		h.FollowThread(w, withMsgIDParam(chatAuthReq(http.MethodPost, url, nil, userID), chatID, msgID))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("unfollow when not following", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, &mockMessageService{err: apperror.NotFound("thread follower", userID.String())}, nil)
+		w := httptest.NewRecorder()
+		h.UnfollowThread(w, withMsgIDParam(chatAuthReq(http.MethodDelete, url, nil, userID), chatID, msgID))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("no user", func(t *testing.T) {
+		h := handler.NewChatHandler(nil, &mockMessageService{}, nil)
+		w := httptest.NewRecorder()
+		h.FollowThread(w, authReqNoUser(http.MethodPost, url, nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
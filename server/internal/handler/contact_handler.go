@@ -78,6 +78,12 @@ func (h *ContactHandler) List(w http.ResponseWriter, r *http.Request) {
 
 // Search handles GET /api/v1/contacts/search?phone=+628xxx
 func (h *ContactHandler) Search(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
 	phoneParam := r.URL.Query().Get("phone")
 	if phoneParam == "" {
 		response.Error(w, apperror.BadRequest("phone query parameter is required"))
@@ -90,7 +96,7 @@ func (h *ContactHandler) Search(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.contactService.SearchByPhone(r.Context(), normalized)
+	user, err := h.contactService.SearchByPhone(r.Context(), userID, normalized)
 	if err != nil {
 		if appErr, ok := err.(*apperror.AppError); ok {
 			response.Error(w, appErr)
@@ -105,13 +111,19 @@ func (h *ContactHandler) Search(w http.ResponseWriter, r *http.Request) {
 
 // GetProfile handles GET /api/v1/contacts/:userId
 func (h *ContactHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
 	contactID, err := GetPathUUID(r, "userId")
 	if err != nil {
 		response.Error(w, apperror.BadRequest("invalid userId"))
 		return
 	}
 
-	contact, err := h.contactService.GetContactProfile(r.Context(), contactID)
+	contact, err := h.contactService.GetContactProfile(r.Context(), userID, contactID)
 	if err != nil {
 		if appErr, ok := err.(*apperror.AppError); ok {
 			response.Error(w, appErr)
@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
 
@@ -36,10 +37,18 @@ type Dependencies struct {
 	MediaService        service.MediaService
 	DocumentService     service.DocumentService
 	BlockService        service.BlockService
+	DocVersionService   service.DocumentVersionService
+	DocCommentService   service.DocumentCommentService
 	TemplateService     service.TemplateService
 	NotificationService service.NotificationService
+	MentionService      service.MentionService
+	ScheduledMsgService service.ScheduledMessageService
 	SearchService       service.SearchService
 	BackupService       service.BackupService
+	ModerationService   service.ModerationService
+	GroupInviteService  service.GroupInviteService
+	BroadcastService    service.BroadcastService
+	PollService         service.PollService
 
 	// Repositories
 	UserRepo        repository.UserRepository
@@ -52,11 +61,21 @@ type Dependencies struct {
 	EntityRepo      repository.EntityRepository
 	MessageStatRepo repository.MessageStatusRepository
 	DocHistoryRepo  repository.DocumentHistoryRepository
+	DocVersionRepo  repository.DocumentVersionRepository
+	DocCommentRepo  repository.DocumentCommentRepository
 	TopicMsgRepo    repository.TopicMessageRepository
 	MediaRepo       repository.MediaRepository
 	DeviceTokenRepo repository.DeviceTokenRepository
 	SearchRepo      repository.SearchRepository
 	BackupRepo      repository.BackupRepository
+	UserBlockRepo   repository.UserBlockRepository
+	GroupInviteRepo repository.GroupInviteRepository
+	BroadcastRepo   repository.BroadcastRepository
+	PollRepo        repository.PollRepository
+
+	// Background workers
+	ScheduledDispatcher *service.ScheduledDispatcher
+	MessageReaper       *service.MessageReaper
 
 	// Handlers
 	AuthHandler         *AuthHandler
@@ -67,10 +86,17 @@ type Dependencies struct {
 	TopicHandler        *TopicHandler
 	MediaHandler        *MediaHandler
 	DocumentHandler     *DocumentHandler
+	DocCommentHandler   *DocumentCommentHandler
 	EntityHandler       *EntityHandler
 	NotificationHandler *NotificationHandler
+	MentionHandler      *MentionHandler
+	ScheduledMsgHandler *ScheduledMessageHandler
 	SearchHandler       *SearchHandler
 	BackupHandler       *BackupHandler
+	ModerationHandler   *ModerationHandler
+	GroupInviteHandler  *GroupInviteHandler
+	BroadcastHandler    *BroadcastHandler
+	PollHandler         *PollHandler
 	WSHandler           *WSHandler
 }
 
@@ -87,11 +113,23 @@ func NewDependencies(cfg *config.Config, db *pgxpool.Pool, redisClient *redis.Cl
 	entityRepo := repository.NewEntityRepository(db)
 	messageStatRepo := repository.NewMessageStatusRepository(db)
 	docHistoryRepo := repository.NewDocumentHistoryRepository(db)
+	docVersionRepo := repository.NewDocumentVersionRepository(db)
+	docCommentRepo := repository.NewDocumentCommentRepository(db)
 	topicMsgRepo := repository.NewTopicMessageRepository(db)
 	mediaRepo := repository.NewMediaRepository(db)
 	deviceTokenRepo := repository.NewDeviceTokenRepository(db)
 	searchRepo := repository.NewSearchRepository(db)
 	backupRepo := repository.NewBackupRepository(db)
+	reactionRepo := repository.NewMessageReactionRepository(db)
+	topicReactionRepo := repository.NewTopicMessageReactionRepository(db)
+	mentionRepo := repository.NewMentionRepository(db)
+	threadRepo := repository.NewThreadRepository(db)
+	scheduledMsgRepo := repository.NewScheduledMessageRepository(db)
+	userBlockRepo := repository.NewUserBlockRepository(db)
+	abuseReportRepo := repository.NewAbuseReportRepository(db)
+	groupInviteRepo := repository.NewGroupInviteRepository(db)
+	broadcastRepo := repository.NewBroadcastRepository(db)
+	pollRepo := repository.NewPollRepository(db)
 
 	// Services
 	smsProvider := service.NewLogSMSProvider()
@@ -106,7 +144,13 @@ func NewDependencies(cfg *config.Config, db *pgxpool.Pool, redisClient *redis.Cl
 	otpService := service.NewOTPService(redisClient, smsProvider, service.DefaultOTPConfig())
 	reverseOTPService := service.NewReverseOTPService(redisClient, waProvider, 0)
 	tokenService := service.NewTokenService(redisClient, service.DefaultTokenConfig(cfg.JWTSecret))
-	sessionService := service.NewSessionService(redisClient, tokenService, 0)
+	sessionService := service.NewSessionService(redisClient, tokenService, hub, 0, service.SessionMode(cfg.SessionMode))
+	// A replayed refresh token means it leaked: sign the device out
+	tokenService.OnRefreshReuse(func(ctx context.Context, userID uuid.UUID, deviceID string) {
+		if deviceID != "" {
+			_ = sessionService.Revoke(ctx, userID, deviceID)
+		}
+	})
 	userService := service.NewUserService(userRepo)
 
 	// Push notification service (created early so other services can use it)
@@ -122,12 +166,19 @@ func NewDependencies(cfg *config.Config, db *pgxpool.Pool, redisClient *redis.Cl
 	}
 	notifSvc := service.NewNotificationService(deviceTokenRepo, chatRepo, pushSender)
 
-	contactService := service.NewContactService(userRepo, contactRepo, hub)
-	chatService := service.NewChatService(chatRepo, messageRepo, messageStatRepo, userRepo, hub)
-	messageService := service.NewMessageService(messageRepo, messageStatRepo, chatRepo, userRepo, hub, notifSvc)
-	groupService := service.NewGroupService(chatRepo, messageRepo, messageStatRepo, userRepo, hub, notifSvc)
+	privacyPolicy := service.NewPrivacyPolicy(contactRepo, userBlockRepo)
+	contactService := service.NewContactService(userRepo, contactRepo, privacyPolicy, hub)
+	chatService := service.NewChatService(chatRepo, messageRepo, messageStatRepo, userRepo, userBlockRepo, privacyPolicy, hub)
+	mentionSvc := service.NewMentionService(mentionRepo, userRepo, notifSvc)
+	messageConfig := service.MessageConfig{EditWindow: cfg.MessageEditWindow}
+	messageService := service.NewMessageService(messageRepo, messageStatRepo, reactionRepo, threadRepo, chatRepo, userRepo, userBlockRepo, hub, notifSvc, mentionSvc, messageConfig)
+	scheduledMsgService := service.NewScheduledMessageService(scheduledMsgRepo, chatRepo, messageService, service.DefaultScheduledMessageConfig())
+	groupService := service.NewGroupService(chatRepo, messageRepo, messageStatRepo, userRepo, userBlockRepo, privacyPolicy, hub, notifSvc)
+	groupInviteService := service.NewGroupInviteService(groupInviteRepo, chatRepo, messageRepo, messageStatRepo, userRepo, userBlockRepo, privacyPolicy, hub, notifSvc)
+	broadcastService := service.NewBroadcastService(broadcastRepo, contactRepo, userRepo, chatService, messageService)
 	topicService := service.NewTopicService(topicRepo, topicMsgRepo, chatRepo, userRepo, hub)
-	topicMsgService := service.NewTopicMessageService(topicMsgRepo, topicRepo, hub)
+	topicMsgService := service.NewTopicMessageService(topicMsgRepo, topicReactionRepo, topicRepo, hub, mentionSvc, messageConfig)
+	pollService := service.NewPollService(pollRepo, chatRepo, topicRepo, messageService, topicMsgService, hub)
 	storageSvc, err := service.NewStorageService(cfg)
 	if err != nil {
 		panic("failed to create storage service: " + err.Error())
@@ -135,11 +186,18 @@ func NewDependencies(cfg *config.Config, db *pgxpool.Pool, redisClient *redis.Cl
 	imageSvc := service.NewImageService()
 	mediaSvc := service.NewMediaService(mediaRepo, storageSvc, imageSvc)
 	templateSvc := service.NewTemplateService()
-	documentSvc := service.NewDocumentService(documentRepo, blockRepo, docHistoryRepo, userRepo, templateSvc, notifSvc)
-	blockSvc := service.NewBlockService(blockRepo, documentRepo, docHistoryRepo)
+	// Realtime document editing; REST block writes and restores take
+	// documents over from it
+	crdtManager := ws.NewDocumentCRDTManager()
+	docVersionSvc := service.NewDocumentVersionService(docVersionRepo, documentRepo, blockRepo, docHistoryRepo, crdtManager)
+	documentSvc := service.NewDocumentService(documentRepo, blockRepo, docHistoryRepo, userRepo, templateSvc, notifSvc, docVersionSvc)
+	blockSvc := service.NewBlockService(blockRepo, documentRepo, docHistoryRepo, mentionSvc, docVersionSvc, crdtManager)
+	crdtManager.SetStore(service.NewDocumentCRDTStore(blockRepo, documentRepo, mentionSvc, docVersionSvc), 0)
+	crdtManager.SetCluster(hub, ws.NewRedisDocumentLease(redisClient))
+	docCommentSvc := service.NewDocumentCommentService(docCommentRepo, documentRepo, blockRepo, mentionSvc, hub)
 
 	// Status notifier: broadcasts online/offline events to contacts
-	_ = service.NewStatusNotifier(hub, contactRepo, userRepo, redisClient)
+	_ = service.NewStatusNotifier(hub, contactRepo, userRepo, privacyPolicy, redisClient)
 
 	// Auth handler
 	authHandler := NewAuthHandler(otpService, reverseOTPService, tokenService, sessionService, userRepo)
@@ -149,14 +207,23 @@ func NewDependencies(cfg *config.Config, db *pgxpool.Pool, redisClient *redis.Cl
 	chatHandler := NewChatHandler(chatService, messageService, groupService)
 	topicHandler := NewTopicHandler(topicService, topicMsgService)
 	mediaHandler := NewMediaHandler(mediaSvc)
-	documentHandler := NewDocumentHandler(documentSvc, blockSvc, templateSvc)
+	documentHandler := NewDocumentHandler(documentSvc, blockSvc, templateSvc, docVersionSvc)
+	docCommentHandler := NewDocumentCommentHandler(docCommentSvc)
 	entitySvc := service.NewEntityService(entityRepo, userRepo, documentRepo)
 	entityHandler := NewEntityHandler(entitySvc)
 	notifHandler := NewNotificationHandler(notifSvc)
+	mentionHandler := NewMentionHandler(mentionSvc)
+	scheduledMsgHandler := NewScheduledMessageHandler(scheduledMsgService)
 	searchSvc := service.NewSearchService(searchRepo, chatRepo)
 	searchHandler := NewSearchHandler(searchSvc)
 	backupSvc := service.NewBackupService(backupRepo, userRepo, chatRepo, messageRepo, contactRepo, documentRepo)
 	backupHandler := NewBackupHandler(backupSvc)
+	moderationSvc := service.NewModerationService(userBlockRepo, abuseReportRepo, userRepo, chatRepo, messageRepo)
+	moderationHandler := NewModerationHandler(moderationSvc)
+	groupInviteHandler := NewGroupInviteHandler(groupInviteService)
+	broadcastHandler := NewBroadcastHandler(broadcastService)
+	pollHandler := NewPollHandler(pollService)
+	wsHandler := NewWSHandler(hub, cfg.JWTSecret, sessionService, documentSvc, crdtManager, chatRepo, topicRepo, messageStatRepo, userRepo, userBlockRepo, privacyPolicy, redisClient)
 
 	deps := &Dependencies{
 		Config: cfg,
@@ -180,10 +247,18 @@ func NewDependencies(cfg *config.Config, db *pgxpool.Pool, redisClient *redis.Cl
 		MediaService:        mediaSvc,
 		DocumentService:     documentSvc,
 		BlockService:        blockSvc,
+		DocVersionService:   docVersionSvc,
+		DocCommentService:   docCommentSvc,
 		TemplateService:     templateSvc,
 		NotificationService: notifSvc,
+		MentionService:      mentionSvc,
+		ScheduledMsgService: scheduledMsgService,
 		SearchService:       searchSvc,
 		BackupService:       backupSvc,
+		ModerationService:   moderationSvc,
+		GroupInviteService:  groupInviteService,
+		BroadcastService:    broadcastService,
+		PollService:         pollService,
 
 		UserRepo:        userRepo,
 		ContactRepo:     contactRepo,
@@ -195,11 +270,20 @@ func NewDependencies(cfg *config.Config, db *pgxpool.Pool, redisClient *redis.Cl
 		EntityRepo:      entityRepo,
 		MessageStatRepo: messageStatRepo,
 		DocHistoryRepo:  docHistoryRepo,
+		DocVersionRepo:  docVersionRepo,
+		DocCommentRepo:  docCommentRepo,
 		TopicMsgRepo:    topicMsgRepo,
 		MediaRepo:       mediaRepo,
 		DeviceTokenRepo: deviceTokenRepo,
 		SearchRepo:      searchRepo,
 		BackupRepo:      backupRepo,
+		UserBlockRepo:   userBlockRepo,
+		GroupInviteRepo: groupInviteRepo,
+		BroadcastRepo:   broadcastRepo,
+		PollRepo:        pollRepo,
+
+		ScheduledDispatcher: service.NewScheduledDispatcher(scheduledMsgService, cfg.ScheduledDispatchInterval),
+		MessageReaper:       service.NewMessageReaper(messageRepo, storageSvc, hub, cfg.MessageReapInterval),
 
 		AuthHandler:         authHandler,
 		WebhookHandler:      webhookHandler,
@@ -209,11 +293,18 @@ func NewDependencies(cfg *config.Config, db *pgxpool.Pool, redisClient *redis.Cl
 		TopicHandler:        topicHandler,
 		MediaHandler:        mediaHandler,
 		DocumentHandler:     documentHandler,
+		DocCommentHandler:   docCommentHandler,
 		EntityHandler:       entityHandler,
 		NotificationHandler: notifHandler,
+		MentionHandler:      mentionHandler,
+		ScheduledMsgHandler: scheduledMsgHandler,
 		SearchHandler:       searchHandler,
 		BackupHandler:       backupHandler,
-		WSHandler:           NewWSHandler(hub, cfg.JWTSecret, chatRepo, topicRepo, messageStatRepo, redisClient),
+		ModerationHandler:   moderationHandler,
+		GroupInviteHandler:  groupInviteHandler,
+		BroadcastHandler:    broadcastHandler,
+		PollHandler:         pollHandler,
+		WSHandler:           wsHandler,
 	}
 
 	return deps
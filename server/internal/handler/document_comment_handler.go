@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/otoritech/chatat/internal/service"
+	"github.com/otoritech/chatat/pkg/apperror"
+	"github.com/otoritech/chatat/pkg/response"
+)
+
+// DocumentCommentHandler handles block comment endpoints.
+type DocumentCommentHandler struct {
+	commentService service.DocumentCommentService
+}
+
+// NewDocumentCommentHandler creates a new DocumentCommentHandler.
+func NewDocumentCommentHandler(commentService service.DocumentCommentService) *DocumentCommentHandler {
+	return &DocumentCommentHandler{commentService: commentService}
+}
+
+type replyCommentRequest struct {
+	Content string `json:"content"`
+}
+
+// List handles GET /api/v1/documents/{id}/comments?blockId=
+func (h *DocumentCommentHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, docID, ok := commentRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	var blockID *uuid.UUID
+	if raw := r.URL.Query().Get("blockId"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			response.Error(w, apperror.BadRequest("format block ID tidak valid"))
+			return
+		}
+		blockID = &id
+	}
+
+	threads, err := h.commentService.ListThreads(r.Context(), docID, userID, blockID)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	response.OK(w, threads)
+}
+
+// Create handles POST /api/v1/documents/{id}/comments
+func (h *DocumentCommentHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, docID, ok := commentRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	var input service.AddCommentInput
+	if err := DecodeJSON(r, &input); err != nil {
+		response.Error(w, apperror.BadRequest("body request tidak valid"))
+		return
+	}
+
+	comment, err := h.commentService.AddComment(r.Context(), docID, userID, input)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	response.Created(w, comment)
+}
+
+// Reply handles POST /api/v1/documents/{id}/comments/{commentId}/replies
+func (h *DocumentCommentHandler) Reply(w http.ResponseWriter, r *http.Request) {
+	userID, docID, ok := commentRequestIDs(w, r)
+	if !ok {
+		return
+	}
+	commentID, ok := commentIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req replyCommentRequest
+	if err := DecodeJSON(r, &req); err != nil {
+		response.Error(w, apperror.BadRequest("body request tidak valid"))
+		return
+	}
+
+	reply, err := h.commentService.Reply(r.Context(), docID, commentID, userID, req.Content)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	response.Created(w, reply)
+}
+
+// Resolve handles POST /api/v1/documents/{id}/comments/{commentId}/resolve
+func (h *DocumentCommentHandler) Resolve(w http.ResponseWriter, r *http.Request) {
+	userID, docID, ok := commentRequestIDs(w, r)
+	if !ok {
+		return
+	}
+	commentID, ok := commentIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	thread, err := h.commentService.ResolveThread(r.Context(), docID, commentID, userID)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	response.OK(w, thread)
+}
+
+// Reopen handles POST /api/v1/documents/{id}/comments/{commentId}/reopen
+func (h *DocumentCommentHandler) Reopen(w http.ResponseWriter, r *http.Request) {
+	userID, docID, ok := commentRequestIDs(w, r)
+	if !ok {
+		return
+	}
+	commentID, ok := commentIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	thread, err := h.commentService.ReopenThread(r.Context(), docID, commentID, userID)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	response.OK(w, thread)
+}
+
+// Delete handles DELETE /api/v1/documents/{id}/comments/{commentId}
+func (h *DocumentCommentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, docID, ok := commentRequestIDs(w, r)
+	if !ok {
+		return
+	}
+	commentID, ok := commentIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.commentService.DeleteComment(r.Context(), docID, commentID, userID); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	response.OK(w, map[string]bool{"deleted": true})
+}
+
+// commentRequestIDs reads the authenticated user and the document ID,
+// writing the error response when either is missing.
+func commentRequestIDs(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("autentikasi diperlukan"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	docID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, apperror.BadRequest("format document ID tidak valid"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, docID, true
+}
+
+func commentIDParam(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	commentID, err := uuid.Parse(chi.URLParam(r, "commentId"))
+	if err != nil {
+		response.Error(w, apperror.BadRequest("format comment ID tidak valid"))
+		return uuid.Nil, false
+	}
+	return commentID, true
+}
@@ -0,0 +1,151 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otoritech/chatat/internal/handler"
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+func withCommentParams(r *http.Request, docID uuid.UUID, commentID string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", docID.String())
+	rctx.URLParams.Add("commentId", commentID)
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestDocumentCommentHandler_List(t *testing.T) {
+	userID := uuid.New()
+	docID := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		svc := &mockDocumentCommentService{threads: []*model.DocumentComment{{ID: uuid.New()}}}
+		w := httptest.NewRecorder()
+		handler.NewDocumentCommentHandler(svc).List(w, withDocIDParam(docAuthReq(http.MethodGet, "/documents/"+docID.String()+"/comments", nil, userID), docID))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Nil(t, svc.blockID)
+	})
+
+	t.Run("filtered by block", func(t *testing.T) {
+		blockID := uuid.New()
+		svc := &mockDocumentCommentService{}
+		w := httptest.NewRecorder()
+		handler.NewDocumentCommentHandler(svc).List(w, withDocIDParam(docAuthReq(http.MethodGet, "/documents/"+docID.String()+"/comments?blockId="+blockID.String(), nil, userID), docID))
+		assert.Equal(t, http.StatusOK, w.Code)
+		require.NotNil(t, svc.blockID)
+		assert.Equal(t, blockID, *svc.blockID)
+	})
+
+	t.Run("invalid block id", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.NewDocumentCommentHandler(&mockDocumentCommentService{}).List(w, withDocIDParam(docAuthReq(http.MethodGet, "/documents/"+docID.String()+"/comments?blockId=abc", nil, userID), docID))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/documents/"+docID.String()+"/comments", nil)
+		handler.NewDocumentCommentHandler(&mockDocumentCommentService{}).List(w, withDocIDParam(r, docID))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestDocumentCommentHandler_Create(t *testing.T) {
+	userID := uuid.New()
+	docID := uuid.New()
+	blockID := uuid.New()
+
+	t.Run("success with range", func(t *testing.T) {
+		svc := &mockDocumentCommentService{comment: &model.DocumentComment{ID: uuid.New()}}
+		body, _ := json.Marshal(map[string]any{
+			"blockId": blockID,
+			"content": "Cek ini",
+			"range":   map[string]int{"start": 2, "end": 6},
+		})
+		w := httptest.NewRecorder()
+		handler.NewDocumentCommentHandler(svc).Create(w, withDocIDParam(docAuthReq(http.MethodPost, "/documents/"+docID.String()+"/comments", body, userID), docID))
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, blockID, svc.input.BlockID)
+		require.NotNil(t, svc.input.Range)
+		assert.Equal(t, 6, svc.input.Range.End)
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		svc := &mockDocumentCommentService{err: apperror.Forbidden("anda tidak memiliki akses ke dokumen ini")}
+		body, _ := json.Marshal(map[string]any{"blockId": blockID, "content": "Cek"})
+		w := httptest.NewRecorder()
+		handler.NewDocumentCommentHandler(svc).Create(w, withDocIDParam(docAuthReq(http.MethodPost, "/documents/"+docID.String()+"/comments", body, userID), docID))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.NewDocumentCommentHandler(&mockDocumentCommentService{}).Create(w, withDocIDParam(docAuthReq(http.MethodPost, "/documents/"+docID.String()+"/comments", []byte("{"), userID), docID))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestDocumentCommentHandler_Reply(t *testing.T) {
+	userID := uuid.New()
+	docID := uuid.New()
+	commentID := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		svc := &mockDocumentCommentService{comment: &model.DocumentComment{ID: uuid.New(), ParentID: &commentID}}
+		body, _ := json.Marshal(map[string]string{"content": "Setuju"})
+		w := httptest.NewRecorder()
+		handler.NewDocumentCommentHandler(svc).Reply(w, withCommentParams(docAuthReq(http.MethodPost, "/replies", body, userID), docID, commentID.String()))
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "Setuju", svc.content)
+	})
+
+	t.Run("invalid comment id", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{"content": "Setuju"})
+		w := httptest.NewRecorder()
+		handler.NewDocumentCommentHandler(&mockDocumentCommentService{}).Reply(w, withCommentParams(docAuthReq(http.MethodPost, "/replies", body, userID), docID, "abc"))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestDocumentCommentHandler_ResolveReopenDelete(t *testing.T) {
+	userID := uuid.New()
+	docID := uuid.New()
+	commentID := uuid.New()
+
+	t.Run("resolve", func(t *testing.T) {
+		svc := &mockDocumentCommentService{comment: &model.DocumentComment{ID: commentID}}
+		w := httptest.NewRecorder()
+		handler.NewDocumentCommentHandler(svc).Resolve(w, withCommentParams(docAuthReq(http.MethodPost, "/resolve", nil, userID), docID, commentID.String()))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("reopen not resolved", func(t *testing.T) {
+		svc := &mockDocumentCommentService{err: apperror.BadRequest("thread belum diselesaikan")}
+		w := httptest.NewRecorder()
+		handler.NewDocumentCommentHandler(svc).Reopen(w, withCommentParams(docAuthReq(http.MethodPost, "/reopen", nil, userID), docID, commentID.String()))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.NewDocumentCommentHandler(&mockDocumentCommentService{}).Delete(w, withCommentParams(docAuthReq(http.MethodDelete, "/comments", nil, userID), docID, commentID.String()))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("delete not found", func(t *testing.T) {
+		svc := &mockDocumentCommentService{err: apperror.NotFound("comment", commentID.String())}
+		w := httptest.NewRecorder()
+		handler.NewDocumentCommentHandler(svc).Delete(w, withCommentParams(docAuthReq(http.MethodDelete, "/comments", nil, userID), docID, commentID.String()))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -19,6 +20,7 @@ type DocumentHandler struct {
 	documentService service.DocumentService
 	blockService    service.BlockService
 	templateService service.TemplateService
+	versionService  service.DocumentVersionService
 }
 
 // NewDocumentHandler creates a new DocumentHandler.
@@ -26,11 +28,13 @@ func NewDocumentHandler(
 	documentService service.DocumentService,
 	blockService service.BlockService,
 	templateService service.TemplateService,
+	versionService service.DocumentVersionService,
 ) *DocumentHandler {
 	return &DocumentHandler{
 		documentService: documentService,
 		blockService:    blockService,
 		templateService: templateService,
+		versionService:  versionService,
 	}
 }
 
@@ -109,6 +113,10 @@ type addSignerRequest struct {
 	UserID string `json:"userId"`
 }
 
+type createVersionRequest struct {
+	Label string `json:"label"`
+}
+
 // -- Document endpoints --
 
 // Create handles POST /api/v1/documents
@@ -834,6 +842,152 @@ func (h *DocumentHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
 	response.OK(w, history)
 }
 
+// -- Version endpoints --
+
+// ListVersions handles GET /api/v1/documents/{id}/versions
+func (h *DocumentHandler) ListVersions(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("autentikasi diperlukan"))
+		return
+	}
+
+	docID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, apperror.BadRequest("format document ID tidak valid"))
+		return
+	}
+
+	versions, err := h.versionService.ListVersions(r.Context(), docID, userID)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	response.OK(w, versions)
+}
+
+// CreateVersion handles POST /api/v1/documents/{id}/versions
+func (h *DocumentHandler) CreateVersion(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("autentikasi diperlukan"))
+		return
+	}
+
+	docID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, apperror.BadRequest("format document ID tidak valid"))
+		return
+	}
+
+	var req createVersionRequest
+	if err := DecodeJSON(r, &req); err != nil {
+		response.Error(w, apperror.BadRequest("body request tidak valid"))
+		return
+	}
+
+	version, err := h.versionService.CreateVersion(r.Context(), docID, userID, req.Label)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	response.Created(w, version)
+}
+
+// GetVersion handles GET /api/v1/documents/{id}/versions/{version}
+func (h *DocumentHandler) GetVersion(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("autentikasi diperlukan"))
+		return
+	}
+
+	docID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, apperror.BadRequest("format document ID tidak valid"))
+		return
+	}
+
+	number, err := parseVersionNumber(chi.URLParam(r, "version"))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	version, err := h.versionService.GetVersion(r.Context(), docID, userID, number)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	response.OK(w, version)
+}
+
+// DiffVersions handles GET /api/v1/documents/{id}/versions/diff?from=&to=
+func (h *DocumentHandler) DiffVersions(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("autentikasi diperlukan"))
+		return
+	}
+
+	docID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, apperror.BadRequest("format document ID tidak valid"))
+		return
+	}
+
+	from, err := parseVersionNumber(r.URL.Query().Get("from"))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	to, err := parseVersionNumber(r.URL.Query().Get("to"))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	diff, err := h.versionService.DiffVersions(r.Context(), docID, userID, from, to)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	response.OK(w, diff)
+}
+
+// RestoreVersion handles POST /api/v1/documents/{id}/versions/{version}/restore
+func (h *DocumentHandler) RestoreVersion(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("autentikasi diperlukan"))
+		return
+	}
+
+	docID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, apperror.BadRequest("format document ID tidak valid"))
+		return
+	}
+
+	number, err := parseVersionNumber(chi.URLParam(r, "version"))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	version, err := h.versionService.RestoreVersion(r.Context(), docID, userID, number)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	response.OK(w, version)
+}
+
 // -- Template endpoints --
 
 // ListTemplates handles GET /api/v1/templates
@@ -844,6 +998,15 @@ func (h *DocumentHandler) ListTemplates(w http.ResponseWriter, r *http.Request)
 
 // -- Helper --
 
+// parseVersionNumber parses a document version number from a path or query value.
+func parseVersionNumber(raw string) (int, error) {
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		return 0, apperror.BadRequest("nomor versi tidak valid")
+	}
+	return n, nil
+}
+
 func handleError(w http.ResponseWriter, err error) {
 	if appErr, ok := err.(*apperror.AppError); ok {
 		response.Error(w, appErr)
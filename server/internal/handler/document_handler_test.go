@@ -39,7 +39,18 @@ func withDocIDParam(r *http.Request, id uuid.UUID) *http.Request {
 }
 
 func newDocHandler(docSvc *mockDocumentService, blockSvc *mockBlockService, tmplSvc *mockTemplateService) *handler.DocumentHandler {
-	return handler.NewDocumentHandler(docSvc, blockSvc, tmplSvc)
+	return handler.NewDocumentHandler(docSvc, blockSvc, tmplSvc, &mockDocumentVersionService{})
+}
+
+func newVersionHandler(versionSvc *mockDocumentVersionService) *handler.DocumentHandler {
+	return handler.NewDocumentHandler(&mockDocumentService{}, &mockBlockService{}, &mockTemplateService{}, versionSvc)
+}
+
+func withVersionParams(r *http.Request, docID uuid.UUID, version string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", docID.String())
+	rctx.URLParams.Add("version", version)
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
 }
 
 // --- Document CRUD ---
@@ -1633,6 +1644,134 @@ func TestDocumentHandler_GetHistory(t *testing.T) {
 	})
 }
 
+// --- Version endpoints ---
+
+func TestDocumentHandler_ListVersions(t *testing.T) {
+	userID := uuid.New()
+	docID := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		svc := &mockDocumentVersionService{versions: []*model.DocumentVersion{{Version: 2}, {Version: 1}}}
+		w := httptest.NewRecorder()
+		newVersionHandler(svc).ListVersions(w, withDocIDParam(docAuthReq(http.MethodGet, "/documents/"+docID.String()+"/versions", nil, userID), docID))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		svc := &mockDocumentVersionService{err: apperror.Forbidden("anda tidak memiliki akses ke dokumen ini")}
+		w := httptest.NewRecorder()
+		newVersionHandler(svc).ListVersions(w, withDocIDParam(docAuthReq(http.MethodGet, "/documents/"+docID.String()+"/versions", nil, userID), docID))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/documents/"+docID.String()+"/versions", nil)
+		newVersionHandler(&mockDocumentVersionService{}).ListVersions(w, withDocIDParam(r, docID))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestDocumentHandler_CreateVersion(t *testing.T) {
+	userID := uuid.New()
+	docID := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		svc := &mockDocumentVersionService{version: &model.DocumentVersion{Version: 3, Label: "Draf final"}}
+		body, _ := json.Marshal(map[string]string{"label": "Draf final"})
+		w := httptest.NewRecorder()
+		newVersionHandler(svc).CreateVersion(w, withDocIDParam(docAuthReq(http.MethodPost, "/documents/"+docID.String()+"/versions", body, userID), docID))
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "Draf final", svc.label)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		newVersionHandler(&mockDocumentVersionService{}).CreateVersion(w, withDocIDParam(docAuthReq(http.MethodPost, "/documents/"+docID.String()+"/versions", []byte("{"), userID), docID))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestDocumentHandler_GetVersion(t *testing.T) {
+	userID := uuid.New()
+	docID := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		svc := &mockDocumentVersionService{version: &model.DocumentVersion{Version: 2}}
+		w := httptest.NewRecorder()
+		newVersionHandler(svc).GetVersion(w, withVersionParams(docAuthReq(http.MethodGet, "/documents/"+docID.String()+"/versions/2", nil, userID), docID, "2"))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 2, svc.number)
+	})
+
+	t.Run("invalid version", func(t *testing.T) {
+		for _, version := range []string{"abc", "0", "-1"} {
+			w := httptest.NewRecorder()
+			newVersionHandler(&mockDocumentVersionService{}).GetVersion(w, withVersionParams(docAuthReq(http.MethodGet, "/documents/"+docID.String()+"/versions/"+version, nil, userID), docID, version))
+			assert.Equal(t, http.StatusBadRequest, w.Code, version)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		svc := &mockDocumentVersionService{err: apperror.NotFound("document version", docID.String())}
+		w := httptest.NewRecorder()
+		newVersionHandler(svc).GetVersion(w, withVersionParams(docAuthReq(http.MethodGet, "/documents/"+docID.String()+"/versions/9", nil, userID), docID, "9"))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestDocumentHandler_DiffVersions(t *testing.T) {
+	userID := uuid.New()
+	docID := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		svc := &mockDocumentVersionService{diff: &model.DocumentVersionDiff{From: 1, To: 3}}
+		w := httptest.NewRecorder()
+		newVersionHandler(svc).DiffVersions(w, withDocIDParam(docAuthReq(http.MethodGet, "/documents/"+docID.String()+"/versions/diff?from=1&to=3", nil, userID), docID))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 1, svc.from)
+		assert.Equal(t, 3, svc.to)
+	})
+
+	t.Run("missing to", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		newVersionHandler(&mockDocumentVersionService{}).DiffVersions(w, withDocIDParam(docAuthReq(http.MethodGet, "/documents/"+docID.String()+"/versions/diff?from=1", nil, userID), docID))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestDocumentHandler_RestoreVersion(t *testing.T) {
+	userID := uuid.New()
+	docID := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		from := 1
+		svc := &mockDocumentVersionService{version: &model.DocumentVersion{Version: 4, Reason: model.VersionReasonRestored, RestoredFrom: &from}}
+		w := httptest.NewRecorder()
+		newVersionHandler(svc).RestoreVersion(w, withVersionParams(docAuthReq(http.MethodPost, "/documents/"+docID.String()+"/versions/1/restore", nil, userID), docID, "1"))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 1, svc.number)
+	})
+
+	t.Run("locked document", func(t *testing.T) {
+		svc := &mockDocumentVersionService{err: apperror.Forbidden("dokumen terkunci, tidak dapat memulihkan versi")}
+		w := httptest.NewRecorder()
+		newVersionHandler(svc).RestoreVersion(w, withVersionParams(docAuthReq(http.MethodPost, "/documents/"+docID.String()+"/versions/1/restore", nil, userID), docID, "1"))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("invalid doc id", func(t *testing.T) {
+		r := docAuthReq(http.MethodPost, "/documents/invalid/versions/1/restore", nil, userID)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "invalid")
+		rctx.URLParams.Add("version", "1")
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+		w := httptest.NewRecorder()
+		newVersionHandler(&mockDocumentVersionService{}).RestoreVersion(w, r)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
 func TestDocumentHandler_ListTemplates(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		templates := []*service.DocumentTemplate{{ID: "basic", Name: "Basic"}}
@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/otoritech/chatat/internal/service"
+	"github.com/otoritech/chatat/pkg/apperror"
+	"github.com/otoritech/chatat/pkg/response"
+)
+
+// GroupInviteHandler handles group invite link and join request endpoints.
+type GroupInviteHandler struct {
+	inviteService service.GroupInviteService
+}
+
+// NewGroupInviteHandler creates a new group invite handler.
+func NewGroupInviteHandler(inviteService service.GroupInviteService) *GroupInviteHandler {
+	return &GroupInviteHandler{inviteService: inviteService}
+}
+
+// Create handles POST /api/v1/chats/{id}/invites
+func (h *GroupInviteHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	chatID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid chat id"))
+		return
+	}
+
+	var input service.CreateInviteInput
+	if err := DecodeJSON(r, &input); err != nil {
+		response.Error(w, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	invite, err := h.inviteService.CreateInvite(r.Context(), chatID, userID, input)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.Created(w, invite)
+}
+
+// List handles GET /api/v1/chats/{id}/invites
+func (h *GroupInviteHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	chatID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid chat id"))
+		return
+	}
+
+	invites, err := h.inviteService.ListInvites(r.Context(), chatID, userID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, invites)
+}
+
+// Revoke handles DELETE /api/v1/chats/{id}/invites/{inviteId}
+func (h *GroupInviteHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	chatID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid chat id"))
+		return
+	}
+
+	inviteID, err := GetPathUUID(r, "inviteId")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid invite id"))
+		return
+	}
+
+	if err := h.inviteService.RevokeInvite(r.Context(), chatID, inviteID, userID); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// Preview handles GET /api/v1/invites/{code}
+func (h *GroupInviteHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	if _, err := GetUserID(r); err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	preview, err := h.inviteService.PreviewInvite(r.Context(), GetPathParam(r, "code"))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, preview)
+}
+
+// Join handles POST /api/v1/invites/{code}/join
+func (h *GroupInviteHandler) Join(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	result, err := h.inviteService.JoinWithInvite(r.Context(), GetPathParam(r, "code"), userID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	if result.Joined {
+		response.OK(w, result)
+		return
+	}
+	response.Created(w, result)
+}
+
+// ListJoinRequests handles GET /api/v1/chats/{id}/join-requests
+func (h *GroupInviteHandler) ListJoinRequests(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	chatID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid chat id"))
+		return
+	}
+
+	requests, err := h.inviteService.ListJoinRequests(r.Context(), chatID, userID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, requests)
+}
+
+// ApproveJoinRequest handles POST /api/v1/chats/{id}/join-requests/{requestId}/approve
+func (h *GroupInviteHandler) ApproveJoinRequest(w http.ResponseWriter, r *http.Request) {
+	h.decideJoinRequest(w, r, h.inviteService.ApproveJoinRequest)
+}
+
+// RejectJoinRequest handles POST /api/v1/chats/{id}/join-requests/{requestId}/reject
+func (h *GroupInviteHandler) RejectJoinRequest(w http.ResponseWriter, r *http.Request) {
+	h.decideJoinRequest(w, r, h.inviteService.RejectJoinRequest)
+}
+
+func (h *GroupInviteHandler) decideJoinRequest(w http.ResponseWriter, r *http.Request, decide func(ctx context.Context, chatID, requestID, userID uuid.UUID) error) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	chatID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid chat id"))
+		return
+	}
+
+	requestID, err := GetPathUUID(r, "requestId")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid request id"))
+		return
+	}
+
+	if err := decide(r.Context(), chatID, requestID, userID); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
@@ -0,0 +1,180 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/otoritech/chatat/internal/handler"
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/internal/service"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+func withRouteParams(r *http.Request, params map[string]string) *http.Request {
+	rctx := chi.NewRouteContext()
+	for k, v := range params {
+		rctx.URLParams.Add(k, v)
+	}
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestGroupInviteHandler_Create(t *testing.T) {
+	userID := uuid.New()
+	chatID := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		h := handler.NewGroupInviteHandler(&mockGroupInviteService{invite: &model.GroupInvite{ID: uuid.New(), Code: "ABCDEF123456"}})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodPost, "/api/v1/chats/"+chatID.String()+"/invites", []byte(`{"requiresApproval":true,"maxUses":10}`), userID)
+		h.Create(w, withChatIDParam(r, chatID))
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), "ABCDEF123456")
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		h := handler.NewGroupInviteHandler(&mockGroupInviteService{err: apperror.Forbidden("only admins can perform this action")})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodPost, "/api/v1/chats/"+chatID.String()+"/invites", []byte(`{}`), userID)
+		h.Create(w, withChatIDParam(r, chatID))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		h := handler.NewGroupInviteHandler(&mockGroupInviteService{})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodPost, "/api/v1/chats/"+chatID.String()+"/invites", []byte(`{"code":"x"}`), userID)
+		h.Create(w, withChatIDParam(r, chatID))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestGroupInviteHandler_ListAndRevoke(t *testing.T) {
+	userID := uuid.New()
+	chatID := uuid.New()
+	inviteID := uuid.New()
+
+	t.Run("list", func(t *testing.T) {
+		h := handler.NewGroupInviteHandler(&mockGroupInviteService{invites: []*model.GroupInvite{{ID: inviteID, Code: "CODE"}}})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodGet, "/api/v1/chats/"+chatID.String()+"/invites", nil, userID)
+		h.List(w, withChatIDParam(r, chatID))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), inviteID.String())
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		h := handler.NewGroupInviteHandler(&mockGroupInviteService{})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodDelete, "/api/v1/chats/"+chatID.String()+"/invites/"+inviteID.String(), nil, userID)
+		h.Revoke(w, withRouteParams(r, map[string]string{"id": chatID.String(), "inviteId": inviteID.String()}))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("revoke invalid id", func(t *testing.T) {
+		h := handler.NewGroupInviteHandler(&mockGroupInviteService{})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodDelete, "/api/v1/chats/"+chatID.String()+"/invites/x", nil, userID)
+		h.Revoke(w, withRouteParams(r, map[string]string{"id": chatID.String(), "inviteId": "x"}))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestGroupInviteHandler_PreviewAndJoin(t *testing.T) {
+	userID := uuid.New()
+	chatID := uuid.New()
+
+	t.Run("preview", func(t *testing.T) {
+		svc := &mockGroupInviteService{preview: &service.GroupPreview{ChatID: chatID, Name: "Keluarga", MemberCount: 3}}
+		h := handler.NewGroupInviteHandler(svc)
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodGet, "/api/v1/invites/ABC", nil, userID)
+		h.Preview(w, withRouteParams(r, map[string]string{"code": "ABC"}))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Keluarga")
+		assert.Equal(t, "ABC", svc.code)
+	})
+
+	t.Run("preview unauthenticated", func(t *testing.T) {
+		h := handler.NewGroupInviteHandler(&mockGroupInviteService{})
+		w := httptest.NewRecorder()
+		h.Preview(w, httptest.NewRequest(http.MethodGet, "/api/v1/invites/ABC", nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("join", func(t *testing.T) {
+		h := handler.NewGroupInviteHandler(&mockGroupInviteService{result: &service.JoinResult{Chat: &model.Chat{ID: chatID}, Joined: true}})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodPost, "/api/v1/invites/ABC/join", nil, userID)
+		h.Join(w, withRouteParams(r, map[string]string{"code": "ABC"}))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"joined":true`)
+	})
+
+	t.Run("join pending approval", func(t *testing.T) {
+		h := handler.NewGroupInviteHandler(&mockGroupInviteService{result: &service.JoinResult{
+			Chat:    &model.Chat{ID: chatID},
+			Request: &model.JoinRequest{ID: uuid.New(), Status: model.JoinRequestPending},
+		}})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodPost, "/api/v1/invites/ABC/join", nil, userID)
+		h.Join(w, withRouteParams(r, map[string]string{"code": "ABC"}))
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"pending"`)
+	})
+
+	t.Run("join invalid invite", func(t *testing.T) {
+		h := handler.NewGroupInviteHandler(&mockGroupInviteService{err: apperror.BadRequest("invite link is no longer valid")})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodPost, "/api/v1/invites/ABC/join", nil, userID)
+		h.Join(w, withRouteParams(r, map[string]string{"code": "ABC"}))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestGroupInviteHandler_JoinRequests(t *testing.T) {
+	userID := uuid.New()
+	chatID := uuid.New()
+	requestID := uuid.New()
+	params := map[string]string{"id": chatID.String(), "requestId": requestID.String()}
+
+	t.Run("list", func(t *testing.T) {
+		h := handler.NewGroupInviteHandler(&mockGroupInviteService{requests: []*model.JoinRequest{{ID: requestID}}})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodGet, "/api/v1/chats/"+chatID.String()+"/join-requests", nil, userID)
+		h.ListJoinRequests(w, withChatIDParam(r, chatID))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), requestID.String())
+	})
+
+	t.Run("approve", func(t *testing.T) {
+		svc := &mockGroupInviteService{}
+		h := handler.NewGroupInviteHandler(svc)
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodPost, "/api/v1/chats/"+chatID.String()+"/join-requests/"+requestID.String()+"/approve", nil, userID)
+		h.ApproveJoinRequest(w, withRouteParams(r, params))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, requestID, svc.decided)
+	})
+
+	t.Run("reject not found", func(t *testing.T) {
+		h := handler.NewGroupInviteHandler(&mockGroupInviteService{err: apperror.NotFound("join request", requestID.String())})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodPost, "/api/v1/chats/"+chatID.String()+"/join-requests/"+requestID.String()+"/reject", nil, userID)
+		h.RejectJoinRequest(w, withRouteParams(r, params))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid request id", func(t *testing.T) {
+		h := handler.NewGroupInviteHandler(&mockGroupInviteService{})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodPost, "/api/v1/chats/"+chatID.String()+"/join-requests/x/approve", nil, userID)
+		h.ApproveJoinRequest(w, withRouteParams(r, map[string]string{"id": chatID.String(), "requestId": "x"}))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
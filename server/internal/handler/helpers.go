@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"net"
 	"net/http"
 	"strconv"
 
@@ -36,6 +37,20 @@ func GetUserID(r *http.Request) (uuid.UUID, error) {
 	return id, nil
 }
 
+// GetDeviceID extracts the device ID of the authenticated session, if any.
+func GetDeviceID(r *http.Request) string {
+	id, _ := middleware.GetDeviceID(r.Context())
+	return id
+}
+
+// GetClientIP returns the caller's IP address without the port.
+func GetClientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
 // GetPathParam extracts a URL path parameter.
 func GetPathParam(r *http.Request, key string) string {
 	return chi.URLParam(r, key)
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/otoritech/chatat/internal/service"
+	"github.com/otoritech/chatat/pkg/apperror"
+	"github.com/otoritech/chatat/pkg/response"
+)
+
+// MentionHandler handles mention feed endpoints.
+type MentionHandler struct {
+	mentionService service.MentionService
+}
+
+// NewMentionHandler creates a new mention handler.
+func NewMentionHandler(mentionService service.MentionService) *MentionHandler {
+	return &MentionHandler{mentionService: mentionService}
+}
+
+// List handles GET /api/v1/mentions
+func (h *MentionHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	cursor, limit := ParsePagination(r)
+
+	page, err := h.mentionService.List(r.Context(), userID, cursor, limit)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.Paginated(w, page.Mentions, response.PaginationMeta{
+		Cursor:  page.Cursor,
+		HasMore: page.HasMore,
+	})
+}
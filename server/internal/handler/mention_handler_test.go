@@ -0,0 +1,46 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/otoritech/chatat/internal/handler"
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/internal/service"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+func TestMentionHandler_List(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		h := handler.NewMentionHandler(&mockMentionService{page: &service.MentionPage{
+			Mentions: []*model.Mention{{ID: uuid.New(), UserID: userID, SourceType: model.MentionSourceChat, Preview: "@Budi cek"}},
+			Cursor:   "next",
+			HasMore:  true,
+		}})
+		w := httptest.NewRecorder()
+		h.List(w, authReqWithUserID(http.MethodGet, "/api/v1/mentions?limit=1", nil, userID))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "@Budi cek")
+		assert.Contains(t, w.Body.String(), `"hasMore":true`)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		h := handler.NewMentionHandler(&mockMentionService{})
+		w := httptest.NewRecorder()
+		h.List(w, httptest.NewRequest(http.MethodGet, "/api/v1/mentions", nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		h := handler.NewMentionHandler(&mockMentionService{err: apperror.BadRequest("invalid cursor format")})
+		w := httptest.NewRecorder()
+		h.List(w, authReqWithUserID(http.MethodGet, "/api/v1/mentions?cursor=x", nil, userID))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
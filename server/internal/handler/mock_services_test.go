@@ -16,6 +16,8 @@ type mockChatService struct {
 	chatList   []*service.ChatListItem
 	chatDetail *service.ChatDetail
 	chat       *model.Chat
+	settings   *model.ChatSettings
+	listFilter service.ChatListFilter
 	isMember   bool
 	err        error
 }
@@ -28,7 +30,8 @@ func (m *mockChatService) GetOrCreatePersonalChat(_ context.Context, _, _ uuid.U
 	return m.chat, m.err
 }
 
-func (m *mockChatService) ListChats(_ context.Context, _ uuid.UUID) ([]*service.ChatListItem, error) {
+func (m *mockChatService) ListChats(_ context.Context, _ uuid.UUID, filter service.ChatListFilter) ([]*service.ChatListItem, error) {
+	m.listFilter = filter
 	return m.chatList, m.err
 }
 
@@ -44,6 +47,14 @@ func (m *mockChatService) UnpinChat(_ context.Context, _, _ uuid.UUID) error {
 	return m.err
 }
 
+func (m *mockChatService) UpdateSettings(_ context.Context, _, _ uuid.UUID, _ model.UpdateChatSettingsInput) (*model.ChatSettings, error) {
+	return m.settings, m.err
+}
+
+func (m *mockChatService) SetDisappearingTimer(_ context.Context, _, _ uuid.UUID, _ int) (*model.Chat, error) {
+	return m.chat, m.err
+}
+
 func (m *mockChatService) IsMember(_ context.Context, _, _ uuid.UUID) (bool, error) {
 	return m.isMember, m.err
 }
@@ -54,9 +65,50 @@ type mockMessageService struct {
 	message     *model.Message
 	messagePage *service.MessagePage
 	messages    []*model.Message
+	reactions   []model.ReactionSummary
+	edits       []*model.MessageEdit
+	receipts    []*model.MessageReceipt
+	lastReadID  *uuid.UUID
+	thread      *service.ThreadPage
 	err         error
 }
 
+func (m *mockMessageService) EditMessage(_ context.Context, _, _, _ uuid.UUID, _ string) (*model.Message, error) {
+	return m.message, m.err
+}
+
+func (m *mockMessageService) GetEditHistory(_ context.Context, _, _, _ uuid.UUID) ([]*model.MessageEdit, error) {
+	return m.edits, m.err
+}
+
+func (m *mockMessageService) GetThread(_ context.Context, _, _, _ uuid.UUID, _ string, _ int) (*service.ThreadPage, error) {
+	return m.thread, m.err
+}
+
+func (m *mockMessageService) FollowThread(_ context.Context, _, _, _ uuid.UUID) error {
+	return m.err
+}
+
+func (m *mockMessageService) UnfollowThread(_ context.Context, _, _, _ uuid.UUID) error {
+	return m.err
+}
+
+func (m *mockMessageService) UpdateLiveLocation(_ context.Context, _, _, _ uuid.UUID, _, _ float64) (*model.Message, error) {
+	return m.message, m.err
+}
+
+func (m *mockMessageService) StopLiveLocation(_ context.Context, _, _, _ uuid.UUID) (*model.Message, error) {
+	return m.message, m.err
+}
+
+func (m *mockMessageService) AddReaction(_ context.Context, _, _, _ uuid.UUID, _ string) ([]model.ReactionSummary, error) {
+	return m.reactions, m.err
+}
+
+func (m *mockMessageService) RemoveReaction(_ context.Context, _, _, _ uuid.UUID, _ string) ([]model.ReactionSummary, error) {
+	return m.reactions, m.err
+}
+
 func (m *mockMessageService) SendMessage(_ context.Context, _ service.SendMessageInput) (*model.Message, error) {
 	return m.message, m.err
 }
@@ -77,10 +129,15 @@ func (m *mockMessageService) SearchMessages(_ context.Context, _ uuid.UUID, _ st
 	return m.messages, m.err
 }
 
-func (m *mockMessageService) MarkChatAsRead(_ context.Context, _, _ uuid.UUID) error {
+func (m *mockMessageService) MarkChatAsRead(_ context.Context, _, _ uuid.UUID, lastReadID *uuid.UUID) error {
+	m.lastReadID = lastReadID
 	return m.err
 }
 
+func (m *mockMessageService) GetReceipts(_ context.Context, _, _, _ uuid.UUID) ([]*model.MessageReceipt, error) {
+	return m.receipts, m.err
+}
+
 // --- Mock GroupService ---
 
 type mockGroupService struct {
@@ -109,6 +166,18 @@ func (m *mockGroupService) PromoteToAdmin(_ context.Context, _, _, _ uuid.UUID)
 	return m.err
 }
 
+func (m *mockGroupService) DemoteAdmin(_ context.Context, _, _, _ uuid.UUID) error {
+	return m.err
+}
+
+func (m *mockGroupService) TransferOwnership(_ context.Context, _, _, _ uuid.UUID) error {
+	return m.err
+}
+
+func (m *mockGroupService) UpdatePermissions(_ context.Context, _, _ uuid.UUID, _ model.UpdateGroupPermissionsInput) (*model.Chat, error) {
+	return m.chat, m.err
+}
+
 func (m *mockGroupService) LeaveGroup(_ context.Context, _, _ uuid.UUID) error {
 	return m.err
 }
@@ -207,7 +276,7 @@ func (m *mockNotificationService) SendToUsers(_ context.Context, _ []uuid.UUID,
 	return m.err
 }
 
-func (m *mockNotificationService) SendToChat(_ context.Context, _ uuid.UUID, _ uuid.UUID, _ model.Notification) error {
+func (m *mockNotificationService) SendToChat(_ context.Context, _ uuid.UUID, _ []uuid.UUID, _ model.Notification) error {
 	return m.err
 }
 
@@ -314,6 +383,13 @@ func (m *mockDocumentService) GetByID(_ context.Context, _, _ uuid.UUID) (*servi
 	return m.docFull, m.err
 }
 
+func (m *mockDocumentService) GetAccess(_ context.Context, _, _ uuid.UUID) (*service.DocumentAccess, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &service.DocumentAccess{Document: m.doc, CanEdit: m.doc != nil && !m.doc.Locked}, nil
+}
+
 func (m *mockDocumentService) ListByContext(_ context.Context, _ string, _ uuid.UUID) ([]*service.DocumentListItem, error) {
 	return m.docList, m.err
 }
@@ -484,9 +560,27 @@ func (m *mockTopicService) DeleteTopic(_ context.Context, _, _ uuid.UUID) error
 type mockTopicMessageService struct {
 	message     *model.TopicMessage
 	messagePage *service.TopicMessagePage
+	reactions   []model.ReactionSummary
+	edits       []*model.MessageEdit
 	err         error
 }
 
+func (m *mockTopicMessageService) EditMessage(_ context.Context, _, _, _ uuid.UUID, _ string) (*model.TopicMessage, error) {
+	return m.message, m.err
+}
+
+func (m *mockTopicMessageService) GetEditHistory(_ context.Context, _, _, _ uuid.UUID) ([]*model.MessageEdit, error) {
+	return m.edits, m.err
+}
+
+func (m *mockTopicMessageService) AddReaction(_ context.Context, _, _, _ uuid.UUID, _ string) ([]model.ReactionSummary, error) {
+	return m.reactions, m.err
+}
+
+func (m *mockTopicMessageService) RemoveReaction(_ context.Context, _, _, _ uuid.UUID, _ string) ([]model.ReactionSummary, error) {
+	return m.reactions, m.err
+}
+
 func (m *mockTopicMessageService) SendMessage(_ context.Context, _ service.SendTopicMessageInput) (*model.TopicMessage, error) {
 	return m.message, m.err
 }
@@ -546,6 +640,16 @@ func (m *mockTokenService) Generate(_ context.Context, _ uuid.UUID) (*service.To
 	return m.tokenPair, m.err
 }
 
+func (m *mockTokenService) GenerateForDevice(_ context.Context, _ uuid.UUID, _ string) (*service.TokenPair, error) {
+	return m.tokenPair, m.err
+}
+
+func (m *mockTokenService) RevokeDevice(_ context.Context, _ uuid.UUID, _ string) error {
+	return m.err
+}
+
+func (m *mockTokenService) OnRefreshReuse(_ service.RefreshReuseHandler) {}
+
 func (m *mockTokenService) Validate(_ string) (*service.Claims, error) {
 	return m.claims, m.err
 }
@@ -561,10 +665,27 @@ func (m *mockTokenService) Revoke(_ context.Context, _, _ string) error {
 // --- Mock SessionService ---
 
 type mockSessionService struct {
-	err error
+	sessions []*service.Session
+	err      error
 }
 
-func (m *mockSessionService) Register(_ context.Context, _ uuid.UUID, _, _ string) error {
+func (m *mockSessionService) Register(_ context.Context, _ uuid.UUID, _ service.SessionDevice) error {
+	return m.err
+}
+
+func (m *mockSessionService) Touch(_ context.Context, _ uuid.UUID, _, _ string) error {
+	return m.err
+}
+
+func (m *mockSessionService) List(_ context.Context, _ uuid.UUID, _ string) ([]*service.Session, error) {
+	return m.sessions, m.err
+}
+
+func (m *mockSessionService) Revoke(_ context.Context, _ uuid.UUID, _ string) error {
+	return m.err
+}
+
+func (m *mockSessionService) RevokeOthers(_ context.Context, _ uuid.UUID, _ string) error {
 	return m.err
 }
 
@@ -626,3 +747,268 @@ func (m *mockUserRepo) Delete(_ context.Context, _ uuid.UUID) error {
 
 // ensure mockUserRepo implements repository.UserRepository
 var _ repository.UserRepository = (*mockUserRepo)(nil)
+
+// --- Mock MentionService ---
+
+type mockMentionService struct {
+	page *service.MentionPage
+	err  error
+}
+
+func (m *mockMentionService) Record(_ context.Context, _ model.MentionTarget, _ []uuid.UUID) error {
+	return m.err
+}
+func (m *mockMentionService) Clear(_ context.Context, _ uuid.UUID) error {
+	return m.err
+}
+func (m *mockMentionService) ForItems(_ context.Context, _ []uuid.UUID) (map[uuid.UUID][]uuid.UUID, error) {
+	return map[uuid.UUID][]uuid.UUID{}, m.err
+}
+func (m *mockMentionService) List(_ context.Context, _ uuid.UUID, _ string, _ int) (*service.MentionPage, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if m.page != nil {
+		return m.page, nil
+	}
+	return &service.MentionPage{Mentions: []*model.Mention{}}, nil
+}
+
+// --- Mock ScheduledMessageService ---
+
+type mockScheduledMessageService struct {
+	message  *model.ScheduledMessage
+	messages []*model.ScheduledMessage
+	err      error
+}
+
+func (m *mockScheduledMessageService) Schedule(_ context.Context, _ service.ScheduleMessageInput) (*model.ScheduledMessage, error) {
+	return m.message, m.err
+}
+func (m *mockScheduledMessageService) List(_ context.Context, _, _ uuid.UUID) ([]*model.ScheduledMessage, error) {
+	return m.messages, m.err
+}
+func (m *mockScheduledMessageService) Update(_ context.Context, _, _, _ uuid.UUID, _ service.UpdateScheduledMessageInput) (*model.ScheduledMessage, error) {
+	return m.message, m.err
+}
+func (m *mockScheduledMessageService) Cancel(_ context.Context, _, _, _ uuid.UUID) error {
+	return m.err
+}
+func (m *mockScheduledMessageService) DispatchDue(_ context.Context) (int, error) {
+	return 0, m.err
+}
+
+// --- Mock ModerationService ---
+
+type mockModerationService struct {
+	blocked  []*model.BlockedUser
+	report   *model.AbuseReport
+	targetID uuid.UUID
+	input    service.ReportInput
+	err      error
+}
+
+func (m *mockModerationService) BlockUser(_ context.Context, _, targetID uuid.UUID) error {
+	m.targetID = targetID
+	return m.err
+}
+func (m *mockModerationService) UnblockUser(_ context.Context, _, targetID uuid.UUID) error {
+	m.targetID = targetID
+	return m.err
+}
+func (m *mockModerationService) ListBlocked(_ context.Context, _ uuid.UUID) ([]*model.BlockedUser, error) {
+	return m.blocked, m.err
+}
+func (m *mockModerationService) ReportUser(_ context.Context, _ uuid.UUID, input service.ReportInput) (*model.AbuseReport, error) {
+	m.input = input
+	return m.report, m.err
+}
+
+// --- Mock GroupInviteService ---
+
+type mockGroupInviteService struct {
+	invite   *model.GroupInvite
+	invites  []*model.GroupInvite
+	preview  *service.GroupPreview
+	result   *service.JoinResult
+	requests []*model.JoinRequest
+	code     string
+	decided  uuid.UUID
+	err      error
+}
+
+func (m *mockGroupInviteService) CreateInvite(_ context.Context, _, _ uuid.UUID, _ service.CreateInviteInput) (*model.GroupInvite, error) {
+	return m.invite, m.err
+}
+func (m *mockGroupInviteService) ListInvites(_ context.Context, _, _ uuid.UUID) ([]*model.GroupInvite, error) {
+	return m.invites, m.err
+}
+func (m *mockGroupInviteService) RevokeInvite(_ context.Context, _, _, _ uuid.UUID) error {
+	return m.err
+}
+func (m *mockGroupInviteService) PreviewInvite(_ context.Context, code string) (*service.GroupPreview, error) {
+	m.code = code
+	return m.preview, m.err
+}
+func (m *mockGroupInviteService) JoinWithInvite(_ context.Context, code string, _ uuid.UUID) (*service.JoinResult, error) {
+	m.code = code
+	return m.result, m.err
+}
+func (m *mockGroupInviteService) ListJoinRequests(_ context.Context, _, _ uuid.UUID) ([]*model.JoinRequest, error) {
+	return m.requests, m.err
+}
+func (m *mockGroupInviteService) ApproveJoinRequest(_ context.Context, _, requestID, _ uuid.UUID) error {
+	m.decided = requestID
+	return m.err
+}
+func (m *mockGroupInviteService) RejectJoinRequest(_ context.Context, _, requestID, _ uuid.UUID) error {
+	m.decided = requestID
+	return m.err
+}
+
+// --- Mock BroadcastService ---
+
+type mockBroadcastService struct {
+	list       *model.BroadcastList
+	lists      []*model.BroadcastList
+	broadcast  *model.Broadcast
+	broadcasts []*model.Broadcast
+	sent       service.SendBroadcastInput
+	removed    uuid.UUID
+	err        error
+}
+
+func (m *mockBroadcastService) CreateList(_ context.Context, _ uuid.UUID, _ service.CreateBroadcastListInput) (*model.BroadcastList, error) {
+	return m.list, m.err
+}
+func (m *mockBroadcastService) ListLists(_ context.Context, _ uuid.UUID) ([]*model.BroadcastList, error) {
+	return m.lists, m.err
+}
+func (m *mockBroadcastService) GetList(_ context.Context, _, _ uuid.UUID) (*model.BroadcastList, error) {
+	return m.list, m.err
+}
+func (m *mockBroadcastService) RenameList(_ context.Context, _, _ uuid.UUID, _ string) (*model.BroadcastList, error) {
+	return m.list, m.err
+}
+func (m *mockBroadcastService) DeleteList(_ context.Context, _, _ uuid.UUID) error {
+	return m.err
+}
+func (m *mockBroadcastService) AddRecipients(_ context.Context, _, _ uuid.UUID, _ []uuid.UUID) (*model.BroadcastList, error) {
+	return m.list, m.err
+}
+func (m *mockBroadcastService) RemoveRecipient(_ context.Context, _, _, recipientID uuid.UUID) error {
+	m.removed = recipientID
+	return m.err
+}
+func (m *mockBroadcastService) Send(_ context.Context, _, _ uuid.UUID, input service.SendBroadcastInput) (*model.Broadcast, error) {
+	m.sent = input
+	return m.broadcast, m.err
+}
+func (m *mockBroadcastService) ListBroadcasts(_ context.Context, _, _ uuid.UUID) ([]*model.Broadcast, error) {
+	return m.broadcasts, m.err
+}
+func (m *mockBroadcastService) GetBroadcast(_ context.Context, _, _, _ uuid.UUID) (*model.Broadcast, error) {
+	return m.broadcast, m.err
+}
+
+// --- Mock PollService ---
+
+type mockPollService struct {
+	poll      *model.Poll
+	input     service.CreatePollInput
+	positions []int
+	err       error
+}
+
+func (m *mockPollService) CreateChatPoll(_ context.Context, _, _ uuid.UUID, input service.CreatePollInput) (*model.Poll, error) {
+	m.input = input
+	return m.poll, m.err
+}
+func (m *mockPollService) CreateTopicPoll(_ context.Context, _, _ uuid.UUID, input service.CreatePollInput) (*model.Poll, error) {
+	m.input = input
+	return m.poll, m.err
+}
+func (m *mockPollService) GetPoll(_ context.Context, _, _ uuid.UUID) (*model.Poll, error) {
+	return m.poll, m.err
+}
+func (m *mockPollService) Vote(_ context.Context, _, _ uuid.UUID, positions []int) (*model.Poll, error) {
+	m.positions = positions
+	return m.poll, m.err
+}
+func (m *mockPollService) RetractVote(_ context.Context, _, _ uuid.UUID) (*model.Poll, error) {
+	return m.poll, m.err
+}
+func (m *mockPollService) ClosePoll(_ context.Context, _, _ uuid.UUID) (*model.Poll, error) {
+	return m.poll, m.err
+}
+
+// --- Mock DocumentVersionService ---
+
+type mockDocumentVersionService struct {
+	version  *model.DocumentVersion
+	versions []*model.DocumentVersion
+	diff     *model.DocumentVersionDiff
+	label    string
+	number   int
+	from, to int
+	err      error
+}
+
+func (m *mockDocumentVersionService) Capture(_ context.Context, _, _ uuid.UUID, _ model.VersionReason) (*model.DocumentVersion, error) {
+	return m.version, m.err
+}
+func (m *mockDocumentVersionService) CaptureEdit(_ context.Context, _, _ uuid.UUID) error {
+	return m.err
+}
+func (m *mockDocumentVersionService) CreateVersion(_ context.Context, _, _ uuid.UUID, label string) (*model.DocumentVersion, error) {
+	m.label = label
+	return m.version, m.err
+}
+func (m *mockDocumentVersionService) ListVersions(_ context.Context, _, _ uuid.UUID) ([]*model.DocumentVersion, error) {
+	return m.versions, m.err
+}
+func (m *mockDocumentVersionService) GetVersion(_ context.Context, _, _ uuid.UUID, version int) (*model.DocumentVersion, error) {
+	m.number = version
+	return m.version, m.err
+}
+func (m *mockDocumentVersionService) DiffVersions(_ context.Context, _, _ uuid.UUID, from, to int) (*model.DocumentVersionDiff, error) {
+	m.from, m.to = from, to
+	return m.diff, m.err
+}
+func (m *mockDocumentVersionService) RestoreVersion(_ context.Context, _, _ uuid.UUID, version int) (*model.DocumentVersion, error) {
+	m.number = version
+	return m.version, m.err
+}
+
+// --- Mock DocumentCommentService ---
+
+type mockDocumentCommentService struct {
+	comment *model.DocumentComment
+	threads []*model.DocumentComment
+	input   service.AddCommentInput
+	content string
+	blockID *uuid.UUID
+	err     error
+}
+
+func (m *mockDocumentCommentService) AddComment(_ context.Context, _, _ uuid.UUID, input service.AddCommentInput) (*model.DocumentComment, error) {
+	m.input = input
+	return m.comment, m.err
+}
+func (m *mockDocumentCommentService) Reply(_ context.Context, _, _, _ uuid.UUID, content string) (*model.DocumentComment, error) {
+	m.content = content
+	return m.comment, m.err
+}
+func (m *mockDocumentCommentService) ListThreads(_ context.Context, _, _ uuid.UUID, blockID *uuid.UUID) ([]*model.DocumentComment, error) {
+	m.blockID = blockID
+	return m.threads, m.err
+}
+func (m *mockDocumentCommentService) ResolveThread(_ context.Context, _, _, _ uuid.UUID) (*model.DocumentComment, error) {
+	return m.comment, m.err
+}
+func (m *mockDocumentCommentService) ReopenThread(_ context.Context, _, _, _ uuid.UUID) (*model.DocumentComment, error) {
+	return m.comment, m.err
+}
+func (m *mockDocumentCommentService) DeleteComment(_ context.Context, _, _, _ uuid.UUID) error {
+	return m.err
+}
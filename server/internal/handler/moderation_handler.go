@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/otoritech/chatat/internal/service"
+	"github.com/otoritech/chatat/pkg/apperror"
+	"github.com/otoritech/chatat/pkg/response"
+)
+
+// ModerationHandler handles user blocking and abuse report endpoints.
+type ModerationHandler struct {
+	moderationService service.ModerationService
+}
+
+// NewModerationHandler creates a new moderation handler.
+func NewModerationHandler(moderationService service.ModerationService) *ModerationHandler {
+	return &ModerationHandler{moderationService: moderationService}
+}
+
+// ListBlocked handles GET /api/v1/users/me/blocked
+func (h *ModerationHandler) ListBlocked(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	blocked, err := h.moderationService.ListBlocked(r.Context(), userID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, blocked)
+}
+
+// Block handles POST /api/v1/users/{userId}/block
+func (h *ModerationHandler) Block(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	targetID, err := GetPathUUID(r, "userId")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid userId"))
+		return
+	}
+
+	if err := h.moderationService.BlockUser(r.Context(), userID, targetID); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// Unblock handles DELETE /api/v1/users/{userId}/block
+func (h *ModerationHandler) Unblock(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	targetID, err := GetPathUUID(r, "userId")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid userId"))
+		return
+	}
+
+	if err := h.moderationService.UnblockUser(r.Context(), userID, targetID); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// Report handles POST /api/v1/reports
+func (h *ModerationHandler) Report(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	var input service.ReportInput
+	if err := DecodeJSON(r, &input); err != nil {
+		response.Error(w, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	report, err := h.moderationService.ReportUser(r.Context(), userID, input)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.Created(w, report)
+}
@@ -0,0 +1,123 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/otoritech/chatat/internal/handler"
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+func withUserIDParam(r *http.Request, userID string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("userId", userID)
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestModerationHandler_ListBlocked(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		h := handler.NewModerationHandler(&mockModerationService{blocked: []*model.BlockedUser{{UserID: uuid.New(), Name: "Spammer"}}})
+		w := httptest.NewRecorder()
+		h.ListBlocked(w, authReqWithUserID(http.MethodGet, "/api/v1/users/me/blocked", nil, userID))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Spammer")
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		h := handler.NewModerationHandler(&mockModerationService{})
+		w := httptest.NewRecorder()
+		h.ListBlocked(w, httptest.NewRequest(http.MethodGet, "/api/v1/users/me/blocked", nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestModerationHandler_BlockUnblock(t *testing.T) {
+	userID := uuid.New()
+	targetID := uuid.New()
+
+	t.Run("block", func(t *testing.T) {
+		svc := &mockModerationService{}
+		h := handler.NewModerationHandler(svc)
+		w := httptest.NewRecorder()
+		r := authReqWithUserID(http.MethodPost, "/api/v1/users/"+targetID.String()+"/block", nil, userID)
+		h.Block(w, withUserIDParam(r, targetID.String()))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, targetID, svc.targetID)
+	})
+
+	t.Run("unblock", func(t *testing.T) {
+		svc := &mockModerationService{}
+		h := handler.NewModerationHandler(svc)
+		w := httptest.NewRecorder()
+		r := authReqWithUserID(http.MethodDelete, "/api/v1/users/"+targetID.String()+"/block", nil, userID)
+		h.Unblock(w, withUserIDParam(r, targetID.String()))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, targetID, svc.targetID)
+	})
+
+	t.Run("invalid userId", func(t *testing.T) {
+		h := handler.NewModerationHandler(&mockModerationService{})
+		w := httptest.NewRecorder()
+		r := authReqWithUserID(http.MethodPost, "/api/v1/users/x/block", nil, userID)
+		h.Block(w, withUserIDParam(r, "x"))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		h := handler.NewModerationHandler(&mockModerationService{err: apperror.NotFound("user", targetID.String())})
+		w := httptest.NewRecorder()
+		r := authReqWithUserID(http.MethodPost, "/api/v1/users/"+targetID.String()+"/block", nil, userID)
+		h.Block(w, withUserIDParam(r, targetID.String()))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestModerationHandler_Report(t *testing.T) {
+	userID := uuid.New()
+	reportedID := uuid.New()
+	chatID := uuid.New()
+	msgID := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		svc := &mockModerationService{report: &model.AbuseReport{ID: uuid.New(), Status: model.ReportStatusOpen}}
+		h := handler.NewModerationHandler(svc)
+		body, _ := json.Marshal(map[string]interface{}{
+			"userId":     reportedID,
+			"chatId":     chatID,
+			"messageIds": []uuid.UUID{msgID},
+			"reason":     "spam",
+			"block":      true,
+		})
+		w := httptest.NewRecorder()
+		h.Report(w, authReqWithUserID(http.MethodPost, "/api/v1/reports", body, userID))
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, reportedID, svc.input.UserID)
+		assert.Equal(t, &chatID, svc.input.ChatID)
+		assert.Equal(t, []uuid.UUID{msgID}, svc.input.MessageIDs)
+		assert.True(t, svc.input.Block)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		h := handler.NewModerationHandler(&mockModerationService{})
+		w := httptest.NewRecorder()
+		h.Report(w, authReqWithUserID(http.MethodPost, "/api/v1/reports", []byte(`{"unknown":1}`), userID))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		h := handler.NewModerationHandler(&mockModerationService{})
+		w := httptest.NewRecorder()
+		h.Report(w, httptest.NewRequest(http.MethodPost, "/api/v1/reports", nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
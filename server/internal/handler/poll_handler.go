@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/otoritech/chatat/internal/service"
+	"github.com/otoritech/chatat/pkg/apperror"
+	"github.com/otoritech/chatat/pkg/response"
+)
+
+// PollHandler handles poll endpoints.
+type PollHandler struct {
+	pollService service.PollService
+}
+
+// NewPollHandler creates a new poll handler.
+func NewPollHandler(pollService service.PollService) *PollHandler {
+	return &PollHandler{pollService: pollService}
+}
+
+type votePollRequest struct {
+	Positions []int `json:"positions"`
+}
+
+// CreateInChat handles POST /api/v1/chats/{id}/polls
+func (h *PollHandler) CreateInChat(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	chatID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid chat id"))
+		return
+	}
+
+	var input service.CreatePollInput
+	if err := DecodeJSON(r, &input); err != nil {
+		response.Error(w, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	poll, err := h.pollService.CreateChatPoll(r.Context(), chatID, userID, input)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.Created(w, poll)
+}
+
+// CreateInTopic handles POST /api/v1/topics/{id}/polls
+func (h *PollHandler) CreateInTopic(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	topicID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid topic id"))
+		return
+	}
+
+	var input service.CreatePollInput
+	if err := DecodeJSON(r, &input); err != nil {
+		response.Error(w, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	poll, err := h.pollService.CreateTopicPoll(r.Context(), topicID, userID, input)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.Created(w, poll)
+}
+
+// Get handles GET /api/v1/polls/{pollId}
+func (h *PollHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	pollID, err := GetPathUUID(r, "pollId")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid poll id"))
+		return
+	}
+
+	poll, err := h.pollService.GetPoll(r.Context(), pollID, userID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, poll)
+}
+
+// Vote handles POST /api/v1/polls/{pollId}/votes
+func (h *PollHandler) Vote(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	pollID, err := GetPathUUID(r, "pollId")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid poll id"))
+		return
+	}
+
+	var req votePollRequest
+	if err := DecodeJSON(r, &req); err != nil {
+		response.Error(w, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	poll, err := h.pollService.Vote(r.Context(), pollID, userID, req.Positions)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, poll)
+}
+
+// RetractVote handles DELETE /api/v1/polls/{pollId}/votes
+func (h *PollHandler) RetractVote(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	pollID, err := GetPathUUID(r, "pollId")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid poll id"))
+		return
+	}
+
+	poll, err := h.pollService.RetractVote(r.Context(), pollID, userID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, poll)
+}
+
+// Close handles POST /api/v1/polls/{pollId}/close
+func (h *PollHandler) Close(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	pollID, err := GetPathUUID(r, "pollId")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid poll id"))
+		return
+	}
+
+	poll, err := h.pollService.ClosePoll(r.Context(), pollID, userID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, poll)
+}
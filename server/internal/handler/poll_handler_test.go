@@ -0,0 +1,115 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/otoritech/chatat/internal/handler"
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+func TestPollHandler_Create(t *testing.T) {
+	userID := uuid.New()
+	chatID := uuid.New()
+	topicID := uuid.New()
+	pollID := uuid.New()
+
+	t.Run("in chat", func(t *testing.T) {
+		mock := &mockPollService{poll: &model.Poll{ID: pollID, Question: "Makan di mana?"}}
+		h := handler.NewPollHandler(mock)
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodPost, "/api/v1/chats/"+chatID.String()+"/polls",
+			[]byte(`{"question":"Makan di mana?","options":["Sate","Bakso"],"multipleChoice":true}`), userID)
+		h.CreateInChat(w, withChatIDParam(r, chatID))
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), pollID.String())
+		assert.Equal(t, []string{"Sate", "Bakso"}, mock.input.Options)
+		assert.True(t, mock.input.MultipleChoice)
+	})
+
+	t.Run("in topic", func(t *testing.T) {
+		h := handler.NewPollHandler(&mockPollService{poll: &model.Poll{ID: pollID}})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodPost, "/api/v1/topics/"+topicID.String()+"/polls",
+			[]byte(`{"question":"Q","options":["A","B"]}`), userID)
+		h.CreateInTopic(w, withRouteParams(r, map[string]string{"id": topicID.String()}))
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		h := handler.NewPollHandler(&mockPollService{})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodPost, "/api/v1/chats/"+chatID.String()+"/polls", []byte(`{"votes":1}`), userID)
+		h.CreateInChat(w, withChatIDParam(r, chatID))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("not a member", func(t *testing.T) {
+		h := handler.NewPollHandler(&mockPollService{err: apperror.Forbidden("you are not a member of this chat")})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodPost, "/api/v1/chats/"+chatID.String()+"/polls", []byte(`{"question":"Q","options":["A","B"]}`), userID)
+		h.CreateInChat(w, withChatIDParam(r, chatID))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestPollHandler_Votes(t *testing.T) {
+	userID := uuid.New()
+	pollID := uuid.New()
+	params := map[string]string{"pollId": pollID.String()}
+
+	t.Run("get", func(t *testing.T) {
+		h := handler.NewPollHandler(&mockPollService{poll: &model.Poll{ID: pollID}})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodGet, "/api/v1/polls/"+pollID.String(), nil, userID)
+		h.Get(w, withRouteParams(r, params))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("vote", func(t *testing.T) {
+		mock := &mockPollService{poll: &model.Poll{ID: pollID, MyVotes: []int{1}}}
+		h := handler.NewPollHandler(mock)
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodPost, "/api/v1/polls/"+pollID.String()+"/votes", []byte(`{"positions":[1]}`), userID)
+		h.Vote(w, withRouteParams(r, params))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []int{1}, mock.positions)
+	})
+
+	t.Run("vote on closed poll", func(t *testing.T) {
+		h := handler.NewPollHandler(&mockPollService{err: apperror.BadRequest("this poll is closed")})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodPost, "/api/v1/polls/"+pollID.String()+"/votes", []byte(`{"positions":[0]}`), userID)
+		h.Vote(w, withRouteParams(r, params))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("retract", func(t *testing.T) {
+		h := handler.NewPollHandler(&mockPollService{poll: &model.Poll{ID: pollID}})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodDelete, "/api/v1/polls/"+pollID.String()+"/votes", nil, userID)
+		h.RetractVote(w, withRouteParams(r, params))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("close forbidden", func(t *testing.T) {
+		h := handler.NewPollHandler(&mockPollService{err: apperror.Forbidden("only the poll creator can close it")})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodPost, "/api/v1/polls/"+pollID.String()+"/close", nil, userID)
+		h.Close(w, withRouteParams(r, params))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("invalid poll id", func(t *testing.T) {
+		h := handler.NewPollHandler(&mockPollService{})
+		w := httptest.NewRecorder()
+		r := chatAuthReq(http.MethodGet, "/api/v1/polls/x", nil, userID)
+		h.Get(w, withRouteParams(r, map[string]string{"pollId": "x"}))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -70,10 +70,13 @@ func NewRouterWithRedis(cfg *config.Config, deps *Dependencies, redisClient *red
 
 		// Protected routes
 		r.Group(func(r chi.Router) {
-			r.Use(mw.Auth(cfg.JWTSecret))
+			r.Use(mw.Auth(cfg.JWTSecret, deps.SessionService))
 
 			// Auth (requires token)
 			r.Post("/auth/logout", deps.AuthHandler.Logout)
+			r.Get("/auth/sessions", deps.AuthHandler.ListSessions)
+			r.Post("/auth/sessions/revoke-others", deps.AuthHandler.RevokeOtherSessions)
+			r.Delete("/auth/sessions/{deviceId}", deps.AuthHandler.RevokeSession)
 
 			r.Route("/users", func(r chi.Router) {
 				r.Get("/me", deps.UserHandler.GetMe)
@@ -82,6 +85,9 @@ func NewRouterWithRedis(cfg *config.Config, deps *Dependencies, redisClient *red
 				r.Delete("/me", deps.UserHandler.DeleteAccount)
 				r.Get("/me/privacy", deps.UserHandler.GetPrivacySettings)
 				r.Put("/me/privacy", deps.UserHandler.UpdatePrivacySettings)
+				r.Get("/me/blocked", deps.ModerationHandler.ListBlocked)
+				r.Post("/{userId}/block", deps.ModerationHandler.Block)
+				r.Delete("/{userId}/block", deps.ModerationHandler.Unblock)
 			})
 
 			r.Route("/contacts", func(r chi.Router) {
@@ -100,23 +106,76 @@ func NewRouterWithRedis(cfg *config.Config, deps *Dependencies, redisClient *red
 					r.Delete("/", deps.ChatHandler.Delete)
 					r.Put("/pin", deps.ChatHandler.PinChat)
 					r.Delete("/pin", deps.ChatHandler.UnpinChat)
+					r.Put("/settings", deps.ChatHandler.UpdateSettings)
+					r.Put("/disappearing", deps.ChatHandler.SetDisappearingTimer)
+					r.Put("/permissions", deps.ChatHandler.UpdatePermissions)
 					r.Post("/read", deps.ChatHandler.MarkAsRead)
 					r.Get("/info", deps.ChatHandler.GetGroupInfo)
 					r.Post("/leave", deps.ChatHandler.LeaveGroup)
 					r.Post("/messages", deps.ChatHandler.SendMessage)
 					r.Get("/messages", deps.ChatHandler.ListMessages)
 					r.Get("/messages/search", deps.ChatHandler.SearchMessages)
+					r.Put("/messages/{messageId}", deps.ChatHandler.EditMessage)
 					r.Delete("/messages/{messageId}", deps.ChatHandler.DeleteMessage)
+					r.Get("/messages/{messageId}/edits", deps.ChatHandler.GetEditHistory)
+					r.Get("/messages/{messageId}/receipts", deps.ChatHandler.GetReceipts)
+					r.Get("/messages/{messageId}/thread", deps.ChatHandler.GetThread)
+					r.Post("/messages/{messageId}/thread/follow", deps.ChatHandler.FollowThread)
+					r.Delete("/messages/{messageId}/thread/follow", deps.ChatHandler.UnfollowThread)
 					r.Post("/messages/{messageId}/forward", deps.ChatHandler.ForwardMessage)
+					r.Put("/messages/{messageId}/location", deps.ChatHandler.UpdateLiveLocation)
+					r.Delete("/messages/{messageId}/location", deps.ChatHandler.StopLiveLocation)
+					r.Post("/messages/{messageId}/reactions", deps.ChatHandler.AddReaction)
+					r.Delete("/messages/{messageId}/reactions", deps.ChatHandler.RemoveReaction)
+					r.Post("/scheduled-messages", deps.ScheduledMsgHandler.Create)
+					r.Get("/scheduled-messages", deps.ScheduledMsgHandler.List)
+					r.Put("/scheduled-messages/{scheduledId}", deps.ScheduledMsgHandler.Update)
+					r.Delete("/scheduled-messages/{scheduledId}", deps.ScheduledMsgHandler.Cancel)
 					r.Post("/members", deps.ChatHandler.AddMember)
 					r.Delete("/members/{memberID}", deps.ChatHandler.RemoveMember)
 					r.Put("/members/{memberID}/admin", deps.ChatHandler.PromoteToAdmin)
+					r.Delete("/members/{memberID}/admin", deps.ChatHandler.DemoteAdmin)
+					r.Put("/members/{memberID}/owner", deps.ChatHandler.TransferOwnership)
+					r.Post("/invites", deps.GroupInviteHandler.Create)
+					r.Get("/invites", deps.GroupInviteHandler.List)
+					r.Delete("/invites/{inviteId}", deps.GroupInviteHandler.Revoke)
+					r.Get("/join-requests", deps.GroupInviteHandler.ListJoinRequests)
+					r.Post("/join-requests/{requestId}/approve", deps.GroupInviteHandler.ApproveJoinRequest)
+					r.Post("/join-requests/{requestId}/reject", deps.GroupInviteHandler.RejectJoinRequest)
+					r.Post("/polls", deps.PollHandler.CreateInChat)
 					r.Get("/topics", deps.TopicHandler.ListByChat)
 					r.Get("/documents", deps.DocumentHandler.ListByChat)
 					r.Get("/search", deps.SearchHandler.SearchInChat)
 				})
 			})
 
+			r.Route("/invites/{code}", func(r chi.Router) {
+				r.Get("/", deps.GroupInviteHandler.Preview)
+				r.Post("/join", deps.GroupInviteHandler.Join)
+			})
+
+			r.Route("/polls/{pollId}", func(r chi.Router) {
+				r.Get("/", deps.PollHandler.Get)
+				r.Post("/votes", deps.PollHandler.Vote)
+				r.Delete("/votes", deps.PollHandler.RetractVote)
+				r.Post("/close", deps.PollHandler.Close)
+			})
+
+			r.Route("/broadcast-lists", func(r chi.Router) {
+				r.Get("/", deps.BroadcastHandler.ListLists)
+				r.Post("/", deps.BroadcastHandler.CreateList)
+				r.Route("/{listId}", func(r chi.Router) {
+					r.Get("/", deps.BroadcastHandler.GetList)
+					r.Put("/", deps.BroadcastHandler.RenameList)
+					r.Delete("/", deps.BroadcastHandler.DeleteList)
+					r.Post("/recipients", deps.BroadcastHandler.AddRecipients)
+					r.Delete("/recipients/{userId}", deps.BroadcastHandler.RemoveRecipient)
+					r.Post("/send", deps.BroadcastHandler.Send)
+					r.Get("/broadcasts", deps.BroadcastHandler.ListBroadcasts)
+					r.Get("/broadcasts/{broadcastId}", deps.BroadcastHandler.GetBroadcast)
+				})
+			})
+
 			r.Route("/topics", func(r chi.Router) {
 				r.Get("/", deps.TopicHandler.ListByUser)
 				r.Post("/", deps.TopicHandler.Create)
@@ -128,7 +187,12 @@ func NewRouterWithRedis(cfg *config.Config, deps *Dependencies, redisClient *red
 					r.Delete("/members/{userId}", deps.TopicHandler.RemoveMember)
 					r.Post("/messages", deps.TopicHandler.SendMessage)
 					r.Get("/messages", deps.TopicHandler.ListMessages)
+					r.Put("/messages/{messageId}", deps.TopicHandler.EditMessage)
 					r.Delete("/messages/{messageId}", deps.TopicHandler.DeleteMessage)
+					r.Get("/messages/{messageId}/edits", deps.TopicHandler.GetEditHistory)
+					r.Post("/messages/{messageId}/reactions", deps.TopicHandler.AddReaction)
+					r.Delete("/messages/{messageId}/reactions", deps.TopicHandler.RemoveReaction)
+					r.Post("/polls", deps.PollHandler.CreateInTopic)
 					r.Get("/documents", deps.DocumentHandler.ListByTopic)
 				})
 			})
@@ -176,6 +240,21 @@ func NewRouterWithRedis(cfg *config.Config, deps *Dependencies, redisClient *red
 					// History endpoint
 					r.Get("/history", deps.DocumentHandler.GetHistory)
 
+					// Version endpoints
+					r.Get("/versions", deps.DocumentHandler.ListVersions)
+					r.Post("/versions", deps.DocumentHandler.CreateVersion)
+					r.Get("/versions/diff", deps.DocumentHandler.DiffVersions)
+					r.Get("/versions/{version}", deps.DocumentHandler.GetVersion)
+					r.Post("/versions/{version}/restore", deps.DocumentHandler.RestoreVersion)
+
+					// Comment endpoints
+					r.Get("/comments", deps.DocCommentHandler.List)
+					r.Post("/comments", deps.DocCommentHandler.Create)
+					r.Post("/comments/{commentId}/replies", deps.DocCommentHandler.Reply)
+					r.Post("/comments/{commentId}/resolve", deps.DocCommentHandler.Resolve)
+					r.Post("/comments/{commentId}/reopen", deps.DocCommentHandler.Reopen)
+					r.Delete("/comments/{commentId}", deps.DocCommentHandler.Delete)
+
 					// Entity linking endpoints
 					r.Get("/entities", deps.EntityHandler.GetDocumentEntities)
 					r.Post("/entities", deps.EntityHandler.LinkToDocument)
@@ -204,6 +283,9 @@ func NewRouterWithRedis(cfg *config.Config, deps *Dependencies, redisClient *red
 				r.Delete("/devices", deps.NotificationHandler.UnregisterDevice)
 			})
 
+			r.Get("/mentions", deps.MentionHandler.List)
+			r.Post("/reports", deps.ModerationHandler.Report)
+
 			r.Route("/search", func(r chi.Router) {
 				r.Get("/", deps.SearchHandler.SearchAll)
 				r.Get("/messages", deps.SearchHandler.SearchMessages)
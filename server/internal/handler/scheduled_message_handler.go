@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/otoritech/chatat/internal/service"
+	"github.com/otoritech/chatat/pkg/apperror"
+	"github.com/otoritech/chatat/pkg/response"
+)
+
+// ScheduledMessageHandler handles scheduled message endpoints.
+type ScheduledMessageHandler struct {
+	scheduledService service.ScheduledMessageService
+}
+
+// NewScheduledMessageHandler creates a new scheduled message handler.
+func NewScheduledMessageHandler(scheduledService service.ScheduledMessageService) *ScheduledMessageHandler {
+	return &ScheduledMessageHandler{scheduledService: scheduledService}
+}
+
+// Create handles POST /api/v1/chats/{id}/scheduled-messages
+func (h *ScheduledMessageHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	chatID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid chat id"))
+		return
+	}
+
+	var input service.ScheduleMessageInput
+	if err := DecodeJSON(r, &input); err != nil {
+		response.Error(w, apperror.BadRequest("invalid request body"))
+		return
+	}
+	input.ChatID = chatID
+	input.SenderID = userID
+
+	msg, err := h.scheduledService.Schedule(r.Context(), input)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.Created(w, msg)
+}
+
+// List handles GET /api/v1/chats/{id}/scheduled-messages
+func (h *ScheduledMessageHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	chatID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid chat id"))
+		return
+	}
+
+	messages, err := h.scheduledService.List(r.Context(), chatID, userID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, messages)
+}
+
+// Update handles PUT /api/v1/chats/{id}/scheduled-messages/{scheduledId}
+func (h *ScheduledMessageHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	chatID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid chat id"))
+		return
+	}
+
+	scheduledID, err := GetPathUUID(r, "scheduledId")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid scheduled message id"))
+		return
+	}
+
+	var input service.UpdateScheduledMessageInput
+	if err := DecodeJSON(r, &input); err != nil {
+		response.Error(w, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	msg, err := h.scheduledService.Update(r.Context(), chatID, scheduledID, userID, input)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, msg)
+}
+
+// Cancel handles DELETE /api/v1/chats/{id}/scheduled-messages/{scheduledId}
+func (h *ScheduledMessageHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	chatID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid chat id"))
+		return
+	}
+
+	scheduledID, err := GetPathUUID(r, "scheduledId")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid scheduled message id"))
+		return
+	}
+
+	if err := h.scheduledService.Cancel(r.Context(), chatID, scheduledID, userID); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
@@ -0,0 +1,131 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/otoritech/chatat/internal/handler"
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+func withScheduledIDParam(r *http.Request, chatID, scheduledID uuid.UUID) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", chatID.String())
+	rctx.URLParams.Add("scheduledId", scheduledID.String())
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestScheduledMessageHandler_Create(t *testing.T) {
+	userID := uuid.New()
+	chatID := uuid.New()
+	url := "/api/v1/chats/" + chatID.String() + "/scheduled-messages"
+	sendAt := time.Now().Add(time.Hour)
+
+	t.Run("success", func(t *testing.T) {
+		h := handler.NewScheduledMessageHandler(&mockScheduledMessageService{message: &model.ScheduledMessage{
+			ID: uuid.New(), ChatID: chatID, Content: "Selamat pagi", SendAt: sendAt, Status: model.ScheduledStatusPending,
+		}})
+		w := httptest.NewRecorder()
+		body, _ := json.Marshal(map[string]interface{}{"content": "Selamat pagi", "sendAt": sendAt})
+		h.Create(w, withChatIDParam(chatAuthReq(http.MethodPost, url, body, userID), chatID))
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"pending"`)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		h := handler.NewScheduledMessageHandler(&mockScheduledMessageService{})
+		w := httptest.NewRecorder()
+		h.Create(w, withChatIDParam(chatAuthReq(http.MethodPost, url, []byte("bad"), userID), chatID))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("send time in the past", func(t *testing.T) {
+		h := handler.NewScheduledMessageHandler(&mockScheduledMessageService{err: apperror.Validation("sendAt", "send time must be in the future")})
+		w := httptest.NewRecorder()
+		body, _ := json.Marshal(map[string]interface{}{"content": "Terlambat", "sendAt": time.Now().Add(-time.Hour)})
+		h.Create(w, withChatIDParam(chatAuthReq(http.MethodPost, url, body, userID), chatID))
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("no user", func(t *testing.T) {
+		h := handler.NewScheduledMessageHandler(&mockScheduledMessageService{})
+		w := httptest.NewRecorder()
+		h.Create(w, authReqNoUser(http.MethodPost, url, nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestScheduledMessageHandler_List(t *testing.T) {
+	userID := uuid.New()
+	chatID := uuid.New()
+	url := "/api/v1/chats/" + chatID.String() + "/scheduled-messages"
+
+	h := handler.NewScheduledMessageHandler(&mockScheduledMessageService{messages: []*model.ScheduledMessage{
+		{ID: uuid.New(), ChatID: chatID, Content: "Pengumuman", Status: model.ScheduledStatusPending},
+	}})
+	w := httptest.NewRecorder()
+	h.List(w, withChatIDParam(chatAuthReq(http.MethodGet, url, nil, userID), chatID))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Pengumuman")
+}
+
+func TestScheduledMessageHandler_Update(t *testing.T) {
+	userID := uuid.New()
+	chatID := uuid.New()
+	scheduledID := uuid.New()
+	url := "/api/v1/chats/" + chatID.String() + "/scheduled-messages/" + scheduledID.String()
+
+	t.Run("success", func(t *testing.T) {
+		h := handler.NewScheduledMessageHandler(&mockScheduledMessageService{message: &model.ScheduledMessage{ID: scheduledID, Content: "Diubah"}})
+		w := httptest.NewRecorder()
+		body, _ := json.Marshal(map[string]string{"content": "Diubah"})
+		h.Update(w, withScheduledIDParam(chatAuthReq(http.MethodPut, url, body, userID), chatID, scheduledID))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Diubah")
+	})
+
+	t.Run("already sent", func(t *testing.T) {
+		h := handler.NewScheduledMessageHandler(&mockScheduledMessageService{err: apperror.Conflict("scheduled message is no longer pending")})
+		w := httptest.NewRecorder()
+		body, _ := json.Marshal(map[string]string{"content": "Diubah"})
+		h.Update(w, withScheduledIDParam(chatAuthReq(http.MethodPut, url, body, userID), chatID, scheduledID))
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		h := handler.NewScheduledMessageHandler(&mockScheduledMessageService{})
+		w := httptest.NewRecorder()
+		h.Update(w, withChatIDParam(chatAuthReq(http.MethodPut, url, []byte("{}"), userID), chatID))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestScheduledMessageHandler_Cancel(t *testing.T) {
+	userID := uuid.New()
+	chatID := uuid.New()
+	scheduledID := uuid.New()
+	url := "/api/v1/chats/" + chatID.String() + "/scheduled-messages/" + scheduledID.String()
+
+	t.Run("success", func(t *testing.T) {
+		h := handler.NewScheduledMessageHandler(&mockScheduledMessageService{})
+		w := httptest.NewRecorder()
+		h.Cancel(w, withScheduledIDParam(chatAuthReq(http.MethodDelete, url, nil, userID), chatID, scheduledID))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		h := handler.NewScheduledMessageHandler(&mockScheduledMessageService{err: apperror.NotFound("scheduled message", scheduledID.String())})
+		w := httptest.NewRecorder()
+		h.Cancel(w, withScheduledIDParam(chatAuthReq(http.MethodDelete, url, nil, userID), chatID, scheduledID))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"encoding/json"
 	"net/http"
 
 	"github.com/google/uuid"
@@ -40,9 +41,10 @@ type updateTopicRequest struct {
 }
 
 type sendTopicMessageRequest struct {
-	Content   string  `json:"content"`
-	ReplyToID *string `json:"replyToId"`
-	Type      string  `json:"type"`
+	Content   string          `json:"content"`
+	ReplyToID *string         `json:"replyToId"`
+	Type      string          `json:"type"`
+	Metadata  json.RawMessage `json:"metadata"`
 }
 
 type addTopicMemberRequest struct {
@@ -300,6 +302,7 @@ func (h *TopicHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 		SenderID: userID,
 		Content:  req.Content,
 		Type:     model.MessageTypeText,
+		Metadata: req.Metadata,
 	}
 
 	if req.Type != "" {
@@ -383,3 +386,135 @@ func (h *TopicHandler) DeleteMessage(w http.ResponseWriter, r *http.Request) {
 
 	response.OK(w, map[string]string{"message": "message deleted"})
 }
+
+// EditMessage handles PUT /api/v1/topics/:id/messages/:messageId
+func (h *TopicHandler) EditMessage(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	topicID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid topic id"))
+		return
+	}
+
+	messageID, err := GetPathUUID(r, "messageId")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid messageId format"))
+		return
+	}
+
+	var req struct {
+		Content string `json:"content"`
+	}
+	if err := DecodeJSON(r, &req); err != nil {
+		response.Error(w, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	msg, err := h.topicMsgService.EditMessage(r.Context(), topicID, messageID, userID, req.Content)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, msg)
+}
+
+// GetEditHistory handles GET /api/v1/topics/:id/messages/:messageId/edits
+func (h *TopicHandler) GetEditHistory(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	topicID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid topic id"))
+		return
+	}
+
+	messageID, err := GetPathUUID(r, "messageId")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid messageId format"))
+		return
+	}
+
+	edits, err := h.topicMsgService.GetEditHistory(r.Context(), topicID, messageID, userID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, edits)
+}
+
+// AddReaction handles POST /api/v1/topics/:id/messages/:messageId/reactions
+func (h *TopicHandler) AddReaction(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	topicID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid topic id"))
+		return
+	}
+
+	messageID, err := GetPathUUID(r, "messageId")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid messageId format"))
+		return
+	}
+
+	var req struct {
+		Emoji string `json:"emoji"`
+	}
+	if err := DecodeJSON(r, &req); err != nil {
+		response.Error(w, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	reactions, err := h.topicMsgService.AddReaction(r.Context(), topicID, messageID, userID, req.Emoji)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, reactions)
+}
+
+// RemoveReaction handles DELETE /api/v1/topics/:id/messages/:messageId/reactions?emoji=
+func (h *TopicHandler) RemoveReaction(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserID(r)
+	if err != nil {
+		response.Error(w, apperror.Unauthorized("user not authenticated"))
+		return
+	}
+
+	topicID, err := GetPathUUID(r, "id")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid topic id"))
+		return
+	}
+
+	messageID, err := GetPathUUID(r, "messageId")
+	if err != nil {
+		response.Error(w, apperror.BadRequest("invalid messageId format"))
+		return
+	}
+
+	reactions, err := h.topicMsgService.RemoveReaction(r.Context(), topicID, messageID, userID, r.URL.Query().Get("emoji"))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	response.OK(w, reactions)
+}
@@ -881,3 +881,81 @@ func TestTopicHandler_DeleteMessage(t *testing.T) {
 		assert.Equal(t, http.StatusInternalServerError, w.Code)
 	})
 }
+
+func TestTopicHandler_Reactions(t *testing.T) {
+	userID := uuid.New()
+	tid := uuid.New()
+	msgID := uuid.New()
+	withParams := func(r *http.Request) *http.Request {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", tid.String())
+		rctx.URLParams.Add("messageId", msgID.String())
+		return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+	}
+	url := "/topics/" + tid.String() + "/messages/" + msgID.String() + "/reactions"
+
+	t.Run("add", func(t *testing.T) {
+		reactions := []model.ReactionSummary{{Emoji: "🎉", Count: 1, UserIDs: []uuid.UUID{userID}}}
+		h := handler.NewTopicHandler(&mockTopicService{}, &mockTopicMessageService{reactions: reactions})
+		w := httptest.NewRecorder()
+		body, _ := json.Marshal(map[string]string{"emoji": "🎉"})
+		h.AddReaction(w, withParams(topicAuthReq(http.MethodPost, url, body, userID)))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "🎉")
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		h := handler.NewTopicHandler(&mockTopicService{}, &mockTopicMessageService{})
+		w := httptest.NewRecorder()
+		h.RemoveReaction(w, withParams(topicAuthReq(http.MethodDelete, url+"?emoji=%F0%9F%8E%89", nil, userID)))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("invalid messageId", func(t *testing.T) {
+		h := handler.NewTopicHandler(&mockTopicService{}, &mockTopicMessageService{})
+		w := httptest.NewRecorder()
+		r := topicAuthReq(http.MethodDelete, url, nil, userID)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", tid.String())
+		rctx.URLParams.Add("messageId", "bad")
+		h.RemoveReaction(w, r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx)))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestTopicHandler_EditMessage(t *testing.T) {
+	userID := uuid.New()
+	tid := uuid.New()
+	msgID := uuid.New()
+	withParams := func(r *http.Request) *http.Request {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", tid.String())
+		rctx.URLParams.Add("messageId", msgID.String())
+		return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+	}
+	url := "/topics/" + tid.String() + "/messages/" + msgID.String()
+
+	t.Run("success", func(t *testing.T) {
+		h := handler.NewTopicHandler(&mockTopicService{}, &mockTopicMessageService{message: &model.TopicMessage{ID: msgID, Content: "edited"}})
+		w := httptest.NewRecorder()
+		body, _ := json.Marshal(map[string]string{"content": "edited"})
+		h.EditMessage(w, withParams(topicAuthReq(http.MethodPut, url, body, userID)))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("window expired", func(t *testing.T) {
+		h := handler.NewTopicHandler(&mockTopicService{}, &mockTopicMessageService{err: apperror.BadRequest("can only edit within 15m0s of sending")})
+		w := httptest.NewRecorder()
+		body, _ := json.Marshal(map[string]string{"content": "edited"})
+		h.EditMessage(w, withParams(topicAuthReq(http.MethodPut, url, body, userID)))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("history", func(t *testing.T) {
+		h := handler.NewTopicHandler(&mockTopicService{}, &mockTopicMessageService{edits: []*model.MessageEdit{{Content: "before"}}})
+		w := httptest.NewRecorder()
+		h.GetEditHistory(w, withParams(topicAuthReq(http.MethodGet, url+"/edits", nil, userID)))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "before")
+	})
+}
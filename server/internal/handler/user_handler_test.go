@@ -90,11 +90,11 @@ func (m *mockContactService) GetContacts(_ context.Context, _ uuid.UUID) ([]serv
 	return m.list, m.err
 }
 
-func (m *mockContactService) SearchByPhone(_ context.Context, _ string) (*model.User, error) {
+func (m *mockContactService) SearchByPhone(_ context.Context, _ uuid.UUID, _ string) (*model.User, error) {
 	return m.user, m.err
 }
 
-func (m *mockContactService) GetContactProfile(_ context.Context, _ uuid.UUID) (*service.ContactInfo, error) {
+func (m *mockContactService) GetContactProfile(_ context.Context, _, _ uuid.UUID) (*service.ContactInfo, error) {
 	return m.profile, m.err
 }
 
@@ -384,6 +384,14 @@ func TestContactHandler_Search(t *testing.T) {
 		assert.Equal(t, http.StatusOK, w.Code)
 	})
 
+	t.Run("unauthorized", func(t *testing.T) {
+		h := handler.NewContactHandler(&mockContactService{user: user})
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/contacts/search?phone=%2B6281234567890", nil)
+		w := httptest.NewRecorder()
+		h.Search(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
 	t.Run("missing phone", func(t *testing.T) {
 		h := handler.NewContactHandler(&mockContactService{})
 		req := authReq(http.MethodGet, "/api/v1/contacts/search", nil, uuid.New())
@@ -438,6 +446,13 @@ func TestContactHandler_GetProfile(t *testing.T) {
 		assert.Equal(t, http.StatusOK, w.Code)
 	})
 
+	t.Run("unauthorized", func(t *testing.T) {
+		h := handler.NewContactHandler(&mockContactService{profile: profile})
+		w := httptest.NewRecorder()
+		h.GetProfile(w, httptest.NewRequest(http.MethodGet, "/api/v1/contacts/"+contactID.String(), nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
 	t.Run("invalid userId", func(t *testing.T) {
 		h := handler.NewContactHandler(&mockContactService{profile: profile})
 		req := authReq(http.MethodGet, "/api/v1/contacts/not-a-uuid", nil, uuid.New())
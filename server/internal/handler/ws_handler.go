@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/otoritech/chatat/internal/repository"
+	"github.com/otoritech/chatat/internal/service"
 	"github.com/otoritech/chatat/internal/ws"
 	"github.com/otoritech/chatat/pkg/apperror"
 	"github.com/otoritech/chatat/pkg/response"
@@ -30,24 +32,42 @@ var upgrader = websocket.Upgrader{
 type WSHandler struct {
 	hub             *ws.Hub
 	jwtSecret       string
+	sessions        service.SessionService
+	documents       service.DocumentService
 	chatRepo        repository.ChatRepository
 	topicRepo       repository.TopicRepository
 	messageStatRepo repository.MessageStatusRepository
+	userRepo        repository.UserRepository
+	blockRepo       repository.UserBlockRepository
+	privacy         service.PrivacyPolicy
 	redis           *redis.Client
 	crdtManager     *ws.DocumentCRDTManager
 }
 
 // NewWSHandler creates a new WebSocket handler.
-func NewWSHandler(hub *ws.Hub, jwtSecret string, chatRepo repository.ChatRepository, topicRepo repository.TopicRepository, messageStatRepo repository.MessageStatusRepository, redisClient *redis.Client) *WSHandler {
-	return &WSHandler{
+func NewWSHandler(hub *ws.Hub, jwtSecret string, sessions service.SessionService, documents service.DocumentService, crdtManager *ws.DocumentCRDTManager, chatRepo repository.ChatRepository, topicRepo repository.TopicRepository, messageStatRepo repository.MessageStatusRepository, userRepo repository.UserRepository, blockRepo repository.UserBlockRepository, privacy service.PrivacyPolicy, redisClient *redis.Client) *WSHandler {
+	h := &WSHandler{
 		hub:             hub,
 		jwtSecret:       jwtSecret,
+		sessions:        sessions,
+		documents:       documents,
 		chatRepo:        chatRepo,
 		topicRepo:       topicRepo,
 		messageStatRepo: messageStatRepo,
+		userRepo:        userRepo,
+		blockRepo:       blockRepo,
+		privacy:         privacy,
 		redis:           redisClient,
-		crdtManager:     ws.NewDocumentCRDTManager(),
+		crdtManager:     crdtManager,
 	}
+	crdtManager.SetForwardHandler(h.handleDocumentRequest)
+	return h
+}
+
+// FlushDocuments saves every open document's unsaved edits and hands the
+// documents back to the cluster, e.g. on shutdown.
+func (h *WSHandler) FlushDocuments(ctx context.Context) error {
+	return h.crdtManager.ReleaseAll(ctx)
 }
 
 // HandleConnection upgrades an HTTP connection to WebSocket.
@@ -58,11 +78,17 @@ func (h *WSHandler) HandleConnection(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userID, err := h.validateToken(tokenString)
+	userID, tokenDeviceID, err := h.validateToken(tokenString)
 	if err != nil {
 		response.Error(w, apperror.Unauthorized("invalid or expired token"))
 		return
 	}
+	if tokenDeviceID != "" && h.sessions != nil {
+		if err := h.sessions.Validate(r.Context(), userID, tokenDeviceID); err != nil {
+			handleError(w, err)
+			return
+		}
+	}
 
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
@@ -70,7 +96,19 @@ func (h *WSHandler) HandleConnection(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client := ws.NewClient(h.hub, conn, userID)
+	// Each device keeps its own connection; clients that don't identify
+	// themselves get a per-connection ID so they never displace each other.
+	// A token bound to a device always connects as that device, so revoking
+	// its session can find the connection.
+	deviceID := tokenDeviceID
+	if deviceID == "" {
+		deviceID = r.URL.Query().Get("deviceId")
+	}
+	if deviceID == "" {
+		deviceID = uuid.New().String()
+	}
+
+	client := ws.NewClient(h.hub, conn, userID, deviceID)
 	client.MessageHandler = h.handleClientMessage
 	h.hub.RegisterClient(client)
 
@@ -148,6 +186,8 @@ func (h *WSHandler) handleClientMessage(client *ws.Client, msg ws.WSMessage) {
 		h.handleDocUpdate(client, msg.Payload)
 	case ws.WSTypeDocLock:
 		h.handleDocLockEvent(client, msg.Payload)
+	case ws.WSTypeResume:
+		h.handleResume(client, msg.Payload)
 	default:
 		log.Debug().
 			Str("user_id", client.UserID.String()).
@@ -209,8 +249,61 @@ func (h *WSHandler) handleTyping(client *ws.Client, payload json.RawMessage) {
 		return
 	}
 
-	roomID := "chat:" + p.ChatID
-	h.hub.SendToRoom(roomID, data, client.UserID)
+	h.broadcastTyping(client.UserID, p.ChatID, data)
+}
+
+// broadcastTyping sends a typing event to the other members of a chat,
+// skipping those the typist hides their online status from or has a block
+// with either way.
+func (h *WSHandler) broadcastTyping(userID uuid.UUID, chatIDStr string, data []byte) {
+	chatID, err := uuid.Parse(chatIDStr)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	user, err := h.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return
+	}
+
+	blocked, err := h.blockRepo.FindBlockedPeers(ctx, userID)
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID.String()).Msg("failed to find blocked peers for typing event")
+		return
+	}
+
+	online := user.PrivacySettings.OnlineVisibility
+	if len(blocked) == 0 && online != service.VisibilityContacts && online != service.VisibilityNobody {
+		h.hub.SendToRoom("chat:"+chatIDStr, data, userID)
+		return
+	}
+
+	members, err := h.chatRepo.GetMembers(ctx, chatID)
+	if err != nil {
+		log.Warn().Err(err).Str("chat_id", chatIDStr).Msg("failed to get members for typing event")
+		return
+	}
+
+	viewerIDs := make([]uuid.UUID, 0, len(members))
+	for _, m := range members {
+		if m.UserID != userID {
+			viewerIDs = append(viewerIDs, m.UserID)
+		}
+	}
+
+	visibility, err := h.privacy.VisibilityFor(ctx, user, viewerIDs)
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID.String()).Msg("failed to evaluate privacy for typing event")
+		return
+	}
+	for _, viewerID := range viewerIDs {
+		if visibility[viewerID].Online {
+			h.hub.SendToUser(viewerID, data)
+		}
+	}
 }
 
 // --- Message Ack (Delivered) ---
@@ -263,11 +356,20 @@ func (h *WSHandler) handleReadReceipt(client *ws.Client, payload json.RawMessage
 		return
 	}
 
+	var lastReadID *uuid.UUID
+	if p.LastReadMessageID != "" {
+		id, err := uuid.Parse(p.LastReadMessageID)
+		if err != nil {
+			return
+		}
+		lastReadID = &id
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	// Mark all messages in chat as read for this user
-	if err := h.messageStatRepo.MarkChatAsRead(ctx, chatID, client.UserID); err != nil {
+	// Move the user's read cursor and mark everything up to it as read
+	if err := h.messageStatRepo.MarkChatAsRead(ctx, chatID, client.UserID, lastReadID); err != nil {
 		log.Warn().Err(err).
 			Str("chat_id", p.ChatID).
 			Str("user_id", client.UserID.String()).
@@ -275,12 +377,18 @@ func (h *WSHandler) handleReadReceipt(client *ws.Client, payload json.RawMessage
 		return
 	}
 
+	// Users who turned off read receipts read silently
+	user, err := h.userRepo.FindByID(ctx, client.UserID)
+	if err != nil || !user.PrivacySettings.ReadReceipts {
+		return
+	}
+
 	// Broadcast read receipt to chat room
-	lastReadID := p.LastReadMessageID
-	if lastReadID == "" {
-		lastReadID = p.ChatID // fallback
+	readID := p.LastReadMessageID
+	if readID == "" {
+		readID = p.ChatID // fallback
 	}
-	h.broadcastMessageStatus(p.ChatID, lastReadID, client.UserID.String(), "read")
+	h.broadcastMessageStatus(p.ChatID, readID, client.UserID.String(), "read")
 }
 
 // --- Helpers ---
@@ -319,10 +427,34 @@ func (h *WSHandler) broadcastMessageStatus(chatID, messageID, userID, status str
 	h.hub.SendToRoom(roomID, data, uuid.Nil)
 }
 
+// --- Resume (replay missed events) ---
+
+type resumePayload struct {
+	LastSeq int64 `json:"lastSeq"`
+}
+
+func (h *WSHandler) handleResume(client *ws.Client, payload json.RawMessage) {
+	var p resumePayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return
+	}
+
+	if err := h.hub.Replay(client, p.LastSeq); err != nil {
+		log.Warn().Err(err).
+			Str("user_id", client.UserID.String()).
+			Int64("last_seq", p.LastSeq).
+			Msg("failed to replay events")
+	}
+}
+
 // --- Document Collaboration ---
 
+// docJoinPayload is sent on doc_join and doc_leave. A client rejoining a
+// document passes the Epoch and StateVector of its last doc_sync.
 type docJoinPayload struct {
-	DocumentID string `json:"documentId"`
+	DocumentID  string              `json:"documentId"`
+	Epoch       string              `json:"epoch,omitempty"`
+	StateVector map[uuid.UUID]int64 `json:"stateVector,omitempty"`
 }
 
 type docPresenceBroadcast struct {
@@ -337,6 +469,14 @@ func (h *WSHandler) handleDocJoin(client *ws.Client, payload json.RawMessage) {
 		return
 	}
 
+	docID, err := uuid.Parse(p.DocumentID)
+	if err != nil {
+		return
+	}
+	if _, ok := h.documentAccess(client, docID); !ok {
+		return
+	}
+
 	roomID := "doc:" + p.DocumentID
 	h.hub.JoinRoom(client, roomID)
 
@@ -355,12 +495,135 @@ func (h *WSHandler) handleDocJoin(client *ws.Client, payload json.RawMessage) {
 	data, _ := json.Marshal(wsMsg)
 	h.hub.SendToRoom(roomID, data, client.UserID)
 
+	h.sendDocSync(ws.DocumentRequest{
+		Type:       ws.WSTypeDocSync,
+		DocumentID: docID,
+		UserID:     client.UserID,
+		DeviceID:   client.DeviceID,
+		Payload:    payload,
+	})
+
 	log.Debug().
 		Str("user_id", client.UserID.String()).
 		Str("document_id", p.DocumentID).
 		Msg("user joined document room")
 }
 
+// documentAccess loads a document through the document service, which only
+// lets its owner and collaborators in.
+func (h *WSHandler) documentAccess(client *ws.Client, docID uuid.UUID) (*service.DocumentAccess, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	access, err := h.documents.GetAccess(ctx, docID, client.UserID)
+	if err != nil {
+		log.Debug().Err(err).
+			Str("user_id", client.UserID.String()).
+			Str("document_id", docID.String()).
+			Msg("document access denied")
+		return nil, false
+	}
+	return access, true
+}
+
+// loadDocument loads the CRDT a client request is about. Only the node
+// hosting a document loads it; if another node does, the request is
+// forwarded there and ok is false.
+func (h *WSHandler) loadDocument(req ws.DocumentRequest) (*ws.DocumentCRDT, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	crdt, err := h.crdtManager.Load(ctx, req.DocumentID)
+	var remote *ws.RemoteDocumentError
+	switch {
+	case err == nil:
+		return crdt, true
+	case errors.As(err, &remote):
+		h.crdtManager.Forward(remote.Node, req)
+	case errors.Is(err, ws.ErrDocumentSuspended):
+		log.Debug().
+			Str("document_id", req.DocumentID.String()).
+			Str("type", req.Type).
+			Msg("document is being written, rejecting request")
+		h.rejectDocRequest(req, docRejectSuspended)
+	default:
+		log.Warn().Err(err).
+			Str("document_id", req.DocumentID.String()).
+			Msg("failed to load document CRDT")
+	}
+	return nil, false
+}
+
+// docRejectSuspended is the reason given while a REST write or version
+// restore holds the document.
+const docRejectSuspended = "suspended"
+
+// docRejectedPayload tells a client a doc_join or doc_update was not
+// applied. The client keeps its pending ops and retries them once it has
+// the doc_sync that follows.
+type docRejectedPayload struct {
+	DocumentID string          `json:"documentId"`
+	Type       string          `json:"type"`
+	Reason     string          `json:"reason"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+}
+
+// rejectDocRequest sends the device behind a request a doc_rejected.
+func (h *WSHandler) rejectDocRequest(req ws.DocumentRequest, reason string) {
+	payload, _ := json.Marshal(docRejectedPayload{
+		DocumentID: req.DocumentID.String(),
+		Type:       req.Type,
+		Reason:     reason,
+		Payload:    req.Payload,
+	})
+	wsMsg := ws.WSMessage{
+		Type:    ws.WSTypeDocRejected,
+		Payload: payload,
+	}
+	data, _ := json.Marshal(wsMsg)
+	h.hub.SendToDevice(req.UserID, req.DeviceID, data)
+}
+
+// handleDocumentRequest handles a client message forwarded by the node the
+// client is connected to, which has already checked its access.
+func (h *WSHandler) handleDocumentRequest(req ws.DocumentRequest) {
+	switch req.Type {
+	case ws.WSTypeDocSync:
+		h.sendDocSync(req)
+	case ws.WSTypeDocUpdate:
+		h.applyDocUpdate(req)
+	}
+}
+
+// sendDocSync sends a device the state of a document it is missing
+// according to the state vector in its doc_join payload, if any. A state
+// vector from another epoch says nothing about this one, so the device gets
+// everything. Callers check the user's access first.
+func (h *WSHandler) sendDocSync(req ws.DocumentRequest) {
+	crdt, ok := h.loadDocument(req)
+	if !ok {
+		return
+	}
+
+	var p docJoinPayload
+	if len(req.Payload) > 0 {
+		if err := json.Unmarshal(req.Payload, &p); err != nil {
+			return
+		}
+	}
+	since := p.StateVector
+	if p.Epoch != crdt.Epoch {
+		since = nil
+	}
+	payload, _ := json.Marshal(crdt.Sync(since))
+	wsMsg := ws.WSMessage{
+		Type:    ws.WSTypeDocSync,
+		Payload: payload,
+	}
+	data, _ := json.Marshal(wsMsg)
+	h.hub.SendToDevice(req.UserID, req.DeviceID, data)
+}
+
 func (h *WSHandler) handleDocLeave(client *ws.Client, payload json.RawMessage) {
 	var p docJoinPayload
 	if err := json.Unmarshal(payload, &p); err != nil {
@@ -385,18 +648,6 @@ func (h *WSHandler) handleDocLeave(client *ws.Client, payload json.RawMessage) {
 	data, _ := json.Marshal(wsMsg)
 	h.hub.SendToRoom(roomID, data, client.UserID)
 
-	// Clean up CRDT state if no more clients in the document room
-	members := h.hub.GetRoomMembers(roomID)
-	if len(members) == 0 {
-		docID, err := uuid.Parse(p.DocumentID)
-		if err == nil {
-			h.crdtManager.Remove(docID)
-			log.Debug().
-				Str("document_id", p.DocumentID).
-				Msg("CRDT state cleaned up for empty document room")
-		}
-	}
-
 	log.Debug().
 		Str("user_id", client.UserID.String()).
 		Str("document_id", p.DocumentID).
@@ -404,14 +655,25 @@ func (h *WSHandler) handleDocLeave(client *ws.Client, payload json.RawMessage) {
 }
 
 // docCRDTUpdatePayload is the CRDT-aware update payload from clients.
+// Inserts, moves and reparents place the block with ParentID (empty for
+// the top level) and Key, a fractional order key; inserts also carry the
+// BlockType and initial Value. Text edits carry character-level Ops,
+// with Base the content the client started from. Epoch is the epoch of
+// the client's last doc_sync; see epochBound.
 type docCRDTUpdatePayload struct {
-	DocumentID string `json:"documentId"`
-	BlockID    string `json:"blockId"`
-	Field      string `json:"field"`
-	Value      string `json:"value"`
-	Timestamp  int64  `json:"timestamp"`
-	NodeID     string `json:"nodeId"`
-	Action     string `json:"action"` // "update" or "delete"
+	DocumentID string      `json:"documentId"`
+	Epoch      string      `json:"epoch,omitempty"`
+	BlockID    string      `json:"blockId"`
+	Field      string      `json:"field,omitempty"`
+	Value      string      `json:"value,omitempty"`
+	ParentID   string      `json:"parentId,omitempty"`
+	Key        string      `json:"key,omitempty"`
+	BlockType  string      `json:"blockType,omitempty"`
+	Base       string      `json:"base,omitempty"`
+	Ops        []ws.TextOp `json:"ops,omitempty"`
+	Timestamp  int64       `json:"timestamp"`
+	NodeID     string      `json:"nodeId"`
+	Action     string      `json:"action"` // "update", "delete", "insert", "move", "reparent" or "text"
 }
 
 func (h *WSHandler) handleDocUpdate(client *ws.Client, payload json.RawMessage) {
@@ -425,6 +687,37 @@ func (h *WSHandler) handleDocUpdate(client *ws.Client, payload json.RawMessage)
 		return
 	}
 
+	access, ok := h.documentAccess(client, docID)
+	if !ok {
+		return
+	}
+	if !access.CanEdit {
+		log.Debug().
+			Str("user_id", client.UserID.String()).
+			Str("document_id", p.DocumentID).
+			Bool("locked", access.Document.Locked).
+			Msg("CRDT update rejected (read-only)")
+		return
+	}
+
+	h.applyDocUpdate(ws.DocumentRequest{
+		Type:       ws.WSTypeDocUpdate,
+		DocumentID: docID,
+		UserID:     client.UserID,
+		DeviceID:   client.DeviceID,
+		Payload:    payload,
+	})
+}
+
+// applyDocUpdate applies a client's update on the node hosting the document
+// and broadcasts it if accepted. Callers check the user may edit first.
+func (h *WSHandler) applyDocUpdate(req ws.DocumentRequest) {
+	var p docCRDTUpdatePayload
+	if err := json.Unmarshal(req.Payload, &p); err != nil {
+		return
+	}
+	docID := req.DocumentID
+
 	blockID, err := uuid.Parse(p.BlockID)
 	if err != nil {
 		return
@@ -433,10 +726,50 @@ func (h *WSHandler) handleDocUpdate(client *ws.Client, payload json.RawMessage)
 	nodeID, err := uuid.Parse(p.NodeID)
 	if err != nil {
 		// Fallback: use client user ID as node ID
-		nodeID = client.UserID
+		nodeID = req.UserID
 	}
 
-	crdt := h.crdtManager.GetOrCreate(docID)
+	var parentID *uuid.UUID
+	if p.ParentID != "" {
+		id, err := uuid.Parse(p.ParentID)
+		if err != nil {
+			return
+		}
+		parentID = &id
+	}
+
+	crdt, ok := h.loadDocument(req)
+	if !ok {
+		return
+	}
+
+	if p.Epoch != crdt.Epoch && (p.Epoch != "" || epochBound(p.Action)) {
+		log.Debug().
+			Str("document_id", p.DocumentID).
+			Str("action", p.Action).
+			Msg("CRDT update from another epoch, resyncing client")
+		h.sendDocSync(ws.DocumentRequest{
+			Type:       ws.WSTypeDocSync,
+			DocumentID: docID,
+			UserID:     req.UserID,
+			DeviceID:   req.DeviceID,
+			Hops:       req.Hops,
+		})
+		return
+	}
+
+	// Clients that leave out the timestamp get one from the document clock;
+	// rewritten marks a payload the broadcast must re-encode. Text ops
+	// carry their own timestamps.
+	rewritten := false
+	if p.Action != "delete" && p.Action != "text" {
+		if p.Timestamp == 0 {
+			p.Timestamp = crdt.Tick()
+			rewritten = true
+		} else {
+			crdt.ReceiveTick(p.Timestamp)
+		}
+	}
 
 	var accepted bool
 	switch p.Action {
@@ -447,20 +780,59 @@ func (h *WSHandler) handleDocUpdate(client *ws.Client, payload json.RawMessage)
 			Timestamp:  p.Timestamp,
 			NodeID:     nodeID,
 		})
-	default: // "update" or empty
-		if p.Timestamp == 0 {
-			p.Timestamp = crdt.Tick()
+	case "insert":
+		accepted = crdt.ApplyInsert(ws.CRDTInsertEvent{
+			DocumentID: docID,
+			BlockID:    blockID,
+			ParentID:   parentID,
+			Key:        p.Key,
+			Type:       p.BlockType,
+			Content:    p.Value,
+			Timestamp:  p.Timestamp,
+			NodeID:     nodeID,
+		})
+	case "move", "reparent":
+		move := ws.CRDTMoveEvent{
+			DocumentID: docID,
+			BlockID:    blockID,
+			ParentID:   parentID,
+			Key:        p.Key,
+			Timestamp:  p.Timestamp,
+			NodeID:     nodeID,
+		}
+		if p.Action == "move" {
+			accepted = crdt.ApplyMove(move)
 		} else {
-			crdt.ReceiveTick(p.Timestamp)
+			accepted = crdt.ApplyReparent(move)
 		}
-		accepted = crdt.ApplyUpdate(ws.CRDTUpdateEvent{
+	case "text":
+		p.Ops = crdt.ApplyText(ws.CRDTTextEvent{
+			DocumentID: docID,
+			BlockID:    blockID,
+			Base:       p.Base,
+			Ops:        p.Ops,
+			NodeID:     nodeID,
+		})
+		// Only pass on the ops that were new to the server
+		accepted = len(p.Ops) > 0
+		rewritten = accepted
+	default: // "update" or empty
+		update := ws.CRDTUpdateEvent{
 			DocumentID: docID,
 			BlockID:    blockID,
 			Field:      p.Field,
 			Value:      p.Value,
 			Timestamp:  p.Timestamp,
 			NodeID:     nodeID,
-		})
+		}
+		if ops, ok := crdt.ReplaceText(update); ok {
+			// Clients editing the block as text need the change as ops
+			p.Action, p.Field, p.Value, p.Ops = "text", "", "", ops
+			accepted = len(ops) > 0
+			rewritten = accepted
+		} else {
+			accepted = crdt.ApplyUpdate(update)
+		}
 	}
 
 	if !accepted {
@@ -472,6 +844,14 @@ func (h *WSHandler) handleDocUpdate(client *ws.Client, payload json.RawMessage)
 		return
 	}
 
+	crdt.RecordEditor(blockID, req.UserID)
+	h.crdtManager.Changed(docID)
+
+	payload := req.Payload
+	if rewritten {
+		payload, _ = json.Marshal(p)
+	}
+
 	// Broadcast accepted update to all other clients in the document room
 	wsMsg := ws.WSMessage{
 		Type:    ws.WSTypeDocUpdate,
@@ -479,8 +859,21 @@ func (h *WSHandler) handleDocUpdate(client *ws.Client, payload json.RawMessage)
 	}
 	data, _ := json.Marshal(wsMsg)
 
-	roomID := "doc:" + p.DocumentID
-	h.hub.SendToRoom(roomID, data, client.UserID)
+	roomID := "doc:" + docID.String()
+	h.hub.SendToRoom(roomID, data, req.UserID)
+}
+
+// epochBound reports whether an action refers to order keys or character
+// IDs, which only mean something within the epoch they were synced from.
+// Whole-value updates and deletes stand on their own, so clients that never
+// sync may send them without an epoch.
+func epochBound(action string) bool {
+	switch action {
+	case "insert", "move", "reparent", "text":
+		return true
+	default:
+		return false
+	}
 }
 
 type docLockPayload struct {
@@ -507,7 +900,9 @@ func (h *WSHandler) handleDocLockEvent(client *ws.Client, payload json.RawMessag
 	h.hub.SendToRoom(roomID, data, client.UserID)
 }
 
-func (h *WSHandler) validateToken(tokenString string) (uuid.UUID, error) {
+// validateToken returns the user a token was issued to and, if it is bound
+// to one, the device.
+func (h *WSHandler) validateToken(tokenString string) (uuid.UUID, string, error) {
 	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
 		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, jwt.ErrSignatureInvalid
@@ -515,18 +910,23 @@ func (h *WSHandler) validateToken(tokenString string) (uuid.UUID, error) {
 		return []byte(h.jwtSecret), nil
 	})
 	if err != nil || !token.Valid {
-		return uuid.Nil, apperror.Unauthorized("invalid token")
+		return uuid.Nil, "", apperror.Unauthorized("invalid token")
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return uuid.Nil, apperror.Unauthorized("invalid claims")
+		return uuid.Nil, "", apperror.Unauthorized("invalid claims")
 	}
 
 	sub, ok := claims["sub"].(string)
 	if !ok {
-		return uuid.Nil, apperror.Unauthorized("missing subject")
+		return uuid.Nil, "", apperror.Unauthorized("missing subject")
 	}
 
-	return uuid.Parse(sub)
+	userID, err := uuid.Parse(sub)
+	if err != nil {
+		return uuid.Nil, "", apperror.Unauthorized("invalid user id")
+	}
+	deviceID, _ := claims["did"].(string)
+	return userID, deviceID, nil
 }
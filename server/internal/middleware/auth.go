@@ -14,10 +14,21 @@ import (
 
 type contextKey string
 
-const userIDKey contextKey = "userID"
+const (
+	userIDKey   contextKey = "userID"
+	deviceIDKey contextKey = "deviceID"
+)
+
+// SessionChecker reports whether the session a device signed in with is
+// still active.
+type SessionChecker interface {
+	Validate(ctx context.Context, userID uuid.UUID, deviceID string) error
+}
 
 // Auth returns middleware that validates JWT tokens from the Authorization header.
-func Auth(jwtSecret string) func(http.Handler) http.Handler {
+// When sessions is set, tokens issued to a device whose session has been
+// revoked are rejected even before they expire.
+func Auth(jwtSecret string, sessions SessionChecker) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -63,6 +74,19 @@ func Auth(jwtSecret string) func(http.Handler) http.Handler {
 			}
 
 			ctx := context.WithValue(r.Context(), userIDKey, userID)
+			if deviceID, ok := claims["did"].(string); ok && deviceID != "" {
+				if sessions != nil {
+					if err := sessions.Validate(r.Context(), userID, deviceID); err != nil {
+						appErr, ok := err.(*apperror.AppError)
+						if !ok {
+							appErr = apperror.Internal(err)
+						}
+						response.Error(w, appErr)
+						return
+					}
+				}
+				ctx = context.WithValue(ctx, deviceIDKey, deviceID)
+			}
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -79,3 +103,16 @@ func GetUserID(ctx context.Context) (uuid.UUID, bool) {
 func WithUserID(ctx context.Context, userID uuid.UUID) context.Context {
 	return context.WithValue(ctx, userIDKey, userID)
 }
+
+// GetDeviceID extracts the device ID of the authenticated session from context.
+// Tokens issued without a device carry none.
+func GetDeviceID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(deviceIDKey).(string)
+	return id, ok
+}
+
+// WithDeviceID returns a new context with the given device ID set.
+// This is primarily used for testing.
+func WithDeviceID(ctx context.Context, deviceID string) context.Context {
+	return context.WithValue(ctx, deviceIDKey, deviceID)
+}
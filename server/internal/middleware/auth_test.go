@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/otoritech/chatat/internal/middleware"
+	"github.com/otoritech/chatat/pkg/apperror"
 )
 
 const testSecret = "test-jwt-secret-key-for-testing"
@@ -34,7 +35,7 @@ func TestAuth_ValidToken(t *testing.T) {
 	userID := uuid.New()
 	token := generateToken(t, userID, testSecret, time.Now().Add(time.Hour))
 
-	handler := middleware.Auth(testSecret)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	handler := middleware.Auth(testSecret, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		gotID, ok := middleware.GetUserID(r.Context())
 		assert.True(t, ok)
 		assert.Equal(t, userID, gotID)
@@ -50,7 +51,7 @@ func TestAuth_ValidToken(t *testing.T) {
 }
 
 func TestAuth_MissingHeader(t *testing.T) {
-	handler := middleware.Auth(testSecret)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	handler := middleware.Auth(testSecret, nil)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
 
@@ -62,7 +63,7 @@ func TestAuth_MissingHeader(t *testing.T) {
 }
 
 func TestAuth_InvalidFormat(t *testing.T) {
-	handler := middleware.Auth(testSecret)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	handler := middleware.Auth(testSecret, nil)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
 
@@ -80,7 +81,7 @@ func TestAuth_ExpiredToken(t *testing.T) {
 	userID := uuid.New()
 	token := generateToken(t, userID, testSecret, time.Now().Add(-time.Hour))
 
-	handler := middleware.Auth(testSecret)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	handler := middleware.Auth(testSecret, nil)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
 
@@ -96,7 +97,7 @@ func TestAuth_WrongSecret(t *testing.T) {
 	userID := uuid.New()
 	token := generateToken(t, userID, "wrong-secret", time.Now().Add(time.Hour))
 
-	handler := middleware.Auth(testSecret)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	handler := middleware.Auth(testSecret, nil)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
 
@@ -133,7 +134,7 @@ func TestAuth_InvalidSubject(t *testing.T) {
 	tokenString, err := token.SignedString([]byte(testSecret))
 	require.NoError(t, err)
 
-	handler := middleware.Auth(testSecret)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	handler := middleware.Auth(testSecret, nil)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
 
@@ -155,7 +156,7 @@ func TestAuth_MissingSubject(t *testing.T) {
 	tokenString, err := token.SignedString([]byte(testSecret))
 	require.NoError(t, err)
 
-	handler := middleware.Auth(testSecret)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	handler := middleware.Auth(testSecret, nil)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
 
@@ -168,7 +169,7 @@ func TestAuth_MissingSubject(t *testing.T) {
 }
 
 func TestAuth_NoSpaceInHeader(t *testing.T) {
-	handler := middleware.Auth(testSecret)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	handler := middleware.Auth(testSecret, nil)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
 
@@ -179,3 +180,64 @@ func TestAuth_NoSpaceInHeader(t *testing.T) {
 
 	assert.Equal(t, http.StatusUnauthorized, rec.Code)
 }
+
+func TestAuth_DeviceClaim(t *testing.T) {
+	userID := uuid.New()
+	claims := jwt.MapClaims{
+		"sub": userID.String(),
+		"did": "phone-1",
+		"exp": jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	require.NoError(t, err)
+
+	handler := middleware.Auth(testSecret, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deviceID, ok := middleware.GetDeviceID(r.Context())
+		assert.True(t, ok)
+		assert.Equal(t, "phone-1", deviceID)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+type stubSessions struct {
+	revoked map[string]bool
+}
+
+func (s stubSessions) Validate(_ context.Context, _ uuid.UUID, deviceID string) error {
+	if s.revoked[deviceID] {
+		return apperror.Unauthorized("session revoked or expired")
+	}
+	return nil
+}
+
+func TestAuth_RevokedSession(t *testing.T) {
+	userID := uuid.New()
+	sessions := stubSessions{revoked: map[string]bool{"phone-1": true}}
+	handler := middleware.Auth(testSecret, sessions)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for deviceID, want := range map[string]int{"phone-1": http.StatusUnauthorized, "tablet-1": http.StatusOK} {
+		claims := jwt.MapClaims{
+			"sub": userID.String(),
+			"did": deviceID,
+			"exp": jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		}
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, want, rec.Code, deviceID)
+	}
+}
@@ -31,13 +31,13 @@ const (
 
 // BackupRecord represents a single backup operation record.
 type BackupRecord struct {
-	ID        uuid.UUID      `json:"id"`
-	UserID    uuid.UUID      `json:"userId"`
-	SizeBytes int64          `json:"sizeBytes"`
-	Platform  BackupPlatform `json:"platform"`
-	Status    BackupStatus   `json:"status"`
+	ID        uuid.UUID       `json:"id"`
+	UserID    uuid.UUID       `json:"userId"`
+	SizeBytes int64           `json:"sizeBytes"`
+	Platform  BackupPlatform  `json:"platform"`
+	Status    BackupStatus    `json:"status"`
 	Metadata  json.RawMessage `json:"metadata,omitempty"`
-	CreatedAt time.Time      `json:"createdAt"`
+	CreatedAt time.Time       `json:"createdAt"`
 }
 
 // LogBackupInput holds data for logging a backup record.
@@ -83,21 +83,22 @@ type ChatExport struct {
 
 // MessageExport represents a message in a backup bundle.
 type MessageExport struct {
-	ServerID  string `json:"serverId"`
-	ChatID    string `json:"chatId"`
-	SenderID  string `json:"senderId"`
-	Content   string `json:"content"`
-	Type      string `json:"type"`
-	CreatedAt string `json:"createdAt"`
+	ServerID  string          `json:"serverId"`
+	ChatID    string          `json:"chatId"`
+	SenderID  string          `json:"senderId"`
+	Content   string          `json:"content"`
+	Type      string          `json:"type"`
+	Metadata  json.RawMessage `json:"metadata,omitempty"`
+	CreatedAt string          `json:"createdAt"`
 }
 
 // ContactExport represents a contact in a backup bundle.
 type ContactExport struct {
-	UserID    string `json:"userId"`
-	Name      string `json:"name"`
-	Phone     string `json:"phone"`
-	Nickname  string `json:"nickname,omitempty"`
-	AddedAt   string `json:"addedAt"`
+	UserID   string `json:"userId"`
+	Name     string `json:"name"`
+	Phone    string `json:"phone"`
+	Nickname string `json:"nickname,omitempty"`
+	AddedAt  string `json:"addedAt"`
 }
 
 // DocumentExport represents a document in a backup bundle.
@@ -40,6 +40,7 @@ type Block struct {
 	Color         string          `json:"color,omitempty"`
 	SortOrder     int             `json:"sortOrder"`
 	ParentBlockID *uuid.UUID      `json:"parentBlockId,omitempty"`
+	Mentions      []uuid.UUID     `json:"mentions,omitempty"`
 	CreatedAt     time.Time       `json:"createdAt"`
 	UpdatedAt     time.Time       `json:"updatedAt"`
 }
@@ -70,3 +71,17 @@ type UpdateBlockInput struct {
 	Color     *string         `json:"color"`
 	SortOrder *int            `json:"sortOrder"`
 }
+
+// BlockState is a block as edited in realtime, written back in batches.
+// Deleted blocks are removed. A non-nil SortOrder also sets ParentBlockID,
+// and a Type lets the block be created when it does not exist yet. Nil
+// fields are left unchanged.
+type BlockState struct {
+	ID            uuid.UUID
+	Type          BlockType
+	Content       *string
+	Checked       *bool
+	SortOrder     *int
+	ParentBlockID *uuid.UUID
+	Deleted       bool
+}
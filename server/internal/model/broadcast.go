@@ -0,0 +1,57 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BroadcastList is a named set of users that its owner sends the same
+// message to, one personal chat at a time.
+type BroadcastList struct {
+	ID           uuid.UUID   `json:"id"`
+	OwnerID      uuid.UUID   `json:"ownerId"`
+	Name         string      `json:"name"`
+	RecipientIDs []uuid.UUID `json:"recipientIds"`
+	CreatedAt    time.Time   `json:"createdAt"`
+	UpdatedAt    time.Time   `json:"updatedAt"`
+}
+
+// Broadcast is one message sent to a broadcast list.
+type Broadcast struct {
+	ID           uuid.UUID       `json:"id"`
+	ListID       uuid.UUID       `json:"listId"`
+	SenderID     uuid.UUID       `json:"senderId"`
+	Content      string          `json:"content"`
+	Type         MessageType     `json:"type"`
+	Metadata     json.RawMessage `json:"metadata,omitempty"`
+	SkippedCount int             `json:"skippedCount"` // recipients the message was not sent to
+	Stats        BroadcastStats  `json:"stats"`
+	CreatedAt    time.Time       `json:"createdAt"`
+}
+
+// BroadcastStats counts how many copies of a broadcast were sent, delivered
+// and read.
+type BroadcastStats struct {
+	Sent      int `json:"sent"`
+	Delivered int `json:"delivered"`
+	Read      int `json:"read"`
+}
+
+// BroadcastDelivery links a broadcast to the message sent to one recipient.
+type BroadcastDelivery struct {
+	MessageID   uuid.UUID
+	RecipientID uuid.UUID
+}
+
+// CreateBroadcastInput holds data needed to record a sent broadcast.
+type CreateBroadcastInput struct {
+	ListID       uuid.UUID
+	SenderID     uuid.UUID
+	Content      string
+	Type         MessageType
+	Metadata     json.RawMessage
+	SkippedCount int
+	Deliveries   []BroadcastDelivery
+}
@@ -20,31 +20,79 @@ const (
 type MemberRole string
 
 const (
+	// MemberRoleOwner is a group admin that other admins cannot remove or demote.
+	MemberRoleOwner MemberRole = "owner"
 	// MemberRoleAdmin has administrative privileges.
 	MemberRoleAdmin MemberRole = "admin"
 	// MemberRoleMember is a regular member.
 	MemberRoleMember MemberRole = "member"
 )
 
+// IsAdmin reports whether the role has administrative privileges.
+func (r MemberRole) IsAdmin() bool {
+	return r == MemberRoleOwner || r == MemberRoleAdmin
+}
+
 // Chat represents a chat room (personal or group).
 type Chat struct {
-	ID          uuid.UUID  `json:"id"`
-	Type        ChatType   `json:"type"`
-	Name        string     `json:"name,omitempty"`
-	Icon        string     `json:"icon,omitempty"`
-	Description string     `json:"description,omitempty"`
-	CreatedBy   uuid.UUID  `json:"createdBy"`
-	PinnedAt    *time.Time `json:"pinnedAt,omitempty"`
-	CreatedAt   time.Time  `json:"createdAt"`
-	UpdatedAt   time.Time  `json:"updatedAt"`
+	ID                uuid.UUID        `json:"id"`
+	Type              ChatType         `json:"type"`
+	Name              string           `json:"name,omitempty"`
+	Icon              string           `json:"icon,omitempty"`
+	Description       string           `json:"description,omitempty"`
+	CreatedBy         uuid.UUID        `json:"createdBy"`
+	DisappearingTimer int              `json:"disappearingTimer"` // seconds new messages live; 0 = off
+	Permissions       GroupPermissions `json:"permissions"`
+	CreatedAt         time.Time        `json:"createdAt"`
+	UpdatedAt         time.Time        `json:"updatedAt"`
+}
+
+// GroupPermissions restricts group actions to admins. They have no effect
+// on personal chats.
+type GroupPermissions struct {
+	OnlyAdminsSend         bool `json:"onlyAdminsSend"`
+	OnlyAdminsEditInfo     bool `json:"onlyAdminsEditInfo"`
+	OnlyAdminsCreateTopics bool `json:"onlyAdminsCreateTopics"`
+	OnlyAdminsAddMembers   bool `json:"onlyAdminsAddMembers"`
+}
+
+// UpdateGroupPermissionsInput holds optional changes to a group's permissions.
+type UpdateGroupPermissionsInput struct {
+	OnlyAdminsSend         *bool `json:"onlyAdminsSend"`
+	OnlyAdminsEditInfo     *bool `json:"onlyAdminsEditInfo"`
+	OnlyAdminsCreateTopics *bool `json:"onlyAdminsCreateTopics"`
+	OnlyAdminsAddMembers   *bool `json:"onlyAdminsAddMembers"`
 }
 
 // ChatMember represents a user's membership in a chat.
 type ChatMember struct {
-	ChatID   uuid.UUID  `json:"chatId"`
-	UserID   uuid.UUID  `json:"userId"`
-	Role     MemberRole `json:"role"`
-	JoinedAt time.Time  `json:"joinedAt"`
+	ChatID   uuid.UUID    `json:"chatId"`
+	UserID   uuid.UUID    `json:"userId"`
+	Role     MemberRole   `json:"role"`
+	JoinedAt time.Time    `json:"joinedAt"`
+	Settings ChatSettings `json:"-"` // private to the member
+}
+
+// ChatSettings holds one member's personal preferences for a chat.
+type ChatSettings struct {
+	PinnedAt          *time.Time `json:"pinnedAt"`
+	ArchivedAt        *time.Time `json:"archivedAt"`
+	MutedUntil        *time.Time `json:"mutedUntil"`
+	NotificationSound string     `json:"notificationSound,omitempty"` // empty uses the default sound
+}
+
+// IsMuted reports whether the member has muted the chat at t.
+func (s ChatSettings) IsMuted(t time.Time) bool {
+	return s.MutedUntil != nil && s.MutedUntil.After(t)
+}
+
+// UpdateChatSettingsInput holds optional changes to a member's chat settings.
+// A MutedUntil that is not in the future unmutes the chat.
+type UpdateChatSettingsInput struct {
+	Pinned            *bool      `json:"pinned"`
+	Archived          *bool      `json:"archived"`
+	MutedUntil        *time.Time `json:"mutedUntil"`
+	NotificationSound *string    `json:"notificationSound"`
 }
 
 // CreateChatInput holds data needed to create a new chat.
@@ -65,7 +113,8 @@ type UpdateChatInput struct {
 
 // ChatWithLastMessage combines a chat with its most recent message preview.
 type ChatWithLastMessage struct {
-	Chat        Chat    `json:"chat"`
-	LastMessage *string `json:"lastMessage,omitempty"`
-	UnreadCount int     `json:"unreadCount"`
+	Chat        Chat         `json:"chat"`
+	Settings    ChatSettings `json:"settings"`
+	LastMessage *string      `json:"lastMessage,omitempty"`
+	UnreadCount int          `json:"unreadCount"`
 }
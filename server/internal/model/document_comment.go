@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CommentRange anchors a comment to part of its block's content. Start and
+// End are character offsets; Quote is the text they covered when the
+// comment was made.
+type CommentRange struct {
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Quote string `json:"quote"`
+}
+
+// DocumentComment is a comment on a document block. A thread is a comment
+// without a ParentID together with its replies; only threads are resolved.
+type DocumentComment struct {
+	ID         uuid.UUID          `json:"id"`
+	DocumentID uuid.UUID          `json:"documentId"`
+	BlockID    uuid.UUID          `json:"blockId"`
+	ParentID   *uuid.UUID         `json:"parentId,omitempty"`
+	AuthorID   uuid.UUID          `json:"authorId"`
+	Content    string             `json:"content"`
+	Range      *CommentRange      `json:"range,omitempty"`
+	ResolvedAt *time.Time         `json:"resolvedAt,omitempty"`
+	ResolvedBy *uuid.UUID         `json:"resolvedBy,omitempty"`
+	Mentions   []uuid.UUID        `json:"mentions,omitempty"`
+	Replies    []*DocumentComment `json:"replies,omitempty"`
+	CreatedAt  time.Time          `json:"createdAt"`
+	UpdatedAt  time.Time          `json:"updatedAt"`
+}
+
+// CreateDocumentCommentInput holds data needed to create a comment.
+type CreateDocumentCommentInput struct {
+	DocumentID uuid.UUID
+	BlockID    uuid.UUID
+	ParentID   *uuid.UUID
+	AuthorID   uuid.UUID
+	Content    string
+	Range      *CommentRange
+}
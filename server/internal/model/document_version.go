@@ -0,0 +1,86 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// VersionReason records why a document version was captured.
+type VersionReason string
+
+const (
+	VersionReasonManual   VersionReason = "manual"
+	VersionReasonEdited   VersionReason = "edited"
+	VersionReasonLocked   VersionReason = "locked"
+	VersionReasonSigned   VersionReason = "signed"
+	VersionReasonRestored VersionReason = "restored"
+)
+
+// DocumentVersion is a snapshot of a document's title, icon, cover and
+// blocks. Versions are numbered from 1 per document. Blocks is only filled
+// when a single version is fetched.
+type DocumentVersion struct {
+	ID           uuid.UUID     `json:"id"`
+	DocumentID   uuid.UUID     `json:"documentId"`
+	Version      int           `json:"version"`
+	Title        string        `json:"title"`
+	Icon         string        `json:"icon"`
+	Cover        *string       `json:"cover,omitempty"`
+	Blocks       []*Block      `json:"blocks,omitempty"`
+	BlockCount   int           `json:"blockCount"`
+	Reason       VersionReason `json:"reason"`
+	Label        string        `json:"label,omitempty"`
+	RestoredFrom *int          `json:"restoredFrom,omitempty"`
+	CreatedBy    *uuid.UUID    `json:"createdBy,omitempty"`
+	ContentHash  string        `json:"-"`
+	CreatedAt    time.Time     `json:"createdAt"`
+}
+
+// CreateDocumentVersionInput holds data needed to capture a document version.
+type CreateDocumentVersionInput struct {
+	DocumentID   uuid.UUID
+	Title        string
+	Icon         string
+	Cover        *string
+	Blocks       []*Block
+	ContentHash  string
+	Reason       VersionReason
+	Label        string
+	RestoredFrom *int
+	CreatedBy    uuid.UUID
+}
+
+// BlockChangeType describes how a block differs between two versions.
+type BlockChangeType string
+
+const (
+	BlockChangeAdded    BlockChangeType = "added"
+	BlockChangeRemoved  BlockChangeType = "removed"
+	BlockChangeModified BlockChangeType = "modified"
+	BlockChangeMoved    BlockChangeType = "moved"
+)
+
+// BlockChange is one block that differs between two versions. Before is
+// unset for added blocks and After for removed ones.
+type BlockChange struct {
+	BlockID uuid.UUID       `json:"blockId"`
+	Change  BlockChangeType `json:"change"`
+	Before  *Block          `json:"before,omitempty"`
+	After   *Block          `json:"after,omitempty"`
+}
+
+// DocumentFieldChange is a document property that differs between two versions.
+type DocumentFieldChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// DocumentVersionDiff compares two versions of a document block by block.
+type DocumentVersionDiff struct {
+	From   int                   `json:"from"`
+	To     int                   `json:"to"`
+	Fields []DocumentFieldChange `json:"fields"`
+	Blocks []BlockChange         `json:"blocks"`
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GroupInvite is a shareable code that lets users join a group.
+type GroupInvite struct {
+	ID               uuid.UUID  `json:"id"`
+	ChatID           uuid.UUID  `json:"chatId"`
+	Code             string     `json:"code"`
+	CreatedBy        uuid.UUID  `json:"createdBy"`
+	RequiresApproval bool       `json:"requiresApproval"`
+	MaxUses          *int       `json:"maxUses,omitempty"`
+	UseCount         int        `json:"useCount"`
+	ExpiresAt        *time.Time `json:"expiresAt,omitempty"`
+	RevokedAt        *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt        time.Time  `json:"createdAt"`
+}
+
+// Usable reports whether the invite can still be used to join at t.
+func (i *GroupInvite) Usable(t time.Time) bool {
+	if i.RevokedAt != nil {
+		return false
+	}
+	if i.ExpiresAt != nil && !i.ExpiresAt.After(t) {
+		return false
+	}
+	return i.MaxUses == nil || i.UseCount < *i.MaxUses
+}
+
+// CreateGroupInviteInput holds data needed to create a group invite.
+type CreateGroupInviteInput struct {
+	ChatID           uuid.UUID
+	Code             string
+	CreatedBy        uuid.UUID
+	RequiresApproval bool
+	MaxUses          *int
+	ExpiresAt        *time.Time
+}
+
+// JoinRequestStatus is the state of a request to join a group.
+type JoinRequestStatus string
+
+const (
+	JoinRequestPending  JoinRequestStatus = "pending"
+	JoinRequestApproved JoinRequestStatus = "approved"
+	JoinRequestRejected JoinRequestStatus = "rejected"
+)
+
+// JoinRequest is a request to join a group through an invite that requires
+// admin approval.
+type JoinRequest struct {
+	ID        uuid.UUID         `json:"id"`
+	ChatID    uuid.UUID         `json:"chatId"`
+	UserID    uuid.UUID         `json:"userId"`
+	InviteID  uuid.UUID         `json:"inviteId"`
+	Status    JoinRequestStatus `json:"status"`
+	DecidedBy *uuid.UUID        `json:"decidedBy,omitempty"`
+	DecidedAt *time.Time        `json:"decidedAt,omitempty"`
+	CreatedAt time.Time         `json:"createdAt"`
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MentionSource identifies where a mention was made.
+type MentionSource string
+
+const (
+	MentionSourceChat     MentionSource = "chat"
+	MentionSourceTopic    MentionSource = "topic"
+	MentionSourceDocument MentionSource = "document"
+	MentionSourceComment  MentionSource = "comment"
+)
+
+// Mention records that a user was mentioned in a message, document block or
+// block comment. SourceID is the chat, topic or document; ItemID is the
+// message, block or comment.
+type Mention struct {
+	ID          uuid.UUID     `json:"id"`
+	UserID      uuid.UUID     `json:"userId"`
+	MentionedBy uuid.UUID     `json:"mentionedBy"`
+	SourceType  MentionSource `json:"sourceType"`
+	SourceID    uuid.UUID     `json:"sourceId"`
+	ItemID      uuid.UUID     `json:"itemId"`
+	Preview     string        `json:"preview"`
+	CreatedAt   time.Time     `json:"createdAt"`
+}
+
+// MentionTarget describes the message or block whose mentions are being recorded.
+type MentionTarget struct {
+	SourceType  MentionSource `json:"sourceType"`
+	SourceID    uuid.UUID     `json:"sourceId"`
+	ItemID      uuid.UUID     `json:"itemId"`
+	MentionedBy uuid.UUID     `json:"mentionedBy"`
+	Preview     string        `json:"preview"`
+}
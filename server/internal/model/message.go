@@ -16,20 +16,54 @@ const (
 	MessageTypeFile         MessageType = "file"
 	MessageTypeDocumentCard MessageType = "document_card"
 	MessageTypeSystem       MessageType = "system"
+	MessageTypePoll         MessageType = "poll"
+	MessageTypeLocation     MessageType = "location"
+	MessageTypeContact      MessageType = "contact"
 )
 
+// LocationMetadata is the metadata of a location message. A live location
+// keeps receiving position updates from its sender until LiveUntil.
+type LocationMetadata struct {
+	Latitude  float64    `json:"latitude"`
+	Longitude float64    `json:"longitude"`
+	Label     string     `json:"label,omitempty"`
+	LiveUntil *time.Time `json:"liveUntil,omitempty"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+// IsLive reports whether the location is still being shared live at t.
+func (l *LocationMetadata) IsLive(t time.Time) bool {
+	return l.LiveUntil != nil && l.LiveUntil.After(t)
+}
+
+// ContactMetadata is the metadata of a contact card message. UserID is set
+// when one of the card's phone numbers belongs to a Chatat user.
+type ContactMetadata struct {
+	VCard  string     `json:"vcard"`
+	Name   string     `json:"name"`
+	Phones []string   `json:"phones"`
+	UserID *uuid.UUID `json:"userId,omitempty"`
+}
+
 // Message represents a chat message.
 type Message struct {
-	ID            uuid.UUID       `json:"id"`
-	ChatID        uuid.UUID       `json:"chatId"`
-	SenderID      uuid.UUID       `json:"senderId"`
-	Content       string          `json:"content"`
-	ReplyToID     *uuid.UUID      `json:"replyToId,omitempty"`
-	Type          MessageType     `json:"type"`
-	Metadata      json.RawMessage `json:"metadata,omitempty"`
-	IsDeleted     bool            `json:"isDeleted"`
-	DeletedForAll bool            `json:"deletedForAll"`
-	CreatedAt     time.Time       `json:"createdAt"`
+	ID            uuid.UUID         `json:"id"`
+	ChatID        uuid.UUID         `json:"chatId"`
+	SenderID      uuid.UUID         `json:"senderId"`
+	Content       string            `json:"content"`
+	ReplyToID     *uuid.UUID        `json:"replyToId,omitempty"`
+	ThreadID      *uuid.UUID        `json:"threadId,omitempty"`
+	Type          MessageType       `json:"type"`
+	Metadata      json.RawMessage   `json:"metadata,omitempty"`
+	IsDeleted     bool              `json:"isDeleted"`
+	DeletedForAll bool              `json:"deletedForAll"`
+	CreatedAt     time.Time         `json:"createdAt"`
+	EditedAt      *time.Time        `json:"editedAt,omitempty"`
+	ExpiresAt     *time.Time        `json:"expiresAt,omitempty"`
+	ReplyCount    int               `json:"replyCount,omitempty"`
+	LastReplyAt   *time.Time        `json:"lastReplyAt,omitempty"`
+	Mentions      []uuid.UUID       `json:"mentions,omitempty"`
+	Reactions     []ReactionSummary `json:"reactions,omitempty"`
 }
 
 // CreateMessageInput holds data needed to create a new message.
@@ -38,6 +72,41 @@ type CreateMessageInput struct {
 	SenderID  uuid.UUID       `json:"senderId"`
 	Content   string          `json:"content"`
 	ReplyToID *uuid.UUID      `json:"replyToId"`
+	ThreadID  *uuid.UUID      `json:"threadId"`
 	Type      MessageType     `json:"type"`
 	Metadata  json.RawMessage `json:"metadata"`
 }
+
+// MessageEdit is a previous version of an edited message. EditedAt is when
+// this version was replaced.
+type MessageEdit struct {
+	ID        uuid.UUID `json:"id"`
+	MessageID uuid.UUID `json:"messageId"`
+	Content   string    `json:"content"`
+	EditedAt  time.Time `json:"editedAt"`
+}
+
+// ThreadSummary aggregates the replies in a message thread.
+type ThreadSummary struct {
+	RootID      uuid.UUID `json:"rootId"`
+	ReplyCount  int       `json:"replyCount"`
+	LastReplyAt time.Time `json:"lastReplyAt"`
+}
+
+// ExpiredMessages is a batch of messages removed by their chat's disappearing timer.
+type ExpiredMessages struct {
+	// ByChat maps each chat to the IDs of its removed messages.
+	ByChat map[uuid.UUID][]uuid.UUID
+	// Media is the uploads no remaining message refers to; their rows are
+	// already gone but the stored objects still need deleting.
+	Media []*Media
+}
+
+// Count returns how many messages were removed.
+func (e *ExpiredMessages) Count() int {
+	n := 0
+	for _, ids := range e.ByChat {
+		n += len(ids)
+	}
+	return n
+}
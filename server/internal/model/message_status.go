@@ -17,8 +17,19 @@ const (
 
 // MessageStatus tracks delivery/read status of a message for a user.
 type MessageStatus struct {
-	MessageID uuid.UUID      `json:"messageId"`
-	UserID    uuid.UUID      `json:"userId"`
-	Status    DeliveryStatus `json:"status"`
-	UpdatedAt time.Time      `json:"updatedAt"`
+	MessageID   uuid.UUID      `json:"messageId"`
+	UserID      uuid.UUID      `json:"userId"`
+	Status      DeliveryStatus `json:"status"`
+	DeliveredAt *time.Time     `json:"deliveredAt"`
+	ReadAt      *time.Time     `json:"readAt"`
+	UpdatedAt   time.Time      `json:"updatedAt"`
+}
+
+// MessageReceipt is one recipient's delivery and read state for a message,
+// as shown on a "message info" screen.
+type MessageReceipt struct {
+	User        User           `json:"user"`
+	Status      DeliveryStatus `json:"status"`
+	DeliveredAt *time.Time     `json:"deliveredAt"`
+	ReadAt      *time.Time     `json:"readAt"`
 }
@@ -26,6 +26,10 @@ const (
 	NotifTypeSignatureRequest NotificationType = "signature_request"
 	NotifTypeDocumentLocked   NotificationType = "document_locked"
 	NotifTypeGroupInvite      NotificationType = "group_invite"
+	NotifTypeMention          NotificationType = "mention"
+	NotifTypeThreadReply      NotificationType = "thread_reply"
+	NotifTypeJoinRequest      NotificationType = "join_request"
+	NotifTypeJoinApproved     NotificationType = "join_approved"
 )
 
 // Notification represents a push notification payload.
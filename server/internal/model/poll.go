@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Poll is a question with fixed options, posted as a poll message in a chat
+// or a topic. Exactly one of ChatID and TopicID is set.
+type Poll struct {
+	ID             uuid.UUID    `json:"id"`
+	ChatID         *uuid.UUID   `json:"chatId,omitempty"`
+	TopicID        *uuid.UUID   `json:"topicId,omitempty"`
+	MessageID      *uuid.UUID   `json:"messageId,omitempty"`
+	CreatorID      uuid.UUID    `json:"creatorId"`
+	Question       string       `json:"question"`
+	Options        []PollOption `json:"options"`
+	MultipleChoice bool         `json:"multipleChoice"`
+	Anonymous      bool         `json:"anonymous"`
+	ClosesAt       *time.Time   `json:"closesAt,omitempty"`
+	ClosedAt       *time.Time   `json:"closedAt,omitempty"`
+	TotalVoters    int          `json:"totalVoters"`
+	// MyVotes holds the positions the requesting user voted for.
+	MyVotes   []int     `json:"myVotes,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// PollOption is one answer of a poll with its tally. Voters is only filled
+// for polls that are not anonymous.
+type PollOption struct {
+	Position int         `json:"position"`
+	Text     string      `json:"text"`
+	Votes    int         `json:"votes"`
+	Voters   []uuid.UUID `json:"voters,omitempty"`
+}
+
+// IsClosed reports whether the poll stopped accepting votes at t.
+func (p *Poll) IsClosed(t time.Time) bool {
+	if p.ClosedAt != nil {
+		return true
+	}
+	return p.ClosesAt != nil && !p.ClosesAt.After(t)
+}
+
+// PollVote is a user's vote for one option of a poll.
+type PollVote struct {
+	Position int       `json:"position"`
+	UserID   uuid.UUID `json:"userId"`
+}
+
+// CreatePollInput holds data needed to create a poll.
+type CreatePollInput struct {
+	ChatID         *uuid.UUID
+	TopicID        *uuid.UUID
+	CreatorID      uuid.UUID
+	Question       string
+	Options        []string
+	MultipleChoice bool
+	Anonymous      bool
+	ClosesAt       *time.Time
+}
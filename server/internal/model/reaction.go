@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reaction is one user's emoji reaction to a message.
+type Reaction struct {
+	MessageID uuid.UUID `json:"messageId"`
+	UserID    uuid.UUID `json:"userId"`
+	Emoji     string    `json:"emoji"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ReactionSummary aggregates the reactions with the same emoji on a message.
+type ReactionSummary struct {
+	Emoji   string      `json:"emoji"`
+	Count   int         `json:"count"`
+	UserIDs []uuid.UUID `json:"userIds"`
+}
+
+// SummarizeReactions groups reactions by emoji, ordered by first use.
+func SummarizeReactions(reactions []*Reaction) []ReactionSummary {
+	summaries := make([]ReactionSummary, 0)
+	index := make(map[string]int)
+	for _, r := range reactions {
+		i, ok := index[r.Emoji]
+		if !ok {
+			i = len(summaries)
+			index[r.Emoji] = i
+			summaries = append(summaries, ReactionSummary{Emoji: r.Emoji, UserIDs: []uuid.UUID{}})
+		}
+		summaries[i].Count++
+		summaries[i].UserIDs = append(summaries[i].UserIDs, r.UserID)
+	}
+	return summaries
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScheduledMessageStatus represents the delivery state of a scheduled message.
+type ScheduledMessageStatus string
+
+const (
+	ScheduledStatusPending   ScheduledMessageStatus = "pending"
+	ScheduledStatusSending   ScheduledMessageStatus = "sending"
+	ScheduledStatusSent      ScheduledMessageStatus = "sent"
+	ScheduledStatusFailed    ScheduledMessageStatus = "failed"
+	ScheduledStatusCancelled ScheduledMessageStatus = "cancelled"
+)
+
+// ScheduledMessage is a chat message queued to be sent at SendAt.
+type ScheduledMessage struct {
+	ID        uuid.UUID              `json:"id"`
+	ChatID    uuid.UUID              `json:"chatId"`
+	SenderID  uuid.UUID              `json:"senderId"`
+	Content   string                 `json:"content"`
+	ReplyToID *uuid.UUID             `json:"replyToId,omitempty"`
+	Type      MessageType            `json:"type"`
+	Metadata  json.RawMessage        `json:"metadata,omitempty"`
+	SendAt    time.Time              `json:"sendAt"`
+	Status    ScheduledMessageStatus `json:"status"`
+	Attempts  int                    `json:"-"`
+	MessageID *uuid.UUID             `json:"messageId,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+	CreatedAt time.Time              `json:"createdAt"`
+	UpdatedAt time.Time              `json:"updatedAt"`
+}
+
+// CreateScheduledMessageInput holds data needed to schedule a message.
+type CreateScheduledMessageInput struct {
+	ChatID    uuid.UUID       `json:"chatId"`
+	SenderID  uuid.UUID       `json:"senderId"`
+	Content   string          `json:"content"`
+	ReplyToID *uuid.UUID      `json:"replyToId"`
+	Type      MessageType     `json:"type"`
+	Metadata  json.RawMessage `json:"metadata"`
+	SendAt    time.Time       `json:"sendAt"`
+}
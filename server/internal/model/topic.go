@@ -1,6 +1,7 @@
 package model
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -46,22 +47,27 @@ type UpdateTopicInput struct {
 
 // TopicMessage represents a message within a topic.
 type TopicMessage struct {
-	ID            uuid.UUID   `json:"id"`
-	TopicID       uuid.UUID   `json:"topicId"`
-	SenderID      uuid.UUID   `json:"senderId"`
-	Content       string      `json:"content"`
-	ReplyToID     *uuid.UUID  `json:"replyToId,omitempty"`
-	Type          MessageType `json:"type"`
-	IsDeleted     bool        `json:"isDeleted"`
-	DeletedForAll bool        `json:"deletedForAll"`
-	CreatedAt     time.Time   `json:"createdAt"`
+	ID            uuid.UUID         `json:"id"`
+	TopicID       uuid.UUID         `json:"topicId"`
+	SenderID      uuid.UUID         `json:"senderId"`
+	Content       string            `json:"content"`
+	ReplyToID     *uuid.UUID        `json:"replyToId,omitempty"`
+	Type          MessageType       `json:"type"`
+	Metadata      json.RawMessage   `json:"metadata,omitempty"`
+	IsDeleted     bool              `json:"isDeleted"`
+	DeletedForAll bool              `json:"deletedForAll"`
+	CreatedAt     time.Time         `json:"createdAt"`
+	EditedAt      *time.Time        `json:"editedAt,omitempty"`
+	Mentions      []uuid.UUID       `json:"mentions,omitempty"`
+	Reactions     []ReactionSummary `json:"reactions,omitempty"`
 }
 
 // CreateTopicMessageInput holds data needed to create a topic message.
 type CreateTopicMessageInput struct {
-	TopicID   uuid.UUID   `json:"topicId"`
-	SenderID  uuid.UUID   `json:"senderId"`
-	Content   string      `json:"content"`
-	ReplyToID *uuid.UUID  `json:"replyToId"`
-	Type      MessageType `json:"type"`
+	TopicID   uuid.UUID       `json:"topicId"`
+	SenderID  uuid.UUID       `json:"senderId"`
+	Content   string          `json:"content"`
+	ReplyToID *uuid.UUID      `json:"replyToId"`
+	Type      MessageType     `json:"type"`
+	Metadata  json.RawMessage `json:"metadata"`
 }
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BlockedUser is an entry in a user's block list.
+type BlockedUser struct {
+	UserID    uuid.UUID `json:"userId"`
+	Name      string    `json:"name"`
+	Avatar    string    `json:"avatar"`
+	BlockedAt time.Time `json:"blockedAt"`
+}
+
+// ReportReason is why a user was reported.
+type ReportReason string
+
+const (
+	ReportReasonSpam          ReportReason = "spam"
+	ReportReasonHarassment    ReportReason = "harassment"
+	ReportReasonInappropriate ReportReason = "inappropriate"
+	ReportReasonOther         ReportReason = "other"
+)
+
+// ReportStatus is the moderation state of an abuse report.
+type ReportStatus string
+
+const (
+	ReportStatusOpen     ReportStatus = "open"
+	ReportStatusReviewed ReportStatus = "reviewed"
+)
+
+// AbuseReport is an entry in the moderation queue.
+type AbuseReport struct {
+	ID             uuid.UUID         `json:"id"`
+	ReporterID     uuid.UUID         `json:"reporterId"`
+	ReportedUserID uuid.UUID         `json:"reportedUserId"`
+	ChatID         *uuid.UUID        `json:"chatId,omitempty"`
+	Reason         ReportReason      `json:"reason"`
+	Details        string            `json:"details"`
+	Messages       []ReportedMessage `json:"messages"`
+	Status         ReportStatus      `json:"status"`
+	CreatedAt      time.Time         `json:"createdAt"`
+	ReviewedAt     *time.Time        `json:"reviewedAt,omitempty"`
+}
+
+// ReportedMessage is a copy of a message taken when it was reported.
+type ReportedMessage struct {
+	ID        uuid.UUID   `json:"id"`
+	SenderID  uuid.UUID   `json:"senderId"`
+	Content   string      `json:"content"`
+	Type      MessageType `json:"type"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
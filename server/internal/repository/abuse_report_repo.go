@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/otoritech/chatat/internal/model"
+)
+
+// AbuseReportRepository defines data access operations for the moderation queue.
+type AbuseReportRepository interface {
+	// Create stores report and fills in its ID, status and creation time.
+	Create(ctx context.Context, report *model.AbuseReport) error
+}
+
+type pgAbuseReportRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewAbuseReportRepository creates a new PostgreSQL-backed AbuseReportRepository.
+func NewAbuseReportRepository(db *pgxpool.Pool) AbuseReportRepository {
+	return &pgAbuseReportRepository{db: db}
+}
+
+func (r *pgAbuseReportRepository) Create(ctx context.Context, report *model.AbuseReport) error {
+	messages := report.Messages
+	if messages == nil {
+		messages = []model.ReportedMessage{}
+	}
+	messagesJSON, err := json.Marshal(messages)
+	if err != nil {
+		return fmt.Errorf("marshal reported messages: %w", err)
+	}
+
+	err = r.db.QueryRow(ctx,
+		`INSERT INTO abuse_reports (reporter_id, reported_user_id, chat_id, reason, details, messages)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 RETURNING id, status, created_at`,
+		report.ReporterID, report.ReportedUserID, report.ChatID, report.Reason, report.Details, messagesJSON,
+	).Scan(&report.ID, &report.Status, &report.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("create abuse report: %w", err)
+	}
+	report.Messages = messages
+	return nil
+}
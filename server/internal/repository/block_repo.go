@@ -21,6 +21,7 @@ type BlockRepository interface {
 	Update(ctx context.Context, id uuid.UUID, input model.UpdateBlockInput) (*model.Block, error)
 	Reorder(ctx context.Context, docID uuid.UUID, blockIDs []uuid.UUID) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	SaveStates(ctx context.Context, docID uuid.UUID, states []model.BlockState) error
 }
 
 type pgBlockRepository struct {
@@ -173,3 +174,56 @@ func (r *pgBlockRepository) Delete(ctx context.Context, id uuid.UUID) error {
 
 	return nil
 }
+
+// SaveStates writes a batch of realtime block states in one transaction,
+// in order, so parents must come before their children.
+func (r *pgBlockRepository) SaveStates(ctx context.Context, docID uuid.UUID, states []model.BlockState) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin save states transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	for _, state := range states {
+		switch {
+		case state.Deleted:
+			_, err = tx.Exec(ctx,
+				`DELETE FROM blocks WHERE id = $1 AND document_id = $2`,
+				state.ID, docID,
+			)
+		case state.Type != "" && state.SortOrder != nil:
+			_, err = tx.Exec(ctx,
+				`INSERT INTO blocks (id, document_id, type, content, checked, sort_order, parent_block_id)
+				 VALUES ($1, $2, $3, COALESCE($4, ''), $5, $6, $7)
+				 ON CONFLICT (id) DO UPDATE SET
+				   content = COALESCE($4, blocks.content),
+				   checked = COALESCE($5, blocks.checked),
+				   sort_order = $6,
+				   parent_block_id = $7,
+				   updated_at = NOW()
+				 WHERE blocks.document_id = $2`,
+				state.ID, docID, state.Type, state.Content, state.Checked, *state.SortOrder, state.ParentBlockID,
+			)
+		default:
+			_, err = tx.Exec(ctx,
+				`UPDATE blocks SET
+				   content = COALESCE($3, content),
+				   checked = COALESCE($4, checked),
+				   sort_order = COALESCE($5, sort_order),
+				   parent_block_id = CASE WHEN $5::INTEGER IS NULL THEN parent_block_id ELSE $6 END,
+				   updated_at = NOW()
+				 WHERE id = $1 AND document_id = $2`,
+				state.ID, docID, state.Content, state.Checked, state.SortOrder, state.ParentBlockID,
+			)
+		}
+		if err != nil {
+			return fmt.Errorf("save block state %s: %w", state.ID.String(), err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit save states transaction: %w", err)
+	}
+
+	return nil
+}
@@ -94,3 +94,67 @@ func TestBlockRepository_Reorder(t *testing.T) {
 	assert.Equal(t, b1.ID, blocks[1].ID)
 	assert.Equal(t, b2.ID, blocks[2].ID)
 }
+
+func TestBlockRepository_SaveStates(t *testing.T) {
+	repo, doc := setupBlockTest(t)
+	ctx := context.Background()
+
+	existing, err := repo.Create(ctx, model.CreateBlockInput{
+		DocumentID: doc.ID, Type: model.BlockTypeParagraph,
+		Content: "Lama", SortOrder: 0,
+	})
+	require.NoError(t, err)
+	doomed, err := repo.Create(ctx, model.CreateBlockInput{
+		DocumentID: doc.ID, Type: model.BlockTypeParagraph,
+		Content: "Hapus", SortOrder: 1,
+	})
+	require.NoError(t, err)
+
+	newID := uuid.New()
+	content := "Baru"
+	checked := true
+	first, second := 0, 1
+
+	err = repo.SaveStates(ctx, doc.ID, []model.BlockState{
+		{ID: newID, Type: model.BlockTypeChecklist, Content: &content, Checked: &checked, SortOrder: &first},
+		{ID: existing.ID, Type: model.BlockTypeParagraph, SortOrder: &second, ParentBlockID: &newID},
+		{ID: doomed.ID, Deleted: true},
+	})
+	require.NoError(t, err)
+
+	blocks, err := repo.ListByDocument(ctx, doc.ID)
+	require.NoError(t, err)
+	require.Len(t, blocks, 2)
+	assert.Equal(t, newID, blocks[0].ID)
+	assert.Equal(t, model.BlockTypeChecklist, blocks[0].Type)
+	assert.Equal(t, "Baru", blocks[0].Content)
+	assert.Equal(t, &checked, blocks[0].Checked)
+	assert.Equal(t, existing.ID, blocks[1].ID)
+	assert.Equal(t, "Lama", blocks[1].Content, "nil content is left unchanged")
+	assert.Equal(t, &newID, blocks[1].ParentBlockID)
+
+	t.Run("update only", func(t *testing.T) {
+		edited := "Diubah"
+		require.NoError(t, repo.SaveStates(ctx, doc.ID, []model.BlockState{{ID: existing.ID, Content: &edited}}))
+
+		block, err := repo.FindByID(ctx, existing.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Diubah", block.Content)
+		assert.Equal(t, 1, block.SortOrder)
+		assert.Equal(t, &newID, block.ParentBlockID)
+	})
+
+	t.Run("rolls back the whole batch", func(t *testing.T) {
+		missingParent := uuid.New()
+		edited := "Tidak tersimpan"
+		err := repo.SaveStates(ctx, doc.ID, []model.BlockState{
+			{ID: existing.ID, Content: &edited},
+			{ID: uuid.New(), Type: model.BlockTypeParagraph, SortOrder: &first, ParentBlockID: &missingParent},
+		})
+		require.Error(t, err)
+
+		block, err := repo.FindByID(ctx, existing.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Diubah", block.Content)
+	})
+}
@@ -0,0 +1,307 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+const broadcastListColumns = `id, owner_id, name, created_at, updated_at`
+
+// broadcastColumns selects a broadcast with its delivery stats; queries
+// using it must join broadcast_messages as bm and message_status as ms and
+// group by b.id.
+const broadcastColumns = `b.id, b.list_id, b.sender_id, b.content, b.type, b.metadata, b.skipped_count,
+		        COUNT(bm.message_id), COUNT(ms.delivered_at), COUNT(ms.read_at), b.created_at`
+
+const broadcastStatsJoins = `LEFT JOIN broadcast_messages bm ON bm.broadcast_id = b.id
+		 LEFT JOIN message_status ms ON ms.message_id = bm.message_id AND ms.user_id = bm.recipient_id`
+
+// BroadcastRepository defines data access operations for broadcast lists
+// and the broadcasts sent to them.
+type BroadcastRepository interface {
+	CreateList(ctx context.Context, ownerID uuid.UUID, name string) (*model.BroadcastList, error)
+	FindListByID(ctx context.Context, id uuid.UUID) (*model.BroadcastList, error)
+	ListByOwner(ctx context.Context, ownerID uuid.UUID) ([]*model.BroadcastList, error)
+	RenameList(ctx context.Context, id uuid.UUID, name string) (*model.BroadcastList, error)
+	DeleteList(ctx context.Context, id uuid.UUID) error
+	// AddRecipients adds users to the list, ignoring ones already on it.
+	AddRecipients(ctx context.Context, listID uuid.UUID, userIDs []uuid.UUID) error
+	RemoveRecipient(ctx context.Context, listID, userID uuid.UUID) error
+	// ListRecipients returns the recipients of each list, oldest first.
+	ListRecipients(ctx context.Context, listIDs []uuid.UUID) (map[uuid.UUID][]uuid.UUID, error)
+
+	// CreateBroadcast records a broadcast and the messages it was sent as.
+	CreateBroadcast(ctx context.Context, input model.CreateBroadcastInput) (*model.Broadcast, error)
+	FindBroadcast(ctx context.Context, id uuid.UUID) (*model.Broadcast, error)
+	// ListBroadcasts returns the list's broadcasts, newest first.
+	ListBroadcasts(ctx context.Context, listID uuid.UUID) ([]*model.Broadcast, error)
+}
+
+type pgBroadcastRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewBroadcastRepository creates a new PostgreSQL-backed BroadcastRepository.
+func NewBroadcastRepository(db *pgxpool.Pool) BroadcastRepository {
+	return &pgBroadcastRepository{db: db}
+}
+
+func scanBroadcastList(row pgx.Row) (*model.BroadcastList, error) {
+	var l model.BroadcastList
+	if err := row.Scan(&l.ID, &l.OwnerID, &l.Name, &l.CreatedAt, &l.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func scanBroadcast(row pgx.Row) (*model.Broadcast, error) {
+	var b model.Broadcast
+	err := row.Scan(&b.ID, &b.ListID, &b.SenderID, &b.Content, &b.Type, &b.Metadata, &b.SkippedCount,
+		&b.Stats.Sent, &b.Stats.Delivered, &b.Stats.Read, &b.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (r *pgBroadcastRepository) CreateList(ctx context.Context, ownerID uuid.UUID, name string) (*model.BroadcastList, error) {
+	list, err := scanBroadcastList(r.db.QueryRow(ctx,
+		`INSERT INTO broadcast_lists (owner_id, name) VALUES ($1, $2)
+		 RETURNING `+broadcastListColumns,
+		ownerID, name,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("create broadcast list: %w", err)
+	}
+	return list, nil
+}
+
+func (r *pgBroadcastRepository) FindListByID(ctx context.Context, id uuid.UUID) (*model.BroadcastList, error) {
+	list, err := scanBroadcastList(r.db.QueryRow(ctx,
+		`SELECT `+broadcastListColumns+` FROM broadcast_lists WHERE id = $1`, id,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NotFound("broadcast list", id.String())
+		}
+		return nil, fmt.Errorf("find broadcast list: %w", err)
+	}
+	return list, nil
+}
+
+func (r *pgBroadcastRepository) ListByOwner(ctx context.Context, ownerID uuid.UUID) ([]*model.BroadcastList, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+broadcastListColumns+` FROM broadcast_lists
+		 WHERE owner_id = $1
+		 ORDER BY created_at DESC`, ownerID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list broadcast lists: %w", err)
+	}
+	defer rows.Close()
+
+	var lists []*model.BroadcastList
+	for rows.Next() {
+		list, err := scanBroadcastList(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan broadcast list: %w", err)
+		}
+		lists = append(lists, list)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate broadcast list rows: %w", err)
+	}
+
+	return lists, nil
+}
+
+func (r *pgBroadcastRepository) RenameList(ctx context.Context, id uuid.UUID, name string) (*model.BroadcastList, error) {
+	list, err := scanBroadcastList(r.db.QueryRow(ctx,
+		`UPDATE broadcast_lists SET name = $2, updated_at = NOW()
+		 WHERE id = $1
+		 RETURNING `+broadcastListColumns,
+		id, name,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NotFound("broadcast list", id.String())
+		}
+		return nil, fmt.Errorf("rename broadcast list: %w", err)
+	}
+	return list, nil
+}
+
+func (r *pgBroadcastRepository) DeleteList(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM broadcast_lists WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete broadcast list: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NotFound("broadcast list", id.String())
+	}
+
+	return nil
+}
+
+func (r *pgBroadcastRepository) AddRecipients(ctx context.Context, listID uuid.UUID, userIDs []uuid.UUID) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO broadcast_list_recipients (list_id, user_id)
+		 SELECT $1, unnest($2::uuid[])
+		 ON CONFLICT (list_id, user_id) DO NOTHING`,
+		listID, userIDs,
+	)
+	if err != nil {
+		return fmt.Errorf("add broadcast recipients: %w", err)
+	}
+
+	return nil
+}
+
+func (r *pgBroadcastRepository) RemoveRecipient(ctx context.Context, listID, userID uuid.UUID) error {
+	result, err := r.db.Exec(ctx,
+		`DELETE FROM broadcast_list_recipients WHERE list_id = $1 AND user_id = $2`,
+		listID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("remove broadcast recipient: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NotFound("broadcast recipient", userID.String())
+	}
+
+	return nil
+}
+
+func (r *pgBroadcastRepository) ListRecipients(ctx context.Context, listIDs []uuid.UUID) (map[uuid.UUID][]uuid.UUID, error) {
+	recipients := make(map[uuid.UUID][]uuid.UUID)
+	if len(listIDs) == 0 {
+		return recipients, nil
+	}
+
+	rows, err := r.db.Query(ctx,
+		`SELECT list_id, user_id FROM broadcast_list_recipients
+		 WHERE list_id = ANY($1)
+		 ORDER BY added_at ASC`,
+		listIDs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list broadcast recipients: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var listID, userID uuid.UUID
+		if err := rows.Scan(&listID, &userID); err != nil {
+			return nil, fmt.Errorf("scan broadcast recipient: %w", err)
+		}
+		recipients[listID] = append(recipients[listID], userID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate broadcast recipient rows: %w", err)
+	}
+
+	return recipients, nil
+}
+
+func (r *pgBroadcastRepository) CreateBroadcast(ctx context.Context, input model.CreateBroadcastInput) (*model.Broadcast, error) {
+	msgType := input.Type
+	if msgType == "" {
+		msgType = model.MessageTypeText
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin create broadcast transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var id uuid.UUID
+	err = tx.QueryRow(ctx,
+		`INSERT INTO broadcasts (list_id, sender_id, content, type, metadata, skipped_count)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 RETURNING id`,
+		input.ListID, input.SenderID, input.Content, msgType, input.Metadata, input.SkippedCount,
+	).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("create broadcast: %w", err)
+	}
+
+	for _, d := range input.Deliveries {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO broadcast_messages (broadcast_id, message_id, recipient_id) VALUES ($1, $2, $3)`,
+			id, d.MessageID, d.RecipientID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("record broadcast message: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit create broadcast transaction: %w", err)
+	}
+
+	return r.FindBroadcast(ctx, id)
+}
+
+func (r *pgBroadcastRepository) FindBroadcast(ctx context.Context, id uuid.UUID) (*model.Broadcast, error) {
+	b, err := scanBroadcast(r.db.QueryRow(ctx,
+		`SELECT `+broadcastColumns+`
+		 FROM broadcasts b
+		 `+broadcastStatsJoins+`
+		 WHERE b.id = $1
+		 GROUP BY b.id`, id,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NotFound("broadcast", id.String())
+		}
+		return nil, fmt.Errorf("find broadcast: %w", err)
+	}
+	return b, nil
+}
+
+func (r *pgBroadcastRepository) ListBroadcasts(ctx context.Context, listID uuid.UUID) ([]*model.Broadcast, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+broadcastColumns+`
+		 FROM broadcasts b
+		 `+broadcastStatsJoins+`
+		 WHERE b.list_id = $1
+		 GROUP BY b.id
+		 ORDER BY b.created_at DESC`, listID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list broadcasts: %w", err)
+	}
+	defer rows.Close()
+
+	var broadcasts []*model.Broadcast
+	for rows.Next() {
+		b, err := scanBroadcast(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan broadcast: %w", err)
+		}
+		broadcasts = append(broadcasts, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate broadcast rows: %w", err)
+	}
+
+	return broadcasts, nil
+}
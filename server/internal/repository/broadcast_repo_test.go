@@ -0,0 +1,104 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/internal/repository"
+	"github.com/otoritech/chatat/internal/testutil"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+func TestBroadcastRepository_Lists(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testutil.CleanTables(t, testPool)
+	ctx := context.Background()
+
+	owner := createTestUser(t, "+62650", "Owner")
+	a := createTestUser(t, "+62651", "A")
+	b := createTestUser(t, "+62652", "B")
+
+	repo := repository.NewBroadcastRepository(testPool)
+	list, err := repo.CreateList(ctx, owner.ID, "Pelanggan")
+	require.NoError(t, err)
+
+	require.NoError(t, repo.AddRecipients(ctx, list.ID, []uuid.UUID{a.ID, b.ID}))
+	// Re-adding an existing recipient is ignored
+	require.NoError(t, repo.AddRecipients(ctx, list.ID, []uuid.UUID{a.ID}))
+
+	recipients, err := repo.ListRecipients(ctx, []uuid.UUID{list.ID})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{a.ID, b.ID}, recipients[list.ID])
+
+	require.NoError(t, repo.RemoveRecipient(ctx, list.ID, b.ID))
+	err = repo.RemoveRecipient(ctx, list.ID, b.ID)
+	assert.True(t, apperror.IsNotFound(err))
+
+	renamed, err := repo.RenameList(ctx, list.ID, "VIP")
+	require.NoError(t, err)
+	assert.Equal(t, "VIP", renamed.Name)
+
+	lists, err := repo.ListByOwner(ctx, owner.ID)
+	require.NoError(t, err)
+	assert.Len(t, lists, 1)
+
+	require.NoError(t, repo.DeleteList(ctx, list.ID))
+	_, err = repo.FindListByID(ctx, list.ID)
+	assert.True(t, apperror.IsNotFound(err))
+}
+
+func TestBroadcastRepository_Stats(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testutil.CleanTables(t, testPool)
+	ctx := context.Background()
+
+	owner := createTestUser(t, "+62660", "Owner")
+	a := createTestUser(t, "+62661", "A")
+	b := createTestUser(t, "+62662", "B")
+
+	repo := repository.NewBroadcastRepository(testPool)
+	list, err := repo.CreateList(ctx, owner.ID, "Pelanggan")
+	require.NoError(t, err)
+
+	chatRepo := repository.NewChatRepository(testPool)
+	msgRepo := repository.NewMessageRepository(testPool)
+	statRepo := repository.NewMessageStatusRepository(testPool)
+
+	var deliveries []model.BroadcastDelivery
+	for _, recipient := range []*model.User{a, b} {
+		chat, err := chatRepo.Create(ctx, model.CreateChatInput{Type: model.ChatTypePersonal, CreatedBy: owner.ID})
+		require.NoError(t, err)
+		msg, err := msgRepo.Create(ctx, model.CreateMessageInput{ChatID: chat.ID, SenderID: owner.ID, Content: "Promo"})
+		require.NoError(t, err)
+		require.NoError(t, statRepo.Create(ctx, msg.ID, recipient.ID, model.DeliveryStatusSent))
+		deliveries = append(deliveries, model.BroadcastDelivery{MessageID: msg.ID, RecipientID: recipient.ID})
+	}
+
+	broadcast, err := repo.CreateBroadcast(ctx, model.CreateBroadcastInput{
+		ListID: list.ID, SenderID: owner.ID, Content: "Promo", SkippedCount: 1, Deliveries: deliveries,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, model.BroadcastStats{Sent: 2}, broadcast.Stats)
+	assert.Equal(t, 1, broadcast.SkippedCount)
+
+	require.NoError(t, statRepo.UpdateStatus(ctx, deliveries[0].MessageID, a.ID, model.DeliveryStatusDelivered))
+	require.NoError(t, statRepo.UpdateStatus(ctx, deliveries[1].MessageID, b.ID, model.DeliveryStatusRead))
+
+	found, err := repo.FindBroadcast(ctx, broadcast.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.BroadcastStats{Sent: 2, Delivered: 2, Read: 1}, found.Stats)
+
+	broadcasts, err := repo.ListBroadcasts(ctx, list.ID)
+	require.NoError(t, err)
+	require.Len(t, broadcasts, 1)
+	assert.Equal(t, found.Stats, broadcasts[0].Stats)
+}
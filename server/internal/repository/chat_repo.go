@@ -21,11 +21,21 @@ type ChatRepository interface {
 	ListByUser(ctx context.Context, userID uuid.UUID) ([]*model.ChatWithLastMessage, error)
 	AddMember(ctx context.Context, chatID, userID uuid.UUID, role model.MemberRole) error
 	RemoveMember(ctx context.Context, chatID, userID uuid.UUID) error
+	// UpdateMemberRole changes an existing member's role.
+	UpdateMemberRole(ctx context.Context, chatID, userID uuid.UUID, role model.MemberRole) error
+	// TransferOwnership makes toUserID the owner and fromUserID an admin in
+	// one transaction.
+	TransferOwnership(ctx context.Context, chatID, fromUserID, toUserID uuid.UUID) error
 	GetMembers(ctx context.Context, chatID uuid.UUID) ([]*model.ChatMember, error)
 	Update(ctx context.Context, id uuid.UUID, input model.UpdateChatInput) (*model.Chat, error)
 	Delete(ctx context.Context, id uuid.UUID) error
-	Pin(ctx context.Context, id uuid.UUID) error
-	Unpin(ctx context.Context, id uuid.UUID) error
+	// UpdateSettings changes one member's own settings for the chat.
+	UpdateSettings(ctx context.Context, chatID, userID uuid.UUID, input model.UpdateChatSettingsInput) (*model.ChatSettings, error)
+	// SetDisappearingTimer sets how many seconds messages sent from now on
+	// live; 0 turns disappearing messages off.
+	SetDisappearingTimer(ctx context.Context, id uuid.UUID, seconds int) (*model.Chat, error)
+	// UpdatePermissions changes the group's admin-only settings.
+	UpdatePermissions(ctx context.Context, id uuid.UUID, input model.UpdateGroupPermissionsInput) (*model.Chat, error)
 }
 
 type pgChatRepository struct {
@@ -42,11 +52,15 @@ func (r *pgChatRepository) Create(ctx context.Context, input model.CreateChatInp
 	err := r.db.QueryRow(ctx,
 		`INSERT INTO chats (type, name, icon, description, created_by)
 		 VALUES ($1, $2, $3, $4, $5)
-		 RETURNING id, type, name, icon, description, created_by, pinned_at, created_at, updated_at`,
+		 RETURNING id, type, name, icon, description, created_by, disappearing_timer,
+		   only_admins_send, only_admins_edit_info, only_admins_create_topics, only_admins_add_members, created_at, updated_at`,
 		input.Type, input.Name, input.Icon, input.Description, input.CreatedBy,
 	).Scan(
 		&chat.ID, &chat.Type, &chat.Name, &chat.Icon, &chat.Description,
-		&chat.CreatedBy, &chat.PinnedAt, &chat.CreatedAt, &chat.UpdatedAt,
+		&chat.CreatedBy, &chat.DisappearingTimer,
+		&chat.Permissions.OnlyAdminsSend, &chat.Permissions.OnlyAdminsEditInfo,
+		&chat.Permissions.OnlyAdminsCreateTopics, &chat.Permissions.OnlyAdminsAddMembers,
+		&chat.CreatedAt, &chat.UpdatedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("create chat: %w", err)
@@ -58,11 +72,15 @@ func (r *pgChatRepository) Create(ctx context.Context, input model.CreateChatInp
 func (r *pgChatRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Chat, error) {
 	var chat model.Chat
 	err := r.db.QueryRow(ctx,
-		`SELECT id, type, name, icon, description, created_by, pinned_at, created_at, updated_at
+		`SELECT id, type, name, icon, description, created_by, disappearing_timer,
+		   only_admins_send, only_admins_edit_info, only_admins_create_topics, only_admins_add_members, created_at, updated_at
 		 FROM chats WHERE id = $1`, id,
 	).Scan(
 		&chat.ID, &chat.Type, &chat.Name, &chat.Icon, &chat.Description,
-		&chat.CreatedBy, &chat.PinnedAt, &chat.CreatedAt, &chat.UpdatedAt,
+		&chat.CreatedBy, &chat.DisappearingTimer,
+		&chat.Permissions.OnlyAdminsSend, &chat.Permissions.OnlyAdminsEditInfo,
+		&chat.Permissions.OnlyAdminsCreateTopics, &chat.Permissions.OnlyAdminsAddMembers,
+		&chat.CreatedAt, &chat.UpdatedAt,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -77,14 +95,18 @@ func (r *pgChatRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.C
 func (r *pgChatRepository) FindPersonalChat(ctx context.Context, userID1, userID2 uuid.UUID) (*model.Chat, error) {
 	var chat model.Chat
 	err := r.db.QueryRow(ctx,
-		`SELECT c.id, c.type, c.name, c.icon, c.description, c.created_by, c.pinned_at, c.created_at, c.updated_at
+		`SELECT c.id, c.type, c.name, c.icon, c.description, c.created_by, c.disappearing_timer,
+		        c.only_admins_send, c.only_admins_edit_info, c.only_admins_create_topics, c.only_admins_add_members, c.created_at, c.updated_at
 		 FROM chats c
 		 JOIN chat_members cm1 ON c.id = cm1.chat_id AND cm1.user_id = $1
 		 JOIN chat_members cm2 ON c.id = cm2.chat_id AND cm2.user_id = $2
 		 WHERE c.type = 'personal'`, userID1, userID2,
 	).Scan(
 		&chat.ID, &chat.Type, &chat.Name, &chat.Icon, &chat.Description,
-		&chat.CreatedBy, &chat.PinnedAt, &chat.CreatedAt, &chat.UpdatedAt,
+		&chat.CreatedBy, &chat.DisappearingTimer,
+		&chat.Permissions.OnlyAdminsSend, &chat.Permissions.OnlyAdminsEditInfo,
+		&chat.Permissions.OnlyAdminsCreateTopics, &chat.Permissions.OnlyAdminsAddMembers,
+		&chat.CreatedAt, &chat.UpdatedAt,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -98,8 +120,12 @@ func (r *pgChatRepository) FindPersonalChat(ctx context.Context, userID1, userID
 
 func (r *pgChatRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*model.ChatWithLastMessage, error) {
 	rows, err := r.db.Query(ctx,
-		`SELECT c.id, c.type, c.name, c.icon, c.description, c.created_by, c.pinned_at, c.created_at, c.updated_at,
-		        (SELECT m.content FROM messages m WHERE m.chat_id = c.id ORDER BY m.created_at DESC LIMIT 1) as last_message
+		`SELECT c.id, c.type, c.name, c.icon, c.description, c.created_by, c.disappearing_timer,
+		        c.only_admins_send, c.only_admins_edit_info, c.only_admins_create_topics, c.only_admins_add_members, c.created_at, c.updated_at,
+		        cm.pinned_at, cm.archived_at, cm.muted_until, cm.notification_sound,
+		        (SELECT m.content FROM messages m
+		         WHERE m.chat_id = c.id AND (m.expires_at IS NULL OR m.expires_at > NOW())
+		         ORDER BY m.created_at DESC LIMIT 1) as last_message
 		 FROM chats c
 		 JOIN chat_members cm ON c.id = cm.chat_id
 		 WHERE cm.user_id = $1
@@ -115,8 +141,12 @@ func (r *pgChatRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]
 		var cwm model.ChatWithLastMessage
 		if err := rows.Scan(
 			&cwm.Chat.ID, &cwm.Chat.Type, &cwm.Chat.Name, &cwm.Chat.Icon,
-			&cwm.Chat.Description, &cwm.Chat.CreatedBy, &cwm.Chat.PinnedAt,
-			&cwm.Chat.CreatedAt, &cwm.Chat.UpdatedAt, &cwm.LastMessage,
+			&cwm.Chat.Description, &cwm.Chat.CreatedBy, &cwm.Chat.DisappearingTimer,
+			&cwm.Chat.Permissions.OnlyAdminsSend, &cwm.Chat.Permissions.OnlyAdminsEditInfo,
+			&cwm.Chat.Permissions.OnlyAdminsCreateTopics, &cwm.Chat.Permissions.OnlyAdminsAddMembers,
+			&cwm.Chat.CreatedAt, &cwm.Chat.UpdatedAt,
+			&cwm.Settings.PinnedAt, &cwm.Settings.ArchivedAt, &cwm.Settings.MutedUntil, &cwm.Settings.NotificationSound,
+			&cwm.LastMessage,
 		); err != nil {
 			return nil, fmt.Errorf("scan chat row: %w", err)
 		}
@@ -159,9 +189,63 @@ func (r *pgChatRepository) RemoveMember(ctx context.Context, chatID, userID uuid
 	return nil
 }
 
+func (r *pgChatRepository) UpdateMemberRole(ctx context.Context, chatID, userID uuid.UUID, role model.MemberRole) error {
+	result, err := r.db.Exec(ctx,
+		`UPDATE chat_members SET role = $3 WHERE chat_id = $1 AND user_id = $2`,
+		chatID, userID, role,
+	)
+	if err != nil {
+		return fmt.Errorf("update member role: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NotFound("chat member", userID.String())
+	}
+
+	return nil
+}
+
+func (r *pgChatRepository) TransferOwnership(ctx context.Context, chatID, fromUserID, toUserID uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transfer ownership transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Demote first so the one-owner-per-chat index holds throughout
+	result, err := tx.Exec(ctx,
+		`UPDATE chat_members SET role = 'admin'
+		 WHERE chat_id = $1 AND user_id = $2 AND role = 'owner'`,
+		chatID, fromUserID,
+	)
+	if err != nil {
+		return fmt.Errorf("demote previous owner: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return apperror.NotFound("chat owner", fromUserID.String())
+	}
+
+	result, err = tx.Exec(ctx,
+		`UPDATE chat_members SET role = 'owner' WHERE chat_id = $1 AND user_id = $2`,
+		chatID, toUserID,
+	)
+	if err != nil {
+		return fmt.Errorf("promote new owner: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return apperror.NotFound("chat member", toUserID.String())
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transfer ownership transaction: %w", err)
+	}
+
+	return nil
+}
+
 func (r *pgChatRepository) GetMembers(ctx context.Context, chatID uuid.UUID) ([]*model.ChatMember, error) {
 	rows, err := r.db.Query(ctx,
-		`SELECT chat_id, user_id, role, joined_at
+		`SELECT chat_id, user_id, role, joined_at, pinned_at, archived_at, muted_until, notification_sound
 		 FROM chat_members WHERE chat_id = $1
 		 ORDER BY joined_at`, chatID,
 	)
@@ -173,7 +257,10 @@ func (r *pgChatRepository) GetMembers(ctx context.Context, chatID uuid.UUID) ([]
 	var members []*model.ChatMember
 	for rows.Next() {
 		var m model.ChatMember
-		if err := rows.Scan(&m.ChatID, &m.UserID, &m.Role, &m.JoinedAt); err != nil {
+		if err := rows.Scan(
+			&m.ChatID, &m.UserID, &m.Role, &m.JoinedAt,
+			&m.Settings.PinnedAt, &m.Settings.ArchivedAt, &m.Settings.MutedUntil, &m.Settings.NotificationSound,
+		); err != nil {
 			return nil, fmt.Errorf("scan chat member: %w", err)
 		}
 		members = append(members, &m)
@@ -195,11 +282,15 @@ func (r *pgChatRepository) Update(ctx context.Context, id uuid.UUID, input model
 		   description = COALESCE($4, description),
 		   updated_at = NOW()
 		 WHERE id = $1
-		 RETURNING id, type, name, icon, description, created_by, pinned_at, created_at, updated_at`,
+		 RETURNING id, type, name, icon, description, created_by, disappearing_timer,
+		   only_admins_send, only_admins_edit_info, only_admins_create_topics, only_admins_add_members, created_at, updated_at`,
 		id, input.Name, input.Icon, input.Description,
 	).Scan(
 		&chat.ID, &chat.Type, &chat.Name, &chat.Icon, &chat.Description,
-		&chat.CreatedBy, &chat.PinnedAt, &chat.CreatedAt, &chat.UpdatedAt,
+		&chat.CreatedBy, &chat.DisappearingTimer,
+		&chat.Permissions.OnlyAdminsSend, &chat.Permissions.OnlyAdminsEditInfo,
+		&chat.Permissions.OnlyAdminsCreateTopics, &chat.Permissions.OnlyAdminsAddMembers,
+		&chat.CreatedAt, &chat.UpdatedAt,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -226,28 +317,79 @@ func (r *pgChatRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
-func (r *pgChatRepository) Pin(ctx context.Context, id uuid.UUID) error {
-	result, err := r.db.Exec(ctx,
-		`UPDATE chats SET pinned_at = NOW() WHERE id = $1`, id,
-	)
+func (r *pgChatRepository) UpdateSettings(ctx context.Context, chatID, userID uuid.UUID, input model.UpdateChatSettingsInput) (*model.ChatSettings, error) {
+	var settings model.ChatSettings
+	err := r.db.QueryRow(ctx,
+		`UPDATE chat_members SET
+		   pinned_at = CASE WHEN $3::boolean IS NULL THEN pinned_at WHEN $3 THEN COALESCE(pinned_at, NOW()) END,
+		   archived_at = CASE WHEN $4::boolean IS NULL THEN archived_at WHEN $4 THEN COALESCE(archived_at, NOW()) END,
+		   muted_until = CASE WHEN $5::timestamptz IS NULL THEN muted_until WHEN $5 > NOW() THEN $5 END,
+		   notification_sound = COALESCE($6, notification_sound)
+		 WHERE chat_id = $1 AND user_id = $2
+		 RETURNING pinned_at, archived_at, muted_until, notification_sound`,
+		chatID, userID, input.Pinned, input.Archived, input.MutedUntil, input.NotificationSound,
+	).Scan(&settings.PinnedAt, &settings.ArchivedAt, &settings.MutedUntil, &settings.NotificationSound)
 	if err != nil {
-		return fmt.Errorf("pin chat: %w", err)
-	}
-	if result.RowsAffected() == 0 {
-		return apperror.NotFound("chat", id.String())
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NotFound("chat member", userID.String())
+		}
+		return nil, fmt.Errorf("update chat settings: %w", err)
 	}
-	return nil
+
+	return &settings, nil
 }
 
-func (r *pgChatRepository) Unpin(ctx context.Context, id uuid.UUID) error {
-	result, err := r.db.Exec(ctx,
-		`UPDATE chats SET pinned_at = NULL WHERE id = $1`, id,
+func (r *pgChatRepository) SetDisappearingTimer(ctx context.Context, id uuid.UUID, seconds int) (*model.Chat, error) {
+	var chat model.Chat
+	err := r.db.QueryRow(ctx,
+		`UPDATE chats SET disappearing_timer = $2, updated_at = NOW()
+		 WHERE id = $1
+		 RETURNING id, type, name, icon, description, created_by, disappearing_timer,
+		   only_admins_send, only_admins_edit_info, only_admins_create_topics, only_admins_add_members, created_at, updated_at`,
+		id, seconds,
+	).Scan(
+		&chat.ID, &chat.Type, &chat.Name, &chat.Icon, &chat.Description,
+		&chat.CreatedBy, &chat.DisappearingTimer,
+		&chat.Permissions.OnlyAdminsSend, &chat.Permissions.OnlyAdminsEditInfo,
+		&chat.Permissions.OnlyAdminsCreateTopics, &chat.Permissions.OnlyAdminsAddMembers,
+		&chat.CreatedAt, &chat.UpdatedAt,
 	)
 	if err != nil {
-		return fmt.Errorf("unpin chat: %w", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NotFound("chat", id.String())
+		}
+		return nil, fmt.Errorf("set disappearing timer: %w", err)
 	}
-	if result.RowsAffected() == 0 {
-		return apperror.NotFound("chat", id.String())
+
+	return &chat, nil
+}
+
+func (r *pgChatRepository) UpdatePermissions(ctx context.Context, id uuid.UUID, input model.UpdateGroupPermissionsInput) (*model.Chat, error) {
+	var chat model.Chat
+	err := r.db.QueryRow(ctx,
+		`UPDATE chats SET
+		   only_admins_send = COALESCE($2, only_admins_send),
+		   only_admins_edit_info = COALESCE($3, only_admins_edit_info),
+		   only_admins_create_topics = COALESCE($4, only_admins_create_topics),
+		   only_admins_add_members = COALESCE($5, only_admins_add_members),
+		   updated_at = NOW()
+		 WHERE id = $1
+		 RETURNING id, type, name, icon, description, created_by, disappearing_timer,
+		   only_admins_send, only_admins_edit_info, only_admins_create_topics, only_admins_add_members, created_at, updated_at`,
+		id, input.OnlyAdminsSend, input.OnlyAdminsEditInfo, input.OnlyAdminsCreateTopics, input.OnlyAdminsAddMembers,
+	).Scan(
+		&chat.ID, &chat.Type, &chat.Name, &chat.Icon, &chat.Description,
+		&chat.CreatedBy, &chat.DisappearingTimer,
+		&chat.Permissions.OnlyAdminsSend, &chat.Permissions.OnlyAdminsEditInfo,
+		&chat.Permissions.OnlyAdminsCreateTopics, &chat.Permissions.OnlyAdminsAddMembers,
+		&chat.CreatedAt, &chat.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NotFound("chat", id.String())
+		}
+		return nil, fmt.Errorf("update group permissions: %w", err)
 	}
-	return nil
+
+	return &chat, nil
 }
@@ -3,6 +3,7 @@ package repository_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -154,6 +155,131 @@ func TestChatRepository_Update(t *testing.T) {
 	assert.Equal(t, "New Name", updated.Name)
 }
 
+func TestChatRepository_UpdatePermissions(t *testing.T) {
+	repo := setupChatRepo(t)
+	ctx := context.Background()
+	user := createTestUser(t, "+62013", "User13")
+
+	chat, err := repo.Create(ctx, model.CreateChatInput{
+		Type:      model.ChatTypeGroup,
+		Name:      "Permissions",
+		CreatedBy: user.ID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, model.GroupPermissions{OnlyAdminsEditInfo: true, OnlyAdminsAddMembers: true}, chat.Permissions)
+
+	yes, no := true, false
+	updated, err := repo.UpdatePermissions(ctx, chat.ID, model.UpdateGroupPermissionsInput{
+		OnlyAdminsSend:     &yes,
+		OnlyAdminsEditInfo: &no,
+	})
+	require.NoError(t, err)
+	assert.True(t, updated.Permissions.OnlyAdminsSend)
+	assert.False(t, updated.Permissions.OnlyAdminsEditInfo)
+	assert.True(t, updated.Permissions.OnlyAdminsAddMembers)
+
+	found, err := repo.FindByID(ctx, chat.ID)
+	require.NoError(t, err)
+	assert.Equal(t, updated.Permissions, found.Permissions)
+
+	_, err = repo.UpdatePermissions(ctx, uuid.New(), model.UpdateGroupPermissionsInput{OnlyAdminsSend: &yes})
+	assert.True(t, apperror.IsNotFound(err))
+}
+
+func TestChatRepository_MemberRoles(t *testing.T) {
+	repo := setupChatRepo(t)
+	ctx := context.Background()
+	owner := createTestUser(t, "+62014", "Owner")
+	member := createTestUser(t, "+62015", "Member")
+
+	chat, err := repo.Create(ctx, model.CreateChatInput{
+		Type:      model.ChatTypeGroup,
+		Name:      "Roles",
+		CreatedBy: owner.ID,
+	})
+	require.NoError(t, err)
+	require.NoError(t, repo.AddMember(ctx, chat.ID, owner.ID, model.MemberRoleOwner))
+	require.NoError(t, repo.AddMember(ctx, chat.ID, member.ID, model.MemberRoleMember))
+
+	roles := func() map[uuid.UUID]model.MemberRole {
+		members, err := repo.GetMembers(ctx, chat.ID)
+		require.NoError(t, err)
+		result := make(map[uuid.UUID]model.MemberRole, len(members))
+		for _, m := range members {
+			result[m.UserID] = m.Role
+		}
+		return result
+	}
+
+	require.NoError(t, repo.UpdateMemberRole(ctx, chat.ID, member.ID, model.MemberRoleAdmin))
+	assert.Equal(t, model.MemberRoleAdmin, roles()[member.ID])
+
+	err = repo.UpdateMemberRole(ctx, chat.ID, uuid.New(), model.MemberRoleAdmin)
+	assert.True(t, apperror.IsNotFound(err))
+
+	// Only one owner per chat
+	err = repo.UpdateMemberRole(ctx, chat.ID, member.ID, model.MemberRoleOwner)
+	assert.Error(t, err)
+
+	require.NoError(t, repo.TransferOwnership(ctx, chat.ID, owner.ID, member.ID))
+	assert.Equal(t, model.MemberRoleAdmin, roles()[owner.ID])
+	assert.Equal(t, model.MemberRoleOwner, roles()[member.ID])
+
+	// The previous owner no longer owns the chat
+	err = repo.TransferOwnership(ctx, chat.ID, owner.ID, member.ID)
+	assert.True(t, apperror.IsNotFound(err))
+}
+
+func TestChatRepository_UpdateSettings(t *testing.T) {
+	repo := setupChatRepo(t)
+	ctx := context.Background()
+	user := createTestUser(t, "+62010", "User10")
+	other := createTestUser(t, "+62011", "User11")
+
+	chat, err := repo.Create(ctx, model.CreateChatInput{
+		Type:      model.ChatTypeGroup,
+		Name:      "Settings",
+		CreatedBy: user.ID,
+	})
+	require.NoError(t, err)
+	require.NoError(t, repo.AddMember(ctx, chat.ID, user.ID, model.MemberRoleAdmin))
+	require.NoError(t, repo.AddMember(ctx, chat.ID, other.ID, model.MemberRoleMember))
+
+	pinned := true
+	until := time.Now().Add(time.Hour)
+	sound := "chime"
+	settings, err := repo.UpdateSettings(ctx, chat.ID, user.ID, model.UpdateChatSettingsInput{
+		Pinned: &pinned, MutedUntil: &until, NotificationSound: &sound,
+	})
+	require.NoError(t, err)
+	assert.NotNil(t, settings.PinnedAt)
+	assert.Nil(t, settings.ArchivedAt)
+	assert.True(t, settings.IsMuted(time.Now()))
+	assert.Equal(t, "chime", settings.NotificationSound)
+
+	// Untouched fields keep their values
+	archived := true
+	settings, err = repo.UpdateSettings(ctx, chat.ID, user.ID, model.UpdateChatSettingsInput{Archived: &archived})
+	require.NoError(t, err)
+	assert.NotNil(t, settings.PinnedAt)
+	assert.NotNil(t, settings.ArchivedAt)
+	assert.Equal(t, "chime", settings.NotificationSound)
+
+	list, err := repo.ListByUser(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.NotNil(t, list[0].Settings.PinnedAt)
+
+	// Settings are per member
+	list, err = repo.ListByUser(ctx, other.ID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Nil(t, list[0].Settings.PinnedAt)
+
+	_, err = repo.UpdateSettings(ctx, chat.ID, uuid.New(), model.UpdateChatSettingsInput{Archived: &archived})
+	assert.True(t, apperror.IsNotFound(err))
+}
+
 func TestChatRepository_CascadeDelete(t *testing.T) {
 	chatRepo := setupChatRepo(t)
 	msgRepo := repository.NewMessageRepository(testPool)
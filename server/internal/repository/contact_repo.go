@@ -23,6 +23,8 @@ type ContactRepository interface {
 	UpsertBatch(ctx context.Context, userID uuid.UUID, contacts []ContactUpsertInput) error
 	FindByUserID(ctx context.Context, userID uuid.UUID) ([]UserContact, error)
 	FindContactsOf(ctx context.Context, contactUserID uuid.UUID) ([]uuid.UUID, error)
+	// IsContact reports whether userID has contactUserID in their contacts.
+	IsContact(ctx context.Context, userID, contactUserID uuid.UUID) (bool, error)
 	Delete(ctx context.Context, userID, contactUserID uuid.UUID) error
 	DeleteAllByUserID(ctx context.Context, userID uuid.UUID) error
 }
@@ -144,6 +146,18 @@ func (r *pgContactRepository) FindContactsOf(ctx context.Context, contactUserID
 	return userIDs, nil
 }
 
+func (r *pgContactRepository) IsContact(ctx context.Context, userID, contactUserID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM user_contacts WHERE user_id = $1 AND contact_user_id = $2)`,
+		userID, contactUserID,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("check contact: %w", err)
+	}
+	return exists, nil
+}
+
 func (r *pgContactRepository) Delete(ctx context.Context, userID, contactUserID uuid.UUID) error {
 	_, err := r.db.Exec(ctx,
 		`DELETE FROM user_contacts WHERE user_id = $1 AND contact_user_id = $2`,
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+// DocumentCommentRepository defines data access operations for block comments.
+type DocumentCommentRepository interface {
+	Create(ctx context.Context, input model.CreateDocumentCommentInput) (*model.DocumentComment, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*model.DocumentComment, error)
+	// ListByDocument returns the document's comments and replies, oldest
+	// first. A non-nil blockID limits them to that block.
+	ListByDocument(ctx context.Context, docID uuid.UUID, blockID *uuid.UUID) ([]*model.DocumentComment, error)
+	ListReplies(ctx context.Context, parentID uuid.UUID) ([]*model.DocumentComment, error)
+	// SetResolved marks a thread resolved by resolvedBy, or reopens it when
+	// resolvedBy is nil.
+	SetResolved(ctx context.Context, id uuid.UUID, resolvedBy *uuid.UUID) (*model.DocumentComment, error)
+	// Delete removes a comment together with its replies.
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// documentCommentColumns is the column list scanned by scanDocumentComment.
+const documentCommentColumns = `id, document_id, block_id, parent_id, author_id, content,
+	range_start, range_end, quoted_text, resolved_at, resolved_by, created_at, updated_at`
+
+type pgDocumentCommentRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewDocumentCommentRepository creates a new PostgreSQL-backed DocumentCommentRepository.
+func NewDocumentCommentRepository(db *pgxpool.Pool) DocumentCommentRepository {
+	return &pgDocumentCommentRepository{db: db}
+}
+
+func (r *pgDocumentCommentRepository) Create(ctx context.Context, input model.CreateDocumentCommentInput) (*model.DocumentComment, error) {
+	var rangeStart, rangeEnd *int
+	quote := ""
+	if input.Range != nil {
+		rangeStart, rangeEnd = &input.Range.Start, &input.Range.End
+		quote = input.Range.Quote
+	}
+
+	c, err := scanDocumentComment(r.db.QueryRow(ctx,
+		`INSERT INTO document_comments (document_id, block_id, parent_id, author_id, content, range_start, range_end, quoted_text)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		 RETURNING `+documentCommentColumns,
+		input.DocumentID, input.BlockID, input.ParentID, input.AuthorID, input.Content,
+		rangeStart, rangeEnd, quote,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("create document comment: %w", err)
+	}
+	return c, nil
+}
+
+func (r *pgDocumentCommentRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.DocumentComment, error) {
+	c, err := scanDocumentComment(r.db.QueryRow(ctx,
+		`SELECT `+documentCommentColumns+` FROM document_comments WHERE id = $1`, id,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NotFound("comment", id.String())
+		}
+		return nil, fmt.Errorf("find document comment: %w", err)
+	}
+	return c, nil
+}
+
+func (r *pgDocumentCommentRepository) ListByDocument(ctx context.Context, docID uuid.UUID, blockID *uuid.UUID) ([]*model.DocumentComment, error) {
+	return r.list(ctx,
+		`SELECT `+documentCommentColumns+`
+		 FROM document_comments
+		 WHERE document_id = $1 AND ($2::uuid IS NULL OR block_id = $2)
+		 ORDER BY created_at, id`, docID, blockID,
+	)
+}
+
+func (r *pgDocumentCommentRepository) ListReplies(ctx context.Context, parentID uuid.UUID) ([]*model.DocumentComment, error) {
+	return r.list(ctx,
+		`SELECT `+documentCommentColumns+`
+		 FROM document_comments WHERE parent_id = $1
+		 ORDER BY created_at, id`, parentID,
+	)
+}
+
+func (r *pgDocumentCommentRepository) SetResolved(ctx context.Context, id uuid.UUID, resolvedBy *uuid.UUID) (*model.DocumentComment, error) {
+	c, err := scanDocumentComment(r.db.QueryRow(ctx,
+		`UPDATE document_comments SET
+		   resolved_by = $2,
+		   resolved_at = CASE WHEN $2::uuid IS NULL THEN NULL ELSE NOW() END,
+		   updated_at = NOW()
+		 WHERE id = $1 AND parent_id IS NULL
+		 RETURNING `+documentCommentColumns, id, resolvedBy,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NotFound("comment", id.String())
+		}
+		return nil, fmt.Errorf("set comment resolved: %w", err)
+	}
+	return c, nil
+}
+
+func (r *pgDocumentCommentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM document_comments WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete document comment: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return apperror.NotFound("comment", id.String())
+	}
+	return nil
+}
+
+func (r *pgDocumentCommentRepository) list(ctx context.Context, query string, args ...interface{}) ([]*model.DocumentComment, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list document comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := make([]*model.DocumentComment, 0)
+	for rows.Next() {
+		c, err := scanDocumentComment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan document comment: %w", err)
+		}
+		comments = append(comments, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate document comment rows: %w", err)
+	}
+
+	return comments, nil
+}
+
+func scanDocumentComment(row pgx.Row) (*model.DocumentComment, error) {
+	var c model.DocumentComment
+	var rangeStart, rangeEnd *int
+	var quote string
+	err := row.Scan(
+		&c.ID, &c.DocumentID, &c.BlockID, &c.ParentID, &c.AuthorID, &c.Content,
+		&rangeStart, &rangeEnd, &quote, &c.ResolvedAt, &c.ResolvedBy, &c.CreatedAt, &c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if rangeStart != nil && rangeEnd != nil {
+		c.Range = &model.CommentRange{Start: *rangeStart, End: *rangeEnd, Quote: quote}
+	}
+	return &c, nil
+}
@@ -0,0 +1,95 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/internal/repository"
+	"github.com/otoritech/chatat/internal/testutil"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+func TestDocumentCommentRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testutil.CleanTables(t, testPool)
+	ctx := context.Background()
+
+	user := createTestUser(t, "+62340", "Commenter")
+	docRepo := repository.NewDocumentRepository(testPool)
+	blockRepo := repository.NewBlockRepository(testPool)
+	repo := repository.NewDocumentCommentRepository(testPool)
+
+	doc, err := docRepo.Create(ctx, model.CreateDocumentInput{
+		Title: "Commented", Icon: "C", OwnerID: user.ID, IsStandalone: true,
+	})
+	require.NoError(t, err)
+	block, err := blockRepo.Create(ctx, model.CreateBlockInput{
+		DocumentID: doc.ID, Type: model.BlockTypeParagraph, Content: "Halo dunia",
+	})
+	require.NoError(t, err)
+
+	thread, err := repo.Create(ctx, model.CreateDocumentCommentInput{
+		DocumentID: doc.ID, BlockID: block.ID, AuthorID: user.ID, Content: "Cek",
+		Range: &model.CommentRange{Start: 5, End: 10, Quote: "dunia"},
+	})
+	require.NoError(t, err)
+
+	t.Run("create keeps the range", func(t *testing.T) {
+		require.NotNil(t, thread.Range)
+		assert.Equal(t, model.CommentRange{Start: 5, End: 10, Quote: "dunia"}, *thread.Range)
+		assert.Nil(t, thread.ParentID)
+	})
+
+	reply, err := repo.Create(ctx, model.CreateDocumentCommentInput{
+		DocumentID: doc.ID, BlockID: block.ID, ParentID: &thread.ID, AuthorID: user.ID, Content: "Sudah",
+	})
+	require.NoError(t, err)
+
+	t.Run("list", func(t *testing.T) {
+		all, err := repo.ListByDocument(ctx, doc.ID, nil)
+		require.NoError(t, err)
+		require.Len(t, all, 2)
+		assert.Equal(t, thread.ID, all[0].ID)
+		assert.Nil(t, all[1].Range)
+
+		other := uuid.New()
+		none, err := repo.ListByDocument(ctx, doc.ID, &other)
+		require.NoError(t, err)
+		assert.Empty(t, none)
+
+		replies, err := repo.ListReplies(ctx, thread.ID)
+		require.NoError(t, err)
+		require.Len(t, replies, 1)
+		assert.Equal(t, reply.ID, replies[0].ID)
+	})
+
+	t.Run("resolve and reopen", func(t *testing.T) {
+		resolved, err := repo.SetResolved(ctx, thread.ID, &user.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, resolved.ResolvedAt)
+		assert.Equal(t, &user.ID, resolved.ResolvedBy)
+
+		reopened, err := repo.SetResolved(ctx, thread.ID, nil)
+		require.NoError(t, err)
+		assert.Nil(t, reopened.ResolvedAt)
+		assert.Nil(t, reopened.ResolvedBy)
+
+		_, err = repo.SetResolved(ctx, reply.ID, &user.ID)
+		assert.True(t, apperror.IsNotFound(err))
+	})
+
+	t.Run("delete removes replies", func(t *testing.T) {
+		require.NoError(t, repo.Delete(ctx, thread.ID))
+
+		_, err := repo.FindByID(ctx, reply.ID)
+		assert.True(t, apperror.IsNotFound(err))
+		assert.True(t, apperror.IsNotFound(repo.Delete(ctx, thread.ID)))
+	})
+}
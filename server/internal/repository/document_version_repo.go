@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+// DocumentVersionRepository defines data access operations for document versions.
+type DocumentVersionRepository interface {
+	// Create stores a snapshot as the document's next version number.
+	Create(ctx context.Context, input model.CreateDocumentVersionInput) (*model.DocumentVersion, error)
+	// ListByDocument returns the document's versions, newest first, without their blocks.
+	ListByDocument(ctx context.Context, docID uuid.UUID) ([]*model.DocumentVersion, error)
+	// Latest returns the document's newest version without its blocks.
+	Latest(ctx context.Context, docID uuid.UUID) (*model.DocumentVersion, error)
+	FindByNumber(ctx context.Context, docID uuid.UUID, version int) (*model.DocumentVersion, error)
+	// Restore replaces the document's title, icon, cover and blocks with
+	// those of the version. Blocks keep their IDs.
+	Restore(ctx context.Context, docID uuid.UUID, version int) error
+}
+
+// documentVersionColumns is the column list scanned by documentVersionScanTargets.
+const documentVersionColumns = `id, document_id, version, title, icon, cover, jsonb_array_length(blocks),
+	reason, label, restored_from, created_by, content_hash, created_at`
+
+func documentVersionScanTargets(v *model.DocumentVersion) []interface{} {
+	return []interface{}{
+		&v.ID, &v.DocumentID, &v.Version, &v.Title, &v.Icon, &v.Cover, &v.BlockCount,
+		&v.Reason, &v.Label, &v.RestoredFrom, &v.CreatedBy, &v.ContentHash, &v.CreatedAt,
+	}
+}
+
+type pgDocumentVersionRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewDocumentVersionRepository creates a new PostgreSQL-backed DocumentVersionRepository.
+func NewDocumentVersionRepository(db *pgxpool.Pool) DocumentVersionRepository {
+	return &pgDocumentVersionRepository{db: db}
+}
+
+func (r *pgDocumentVersionRepository) Create(ctx context.Context, input model.CreateDocumentVersionInput) (*model.DocumentVersion, error) {
+	blocks := input.Blocks
+	if blocks == nil {
+		blocks = []*model.Block{}
+	}
+	blocksJSON, err := json.Marshal(blocks)
+	if err != nil {
+		return nil, fmt.Errorf("marshal version blocks: %w", err)
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin create version transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Locking the document serializes version numbering
+	var locked uuid.UUID
+	err = tx.QueryRow(ctx, `SELECT id FROM documents WHERE id = $1 FOR UPDATE`, input.DocumentID).Scan(&locked)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NotFound("document", input.DocumentID.String())
+		}
+		return nil, fmt.Errorf("lock document: %w", err)
+	}
+
+	var v model.DocumentVersion
+	err = tx.QueryRow(ctx,
+		`INSERT INTO document_versions
+		   (document_id, version, title, icon, cover, blocks, content_hash, reason, label, restored_from, created_by)
+		 VALUES ($1, (SELECT COALESCE(MAX(version), 0) + 1 FROM document_versions WHERE document_id = $1),
+		         $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		 RETURNING `+documentVersionColumns,
+		input.DocumentID, input.Title, input.Icon, input.Cover, blocksJSON, input.ContentHash,
+		input.Reason, input.Label, input.RestoredFrom, input.CreatedBy,
+	).Scan(documentVersionScanTargets(&v)...)
+	if err != nil {
+		return nil, fmt.Errorf("create document version: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit create version transaction: %w", err)
+	}
+
+	v.Blocks = blocks
+	return &v, nil
+}
+
+func (r *pgDocumentVersionRepository) ListByDocument(ctx context.Context, docID uuid.UUID) ([]*model.DocumentVersion, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+documentVersionColumns+`
+		 FROM document_versions WHERE document_id = $1
+		 ORDER BY version DESC`, docID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list document versions: %w", err)
+	}
+	defer rows.Close()
+
+	versions := make([]*model.DocumentVersion, 0)
+	for rows.Next() {
+		var v model.DocumentVersion
+		if err := rows.Scan(documentVersionScanTargets(&v)...); err != nil {
+			return nil, fmt.Errorf("scan document version: %w", err)
+		}
+		versions = append(versions, &v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate document version rows: %w", err)
+	}
+
+	return versions, nil
+}
+
+func (r *pgDocumentVersionRepository) Latest(ctx context.Context, docID uuid.UUID) (*model.DocumentVersion, error) {
+	var v model.DocumentVersion
+	err := r.db.QueryRow(ctx,
+		`SELECT `+documentVersionColumns+`
+		 FROM document_versions WHERE document_id = $1
+		 ORDER BY version DESC
+		 LIMIT 1`, docID,
+	).Scan(documentVersionScanTargets(&v)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NotFound("document version", docID.String())
+		}
+		return nil, fmt.Errorf("find latest document version: %w", err)
+	}
+
+	return &v, nil
+}
+
+func (r *pgDocumentVersionRepository) FindByNumber(ctx context.Context, docID uuid.UUID, version int) (*model.DocumentVersion, error) {
+	var v model.DocumentVersion
+	var blocks []byte
+	err := r.db.QueryRow(ctx,
+		`SELECT `+documentVersionColumns+`, blocks
+		 FROM document_versions WHERE document_id = $1 AND version = $2`,
+		docID, version,
+	).Scan(append(documentVersionScanTargets(&v), &blocks)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NotFound("document version", fmt.Sprintf("%s/%d", docID, version))
+		}
+		return nil, fmt.Errorf("find document version: %w", err)
+	}
+
+	if err := json.Unmarshal(blocks, &v.Blocks); err != nil {
+		return nil, fmt.Errorf("decode version blocks: %w", err)
+	}
+
+	return &v, nil
+}
+
+func (r *pgDocumentVersionRepository) Restore(ctx context.Context, docID uuid.UUID, version int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin restore version transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	result, err := tx.Exec(ctx,
+		`UPDATE documents d SET
+		   title = v.title, icon = v.icon, cover = v.cover, updated_at = NOW()
+		 FROM document_versions v
+		 WHERE d.id = $1 AND v.document_id = d.id AND v.version = $2`,
+		docID, version,
+	)
+	if err != nil {
+		return fmt.Errorf("restore document fields: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return apperror.NotFound("document version", fmt.Sprintf("%s/%d", docID, version))
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM blocks WHERE document_id = $1`, docID); err != nil {
+		return fmt.Errorf("clear document blocks: %w", err)
+	}
+
+	// Foreign keys are checked at the end of the statement, so children may
+	// come before their parent block
+	_, err = tx.Exec(ctx,
+		`INSERT INTO blocks (id, document_id, type, content, checked, rows, columns, language, emoji, color, sort_order, parent_block_id, created_at)
+		 SELECT b.id, $1, b.type, COALESCE(b.content, ''), b.checked, b.rows, b.columns,
+		        COALESCE(b.language, ''), COALESCE(b.emoji, ''), COALESCE(b.color, ''),
+		        b."sortOrder", b."parentBlockId", COALESCE(b."createdAt", NOW())
+		 FROM document_versions v,
+		      jsonb_to_recordset(v.blocks) AS b(
+		        id UUID, type TEXT, content TEXT, checked BOOLEAN, rows JSONB, columns JSONB,
+		        language TEXT, emoji TEXT, color TEXT, "sortOrder" INTEGER, "parentBlockId" UUID,
+		        "createdAt" TIMESTAMPTZ)
+		 WHERE v.document_id = $1 AND v.version = $2`,
+		docID, version,
+	)
+	if err != nil {
+		return fmt.Errorf("restore document blocks: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit restore version transaction: %w", err)
+	}
+
+	return nil
+}
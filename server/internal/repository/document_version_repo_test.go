@@ -0,0 +1,116 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/internal/repository"
+	"github.com/otoritech/chatat/internal/testutil"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+func TestDocumentVersionRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testutil.CleanTables(t, testPool)
+	ctx := context.Background()
+
+	user := createTestUser(t, "+62330", "VersionUser")
+	docRepo := repository.NewDocumentRepository(testPool)
+	blockRepo := repository.NewBlockRepository(testPool)
+	repo := repository.NewDocumentVersionRepository(testPool)
+
+	doc, err := docRepo.Create(ctx, model.CreateDocumentInput{
+		Title: "Versioned", Icon: "V", OwnerID: user.ID, IsStandalone: true,
+	})
+	require.NoError(t, err)
+
+	parent, err := blockRepo.Create(ctx, model.CreateBlockInput{
+		DocumentID: doc.ID, Type: model.BlockTypeToggle, Content: "Detail", SortOrder: 0,
+	})
+	require.NoError(t, err)
+	child, err := blockRepo.Create(ctx, model.CreateBlockInput{
+		DocumentID: doc.ID, Type: model.BlockTypeParagraph, Content: "Isi", SortOrder: 1, ParentBlockID: &parent.ID,
+	})
+	require.NoError(t, err)
+	blocks, err := blockRepo.ListByDocument(ctx, doc.ID)
+	require.NoError(t, err)
+
+	t.Run("no versions yet", func(t *testing.T) {
+		_, err := repo.Latest(ctx, doc.ID)
+		assert.True(t, apperror.IsNotFound(err))
+	})
+
+	first, err := repo.Create(ctx, model.CreateDocumentVersionInput{
+		DocumentID: doc.ID, Title: doc.Title, Icon: doc.Icon, Blocks: blocks,
+		ContentHash: "hash-1", Reason: model.VersionReasonManual, Label: "awal", CreatedBy: user.ID,
+	})
+	require.NoError(t, err)
+
+	t.Run("numbers versions per document", func(t *testing.T) {
+		assert.Equal(t, 1, first.Version)
+		assert.Equal(t, 2, first.BlockCount)
+
+		second, err := repo.Create(ctx, model.CreateDocumentVersionInput{
+			DocumentID: doc.ID, Title: "Renamed", Icon: doc.Icon,
+			ContentHash: "hash-2", Reason: model.VersionReasonEdited, CreatedBy: user.ID,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, second.Version)
+		assert.Equal(t, 0, second.BlockCount)
+
+		latest, err := repo.Latest(ctx, doc.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, latest.Version)
+		assert.Equal(t, "hash-2", latest.ContentHash)
+	})
+
+	t.Run("list newest first without blocks", func(t *testing.T) {
+		versions, err := repo.ListByDocument(ctx, doc.ID)
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+		assert.Equal(t, 2, versions[0].Version)
+		assert.Nil(t, versions[1].Blocks)
+		assert.Equal(t, "awal", versions[1].Label)
+	})
+
+	t.Run("find by number includes blocks", func(t *testing.T) {
+		v, err := repo.FindByNumber(ctx, doc.ID, 1)
+		require.NoError(t, err)
+		require.Len(t, v.Blocks, 2)
+		assert.Equal(t, parent.ID, v.Blocks[0].ID)
+
+		_, err = repo.FindByNumber(ctx, doc.ID, 9)
+		assert.True(t, apperror.IsNotFound(err))
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		title := "Changed"
+		_, err := docRepo.Update(ctx, doc.ID, model.UpdateDocumentInput{Title: &title})
+		require.NoError(t, err)
+		require.NoError(t, blockRepo.Delete(ctx, parent.ID))
+
+		require.NoError(t, repo.Restore(ctx, doc.ID, 1))
+
+		restored, err := docRepo.FindByID(ctx, doc.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Versioned", restored.Title)
+
+		restoredBlocks, err := blockRepo.ListByDocument(ctx, doc.ID)
+		require.NoError(t, err)
+		require.Len(t, restoredBlocks, 2)
+		assert.Equal(t, parent.ID, restoredBlocks[0].ID)
+		assert.Equal(t, child.ID, restoredBlocks[1].ID)
+		assert.Equal(t, &parent.ID, restoredBlocks[1].ParentBlockID)
+	})
+
+	t.Run("restore unknown version", func(t *testing.T) {
+		err := repo.Restore(ctx, doc.ID, 9)
+		assert.True(t, apperror.IsNotFound(err))
+	})
+}
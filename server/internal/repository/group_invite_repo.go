@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+const (
+	groupInviteColumns = `id, chat_id, code, created_by, requires_approval, max_uses, use_count, expires_at, revoked_at, created_at`
+	joinRequestColumns = `id, chat_id, user_id, invite_id, status, decided_by, decided_at, created_at`
+)
+
+// GroupInviteRepository defines data access operations for group invite
+// links and the join requests made through them.
+type GroupInviteRepository interface {
+	Create(ctx context.Context, input model.CreateGroupInviteInput) (*model.GroupInvite, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*model.GroupInvite, error)
+	FindByCode(ctx context.Context, code string) (*model.GroupInvite, error)
+	ListByChat(ctx context.Context, chatID uuid.UUID) ([]*model.GroupInvite, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+	// Use counts one use of the invite. It returns false without counting
+	// when the invite is revoked, expired or out of uses.
+	Use(ctx context.Context, id uuid.UUID) (bool, error)
+
+	// CreateJoinRequest counts one use of the invite and records the request
+	// in one transaction. It returns a Conflict error without counting the
+	// use when the user already has a pending request for the group, and a
+	// BadRequest error when the invite can no longer be used.
+	CreateJoinRequest(ctx context.Context, chatID, userID, inviteID uuid.UUID) (*model.JoinRequest, error)
+	FindJoinRequest(ctx context.Context, id uuid.UUID) (*model.JoinRequest, error)
+	ListPendingJoinRequests(ctx context.Context, chatID uuid.UUID) ([]*model.JoinRequest, error)
+	// DecideJoinRequest moves a pending request to status. It returns
+	// NotFound when the request is not pending.
+	DecideJoinRequest(ctx context.Context, id uuid.UUID, status model.JoinRequestStatus, decidedBy uuid.UUID) error
+}
+
+type pgGroupInviteRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewGroupInviteRepository creates a new PostgreSQL-backed GroupInviteRepository.
+func NewGroupInviteRepository(db *pgxpool.Pool) GroupInviteRepository {
+	return &pgGroupInviteRepository{db: db}
+}
+
+func scanGroupInvite(row pgx.Row) (*model.GroupInvite, error) {
+	var i model.GroupInvite
+	err := row.Scan(&i.ID, &i.ChatID, &i.Code, &i.CreatedBy, &i.RequiresApproval,
+		&i.MaxUses, &i.UseCount, &i.ExpiresAt, &i.RevokedAt, &i.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+func scanJoinRequest(row pgx.Row) (*model.JoinRequest, error) {
+	var jr model.JoinRequest
+	err := row.Scan(&jr.ID, &jr.ChatID, &jr.UserID, &jr.InviteID, &jr.Status,
+		&jr.DecidedBy, &jr.DecidedAt, &jr.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &jr, nil
+}
+
+func (r *pgGroupInviteRepository) Create(ctx context.Context, input model.CreateGroupInviteInput) (*model.GroupInvite, error) {
+	invite, err := scanGroupInvite(r.db.QueryRow(ctx,
+		`INSERT INTO group_invites (chat_id, code, created_by, requires_approval, max_uses, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 RETURNING `+groupInviteColumns,
+		input.ChatID, input.Code, input.CreatedBy, input.RequiresApproval, input.MaxUses, input.ExpiresAt,
+	))
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, apperror.Conflict("invite code already exists")
+		}
+		return nil, fmt.Errorf("create group invite: %w", err)
+	}
+	return invite, nil
+}
+
+func (r *pgGroupInviteRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.GroupInvite, error) {
+	invite, err := scanGroupInvite(r.db.QueryRow(ctx,
+		`SELECT `+groupInviteColumns+` FROM group_invites WHERE id = $1`, id,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NotFound("invite", id.String())
+		}
+		return nil, fmt.Errorf("find group invite: %w", err)
+	}
+	return invite, nil
+}
+
+func (r *pgGroupInviteRepository) FindByCode(ctx context.Context, code string) (*model.GroupInvite, error) {
+	invite, err := scanGroupInvite(r.db.QueryRow(ctx,
+		`SELECT `+groupInviteColumns+` FROM group_invites WHERE code = $1`, code,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NotFound("invite", code)
+		}
+		return nil, fmt.Errorf("find group invite by code: %w", err)
+	}
+	return invite, nil
+}
+
+func (r *pgGroupInviteRepository) ListByChat(ctx context.Context, chatID uuid.UUID) ([]*model.GroupInvite, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+groupInviteColumns+` FROM group_invites
+		 WHERE chat_id = $1
+		 ORDER BY created_at DESC`,
+		chatID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list group invites: %w", err)
+	}
+	defer rows.Close()
+
+	invites := make([]*model.GroupInvite, 0)
+	for rows.Next() {
+		invite, err := scanGroupInvite(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan group invite: %w", err)
+		}
+		invites = append(invites, invite)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate group invites: %w", err)
+	}
+
+	return invites, nil
+}
+
+func (r *pgGroupInviteRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.Exec(ctx,
+		`UPDATE group_invites SET revoked_at = COALESCE(revoked_at, NOW()) WHERE id = $1`,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("revoke group invite: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return apperror.NotFound("invite", id.String())
+	}
+	return nil
+}
+
+// useInviteQuery checks the conditions in the UPDATE so concurrent joins
+// cannot push the count past max_uses.
+const useInviteQuery = `UPDATE group_invites SET use_count = use_count + 1
+	 WHERE id = $1
+	   AND revoked_at IS NULL
+	   AND (expires_at IS NULL OR expires_at > NOW())
+	   AND (max_uses IS NULL OR use_count < max_uses)`
+
+func (r *pgGroupInviteRepository) Use(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, useInviteQuery, id)
+	if err != nil {
+		return false, fmt.Errorf("use group invite: %w", err)
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+func (r *pgGroupInviteRepository) CreateJoinRequest(ctx context.Context, chatID, userID, inviteID uuid.UUID) (*model.JoinRequest, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin create join request transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	result, err := tx.Exec(ctx, useInviteQuery, inviteID)
+	if err != nil {
+		return nil, fmt.Errorf("use group invite: %w", err)
+	}
+	if result.RowsAffected() != 1 {
+		return nil, apperror.BadRequest("invite link is no longer valid")
+	}
+
+	jr, err := scanJoinRequest(tx.QueryRow(ctx,
+		`INSERT INTO group_join_requests (chat_id, user_id, invite_id)
+		 VALUES ($1, $2, $3)
+		 RETURNING `+joinRequestColumns,
+		chatID, userID, inviteID,
+	))
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, apperror.Conflict("you already asked to join this group")
+		}
+		return nil, fmt.Errorf("create join request: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit create join request transaction: %w", err)
+	}
+	return jr, nil
+}
+
+func (r *pgGroupInviteRepository) FindJoinRequest(ctx context.Context, id uuid.UUID) (*model.JoinRequest, error) {
+	jr, err := scanJoinRequest(r.db.QueryRow(ctx,
+		`SELECT `+joinRequestColumns+` FROM group_join_requests WHERE id = $1`, id,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NotFound("join request", id.String())
+		}
+		return nil, fmt.Errorf("find join request: %w", err)
+	}
+	return jr, nil
+}
+
+func (r *pgGroupInviteRepository) ListPendingJoinRequests(ctx context.Context, chatID uuid.UUID) ([]*model.JoinRequest, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+joinRequestColumns+` FROM group_join_requests
+		 WHERE chat_id = $1 AND status = 'pending'
+		 ORDER BY created_at ASC`,
+		chatID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list join requests: %w", err)
+	}
+	defer rows.Close()
+
+	requests := make([]*model.JoinRequest, 0)
+	for rows.Next() {
+		jr, err := scanJoinRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan join request: %w", err)
+		}
+		requests = append(requests, jr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate join requests: %w", err)
+	}
+
+	return requests, nil
+}
+
+func (r *pgGroupInviteRepository) DecideJoinRequest(ctx context.Context, id uuid.UUID, status model.JoinRequestStatus, decidedBy uuid.UUID) error {
+	result, err := r.db.Exec(ctx,
+		`UPDATE group_join_requests
+		 SET status = $2, decided_by = $3, decided_at = NOW()
+		 WHERE id = $1 AND status = 'pending'`,
+		id, status, decidedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("decide join request: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return apperror.NotFound("join request", id.String())
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/internal/repository"
+	"github.com/otoritech/chatat/internal/testutil"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+func TestGroupInviteRepository_Invites(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testutil.CleanTables(t, testPool)
+	ctx := context.Background()
+
+	admin := createTestUser(t, "+62640", "Admin")
+	chat, err := repository.NewChatRepository(testPool).Create(ctx, model.CreateChatInput{
+		Type: model.ChatTypeGroup, Name: "Invites", CreatedBy: admin.ID,
+	})
+	require.NoError(t, err)
+
+	repo := repository.NewGroupInviteRepository(testPool)
+	maxUses := 2
+	invite, err := repo.Create(ctx, model.CreateGroupInviteInput{
+		ChatID: chat.ID, Code: "INVITE000001", CreatedBy: admin.ID, MaxUses: &maxUses,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, invite.UseCount)
+
+	_, err = repo.Create(ctx, model.CreateGroupInviteInput{ChatID: chat.ID, Code: "INVITE000001", CreatedBy: admin.ID})
+	assert.True(t, apperror.IsConflict(err))
+
+	found, err := repo.FindByCode(ctx, "INVITE000001")
+	require.NoError(t, err)
+	assert.Equal(t, invite.ID, found.ID)
+
+	// Uses stop at max_uses
+	for _, want := range []bool{true, true, false} {
+		ok, err := repo.Use(ctx, invite.ID)
+		require.NoError(t, err)
+		assert.Equal(t, want, ok)
+	}
+
+	past := time.Now().Add(-time.Hour)
+	expired, err := repo.Create(ctx, model.CreateGroupInviteInput{
+		ChatID: chat.ID, Code: "INVITE000002", CreatedBy: admin.ID, ExpiresAt: &past,
+	})
+	require.NoError(t, err)
+	ok, err := repo.Use(ctx, expired.ID)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	open, err := repo.Create(ctx, model.CreateGroupInviteInput{ChatID: chat.ID, Code: "INVITE000003", CreatedBy: admin.ID})
+	require.NoError(t, err)
+	require.NoError(t, repo.Revoke(ctx, open.ID))
+	ok, err = repo.Use(ctx, open.ID)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	invites, err := repo.ListByChat(ctx, chat.ID)
+	require.NoError(t, err)
+	assert.Len(t, invites, 3)
+}
+
+func TestGroupInviteRepository_JoinRequests(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testutil.CleanTables(t, testPool)
+	ctx := context.Background()
+
+	admin := createTestUser(t, "+62650", "Admin")
+	joiner := createTestUser(t, "+62651", "Joiner")
+	chat, err := repository.NewChatRepository(testPool).Create(ctx, model.CreateChatInput{
+		Type: model.ChatTypeGroup, Name: "Requests", CreatedBy: admin.ID,
+	})
+	require.NoError(t, err)
+
+	repo := repository.NewGroupInviteRepository(testPool)
+	invite, err := repo.Create(ctx, model.CreateGroupInviteInput{
+		ChatID: chat.ID, Code: "REQUEST00001", CreatedBy: admin.ID, RequiresApproval: true,
+	})
+	require.NoError(t, err)
+
+	jr, err := repo.CreateJoinRequest(ctx, chat.ID, joiner.ID, invite.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.JoinRequestPending, jr.Status)
+
+	_, err = repo.CreateJoinRequest(ctx, chat.ID, joiner.ID, invite.ID)
+	assert.True(t, apperror.IsConflict(err))
+
+	// The rejected duplicate does not spend a use
+	used, err := repo.FindByID(ctx, invite.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, used.UseCount)
+
+	pending, err := repo.ListPendingJoinRequests(ctx, chat.ID)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+
+	require.NoError(t, repo.DecideJoinRequest(ctx, jr.ID, model.JoinRequestRejected, admin.ID))
+	err = repo.DecideJoinRequest(ctx, jr.ID, model.JoinRequestApproved, admin.ID)
+	assert.True(t, apperror.IsNotFound(err))
+
+	decided, err := repo.FindJoinRequest(ctx, jr.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.JoinRequestRejected, decided.Status)
+	require.NotNil(t, decided.DecidedBy)
+	assert.Equal(t, admin.ID, *decided.DecidedBy)
+
+	// A new request is allowed once the old one is decided
+	_, err = repo.CreateJoinRequest(ctx, chat.ID, joiner.ID, invite.ID)
+	require.NoError(t, err)
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/otoritech/chatat/internal/model"
+)
+
+// MentionRepository defines data access operations for user mentions.
+type MentionRepository interface {
+	// Sync replaces the mentions of an item with userIDs and returns the users
+	// that were not mentioned by it before.
+	Sync(ctx context.Context, target model.MentionTarget, userIDs []uuid.UUID) ([]uuid.UUID, error)
+	DeleteByItem(ctx context.Context, itemID uuid.UUID) error
+	ListByItems(ctx context.Context, itemIDs []uuid.UUID) (map[uuid.UUID][]uuid.UUID, error)
+	ListByUser(ctx context.Context, userID uuid.UUID, cursor *time.Time, limit int) ([]*model.Mention, error)
+}
+
+type pgMentionRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewMentionRepository creates a new PostgreSQL-backed MentionRepository.
+func NewMentionRepository(db *pgxpool.Pool) MentionRepository {
+	return &pgMentionRepository{db: db}
+}
+
+func (r *pgMentionRepository) Sync(ctx context.Context, target model.MentionTarget, userIDs []uuid.UUID) ([]uuid.UUID, error) {
+	if userIDs == nil {
+		userIDs = []uuid.UUID{}
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin sync mentions: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	_, err = tx.Exec(ctx,
+		`DELETE FROM mentions WHERE item_id = $1 AND NOT (user_id = ANY($2))`,
+		target.ItemID, userIDs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("delete stale mentions: %w", err)
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE mentions SET preview = $2 WHERE item_id = $1`,
+		target.ItemID, target.Preview,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("update mention preview: %w", err)
+	}
+
+	// Only rows that did not exist yet are returned by RETURNING
+	rows, err := tx.Query(ctx,
+		`INSERT INTO mentions (user_id, mentioned_by, source_type, source_id, item_id, preview)
+		 SELECT u, $2, $3, $4, $5, $6 FROM UNNEST($1::uuid[]) AS u
+		 ON CONFLICT (item_id, user_id) DO NOTHING
+		 RETURNING user_id`,
+		userIDs, target.MentionedBy, target.SourceType, target.SourceID, target.ItemID, target.Preview,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("insert mentions: %w", err)
+	}
+
+	added := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan mention row: %w", err)
+		}
+		added = append(added, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate mention rows: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit sync mentions: %w", err)
+	}
+
+	return added, nil
+}
+
+func (r *pgMentionRepository) DeleteByItem(ctx context.Context, itemID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM mentions WHERE item_id = $1`, itemID)
+	if err != nil {
+		return fmt.Errorf("delete mentions: %w", err)
+	}
+	return nil
+}
+
+func (r *pgMentionRepository) ListByItems(ctx context.Context, itemIDs []uuid.UUID) (map[uuid.UUID][]uuid.UUID, error) {
+	mentions := make(map[uuid.UUID][]uuid.UUID)
+	if len(itemIDs) == 0 {
+		return mentions, nil
+	}
+
+	rows, err := r.db.Query(ctx,
+		`SELECT item_id, user_id FROM mentions
+		 WHERE item_id = ANY($1)
+		 ORDER BY created_at ASC`,
+		itemIDs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list mentions by items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var itemID, userID uuid.UUID
+		if err := rows.Scan(&itemID, &userID); err != nil {
+			return nil, fmt.Errorf("scan mention row: %w", err)
+		}
+		mentions[itemID] = append(mentions[itemID], userID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate mention rows: %w", err)
+	}
+
+	return mentions, nil
+}
+
+func (r *pgMentionRepository) ListByUser(ctx context.Context, userID uuid.UUID, cursor *time.Time, limit int) ([]*model.Mention, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	var rows pgx.Rows
+	var err error
+
+	if cursor != nil {
+		rows, err = r.db.Query(ctx,
+			`SELECT id, user_id, mentioned_by, source_type, source_id, item_id, preview, created_at
+			 FROM mentions
+			 WHERE user_id = $1 AND created_at < $2
+			 ORDER BY created_at DESC
+			 LIMIT $3`,
+			userID, *cursor, limit,
+		)
+	} else {
+		rows, err = r.db.Query(ctx,
+			`SELECT id, user_id, mentioned_by, source_type, source_id, item_id, preview, created_at
+			 FROM mentions
+			 WHERE user_id = $1
+			 ORDER BY created_at DESC
+			 LIMIT $2`,
+			userID, limit,
+		)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list mentions: %w", err)
+	}
+	defer rows.Close()
+
+	mentions := make([]*model.Mention, 0)
+	for rows.Next() {
+		var m model.Mention
+		if err := rows.Scan(&m.ID, &m.UserID, &m.MentionedBy, &m.SourceType, &m.SourceID, &m.ItemID, &m.Preview, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan mention row: %w", err)
+		}
+		mentions = append(mentions, &m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate mention rows: %w", err)
+	}
+
+	return mentions, nil
+}
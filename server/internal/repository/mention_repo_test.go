@@ -0,0 +1,63 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/internal/repository"
+	"github.com/otoritech/chatat/internal/testutil"
+)
+
+func TestMentionRepository_SyncAndList(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testutil.CleanTables(t, testPool)
+	ctx := context.Background()
+
+	alice := createTestUser(t, "+62620", "Alice")
+	bob := createTestUser(t, "+62621", "Bob")
+	carol := createTestUser(t, "+62622", "Carol")
+
+	repo := repository.NewMentionRepository(testPool)
+	target := model.MentionTarget{
+		SourceType:  model.MentionSourceChat,
+		SourceID:    uuid.New(),
+		ItemID:      uuid.New(),
+		MentionedBy: alice.ID,
+		Preview:     "@Bob hi",
+	}
+
+	added, err := repo.Sync(ctx, target, []uuid.UUID{bob.ID})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{bob.ID}, added)
+
+	// Bob stays mentioned, so only Carol is new
+	target.Preview = "@Bob @Carol hi"
+	added, err = repo.Sync(ctx, target, []uuid.UUID{bob.ID, carol.ID})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{carol.ID}, added)
+
+	feed, err := repo.ListByUser(ctx, bob.ID, nil, 10)
+	require.NoError(t, err)
+	require.Len(t, feed, 1)
+	assert.Equal(t, "@Bob @Carol hi", feed[0].Preview)
+	assert.Equal(t, model.MentionSourceChat, feed[0].SourceType)
+
+	// Dropping Bob removes the mention from his feed
+	_, err = repo.Sync(ctx, target, []uuid.UUID{carol.ID})
+	require.NoError(t, err)
+	byItem, err := repo.ListByItems(ctx, []uuid.UUID{target.ItemID})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{carol.ID}, byItem[target.ItemID])
+
+	require.NoError(t, repo.DeleteByItem(ctx, target.ItemID))
+	feed, err = repo.ListByUser(ctx, carol.ID, nil, 10)
+	require.NoError(t, err)
+	assert.Empty(t, feed)
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -21,6 +22,24 @@ type MessageRepository interface {
 	ListByChat(ctx context.Context, chatID uuid.UUID, cursor *time.Time, limit int) ([]*model.Message, error)
 	MarkAsDeleted(ctx context.Context, id uuid.UUID, forAll bool) error
 	Search(ctx context.Context, chatID uuid.UUID, query string) ([]*model.Message, error)
+	Edit(ctx context.Context, id uuid.UUID, content string) (*model.Message, error)
+	ListEdits(ctx context.Context, messageID uuid.UUID) ([]*model.MessageEdit, error)
+	// UpdateMetadata replaces a message's metadata without recording an edit.
+	UpdateMetadata(ctx context.Context, id uuid.UUID, metadata json.RawMessage) (*model.Message, error)
+	ListThread(ctx context.Context, rootID uuid.UUID, cursor *time.Time, limit int) ([]*model.Message, error)
+	ThreadSummaries(ctx context.Context, rootIDs []uuid.UUID) (map[uuid.UUID]*model.ThreadSummary, error)
+	DeleteExpired(ctx context.Context, limit int) (*model.ExpiredMessages, error)
+}
+
+// messageColumns is the column list scanned by messageScanTargets.
+const messageColumns = `id, chat_id, sender_id, content, reply_to_id, thread_id, type, metadata, is_deleted, deleted_for_all, created_at, edited_at, expires_at`
+
+func messageScanTargets(msg *model.Message) []interface{} {
+	return []interface{}{
+		&msg.ID, &msg.ChatID, &msg.SenderID, &msg.Content, &msg.ReplyToID, &msg.ThreadID,
+		&msg.Type, &msg.Metadata, &msg.IsDeleted, &msg.DeletedForAll, &msg.CreatedAt, &msg.EditedAt,
+		&msg.ExpiresAt,
+	}
 }
 
 type pgMessageRepository struct {
@@ -38,16 +57,15 @@ func (r *pgMessageRepository) Create(ctx context.Context, input model.CreateMess
 		msgType = model.MessageTypeText
 	}
 
+	// The chat's disappearing timer at send time decides when the message expires
 	var msg model.Message
 	err := r.db.QueryRow(ctx,
-		`INSERT INTO messages (chat_id, sender_id, content, reply_to_id, type, metadata)
-		 VALUES ($1, $2, $3, $4, $5, $6)
-		 RETURNING id, chat_id, sender_id, content, reply_to_id, type, metadata, is_deleted, deleted_for_all, created_at`,
-		input.ChatID, input.SenderID, input.Content, input.ReplyToID, msgType, input.Metadata,
-	).Scan(
-		&msg.ID, &msg.ChatID, &msg.SenderID, &msg.Content, &msg.ReplyToID,
-		&msg.Type, &msg.Metadata, &msg.IsDeleted, &msg.DeletedForAll, &msg.CreatedAt,
-	)
+		`INSERT INTO messages (chat_id, sender_id, content, reply_to_id, thread_id, type, metadata, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7,
+		   (SELECT NOW() + make_interval(secs => disappearing_timer) FROM chats WHERE id = $1 AND disappearing_timer > 0))
+		 RETURNING `+messageColumns,
+		input.ChatID, input.SenderID, input.Content, input.ReplyToID, input.ThreadID, msgType, input.Metadata,
+	).Scan(messageScanTargets(&msg)...)
 	if err != nil {
 		return nil, fmt.Errorf("create message: %w", err)
 	}
@@ -58,12 +76,9 @@ func (r *pgMessageRepository) Create(ctx context.Context, input model.CreateMess
 func (r *pgMessageRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Message, error) {
 	var msg model.Message
 	err := r.db.QueryRow(ctx,
-		`SELECT id, chat_id, sender_id, content, reply_to_id, type, metadata, is_deleted, deleted_for_all, created_at
+		`SELECT `+messageColumns+`
 		 FROM messages WHERE id = $1`, id,
-	).Scan(
-		&msg.ID, &msg.ChatID, &msg.SenderID, &msg.Content, &msg.ReplyToID,
-		&msg.Type, &msg.Metadata, &msg.IsDeleted, &msg.DeletedForAll, &msg.CreatedAt,
-	)
+	).Scan(messageScanTargets(&msg)...)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, apperror.NotFound("message", id.String())
@@ -84,18 +99,18 @@ func (r *pgMessageRepository) ListByChat(ctx context.Context, chatID uuid.UUID,
 
 	if cursor != nil {
 		rows, err = r.db.Query(ctx,
-			`SELECT id, chat_id, sender_id, content, reply_to_id, type, metadata, is_deleted, deleted_for_all, created_at
+			`SELECT `+messageColumns+`
 			 FROM messages
-			 WHERE chat_id = $1 AND created_at < $2
+			 WHERE chat_id = $1 AND created_at < $2 AND (expires_at IS NULL OR expires_at > NOW())
 			 ORDER BY created_at DESC
 			 LIMIT $3`,
 			chatID, *cursor, limit,
 		)
 	} else {
 		rows, err = r.db.Query(ctx,
-			`SELECT id, chat_id, sender_id, content, reply_to_id, type, metadata, is_deleted, deleted_for_all, created_at
+			`SELECT `+messageColumns+`
 			 FROM messages
-			 WHERE chat_id = $1
+			 WHERE chat_id = $1 AND (expires_at IS NULL OR expires_at > NOW())
 			 ORDER BY created_at DESC
 			 LIMIT $2`,
 			chatID, limit,
@@ -109,10 +124,7 @@ func (r *pgMessageRepository) ListByChat(ctx context.Context, chatID uuid.UUID,
 	var messages []*model.Message
 	for rows.Next() {
 		var msg model.Message
-		if err := rows.Scan(
-			&msg.ID, &msg.ChatID, &msg.SenderID, &msg.Content, &msg.ReplyToID,
-			&msg.Type, &msg.Metadata, &msg.IsDeleted, &msg.DeletedForAll, &msg.CreatedAt,
-		); err != nil {
+		if err := rows.Scan(messageScanTargets(&msg)...); err != nil {
 			return nil, fmt.Errorf("scan message row: %w", err)
 		}
 		messages = append(messages, &msg)
@@ -143,9 +155,10 @@ func (r *pgMessageRepository) MarkAsDeleted(ctx context.Context, id uuid.UUID, f
 
 func (r *pgMessageRepository) Search(ctx context.Context, chatID uuid.UUID, query string) ([]*model.Message, error) {
 	rows, err := r.db.Query(ctx,
-		`SELECT id, chat_id, sender_id, content, reply_to_id, type, metadata, is_deleted, deleted_for_all, created_at
+		`SELECT `+messageColumns+`
 		 FROM messages
 		 WHERE chat_id = $1 AND content ILIKE '%' || $2 || '%' AND is_deleted = false
+		   AND (expires_at IS NULL OR expires_at > NOW())
 		 ORDER BY created_at DESC
 		 LIMIT 100`,
 		chatID, query,
@@ -158,10 +171,7 @@ func (r *pgMessageRepository) Search(ctx context.Context, chatID uuid.UUID, quer
 	var messages []*model.Message
 	for rows.Next() {
 		var msg model.Message
-		if err := rows.Scan(
-			&msg.ID, &msg.ChatID, &msg.SenderID, &msg.Content, &msg.ReplyToID,
-			&msg.Type, &msg.Metadata, &msg.IsDeleted, &msg.DeletedForAll, &msg.CreatedAt,
-		); err != nil {
+		if err := rows.Scan(messageScanTargets(&msg)...); err != nil {
 			return nil, fmt.Errorf("scan search message row: %w", err)
 		}
 		messages = append(messages, &msg)
@@ -173,3 +183,286 @@ func (r *pgMessageRepository) Search(ctx context.Context, chatID uuid.UUID, quer
 
 	return messages, nil
 }
+
+// Edit replaces a message's content, keeping the previous version in the edit history.
+// The messages_search_trigger re-indexes the new content for full-text search.
+func (r *pgMessageRepository) Edit(ctx context.Context, id uuid.UUID, content string) (*model.Message, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin edit message transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO message_edits (message_id, content)
+		 SELECT id, content FROM messages WHERE id = $1`,
+		id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("save message edit: %w", err)
+	}
+
+	var msg model.Message
+	err = tx.QueryRow(ctx,
+		`UPDATE messages SET content = $2, edited_at = NOW()
+		 WHERE id = $1
+		 RETURNING `+messageColumns,
+		id, content,
+	).Scan(messageScanTargets(&msg)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NotFound("message", id.String())
+		}
+		return nil, fmt.Errorf("edit message: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit edit message transaction: %w", err)
+	}
+
+	return &msg, nil
+}
+
+func (r *pgMessageRepository) UpdateMetadata(ctx context.Context, id uuid.UUID, metadata json.RawMessage) (*model.Message, error) {
+	var msg model.Message
+	err := r.db.QueryRow(ctx,
+		`UPDATE messages SET metadata = $2
+		 WHERE id = $1
+		 RETURNING `+messageColumns,
+		id, metadata,
+	).Scan(messageScanTargets(&msg)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NotFound("message", id.String())
+		}
+		return nil, fmt.Errorf("update message metadata: %w", err)
+	}
+
+	return &msg, nil
+}
+
+func (r *pgMessageRepository) ListEdits(ctx context.Context, messageID uuid.UUID) ([]*model.MessageEdit, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT id, message_id, content, edited_at
+		 FROM message_edits WHERE message_id = $1
+		 ORDER BY edited_at ASC`,
+		messageID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list message edits: %w", err)
+	}
+	defer rows.Close()
+
+	edits := make([]*model.MessageEdit, 0)
+	for rows.Next() {
+		var e model.MessageEdit
+		if err := rows.Scan(&e.ID, &e.MessageID, &e.Content, &e.EditedAt); err != nil {
+			return nil, fmt.Errorf("scan message edit row: %w", err)
+		}
+		edits = append(edits, &e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate message edit rows: %w", err)
+	}
+
+	return edits, nil
+}
+
+// ListThread returns the replies in a thread, oldest first, after the cursor.
+func (r *pgMessageRepository) ListThread(ctx context.Context, rootID uuid.UUID, cursor *time.Time, limit int) ([]*model.Message, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	var rows pgx.Rows
+	var err error
+
+	if cursor != nil {
+		rows, err = r.db.Query(ctx,
+			`SELECT `+messageColumns+`
+			 FROM messages
+			 WHERE thread_id = $1 AND created_at > $2 AND (expires_at IS NULL OR expires_at > NOW())
+			 ORDER BY created_at ASC
+			 LIMIT $3`,
+			rootID, *cursor, limit,
+		)
+	} else {
+		rows, err = r.db.Query(ctx,
+			`SELECT `+messageColumns+`
+			 FROM messages
+			 WHERE thread_id = $1 AND (expires_at IS NULL OR expires_at > NOW())
+			 ORDER BY created_at ASC
+			 LIMIT $2`,
+			rootID, limit,
+		)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list thread messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := make([]*model.Message, 0)
+	for rows.Next() {
+		var msg model.Message
+		if err := rows.Scan(messageScanTargets(&msg)...); err != nil {
+			return nil, fmt.Errorf("scan thread message row: %w", err)
+		}
+		messages = append(messages, &msg)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate thread message rows: %w", err)
+	}
+
+	return messages, nil
+}
+
+// ThreadSummaries counts the live replies of each root message. Roots without
+// replies are absent from the result.
+func (r *pgMessageRepository) ThreadSummaries(ctx context.Context, rootIDs []uuid.UUID) (map[uuid.UUID]*model.ThreadSummary, error) {
+	summaries := make(map[uuid.UUID]*model.ThreadSummary)
+	if len(rootIDs) == 0 {
+		return summaries, nil
+	}
+
+	rows, err := r.db.Query(ctx,
+		`SELECT thread_id, COUNT(*), MAX(created_at)
+		 FROM messages
+		 WHERE thread_id = ANY($1) AND NOT (is_deleted AND deleted_for_all)
+		   AND (expires_at IS NULL OR expires_at > NOW())
+		 GROUP BY thread_id`,
+		rootIDs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("summarize threads: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var summary model.ThreadSummary
+		if err := rows.Scan(&summary.RootID, &summary.ReplyCount, &summary.LastReplyAt); err != nil {
+			return nil, fmt.Errorf("scan thread summary row: %w", err)
+		}
+		summaries[summary.RootID] = &summary
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate thread summary rows: %w", err)
+	}
+
+	return summaries, nil
+}
+
+// DeleteExpired hard-deletes up to limit messages whose disappearing timer has
+// run out. Status rows, reactions and edits go with them through ON DELETE
+// CASCADE; mentions and media rows only those messages referenced are deleted
+// here. Rows locked by another replica are skipped.
+func (r *pgMessageRepository) DeleteExpired(ctx context.Context, limit int) (*model.ExpiredMessages, error) {
+	if limit <= 0 {
+		limit = 500
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin delete expired transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	rows, err := tx.Query(ctx,
+		`DELETE FROM messages
+		 WHERE id IN (
+		   SELECT id FROM messages
+		   WHERE expires_at <= NOW()
+		   ORDER BY expires_at ASC
+		   LIMIT $1
+		   FOR UPDATE SKIP LOCKED
+		 )
+		 RETURNING id, chat_id, CASE WHEN type IN ('image', 'file') THEN metadata->>'id' END`,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("delete expired messages: %w", err)
+	}
+
+	expired := &model.ExpiredMessages{ByChat: make(map[uuid.UUID][]uuid.UUID)}
+	var messageIDs []uuid.UUID
+	var mediaIDs []string
+	for rows.Next() {
+		var id, chatID uuid.UUID
+		var mediaID *string
+		if err := rows.Scan(&id, &chatID, &mediaID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan expired message row: %w", err)
+		}
+		expired.ByChat[chatID] = append(expired.ByChat[chatID], id)
+		messageIDs = append(messageIDs, id)
+		if mediaID != nil {
+			mediaIDs = append(mediaIDs, *mediaID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate expired message rows: %w", err)
+	}
+
+	if len(messageIDs) == 0 {
+		return expired, nil
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM mentions WHERE item_id = ANY($1)`, messageIDs); err != nil {
+		return nil, fmt.Errorf("delete expired mentions: %w", err)
+	}
+
+	// Forwarded copies, topic messages, scheduled messages and broadcasts can
+	// share the same media, so keep anything still in use
+	if len(mediaIDs) > 0 {
+		mediaRows, err := tx.Query(ctx,
+			`DELETE FROM media md
+			 WHERE md.id::text = ANY($1)
+			   AND NOT EXISTS (
+			     SELECT 1 FROM messages m
+			     WHERE m.type IN ('image', 'file') AND m.metadata->>'id' = md.id::text
+			   )
+			   AND NOT EXISTS (
+			     SELECT 1 FROM topic_messages tm
+			     WHERE tm.type IN ('image', 'file') AND tm.metadata->>'id' = md.id::text
+			   )
+			   AND NOT EXISTS (
+			     SELECT 1 FROM scheduled_messages sm
+			     WHERE sm.type IN ('image', 'file') AND sm.metadata->>'id' = md.id::text
+			   )
+			   AND NOT EXISTS (
+			     SELECT 1 FROM broadcasts b
+			     WHERE b.type IN ('image', 'file') AND b.metadata->>'id' = md.id::text
+			   )
+			 RETURNING id, uploader_id, type, filename, content_type, size, width, height, storage_key, thumbnail_key, context_type, context_id, created_at`,
+			mediaIDs,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("delete expired media: %w", err)
+		}
+		for mediaRows.Next() {
+			var m model.Media
+			if err := mediaRows.Scan(
+				&m.ID, &m.UploaderID, &m.Type, &m.Filename, &m.ContentType,
+				&m.Size, &m.Width, &m.Height, &m.StorageKey, &m.ThumbnailKey,
+				&m.ContextType, &m.ContextID, &m.CreatedAt,
+			); err != nil {
+				mediaRows.Close()
+				return nil, fmt.Errorf("scan expired media row: %w", err)
+			}
+			expired.Media = append(expired.Media, &m)
+		}
+		mediaRows.Close()
+		if err := mediaRows.Err(); err != nil {
+			return nil, fmt.Errorf("iterate expired media rows: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit delete expired transaction: %w", err)
+	}
+
+	return expired, nil
+}
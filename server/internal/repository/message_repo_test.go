@@ -157,3 +157,199 @@ func TestMessageRepository_FindByID_NotFound(t *testing.T) {
 	assert.Error(t, err)
 	assert.True(t, apperror.IsNotFound(err))
 }
+
+func TestMessageRepository_Edit(t *testing.T) {
+	_, msgRepo, user, chat := setupMessageTest(t)
+	ctx := context.Background()
+
+	msg, err := msgRepo.Create(ctx, model.CreateMessageInput{
+		ChatID:   chat.ID,
+		SenderID: user.ID,
+		Content:  "first draft",
+		Type:     model.MessageTypeText,
+	})
+	require.NoError(t, err)
+	assert.Nil(t, msg.EditedAt)
+
+	edited, err := msgRepo.Edit(ctx, msg.ID, "second draft")
+	require.NoError(t, err)
+	assert.Equal(t, "second draft", edited.Content)
+	require.NotNil(t, edited.EditedAt)
+
+	edits, err := msgRepo.ListEdits(ctx, msg.ID)
+	require.NoError(t, err)
+	require.Len(t, edits, 1)
+	assert.Equal(t, "first draft", edits[0].Content)
+
+	_, err = msgRepo.Edit(ctx, uuid.New(), "nothing")
+	assert.True(t, apperror.IsNotFound(err))
+}
+
+func TestMessageRepository_UpdateMetadata(t *testing.T) {
+	_, msgRepo, user, chat := setupMessageTest(t)
+	ctx := context.Background()
+
+	msg, err := msgRepo.Create(ctx, model.CreateMessageInput{
+		ChatID:   chat.ID,
+		SenderID: user.ID,
+		Content:  "Gudang",
+		Type:     model.MessageTypeLocation,
+		Metadata: []byte(`{"latitude":-6.2,"longitude":106.8,"label":"Gudang"}`),
+	})
+	require.NoError(t, err)
+
+	updated, err := msgRepo.UpdateMetadata(ctx, msg.ID, []byte(`{"latitude":-6.3,"longitude":106.9,"label":"Gudang"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"latitude":-6.3,"longitude":106.9,"label":"Gudang"}`, string(updated.Metadata))
+	assert.Nil(t, updated.EditedAt, "metadata updates are not edits")
+
+	edits, err := msgRepo.ListEdits(ctx, msg.ID)
+	require.NoError(t, err)
+	assert.Empty(t, edits)
+
+	_, err = msgRepo.UpdateMetadata(ctx, uuid.New(), []byte(`{}`))
+	assert.True(t, apperror.IsNotFound(err))
+}
+
+func TestMessageRepository_Threads(t *testing.T) {
+	_, msgRepo, user, chat := setupMessageTest(t)
+	ctx := context.Background()
+
+	root, err := msgRepo.Create(ctx, model.CreateMessageInput{
+		ChatID: chat.ID, SenderID: user.ID, Content: "root", Type: model.MessageTypeText,
+	})
+	require.NoError(t, err)
+
+	var replies []*model.Message
+	for i := 0; i < 3; i++ {
+		reply, err := msgRepo.Create(ctx, model.CreateMessageInput{
+			ChatID: chat.ID, SenderID: user.ID, Content: "reply", ReplyToID: &root.ID, ThreadID: &root.ID, Type: model.MessageTypeText,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, reply.ThreadID)
+		replies = append(replies, reply)
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	page, err := msgRepo.ListThread(ctx, root.ID, nil, 2)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.Equal(t, replies[0].ID, page[0].ID)
+
+	next, err := msgRepo.ListThread(ctx, root.ID, &page[1].CreatedAt, 2)
+	require.NoError(t, err)
+	require.Len(t, next, 1)
+	assert.Equal(t, replies[2].ID, next[0].ID)
+
+	require.NoError(t, msgRepo.MarkAsDeleted(ctx, replies[2].ID, true))
+	summaries, err := msgRepo.ThreadSummaries(ctx, []uuid.UUID{root.ID, replies[0].ID})
+	require.NoError(t, err)
+	require.Contains(t, summaries, root.ID)
+	assert.Equal(t, 2, summaries[root.ID].ReplyCount)
+	assert.NotContains(t, summaries, replies[0].ID)
+}
+
+func TestMessageRepository_DeleteExpired(t *testing.T) {
+	chatRepo, msgRepo, user, chat := setupMessageTest(t)
+	ctx := context.Background()
+	mediaRepo := repository.NewMediaRepository(testPool)
+
+	kept, err := msgRepo.Create(ctx, model.CreateMessageInput{
+		ChatID: chat.ID, SenderID: user.ID, Content: "before timer", Type: model.MessageTypeText,
+	})
+	require.NoError(t, err)
+	assert.Nil(t, kept.ExpiresAt)
+
+	updated, err := chatRepo.SetDisappearingTimer(ctx, chat.ID, 86400)
+	require.NoError(t, err)
+	assert.Equal(t, 86400, updated.DisappearingTimer)
+
+	media := &model.Media{
+		ID: uuid.New(), UploaderID: user.ID, Type: model.MediaTypeImage, Filename: "a.jpg",
+		ContentType: "image/jpeg", Size: 10, StorageKey: "media/a.jpg", CreatedAt: time.Now(),
+	}
+	require.NoError(t, mediaRepo.Create(ctx, media))
+
+	photo, err := msgRepo.Create(ctx, model.CreateMessageInput{
+		ChatID: chat.ID, SenderID: user.ID, Content: "", Type: model.MessageTypeImage,
+		Metadata: []byte(`{"id":"` + media.ID.String() + `"}`),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, photo.ExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), *photo.ExpiresAt, time.Minute)
+
+	// A scheduled copy of the same photo keeps it alive
+	shared := &model.Media{
+		ID: uuid.New(), UploaderID: user.ID, Type: model.MediaTypeImage, Filename: "b.jpg",
+		ContentType: "image/jpeg", Size: 10, StorageKey: "media/b.jpg", CreatedAt: time.Now(),
+	}
+	require.NoError(t, mediaRepo.Create(ctx, shared))
+	sharedMetadata := []byte(`{"id":"` + shared.ID.String() + `"}`)
+	sharedPhoto, err := msgRepo.Create(ctx, model.CreateMessageInput{
+		ChatID: chat.ID, SenderID: user.ID, Content: "", Type: model.MessageTypeImage, Metadata: sharedMetadata,
+	})
+	require.NoError(t, err)
+	_, err = repository.NewScheduledMessageRepository(testPool).Create(ctx, model.CreateScheduledMessageInput{
+		ChatID: chat.ID, SenderID: user.ID, Type: model.MessageTypeImage, Metadata: sharedMetadata,
+		SendAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	// Nothing has expired yet
+	expired, err := msgRepo.DeleteExpired(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 0, expired.Count())
+
+	_, err = testPool.Exec(ctx, `UPDATE messages SET expires_at = NOW() - INTERVAL '1 minute' WHERE id = ANY($1)`, []uuid.UUID{photo.ID, sharedPhoto.ID})
+	require.NoError(t, err)
+
+	page, err := msgRepo.ListByChat(ctx, chat.ID, nil, 10)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, kept.ID, page[0].ID)
+	found, err := msgRepo.Search(ctx, chat.ID, "")
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, kept.ID, found[0].ID)
+
+	expired, err = msgRepo.DeleteExpired(ctx, 10)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{photo.ID, sharedPhoto.ID}, expired.ByChat[chat.ID])
+	require.Len(t, expired.Media, 1)
+	assert.Equal(t, "media/a.jpg", expired.Media[0].StorageKey)
+	_, err = mediaRepo.FindByID(ctx, shared.ID)
+	assert.NoError(t, err)
+
+	_, err = msgRepo.FindByID(ctx, photo.ID)
+	assert.True(t, apperror.IsNotFound(err))
+	_, err = mediaRepo.FindByID(ctx, media.ID)
+	assert.Error(t, err)
+	_, err = msgRepo.FindByID(ctx, kept.ID)
+	assert.NoError(t, err)
+}
+
+func TestThreadRepository_Followers(t *testing.T) {
+	_, msgRepo, user, chat := setupMessageTest(t)
+	ctx := context.Background()
+	threadRepo := repository.NewThreadRepository(testPool)
+
+	root, err := msgRepo.Create(ctx, model.CreateMessageInput{
+		ChatID: chat.ID, SenderID: user.ID, Content: "root", Type: model.MessageTypeText,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, threadRepo.Follow(ctx, root.ID, user.ID))
+	require.NoError(t, threadRepo.Follow(ctx, root.ID, user.ID))
+
+	following, err := threadRepo.IsFollowing(ctx, root.ID, user.ID)
+	require.NoError(t, err)
+	assert.True(t, following)
+
+	followers, err := threadRepo.ListFollowers(ctx, root.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{user.ID}, followers)
+
+	require.NoError(t, threadRepo.Unfollow(ctx, root.ID, user.ID))
+	err = threadRepo.Unfollow(ctx, root.ID, user.ID)
+	assert.True(t, apperror.IsNotFound(err))
+}
@@ -2,12 +2,16 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/pkg/apperror"
 )
 
 // MessageStatusRepository defines operations for managing message delivery/read status.
@@ -15,7 +19,10 @@ type MessageStatusRepository interface {
 	Create(ctx context.Context, messageID, userID uuid.UUID, status model.DeliveryStatus) error
 	UpdateStatus(ctx context.Context, messageID, userID uuid.UUID, status model.DeliveryStatus) error
 	GetStatus(ctx context.Context, messageID uuid.UUID) ([]*model.MessageStatus, error)
-	MarkChatAsRead(ctx context.Context, chatID, userID uuid.UUID) error
+	// MarkChatAsRead moves the user's read cursor forward to lastReadID, or to
+	// the newest message when it is nil, and marks everything up to it read.
+	MarkChatAsRead(ctx context.Context, chatID, userID uuid.UUID, lastReadID *uuid.UUID) error
+	// GetUnreadCount counts other members' messages after the user's read cursor.
 	GetUnreadCount(ctx context.Context, chatID, userID uuid.UUID) (int, error)
 }
 
@@ -41,11 +48,17 @@ func (r *pgMessageStatusRepository) Create(ctx context.Context, messageID, userI
 	return nil
 }
 
+// UpdateStatus only moves a status forward: sent, then delivered, then read.
 func (r *pgMessageStatusRepository) UpdateStatus(ctx context.Context, messageID, userID uuid.UUID, status model.DeliveryStatus) error {
 	_, err := r.db.Exec(ctx,
-		`UPDATE message_status SET status = $3, updated_at = NOW()
-		 WHERE message_id = $1 AND user_id = $2`,
-		messageID, userID, status,
+		`UPDATE message_status SET
+		   status = $3,
+		   delivered_at = CASE WHEN $3::text != 'sent' THEN COALESCE(delivered_at, NOW()) ELSE delivered_at END,
+		   read_at = CASE WHEN $3::text = 'read' THEN NOW() ELSE read_at END,
+		   updated_at = NOW()
+		 WHERE message_id = $1 AND user_id = $2
+		   AND status != 'read' AND status != $3::text`,
+		messageID, userID, string(status),
 	)
 	if err != nil {
 		return fmt.Errorf("update message status: %w", err)
@@ -56,8 +69,9 @@ func (r *pgMessageStatusRepository) UpdateStatus(ctx context.Context, messageID,
 
 func (r *pgMessageStatusRepository) GetStatus(ctx context.Context, messageID uuid.UUID) ([]*model.MessageStatus, error) {
 	rows, err := r.db.Query(ctx,
-		`SELECT message_id, user_id, status, updated_at
-		 FROM message_status WHERE message_id = $1`, messageID,
+		`SELECT message_id, user_id, status, delivered_at, read_at, updated_at
+		 FROM message_status WHERE message_id = $1
+		 ORDER BY updated_at DESC`, messageID,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("get message status: %w", err)
@@ -67,7 +81,7 @@ func (r *pgMessageStatusRepository) GetStatus(ctx context.Context, messageID uui
 	var statuses []*model.MessageStatus
 	for rows.Next() {
 		var s model.MessageStatus
-		if err := rows.Scan(&s.MessageID, &s.UserID, &s.Status, &s.UpdatedAt); err != nil {
+		if err := rows.Scan(&s.MessageID, &s.UserID, &s.Status, &s.DeliveredAt, &s.ReadAt, &s.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("scan message status: %w", err)
 		}
 		statuses = append(statuses, &s)
@@ -80,17 +94,65 @@ func (r *pgMessageStatusRepository) GetStatus(ctx context.Context, messageID uui
 	return statuses, nil
 }
 
-func (r *pgMessageStatusRepository) MarkChatAsRead(ctx context.Context, chatID, userID uuid.UUID) error {
-	_, err := r.db.Exec(ctx,
-		`UPDATE message_status ms SET status = 'read', updated_at = NOW()
-		 FROM messages m
-		 WHERE ms.message_id = m.id AND m.chat_id = $1 AND ms.user_id = $2 AND ms.status != 'read'`,
-		chatID, userID,
-	)
+func (r *pgMessageStatusRepository) MarkChatAsRead(ctx context.Context, chatID, userID uuid.UUID, lastReadID *uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
+		return fmt.Errorf("begin mark read transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var readID uuid.UUID
+	var readAt time.Time
+	if lastReadID != nil {
+		err = tx.QueryRow(ctx,
+			`SELECT id, created_at FROM messages WHERE id = $1 AND chat_id = $2`,
+			*lastReadID, chatID,
+		).Scan(&readID, &readAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return apperror.NotFound("message", lastReadID.String())
+		}
+	} else {
+		err = tx.QueryRow(ctx,
+			`SELECT id, created_at FROM messages WHERE chat_id = $1
+			 ORDER BY created_at DESC LIMIT 1`,
+			chatID,
+		).Scan(&readID, &readAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("find read position: %w", err)
+	}
+
+	// The cursor never moves backwards, e.g. when an older device reports late
+	if _, err := tx.Exec(ctx,
+		`UPDATE chat_members SET last_read_message_id = $3, last_read_at = $4
+		 WHERE chat_id = $1 AND user_id = $2
+		   AND (last_read_at IS NULL OR last_read_at < $4)`,
+		chatID, userID, readID, readAt,
+	); err != nil {
+		return fmt.Errorf("update read cursor: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE message_status ms SET
+		   status = 'read',
+		   delivered_at = COALESCE(ms.delivered_at, NOW()),
+		   read_at = NOW(),
+		   updated_at = NOW()
+		 FROM messages m
+		 WHERE ms.message_id = m.id AND m.chat_id = $1 AND ms.user_id = $2
+		   AND ms.status != 'read' AND m.created_at <= $3`,
+		chatID, userID, readAt,
+	); err != nil {
 		return fmt.Errorf("mark chat as read: %w", err)
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit mark read transaction: %w", err)
+	}
+
 	return nil
 }
 
@@ -99,10 +161,11 @@ func (r *pgMessageStatusRepository) GetUnreadCount(ctx context.Context, chatID,
 	err := r.db.QueryRow(ctx,
 		`SELECT COUNT(*)
 		 FROM messages m
-		 LEFT JOIN message_status ms ON m.id = ms.message_id AND ms.user_id = $2
+		 JOIN chat_members cm ON cm.chat_id = m.chat_id AND cm.user_id = $2
 		 WHERE m.chat_id = $1 AND m.sender_id != $2
-		   AND (ms.status IS NULL OR ms.status != 'read')
-		   AND m.is_deleted = false`,
+		   AND (cm.last_read_at IS NULL OR m.created_at > cm.last_read_at)
+		   AND m.is_deleted = false
+		   AND (m.expires_at IS NULL OR m.expires_at > NOW())`,
 		chatID, userID,
 	).Scan(&count)
 	if err != nil {
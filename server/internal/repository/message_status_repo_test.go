@@ -3,13 +3,16 @@ package repository_test
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/otoritech/chatat/internal/model"
 	"github.com/otoritech/chatat/internal/repository"
 	"github.com/otoritech/chatat/internal/testutil"
+	"github.com/otoritech/chatat/pkg/apperror"
 )
 
 func setupStatusTest(t *testing.T) (repository.MessageStatusRepository, *model.User, *model.Chat, *model.Message) {
@@ -55,6 +58,15 @@ func TestMessageStatus_Transitions(t *testing.T) {
 	require.NoError(t, err)
 	assert.GreaterOrEqual(t, len(statuses), 1)
 	assert.Equal(t, model.DeliveryStatusRead, statuses[0].Status)
+	assert.NotNil(t, statuses[0].DeliveredAt)
+	assert.NotNil(t, statuses[0].ReadAt)
+
+	// A late delivery ack does not undo the read
+	err = repo.UpdateStatus(ctx, msg.ID, user.ID, model.DeliveryStatusDelivered)
+	require.NoError(t, err)
+	statuses, err = repo.GetStatus(ctx, msg.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.DeliveryStatusRead, statuses[0].Status)
 }
 
 func TestMessageStatus_UnreadCount(t *testing.T) {
@@ -76,7 +88,7 @@ func TestMessageStatus_BatchMarkAsRead(t *testing.T) {
 	err := repo.Create(ctx, msg.ID, user.ID, model.DeliveryStatusSent)
 	require.NoError(t, err)
 
-	err = repo.MarkChatAsRead(ctx, chat.ID, user.ID)
+	err = repo.MarkChatAsRead(ctx, chat.ID, user.ID, nil)
 	require.NoError(t, err)
 
 	statuses, err := repo.GetStatus(ctx, msg.ID)
@@ -87,3 +99,51 @@ func TestMessageStatus_BatchMarkAsRead(t *testing.T) {
 		}
 	}
 }
+
+func TestMessageStatus_ReadCursor(t *testing.T) {
+	repo, sender, chat, first := setupStatusTest(t)
+	ctx := context.Background()
+	chatRepo := repository.NewChatRepository(testPool)
+	msgRepo := repository.NewMessageRepository(testPool)
+
+	reader := createTestUser(t, "+62601", "Reader")
+	require.NoError(t, chatRepo.AddMember(ctx, chat.ID, reader.ID, model.MemberRoleMember))
+	require.NoError(t, repo.Create(ctx, first.ID, reader.ID, model.DeliveryStatusSent))
+
+	time.Sleep(5 * time.Millisecond)
+	second, err := msgRepo.Create(ctx, model.CreateMessageInput{
+		ChatID: chat.ID, SenderID: sender.ID, Content: "second", Type: model.MessageTypeText,
+	})
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, second.ID, reader.ID, model.DeliveryStatusSent))
+
+	count, err := repo.GetUnreadCount(ctx, chat.ID, reader.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// Reading up to the first message leaves the second unread
+	require.NoError(t, repo.MarkChatAsRead(ctx, chat.ID, reader.ID, &first.ID))
+	count, err = repo.GetUnreadCount(ctx, chat.ID, reader.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	statuses, err := repo.GetStatus(ctx, second.ID)
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, model.DeliveryStatusSent, statuses[0].Status)
+
+	require.NoError(t, repo.MarkChatAsRead(ctx, chat.ID, reader.ID, nil))
+	count, err = repo.GetUnreadCount(ctx, chat.ID, reader.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	// An older position never moves the cursor back
+	require.NoError(t, repo.MarkChatAsRead(ctx, chat.ID, reader.ID, &first.ID))
+	count, err = repo.GetUnreadCount(ctx, chat.ID, reader.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	missing := uuid.New()
+	err = repo.MarkChatAsRead(ctx, chat.ID, reader.ID, &missing)
+	assert.True(t, apperror.IsNotFound(err))
+}
@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+// PollRepository defines data access operations for polls and their votes.
+type PollRepository interface {
+	Create(ctx context.Context, input model.CreatePollInput) (*model.Poll, error)
+	// AttachMessage links a poll to the chat or topic message it was posted as.
+	AttachMessage(ctx context.Context, pollID, messageID uuid.UUID) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// FindByID returns the poll with its tallies. Closed polls report the
+	// results frozen when they closed.
+	FindByID(ctx context.Context, id uuid.UUID) (*model.Poll, error)
+	ListVotes(ctx context.Context, pollID uuid.UUID) ([]model.PollVote, error)
+	// SetVotes replaces the user's votes with positions; an empty slice
+	// retracts them. It returns false without changing anything once the
+	// poll is closed.
+	SetVotes(ctx context.Context, pollID, userID uuid.UUID, positions []int) (bool, error)
+	// Close freezes the poll's results. It returns false if the poll was
+	// already closed.
+	Close(ctx context.Context, id uuid.UUID) (bool, error)
+}
+
+type pgPollRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPollRepository creates a new PostgreSQL-backed PollRepository.
+func NewPollRepository(db *pgxpool.Pool) PollRepository {
+	return &pgPollRepository{db: db}
+}
+
+func (r *pgPollRepository) Create(ctx context.Context, input model.CreatePollInput) (*model.Poll, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin create poll transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var id uuid.UUID
+	err = tx.QueryRow(ctx,
+		`INSERT INTO polls (chat_id, topic_id, creator_id, question, multiple_choice, anonymous, closes_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)
+		 RETURNING id`,
+		input.ChatID, input.TopicID, input.CreatorID, input.Question, input.MultipleChoice, input.Anonymous, input.ClosesAt,
+	).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("create poll: %w", err)
+	}
+
+	for i, text := range input.Options {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO poll_options (poll_id, position, text) VALUES ($1, $2, $3)`,
+			id, i, text,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("create poll option: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit create poll transaction: %w", err)
+	}
+
+	return r.FindByID(ctx, id)
+}
+
+func (r *pgPollRepository) AttachMessage(ctx context.Context, pollID, messageID uuid.UUID) error {
+	result, err := r.db.Exec(ctx,
+		`UPDATE polls SET
+		   message_id = CASE WHEN chat_id IS NOT NULL THEN $2::uuid END,
+		   topic_message_id = CASE WHEN topic_id IS NOT NULL THEN $2::uuid END
+		 WHERE id = $1`,
+		pollID, messageID,
+	)
+	if err != nil {
+		return fmt.Errorf("attach poll message: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NotFound("poll", pollID.String())
+	}
+
+	return nil
+}
+
+func (r *pgPollRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM polls WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete poll: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NotFound("poll", id.String())
+	}
+
+	return nil
+}
+
+func (r *pgPollRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Poll, error) {
+	var p model.Poll
+	err := r.db.QueryRow(ctx,
+		`SELECT id, chat_id, topic_id, COALESCE(message_id, topic_message_id), creator_id, question,
+		        multiple_choice, anonymous, closes_at, closed_at,
+		        COALESCE(final_voters, (SELECT COUNT(DISTINCT v.user_id) FROM poll_votes v WHERE v.poll_id = p.id)),
+		        created_at
+		 FROM polls p WHERE id = $1`, id,
+	).Scan(
+		&p.ID, &p.ChatID, &p.TopicID, &p.MessageID, &p.CreatorID, &p.Question,
+		&p.MultipleChoice, &p.Anonymous, &p.ClosesAt, &p.ClosedAt, &p.TotalVoters, &p.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NotFound("poll", id.String())
+		}
+		return nil, fmt.Errorf("find poll: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx,
+		`SELECT o.position, o.text,
+		        COALESCE(o.final_votes, (SELECT COUNT(*) FROM poll_votes v WHERE v.poll_id = o.poll_id AND v.position = o.position))
+		 FROM poll_options o
+		 WHERE o.poll_id = $1
+		 ORDER BY o.position ASC`, id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list poll options: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var o model.PollOption
+		if err := rows.Scan(&o.Position, &o.Text, &o.Votes); err != nil {
+			return nil, fmt.Errorf("scan poll option: %w", err)
+		}
+		p.Options = append(p.Options, o)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate poll option rows: %w", err)
+	}
+
+	return &p, nil
+}
+
+func (r *pgPollRepository) ListVotes(ctx context.Context, pollID uuid.UUID) ([]model.PollVote, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT position, user_id FROM poll_votes
+		 WHERE poll_id = $1
+		 ORDER BY created_at ASC`, pollID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list poll votes: %w", err)
+	}
+	defer rows.Close()
+
+	var votes []model.PollVote
+	for rows.Next() {
+		var v model.PollVote
+		if err := rows.Scan(&v.Position, &v.UserID); err != nil {
+			return nil, fmt.Errorf("scan poll vote: %w", err)
+		}
+		votes = append(votes, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate poll vote rows: %w", err)
+	}
+
+	return votes, nil
+}
+
+func (r *pgPollRepository) SetVotes(ctx context.Context, pollID, userID uuid.UUID, positions []int) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin vote transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Locking the poll row serializes votes with Close
+	var open bool
+	err = tx.QueryRow(ctx,
+		`SELECT closed_at IS NULL AND (closes_at IS NULL OR closes_at > NOW())
+		 FROM polls WHERE id = $1
+		 FOR UPDATE`, pollID,
+	).Scan(&open)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, apperror.NotFound("poll", pollID.String())
+		}
+		return false, fmt.Errorf("lock poll: %w", err)
+	}
+	if !open {
+		return false, nil
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM poll_votes WHERE poll_id = $1 AND user_id = $2`, pollID, userID)
+	if err != nil {
+		return false, fmt.Errorf("clear poll votes: %w", err)
+	}
+
+	if len(positions) > 0 {
+		_, err = tx.Exec(ctx,
+			`INSERT INTO poll_votes (poll_id, position, user_id)
+			 SELECT $1, unnest($3::int[]), $2`,
+			pollID, userID, positions,
+		)
+		if err != nil {
+			return false, fmt.Errorf("record poll votes: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("commit vote transaction: %w", err)
+	}
+
+	return true, nil
+}
+
+func (r *pgPollRepository) Close(ctx context.Context, id uuid.UUID) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin close poll transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var closed bool
+	err = tx.QueryRow(ctx,
+		`SELECT closed_at IS NOT NULL FROM polls WHERE id = $1 FOR UPDATE`, id,
+	).Scan(&closed)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, apperror.NotFound("poll", id.String())
+		}
+		return false, fmt.Errorf("lock poll: %w", err)
+	}
+	if closed {
+		return false, nil
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE poll_options o SET final_votes =
+		   (SELECT COUNT(*) FROM poll_votes v WHERE v.poll_id = o.poll_id AND v.position = o.position)
+		 WHERE o.poll_id = $1`, id,
+	)
+	if err != nil {
+		return false, fmt.Errorf("freeze poll votes: %w", err)
+	}
+
+	// A poll past its deadline closed at the deadline, not when noticed
+	_, err = tx.Exec(ctx,
+		`UPDATE polls SET
+		   closed_at = LEAST(NOW(), COALESCE(closes_at, NOW())),
+		   final_voters = (SELECT COUNT(DISTINCT v.user_id) FROM poll_votes v WHERE v.poll_id = polls.id)
+		 WHERE id = $1`, id,
+	)
+	if err != nil {
+		return false, fmt.Errorf("close poll: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("commit close poll transaction: %w", err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,88 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/internal/repository"
+	"github.com/otoritech/chatat/internal/testutil"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+func TestPollRepository_Votes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testutil.CleanTables(t, testPool)
+	ctx := context.Background()
+
+	alice := createTestUser(t, "+62670", "Alice")
+	bob := createTestUser(t, "+62671", "Bob")
+	chat, err := repository.NewChatRepository(testPool).Create(ctx, model.CreateChatInput{
+		Type: model.ChatTypeGroup, Name: "Polls", CreatedBy: alice.ID,
+	})
+	require.NoError(t, err)
+
+	repo := repository.NewPollRepository(testPool)
+	poll, err := repo.Create(ctx, model.CreatePollInput{
+		ChatID: &chat.ID, CreatorID: alice.ID, Question: "Makan di mana?",
+		Options: []string{"Sate", "Bakso", "Soto"}, MultipleChoice: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, poll.Options, 3)
+	assert.Equal(t, "Soto", poll.Options[2].Text)
+
+	msg, err := repository.NewMessageRepository(testPool).Create(ctx, model.CreateMessageInput{
+		ChatID: chat.ID, SenderID: alice.ID, Content: poll.Question, Type: model.MessageTypePoll,
+	})
+	require.NoError(t, err)
+	require.NoError(t, repo.AttachMessage(ctx, poll.ID, msg.ID))
+
+	ok, err := repo.SetVotes(ctx, poll.ID, alice.ID, []int{0, 2})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = repo.SetVotes(ctx, poll.ID, bob.ID, []int{0})
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// Voting again replaces the earlier votes
+	_, err = repo.SetVotes(ctx, poll.ID, alice.ID, []int{1})
+	require.NoError(t, err)
+
+	found, err := repo.FindByID(ctx, poll.ID)
+	require.NoError(t, err)
+	assert.Equal(t, msg.ID, *found.MessageID)
+	assert.Equal(t, 2, found.TotalVoters)
+	assert.Equal(t, []int{1, 1, 0}, []int{found.Options[0].Votes, found.Options[1].Votes, found.Options[2].Votes})
+
+	votes, err := repo.ListVotes(ctx, poll.ID)
+	require.NoError(t, err)
+	assert.Len(t, votes, 2)
+
+	closed, err := repo.Close(ctx, poll.ID)
+	require.NoError(t, err)
+	assert.True(t, closed)
+	closed, err = repo.Close(ctx, poll.ID)
+	require.NoError(t, err)
+	assert.False(t, closed)
+
+	ok, err = repo.SetVotes(ctx, poll.ID, bob.ID, nil)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Frozen results survive a voter leaving
+	require.NoError(t, repository.NewUserRepository(testPool).Delete(ctx, bob.ID))
+	found, err = repo.FindByID(ctx, poll.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found.ClosedAt)
+	assert.Equal(t, 2, found.TotalVoters)
+	assert.Equal(t, 1, found.Options[0].Votes)
+
+	require.NoError(t, repo.Delete(ctx, poll.ID))
+	_, err = repo.FindByID(ctx, poll.ID)
+	assert.True(t, apperror.IsNotFound(err))
+}
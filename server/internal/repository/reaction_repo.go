@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+// ReactionRepository defines operations for managing emoji reactions on messages.
+type ReactionRepository interface {
+	// Add records a reaction and reports whether it was new; adding one the
+	// user already made is a no-op.
+	Add(ctx context.Context, messageID, userID uuid.UUID, emoji string) (bool, error)
+	Remove(ctx context.Context, messageID, userID uuid.UUID, emoji string) error
+	ListByMessages(ctx context.Context, messageIDs []uuid.UUID) (map[uuid.UUID][]*model.Reaction, error)
+}
+
+type pgReactionRepository struct {
+	db    *pgxpool.Pool
+	table string
+}
+
+// NewMessageReactionRepository creates a ReactionRepository for chat messages.
+func NewMessageReactionRepository(db *pgxpool.Pool) ReactionRepository {
+	return &pgReactionRepository{db: db, table: "message_reactions"}
+}
+
+// NewTopicMessageReactionRepository creates a ReactionRepository for topic messages.
+func NewTopicMessageReactionRepository(db *pgxpool.Pool) ReactionRepository {
+	return &pgReactionRepository{db: db, table: "topic_message_reactions"}
+}
+
+func (r *pgReactionRepository) Add(ctx context.Context, messageID, userID uuid.UUID, emoji string) (bool, error) {
+	result, err := r.db.Exec(ctx,
+		`INSERT INTO `+r.table+` (message_id, user_id, emoji) VALUES ($1, $2, $3)
+		 ON CONFLICT (message_id, user_id, emoji) DO NOTHING`,
+		messageID, userID, emoji,
+	)
+	if err != nil {
+		return false, fmt.Errorf("add reaction: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+func (r *pgReactionRepository) Remove(ctx context.Context, messageID, userID uuid.UUID, emoji string) error {
+	result, err := r.db.Exec(ctx,
+		`DELETE FROM `+r.table+` WHERE message_id = $1 AND user_id = $2 AND emoji = $3`,
+		messageID, userID, emoji,
+	)
+	if err != nil {
+		return fmt.Errorf("remove reaction: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NotFound("reaction", emoji)
+	}
+
+	return nil
+}
+
+func (r *pgReactionRepository) ListByMessages(ctx context.Context, messageIDs []uuid.UUID) (map[uuid.UUID][]*model.Reaction, error) {
+	reactions := make(map[uuid.UUID][]*model.Reaction)
+	if len(messageIDs) == 0 {
+		return reactions, nil
+	}
+
+	rows, err := r.db.Query(ctx,
+		`SELECT message_id, user_id, emoji, created_at
+		 FROM `+r.table+`
+		 WHERE message_id = ANY($1)
+		 ORDER BY created_at ASC`,
+		messageIDs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list reactions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var reaction model.Reaction
+		if err := rows.Scan(&reaction.MessageID, &reaction.UserID, &reaction.Emoji, &reaction.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan reaction row: %w", err)
+		}
+		reactions[reaction.MessageID] = append(reactions[reaction.MessageID], &reaction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate reaction rows: %w", err)
+	}
+
+	return reactions, nil
+}
@@ -0,0 +1,63 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/internal/repository"
+	"github.com/otoritech/chatat/internal/testutil"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+func TestReactionRepository_AddRemoveList(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testutil.CleanTables(t, testPool)
+	ctx := context.Background()
+
+	alice := createTestUser(t, "+62610", "Alice")
+	bob := createTestUser(t, "+62611", "Bob")
+
+	chatRepo := repository.NewChatRepository(testPool)
+	chat, err := chatRepo.Create(ctx, model.CreateChatInput{
+		Type: model.ChatTypeGroup, Name: "Reactions", CreatedBy: alice.ID,
+	})
+	require.NoError(t, err)
+
+	msgRepo := repository.NewMessageRepository(testPool)
+	msg, err := msgRepo.Create(ctx, model.CreateMessageInput{
+		ChatID: chat.ID, SenderID: alice.ID, Content: "react", Type: model.MessageTypeText,
+	})
+	require.NoError(t, err)
+
+	repo := repository.NewMessageReactionRepository(testPool)
+	for _, r := range []struct {
+		userID uuid.UUID
+		emoji  string
+	}{{alice.ID, "👍"}, {bob.ID, "👍"}, {bob.ID, "🔥"}} {
+		added, err := repo.Add(ctx, msg.ID, r.userID, r.emoji)
+		require.NoError(t, err)
+		assert.True(t, added)
+	}
+	// Duplicate is ignored
+	added, err := repo.Add(ctx, msg.ID, alice.ID, "👍")
+	require.NoError(t, err)
+	assert.False(t, added)
+
+	reactions, err := repo.ListByMessages(ctx, []uuid.UUID{msg.ID})
+	require.NoError(t, err)
+	summaries := model.SummarizeReactions(reactions[msg.ID])
+	require.Len(t, summaries, 2)
+	assert.Equal(t, "👍", summaries[0].Emoji)
+	assert.Equal(t, 2, summaries[0].Count)
+
+	require.NoError(t, repo.Remove(ctx, msg.ID, bob.ID, "🔥"))
+	err = repo.Remove(ctx, msg.ID, bob.ID, "🔥")
+	assert.True(t, apperror.IsNotFound(err))
+}
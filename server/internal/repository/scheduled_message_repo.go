@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+// ScheduledMessageRepository defines operations for managing scheduled messages.
+type ScheduledMessageRepository interface {
+	Create(ctx context.Context, input model.CreateScheduledMessageInput) (*model.ScheduledMessage, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*model.ScheduledMessage, error)
+	// ListBySender returns the sender's undelivered messages in a chat.
+	ListBySender(ctx context.Context, chatID, senderID uuid.UUID) ([]*model.ScheduledMessage, error)
+	// Update changes a pending or failed message and queues it again. It
+	// returns a Conflict error once the message has been claimed for delivery.
+	Update(ctx context.Context, id uuid.UUID, content string, sendAt time.Time) (*model.ScheduledMessage, error)
+	Cancel(ctx context.Context, id uuid.UUID) error
+	// ClaimDue marks up to limit due messages as sending and returns them.
+	// Rows locked by another replica are skipped. Messages claimed since
+	// staleBefore are left alone: that covers both in-flight deliveries and
+	// released ones waiting to be retried.
+	ClaimDue(ctx context.Context, staleBefore time.Time, limit int) ([]*model.ScheduledMessage, error)
+	MarkSent(ctx context.Context, id, messageID uuid.UUID) error
+	MarkFailed(ctx context.Context, id uuid.UUID, reason string) error
+	// Release returns a claimed message to pending so it is retried once its
+	// claim goes stale.
+	Release(ctx context.Context, id uuid.UUID) error
+}
+
+// scheduledMessageColumns is the column list scanned by scheduledMessageScanTargets.
+const scheduledMessageColumns = `id, chat_id, sender_id, content, reply_to_id, type, metadata, send_at, status, attempts, message_id, error, created_at, updated_at`
+
+func scheduledMessageScanTargets(msg *model.ScheduledMessage) []interface{} {
+	return []interface{}{
+		&msg.ID, &msg.ChatID, &msg.SenderID, &msg.Content, &msg.ReplyToID, &msg.Type, &msg.Metadata,
+		&msg.SendAt, &msg.Status, &msg.Attempts, &msg.MessageID, &msg.Error, &msg.CreatedAt, &msg.UpdatedAt,
+	}
+}
+
+type pgScheduledMessageRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewScheduledMessageRepository creates a new PostgreSQL-backed ScheduledMessageRepository.
+func NewScheduledMessageRepository(db *pgxpool.Pool) ScheduledMessageRepository {
+	return &pgScheduledMessageRepository{db: db}
+}
+
+func (r *pgScheduledMessageRepository) Create(ctx context.Context, input model.CreateScheduledMessageInput) (*model.ScheduledMessage, error) {
+	msgType := input.Type
+	if msgType == "" {
+		msgType = model.MessageTypeText
+	}
+
+	var msg model.ScheduledMessage
+	err := r.db.QueryRow(ctx,
+		`INSERT INTO scheduled_messages (chat_id, sender_id, content, reply_to_id, type, metadata, send_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)
+		 RETURNING `+scheduledMessageColumns,
+		input.ChatID, input.SenderID, input.Content, input.ReplyToID, msgType, input.Metadata, input.SendAt,
+	).Scan(scheduledMessageScanTargets(&msg)...)
+	if err != nil {
+		return nil, fmt.Errorf("create scheduled message: %w", err)
+	}
+
+	return &msg, nil
+}
+
+func (r *pgScheduledMessageRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.ScheduledMessage, error) {
+	var msg model.ScheduledMessage
+	err := r.db.QueryRow(ctx,
+		`SELECT `+scheduledMessageColumns+` FROM scheduled_messages WHERE id = $1`, id,
+	).Scan(scheduledMessageScanTargets(&msg)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NotFound("scheduled message", id.String())
+		}
+		return nil, fmt.Errorf("find scheduled message by id: %w", err)
+	}
+
+	return &msg, nil
+}
+
+func (r *pgScheduledMessageRepository) ListBySender(ctx context.Context, chatID, senderID uuid.UUID) ([]*model.ScheduledMessage, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+scheduledMessageColumns+`
+		 FROM scheduled_messages
+		 WHERE chat_id = $1 AND sender_id = $2 AND status IN ('pending', 'sending', 'failed')
+		 ORDER BY send_at ASC`,
+		chatID, senderID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list scheduled messages: %w", err)
+	}
+
+	return scanScheduledMessages(rows)
+}
+
+func (r *pgScheduledMessageRepository) Update(ctx context.Context, id uuid.UUID, content string, sendAt time.Time) (*model.ScheduledMessage, error) {
+	var msg model.ScheduledMessage
+	err := r.db.QueryRow(ctx,
+		`UPDATE scheduled_messages
+		 SET content = $2, send_at = $3, status = 'pending', attempts = 0, claimed_at = NULL, error = '', updated_at = NOW()
+		 WHERE id = $1 AND status IN ('pending', 'failed')
+		 RETURNING `+scheduledMessageColumns,
+		id, content, sendAt,
+	).Scan(scheduledMessageScanTargets(&msg)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, r.notPending(ctx, id)
+		}
+		return nil, fmt.Errorf("update scheduled message: %w", err)
+	}
+
+	return &msg, nil
+}
+
+func (r *pgScheduledMessageRepository) Cancel(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.Exec(ctx,
+		`UPDATE scheduled_messages SET status = 'cancelled', updated_at = NOW()
+		 WHERE id = $1 AND status IN ('pending', 'failed')`,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("cancel scheduled message: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return r.notPending(ctx, id)
+	}
+
+	return nil
+}
+
+func (r *pgScheduledMessageRepository) ClaimDue(ctx context.Context, staleBefore time.Time, limit int) ([]*model.ScheduledMessage, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	// FOR UPDATE SKIP LOCKED lets each replica claim a disjoint batch
+	rows, err := r.db.Query(ctx,
+		`UPDATE scheduled_messages
+		 SET status = 'sending', claimed_at = NOW(), attempts = attempts + 1, updated_at = NOW()
+		 WHERE id IN (
+		   SELECT id FROM scheduled_messages
+		   WHERE send_at <= NOW()
+		     AND status IN ('pending', 'sending')
+		     AND (claimed_at IS NULL OR claimed_at < $1)
+		   ORDER BY send_at ASC
+		   LIMIT $2
+		   FOR UPDATE SKIP LOCKED
+		 )
+		 RETURNING `+scheduledMessageColumns,
+		staleBefore, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("claim scheduled messages: %w", err)
+	}
+
+	return scanScheduledMessages(rows)
+}
+
+func (r *pgScheduledMessageRepository) MarkSent(ctx context.Context, id, messageID uuid.UUID) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE scheduled_messages
+		 SET status = 'sent', message_id = $2, error = '', updated_at = NOW()
+		 WHERE id = $1`,
+		id, messageID,
+	)
+	if err != nil {
+		return fmt.Errorf("mark scheduled message sent: %w", err)
+	}
+	return nil
+}
+
+func (r *pgScheduledMessageRepository) MarkFailed(ctx context.Context, id uuid.UUID, reason string) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE scheduled_messages
+		 SET status = 'failed', error = $2, updated_at = NOW()
+		 WHERE id = $1`,
+		id, reason,
+	)
+	if err != nil {
+		return fmt.Errorf("mark scheduled message failed: %w", err)
+	}
+	return nil
+}
+
+func (r *pgScheduledMessageRepository) Release(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE scheduled_messages
+		 SET status = 'pending', updated_at = NOW()
+		 WHERE id = $1 AND status = 'sending'`,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("release scheduled message: %w", err)
+	}
+	return nil
+}
+
+// notPending distinguishes a missing message from one that can no longer change.
+func (r *pgScheduledMessageRepository) notPending(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.FindByID(ctx, id); err != nil {
+		return err
+	}
+	return apperror.Conflict("scheduled message is no longer pending")
+}
+
+func scanScheduledMessages(rows pgx.Rows) ([]*model.ScheduledMessage, error) {
+	defer rows.Close()
+
+	messages := make([]*model.ScheduledMessage, 0)
+	for rows.Next() {
+		var msg model.ScheduledMessage
+		if err := rows.Scan(scheduledMessageScanTargets(&msg)...); err != nil {
+			return nil, fmt.Errorf("scan scheduled message row: %w", err)
+		}
+		messages = append(messages, &msg)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate scheduled message rows: %w", err)
+	}
+
+	return messages, nil
+}
@@ -66,6 +66,7 @@ func (r *pgSearchRepository) SearchMessages(ctx context.Context, userID uuid.UUI
 		 JOIN users u ON u.id = m.sender_id
 		 WHERE m.search_vector @@ to_tsquery('indonesian', $1)
 		   AND m.is_deleted = false
+		   AND (m.expires_at IS NULL OR m.expires_at > NOW())
 		 ORDER BY m.created_at DESC
 		 OFFSET $3 LIMIT $4`,
 		tsq, userID, offset, limit,
@@ -115,6 +116,7 @@ func (r *pgSearchRepository) SearchMessagesInChat(ctx context.Context, chatID uu
 		 WHERE m.chat_id = $2
 		   AND m.search_vector @@ to_tsquery('indonesian', $1)
 		   AND m.is_deleted = false
+		   AND (m.expires_at IS NULL OR m.expires_at > NOW())
 		 ORDER BY m.created_at DESC
 		 OFFSET $3 LIMIT $4`,
 		tsq, chatID, offset, limit,
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+// ThreadRepository defines operations for managing thread followers.
+type ThreadRepository interface {
+	Follow(ctx context.Context, rootID, userID uuid.UUID) error
+	Unfollow(ctx context.Context, rootID, userID uuid.UUID) error
+	IsFollowing(ctx context.Context, rootID, userID uuid.UUID) (bool, error)
+	ListFollowers(ctx context.Context, rootID uuid.UUID) ([]uuid.UUID, error)
+}
+
+type pgThreadRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewThreadRepository creates a new PostgreSQL-backed ThreadRepository.
+func NewThreadRepository(db *pgxpool.Pool) ThreadRepository {
+	return &pgThreadRepository{db: db}
+}
+
+func (r *pgThreadRepository) Follow(ctx context.Context, rootID, userID uuid.UUID) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO thread_followers (message_id, user_id) VALUES ($1, $2)
+		 ON CONFLICT (message_id, user_id) DO NOTHING`,
+		rootID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("follow thread: %w", err)
+	}
+	return nil
+}
+
+func (r *pgThreadRepository) Unfollow(ctx context.Context, rootID, userID uuid.UUID) error {
+	result, err := r.db.Exec(ctx,
+		`DELETE FROM thread_followers WHERE message_id = $1 AND user_id = $2`,
+		rootID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("unfollow thread: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NotFound("thread follower", userID.String())
+	}
+	return nil
+}
+
+func (r *pgThreadRepository) IsFollowing(ctx context.Context, rootID, userID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM thread_followers WHERE message_id = $1 AND user_id = $2)`,
+		rootID, userID,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("check thread follower: %w", err)
+	}
+	return exists, nil
+}
+
+func (r *pgThreadRepository) ListFollowers(ctx context.Context, rootID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT user_id FROM thread_followers WHERE message_id = $1 ORDER BY followed_at ASC`,
+		rootID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list thread followers: %w", err)
+	}
+	defer rows.Close()
+
+	followers := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan thread follower row: %w", err)
+		}
+		followers = append(followers, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate thread follower rows: %w", err)
+	}
+
+	return followers, nil
+}
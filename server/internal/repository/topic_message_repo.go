@@ -20,6 +20,8 @@ type TopicMessageRepository interface {
 	FindByID(ctx context.Context, id uuid.UUID) (*model.TopicMessage, error)
 	ListByTopic(ctx context.Context, topicID uuid.UUID, cursor *time.Time, limit int) ([]*model.TopicMessage, error)
 	MarkAsDeleted(ctx context.Context, id uuid.UUID, forAll bool) error
+	Edit(ctx context.Context, id uuid.UUID, content string) (*model.TopicMessage, error)
+	ListEdits(ctx context.Context, messageID uuid.UUID) ([]*model.MessageEdit, error)
 }
 
 type pgTopicMessageRepository struct {
@@ -39,13 +41,13 @@ func (r *pgTopicMessageRepository) Create(ctx context.Context, input model.Creat
 
 	var msg model.TopicMessage
 	err := r.db.QueryRow(ctx,
-		`INSERT INTO topic_messages (topic_id, sender_id, content, reply_to_id, type)
-		 VALUES ($1, $2, $3, $4, $5)
-		 RETURNING id, topic_id, sender_id, content, reply_to_id, type, is_deleted, deleted_for_all, created_at`,
-		input.TopicID, input.SenderID, input.Content, input.ReplyToID, msgType,
+		`INSERT INTO topic_messages (topic_id, sender_id, content, reply_to_id, type, metadata)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 RETURNING id, topic_id, sender_id, content, reply_to_id, type, metadata, is_deleted, deleted_for_all, created_at, edited_at`,
+		input.TopicID, input.SenderID, input.Content, input.ReplyToID, msgType, input.Metadata,
 	).Scan(
 		&msg.ID, &msg.TopicID, &msg.SenderID, &msg.Content, &msg.ReplyToID,
-		&msg.Type, &msg.IsDeleted, &msg.DeletedForAll, &msg.CreatedAt,
+		&msg.Type, &msg.Metadata, &msg.IsDeleted, &msg.DeletedForAll, &msg.CreatedAt, &msg.EditedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("create topic message: %w", err)
@@ -57,11 +59,11 @@ func (r *pgTopicMessageRepository) Create(ctx context.Context, input model.Creat
 func (r *pgTopicMessageRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.TopicMessage, error) {
 	var msg model.TopicMessage
 	err := r.db.QueryRow(ctx,
-		`SELECT id, topic_id, sender_id, content, reply_to_id, type, is_deleted, deleted_for_all, created_at
+		`SELECT id, topic_id, sender_id, content, reply_to_id, type, metadata, is_deleted, deleted_for_all, created_at, edited_at
 		 FROM topic_messages WHERE id = $1`, id,
 	).Scan(
 		&msg.ID, &msg.TopicID, &msg.SenderID, &msg.Content, &msg.ReplyToID,
-		&msg.Type, &msg.IsDeleted, &msg.DeletedForAll, &msg.CreatedAt,
+		&msg.Type, &msg.Metadata, &msg.IsDeleted, &msg.DeletedForAll, &msg.CreatedAt, &msg.EditedAt,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -83,7 +85,7 @@ func (r *pgTopicMessageRepository) ListByTopic(ctx context.Context, topicID uuid
 
 	if cursor != nil {
 		rows, err = r.db.Query(ctx,
-			`SELECT id, topic_id, sender_id, content, reply_to_id, type, is_deleted, deleted_for_all, created_at
+			`SELECT id, topic_id, sender_id, content, reply_to_id, type, metadata, is_deleted, deleted_for_all, created_at, edited_at
 			 FROM topic_messages
 			 WHERE topic_id = $1 AND created_at < $2
 			 ORDER BY created_at DESC
@@ -92,7 +94,7 @@ func (r *pgTopicMessageRepository) ListByTopic(ctx context.Context, topicID uuid
 		)
 	} else {
 		rows, err = r.db.Query(ctx,
-			`SELECT id, topic_id, sender_id, content, reply_to_id, type, is_deleted, deleted_for_all, created_at
+			`SELECT id, topic_id, sender_id, content, reply_to_id, type, metadata, is_deleted, deleted_for_all, created_at, edited_at
 			 FROM topic_messages
 			 WHERE topic_id = $1
 			 ORDER BY created_at DESC
@@ -110,7 +112,7 @@ func (r *pgTopicMessageRepository) ListByTopic(ctx context.Context, topicID uuid
 		var msg model.TopicMessage
 		if err := rows.Scan(
 			&msg.ID, &msg.TopicID, &msg.SenderID, &msg.Content, &msg.ReplyToID,
-			&msg.Type, &msg.IsDeleted, &msg.DeletedForAll, &msg.CreatedAt,
+			&msg.Type, &msg.Metadata, &msg.IsDeleted, &msg.DeletedForAll, &msg.CreatedAt, &msg.EditedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan topic message row: %w", err)
 		}
@@ -139,3 +141,72 @@ func (r *pgTopicMessageRepository) MarkAsDeleted(ctx context.Context, id uuid.UU
 
 	return nil
 }
+
+// Edit replaces a message's content, keeping the previous version in the edit history.
+func (r *pgTopicMessageRepository) Edit(ctx context.Context, id uuid.UUID, content string) (*model.TopicMessage, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin edit topic message transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO topic_message_edits (message_id, content)
+		 SELECT id, content FROM topic_messages WHERE id = $1`,
+		id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("save topic message edit: %w", err)
+	}
+
+	var msg model.TopicMessage
+	err = tx.QueryRow(ctx,
+		`UPDATE topic_messages SET content = $2, edited_at = NOW()
+		 WHERE id = $1
+		 RETURNING id, topic_id, sender_id, content, reply_to_id, type, metadata, is_deleted, deleted_for_all, created_at, edited_at`,
+		id, content,
+	).Scan(
+		&msg.ID, &msg.TopicID, &msg.SenderID, &msg.Content, &msg.ReplyToID,
+		&msg.Type, &msg.Metadata, &msg.IsDeleted, &msg.DeletedForAll, &msg.CreatedAt, &msg.EditedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NotFound("topic message", id.String())
+		}
+		return nil, fmt.Errorf("edit topic message: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit edit topic message transaction: %w", err)
+	}
+
+	return &msg, nil
+}
+
+func (r *pgTopicMessageRepository) ListEdits(ctx context.Context, messageID uuid.UUID) ([]*model.MessageEdit, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT id, message_id, content, edited_at
+		 FROM topic_message_edits WHERE message_id = $1
+		 ORDER BY edited_at ASC`,
+		messageID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list topic message edits: %w", err)
+	}
+	defer rows.Close()
+
+	edits := make([]*model.MessageEdit, 0)
+	for rows.Next() {
+		var e model.MessageEdit
+		if err := rows.Scan(&e.ID, &e.MessageID, &e.Content, &e.EditedAt); err != nil {
+			return nil, fmt.Errorf("scan topic message edit row: %w", err)
+		}
+		edits = append(edits, &e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate topic message edit rows: %w", err)
+	}
+
+	return edits, nil
+}
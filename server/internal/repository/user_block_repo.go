@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/otoritech/chatat/internal/model"
+)
+
+// UserBlockRepository defines data access operations for user blocks.
+type UserBlockRepository interface {
+	Block(ctx context.Context, blockerID, blockedID uuid.UUID) error
+	Unblock(ctx context.Context, blockerID, blockedID uuid.UUID) error
+	ListBlocked(ctx context.Context, blockerID uuid.UUID) ([]*model.BlockedUser, error)
+	// IsBlocked reports whether either user has blocked the other.
+	IsBlocked(ctx context.Context, userID, otherID uuid.UUID) (bool, error)
+	// FindBlockedPeers returns the users userID has blocked or is blocked by.
+	FindBlockedPeers(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+}
+
+type pgUserBlockRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewUserBlockRepository creates a new PostgreSQL-backed UserBlockRepository.
+func NewUserBlockRepository(db *pgxpool.Pool) UserBlockRepository {
+	return &pgUserBlockRepository{db: db}
+}
+
+func (r *pgUserBlockRepository) Block(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO user_blocks (blocker_id, blocked_id)
+		 VALUES ($1, $2)
+		 ON CONFLICT (blocker_id, blocked_id) DO NOTHING`,
+		blockerID, blockedID,
+	)
+	if err != nil {
+		return fmt.Errorf("block user: %w", err)
+	}
+	return nil
+}
+
+func (r *pgUserBlockRepository) Unblock(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+	_, err := r.db.Exec(ctx,
+		`DELETE FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2`,
+		blockerID, blockedID,
+	)
+	if err != nil {
+		return fmt.Errorf("unblock user: %w", err)
+	}
+	return nil
+}
+
+func (r *pgUserBlockRepository) ListBlocked(ctx context.Context, blockerID uuid.UUID) ([]*model.BlockedUser, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT u.id, u.name, u.avatar, b.created_at
+		 FROM user_blocks b
+		 JOIN users u ON u.id = b.blocked_id
+		 WHERE b.blocker_id = $1
+		 ORDER BY b.created_at DESC`,
+		blockerID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list blocked users: %w", err)
+	}
+	defer rows.Close()
+
+	blocked := make([]*model.BlockedUser, 0)
+	for rows.Next() {
+		b := &model.BlockedUser{}
+		if err := rows.Scan(&b.UserID, &b.Name, &b.Avatar, &b.BlockedAt); err != nil {
+			return nil, fmt.Errorf("scan blocked user: %w", err)
+		}
+		blocked = append(blocked, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate blocked users: %w", err)
+	}
+
+	return blocked, nil
+}
+
+func (r *pgUserBlockRepository) IsBlocked(ctx context.Context, userID, otherID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx,
+		`SELECT EXISTS(
+		   SELECT 1 FROM user_blocks
+		   WHERE (blocker_id = $1 AND blocked_id = $2)
+		      OR (blocker_id = $2 AND blocked_id = $1)
+		 )`,
+		userID, otherID,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("check block: %w", err)
+	}
+	return exists, nil
+}
+
+func (r *pgUserBlockRepository) FindBlockedPeers(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT blocked_id FROM user_blocks WHERE blocker_id = $1
+		 UNION
+		 SELECT blocker_id FROM user_blocks WHERE blocked_id = $1`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("find blocked peers: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan blocked peer: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate blocked peers: %w", err)
+	}
+
+	return ids, nil
+}
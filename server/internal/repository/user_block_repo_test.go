@@ -0,0 +1,80 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/internal/repository"
+	"github.com/otoritech/chatat/internal/testutil"
+)
+
+func TestUserBlockRepository_BlockUnblock(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testutil.CleanTables(t, testPool)
+	ctx := context.Background()
+
+	alice := createTestUser(t, "+62620", "Alice")
+	bob := createTestUser(t, "+62621", "Bob")
+	carol := createTestUser(t, "+62622", "Carol")
+
+	repo := repository.NewUserBlockRepository(testPool)
+	require.NoError(t, repo.Block(ctx, alice.ID, bob.ID))
+	// Blocking twice is a no-op
+	require.NoError(t, repo.Block(ctx, alice.ID, bob.ID))
+	require.NoError(t, repo.Block(ctx, carol.ID, alice.ID))
+
+	blocked, err := repo.ListBlocked(ctx, alice.ID)
+	require.NoError(t, err)
+	require.Len(t, blocked, 1)
+	assert.Equal(t, bob.ID, blocked[0].UserID)
+	assert.Equal(t, "Bob", blocked[0].Name)
+
+	// Blocks apply in both directions
+	isBlocked, err := repo.IsBlocked(ctx, bob.ID, alice.ID)
+	require.NoError(t, err)
+	assert.True(t, isBlocked)
+	isBlocked, err = repo.IsBlocked(ctx, bob.ID, carol.ID)
+	require.NoError(t, err)
+	assert.False(t, isBlocked)
+
+	peers, err := repo.FindBlockedPeers(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{bob.ID, carol.ID}, peers)
+
+	require.NoError(t, repo.Unblock(ctx, alice.ID, bob.ID))
+	isBlocked, err = repo.IsBlocked(ctx, alice.ID, bob.ID)
+	require.NoError(t, err)
+	assert.False(t, isBlocked)
+}
+
+func TestAbuseReportRepository_Create(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testutil.CleanTables(t, testPool)
+	ctx := context.Background()
+
+	alice := createTestUser(t, "+62630", "Alice")
+	bob := createTestUser(t, "+62631", "Bob")
+
+	report := &model.AbuseReport{
+		ReporterID:     alice.ID,
+		ReportedUserID: bob.ID,
+		Reason:         model.ReportReasonSpam,
+		Messages: []model.ReportedMessage{
+			{ID: uuid.New(), SenderID: bob.ID, Content: "buy now", Type: model.MessageTypeText},
+		},
+	}
+	repo := repository.NewAbuseReportRepository(testPool)
+	require.NoError(t, repo.Create(ctx, report))
+	assert.NotEqual(t, uuid.Nil, report.ID)
+	assert.Equal(t, model.ReportStatusOpen, report.Status)
+	assert.False(t, report.CreatedAt.IsZero())
+}
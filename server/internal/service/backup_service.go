@@ -100,6 +100,7 @@ func (s *backupService) ExportUserData(ctx context.Context, userID uuid.UUID) (*
 				SenderID:  msg.SenderID.String(),
 				Content:   msg.Content,
 				Type:      string(msg.Type),
+				Metadata:  msg.Metadata,
 				CreatedAt: msg.CreatedAt.Format(time.RFC3339),
 			})
 		}
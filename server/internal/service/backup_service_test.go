@@ -463,6 +463,30 @@ func TestBackupService_ExportUserData_Errors(t *testing.T) {
 		require.NoError(t, err)
 		assert.Len(t, bundle.Data.Messages, 1, "deleted message should be skipped")
 	})
+
+	t.Run("export keeps message metadata", func(t *testing.T) {
+		userRepo := newMockUserRepo()
+		chatRepo := newMockChatRepo()
+		msgRepo := newMockMessageRepo()
+		uid := uuid.New()
+		userRepo.addUser(&model.User{ID: uid, Name: "A"})
+
+		chat := &model.Chat{ID: uuid.New(), Type: model.ChatTypePersonal, CreatedBy: uid}
+		chatRepo.chats[chat.ID] = chat
+		_ = chatRepo.AddMember(context.Background(), chat.ID, uid, model.MemberRoleAdmin)
+
+		meta := `{"latitude":-6.2,"longitude":106.8,"label":"Gudang"}`
+		_, _ = msgRepo.Create(context.Background(), model.CreateMessageInput{
+			ChatID: chat.ID, SenderID: uid, Content: "Gudang", Type: model.MessageTypeLocation, Metadata: []byte(meta),
+		})
+
+		svc := NewBackupService(newMockBackupRepo(), userRepo, chatRepo, msgRepo, newMockContactRepo(), newMockBackupDocRepo())
+		bundle, err := svc.ExportUserData(context.Background(), uid)
+		require.NoError(t, err)
+		require.Len(t, bundle.Data.Messages, 1)
+		assert.Equal(t, "location", bundle.Data.Messages[0].Type)
+		assert.JSONEq(t, meta, string(bundle.Data.Messages[0].Metadata))
+	})
 }
 
 func TestBackupService_ImportUserData_Errors(t *testing.T) {
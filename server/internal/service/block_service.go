@@ -48,6 +48,9 @@ type blockService struct {
 	blockRepo   repository.BlockRepository
 	docRepo     repository.DocumentRepository
 	historyRepo repository.DocumentHistoryRepository
+	mentionSvc  MentionService
+	versionSvc  DocumentVersionService
+	live        LiveDocuments
 }
 
 // NewBlockService creates a new block service.
@@ -55,11 +58,17 @@ func NewBlockService(
 	blockRepo repository.BlockRepository,
 	docRepo repository.DocumentRepository,
 	historyRepo repository.DocumentHistoryRepository,
+	mentionSvc MentionService,
+	versionSvc DocumentVersionService,
+	live LiveDocuments,
 ) BlockService {
 	return &blockService{
 		blockRepo:   blockRepo,
 		docRepo:     docRepo,
 		historyRepo: historyRepo,
+		mentionSvc:  mentionSvc,
+		versionSvc:  versionSvc,
+		live:        live,
 	}
 }
 
@@ -76,6 +85,17 @@ func (s *blockService) AddBlock(ctx context.Context, docID, userID uuid.UUID, in
 		return nil, err
 	}
 
+	resume, err := suspendLive(ctx, s.live, docID)
+	if err != nil {
+		return nil, err
+	}
+	defer resume()
+
+	mentioned, err := s.resolveBlockMentions(ctx, doc, userID, input.Content)
+	if err != nil {
+		return nil, err
+	}
+
 	block, err := s.blockRepo.Create(ctx, model.CreateBlockInput{
 		DocumentID:    docID,
 		Type:          input.Type,
@@ -92,8 +112,14 @@ func (s *blockService) AddBlock(ctx context.Context, docID, userID uuid.UUID, in
 	if err != nil {
 		return nil, fmt.Errorf("add block: %w", err)
 	}
+	block.Mentions = mentioned
+
+	if len(mentioned) > 0 {
+		recordMentions(ctx, s.mentionSvc, blockMentionTarget(block, userID), mentioned)
+	}
 
 	_ = s.historyRepo.Create(ctx, docID, userID, "block_added", "Blok ditambahkan")
+	captureEditVersion(ctx, s.versionSvc, docID, userID)
 	return block, nil
 }
 
@@ -111,12 +137,40 @@ func (s *blockService) UpdateBlock(ctx context.Context, blockID, userID uuid.UUI
 		return nil, apperror.Forbidden("dokumen terkunci, tidak dapat mengubah blok")
 	}
 
+	resume, err := suspendLive(ctx, s.live, doc.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer resume()
+
+	// Realtime edits saved by the suspend may have changed the block
+	block, err = s.blockRepo.FindByID(ctx, blockID)
+	if err != nil {
+		return nil, err
+	}
+
+	var mentioned []uuid.UUID
+	hadMentions := mentionPattern.MatchString(block.Content)
+	if input.Content != nil {
+		mentioned, err = s.resolveBlockMentions(ctx, doc, userID, *input.Content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	updated, err := s.blockRepo.Update(ctx, blockID, input)
 	if err != nil {
 		return nil, err
 	}
 
+	// Skip the sync when neither the old nor the new content mentions anyone
+	if input.Content != nil && (len(mentioned) > 0 || hadMentions) {
+		updated.Mentions = mentioned
+		recordMentions(ctx, s.mentionSvc, blockMentionTarget(updated, userID), mentioned)
+	}
+
 	_ = s.historyRepo.Create(ctx, doc.ID, userID, "block_updated", "Blok diperbarui")
+	captureEditVersion(ctx, s.versionSvc, doc.ID, userID)
 	return updated, nil
 }
 
@@ -134,9 +188,21 @@ func (s *blockService) DeleteBlock(ctx context.Context, blockID, userID uuid.UUI
 		return apperror.Forbidden("dokumen terkunci, tidak dapat menghapus blok")
 	}
 
+	resume, err := suspendLive(ctx, s.live, doc.ID)
+	if err != nil {
+		return err
+	}
+	defer resume()
+
+	// The content is captured before the block goes away
+	captureEditVersion(ctx, s.versionSvc, doc.ID, userID)
+
 	if err := s.blockRepo.Delete(ctx, blockID); err != nil {
 		return err
 	}
+	if s.mentionSvc != nil {
+		_ = s.mentionSvc.Clear(ctx, blockID)
+	}
 
 	_ = s.historyRepo.Create(ctx, doc.ID, userID, "block_deleted", "Blok dihapus")
 	return nil
@@ -151,6 +217,12 @@ func (s *blockService) MoveBlock(ctx context.Context, docID uuid.UUID, blockID u
 		return apperror.Forbidden("dokumen terkunci, tidak dapat memindahkan blok")
 	}
 
+	resume, err := suspendLive(ctx, s.live, docID)
+	if err != nil {
+		return err
+	}
+	defer resume()
+
 	blocks, err := s.blockRepo.ListByDocument(ctx, docID)
 	if err != nil {
 		return err
@@ -199,6 +271,20 @@ func (s *blockService) GetBlocks(ctx context.Context, docID uuid.UUID) ([]*model
 	if blocks == nil {
 		blocks = []*model.Block{}
 	}
+
+	if s.mentionSvc != nil && len(blocks) > 0 {
+		ids := make([]uuid.UUID, len(blocks))
+		for i, b := range blocks {
+			ids[i] = b.ID
+		}
+		mentions, err := s.mentionSvc.ForItems(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range blocks {
+			b.Mentions = mentions[b.ID]
+		}
+	}
 	return blocks, nil
 }
 
@@ -211,11 +297,18 @@ func (s *blockService) ReorderBlocks(ctx context.Context, docID, userID uuid.UUI
 		return apperror.Forbidden("dokumen terkunci, tidak dapat mengurutkan ulang")
 	}
 
+	resume, err := suspendLive(ctx, s.live, docID)
+	if err != nil {
+		return err
+	}
+	defer resume()
+
 	if err := s.blockRepo.Reorder(ctx, docID, blockIDs); err != nil {
 		return err
 	}
 
 	_ = s.historyRepo.Create(ctx, docID, userID, "blocks_reordered", "Urutan blok diubah")
+	captureEditVersion(ctx, s.versionSvc, docID, userID)
 	return nil
 }
 
@@ -266,6 +359,44 @@ func (s *blockService) BatchUpdate(ctx context.Context, docID, userID uuid.UUID,
 	return nil
 }
 
+// resolveBlockMentions parses the mentions in block content. Only the document
+// owner and its collaborators can be mentioned.
+func (s *blockService) resolveBlockMentions(ctx context.Context, doc *model.Document, userID uuid.UUID, content string) ([]uuid.UUID, error) {
+	if !mentionPattern.MatchString(content) {
+		return nil, nil
+	}
+
+	allowed, err := mentionableUsers(ctx, s.docRepo, doc)
+	if err != nil {
+		return nil, err
+	}
+	return resolveMentions(content, userID, allowed)
+}
+
+// mentionableUsers returns the users who can be mentioned in a document:
+// its owner and collaborators.
+func mentionableUsers(ctx context.Context, docRepo repository.DocumentRepository, doc *model.Document) (map[uuid.UUID]bool, error) {
+	collabs, err := docRepo.ListCollaborators(ctx, doc.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list collaborators: %w", err)
+	}
+	allowed := map[uuid.UUID]bool{doc.OwnerID: true}
+	for _, c := range collabs {
+		allowed[c.UserID] = true
+	}
+	return allowed, nil
+}
+
+func blockMentionTarget(block *model.Block, userID uuid.UUID) model.MentionTarget {
+	return model.MentionTarget{
+		SourceType:  model.MentionSourceDocument,
+		SourceID:    block.DocumentID,
+		ItemID:      block.ID,
+		MentionedBy: userID,
+		Preview:     block.Content,
+	}
+}
+
 // validateBlockType checks if a block type is valid.
 func validateBlockType(bt model.BlockType) error {
 	switch bt {
@@ -10,13 +10,15 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/internal/ws"
+	"github.com/otoritech/chatat/pkg/apperror"
 )
 
 func newTestBlockService() (BlockService, *mockDocumentRepo, *mockBlockRepo) {
 	docRepo := newMockDocumentRepo()
 	blockRepo := newMockBlockRepo()
 	historyRepo := &mockDocHistoryRepo{}
-	svc := NewBlockService(blockRepo, docRepo, historyRepo)
+	svc := NewBlockService(blockRepo, docRepo, historyRepo, nil, nil, nil)
 	return svc, docRepo, blockRepo
 }
 
@@ -542,3 +544,105 @@ func TestBlockService_MoveBlock_ReorderError(t *testing.T) {
 	require.Error(t, err)
 	blockRepo.reorderErr = nil
 }
+
+func TestBlockService_Mentions(t *testing.T) {
+	ctx := context.Background()
+	docRepo := newMockDocumentRepo()
+	mentionRepo := newMockMentionRepo()
+	svc := NewBlockService(newMockBlockRepo(), docRepo, &mockDocHistoryRepo{}, NewMentionService(mentionRepo, newMockUserRepo(), nil), nil, nil)
+
+	ownerID := uuid.New()
+	collabID := uuid.New()
+	doc := createTestDoc(docRepo, ownerID)
+	_ = docRepo.AddCollaborator(ctx, doc.ID, collabID, model.CollaboratorRoleEditor)
+
+	t.Run("mention collaborator", func(t *testing.T) {
+		block, err := svc.AddBlock(ctx, doc.ID, ownerID, AddBlockInput{
+			Type:    model.BlockTypeParagraph,
+			Content: "Review by " + mentionToken("Collab", collabID),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{collabID}, block.Mentions)
+
+		mentions, err := mentionRepo.ListByUser(ctx, collabID, nil, 10)
+		require.NoError(t, err)
+		require.Len(t, mentions, 1)
+		assert.Equal(t, model.MentionSourceDocument, mentions[0].SourceType)
+		assert.Equal(t, doc.ID, mentions[0].SourceID)
+		assert.Equal(t, block.ID, mentions[0].ItemID)
+
+		// Removing the mention drops it from the feed
+		content := "Review later"
+		_, err = svc.UpdateBlock(ctx, block.ID, ownerID, model.UpdateBlockInput{Content: &content})
+		require.NoError(t, err)
+		mentions, err = mentionRepo.ListByUser(ctx, collabID, nil, 10)
+		require.NoError(t, err)
+		assert.Empty(t, mentions)
+	})
+
+	t.Run("mention outsider rejected", func(t *testing.T) {
+		_, err := svc.AddBlock(ctx, doc.ID, ownerID, AddBlockInput{
+			Type:    model.BlockTypeParagraph,
+			Content: "Hi " + mentionToken("Stranger", uuid.New()),
+		})
+		require.Error(t, err)
+	})
+}
+
+// fakeLiveDocuments records suspends and resumes of realtime editing.
+type fakeLiveDocuments struct {
+	err    error
+	events []string
+}
+
+func (l *fakeLiveDocuments) Suspend(_ context.Context, docID uuid.UUID) (func(), error) {
+	if l.err != nil {
+		return nil, l.err
+	}
+	l.events = append(l.events, "suspend "+docID.String())
+	return func() { l.events = append(l.events, "resume "+docID.String()) }, nil
+}
+
+func TestBlockService_SuspendsLiveDocuments(t *testing.T) {
+	ctx := context.Background()
+	docRepo := newMockDocumentRepo()
+	live := &fakeLiveDocuments{}
+	svc := NewBlockService(newMockBlockRepo(), docRepo, &mockDocHistoryRepo{}, nil, nil, live)
+	ownerID := uuid.New()
+	doc := createTestDoc(docRepo, ownerID)
+	cycle := []string{"suspend " + doc.ID.String(), "resume " + doc.ID.String()}
+
+	block, err := svc.AddBlock(ctx, doc.ID, ownerID, AddBlockInput{Type: model.BlockTypeParagraph, Content: "Satu"})
+	require.NoError(t, err)
+	other, err := svc.AddBlock(ctx, doc.ID, ownerID, AddBlockInput{Type: model.BlockTypeParagraph, Content: "Dua"})
+	require.NoError(t, err)
+	assert.Equal(t, append(cycle, cycle...), live.events)
+
+	writes := map[string]func() error{
+		"update": func() error {
+			content := "Satu lagi"
+			_, err := svc.UpdateBlock(ctx, block.ID, ownerID, model.UpdateBlockInput{Content: &content})
+			return err
+		},
+		"move":    func() error { return svc.MoveBlock(ctx, doc.ID, block.ID, 1) },
+		"reorder": func() error { return svc.ReorderBlocks(ctx, doc.ID, ownerID, []uuid.UUID{block.ID, other.ID}) },
+		"delete":  func() error { return svc.DeleteBlock(ctx, other.ID, ownerID) },
+	}
+	for _, name := range []string{"update", "move", "reorder", "delete"} {
+		t.Run(name, func(t *testing.T) {
+			live.events = nil
+			require.NoError(t, writes[name]())
+			assert.Equal(t, cycle, live.events)
+		})
+	}
+
+	t.Run("busy document is a conflict", func(t *testing.T) {
+		live.err = ws.ErrDocumentBusy
+		defer func() { live.err = nil }()
+
+		_, err := svc.AddBlock(ctx, doc.ID, ownerID, AddBlockInput{Type: model.BlockTypeParagraph, Content: "Tiga"})
+		assert.True(t, apperror.IsConflict(err))
+		blocks, _ := svc.GetBlocks(ctx, doc.ID)
+		assert.Len(t, blocks, 1)
+	})
+}
@@ -0,0 +1,386 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/internal/repository"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+const (
+	maxBroadcastListName       = 100
+	maxBroadcastListRecipients = 256
+)
+
+// CreateBroadcastListInput holds data for creating a broadcast list.
+type CreateBroadcastListInput struct {
+	Name         string      `json:"name"`
+	RecipientIDs []uuid.UUID `json:"recipientIds"`
+}
+
+// SendBroadcastInput holds the message sent to every recipient of a list.
+type SendBroadcastInput struct {
+	Content  string            `json:"content"`
+	Type     model.MessageType `json:"type"`
+	Metadata json.RawMessage   `json:"metadata"`
+}
+
+// BroadcastService defines operations for broadcast lists.
+type BroadcastService interface {
+	CreateList(ctx context.Context, userID uuid.UUID, input CreateBroadcastListInput) (*model.BroadcastList, error)
+	ListLists(ctx context.Context, userID uuid.UUID) ([]*model.BroadcastList, error)
+	GetList(ctx context.Context, listID, userID uuid.UUID) (*model.BroadcastList, error)
+	RenameList(ctx context.Context, listID, userID uuid.UUID, name string) (*model.BroadcastList, error)
+	DeleteList(ctx context.Context, listID, userID uuid.UUID) error
+	AddRecipients(ctx context.Context, listID, userID uuid.UUID, recipientIDs []uuid.UUID) (*model.BroadcastList, error)
+	RemoveRecipient(ctx context.Context, listID, userID, recipientID uuid.UUID) error
+	// Send delivers the message to each recipient's personal chat with the
+	// owner. Recipients who have not saved the owner as a contact are skipped.
+	Send(ctx context.Context, listID, userID uuid.UUID, input SendBroadcastInput) (*model.Broadcast, error)
+	ListBroadcasts(ctx context.Context, listID, userID uuid.UUID) ([]*model.Broadcast, error)
+	GetBroadcast(ctx context.Context, listID, broadcastID, userID uuid.UUID) (*model.Broadcast, error)
+}
+
+type broadcastService struct {
+	broadcastRepo repository.BroadcastRepository
+	contactRepo   repository.ContactRepository
+	userRepo      repository.UserRepository
+	chatSvc       ChatService
+	messageSvc    MessageService
+}
+
+// NewBroadcastService creates a new BroadcastService. Each copy of a
+// broadcast is sent through chatSvc and messageSvc like any personal message.
+func NewBroadcastService(
+	broadcastRepo repository.BroadcastRepository,
+	contactRepo repository.ContactRepository,
+	userRepo repository.UserRepository,
+	chatSvc ChatService,
+	messageSvc MessageService,
+) BroadcastService {
+	return &broadcastService{
+		broadcastRepo: broadcastRepo,
+		contactRepo:   contactRepo,
+		userRepo:      userRepo,
+		chatSvc:       chatSvc,
+		messageSvc:    messageSvc,
+	}
+}
+
+func (s *broadcastService) CreateList(ctx context.Context, userID uuid.UUID, input CreateBroadcastListInput) (*model.BroadcastList, error) {
+	if err := validateBroadcastListName(input.Name); err != nil {
+		return nil, err
+	}
+
+	recipientIDs, err := s.validateRecipients(ctx, userID, nil, input.RecipientIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.broadcastRepo.CreateList(ctx, userID, input.Name)
+	if err != nil {
+		return nil, fmt.Errorf("create broadcast list: %w", err)
+	}
+	if err := s.broadcastRepo.AddRecipients(ctx, list.ID, recipientIDs); err != nil {
+		return nil, fmt.Errorf("add recipients: %w", err)
+	}
+
+	list.RecipientIDs = recipientIDs
+	return list, nil
+}
+
+func (s *broadcastService) ListLists(ctx context.Context, userID uuid.UUID) ([]*model.BroadcastList, error) {
+	lists, err := s.broadcastRepo.ListByOwner(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list broadcast lists: %w", err)
+	}
+
+	listIDs := make([]uuid.UUID, len(lists))
+	for i, l := range lists {
+		listIDs[i] = l.ID
+	}
+	recipients, err := s.broadcastRepo.ListRecipients(ctx, listIDs)
+	if err != nil {
+		return nil, fmt.Errorf("list recipients: %w", err)
+	}
+
+	for _, l := range lists {
+		l.RecipientIDs = recipientsOrEmpty(recipients[l.ID])
+	}
+	if lists == nil {
+		lists = []*model.BroadcastList{}
+	}
+
+	return lists, nil
+}
+
+func (s *broadcastService) GetList(ctx context.Context, listID, userID uuid.UUID) (*model.BroadcastList, error) {
+	return s.ownedList(ctx, listID, userID)
+}
+
+func (s *broadcastService) RenameList(ctx context.Context, listID, userID uuid.UUID, name string) (*model.BroadcastList, error) {
+	if err := validateBroadcastListName(name); err != nil {
+		return nil, err
+	}
+
+	list, err := s.ownedList(ctx, listID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	renamed, err := s.broadcastRepo.RenameList(ctx, listID, name)
+	if err != nil {
+		return nil, fmt.Errorf("rename broadcast list: %w", err)
+	}
+
+	renamed.RecipientIDs = list.RecipientIDs
+	return renamed, nil
+}
+
+func (s *broadcastService) DeleteList(ctx context.Context, listID, userID uuid.UUID) error {
+	if _, err := s.ownedList(ctx, listID, userID); err != nil {
+		return err
+	}
+
+	if err := s.broadcastRepo.DeleteList(ctx, listID); err != nil {
+		return fmt.Errorf("delete broadcast list: %w", err)
+	}
+
+	return nil
+}
+
+func (s *broadcastService) AddRecipients(ctx context.Context, listID, userID uuid.UUID, recipientIDs []uuid.UUID) (*model.BroadcastList, error) {
+	if len(recipientIDs) == 0 {
+		return nil, apperror.Validation("recipientIds", "at least one recipient is required")
+	}
+
+	list, err := s.ownedList(ctx, listID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	added, err := s.validateRecipients(ctx, userID, list.RecipientIDs, recipientIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.broadcastRepo.AddRecipients(ctx, listID, added); err != nil {
+		return nil, fmt.Errorf("add recipients: %w", err)
+	}
+
+	list.RecipientIDs = append(list.RecipientIDs, added...)
+	return list, nil
+}
+
+func (s *broadcastService) RemoveRecipient(ctx context.Context, listID, userID, recipientID uuid.UUID) error {
+	if _, err := s.ownedList(ctx, listID, userID); err != nil {
+		return err
+	}
+
+	if err := s.broadcastRepo.RemoveRecipient(ctx, listID, recipientID); err != nil {
+		return fmt.Errorf("remove recipient: %w", err)
+	}
+
+	return nil
+}
+
+func (s *broadcastService) Send(ctx context.Context, listID, userID uuid.UUID, input SendBroadcastInput) (*model.Broadcast, error) {
+	if input.Type == "" {
+		input.Type = model.MessageTypeText
+	}
+	if input.Type == model.MessageTypeText && input.Content == "" {
+		return nil, apperror.Validation("content", "message content cannot be empty")
+	}
+	// System and poll messages are never sent by users directly
+	if input.Type == model.MessageTypeSystem || input.Type == model.MessageTypePoll {
+		return nil, apperror.Validation("type", "this message type cannot be broadcast")
+	}
+	// Validate once up front so a bad payload fails the whole broadcast
+	// instead of being skipped for every recipient
+	if _, _, err := prepareMessageMetadata(ctx, nil, input.Type, input.Content, input.Metadata); err != nil {
+		return nil, err
+	}
+
+	list, err := s.ownedList(ctx, listID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(list.RecipientIDs) == 0 {
+		return nil, apperror.BadRequest("broadcast list has no recipients")
+	}
+
+	var deliveries []model.BroadcastDelivery
+	skipped := 0
+	for _, recipientID := range list.RecipientIDs {
+		// Only recipients who saved the sender get broadcasts, as with SMS
+		// broadcast lists; everyone else would be receiving unsolicited messages
+		saved, err := s.contactRepo.IsContact(ctx, recipientID, userID)
+		if err != nil {
+			return nil, fmt.Errorf("check recipient contacts: %w", err)
+		}
+		if !saved {
+			skipped++
+			continue
+		}
+
+		msg, err := s.sendTo(ctx, userID, recipientID, input)
+		if err != nil {
+			// A recipient who blocked the sender is skipped, not fatal; any
+			// other failure aborts the broadcast
+			var appErr *apperror.AppError
+			if !errors.As(err, &appErr) || !isClientError(appErr) {
+				return nil, fmt.Errorf("send broadcast: %w", err)
+			}
+			log.Warn().Err(err).
+				Str("list_id", listID.String()).
+				Str("recipient_id", recipientID.String()).
+				Msg("failed to send broadcast to recipient")
+			skipped++
+			continue
+		}
+		deliveries = append(deliveries, model.BroadcastDelivery{MessageID: msg.ID, RecipientID: recipientID})
+	}
+
+	broadcast, err := s.broadcastRepo.CreateBroadcast(ctx, model.CreateBroadcastInput{
+		ListID:       listID,
+		SenderID:     userID,
+		Content:      input.Content,
+		Type:         input.Type,
+		Metadata:     input.Metadata,
+		SkippedCount: skipped,
+		Deliveries:   deliveries,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("record broadcast: %w", err)
+	}
+
+	return broadcast, nil
+}
+
+func (s *broadcastService) ListBroadcasts(ctx context.Context, listID, userID uuid.UUID) ([]*model.Broadcast, error) {
+	if _, err := s.ownedList(ctx, listID, userID); err != nil {
+		return nil, err
+	}
+
+	broadcasts, err := s.broadcastRepo.ListBroadcasts(ctx, listID)
+	if err != nil {
+		return nil, fmt.Errorf("list broadcasts: %w", err)
+	}
+	if broadcasts == nil {
+		broadcasts = []*model.Broadcast{}
+	}
+
+	return broadcasts, nil
+}
+
+func (s *broadcastService) GetBroadcast(ctx context.Context, listID, broadcastID, userID uuid.UUID) (*model.Broadcast, error) {
+	if _, err := s.ownedList(ctx, listID, userID); err != nil {
+		return nil, err
+	}
+
+	broadcast, err := s.broadcastRepo.FindBroadcast(ctx, broadcastID)
+	if err != nil {
+		return nil, fmt.Errorf("find broadcast: %w", err)
+	}
+	if broadcast.ListID != listID {
+		return nil, apperror.NotFound("broadcast", broadcastID.String())
+	}
+
+	return broadcast, nil
+}
+
+// --- Helper Methods ---
+
+// ownedList loads a list with its recipients, hiding lists owned by other
+// users as not found.
+func (s *broadcastService) ownedList(ctx context.Context, listID, userID uuid.UUID) (*model.BroadcastList, error) {
+	list, err := s.broadcastRepo.FindListByID(ctx, listID)
+	if err != nil {
+		return nil, fmt.Errorf("find broadcast list: %w", err)
+	}
+	if list.OwnerID != userID {
+		return nil, apperror.NotFound("broadcast list", listID.String())
+	}
+
+	recipients, err := s.broadcastRepo.ListRecipients(ctx, []uuid.UUID{listID})
+	if err != nil {
+		return nil, fmt.Errorf("list recipients: %w", err)
+	}
+	list.RecipientIDs = recipientsOrEmpty(recipients[listID])
+
+	return list, nil
+}
+
+// validateRecipients returns the users in ids that are not already in
+// existing, after checking that each one exists, is not the owner and that
+// the list stays within its size limit.
+func (s *broadcastService) validateRecipients(ctx context.Context, ownerID uuid.UUID, existing, ids []uuid.UUID) ([]uuid.UUID, error) {
+	seen := make(map[uuid.UUID]bool, len(existing)+len(ids))
+	for _, id := range existing {
+		seen[id] = true
+	}
+
+	added := []uuid.UUID{}
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		if id == ownerID {
+			return nil, apperror.Validation("recipientIds", "cannot add yourself to a broadcast list")
+		}
+		if _, err := s.userRepo.FindByID(ctx, id); err != nil {
+			if apperror.IsNotFound(err) {
+				return nil, apperror.NotFound("user", id.String())
+			}
+			return nil, fmt.Errorf("verify recipient: %w", err)
+		}
+		seen[id] = true
+		added = append(added, id)
+	}
+
+	if len(existing)+len(added) > maxBroadcastListRecipients {
+		return nil, apperror.Validation("recipientIds", fmt.Sprintf("a broadcast list can have at most %d recipients", maxBroadcastListRecipients))
+	}
+
+	return added, nil
+}
+
+// sendTo sends one copy of a broadcast into the personal chat between the
+// sender and the recipient.
+func (s *broadcastService) sendTo(ctx context.Context, senderID, recipientID uuid.UUID, input SendBroadcastInput) (*model.Message, error) {
+	chat, err := s.chatSvc.GetOrCreatePersonalChat(ctx, senderID, recipientID)
+	if err != nil {
+		return nil, fmt.Errorf("get personal chat: %w", err)
+	}
+
+	return s.messageSvc.SendMessage(ctx, SendMessageInput{
+		ChatID:   chat.ID,
+		SenderID: senderID,
+		Content:  input.Content,
+		Type:     input.Type,
+		Metadata: input.Metadata,
+	})
+}
+
+func validateBroadcastListName(name string) error {
+	if name == "" {
+		return apperror.Validation("name", "broadcast list name is required")
+	}
+	if len(name) > maxBroadcastListName {
+		return apperror.Validation("name", fmt.Sprintf("broadcast list name must be at most %d characters", maxBroadcastListName))
+	}
+	return nil
+}
+
+func recipientsOrEmpty(ids []uuid.UUID) []uuid.UUID {
+	if ids == nil {
+		return []uuid.UUID{}
+	}
+	return ids
+}
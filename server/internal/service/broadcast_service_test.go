@@ -0,0 +1,343 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+type mockBroadcastRepo struct {
+	lists      map[uuid.UUID]*model.BroadcastList
+	recipients map[uuid.UUID][]uuid.UUID
+	broadcasts map[uuid.UUID]*model.Broadcast
+}
+
+func newMockBroadcastRepo() *mockBroadcastRepo {
+	return &mockBroadcastRepo{
+		lists:      make(map[uuid.UUID]*model.BroadcastList),
+		recipients: make(map[uuid.UUID][]uuid.UUID),
+		broadcasts: make(map[uuid.UUID]*model.Broadcast),
+	}
+}
+
+func (m *mockBroadcastRepo) CreateList(_ context.Context, ownerID uuid.UUID, name string) (*model.BroadcastList, error) {
+	list := &model.BroadcastList{ID: uuid.New(), OwnerID: ownerID, Name: name, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	m.lists[list.ID] = list
+	return list, nil
+}
+
+func (m *mockBroadcastRepo) FindListByID(_ context.Context, id uuid.UUID) (*model.BroadcastList, error) {
+	list, ok := m.lists[id]
+	if !ok {
+		return nil, apperror.NotFound("broadcast list", id.String())
+	}
+	cp := *list
+	return &cp, nil
+}
+
+func (m *mockBroadcastRepo) ListByOwner(_ context.Context, ownerID uuid.UUID) ([]*model.BroadcastList, error) {
+	var lists []*model.BroadcastList
+	for _, l := range m.lists {
+		if l.OwnerID == ownerID {
+			cp := *l
+			lists = append(lists, &cp)
+		}
+	}
+	return lists, nil
+}
+
+func (m *mockBroadcastRepo) RenameList(_ context.Context, id uuid.UUID, name string) (*model.BroadcastList, error) {
+	list, ok := m.lists[id]
+	if !ok {
+		return nil, apperror.NotFound("broadcast list", id.String())
+	}
+	list.Name = name
+	cp := *list
+	return &cp, nil
+}
+
+func (m *mockBroadcastRepo) DeleteList(_ context.Context, id uuid.UUID) error {
+	if _, ok := m.lists[id]; !ok {
+		return apperror.NotFound("broadcast list", id.String())
+	}
+	delete(m.lists, id)
+	delete(m.recipients, id)
+	return nil
+}
+
+func (m *mockBroadcastRepo) AddRecipients(_ context.Context, listID uuid.UUID, userIDs []uuid.UUID) error {
+	m.recipients[listID] = append(m.recipients[listID], userIDs...)
+	return nil
+}
+
+func (m *mockBroadcastRepo) RemoveRecipient(_ context.Context, listID, userID uuid.UUID) error {
+	ids := m.recipients[listID]
+	for i, id := range ids {
+		if id == userID {
+			m.recipients[listID] = append(ids[:i:i], ids[i+1:]...)
+			return nil
+		}
+	}
+	return apperror.NotFound("broadcast recipient", userID.String())
+}
+
+func (m *mockBroadcastRepo) ListRecipients(_ context.Context, listIDs []uuid.UUID) (map[uuid.UUID][]uuid.UUID, error) {
+	result := make(map[uuid.UUID][]uuid.UUID)
+	for _, id := range listIDs {
+		if ids, ok := m.recipients[id]; ok {
+			result[id] = append([]uuid.UUID(nil), ids...)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockBroadcastRepo) CreateBroadcast(_ context.Context, input model.CreateBroadcastInput) (*model.Broadcast, error) {
+	b := &model.Broadcast{
+		ID:           uuid.New(),
+		ListID:       input.ListID,
+		SenderID:     input.SenderID,
+		Content:      input.Content,
+		Type:         input.Type,
+		Metadata:     input.Metadata,
+		SkippedCount: input.SkippedCount,
+		Stats:        model.BroadcastStats{Sent: len(input.Deliveries)},
+		CreatedAt:    time.Now(),
+	}
+	m.broadcasts[b.ID] = b
+	return b, nil
+}
+
+func (m *mockBroadcastRepo) FindBroadcast(_ context.Context, id uuid.UUID) (*model.Broadcast, error) {
+	b, ok := m.broadcasts[id]
+	if !ok {
+		return nil, apperror.NotFound("broadcast", id.String())
+	}
+	return b, nil
+}
+
+func (m *mockBroadcastRepo) ListBroadcasts(_ context.Context, listID uuid.UUID) ([]*model.Broadcast, error) {
+	var broadcasts []*model.Broadcast
+	for _, b := range m.broadcasts {
+		if b.ListID == listID {
+			broadcasts = append(broadcasts, b)
+		}
+	}
+	return broadcasts, nil
+}
+
+// ==================== BroadcastService Tests ====================
+
+func TestBroadcastService_Lists(t *testing.T) {
+	ctx := context.Background()
+	contactRepo := newMockContactRepo()
+	userRepo := newMockUserRepo()
+	chatRepo := newMockChatRepo()
+	msgRepo := newMockMessageRepo()
+	msgStatRepo := newMockMessageStatRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	chatSvc := NewChatService(chatRepo, msgRepo, msgStatRepo, userRepo, nil, nil, hub)
+	msgSvc := NewMessageService(msgRepo, msgStatRepo, newMockReactionRepo(), newMockThreadRepo(), chatRepo, nil, nil, hub, nil, nil, DefaultMessageConfig())
+	svc := NewBroadcastService(newMockBroadcastRepo(), contactRepo, userRepo, chatSvc, msgSvc)
+
+	owner := uuid.New()
+	a := uuid.New()
+	b := uuid.New()
+	c := uuid.New()
+	userRepo.addUser(&model.User{ID: owner, Phone: "+628111", Name: "Owner"})
+	userRepo.addUser(&model.User{ID: a, Phone: "+628222", Name: "A"})
+	userRepo.addUser(&model.User{ID: b, Phone: "+628333", Name: "B"})
+	userRepo.addUser(&model.User{ID: c, Phone: "+628444", Name: "C"})
+	_ = contactRepo.Upsert(ctx, a, owner, "Owner")
+	_ = contactRepo.Upsert(ctx, b, owner, "Owner")
+
+	list, err := svc.CreateList(ctx, owner, CreateBroadcastListInput{Name: "Pelanggan", RecipientIDs: []uuid.UUID{a, b, a}})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{a, b}, list.RecipientIDs)
+
+	t.Run("list and get", func(t *testing.T) {
+		lists, err := svc.ListLists(ctx, owner)
+		require.NoError(t, err)
+		require.Len(t, lists, 1)
+		assert.Equal(t, []uuid.UUID{a, b}, lists[0].RecipientIDs)
+
+		got, err := svc.GetList(ctx, list.ID, owner)
+		require.NoError(t, err)
+		assert.Equal(t, "Pelanggan", got.Name)
+	})
+
+	t.Run("other users cannot see the list", func(t *testing.T) {
+		_, err := svc.GetList(ctx, list.ID, a)
+		assert.True(t, apperror.IsNotFound(err))
+		err = svc.DeleteList(ctx, list.ID, a)
+		assert.True(t, apperror.IsNotFound(err))
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		renamed, err := svc.RenameList(ctx, list.ID, owner, "VIP")
+		require.NoError(t, err)
+		assert.Equal(t, "VIP", renamed.Name)
+		assert.Len(t, renamed.RecipientIDs, 2)
+
+		_, err = svc.RenameList(ctx, list.ID, owner, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "name")
+	})
+
+	t.Run("add and remove recipients", func(t *testing.T) {
+		updated, err := svc.AddRecipients(ctx, list.ID, owner, []uuid.UUID{b, c})
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{a, b, c}, updated.RecipientIDs)
+
+		require.NoError(t, svc.RemoveRecipient(ctx, list.ID, owner, c))
+		err = svc.RemoveRecipient(ctx, list.ID, owner, c)
+		assert.True(t, apperror.IsNotFound(err))
+	})
+
+	t.Run("invalid recipients", func(t *testing.T) {
+		_, err := svc.AddRecipients(ctx, list.ID, owner, []uuid.UUID{owner})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "yourself")
+
+		_, err = svc.AddRecipients(ctx, list.ID, owner, []uuid.UUID{uuid.New()})
+		assert.True(t, apperror.IsNotFound(err))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, svc.DeleteList(ctx, list.ID, owner))
+		lists, err := svc.ListLists(ctx, owner)
+		require.NoError(t, err)
+		assert.Empty(t, lists)
+	})
+}
+
+func TestBroadcastService_Send(t *testing.T) {
+	ctx := context.Background()
+	contactRepo := newMockContactRepo()
+	userRepo := newMockUserRepo()
+	chatRepo := newMockChatRepo()
+	msgRepo := newMockMessageRepo()
+	msgStatRepo := newMockMessageStatRepo()
+	blockRepo := newMockUserBlockRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	chatSvc := NewChatService(chatRepo, msgRepo, msgStatRepo, userRepo, blockRepo, nil, hub)
+	msgSvc := NewMessageService(msgRepo, msgStatRepo, newMockReactionRepo(), newMockThreadRepo(), chatRepo, nil, blockRepo, hub, nil, nil, DefaultMessageConfig())
+	svc := NewBroadcastService(newMockBroadcastRepo(), contactRepo, userRepo, chatSvc, msgSvc)
+
+	owner := uuid.New()
+	saved := uuid.New()
+	unsaved := uuid.New()
+	blocker := uuid.New()
+	userRepo.addUser(&model.User{ID: owner, Phone: "+628111", Name: "Owner"})
+	userRepo.addUser(&model.User{ID: saved, Phone: "+628222", Name: "Saved"})
+	userRepo.addUser(&model.User{ID: unsaved, Phone: "+628333", Name: "Unsaved"})
+	userRepo.addUser(&model.User{ID: blocker, Phone: "+628444", Name: "Blocker"})
+	_ = contactRepo.Upsert(ctx, saved, owner, "Owner")
+	_ = contactRepo.Upsert(ctx, blocker, owner, "Owner")
+	require.NoError(t, blockRepo.Block(ctx, blocker, owner))
+
+	list, err := svc.CreateList(ctx, owner, CreateBroadcastListInput{Name: "Semua", RecipientIDs: []uuid.UUID{saved, unsaved, blocker}})
+	require.NoError(t, err)
+
+	broadcast, err := svc.Send(ctx, list.ID, owner, SendBroadcastInput{Content: "Promo hari ini"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, broadcast.Stats.Sent)
+	assert.Equal(t, 2, broadcast.SkippedCount)
+	assert.Equal(t, model.MessageTypeText, broadcast.Type)
+
+	t.Run("only recipients who saved the sender get the message", func(t *testing.T) {
+		chat, err := chatRepo.FindPersonalChat(ctx, owner, saved)
+		require.NoError(t, err)
+		msgs := msgRepo.byChat[chat.ID]
+		require.Len(t, msgs, 1)
+		assert.Equal(t, "Promo hari ini", msgs[0].Content)
+
+		_, err = chatRepo.FindPersonalChat(ctx, owner, unsaved)
+		assert.True(t, apperror.IsNotFound(err))
+	})
+
+	t.Run("list and get broadcasts", func(t *testing.T) {
+		broadcasts, err := svc.ListBroadcasts(ctx, list.ID, owner)
+		require.NoError(t, err)
+		require.Len(t, broadcasts, 1)
+
+		got, err := svc.GetBroadcast(ctx, list.ID, broadcast.ID, owner)
+		require.NoError(t, err)
+		assert.Equal(t, broadcast.ID, got.ID)
+	})
+}
+
+func TestBroadcastService_Send_Errors(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockBroadcastRepo()
+	contactRepo := newMockContactRepo()
+	userRepo := newMockUserRepo()
+	chatRepo := newMockChatRepo()
+	msgRepo := newMockMessageRepo()
+	msgStatRepo := newMockMessageStatRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	chatSvc := NewChatService(chatRepo, msgRepo, msgStatRepo, userRepo, nil, nil, hub)
+	msgSvc := NewMessageService(msgRepo, msgStatRepo, newMockReactionRepo(), newMockThreadRepo(), chatRepo, nil, nil, hub, nil, nil, DefaultMessageConfig())
+	svc := NewBroadcastService(repo, contactRepo, userRepo, chatSvc, msgSvc)
+
+	owner := uuid.New()
+	recipient := uuid.New()
+	userRepo.addUser(&model.User{ID: owner, Phone: "+628111", Name: "Owner"})
+	userRepo.addUser(&model.User{ID: recipient, Phone: "+628222", Name: "A"})
+	_ = contactRepo.Upsert(ctx, recipient, owner, "Owner")
+
+	empty, err := svc.CreateList(ctx, owner, CreateBroadcastListInput{Name: "Kosong"})
+	require.NoError(t, err)
+	list, err := svc.CreateList(ctx, owner, CreateBroadcastListInput{Name: "Satu", RecipientIDs: []uuid.UUID{recipient}})
+	require.NoError(t, err)
+
+	t.Run("validation", func(t *testing.T) {
+		_, err := svc.Send(ctx, empty.ID, owner, SendBroadcastInput{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "content")
+
+		_, err = svc.Send(ctx, empty.ID, owner, SendBroadcastInput{Content: "Halo"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no recipients")
+
+		_, err = svc.Send(ctx, empty.ID, uuid.New(), SendBroadcastInput{Content: "Halo"})
+		assert.True(t, apperror.IsNotFound(err))
+	})
+
+	t.Run("invalid payload fails before any delivery", func(t *testing.T) {
+		_, err := svc.Send(ctx, list.ID, owner, SendBroadcastInput{Type: model.MessageTypeLocation, Content: "Lokasi"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "metadata")
+
+		_, err = svc.Send(ctx, list.ID, owner, SendBroadcastInput{Type: model.MessageTypePoll, Content: "Pilih"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "type")
+
+		_, err = chatRepo.FindPersonalChat(ctx, owner, recipient)
+		assert.True(t, apperror.IsNotFound(err))
+		assert.Empty(t, repo.broadcasts)
+	})
+
+	t.Run("internal errors abort the broadcast", func(t *testing.T) {
+		msgRepo.createErr = errors.New("db error")
+		defer func() { msgRepo.createErr = nil }()
+
+		_, err := svc.Send(ctx, list.ID, owner, SendBroadcastInput{Content: "Halo"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "db error")
+		assert.Empty(t, repo.broadcasts)
+	})
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/google/uuid"
@@ -14,11 +15,20 @@ import (
 
 // ChatListItem represents a chat in the user's chat list with metadata.
 type ChatListItem struct {
-	Chat        model.Chat     `json:"chat"`
-	LastMessage *model.Message `json:"lastMessage"`
-	UnreadCount int            `json:"unreadCount"`
-	OtherUser   *model.User    `json:"otherUser,omitempty"` // for personal chats
-	IsOnline    bool           `json:"isOnline"`
+	Chat        model.Chat         `json:"chat"`
+	Settings    model.ChatSettings `json:"settings"`
+	LastMessage *model.Message     `json:"lastMessage"`
+	UnreadCount int                `json:"unreadCount"`
+	OtherUser   *model.User        `json:"otherUser,omitempty"` // for personal chats
+	IsOnline    bool               `json:"isOnline"`
+}
+
+// ChatListFilter narrows the chat list. The zero value lists every chat that
+// is not archived.
+type ChatListFilter struct {
+	Archived bool           // list archived chats instead of the main list
+	Unread   bool           // only chats with unread messages
+	Type     model.ChatType // only chats of this type, if set
 }
 
 // ChatDetail represents detailed chat information with members.
@@ -27,14 +37,35 @@ type ChatDetail struct {
 	Members []*model.User `json:"members"`
 }
 
+// Disappearing message timers a chat may use, in seconds. 0 turns them off.
+const (
+	DisappearingOff     = 0
+	Disappearing24Hours = 24 * 60 * 60
+	Disappearing7Days   = 7 * Disappearing24Hours
+	Disappearing90Days  = 90 * Disappearing24Hours
+)
+
+var disappearingTimers = map[int]bool{
+	DisappearingOff:     true,
+	Disappearing24Hours: true,
+	Disappearing7Days:   true,
+	Disappearing90Days:  true,
+}
+
 // ChatService defines operations for chat management.
 type ChatService interface {
 	CreatePersonalChat(ctx context.Context, userID, contactID uuid.UUID) (*model.Chat, error)
 	GetOrCreatePersonalChat(ctx context.Context, userID, contactID uuid.UUID) (*model.Chat, error)
-	ListChats(ctx context.Context, userID uuid.UUID) ([]*ChatListItem, error)
+	ListChats(ctx context.Context, userID uuid.UUID, filter ChatListFilter) ([]*ChatListItem, error)
 	GetChat(ctx context.Context, chatID, userID uuid.UUID) (*ChatDetail, error)
 	PinChat(ctx context.Context, chatID, userID uuid.UUID) error
 	UnpinChat(ctx context.Context, chatID, userID uuid.UUID) error
+	// UpdateSettings changes the user's own pin, archive, mute and sound
+	// settings for the chat.
+	UpdateSettings(ctx context.Context, chatID, userID uuid.UUID, input model.UpdateChatSettingsInput) (*model.ChatSettings, error)
+	// SetDisappearingTimer changes how long new messages in the chat live.
+	// Only admins may change it in group chats.
+	SetDisappearingTimer(ctx context.Context, chatID, userID uuid.UUID, seconds int) (*model.Chat, error)
 	IsMember(ctx context.Context, chatID, userID uuid.UUID) (bool, error)
 }
 
@@ -43,6 +74,8 @@ type chatService struct {
 	messageRepo     repository.MessageRepository
 	messageStatRepo repository.MessageStatusRepository
 	userRepo        repository.UserRepository
+	blockRepo       repository.UserBlockRepository
+	privacy         PrivacyPolicy
 	hub             *ws.Hub
 }
 
@@ -52,6 +85,8 @@ func NewChatService(
 	messageRepo repository.MessageRepository,
 	messageStatRepo repository.MessageStatusRepository,
 	userRepo repository.UserRepository,
+	blockRepo repository.UserBlockRepository,
+	privacy PrivacyPolicy,
 	hub *ws.Hub,
 ) ChatService {
 	return &chatService{
@@ -59,6 +94,8 @@ func NewChatService(
 		messageRepo:     messageRepo,
 		messageStatRepo: messageStatRepo,
 		userRepo:        userRepo,
+		blockRepo:       blockRepo,
+		privacy:         privacy,
 		hub:             hub,
 	}
 }
@@ -77,6 +114,14 @@ func (s *chatService) CreatePersonalChat(ctx context.Context, userID, contactID
 		return nil, fmt.Errorf("find contact user: %w", err)
 	}
 
+	blocked, err := isBlocked(ctx, s.blockRepo, userID, contactID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, apperror.Forbidden("cannot start a chat with this user")
+	}
+
 	// Create chat
 	chat, err := s.chatRepo.Create(ctx, model.CreateChatInput{
 		Type:      model.ChatTypePersonal,
@@ -116,7 +161,7 @@ func (s *chatService) GetOrCreatePersonalChat(ctx context.Context, userID, conta
 	return nil, fmt.Errorf("find personal chat: %w", err)
 }
 
-func (s *chatService) ListChats(ctx context.Context, userID uuid.UUID) ([]*ChatListItem, error) {
+func (s *chatService) ListChats(ctx context.Context, userID uuid.UUID, filter ChatListFilter) ([]*ChatListItem, error) {
 	chatsWithMsg, err := s.chatRepo.ListByUser(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("list chats: %w", err)
@@ -124,8 +169,16 @@ func (s *chatService) ListChats(ctx context.Context, userID uuid.UUID) ([]*ChatL
 
 	items := make([]*ChatListItem, 0, len(chatsWithMsg))
 	for _, cwm := range chatsWithMsg {
+		if (cwm.Settings.ArchivedAt != nil) != filter.Archived {
+			continue
+		}
+		if filter.Type != "" && cwm.Chat.Type != filter.Type {
+			continue
+		}
+
 		item := &ChatListItem{
-			Chat: cwm.Chat,
+			Chat:     cwm.Chat,
+			Settings: cwm.Settings,
 		}
 
 		// Get unread count
@@ -133,6 +186,9 @@ func (s *chatService) ListChats(ctx context.Context, userID uuid.UUID) ([]*ChatL
 		if err != nil {
 			return nil, fmt.Errorf("get unread count: %w", err)
 		}
+		if filter.Unread && unread == 0 {
+			continue
+		}
 		item.UnreadCount = unread
 
 		// Get last message
@@ -159,8 +215,12 @@ func (s *chatService) ListChats(ctx context.Context, userID uuid.UUID) ([]*ChatL
 						}
 						return nil, fmt.Errorf("get other user: %w", err)
 					}
-					item.OtherUser = otherUser
-					item.IsOnline = s.hub.IsOnline(otherUser.ID)
+					vis, err := visibilityOf(ctx, s.privacy, userID, otherUser)
+					if err != nil {
+						return nil, fmt.Errorf("check other user privacy: %w", err)
+					}
+					item.OtherUser = vis.Apply(otherUser)
+					item.IsOnline = vis.Online && s.hub.IsOnline(otherUser.ID)
 					break
 				}
 			}
@@ -205,7 +265,11 @@ func (s *chatService) GetChat(ctx context.Context, chatID, userID uuid.UUID) (*C
 			}
 			return nil, fmt.Errorf("get member user: %w", err)
 		}
-		users = append(users, user)
+		vis, err := visibilityOf(ctx, s.privacy, userID, user)
+		if err != nil {
+			return nil, fmt.Errorf("check member privacy: %w", err)
+		}
+		users = append(users, vis.Apply(user))
 	}
 
 	return &ChatDetail{
@@ -215,42 +279,104 @@ func (s *chatService) GetChat(ctx context.Context, chatID, userID uuid.UUID) (*C
 }
 
 func (s *chatService) PinChat(ctx context.Context, chatID, userID uuid.UUID) error {
-	isMember, err := s.IsMember(ctx, chatID, userID)
+	pinned := true
+	_, err := s.UpdateSettings(ctx, chatID, userID, model.UpdateChatSettingsInput{Pinned: &pinned})
+	return err
+}
+
+func (s *chatService) UnpinChat(ctx context.Context, chatID, userID uuid.UUID) error {
+	pinned := false
+	_, err := s.UpdateSettings(ctx, chatID, userID, model.UpdateChatSettingsInput{Pinned: &pinned})
+	return err
+}
+
+func (s *chatService) UpdateSettings(ctx context.Context, chatID, userID uuid.UUID, input model.UpdateChatSettingsInput) (*model.ChatSettings, error) {
+	if input.NotificationSound != nil && len(*input.NotificationSound) > 100 {
+		return nil, apperror.Validation("notificationSound", "notification sound must be at most 100 characters")
+	}
+
+	settings, err := s.chatRepo.UpdateSettings(ctx, chatID, userID, input)
 	if err != nil {
-		return err
+		if apperror.IsNotFound(err) {
+			return nil, apperror.Forbidden("you are not a member of this chat")
+		}
+		return nil, fmt.Errorf("update chat settings: %w", err)
 	}
-	if !isMember {
-		return apperror.Forbidden("you are not a member of this chat")
+	return settings, nil
+}
+
+func (s *chatService) SetDisappearingTimer(ctx context.Context, chatID, userID uuid.UUID, seconds int) (*model.Chat, error) {
+	if !disappearingTimers[seconds] {
+		return nil, apperror.Validation("disappearingTimer", "timer must be off, 24 hours, 7 days or 90 days")
 	}
 
-	_, err = s.chatRepo.FindByID(ctx, chatID)
+	chat, err := s.chatRepo.FindByID(ctx, chatID)
 	if err != nil {
-		return fmt.Errorf("find chat: %w", err)
+		return nil, err
 	}
 
-	// Update pinned_at timestamp
-	now := "NOW()"
-	_ = now
-	// Use the repository's raw update to set pinned_at
-	if err := s.chatRepo.Pin(ctx, chatID); err != nil {
-		return fmt.Errorf("pin chat: %w", err)
+	members, err := s.chatRepo.GetMembers(ctx, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("get chat members: %w", err)
+	}
+	var member *model.ChatMember
+	for _, m := range members {
+		if m.UserID == userID {
+			member = m
+			break
+		}
+	}
+	if member == nil {
+		return nil, apperror.Forbidden("you are not a member of this chat")
+	}
+	if chat.Type == model.ChatTypeGroup && !member.Role.IsAdmin() {
+		return nil, apperror.Forbidden("only admins can perform this action")
 	}
-	return nil
-}
 
-func (s *chatService) UnpinChat(ctx context.Context, chatID, userID uuid.UUID) error {
-	isMember, err := s.IsMember(ctx, chatID, userID)
+	if chat.DisappearingTimer == seconds {
+		return chat, nil
+	}
+
+	updated, err := s.chatRepo.SetDisappearingTimer(ctx, chatID, seconds)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("set disappearing timer: %w", err)
 	}
-	if !isMember {
-		return apperror.Forbidden("you are not a member of this chat")
+
+	// Announce the change in the chat itself
+	userName := "Seseorang"
+	if user, err := s.userRepo.FindByID(ctx, userID); err == nil && user.Name != "" {
+		userName = user.Name
 	}
+	content := userName + " menonaktifkan pesan sementara"
+	if seconds != DisappearingOff {
+		content = userName + " mengaktifkan pesan sementara: " + formatDisappearingTimer(seconds)
+	}
+	metadata, _ := json.Marshal(map[string]int{"disappearingTimer": seconds})
+	sysMsg, err := s.messageRepo.Create(ctx, model.CreateMessageInput{
+		ChatID:   chatID,
+		SenderID: userID,
+		Content:  content,
+		Type:     model.MessageTypeSystem,
+		Metadata: metadata,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create system message: %w", err)
+	}
+
+	data, err := json.Marshal(WSMessageEvent{Type: "new_message", Payload: sysMsg})
+	if err == nil {
+		s.hub.SendToRoom("chat:"+chatID.String(), data, uuid.Nil)
+	}
+
+	return updated, nil
+}
 
-	if err := s.chatRepo.Unpin(ctx, chatID); err != nil {
-		return fmt.Errorf("unpin chat: %w", err)
+// formatDisappearingTimer renders a timer for system messages, e.g. "7 hari".
+func formatDisappearingTimer(seconds int) string {
+	if seconds%Disappearing24Hours == 0 && seconds > Disappearing24Hours {
+		return fmt.Sprintf("%d hari", seconds/Disappearing24Hours)
 	}
-	return nil
+	return fmt.Sprintf("%d jam", seconds/3600)
 }
 
 func (s *chatService) IsMember(ctx context.Context, chatID, userID uuid.UUID) (bool, error) {
@@ -284,8 +410,8 @@ func sortChatList(items []*ChatListItem) {
 
 func shouldSwapChat(a, b *ChatListItem) bool {
 	// Pinned always comes first
-	aPinned := a.Chat.PinnedAt != nil
-	bPinned := b.Chat.PinnedAt != nil
+	aPinned := a.Settings.PinnedAt != nil
+	bPinned := b.Settings.PinnedAt != nil
 
 	if aPinned && !bPinned {
 		return false
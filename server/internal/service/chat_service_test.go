@@ -2,8 +2,11 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -24,11 +27,11 @@ type mockChatRepo struct {
 	addMemberErr        error
 	listByUserErr       error
 	getMembersErr       error
-	pinErr              error
-	unpinErr            error
+	settingsErr         error
 	findErr             error
 	findPersonalChatErr error
 	removeMemberErr     error
+	updateRoleErr       error
 	deleteErr           error
 }
 
@@ -49,6 +52,10 @@ func (m *mockChatRepo) Create(_ context.Context, input model.CreateChatInput) (*
 		Name:      input.Name,
 		Icon:      input.Icon,
 		CreatedBy: input.CreatedBy,
+		Permissions: model.GroupPermissions{
+			OnlyAdminsEditInfo:   true,
+			OnlyAdminsAddMembers: true,
+		},
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -100,7 +107,7 @@ func (m *mockChatRepo) ListByUser(_ context.Context, userID uuid.UUID) ([]*model
 	for chatID, chat := range m.chats {
 		for _, mem := range m.members[chatID] {
 			if mem.UserID == userID {
-				result = append(result, &model.ChatWithLastMessage{Chat: *chat})
+				result = append(result, &model.ChatWithLastMessage{Chat: *chat, Settings: mem.Settings})
 				break
 			}
 		}
@@ -135,6 +142,26 @@ func (m *mockChatRepo) RemoveMember(_ context.Context, chatID, userID uuid.UUID)
 	return apperror.NotFound("chat member", userID.String())
 }
 
+func (m *mockChatRepo) UpdateMemberRole(_ context.Context, chatID, userID uuid.UUID, role model.MemberRole) error {
+	if m.updateRoleErr != nil {
+		return m.updateRoleErr
+	}
+	for _, mem := range m.members[chatID] {
+		if mem.UserID == userID {
+			mem.Role = role
+			return nil
+		}
+	}
+	return apperror.NotFound("chat member", userID.String())
+}
+
+func (m *mockChatRepo) TransferOwnership(ctx context.Context, chatID, fromUserID, toUserID uuid.UUID) error {
+	if err := m.UpdateMemberRole(ctx, chatID, fromUserID, model.MemberRoleAdmin); err != nil {
+		return err
+	}
+	return m.UpdateMemberRole(ctx, chatID, toUserID, model.MemberRoleOwner)
+}
+
 func (m *mockChatRepo) GetMembers(_ context.Context, chatID uuid.UUID) ([]*model.ChatMember, error) {
 	if m.getMembersErr != nil {
 		return nil, m.getMembersErr
@@ -164,35 +191,78 @@ func (m *mockChatRepo) Delete(_ context.Context, id uuid.UUID) error {
 	return nil
 }
 
-func (m *mockChatRepo) Pin(_ context.Context, id uuid.UUID) error {
-	if m.pinErr != nil {
-		return m.pinErr
+func (m *mockChatRepo) UpdateSettings(_ context.Context, chatID, userID uuid.UUID, input model.UpdateChatSettingsInput) (*model.ChatSettings, error) {
+	if m.settingsErr != nil {
+		return nil, m.settingsErr
 	}
+	for _, mem := range m.members[chatID] {
+		if mem.UserID != userID {
+			continue
+		}
+		now := time.Now()
+		settings := &mem.Settings
+		if input.Pinned != nil {
+			settings.PinnedAt = nil
+			if *input.Pinned {
+				settings.PinnedAt = &now
+			}
+		}
+		if input.Archived != nil {
+			settings.ArchivedAt = nil
+			if *input.Archived {
+				settings.ArchivedAt = &now
+			}
+		}
+		if input.MutedUntil != nil {
+			settings.MutedUntil = nil
+			if input.MutedUntil.After(now) {
+				settings.MutedUntil = input.MutedUntil
+			}
+		}
+		if input.NotificationSound != nil {
+			settings.NotificationSound = *input.NotificationSound
+		}
+		result := *settings
+		return &result, nil
+	}
+	return nil, apperror.NotFound("chat member", userID.String())
+}
+
+func (m *mockChatRepo) SetDisappearingTimer(_ context.Context, id uuid.UUID, seconds int) (*model.Chat, error) {
 	c, ok := m.chats[id]
 	if !ok {
-		return apperror.NotFound("chat", id.String())
+		return nil, apperror.NotFound("chat", id.String())
 	}
-	now := time.Now()
-	c.PinnedAt = &now
-	return nil
+	c.DisappearingTimer = seconds
+	return c, nil
 }
 
-func (m *mockChatRepo) Unpin(_ context.Context, id uuid.UUID) error {
-	if m.unpinErr != nil {
-		return m.unpinErr
-	}
+func (m *mockChatRepo) UpdatePermissions(_ context.Context, id uuid.UUID, input model.UpdateGroupPermissionsInput) (*model.Chat, error) {
 	c, ok := m.chats[id]
 	if !ok {
-		return apperror.NotFound("chat", id.String())
+		return nil, apperror.NotFound("chat", id.String())
 	}
-	c.PinnedAt = nil
-	return nil
+	if input.OnlyAdminsSend != nil {
+		c.Permissions.OnlyAdminsSend = *input.OnlyAdminsSend
+	}
+	if input.OnlyAdminsEditInfo != nil {
+		c.Permissions.OnlyAdminsEditInfo = *input.OnlyAdminsEditInfo
+	}
+	if input.OnlyAdminsCreateTopics != nil {
+		c.Permissions.OnlyAdminsCreateTopics = *input.OnlyAdminsCreateTopics
+	}
+	if input.OnlyAdminsAddMembers != nil {
+		c.Permissions.OnlyAdminsAddMembers = *input.OnlyAdminsAddMembers
+	}
+	return c, nil
 }
 
 // --- Mock Message Repository ---
 type mockMessageRepo struct {
 	messages   map[uuid.UUID]*model.Message
 	byChat     map[uuid.UUID][]*model.Message
+	edits      map[uuid.UUID][]*model.MessageEdit
+	expired    []*model.ExpiredMessages // returned by DeleteExpired, one batch per call
 	createErr  error
 	listErr    error
 	searchErr  error
@@ -203,6 +273,7 @@ func newMockMessageRepo() *mockMessageRepo {
 	return &mockMessageRepo{
 		messages: make(map[uuid.UUID]*model.Message),
 		byChat:   make(map[uuid.UUID][]*model.Message),
+		edits:    make(map[uuid.UUID][]*model.MessageEdit),
 	}
 }
 
@@ -220,6 +291,7 @@ func (m *mockMessageRepo) Create(_ context.Context, input model.CreateMessageInp
 		SenderID:  input.SenderID,
 		Content:   input.Content,
 		ReplyToID: input.ReplyToID,
+		ThreadID:  input.ThreadID,
 		Type:      msgType,
 		Metadata:  input.Metadata,
 		CreatedAt: time.Now(),
@@ -278,6 +350,150 @@ func (m *mockMessageRepo) Search(_ context.Context, _ uuid.UUID, _ string) ([]*m
 	return nil, nil
 }
 
+func (m *mockMessageRepo) Edit(_ context.Context, id uuid.UUID, content string) (*model.Message, error) {
+	msg, ok := m.messages[id]
+	if !ok {
+		return nil, apperror.NotFound("message", id.String())
+	}
+	now := time.Now()
+	m.edits[id] = append(m.edits[id], &model.MessageEdit{
+		ID: uuid.New(), MessageID: id, Content: msg.Content, EditedAt: now,
+	})
+	msg.Content = content
+	msg.EditedAt = &now
+	return msg, nil
+}
+
+func (m *mockMessageRepo) UpdateMetadata(_ context.Context, id uuid.UUID, metadata json.RawMessage) (*model.Message, error) {
+	msg, ok := m.messages[id]
+	if !ok {
+		return nil, apperror.NotFound("message", id.String())
+	}
+	msg.Metadata = metadata
+	return msg, nil
+}
+
+func (m *mockMessageRepo) ListEdits(_ context.Context, messageID uuid.UUID) ([]*model.MessageEdit, error) {
+	return m.edits[messageID], nil
+}
+
+func (m *mockMessageRepo) ListThread(_ context.Context, rootID uuid.UUID, cursor *time.Time, limit int) ([]*model.Message, error) {
+	root, ok := m.messages[rootID]
+	if !ok {
+		return nil, nil
+	}
+	// byChat is newest first; threads read oldest first
+	msgs := m.byChat[root.ChatID]
+	var result []*model.Message
+	for i := len(msgs) - 1; i >= 0; i-- {
+		msg := msgs[i]
+		if msg.ThreadID == nil || *msg.ThreadID != rootID {
+			continue
+		}
+		if cursor != nil && !msg.CreatedAt.After(*cursor) {
+			continue
+		}
+		result = append(result, msg)
+		if len(result) >= limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+func (m *mockMessageRepo) ThreadSummaries(_ context.Context, rootIDs []uuid.UUID) (map[uuid.UUID]*model.ThreadSummary, error) {
+	wanted := make(map[uuid.UUID]bool, len(rootIDs))
+	for _, id := range rootIDs {
+		wanted[id] = true
+	}
+	summaries := make(map[uuid.UUID]*model.ThreadSummary)
+	for _, msg := range m.messages {
+		if msg.ThreadID == nil || !wanted[*msg.ThreadID] || (msg.IsDeleted && msg.DeletedForAll) {
+			continue
+		}
+		summary, ok := summaries[*msg.ThreadID]
+		if !ok {
+			summary = &model.ThreadSummary{RootID: *msg.ThreadID}
+			summaries[*msg.ThreadID] = summary
+		}
+		summary.ReplyCount++
+		if msg.CreatedAt.After(summary.LastReplyAt) {
+			summary.LastReplyAt = msg.CreatedAt
+		}
+	}
+	return summaries, nil
+}
+
+func (m *mockMessageRepo) DeleteExpired(_ context.Context, _ int) (*model.ExpiredMessages, error) {
+	if len(m.expired) == 0 {
+		return &model.ExpiredMessages{ByChat: map[uuid.UUID][]uuid.UUID{}}, nil
+	}
+	batch := m.expired[0]
+	m.expired = m.expired[1:]
+	for _, ids := range batch.ByChat {
+		for _, id := range ids {
+			delete(m.messages, id)
+		}
+	}
+	return batch, nil
+}
+
+// --- Mock Thread Repository ---
+type mockThreadRepo struct {
+	followers map[uuid.UUID][]uuid.UUID
+	followErr error
+	mu        sync.Mutex
+}
+
+func newMockThreadRepo() *mockThreadRepo {
+	return &mockThreadRepo{followers: make(map[uuid.UUID][]uuid.UUID)}
+}
+
+func (m *mockThreadRepo) Follow(_ context.Context, rootID, userID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.followErr != nil {
+		return m.followErr
+	}
+	for _, id := range m.followers[rootID] {
+		if id == userID {
+			return nil
+		}
+	}
+	m.followers[rootID] = append(m.followers[rootID], userID)
+	return nil
+}
+
+func (m *mockThreadRepo) Unfollow(_ context.Context, rootID, userID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	followers := m.followers[rootID]
+	for i, id := range followers {
+		if id == userID {
+			m.followers[rootID] = append(followers[:i:i], followers[i+1:]...)
+			return nil
+		}
+	}
+	return apperror.NotFound("thread follower", userID.String())
+}
+
+func (m *mockThreadRepo) IsFollowing(_ context.Context, rootID, userID uuid.UUID) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, id := range m.followers[rootID] {
+		if id == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockThreadRepo) ListFollowers(_ context.Context, rootID uuid.UUID) ([]uuid.UUID, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]uuid.UUID(nil), m.followers[rootID]...), nil
+}
+
 // --- Mock Message Status Repository ---
 type mockMessageStatRepo struct {
 	statuses       map[string]*model.MessageStatus // key: "msgID:userID"
@@ -321,7 +537,7 @@ func (m *mockMessageStatRepo) GetStatus(_ context.Context, messageID uuid.UUID)
 	return result, nil
 }
 
-func (m *mockMessageStatRepo) MarkChatAsRead(_ context.Context, chatID, userID uuid.UUID) error {
+func (m *mockMessageStatRepo) MarkChatAsRead(_ context.Context, chatID, userID uuid.UUID, _ *uuid.UUID) error {
 	if m.markReadErr != nil {
 		return m.markReadErr
 	}
@@ -338,6 +554,53 @@ func (m *mockMessageStatRepo) GetUnreadCount(_ context.Context, chatID, userID u
 	return m.unreadCount[key], nil
 }
 
+// --- Mock Reaction Repository ---
+type mockReactionRepo struct {
+	reactions []*model.Reaction
+	listErr   error
+}
+
+func newMockReactionRepo() *mockReactionRepo {
+	return &mockReactionRepo{}
+}
+
+func (m *mockReactionRepo) Add(_ context.Context, messageID, userID uuid.UUID, emoji string) (bool, error) {
+	for _, r := range m.reactions {
+		if r.MessageID == messageID && r.UserID == userID && r.Emoji == emoji {
+			return false, nil
+		}
+	}
+	m.reactions = append(m.reactions, &model.Reaction{
+		MessageID: messageID, UserID: userID, Emoji: emoji, CreatedAt: time.Now(),
+	})
+	return true, nil
+}
+
+func (m *mockReactionRepo) Remove(_ context.Context, messageID, userID uuid.UUID, emoji string) error {
+	for i, r := range m.reactions {
+		if r.MessageID == messageID && r.UserID == userID && r.Emoji == emoji {
+			m.reactions = append(m.reactions[:i], m.reactions[i+1:]...)
+			return nil
+		}
+	}
+	return apperror.NotFound("reaction", emoji)
+}
+
+func (m *mockReactionRepo) ListByMessages(_ context.Context, messageIDs []uuid.UUID) (map[uuid.UUID][]*model.Reaction, error) {
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+	result := make(map[uuid.UUID][]*model.Reaction)
+	for _, id := range messageIDs {
+		for _, r := range m.reactions {
+			if r.MessageID == id {
+				result[id] = append(result[id], r)
+			}
+		}
+	}
+	return result, nil
+}
+
 // --- Helper to create test hub ---
 func newTestHub() *ws.Hub {
 	hub := ws.NewHub()
@@ -355,7 +618,7 @@ func TestChatService_CreatePersonalChat(t *testing.T) {
 	hub := newTestHub()
 	defer hub.Shutdown()
 
-	svc := NewChatService(chatRepo, msgRepo, msgStatRepo, userRepo, hub)
+	svc := NewChatService(chatRepo, msgRepo, msgStatRepo, userRepo, nil, nil, hub)
 
 	userA := uuid.New()
 	userB := uuid.New()
@@ -390,7 +653,7 @@ func TestChatService_GetOrCreatePersonalChat(t *testing.T) {
 	hub := newTestHub()
 	defer hub.Shutdown()
 
-	svc := NewChatService(chatRepo, msgRepo, msgStatRepo, userRepo, hub)
+	svc := NewChatService(chatRepo, msgRepo, msgStatRepo, userRepo, nil, nil, hub)
 
 	userA := uuid.New()
 	userB := uuid.New()
@@ -417,7 +680,7 @@ func TestChatService_ListChats(t *testing.T) {
 	hub := newTestHub()
 	defer hub.Shutdown()
 
-	svc := NewChatService(chatRepo, msgRepo, msgStatRepo, userRepo, hub)
+	svc := NewChatService(chatRepo, msgRepo, msgStatRepo, userRepo, nil, nil, hub)
 
 	userA := uuid.New()
 	userB := uuid.New()
@@ -429,7 +692,7 @@ func TestChatService_ListChats(t *testing.T) {
 	require.NoError(t, err)
 
 	t.Run("list for user A", func(t *testing.T) {
-		items, err := svc.ListChats(context.Background(), userA)
+		items, err := svc.ListChats(context.Background(), userA, ChatListFilter{})
 		require.NoError(t, err)
 		assert.Len(t, items, 1)
 		assert.Equal(t, chat.ID, items[0].Chat.ID)
@@ -438,7 +701,7 @@ func TestChatService_ListChats(t *testing.T) {
 	})
 
 	t.Run("empty for unknown user", func(t *testing.T) {
-		items, err := svc.ListChats(context.Background(), uuid.New())
+		items, err := svc.ListChats(context.Background(), uuid.New(), ChatListFilter{})
 		require.NoError(t, err)
 		assert.Len(t, items, 0)
 	})
@@ -452,7 +715,7 @@ func TestChatService_PinUnpin(t *testing.T) {
 	hub := newTestHub()
 	defer hub.Shutdown()
 
-	svc := NewChatService(chatRepo, msgRepo, msgStatRepo, userRepo, hub)
+	svc := NewChatService(chatRepo, msgRepo, msgStatRepo, userRepo, nil, nil, hub)
 
 	userA := uuid.New()
 	userB := uuid.New()
@@ -462,16 +725,27 @@ func TestChatService_PinUnpin(t *testing.T) {
 	chat, err := svc.CreatePersonalChat(context.Background(), userA, userB)
 	require.NoError(t, err)
 
+	settingsOf := func(userID uuid.UUID) model.ChatSettings {
+		for _, m := range chatRepo.members[chat.ID] {
+			if m.UserID == userID {
+				return m.Settings
+			}
+		}
+		return model.ChatSettings{}
+	}
+
 	t.Run("pin chat", func(t *testing.T) {
 		err := svc.PinChat(context.Background(), chat.ID, userA)
 		require.NoError(t, err)
-		assert.NotNil(t, chatRepo.chats[chat.ID].PinnedAt)
+		assert.NotNil(t, settingsOf(userA).PinnedAt)
+		// Pins are personal
+		assert.Nil(t, settingsOf(userB).PinnedAt)
 	})
 
 	t.Run("unpin chat", func(t *testing.T) {
 		err := svc.UnpinChat(context.Background(), chat.ID, userA)
 		require.NoError(t, err)
-		assert.Nil(t, chatRepo.chats[chat.ID].PinnedAt)
+		assert.Nil(t, settingsOf(userA).PinnedAt)
 	})
 
 	t.Run("pin not a member", func(t *testing.T) {
@@ -480,6 +754,237 @@ func TestChatService_PinUnpin(t *testing.T) {
 	})
 }
 
+func TestChatService_UpdateSettings(t *testing.T) {
+	ctx := context.Background()
+	chatRepo := newMockChatRepo()
+	userRepo := newMockUserRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub)
+
+	userA := uuid.New()
+	userB := uuid.New()
+	userRepo.addUser(&model.User{ID: userA, Phone: "+628111", Name: "A", Avatar: "\U0001F60A"})
+	userRepo.addUser(&model.User{ID: userB, Phone: "+628222", Name: "B", Avatar: "\U0001F60A"})
+
+	chat, err := svc.CreatePersonalChat(ctx, userA, userB)
+	require.NoError(t, err)
+
+	t.Run("archive, mute and sound", func(t *testing.T) {
+		archived := true
+		until := time.Now().Add(8 * time.Hour)
+		sound := "chime"
+		settings, err := svc.UpdateSettings(ctx, chat.ID, userA, model.UpdateChatSettingsInput{
+			Archived: &archived, MutedUntil: &until, NotificationSound: &sound,
+		})
+		require.NoError(t, err)
+		assert.NotNil(t, settings.ArchivedAt)
+		assert.True(t, settings.IsMuted(time.Now()))
+		assert.Equal(t, "chime", settings.NotificationSound)
+	})
+
+	t.Run("past mute time unmutes", func(t *testing.T) {
+		past := time.Now().Add(-time.Minute)
+		settings, err := svc.UpdateSettings(ctx, chat.ID, userA, model.UpdateChatSettingsInput{MutedUntil: &past})
+		require.NoError(t, err)
+		assert.Nil(t, settings.MutedUntil)
+		// Fields left out are unchanged
+		assert.NotNil(t, settings.ArchivedAt)
+	})
+
+	t.Run("sound too long", func(t *testing.T) {
+		sound := strings.Repeat("a", 101)
+		_, err := svc.UpdateSettings(ctx, chat.ID, userA, model.UpdateChatSettingsInput{NotificationSound: &sound})
+		require.Error(t, err)
+	})
+
+	t.Run("not a member", func(t *testing.T) {
+		archived := true
+		_, err := svc.UpdateSettings(ctx, chat.ID, uuid.New(), model.UpdateChatSettingsInput{Archived: &archived})
+		require.Error(t, err)
+		assert.True(t, apperror.IsForbidden(err))
+	})
+}
+
+func TestChatService_ListChats_Filter(t *testing.T) {
+	ctx := context.Background()
+	chatRepo := newMockChatRepo()
+	msgStatRepo := newMockMessageStatRepo()
+	userRepo := newMockUserRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	svc := NewChatService(chatRepo, newMockMessageRepo(), msgStatRepo, userRepo, nil, nil, hub)
+
+	userA := uuid.New()
+	userB := uuid.New()
+	userC := uuid.New()
+	userRepo.addUser(&model.User{ID: userA, Phone: "+628111", Name: "A", Avatar: "\U0001F60A"})
+	userRepo.addUser(&model.User{ID: userB, Phone: "+628222", Name: "B", Avatar: "\U0001F60A"})
+	userRepo.addUser(&model.User{ID: userC, Phone: "+628333", Name: "C", Avatar: "\U0001F60A"})
+
+	withB, err := svc.CreatePersonalChat(ctx, userA, userB)
+	require.NoError(t, err)
+	withC, err := svc.CreatePersonalChat(ctx, userA, userC)
+	require.NoError(t, err)
+	group := &model.Chat{ID: uuid.New(), Type: model.ChatTypeGroup, Name: "Tim"}
+	chatRepo.chats[group.ID] = group
+	_ = chatRepo.AddMember(ctx, group.ID, userA, model.MemberRoleMember)
+
+	archived := true
+	_, err = svc.UpdateSettings(ctx, withC.ID, userA, model.UpdateChatSettingsInput{Archived: &archived})
+	require.NoError(t, err)
+	msgStatRepo.unreadCount[group.ID.String()+":"+userA.String()] = 3
+
+	chatIDs := func(items []*ChatListItem) []uuid.UUID {
+		ids := make([]uuid.UUID, 0, len(items))
+		for _, item := range items {
+			ids = append(ids, item.Chat.ID)
+		}
+		return ids
+	}
+
+	t.Run("main list hides archived chats", func(t *testing.T) {
+		items, err := svc.ListChats(ctx, userA, ChatListFilter{})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []uuid.UUID{withB.ID, group.ID}, chatIDs(items))
+	})
+
+	t.Run("archived list", func(t *testing.T) {
+		items, err := svc.ListChats(ctx, userA, ChatListFilter{Archived: true})
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{withC.ID}, chatIDs(items))
+		assert.NotNil(t, items[0].Settings.ArchivedAt)
+	})
+
+	t.Run("archiving is personal", func(t *testing.T) {
+		items, err := svc.ListChats(ctx, userC, ChatListFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{withC.ID}, chatIDs(items))
+	})
+
+	t.Run("by type", func(t *testing.T) {
+		items, err := svc.ListChats(ctx, userA, ChatListFilter{Type: model.ChatTypeGroup})
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{group.ID}, chatIDs(items))
+	})
+
+	t.Run("unread only", func(t *testing.T) {
+		items, err := svc.ListChats(ctx, userA, ChatListFilter{Unread: true})
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{group.ID}, chatIDs(items))
+	})
+}
+
+func TestChatService_ListChats_Privacy(t *testing.T) {
+	ctx := context.Background()
+	userRepo := newMockUserRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	svc := NewChatService(newMockChatRepo(), newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, NewPrivacyPolicy(newMockContactRepo(), nil), hub)
+
+	viewer := uuid.New()
+	private := uuid.New()
+	hidden := model.PrivacySettings{
+		LastSeenVisibility:     VisibilityNobody,
+		OnlineVisibility:       VisibilityNobody,
+		ProfilePhotoVisibility: VisibilityContacts,
+	}
+	userRepo.addUser(&model.User{ID: viewer, Phone: "+628111", Name: "Viewer", Avatar: "\U0001F60A"})
+	userRepo.addUser(&model.User{ID: private, Phone: "+628222", Name: "Private", Avatar: "\U0001F60E", LastSeen: time.Now(), PrivacySettings: hidden})
+
+	_, err := svc.CreatePersonalChat(ctx, viewer, private)
+	require.NoError(t, err)
+
+	observer := &ws.Client{UserID: private, DeviceID: "d1", Send: make(chan []byte, 16), Hub: hub}
+	hub.RegisterClient(observer)
+	time.Sleep(20 * time.Millisecond)
+
+	items, err := svc.ListChats(ctx, viewer, ChatListFilter{})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.NotNil(t, items[0].OtherUser)
+	assert.Equal(t, "Private", items[0].OtherUser.Name)
+	assert.Empty(t, items[0].OtherUser.Avatar)
+	assert.True(t, items[0].OtherUser.LastSeen.IsZero())
+	assert.False(t, items[0].IsOnline)
+}
+
+func TestChatService_SetDisappearingTimer(t *testing.T) {
+	ctx := context.Background()
+	chatRepo := newMockChatRepo()
+	msgRepo := newMockMessageRepo()
+	msgStatRepo := newMockMessageStatRepo()
+	userRepo := newMockUserRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	svc := NewChatService(chatRepo, msgRepo, msgStatRepo, userRepo, nil, nil, hub)
+
+	admin := uuid.New()
+	member := uuid.New()
+	userRepo.addUser(&model.User{ID: admin, Phone: "+628111", Name: "Andi", Avatar: "\U0001F60A"})
+	userRepo.addUser(&model.User{ID: member, Phone: "+628222", Name: "Budi", Avatar: "\U0001F60A"})
+
+	group := &model.Chat{ID: uuid.New(), Type: model.ChatTypeGroup, Name: "Tim"}
+	chatRepo.chats[group.ID] = group
+	_ = chatRepo.AddMember(ctx, group.ID, admin, model.MemberRoleAdmin)
+	_ = chatRepo.AddMember(ctx, group.ID, member, model.MemberRoleMember)
+
+	t.Run("admin enables timer with system message", func(t *testing.T) {
+		chat, err := svc.SetDisappearingTimer(ctx, group.ID, admin, Disappearing7Days)
+		require.NoError(t, err)
+		assert.Equal(t, Disappearing7Days, chat.DisappearingTimer)
+
+		require.Len(t, msgRepo.byChat[group.ID], 1)
+		sysMsg := msgRepo.byChat[group.ID][0]
+		assert.Equal(t, model.MessageTypeSystem, sysMsg.Type)
+		assert.Equal(t, "Andi mengaktifkan pesan sementara: 7 hari", sysMsg.Content)
+		assert.JSONEq(t, `{"disappearingTimer":604800}`, string(sysMsg.Metadata))
+	})
+
+	t.Run("unchanged timer is not announced again", func(t *testing.T) {
+		_, err := svc.SetDisappearingTimer(ctx, group.ID, admin, Disappearing7Days)
+		require.NoError(t, err)
+		assert.Len(t, msgRepo.byChat[group.ID], 1)
+	})
+
+	t.Run("group member cannot change timer", func(t *testing.T) {
+		_, err := svc.SetDisappearingTimer(ctx, group.ID, member, DisappearingOff)
+		require.Error(t, err)
+		assert.True(t, apperror.IsForbidden(err))
+	})
+
+	t.Run("any member of a personal chat can change timer", func(t *testing.T) {
+		chat, err := svc.CreatePersonalChat(ctx, admin, member)
+		require.NoError(t, err)
+
+		updated, err := svc.SetDisappearingTimer(ctx, chat.ID, member, Disappearing24Hours)
+		require.NoError(t, err)
+		assert.Equal(t, Disappearing24Hours, updated.DisappearingTimer)
+		assert.Equal(t, "Budi mengaktifkan pesan sementara: 24 jam", msgRepo.byChat[chat.ID][0].Content)
+
+		_, err = svc.SetDisappearingTimer(ctx, chat.ID, member, DisappearingOff)
+		require.NoError(t, err)
+		assert.Equal(t, "Budi menonaktifkan pesan sementara", msgRepo.byChat[chat.ID][0].Content)
+	})
+
+	t.Run("unsupported timer", func(t *testing.T) {
+		_, err := svc.SetDisappearingTimer(ctx, group.ID, admin, 3600)
+		var appErr *apperror.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, "VALIDATION_ERROR", appErr.Code)
+	})
+
+	t.Run("non-member", func(t *testing.T) {
+		_, err := svc.SetDisappearingTimer(ctx, group.ID, uuid.New(), Disappearing24Hours)
+		require.Error(t, err)
+		assert.True(t, apperror.IsForbidden(err))
+	})
+}
+
 func TestChatService_IsMember(t *testing.T) {
 	chatRepo := newMockChatRepo()
 	msgRepo := newMockMessageRepo()
@@ -488,7 +993,7 @@ func TestChatService_IsMember(t *testing.T) {
 	hub := newTestHub()
 	defer hub.Shutdown()
 
-	svc := NewChatService(chatRepo, msgRepo, msgStatRepo, userRepo, hub)
+	svc := NewChatService(chatRepo, msgRepo, msgStatRepo, userRepo, nil, nil, hub)
 
 	userA := uuid.New()
 	userB := uuid.New()
@@ -519,7 +1024,7 @@ func TestChatService_GetChat(t *testing.T) {
 	hub := newTestHub()
 	defer hub.Shutdown()
 
-	svc := NewChatService(chatRepo, msgRepo, msgStatRepo, userRepo, hub)
+	svc := NewChatService(chatRepo, msgRepo, msgStatRepo, userRepo, nil, nil, hub)
 
 	userA := uuid.New()
 	userB := uuid.New()
@@ -547,6 +1052,33 @@ func TestChatService_GetChat(t *testing.T) {
 	})
 }
 
+func TestChatService_CreatePersonalChat_Blocked(t *testing.T) {
+	ctx := context.Background()
+	userRepo := newMockUserRepo()
+	blockRepo := newMockUserBlockRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	svc := NewChatService(newMockChatRepo(), newMockMessageRepo(), newMockMessageStatRepo(), userRepo, blockRepo, nil, hub)
+
+	userA := uuid.New()
+	userB := uuid.New()
+	userRepo.addUser(&model.User{ID: userA, Phone: "+628111", Name: "A"})
+	userRepo.addUser(&model.User{ID: userB, Phone: "+628222", Name: "B"})
+	_ = blockRepo.Block(ctx, userB, userA)
+
+	// Neither side can start a chat
+	_, err := svc.CreatePersonalChat(ctx, userA, userB)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot start a chat")
+	_, err = svc.CreatePersonalChat(ctx, userB, userA)
+	require.Error(t, err)
+
+	_ = blockRepo.Unblock(ctx, userB, userA)
+	_, err = svc.CreatePersonalChat(ctx, userA, userB)
+	require.NoError(t, err)
+}
+
 func TestChatService_CreatePersonalChat_Errors(t *testing.T) {
 	hub := newTestHub()
 	defer hub.Shutdown()
@@ -557,7 +1089,7 @@ func TestChatService_CreatePersonalChat_Errors(t *testing.T) {
 		// Don't add user → FindByID returns NotFound, but we want non-NotFound
 		// Instead, test when contactID doesn't exist → already covered as NotFound
 		// For a generic error we need a custom behavior
-		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub)
+		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub)
 		_, err := svc.CreatePersonalChat(context.Background(), uuid.New(), uuid.New())
 		require.Error(t, err)
 	})
@@ -569,7 +1101,7 @@ func TestChatService_CreatePersonalChat_Errors(t *testing.T) {
 		contactID := uuid.New()
 		userRepo.addUser(&model.User{ID: contactID, Phone: "+628111", Name: "C", Avatar: "\U0001F60A"})
 
-		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub)
+		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub)
 		_, err := svc.CreatePersonalChat(context.Background(), uuid.New(), contactID)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "create personal chat")
@@ -582,7 +1114,7 @@ func TestChatService_CreatePersonalChat_Errors(t *testing.T) {
 		contactID := uuid.New()
 		userRepo.addUser(&model.User{ID: contactID, Phone: "+628111", Name: "C", Avatar: "\U0001F60A"})
 
-		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub)
+		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub)
 		_, err := svc.CreatePersonalChat(context.Background(), uuid.New(), contactID)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "add creator member")
@@ -594,7 +1126,7 @@ func TestChatService_GetOrCreatePersonalChat_Errors(t *testing.T) {
 	defer hub.Shutdown()
 
 	t.Run("self chat", func(t *testing.T) {
-		svc := NewChatService(newMockChatRepo(), newMockMessageRepo(), newMockMessageStatRepo(), newMockUserRepo(), hub)
+		svc := NewChatService(newMockChatRepo(), newMockMessageRepo(), newMockMessageStatRepo(), newMockUserRepo(), nil, nil, hub)
 		id := uuid.New()
 		_, err := svc.GetOrCreatePersonalChat(context.Background(), id, id)
 		require.Error(t, err)
@@ -604,7 +1136,7 @@ func TestChatService_GetOrCreatePersonalChat_Errors(t *testing.T) {
 	t.Run("find personal chat generic error", func(t *testing.T) {
 		chatRepo := newMockChatRepo()
 		chatRepo.findPersonalChatErr = fmt.Errorf("db error")
-		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), newMockUserRepo(), hub)
+		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), newMockUserRepo(), nil, nil, hub)
 		_, err := svc.GetOrCreatePersonalChat(context.Background(), uuid.New(), uuid.New())
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "find personal chat")
@@ -618,8 +1150,8 @@ func TestChatService_ListChats_Errors(t *testing.T) {
 	t.Run("list by user error", func(t *testing.T) {
 		chatRepo := newMockChatRepo()
 		chatRepo.listByUserErr = fmt.Errorf("db error")
-		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), newMockUserRepo(), hub)
-		_, err := svc.ListChats(context.Background(), uuid.New())
+		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), newMockUserRepo(), nil, nil, hub)
+		_, err := svc.ListChats(context.Background(), uuid.New(), ChatListFilter{})
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "list chats")
 	})
@@ -633,12 +1165,12 @@ func TestChatService_ListChats_Errors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: userA, Phone: "+628111", Name: "A", Avatar: "\U0001F60A"})
 		userRepo.addUser(&model.User{ID: userB, Phone: "+628222", Name: "B", Avatar: "\U0001F60A"})
 
-		svc := NewChatService(chatRepo, newMockMessageRepo(), msgStatRepo, userRepo, hub)
+		svc := NewChatService(chatRepo, newMockMessageRepo(), msgStatRepo, userRepo, nil, nil, hub)
 		_, err := svc.CreatePersonalChat(context.Background(), userA, userB)
 		require.NoError(t, err)
 
 		msgStatRepo.unreadCountErr = fmt.Errorf("redis error")
-		_, err = svc.ListChats(context.Background(), userA)
+		_, err = svc.ListChats(context.Background(), userA, ChatListFilter{})
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "unread count")
 	})
@@ -648,30 +1180,11 @@ func TestChatService_PinChat_Errors(t *testing.T) {
 	hub := newTestHub()
 	defer hub.Shutdown()
 
-	t.Run("get members error", func(t *testing.T) {
-		chatRepo := newMockChatRepo()
-		chatRepo.getMembersErr = fmt.Errorf("db error")
-		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), newMockUserRepo(), hub)
+	t.Run("unknown chat", func(t *testing.T) {
+		svc := NewChatService(newMockChatRepo(), newMockMessageRepo(), newMockMessageStatRepo(), newMockUserRepo(), nil, nil, hub)
 		err := svc.PinChat(context.Background(), uuid.New(), uuid.New())
 		require.Error(t, err)
-	})
-
-	t.Run("find by id error", func(t *testing.T) {
-		chatRepo := newMockChatRepo()
-		userRepo := newMockUserRepo()
-		userA := uuid.New()
-		userB := uuid.New()
-		userRepo.addUser(&model.User{ID: userA, Phone: "+628111", Name: "A", Avatar: "\U0001F60A"})
-		userRepo.addUser(&model.User{ID: userB, Phone: "+628222", Name: "B", Avatar: "\U0001F60A"})
-
-		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub)
-		chat, err := svc.CreatePersonalChat(context.Background(), userA, userB)
-		require.NoError(t, err)
-
-		chatRepo.findErr = fmt.Errorf("db error")
-		err = svc.PinChat(context.Background(), chat.ID, userA)
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "find chat")
+		assert.True(t, apperror.IsForbidden(err))
 	})
 
 	t.Run("pin error", func(t *testing.T) {
@@ -682,14 +1195,14 @@ func TestChatService_PinChat_Errors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: userA, Phone: "+628111", Name: "A", Avatar: "\U0001F60A"})
 		userRepo.addUser(&model.User{ID: userB, Phone: "+628222", Name: "B", Avatar: "\U0001F60A"})
 
-		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub)
+		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub)
 		chat, err := svc.CreatePersonalChat(context.Background(), userA, userB)
 		require.NoError(t, err)
 
-		chatRepo.pinErr = fmt.Errorf("db error")
+		chatRepo.settingsErr = fmt.Errorf("db error")
 		err = svc.PinChat(context.Background(), chat.ID, userA)
 		require.Error(t, err)
-		assert.Contains(t, err.Error(), "pin chat")
+		assert.Contains(t, err.Error(), "update chat settings")
 	})
 }
 
@@ -697,14 +1210,6 @@ func TestChatService_UnpinChat_Errors(t *testing.T) {
 	hub := newTestHub()
 	defer hub.Shutdown()
 
-	t.Run("get members error", func(t *testing.T) {
-		chatRepo := newMockChatRepo()
-		chatRepo.getMembersErr = fmt.Errorf("db error")
-		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), newMockUserRepo(), hub)
-		err := svc.UnpinChat(context.Background(), uuid.New(), uuid.New())
-		require.Error(t, err)
-	})
-
 	t.Run("not a member", func(t *testing.T) {
 		chatRepo := newMockChatRepo()
 		userRepo := newMockUserRepo()
@@ -713,12 +1218,13 @@ func TestChatService_UnpinChat_Errors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: userA, Phone: "+628111", Name: "A", Avatar: "\U0001F60A"})
 		userRepo.addUser(&model.User{ID: userB, Phone: "+628222", Name: "B", Avatar: "\U0001F60A"})
 
-		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub)
+		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub)
 		chat, err := svc.CreatePersonalChat(context.Background(), userA, userB)
 		require.NoError(t, err)
 
 		err = svc.UnpinChat(context.Background(), chat.ID, uuid.New())
 		require.Error(t, err)
+		assert.True(t, apperror.IsForbidden(err))
 	})
 
 	t.Run("unpin error", func(t *testing.T) {
@@ -729,14 +1235,14 @@ func TestChatService_UnpinChat_Errors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: userA, Phone: "+628111", Name: "A", Avatar: "\U0001F60A"})
 		userRepo.addUser(&model.User{ID: userB, Phone: "+628222", Name: "B", Avatar: "\U0001F60A"})
 
-		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub)
+		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub)
 		chat, err := svc.CreatePersonalChat(context.Background(), userA, userB)
 		require.NoError(t, err)
 
-		chatRepo.unpinErr = fmt.Errorf("db error")
+		chatRepo.settingsErr = fmt.Errorf("db error")
 		err = svc.UnpinChat(context.Background(), chat.ID, userA)
 		require.Error(t, err)
-		assert.Contains(t, err.Error(), "unpin chat")
+		assert.Contains(t, err.Error(), "update chat settings")
 	})
 }
 
@@ -747,7 +1253,7 @@ func TestChatService_IsMember_Errors(t *testing.T) {
 	t.Run("get members not found", func(t *testing.T) {
 		chatRepo := newMockChatRepo()
 		chatRepo.getMembersErr = apperror.NotFound("chat", "test")
-		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), newMockUserRepo(), hub)
+		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), newMockUserRepo(), nil, nil, hub)
 		_, err := svc.IsMember(context.Background(), uuid.New(), uuid.New())
 		require.Error(t, err)
 		assert.True(t, apperror.IsNotFound(err))
@@ -756,7 +1262,7 @@ func TestChatService_IsMember_Errors(t *testing.T) {
 	t.Run("get members generic error", func(t *testing.T) {
 		chatRepo := newMockChatRepo()
 		chatRepo.getMembersErr = fmt.Errorf("db error")
-		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), newMockUserRepo(), hub)
+		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), newMockUserRepo(), nil, nil, hub)
 		_, err := svc.IsMember(context.Background(), uuid.New(), uuid.New())
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "check membership")
@@ -775,7 +1281,7 @@ func TestChatService_GetChat_Errors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: userA, Phone: "+628111", Name: "A", Avatar: "\U0001F60A"})
 		userRepo.addUser(&model.User{ID: userB, Phone: "+628222", Name: "B", Avatar: "\U0001F60A"})
 
-		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub)
+		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub)
 		chat, err := svc.CreatePersonalChat(context.Background(), userA, userB)
 		require.NoError(t, err)
 
@@ -793,7 +1299,7 @@ func TestChatService_GetChat_Errors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: userA, Phone: "+628111", Name: "A", Avatar: "\U0001F60A"})
 		userRepo.addUser(&model.User{ID: userB, Phone: "+628222", Name: "B", Avatar: "\U0001F60A"})
 
-		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub)
+		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub)
 		chat, err := svc.CreatePersonalChat(context.Background(), userA, userB)
 		require.NoError(t, err)
 
@@ -812,11 +1318,11 @@ func TestSortChatList(t *testing.T) {
 	t.Run("pinned first", func(t *testing.T) {
 		items := []*ChatListItem{
 			{Chat: model.Chat{ID: uuid.New(), UpdatedAt: now}},
-			{Chat: model.Chat{ID: uuid.New(), UpdatedAt: earlier, PinnedAt: &now}},
+			{Chat: model.Chat{ID: uuid.New(), UpdatedAt: earlier}, Settings: model.ChatSettings{PinnedAt: &now}},
 		}
 		sortChatList(items)
-		assert.NotNil(t, items[0].Chat.PinnedAt)
-		assert.Nil(t, items[1].Chat.PinnedAt)
+		assert.NotNil(t, items[0].Settings.PinnedAt)
+		assert.Nil(t, items[1].Settings.PinnedAt)
 	})
 
 	t.Run("by last message time desc", func(t *testing.T) {
@@ -845,8 +1351,8 @@ func TestSortChatList(t *testing.T) {
 		id1 := uuid.New()
 		id2 := uuid.New()
 		items := []*ChatListItem{
-			{Chat: model.Chat{ID: id1, UpdatedAt: earlier, PinnedAt: &now}},
-			{Chat: model.Chat{ID: id2, UpdatedAt: later, PinnedAt: &now}},
+			{Chat: model.Chat{ID: id1, UpdatedAt: earlier}, Settings: model.ChatSettings{PinnedAt: &now}},
+			{Chat: model.Chat{ID: id2, UpdatedAt: later}, Settings: model.ChatSettings{PinnedAt: &now}},
 		}
 		sortChatList(items)
 		assert.Equal(t, id2, items[0].Chat.ID)
@@ -880,12 +1386,12 @@ func TestChatService_ListChats_MoreErrors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: userA, Phone: "+628111", Name: "A", Avatar: "a"})
 		userRepo.addUser(&model.User{ID: userB, Phone: "+628222", Name: "B", Avatar: "b"})
 
-		svc := NewChatService(chatRepo, msgRepo, newMockMessageStatRepo(), userRepo, hub)
+		svc := NewChatService(chatRepo, msgRepo, newMockMessageStatRepo(), userRepo, nil, nil, hub)
 		_, err := svc.CreatePersonalChat(context.Background(), userA, userB)
 		require.NoError(t, err)
 
 		msgRepo.listErr = fmt.Errorf("db error")
-		_, err = svc.ListChats(context.Background(), userA)
+		_, err = svc.ListChats(context.Background(), userA, ChatListFilter{})
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "last message")
 		msgRepo.listErr = nil
@@ -899,12 +1405,12 @@ func TestChatService_ListChats_MoreErrors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: userA, Phone: "+628111", Name: "A", Avatar: "a"})
 		userRepo.addUser(&model.User{ID: userB, Phone: "+628222", Name: "B", Avatar: "b"})
 
-		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub)
+		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub)
 		_, err := svc.CreatePersonalChat(context.Background(), userA, userB)
 		require.NoError(t, err)
 
 		chatRepo.getMembersErr = fmt.Errorf("db error")
-		_, err = svc.ListChats(context.Background(), userA)
+		_, err = svc.ListChats(context.Background(), userA, ChatListFilter{})
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "chat members")
 		chatRepo.getMembersErr = nil
@@ -918,12 +1424,12 @@ func TestChatService_ListChats_MoreErrors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: userA, Phone: "+628111", Name: "A", Avatar: "a"})
 		userRepo.addUser(&model.User{ID: userB, Phone: "+628222", Name: "B", Avatar: "b"})
 
-		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub)
+		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub)
 		_, err := svc.CreatePersonalChat(context.Background(), userA, userB)
 		require.NoError(t, err)
 
 		userRepo.findErr = errors.New("generic db error")
-		_, err = svc.ListChats(context.Background(), userA)
+		_, err = svc.ListChats(context.Background(), userA, ChatListFilter{})
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "other user")
 		userRepo.findErr = nil
@@ -937,7 +1443,7 @@ func TestChatService_ListChats_MoreErrors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: userA, Phone: "+628111", Name: "A", Avatar: "a"})
 		userRepo.addUser(&model.User{ID: userB, Phone: "+628222", Name: "B", Avatar: "b"})
 
-		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub)
+		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub)
 		_, err := svc.CreatePersonalChat(context.Background(), userA, userB)
 		require.NoError(t, err)
 
@@ -945,7 +1451,7 @@ func TestChatService_ListChats_MoreErrors(t *testing.T) {
 		delete(userRepo.users, userB)
 		delete(userRepo.byPhone, "+628222")
 
-		items, err := svc.ListChats(context.Background(), userA)
+		items, err := svc.ListChats(context.Background(), userA, ChatListFilter{})
 		require.NoError(t, err)
 		assert.Len(t, items, 1)
 		assert.Nil(t, items[0].OtherUser, "deleted user should be nil")
@@ -961,8 +1467,8 @@ func TestChatService_ListChats_MoreErrors(t *testing.T) {
 		chatRepo.chats[groupChat.ID] = groupChat
 		_ = chatRepo.AddMember(context.Background(), groupChat.ID, userA, model.MemberRoleAdmin)
 
-		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub)
-		items, err := svc.ListChats(context.Background(), userA)
+		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub)
+		items, err := svc.ListChats(context.Background(), userA, ChatListFilter{})
 		require.NoError(t, err)
 		assert.Len(t, items, 1)
 		assert.Nil(t, items[0].OtherUser, "group chat should have nil OtherUser")
@@ -981,7 +1487,7 @@ func TestChatService_GetChat_MemberErrors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: userA, Phone: "+628111", Name: "A", Avatar: "a"})
 		userRepo.addUser(&model.User{ID: userB, Phone: "+628222", Name: "B", Avatar: "b"})
 
-		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub)
+		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub)
 		chat, err := svc.CreatePersonalChat(context.Background(), userA, userB)
 		require.NoError(t, err)
 
@@ -1002,7 +1508,7 @@ func TestChatService_GetChat_MemberErrors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: userA, Phone: "+628111", Name: "A", Avatar: "a"})
 		userRepo.addUser(&model.User{ID: userB, Phone: "+628222", Name: "B", Avatar: "b"})
 
-		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub)
+		svc := NewChatService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub)
 		chat, err := svc.CreatePersonalChat(context.Background(), userA, userB)
 		require.NoError(t, err)
 
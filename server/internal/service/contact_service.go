@@ -38,13 +38,14 @@ type ContactInfo struct {
 type ContactService interface {
 	SyncContacts(ctx context.Context, userID uuid.UUID, phoneHashes []string) ([]ContactMatch, error)
 	GetContacts(ctx context.Context, userID uuid.UUID) ([]ContactInfo, error)
-	SearchByPhone(ctx context.Context, phone string) (*model.User, error)
-	GetContactProfile(ctx context.Context, contactUserID uuid.UUID) (*ContactInfo, error)
+	SearchByPhone(ctx context.Context, viewerID uuid.UUID, phone string) (*model.User, error)
+	GetContactProfile(ctx context.Context, viewerID, contactUserID uuid.UUID) (*ContactInfo, error)
 }
 
 type contactService struct {
 	userRepo    repository.UserRepository
 	contactRepo repository.ContactRepository
+	privacy     PrivacyPolicy
 	hub         *ws.Hub
 }
 
@@ -52,11 +53,13 @@ type contactService struct {
 func NewContactService(
 	userRepo repository.UserRepository,
 	contactRepo repository.ContactRepository,
+	privacy PrivacyPolicy,
 	hub *ws.Hub,
 ) ContactService {
 	return &contactService{
 		userRepo:    userRepo,
 		contactRepo: contactRepo,
+		privacy:     privacy,
 		hub:         hub,
 	}
 }
@@ -96,6 +99,11 @@ func (s *contactService) SyncContacts(ctx context.Context, userID uuid.UUID, pho
 		if u.ID == userID {
 			continue
 		}
+		vis, err := s.privacy.Visibility(ctx, userID, u)
+		if err != nil {
+			return nil, fmt.Errorf("check contact privacy: %w", err)
+		}
+		u = vis.Apply(u)
 		matches = append(matches, ContactMatch{
 			PhoneHash: hash,
 			UserID:    u.ID,
@@ -146,15 +154,11 @@ func (s *contactService) GetContacts(ctx context.Context, userID uuid.UUID) ([]C
 			return nil, fmt.Errorf("get contact user: %w", err)
 		}
 
-		contacts = append(contacts, ContactInfo{
-			UserID:   user.ID,
-			Phone:    user.Phone,
-			Name:     user.Name,
-			Avatar:   user.Avatar,
-			Status:   user.Status,
-			IsOnline: s.hub.IsOnline(user.ID),
-			LastSeen: user.LastSeen,
-		})
+		info, err := s.contactInfo(ctx, userID, user)
+		if err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, *info)
 	}
 
 	// Sort: online first, then alphabetically by name
@@ -163,27 +167,43 @@ func (s *contactService) GetContacts(ctx context.Context, userID uuid.UUID) ([]C
 	return contacts, nil
 }
 
-func (s *contactService) SearchByPhone(ctx context.Context, phone string) (*model.User, error) {
+func (s *contactService) SearchByPhone(ctx context.Context, viewerID uuid.UUID, phone string) (*model.User, error) {
 	user, err := s.userRepo.FindByPhone(ctx, phone)
 	if err != nil {
 		return nil, fmt.Errorf("search by phone: %w", err)
 	}
-	return user, nil
+
+	vis, err := s.privacy.Visibility(ctx, viewerID, user)
+	if err != nil {
+		return nil, fmt.Errorf("check contact privacy: %w", err)
+	}
+	return vis.Apply(user), nil
 }
 
-func (s *contactService) GetContactProfile(ctx context.Context, contactUserID uuid.UUID) (*ContactInfo, error) {
+func (s *contactService) GetContactProfile(ctx context.Context, viewerID, contactUserID uuid.UUID) (*ContactInfo, error) {
 	user, err := s.userRepo.FindByID(ctx, contactUserID)
 	if err != nil {
 		return nil, fmt.Errorf("get contact profile: %w", err)
 	}
 
+	return s.contactInfo(ctx, viewerID, user)
+}
+
+// contactInfo builds the view of user that viewerID is allowed to see.
+func (s *contactService) contactInfo(ctx context.Context, viewerID uuid.UUID, user *model.User) (*ContactInfo, error) {
+	vis, err := s.privacy.Visibility(ctx, viewerID, user)
+	if err != nil {
+		return nil, fmt.Errorf("check contact privacy: %w", err)
+	}
+	user = vis.Apply(user)
+
 	return &ContactInfo{
 		UserID:   user.ID,
 		Phone:    user.Phone,
 		Name:     user.Name,
 		Avatar:   user.Avatar,
 		Status:   user.Status,
-		IsOnline: s.hub.IsOnline(user.ID),
+		IsOnline: vis.Online && s.hub.IsOnline(user.ID),
 		LastSeen: user.LastSeen,
 	}, nil
 }
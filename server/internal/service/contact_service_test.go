@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -61,6 +62,15 @@ func (m *mockContactRepo) DeleteAllByUserID(_ context.Context, userID uuid.UUID)
 	return nil
 }
 
+func (m *mockContactRepo) IsContact(_ context.Context, userID, contactUserID uuid.UUID) (bool, error) {
+	for _, c := range m.contacts[userID] {
+		if c.ContactUserID == contactUserID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (m *mockContactRepo) FindContactsOf(_ context.Context, contactUserID uuid.UUID) ([]uuid.UUID, error) {
 	var result []uuid.UUID
 	for ownerID, contacts := range m.contacts {
@@ -77,7 +87,7 @@ func TestContactService_SyncContacts(t *testing.T) {
 	userRepo := newMockUserRepo()
 	contactRepo := newMockContactRepo()
 	hub := ws.NewHub()
-	svc := NewContactService(userRepo, contactRepo, hub)
+	svc := NewContactService(userRepo, contactRepo, NewPrivacyPolicy(contactRepo, nil), hub)
 
 	myID := uuid.New()
 	userRepo.addUser(&model.User{ID: myID, Phone: "+6281111111111", PhoneHash: hashPhone("+6281111111111")})
@@ -129,7 +139,7 @@ func TestContactService_GetContacts(t *testing.T) {
 	userRepo := newMockUserRepo()
 	contactRepo := newMockContactRepo()
 	hub := ws.NewHub()
-	svc := NewContactService(userRepo, contactRepo, hub)
+	svc := NewContactService(userRepo, contactRepo, NewPrivacyPolicy(contactRepo, nil), hub)
 
 	myID := uuid.New()
 	user2 := &model.User{ID: uuid.New(), Phone: "+6282222222222", Name: "Zara", Avatar: "\U0001F60A"}
@@ -163,18 +173,18 @@ func TestContactService_SearchByPhone(t *testing.T) {
 	userRepo := newMockUserRepo()
 	contactRepo := newMockContactRepo()
 	hub := ws.NewHub()
-	svc := NewContactService(userRepo, contactRepo, hub)
+	svc := NewContactService(userRepo, contactRepo, NewPrivacyPolicy(contactRepo, nil), hub)
 
 	userRepo.addUser(&model.User{ID: uuid.New(), Phone: "+6281234567890", Name: "Found"})
 
 	t.Run("found", func(t *testing.T) {
-		user, err := svc.SearchByPhone(context.Background(), "+6281234567890")
+		user, err := svc.SearchByPhone(context.Background(), uuid.New(), "+6281234567890")
 		require.NoError(t, err)
 		assert.Equal(t, "Found", user.Name)
 	})
 
 	t.Run("not found", func(t *testing.T) {
-		_, err := svc.SearchByPhone(context.Background(), "+6289999999999")
+		_, err := svc.SearchByPhone(context.Background(), uuid.New(), "+6289999999999")
 		require.Error(t, err)
 	})
 }
@@ -183,24 +193,88 @@ func TestContactService_GetContactProfile(t *testing.T) {
 	userRepo := newMockUserRepo()
 	contactRepo := newMockContactRepo()
 	hub := ws.NewHub()
-	svc := NewContactService(userRepo, contactRepo, hub)
+	svc := NewContactService(userRepo, contactRepo, NewPrivacyPolicy(contactRepo, nil), hub)
 
 	user := &model.User{ID: uuid.New(), Phone: "+6281234567890", Name: "Profile", Avatar: "\U0001F60A"}
 	userRepo.addUser(user)
 
 	t.Run("success", func(t *testing.T) {
-		info, err := svc.GetContactProfile(context.Background(), user.ID)
+		info, err := svc.GetContactProfile(context.Background(), uuid.New(), user.ID)
 		require.NoError(t, err)
 		assert.Equal(t, "Profile", info.Name)
 		assert.False(t, info.IsOnline)
 	})
 
 	t.Run("not found", func(t *testing.T) {
-		_, err := svc.GetContactProfile(context.Background(), uuid.New())
+		_, err := svc.GetContactProfile(context.Background(), uuid.New(), uuid.New())
 		require.Error(t, err)
 	})
 }
 
+func TestContactService_Privacy(t *testing.T) {
+	ctx := context.Background()
+	userRepo := newMockUserRepo()
+	contactRepo := newMockContactRepo()
+	hub := ws.NewHub()
+	go hub.Run()
+	defer hub.Shutdown()
+	svc := NewContactService(userRepo, contactRepo, NewPrivacyPolicy(contactRepo, nil), hub)
+
+	owner := &model.User{
+		ID: uuid.New(), Phone: "+6281200000001", Name: "Owner", Avatar: "\U0001F60A",
+		LastSeen: time.Now(),
+		PrivacySettings: model.PrivacySettings{
+			LastSeenVisibility:     VisibilityNobody,
+			OnlineVisibility:       VisibilityContacts,
+			ProfilePhotoVisibility: VisibilityContacts,
+		},
+	}
+	userRepo.addUser(owner)
+	friend := uuid.New()
+	stranger := uuid.New()
+	// Only the owner's own contacts count, not who saved the owner's number
+	_ = contactRepo.Upsert(ctx, owner.ID, friend, "Friend")
+	_ = contactRepo.Upsert(ctx, stranger, owner.ID, "Owner")
+
+	client := &ws.Client{UserID: owner.ID, DeviceID: "d1", Send: make(chan []byte, 16), Hub: hub}
+	hub.RegisterClient(client)
+	time.Sleep(20 * time.Millisecond)
+
+	t.Run("contact", func(t *testing.T) {
+		info, err := svc.GetContactProfile(ctx, friend, owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "\U0001F60A", info.Avatar)
+		assert.True(t, info.IsOnline)
+		assert.True(t, info.LastSeen.IsZero())
+	})
+
+	t.Run("stranger", func(t *testing.T) {
+		info, err := svc.GetContactProfile(ctx, stranger, owner.ID)
+		require.NoError(t, err)
+		assert.Empty(t, info.Avatar)
+		assert.False(t, info.IsOnline)
+		assert.True(t, info.LastSeen.IsZero())
+
+		contacts, err := svc.GetContacts(ctx, stranger)
+		require.NoError(t, err)
+		require.Len(t, contacts, 1)
+		assert.Empty(t, contacts[0].Avatar)
+		assert.False(t, contacts[0].IsOnline)
+
+		found, err := svc.SearchByPhone(ctx, stranger, owner.Phone)
+		require.NoError(t, err)
+		assert.Empty(t, found.Avatar)
+		// The stored user is left untouched
+		assert.Equal(t, "\U0001F60A", owner.Avatar)
+	})
+
+	t.Run("self", func(t *testing.T) {
+		info, err := svc.GetContactProfile(ctx, owner.ID, owner.ID)
+		require.NoError(t, err)
+		assert.False(t, info.LastSeen.IsZero())
+	})
+}
+
 func TestSortContacts(t *testing.T) {
 	contacts := []ContactInfo{
 		{Name: "Zara", IsOnline: false},
@@ -228,7 +302,7 @@ func TestContactService_GetContacts_Errors(t *testing.T) {
 		userRepo := newMockUserRepo()
 		contactRepo := newMockContactRepo()
 		contactRepo.findByUIDErr = errors.New("db error")
-		svc := NewContactService(userRepo, contactRepo, hub)
+		svc := NewContactService(userRepo, contactRepo, NewPrivacyPolicy(contactRepo, nil), hub)
 		_, err := svc.GetContacts(context.Background(), uuid.New())
 		require.Error(t, err)
 	})
@@ -236,7 +310,7 @@ func TestContactService_GetContacts_Errors(t *testing.T) {
 	t.Run("deleted contact skipped", func(t *testing.T) {
 		userRepo := newMockUserRepo()
 		contactRepo := newMockContactRepo()
-		svc := NewContactService(userRepo, contactRepo, hub)
+		svc := NewContactService(userRepo, contactRepo, NewPrivacyPolicy(contactRepo, nil), hub)
 
 		myID := uuid.New()
 		deletedUserID := uuid.New() // not in userRepo = "deleted"
@@ -257,7 +331,7 @@ func TestContactService_GetContacts_Errors(t *testing.T) {
 	t.Run("find user generic error", func(t *testing.T) {
 		userRepo := newMockUserRepo()
 		contactRepo := newMockContactRepo()
-		svc := NewContactService(userRepo, contactRepo, hub)
+		svc := NewContactService(userRepo, contactRepo, NewPrivacyPolicy(contactRepo, nil), hub)
 
 		myID := uuid.New()
 		badUser := uuid.New()
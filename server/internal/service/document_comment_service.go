@@ -0,0 +1,403 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/internal/repository"
+	"github.com/otoritech/chatat/internal/ws"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+const maxCommentLength = 2000
+
+// AddCommentInput holds data for starting a comment thread on a block.
+// Range optionally narrows the thread to part of the block's content; its
+// Quote is filled in from the block.
+type AddCommentInput struct {
+	BlockID uuid.UUID           `json:"blockId"`
+	Content string              `json:"content"`
+	Range   *model.CommentRange `json:"range"`
+}
+
+// DocumentCommentService manages comment threads on document blocks.
+// Anyone with access to a document can comment, including viewers, and
+// comments stay open on locked documents.
+type DocumentCommentService interface {
+	AddComment(ctx context.Context, docID, userID uuid.UUID, input AddCommentInput) (*model.DocumentComment, error)
+	// Reply adds a comment to the thread that commentID belongs to.
+	Reply(ctx context.Context, docID, commentID, userID uuid.UUID, content string) (*model.DocumentComment, error)
+	// ListThreads returns the document's threads with their replies, oldest
+	// first. A non-nil blockID limits them to that block.
+	ListThreads(ctx context.Context, docID, userID uuid.UUID, blockID *uuid.UUID) ([]*model.DocumentComment, error)
+	// ResolveThread and ReopenThread are open to editors and the thread's author.
+	ResolveThread(ctx context.Context, docID, commentID, userID uuid.UUID) (*model.DocumentComment, error)
+	ReopenThread(ctx context.Context, docID, commentID, userID uuid.UUID) (*model.DocumentComment, error)
+	// DeleteComment removes a comment, and its replies when it starts a
+	// thread. Only its author and the document owner can delete it.
+	DeleteComment(ctx context.Context, docID, commentID, userID uuid.UUID) error
+}
+
+type documentCommentService struct {
+	commentRepo repository.DocumentCommentRepository
+	docRepo     repository.DocumentRepository
+	blockRepo   repository.BlockRepository
+	mentionSvc  MentionService
+	hub         *ws.Hub
+}
+
+// NewDocumentCommentService creates a new DocumentCommentService.
+func NewDocumentCommentService(
+	commentRepo repository.DocumentCommentRepository,
+	docRepo repository.DocumentRepository,
+	blockRepo repository.BlockRepository,
+	mentionSvc MentionService,
+	hub *ws.Hub,
+) DocumentCommentService {
+	return &documentCommentService{
+		commentRepo: commentRepo,
+		docRepo:     docRepo,
+		blockRepo:   blockRepo,
+		mentionSvc:  mentionSvc,
+		hub:         hub,
+	}
+}
+
+// commentAccess is a user's standing on a document whose comments they use.
+type commentAccess struct {
+	doc     *model.Document
+	canEdit bool
+	// members are the users that can be mentioned in a comment.
+	members map[uuid.UUID]bool
+}
+
+// commentEvent is the doc_comment payload sent to the document room.
+type commentEvent struct {
+	DocumentID uuid.UUID              `json:"documentId"`
+	Action     string                 `json:"action"`
+	Comment    *model.DocumentComment `json:"comment"`
+}
+
+func (s *documentCommentService) AddComment(ctx context.Context, docID, userID uuid.UUID, input AddCommentInput) (*model.DocumentComment, error) {
+	content, err := validateCommentContent(input.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	access, err := s.access(ctx, docID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := s.blockRepo.FindByID(ctx, input.BlockID)
+	if err != nil {
+		return nil, err
+	}
+	if block.DocumentID != docID {
+		return nil, apperror.NotFound("block", input.BlockID.String())
+	}
+
+	var commentRange *model.CommentRange
+	if input.Range != nil {
+		runes := []rune(block.Content)
+		start, end := input.Range.Start, input.Range.End
+		if start < 0 || end <= start || end > len(runes) {
+			return nil, apperror.Validation("range", "rentang teks tidak valid")
+		}
+		commentRange = &model.CommentRange{Start: start, End: end, Quote: string(runes[start:end])}
+	}
+
+	mentioned, err := resolveMentions(content, userID, access.members)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := s.commentRepo.Create(ctx, model.CreateDocumentCommentInput{
+		DocumentID: docID,
+		BlockID:    block.ID,
+		AuthorID:   userID,
+		Content:    content,
+		Range:      commentRange,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create comment: %w", err)
+	}
+
+	s.recordCommentMentions(ctx, comment, mentioned)
+	s.broadcast(docID, "created", comment)
+	return comment, nil
+}
+
+func (s *documentCommentService) Reply(ctx context.Context, docID, commentID, userID uuid.UUID, content string) (*model.DocumentComment, error) {
+	content, err := validateCommentContent(content)
+	if err != nil {
+		return nil, err
+	}
+
+	access, err := s.access(ctx, docID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	thread, err := s.findThread(ctx, docID, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	mentioned, err := resolveMentions(content, userID, access.members)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := s.commentRepo.Create(ctx, model.CreateDocumentCommentInput{
+		DocumentID: docID,
+		BlockID:    thread.BlockID,
+		ParentID:   &thread.ID,
+		AuthorID:   userID,
+		Content:    content,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create reply: %w", err)
+	}
+
+	s.recordCommentMentions(ctx, reply, mentioned)
+	s.broadcast(docID, "created", reply)
+	return reply, nil
+}
+
+func (s *documentCommentService) ListThreads(ctx context.Context, docID, userID uuid.UUID, blockID *uuid.UUID) ([]*model.DocumentComment, error) {
+	if _, err := s.access(ctx, docID, userID); err != nil {
+		return nil, err
+	}
+
+	comments, err := s.commentRepo.ListByDocument(ctx, docID, blockID)
+	if err != nil {
+		return nil, fmt.Errorf("list comments: %w", err)
+	}
+
+	if s.mentionSvc != nil && len(comments) > 0 {
+		ids := make([]uuid.UUID, len(comments))
+		for i, c := range comments {
+			ids[i] = c.ID
+		}
+		mentions, err := s.mentionSvc.ForItems(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range comments {
+			c.Mentions = mentions[c.ID]
+		}
+	}
+
+	threads := make([]*model.DocumentComment, 0)
+	byID := make(map[uuid.UUID]*model.DocumentComment)
+	for _, c := range comments {
+		if c.ParentID == nil {
+			threads = append(threads, c)
+			byID[c.ID] = c
+		}
+	}
+	for _, c := range comments {
+		if c.ParentID == nil {
+			continue
+		}
+		if thread := byID[*c.ParentID]; thread != nil {
+			thread.Replies = append(thread.Replies, c)
+		}
+	}
+	return threads, nil
+}
+
+func (s *documentCommentService) ResolveThread(ctx context.Context, docID, commentID, userID uuid.UUID) (*model.DocumentComment, error) {
+	thread, err := s.requireResolver(ctx, docID, commentID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if thread.ResolvedAt != nil {
+		return nil, apperror.BadRequest("thread sudah diselesaikan")
+	}
+
+	resolved, err := s.commentRepo.SetResolved(ctx, thread.ID, &userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.broadcast(docID, "resolved", resolved)
+	return resolved, nil
+}
+
+func (s *documentCommentService) ReopenThread(ctx context.Context, docID, commentID, userID uuid.UUID) (*model.DocumentComment, error) {
+	thread, err := s.requireResolver(ctx, docID, commentID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if thread.ResolvedAt == nil {
+		return nil, apperror.BadRequest("thread belum diselesaikan")
+	}
+
+	reopened, err := s.commentRepo.SetResolved(ctx, thread.ID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s.broadcast(docID, "reopened", reopened)
+	return reopened, nil
+}
+
+func (s *documentCommentService) DeleteComment(ctx context.Context, docID, commentID, userID uuid.UUID) error {
+	access, err := s.access(ctx, docID, userID)
+	if err != nil {
+		return err
+	}
+
+	comment, err := s.commentRepo.FindByID(ctx, commentID)
+	if err != nil {
+		return err
+	}
+	if comment.DocumentID != docID {
+		return apperror.NotFound("comment", commentID.String())
+	}
+	if comment.AuthorID != userID && access.doc.OwnerID != userID {
+		return apperror.Forbidden("hanya penulis komentar atau pemilik dokumen yang dapat menghapus komentar")
+	}
+
+	// Replies go with their thread, so their mentions are cleared too
+	cleared := []uuid.UUID{comment.ID}
+	if comment.ParentID == nil {
+		replies, err := s.commentRepo.ListReplies(ctx, comment.ID)
+		if err != nil {
+			return fmt.Errorf("list replies: %w", err)
+		}
+		for _, r := range replies {
+			cleared = append(cleared, r.ID)
+		}
+	}
+
+	if err := s.commentRepo.Delete(ctx, comment.ID); err != nil {
+		return err
+	}
+	if s.mentionSvc != nil {
+		for _, id := range cleared {
+			_ = s.mentionSvc.Clear(ctx, id)
+		}
+	}
+
+	s.broadcast(docID, "deleted", comment)
+	return nil
+}
+
+// --- Helper Methods ---
+
+// access checks that the user owns or collaborates on the document.
+func (s *documentCommentService) access(ctx context.Context, docID, userID uuid.UUID) (*commentAccess, error) {
+	doc, err := s.docRepo.FindByID(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
+	collabs, err := s.docRepo.ListCollaborators(ctx, docID)
+	if err != nil {
+		return nil, fmt.Errorf("list collaborators: %w", err)
+	}
+
+	access := &commentAccess{
+		doc:     doc,
+		canEdit: doc.OwnerID == userID,
+		members: map[uuid.UUID]bool{doc.OwnerID: true},
+	}
+	isMember := doc.OwnerID == userID
+	for _, c := range collabs {
+		access.members[c.UserID] = true
+		if c.UserID == userID {
+			isMember = true
+			access.canEdit = c.Role == model.CollaboratorRoleEditor
+		}
+	}
+	if !isMember {
+		return nil, apperror.Forbidden("anda tidak memiliki akses ke dokumen ini")
+	}
+	return access, nil
+}
+
+// findThread returns the thread that commentID starts or replies to.
+func (s *documentCommentService) findThread(ctx context.Context, docID, commentID uuid.UUID) (*model.DocumentComment, error) {
+	comment, err := s.commentRepo.FindByID(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.DocumentID != docID {
+		return nil, apperror.NotFound("comment", commentID.String())
+	}
+	if comment.ParentID == nil {
+		return comment, nil
+	}
+	return s.commentRepo.FindByID(ctx, *comment.ParentID)
+}
+
+// requireResolver returns the thread if the user may resolve or reopen it.
+func (s *documentCommentService) requireResolver(ctx context.Context, docID, commentID, userID uuid.UUID) (*model.DocumentComment, error) {
+	access, err := s.access(ctx, docID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := s.commentRepo.FindByID(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.DocumentID != docID {
+		return nil, apperror.NotFound("comment", commentID.String())
+	}
+	if comment.ParentID != nil {
+		return nil, apperror.BadRequest("hanya thread yang dapat diselesaikan atau dibuka kembali")
+	}
+	if !access.canEdit && comment.AuthorID != userID {
+		return nil, apperror.Forbidden("anda tidak memiliki izin untuk mengubah status thread ini")
+	}
+	return comment, nil
+}
+
+func (s *documentCommentService) recordCommentMentions(ctx context.Context, comment *model.DocumentComment, mentioned []uuid.UUID) {
+	if len(mentioned) == 0 {
+		return
+	}
+	comment.Mentions = mentioned
+	recordMentions(ctx, s.mentionSvc, model.MentionTarget{
+		SourceType:  model.MentionSourceComment,
+		SourceID:    comment.DocumentID,
+		ItemID:      comment.ID,
+		MentionedBy: comment.AuthorID,
+		Preview:     comment.Content,
+	}, mentioned)
+}
+
+// broadcast pushes a comment change to everyone in the document room.
+func (s *documentCommentService) broadcast(docID uuid.UUID, action string, comment *model.DocumentComment) {
+	if s.hub == nil {
+		return
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"type":    ws.WSTypeDocComment,
+		"payload": commentEvent{DocumentID: docID, Action: action, Comment: comment},
+	})
+	if err == nil {
+		s.hub.SendToRoom("doc:"+docID.String(), data, uuid.Nil)
+	}
+}
+
+func validateCommentContent(content string) (string, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return "", apperror.Validation("content", "komentar tidak boleh kosong")
+	}
+	if utf8.RuneCountInString(content) > maxCommentLength {
+		return "", apperror.Validation("content", fmt.Sprintf("komentar maksimal %d karakter", maxCommentLength))
+	}
+	return content, nil
+}
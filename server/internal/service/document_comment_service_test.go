@@ -0,0 +1,459 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/internal/ws"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+// -- Mock DocumentCommentRepository --
+
+type mockDocumentCommentRepo struct {
+	comments []*model.DocumentComment
+}
+
+func (m *mockDocumentCommentRepo) Create(_ context.Context, input model.CreateDocumentCommentInput) (*model.DocumentComment, error) {
+	c := &model.DocumentComment{
+		ID:         uuid.New(),
+		DocumentID: input.DocumentID,
+		BlockID:    input.BlockID,
+		ParentID:   input.ParentID,
+		AuthorID:   input.AuthorID,
+		Content:    input.Content,
+		Range:      input.Range,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+	m.comments = append(m.comments, c)
+	return c, nil
+}
+
+func (m *mockDocumentCommentRepo) FindByID(_ context.Context, id uuid.UUID) (*model.DocumentComment, error) {
+	for _, c := range m.comments {
+		if c.ID == id {
+			copied := *c
+			return &copied, nil
+		}
+	}
+	return nil, apperror.NotFound("comment", id.String())
+}
+
+func (m *mockDocumentCommentRepo) ListByDocument(_ context.Context, docID uuid.UUID, blockID *uuid.UUID) ([]*model.DocumentComment, error) {
+	result := make([]*model.DocumentComment, 0)
+	for _, c := range m.comments {
+		if c.DocumentID == docID && (blockID == nil || c.BlockID == *blockID) {
+			copied := *c
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockDocumentCommentRepo) ListReplies(_ context.Context, parentID uuid.UUID) ([]*model.DocumentComment, error) {
+	result := make([]*model.DocumentComment, 0)
+	for _, c := range m.comments {
+		if c.ParentID != nil && *c.ParentID == parentID {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockDocumentCommentRepo) SetResolved(_ context.Context, id uuid.UUID, resolvedBy *uuid.UUID) (*model.DocumentComment, error) {
+	for _, c := range m.comments {
+		if c.ID == id && c.ParentID == nil {
+			c.ResolvedBy = resolvedBy
+			c.ResolvedAt = nil
+			if resolvedBy != nil {
+				now := time.Now()
+				c.ResolvedAt = &now
+			}
+			copied := *c
+			return &copied, nil
+		}
+	}
+	return nil, apperror.NotFound("comment", id.String())
+}
+
+func (m *mockDocumentCommentRepo) Delete(_ context.Context, id uuid.UUID) error {
+	kept := make([]*model.DocumentComment, 0, len(m.comments))
+	found := false
+	for _, c := range m.comments {
+		if c.ID == id || (c.ParentID != nil && *c.ParentID == id) {
+			found = found || c.ID == id
+			continue
+		}
+		kept = append(kept, c)
+	}
+	if !found {
+		return apperror.NotFound("comment", id.String())
+	}
+	m.comments = kept
+	return nil
+}
+
+func TestDocumentCommentService_AddComment(t *testing.T) {
+	ctx := context.Background()
+	docRepo := newMockDocumentRepo()
+	blockRepo := newMockBlockRepo()
+	commentRepo := &mockDocumentCommentRepo{}
+	mentionRepo := newMockMentionRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	svc := NewDocumentCommentService(commentRepo, docRepo, blockRepo, NewMentionService(mentionRepo, newMockUserRepo(), nil), hub)
+
+	ownerID := uuid.New()
+	editorID := uuid.New()
+	viewerID := uuid.New()
+	doc, err := docRepo.Create(ctx, model.CreateDocumentInput{Title: "Notulen", OwnerID: ownerID, IsStandalone: true})
+	require.NoError(t, err)
+	require.NoError(t, docRepo.AddCollaborator(ctx, doc.ID, editorID, model.CollaboratorRoleEditor))
+	require.NoError(t, docRepo.AddCollaborator(ctx, doc.ID, viewerID, model.CollaboratorRoleViewer))
+	block, err := blockRepo.Create(ctx, model.CreateBlockInput{
+		DocumentID: doc.ID, Type: model.BlockTypeParagraph, Content: "Rapat dimulai jam sembilan",
+	})
+	require.NoError(t, err)
+
+	t.Run("viewer can comment", func(t *testing.T) {
+		c, err := svc.AddComment(ctx, doc.ID, viewerID, AddCommentInput{BlockID: block.ID, Content: "  Jam berapa tepatnya?  "})
+		require.NoError(t, err)
+		assert.Equal(t, "Jam berapa tepatnya?", c.Content)
+		assert.Equal(t, block.ID, c.BlockID)
+		assert.Nil(t, c.ParentID)
+	})
+
+	t.Run("locked document accepts comments", func(t *testing.T) {
+		docRepo.docs[doc.ID].Locked = true
+		defer func() { docRepo.docs[doc.ID].Locked = false }()
+
+		_, err := svc.AddComment(ctx, doc.ID, editorID, AddCommentInput{BlockID: block.ID, Content: "Sudah final"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("text range quotes the block", func(t *testing.T) {
+		c, err := svc.AddComment(ctx, doc.ID, ownerID, AddCommentInput{
+			BlockID: block.ID,
+			Content: "Ganti jadi jam sepuluh",
+			Range:   &model.CommentRange{Start: 18, End: 26, Quote: "diabaikan"},
+		})
+		require.NoError(t, err)
+		require.NotNil(t, c.Range)
+		assert.Equal(t, "sembilan", c.Range.Quote)
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		before := len(commentRepo.comments)
+		for _, r := range []model.CommentRange{{Start: -1, End: 3}, {Start: 4, End: 4}, {Start: 0, End: 100}} {
+			_, err := svc.AddComment(ctx, doc.ID, ownerID, AddCommentInput{BlockID: block.ID, Content: "x", Range: &r})
+			assert.Error(t, err, fmt.Sprintf("%d-%d", r.Start, r.End))
+		}
+		assert.Len(t, commentRepo.comments, before)
+	})
+
+	t.Run("outsider cannot comment", func(t *testing.T) {
+		_, err := svc.AddComment(ctx, doc.ID, uuid.New(), AddCommentInput{BlockID: block.ID, Content: "Halo"})
+		assert.True(t, apperror.IsForbidden(err))
+	})
+
+	t.Run("block from another document", func(t *testing.T) {
+		other, err := docRepo.Create(ctx, model.CreateDocumentInput{Title: "Lain", OwnerID: ownerID})
+		require.NoError(t, err)
+
+		_, err = svc.AddComment(ctx, other.ID, ownerID, AddCommentInput{BlockID: block.ID, Content: "Halo"})
+		assert.True(t, apperror.IsNotFound(err))
+	})
+
+	t.Run("empty and oversized content", func(t *testing.T) {
+		_, err := svc.AddComment(ctx, doc.ID, ownerID, AddCommentInput{BlockID: block.ID, Content: "   "})
+		assert.Error(t, err)
+		_, err = svc.AddComment(ctx, doc.ID, ownerID, AddCommentInput{BlockID: block.ID, Content: strings.Repeat("a", maxCommentLength+1)})
+		assert.Error(t, err)
+	})
+
+	t.Run("mentions collaborators", func(t *testing.T) {
+		before := len(mentionRepo.mentions)
+		c, err := svc.AddComment(ctx, doc.ID, ownerID, AddCommentInput{
+			BlockID: block.ID,
+			Content: fmt.Sprintf("@[Viewer](%s) tolong cek", viewerID),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{viewerID}, c.Mentions)
+		require.Len(t, mentionRepo.mentions, before+1)
+		mention := mentionRepo.mentions[before]
+		assert.Equal(t, model.MentionSourceComment, mention.SourceType)
+		assert.Equal(t, c.ID, mention.ItemID)
+	})
+
+	t.Run("cannot mention outsiders", func(t *testing.T) {
+		before := len(commentRepo.comments)
+		_, err := svc.AddComment(ctx, doc.ID, ownerID, AddCommentInput{
+			BlockID: block.ID,
+			Content: fmt.Sprintf("@[Orang](%s) halo", uuid.New()),
+		})
+		assert.Error(t, err)
+		assert.Len(t, commentRepo.comments, before)
+	})
+
+	t.Run("pushed to the document room", func(t *testing.T) {
+		observer := &ws.Client{UserID: editorID, DeviceID: "d1", Send: make(chan []byte, 16), Hub: hub}
+		hub.RegisterClient(observer)
+		time.Sleep(20 * time.Millisecond)
+		hub.JoinRoom(observer, "doc:"+doc.ID.String())
+
+		_, err := svc.AddComment(ctx, doc.ID, ownerID, AddCommentInput{BlockID: block.ID, Content: "Setuju"})
+		require.NoError(t, err)
+
+		select {
+		case data := <-observer.Send:
+			assert.Contains(t, string(data), `"type":"doc_comment"`)
+			assert.Contains(t, string(data), `"action":"created"`)
+			assert.Contains(t, string(data), `"content":"Setuju"`)
+		case <-time.After(time.Second):
+			t.Fatal("expected doc_comment event")
+		}
+	})
+}
+
+func TestDocumentCommentService_Reply(t *testing.T) {
+	ctx := context.Background()
+	docRepo := newMockDocumentRepo()
+	blockRepo := newMockBlockRepo()
+	commentRepo := &mockDocumentCommentRepo{}
+	mentionRepo := newMockMentionRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	svc := NewDocumentCommentService(commentRepo, docRepo, blockRepo, NewMentionService(mentionRepo, newMockUserRepo(), nil), hub)
+
+	ownerID := uuid.New()
+	editorID := uuid.New()
+	viewerID := uuid.New()
+	doc, err := docRepo.Create(ctx, model.CreateDocumentInput{Title: "Notulen", OwnerID: ownerID, IsStandalone: true})
+	require.NoError(t, err)
+	require.NoError(t, docRepo.AddCollaborator(ctx, doc.ID, editorID, model.CollaboratorRoleEditor))
+	require.NoError(t, docRepo.AddCollaborator(ctx, doc.ID, viewerID, model.CollaboratorRoleViewer))
+	block, err := blockRepo.Create(ctx, model.CreateBlockInput{
+		DocumentID: doc.ID, Type: model.BlockTypeParagraph, Content: "Rapat dimulai jam sembilan",
+	})
+	require.NoError(t, err)
+
+	thread, err := svc.AddComment(ctx, doc.ID, ownerID, AddCommentInput{BlockID: block.ID, Content: "Perlu direvisi?"})
+	require.NoError(t, err)
+
+	t.Run("viewer can reply", func(t *testing.T) {
+		reply, err := svc.Reply(ctx, doc.ID, thread.ID, viewerID, "Tidak perlu")
+		require.NoError(t, err)
+		require.NotNil(t, reply.ParentID)
+		assert.Equal(t, thread.ID, *reply.ParentID)
+		assert.Equal(t, thread.BlockID, reply.BlockID)
+	})
+
+	t.Run("reply to a reply joins the thread", func(t *testing.T) {
+		first, err := svc.Reply(ctx, doc.ID, thread.ID, editorID, "Ya")
+		require.NoError(t, err)
+
+		second, err := svc.Reply(ctx, doc.ID, first.ID, viewerID, "Setuju")
+		require.NoError(t, err)
+		assert.Equal(t, thread.ID, *second.ParentID)
+	})
+
+	t.Run("comment from another document", func(t *testing.T) {
+		other, err := docRepo.Create(ctx, model.CreateDocumentInput{Title: "Lain", OwnerID: ownerID})
+		require.NoError(t, err)
+
+		_, err = svc.Reply(ctx, other.ID, thread.ID, ownerID, "Ya")
+		assert.True(t, apperror.IsNotFound(err))
+	})
+}
+
+func TestDocumentCommentService_ListThreads(t *testing.T) {
+	ctx := context.Background()
+	docRepo := newMockDocumentRepo()
+	blockRepo := newMockBlockRepo()
+	commentRepo := &mockDocumentCommentRepo{}
+	mentionRepo := newMockMentionRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	svc := NewDocumentCommentService(commentRepo, docRepo, blockRepo, NewMentionService(mentionRepo, newMockUserRepo(), nil), hub)
+
+	ownerID := uuid.New()
+	editorID := uuid.New()
+	viewerID := uuid.New()
+	doc, err := docRepo.Create(ctx, model.CreateDocumentInput{Title: "Notulen", OwnerID: ownerID, IsStandalone: true})
+	require.NoError(t, err)
+	require.NoError(t, docRepo.AddCollaborator(ctx, doc.ID, editorID, model.CollaboratorRoleEditor))
+	require.NoError(t, docRepo.AddCollaborator(ctx, doc.ID, viewerID, model.CollaboratorRoleViewer))
+	block, err := blockRepo.Create(ctx, model.CreateBlockInput{
+		DocumentID: doc.ID, Type: model.BlockTypeParagraph, Content: "Rapat dimulai jam sembilan",
+	})
+	require.NoError(t, err)
+
+	first, err := svc.AddComment(ctx, doc.ID, ownerID, AddCommentInput{BlockID: block.ID, Content: fmt.Sprintf("@[Editor](%s) cek ini", editorID)})
+	require.NoError(t, err)
+	_, err = svc.Reply(ctx, doc.ID, first.ID, editorID, "Sudah")
+	require.NoError(t, err)
+	second, err := svc.AddComment(ctx, doc.ID, viewerID, AddCommentInput{BlockID: block.ID, Content: "Typo di sini"})
+	require.NoError(t, err)
+
+	t.Run("threads with replies", func(t *testing.T) {
+		threads, err := svc.ListThreads(ctx, doc.ID, viewerID, nil)
+		require.NoError(t, err)
+		require.Len(t, threads, 2)
+		assert.Equal(t, first.ID, threads[0].ID)
+		assert.Equal(t, []uuid.UUID{editorID}, threads[0].Mentions)
+		require.Len(t, threads[0].Replies, 1)
+		assert.Equal(t, "Sudah", threads[0].Replies[0].Content)
+		assert.Equal(t, second.ID, threads[1].ID)
+		assert.Empty(t, threads[1].Replies)
+	})
+
+	t.Run("filtered by block", func(t *testing.T) {
+		other := uuid.New()
+		threads, err := svc.ListThreads(ctx, doc.ID, ownerID, &other)
+		require.NoError(t, err)
+		assert.Empty(t, threads)
+	})
+
+	t.Run("outsider", func(t *testing.T) {
+		_, err := svc.ListThreads(ctx, doc.ID, uuid.New(), nil)
+		assert.True(t, apperror.IsForbidden(err))
+	})
+}
+
+func TestDocumentCommentService_ResolveAndReopen(t *testing.T) {
+	ctx := context.Background()
+	docRepo := newMockDocumentRepo()
+	blockRepo := newMockBlockRepo()
+	commentRepo := &mockDocumentCommentRepo{}
+	mentionRepo := newMockMentionRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	svc := NewDocumentCommentService(commentRepo, docRepo, blockRepo, NewMentionService(mentionRepo, newMockUserRepo(), nil), hub)
+
+	ownerID := uuid.New()
+	editorID := uuid.New()
+	viewerID := uuid.New()
+	doc, err := docRepo.Create(ctx, model.CreateDocumentInput{Title: "Notulen", OwnerID: ownerID, IsStandalone: true})
+	require.NoError(t, err)
+	require.NoError(t, docRepo.AddCollaborator(ctx, doc.ID, editorID, model.CollaboratorRoleEditor))
+	require.NoError(t, docRepo.AddCollaborator(ctx, doc.ID, viewerID, model.CollaboratorRoleViewer))
+	block, err := blockRepo.Create(ctx, model.CreateBlockInput{
+		DocumentID: doc.ID, Type: model.BlockTypeParagraph, Content: "Rapat dimulai jam sembilan",
+	})
+	require.NoError(t, err)
+
+	t.Run("editor resolves and reopens", func(t *testing.T) {
+		thread, err := svc.AddComment(ctx, doc.ID, viewerID, AddCommentInput{BlockID: block.ID, Content: "Typo di sini"})
+		require.NoError(t, err)
+
+		resolved, err := svc.ResolveThread(ctx, doc.ID, thread.ID, editorID)
+		require.NoError(t, err)
+		require.NotNil(t, resolved.ResolvedAt)
+		assert.Equal(t, editorID, *resolved.ResolvedBy)
+
+		_, err = svc.ResolveThread(ctx, doc.ID, thread.ID, editorID)
+		assert.Error(t, err)
+
+		reopened, err := svc.ReopenThread(ctx, doc.ID, thread.ID, ownerID)
+		require.NoError(t, err)
+		assert.Nil(t, reopened.ResolvedAt)
+
+		_, err = svc.ReopenThread(ctx, doc.ID, thread.ID, ownerID)
+		assert.Error(t, err)
+	})
+
+	t.Run("viewer resolves own thread", func(t *testing.T) {
+		thread, err := svc.AddComment(ctx, doc.ID, viewerID, AddCommentInput{BlockID: block.ID, Content: "Typo di sini"})
+		require.NoError(t, err)
+
+		_, err = svc.ResolveThread(ctx, doc.ID, thread.ID, viewerID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("viewer cannot resolve others' threads", func(t *testing.T) {
+		thread, err := svc.AddComment(ctx, doc.ID, ownerID, AddCommentInput{BlockID: block.ID, Content: "Perlu direvisi?"})
+		require.NoError(t, err)
+
+		_, err = svc.ResolveThread(ctx, doc.ID, thread.ID, viewerID)
+		assert.True(t, apperror.IsForbidden(err))
+	})
+
+	t.Run("replies cannot be resolved", func(t *testing.T) {
+		thread, err := svc.AddComment(ctx, doc.ID, ownerID, AddCommentInput{BlockID: block.ID, Content: "Perlu direvisi?"})
+		require.NoError(t, err)
+		reply, err := svc.Reply(ctx, doc.ID, thread.ID, editorID, "Ya")
+		require.NoError(t, err)
+
+		_, err = svc.ResolveThread(ctx, doc.ID, reply.ID, ownerID)
+		assert.Error(t, err)
+	})
+}
+
+func TestDocumentCommentService_DeleteComment(t *testing.T) {
+	ctx := context.Background()
+	docRepo := newMockDocumentRepo()
+	blockRepo := newMockBlockRepo()
+	commentRepo := &mockDocumentCommentRepo{}
+	mentionRepo := newMockMentionRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	svc := NewDocumentCommentService(commentRepo, docRepo, blockRepo, NewMentionService(mentionRepo, newMockUserRepo(), nil), hub)
+
+	ownerID := uuid.New()
+	editorID := uuid.New()
+	viewerID := uuid.New()
+	doc, err := docRepo.Create(ctx, model.CreateDocumentInput{Title: "Notulen", OwnerID: ownerID, IsStandalone: true})
+	require.NoError(t, err)
+	require.NoError(t, docRepo.AddCollaborator(ctx, doc.ID, editorID, model.CollaboratorRoleEditor))
+	require.NoError(t, docRepo.AddCollaborator(ctx, doc.ID, viewerID, model.CollaboratorRoleViewer))
+	block, err := blockRepo.Create(ctx, model.CreateBlockInput{
+		DocumentID: doc.ID, Type: model.BlockTypeParagraph, Content: "Rapat dimulai jam sembilan",
+	})
+	require.NoError(t, err)
+
+	t.Run("owner deletes a thread with its replies", func(t *testing.T) {
+		comments, mentions := len(commentRepo.comments), len(mentionRepo.mentions)
+		thread, err := svc.AddComment(ctx, doc.ID, viewerID, AddCommentInput{BlockID: block.ID, Content: "Typo di sini"})
+		require.NoError(t, err)
+		_, err = svc.Reply(ctx, doc.ID, thread.ID, editorID, fmt.Sprintf("@[Viewer](%s) yang mana?", viewerID))
+		require.NoError(t, err)
+		require.Len(t, mentionRepo.mentions, mentions+1)
+
+		require.NoError(t, svc.DeleteComment(ctx, doc.ID, thread.ID, ownerID))
+		assert.Len(t, commentRepo.comments, comments)
+		assert.Len(t, mentionRepo.mentions, mentions)
+	})
+
+	t.Run("author deletes own reply", func(t *testing.T) {
+		thread, err := svc.AddComment(ctx, doc.ID, ownerID, AddCommentInput{BlockID: block.ID, Content: "Perlu direvisi?"})
+		require.NoError(t, err)
+		reply, err := svc.Reply(ctx, doc.ID, thread.ID, viewerID, "Tidak")
+		require.NoError(t, err)
+		comments := len(commentRepo.comments)
+
+		require.NoError(t, svc.DeleteComment(ctx, doc.ID, reply.ID, viewerID))
+		assert.Len(t, commentRepo.comments, comments-1)
+	})
+
+	t.Run("editor cannot delete others' comments", func(t *testing.T) {
+		thread, err := svc.AddComment(ctx, doc.ID, viewerID, AddCommentInput{BlockID: block.ID, Content: "Typo di sini"})
+		require.NoError(t, err)
+
+		err = svc.DeleteComment(ctx, doc.ID, thread.ID, editorID)
+		assert.True(t, apperror.IsForbidden(err))
+	})
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/internal/repository"
+	"github.com/otoritech/chatat/internal/ws"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+// LiveDocuments hands documents open for realtime editing over to writes
+// made outside it, see ws.DocumentCRDTManager.Suspend.
+type LiveDocuments interface {
+	Suspend(ctx context.Context, docID uuid.UUID) (resume func(), err error)
+}
+
+// suspendLive takes a document over from realtime editing before a REST
+// write, so its unsaved edits are saved first and its clients resync with
+// the write afterwards. The returned resume must be called once the write
+// is done.
+func suspendLive(ctx context.Context, live LiveDocuments, docID uuid.UUID) (func(), error) {
+	if live == nil {
+		return func() {}, nil
+	}
+	resume, err := live.Suspend(ctx, docID)
+	if errors.Is(err, ws.ErrDocumentBusy) {
+		return nil, apperror.Conflict("dokumen sedang disunting, coba lagi")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("suspend realtime editing: %w", err)
+	}
+	return resume, nil
+}
+
+// documentCRDTStore keeps realtime document edits in the blocks table.
+// Saved edits capture versions and record mentions like the block service
+// does for REST edits.
+type documentCRDTStore struct {
+	blockRepo  repository.BlockRepository
+	docRepo    repository.DocumentRepository
+	mentionSvc MentionService
+	versionSvc DocumentVersionService
+}
+
+// NewDocumentCRDTStore creates the store that seeds document CRDTs from the
+// blocks table and writes their accepted edits back.
+func NewDocumentCRDTStore(
+	blockRepo repository.BlockRepository,
+	docRepo repository.DocumentRepository,
+	mentionSvc MentionService,
+	versionSvc DocumentVersionService,
+) ws.CRDTStore {
+	return &documentCRDTStore{
+		blockRepo:  blockRepo,
+		docRepo:    docRepo,
+		mentionSvc: mentionSvc,
+		versionSvc: versionSvc,
+	}
+}
+
+func (s *documentCRDTStore) LoadBlocks(ctx context.Context, docID uuid.UUID) ([]ws.BlockSeed, error) {
+	blocks, err := s.blockRepo.ListByDocument(ctx, docID)
+	if err != nil {
+		return nil, fmt.Errorf("list blocks: %w", err)
+	}
+
+	seeds := make([]ws.BlockSeed, 0, len(blocks))
+	for _, b := range blocks {
+		seed := ws.BlockSeed{
+			BlockID:  b.ID,
+			ParentID: b.ParentBlockID,
+			Type:     string(b.Type),
+			Content:  b.Content,
+		}
+		if b.Checked != nil {
+			seed.Checked = strconv.FormatBool(*b.Checked)
+		}
+		seeds = append(seeds, seed)
+	}
+	return seeds, nil
+}
+
+func (s *documentCRDTStore) SaveBlocks(ctx context.Context, docID uuid.UUID, changes []ws.BlockChange) error {
+	var editor uuid.UUID
+	deletes := false
+	states := make([]model.BlockState, 0, len(changes))
+	for _, change := range changes {
+		if change.EditedBy != uuid.Nil {
+			editor = change.EditedBy
+		}
+		deletes = deletes || change.Deleted
+
+		state := model.BlockState{
+			ID:      change.BlockID,
+			Content: change.Content,
+			Deleted: change.Deleted,
+		}
+		if change.Checked != nil {
+			if checked, err := strconv.ParseBool(*change.Checked); err == nil {
+				state.Checked = &checked
+			}
+		}
+		if change.Placed {
+			position := change.Position
+			state.SortOrder = &position
+			state.ParentBlockID = change.ParentID
+			// Blocks of an unknown type can be updated but not created
+			if validateBlockType(model.BlockType(change.Type)) == nil {
+				state.Type = model.BlockType(change.Type)
+			}
+		}
+		states = append(states, state)
+	}
+
+	// As over REST, deleted content is captured before the blocks go away
+	if deletes {
+		captureEditVersion(ctx, s.versionSvc, docID, editor)
+	}
+	if err := s.blockRepo.SaveStates(ctx, docID, states); err != nil {
+		return fmt.Errorf("save block states: %w", err)
+	}
+	if !deletes {
+		captureEditVersion(ctx, s.versionSvc, docID, editor)
+	}
+
+	s.recordMentions(ctx, docID, changes)
+	return nil
+}
+
+// recordMentions brings the mentions of saved blocks in line with their
+// content. The edits were accepted long before they are saved, so mentions
+// of users outside the document are dropped rather than rejected.
+func (s *documentCRDTStore) recordMentions(ctx context.Context, docID uuid.UUID, changes []ws.BlockChange) {
+	if s.mentionSvc == nil {
+		return
+	}
+
+	var edited []ws.BlockChange
+	var editedIDs []uuid.UUID
+	for _, change := range changes {
+		if change.Deleted {
+			_ = s.mentionSvc.Clear(ctx, change.BlockID)
+			continue
+		}
+		// Blocks only renumbered keep their mentions
+		if change.Content != nil && change.EditedBy != uuid.Nil {
+			edited = append(edited, change)
+			editedIDs = append(editedIDs, change.BlockID)
+		}
+	}
+	if len(edited) == 0 {
+		return
+	}
+
+	existing, err := s.mentionSvc.ForItems(ctx, editedIDs)
+	if err != nil {
+		log.Warn().Err(err).Str("document_id", docID.String()).Msg("failed to load block mentions")
+		return
+	}
+
+	var allowed map[uuid.UUID]bool
+	for _, change := range edited {
+		content := *change.Content
+		// Skip the sync when neither the old nor the new content mentions anyone
+		if !mentionPattern.MatchString(content) && len(existing[change.BlockID]) == 0 {
+			continue
+		}
+		if allowed == nil {
+			doc, err := s.docRepo.FindByID(ctx, docID)
+			if err == nil {
+				allowed, err = mentionableUsers(ctx, s.docRepo, doc)
+			}
+			if err != nil {
+				log.Warn().Err(err).Str("document_id", docID.String()).Msg("failed to resolve block mentions")
+				return
+			}
+		}
+
+		var mentioned []uuid.UUID
+		for _, id := range parseMentions(content) {
+			if id != change.EditedBy && allowed[id] {
+				mentioned = append(mentioned, id)
+			}
+		}
+		recordMentions(ctx, s.mentionSvc, model.MentionTarget{
+			SourceType:  model.MentionSourceDocument,
+			SourceID:    docID,
+			ItemID:      change.BlockID,
+			MentionedBy: change.EditedBy,
+			Preview:     content,
+		}, mentioned)
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/internal/ws"
+)
+
+func TestDocumentCRDTStore_LoadBlocks(t *testing.T) {
+	blockRepo := newMockBlockRepo()
+	store := NewDocumentCRDTStore(blockRepo, newMockDocumentRepo(), nil, nil)
+	docID := uuid.New()
+	parentID := uuid.New()
+	checked := true
+
+	blockRepo.blocks[parentID] = &model.Block{ID: parentID, DocumentID: docID, Type: model.BlockTypeToggle, Content: "Rincian"}
+	childID := uuid.New()
+	blockRepo.blocks[childID] = &model.Block{
+		ID: childID, DocumentID: docID, Type: model.BlockTypeChecklist, Content: "Tugas",
+		Checked: &checked, ParentBlockID: &parentID, SortOrder: 1,
+	}
+
+	seeds, err := store.LoadBlocks(context.Background(), docID)
+	require.NoError(t, err)
+	require.Len(t, seeds, 2)
+	for _, seed := range seeds {
+		if seed.BlockID == childID {
+			assert.Equal(t, ws.BlockSeed{BlockID: childID, ParentID: &parentID, Type: "checklist", Content: "Tugas", Checked: "true"}, seed)
+		} else {
+			assert.Equal(t, "", seed.Checked)
+		}
+	}
+
+	blockRepo.listErr = errors.New("db down")
+	_, err = store.LoadBlocks(context.Background(), docID)
+	assert.Error(t, err)
+}
+
+func TestDocumentCRDTStore_SaveBlocks(t *testing.T) {
+	blockRepo := newMockBlockRepo()
+	store := NewDocumentCRDTStore(blockRepo, newMockDocumentRepo(), nil, nil)
+	docID := uuid.New()
+	parentID := uuid.New()
+	content := "Halo"
+	checked := "false"
+	invalid := "yes"
+
+	changes := []ws.BlockChange{
+		{BlockID: uuid.New(), Placed: true, Type: "paragraph", Position: 0, Content: &content},
+		{BlockID: uuid.New(), Placed: true, ParentID: &parentID, Type: "widget", Position: 1, Checked: &checked},
+		{BlockID: uuid.New(), Checked: &invalid},
+		{BlockID: uuid.New(), Deleted: true},
+	}
+	require.NoError(t, store.SaveBlocks(context.Background(), docID, changes))
+	require.Len(t, blockRepo.savedStates, 1)
+	states := blockRepo.savedStates[0]
+	require.Len(t, states, 4)
+
+	assert.Equal(t, model.BlockTypeParagraph, states[0].Type)
+	assert.Equal(t, &content, states[0].Content)
+	require.NotNil(t, states[0].SortOrder)
+	assert.Equal(t, 0, *states[0].SortOrder)
+	assert.Nil(t, states[0].ParentBlockID)
+
+	assert.Empty(t, states[1].Type, "unknown types are never created")
+	assert.Equal(t, &parentID, states[1].ParentBlockID)
+	require.NotNil(t, states[1].Checked)
+	assert.False(t, *states[1].Checked)
+
+	assert.Nil(t, states[2].Checked)
+	assert.Nil(t, states[2].SortOrder)
+	assert.True(t, states[3].Deleted)
+
+	blockRepo.saveErr = errors.New("db down")
+	assert.Error(t, store.SaveBlocks(context.Background(), docID, changes))
+}
+
+func TestDocumentCRDTStore_SaveBlocks_Hooks(t *testing.T) {
+	ctx := context.Background()
+	docRepo := newMockDocumentRepo()
+	blockRepo := newMockBlockRepo()
+	versionRepo := newMockDocumentVersionRepo(docRepo, blockRepo)
+	versionSvc := NewDocumentVersionService(versionRepo, docRepo, blockRepo, &mockDocHistoryRepo{}, nil)
+	mentionRepo := newMockMentionRepo()
+	store := NewDocumentCRDTStore(blockRepo, docRepo, NewMentionService(mentionRepo, newMockUserRepo(), nil), versionSvc)
+	ownerID := uuid.New()
+	doc := createTestDoc(docRepo, ownerID)
+
+	collabID := uuid.New()
+	require.NoError(t, docRepo.AddCollaborator(ctx, doc.ID, collabID, model.CollaboratorRoleEditor))
+	block := createTestBlock(t, blockRepo, doc.ID, "Agenda")
+	other := createTestBlock(t, blockRepo, doc.ID, "Catatan")
+
+	content := "Tolong cek " + mentionToken("Collab", collabID) + " " + mentionToken("Orang lain", uuid.New())
+	require.NoError(t, store.SaveBlocks(ctx, doc.ID, []ws.BlockChange{
+		{BlockID: block.ID, Content: &content, EditedBy: ownerID},
+	}))
+
+	mentions, err := mentionRepo.ListByUser(ctx, collabID, nil, 10)
+	require.NoError(t, err)
+	require.Len(t, mentions, 1, "users outside the document are dropped")
+	assert.Equal(t, block.ID, mentions[0].ItemID)
+	assert.Equal(t, ownerID, mentions[0].MentionedBy)
+	require.Len(t, versionRepo.versions[doc.ID], 1)
+	assert.Equal(t, model.VersionReasonEdited, versionRepo.versions[doc.ID][0].Reason)
+
+	t.Run("deleted blocks lose their mentions", func(t *testing.T) {
+		require.NoError(t, store.SaveBlocks(ctx, doc.ID, []ws.BlockChange{
+			{BlockID: block.ID, Deleted: true, EditedBy: ownerID},
+			{BlockID: other.ID, Content: &other.Content},
+		}))
+		mentions, err := mentionRepo.ListByUser(ctx, collabID, nil, 10)
+		require.NoError(t, err)
+		assert.Empty(t, mentions)
+	})
+}
@@ -16,6 +16,7 @@ import (
 type DocumentService interface {
 	Create(ctx context.Context, input CreateDocumentInput) (*DocumentFull, error)
 	GetByID(ctx context.Context, docID, userID uuid.UUID) (*DocumentFull, error)
+	GetAccess(ctx context.Context, docID, userID uuid.UUID) (*DocumentAccess, error)
 	ListByContext(ctx context.Context, contextType string, contextID uuid.UUID) ([]*DocumentListItem, error)
 	ListAll(ctx context.Context, userID uuid.UUID) ([]*DocumentListItem, error)
 	Update(ctx context.Context, docID uuid.UUID, userID uuid.UUID, input model.UpdateDocumentInput) (*model.Document, error)
@@ -66,6 +67,14 @@ type DocumentCollaboratorInfo struct {
 	AddedAt time.Time              `json:"addedAt"`
 }
 
+// DocumentAccess is what a user may do with a document. Only the owner and
+// editors can edit, and only while the document is not locked; documents
+// out for signature are locked too.
+type DocumentAccess struct {
+	Document *model.Document
+	CanEdit  bool
+}
+
 // DocumentListItem is a summary for list views.
 type DocumentListItem struct {
 	ID          uuid.UUID `json:"id"`
@@ -85,6 +94,7 @@ type documentService struct {
 	userRepo    repository.UserRepository
 	templateSvc TemplateService
 	notifSvc    NotificationService
+	versionSvc  DocumentVersionService
 }
 
 // NewDocumentService creates a new document service.
@@ -95,6 +105,7 @@ func NewDocumentService(
 	userRepo repository.UserRepository,
 	templateSvc TemplateService,
 	notifSvc NotificationService,
+	versionSvc DocumentVersionService,
 ) DocumentService {
 	return &documentService{
 		docRepo:     docRepo,
@@ -103,6 +114,7 @@ func NewDocumentService(
 		userRepo:    userRepo,
 		templateSvc: templateSvc,
 		notifSvc:    notifSvc,
+		versionSvc:  versionSvc,
 	}
 }
 
@@ -247,6 +259,27 @@ func (s *documentService) GetByID(ctx context.Context, docID, userID uuid.UUID)
 	}, nil
 }
 
+// GetAccess loads a document for its owner or a collaborator.
+func (s *documentService) GetAccess(ctx context.Context, docID, userID uuid.UUID) (*DocumentAccess, error) {
+	doc, err := s.docRepo.FindByID(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
+
+	role := model.CollaboratorRoleEditor
+	if doc.OwnerID != userID {
+		role, err = s.getCollaboratorRole(ctx, docID, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &DocumentAccess{
+		Document: doc,
+		CanEdit:  role == model.CollaboratorRoleEditor && !doc.Locked,
+	}, nil
+}
+
 func (s *documentService) ListByContext(ctx context.Context, contextType string, contextID uuid.UUID) ([]*DocumentListItem, error) {
 	var docs []*model.Document
 	var err error
@@ -299,6 +332,7 @@ func (s *documentService) Update(ctx context.Context, docID uuid.UUID, userID uu
 	}
 
 	_ = s.historyRepo.Create(ctx, docID, userID, "updated", "Dokumen diperbarui")
+	captureEditVersion(ctx, s.versionSvc, docID, userID)
 
 	return updated, nil
 }
@@ -470,6 +504,7 @@ func (s *documentService) LockDocument(ctx context.Context, docID, userID uuid.U
 	}
 
 	_ = s.historyRepo.Create(ctx, docID, userID, action, details)
+	captureVersion(ctx, s.versionSvc, docID, userID, model.VersionReasonLocked)
 
 	// Notify collaborators about document lock (fire-and-forget)
 	if s.notifSvc != nil {
@@ -632,6 +667,7 @@ func (s *documentService) SignDocument(ctx context.Context, docID, userID uuid.U
 	}
 
 	_ = s.historyRepo.Create(ctx, docID, userID, "signed", fmt.Sprintf("Ditandatangani oleh %s", name))
+	captureVersion(ctx, s.versionSvc, docID, userID, model.VersionReasonSigned)
 
 	// Re-fetch the document to return updated state
 	updatedDoc, err := s.docRepo.FindByID(ctx, docID)
@@ -239,12 +239,14 @@ func (m *mockDocumentRepo) Delete(_ context.Context, id uuid.UUID) error {
 }
 
 type mockBlockRepo struct {
-	blocks     map[uuid.UUID]*model.Block
-	listErr    error
-	updateErr  error
-	deleteErr  error
-	reorderErr error
-	createErr  error
+	blocks      map[uuid.UUID]*model.Block
+	listErr     error
+	updateErr   error
+	deleteErr   error
+	reorderErr  error
+	createErr   error
+	saveErr     error
+	savedStates [][]model.BlockState
 }
 
 func newMockBlockRepo() *mockBlockRepo {
@@ -322,6 +324,14 @@ func (m *mockBlockRepo) Delete(_ context.Context, id uuid.UUID) error {
 	return nil
 }
 
+func (m *mockBlockRepo) SaveStates(_ context.Context, _ uuid.UUID, states []model.BlockState) error {
+	if m.saveErr != nil {
+		return m.saveErr
+	}
+	m.savedStates = append(m.savedStates, states)
+	return nil
+}
+
 type mockDocHistoryRepo struct {
 	entries []*model.DocumentHistory
 }
@@ -391,7 +401,7 @@ func TestDocumentService_Create(t *testing.T) {
 	userRepo := &docTestUserRepo{users: make(map[uuid.UUID]*model.User)}
 	templateSvc := NewTemplateService()
 
-	svc := NewDocumentService(docRepo, blockRepo, historyRepo, userRepo, templateSvc, nil)
+	svc := NewDocumentService(docRepo, blockRepo, historyRepo, userRepo, templateSvc, nil, nil)
 	ctx := context.Background()
 	ownerID := uuid.New()
 
@@ -459,7 +469,7 @@ func TestDocumentService_GetByID(t *testing.T) {
 		ownerID: {ID: ownerID, Name: "Owner", Avatar: "O"},
 	}}
 	templateSvc := NewTemplateService()
-	svc := NewDocumentService(docRepo, blockRepo, historyRepo, userRepo, templateSvc, nil)
+	svc := NewDocumentService(docRepo, blockRepo, historyRepo, userRepo, templateSvc, nil, nil)
 	ctx := context.Background()
 
 	t.Run("owner can access", func(t *testing.T) {
@@ -501,7 +511,7 @@ func TestDocumentService_Update(t *testing.T) {
 	historyRepo := &mockDocHistoryRepo{}
 	userRepo := &docTestUserRepo{users: make(map[uuid.UUID]*model.User)}
 	templateSvc := NewTemplateService()
-	svc := NewDocumentService(docRepo, blockRepo, historyRepo, userRepo, templateSvc, nil)
+	svc := NewDocumentService(docRepo, blockRepo, historyRepo, userRepo, templateSvc, nil, nil)
 	ctx := context.Background()
 	ownerID := uuid.New()
 
@@ -541,7 +551,7 @@ func TestDocumentService_Delete(t *testing.T) {
 	historyRepo := &mockDocHistoryRepo{}
 	userRepo := &docTestUserRepo{users: make(map[uuid.UUID]*model.User)}
 	templateSvc := NewTemplateService()
-	svc := NewDocumentService(docRepo, blockRepo, historyRepo, userRepo, templateSvc, nil)
+	svc := NewDocumentService(docRepo, blockRepo, historyRepo, userRepo, templateSvc, nil, nil)
 	ctx := context.Background()
 	ownerID := uuid.New()
 
@@ -578,7 +588,7 @@ func TestDocumentService_Duplicate(t *testing.T) {
 	historyRepo := &mockDocHistoryRepo{}
 	userRepo := &docTestUserRepo{users: make(map[uuid.UUID]*model.User)}
 	templateSvc := NewTemplateService()
-	svc := NewDocumentService(docRepo, blockRepo, historyRepo, userRepo, templateSvc, nil)
+	svc := NewDocumentService(docRepo, blockRepo, historyRepo, userRepo, templateSvc, nil, nil)
 	ctx := context.Background()
 	ownerID := uuid.New()
 
@@ -597,7 +607,7 @@ func TestDocumentService_Collaborators(t *testing.T) {
 	historyRepo := &mockDocHistoryRepo{}
 	userRepo := &docTestUserRepo{users: make(map[uuid.UUID]*model.User)}
 	templateSvc := NewTemplateService()
-	svc := NewDocumentService(docRepo, blockRepo, historyRepo, userRepo, templateSvc, nil)
+	svc := NewDocumentService(docRepo, blockRepo, historyRepo, userRepo, templateSvc, nil, nil)
 	ctx := context.Background()
 	ownerID := uuid.New()
 	collabID := uuid.New()
@@ -644,7 +654,7 @@ func TestDocumentService_Tags(t *testing.T) {
 	historyRepo := &mockDocHistoryRepo{}
 	userRepo := &docTestUserRepo{users: make(map[uuid.UUID]*model.User)}
 	templateSvc := NewTemplateService()
-	svc := NewDocumentService(docRepo, blockRepo, historyRepo, userRepo, templateSvc, nil)
+	svc := NewDocumentService(docRepo, blockRepo, historyRepo, userRepo, templateSvc, nil, nil)
 	ctx := context.Background()
 	ownerID := uuid.New()
 
@@ -677,7 +687,7 @@ func TestDocumentService_ListAll(t *testing.T) {
 	historyRepo := &mockDocHistoryRepo{}
 	userRepo := &docTestUserRepo{users: make(map[uuid.UUID]*model.User)}
 	templateSvc := NewTemplateService()
-	svc := NewDocumentService(docRepo, blockRepo, historyRepo, userRepo, templateSvc, nil)
+	svc := NewDocumentService(docRepo, blockRepo, historyRepo, userRepo, templateSvc, nil, nil)
 	ctx := context.Background()
 
 	ownerID := uuid.New()
@@ -696,7 +706,7 @@ func TestDocumentService_ListByContext(t *testing.T) {
 	historyRepo := &mockDocHistoryRepo{}
 	userRepo := &docTestUserRepo{users: make(map[uuid.UUID]*model.User)}
 	templateSvc := NewTemplateService()
-	svc := NewDocumentService(docRepo, blockRepo, historyRepo, userRepo, templateSvc, nil)
+	svc := NewDocumentService(docRepo, blockRepo, historyRepo, userRepo, templateSvc, nil, nil)
 	ctx := context.Background()
 	ownerID := uuid.New()
 
@@ -721,7 +731,7 @@ func TestDocumentService_LockUnlock(t *testing.T) {
 	historyRepo := &mockDocHistoryRepo{}
 	userRepo := &docTestUserRepo{users: make(map[uuid.UUID]*model.User)}
 	templateSvc := NewTemplateService()
-	svc := NewDocumentService(docRepo, blockRepo, historyRepo, userRepo, templateSvc, nil)
+	svc := NewDocumentService(docRepo, blockRepo, historyRepo, userRepo, templateSvc, nil, nil)
 	ctx := context.Background()
 	ownerID := uuid.New()
 
@@ -795,7 +805,7 @@ func TestDocumentService_Signers(t *testing.T) {
 		signerID: {ID: signerID, Name: "Signer"},
 	}}
 	templateSvc := NewTemplateService()
-	svc := NewDocumentService(docRepo, blockRepo, historyRepo, userRepo, templateSvc, nil)
+	svc := NewDocumentService(docRepo, blockRepo, historyRepo, userRepo, templateSvc, nil, nil)
 	ctx := context.Background()
 
 	t.Run("owner can add signer", func(t *testing.T) {
@@ -885,7 +895,7 @@ func TestDocumentService_GetHistory(t *testing.T) {
 	userRepo := &docTestUserRepo{users: make(map[uuid.UUID]*model.User)}
 	templateSvc := NewTemplateService()
 
-	svc := NewDocumentService(docRepo, blockRepo, historyRepo, userRepo, templateSvc, nil)
+	svc := NewDocumentService(docRepo, blockRepo, historyRepo, userRepo, templateSvc, nil, nil)
 	ctx := context.Background()
 	ownerID := uuid.New()
 
@@ -913,7 +923,7 @@ func TestDocumentService_RemoveTag(t *testing.T) {
 	userRepo := &docTestUserRepo{users: make(map[uuid.UUID]*model.User)}
 	templateSvc := NewTemplateService()
 
-	svc := NewDocumentService(docRepo, blockRepo, historyRepo, userRepo, templateSvc, nil)
+	svc := NewDocumentService(docRepo, blockRepo, historyRepo, userRepo, templateSvc, nil, nil)
 	ctx := context.Background()
 	ownerID := uuid.New()
 
@@ -938,7 +948,7 @@ func (m *mockNotifSvc) SendToUser(_ context.Context, _ uuid.UUID, _ model.Notifi
 func (m *mockNotifSvc) SendToUsers(_ context.Context, _ []uuid.UUID, _ model.Notification) error {
 	return nil
 }
-func (m *mockNotifSvc) SendToChat(_ context.Context, _ uuid.UUID, _ uuid.UUID, _ model.Notification) error {
+func (m *mockNotifSvc) SendToChat(_ context.Context, _ uuid.UUID, _ []uuid.UUID, _ model.Notification) error {
 	return nil
 }
 func (m *mockNotifSvc) RegisterDevice(_ context.Context, _ uuid.UUID, _, _ string) error { return nil }
@@ -955,14 +965,14 @@ func TestDocumentService_LockDocument_Errors(t *testing.T) {
 	}}
 
 	t.Run("not found", func(t *testing.T) {
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 		err := svc.LockDocument(ctx, uuid.New(), ownerID, model.LockedByManual)
 		require.Error(t, err)
 	})
 
 	t.Run("lock with signatures mode", func(t *testing.T) {
 		docRepo := newMockDocumentRepo()
-		svc := NewDocumentService(docRepo, newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), &mockNotifSvc{})
+		svc := NewDocumentService(docRepo, newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), &mockNotifSvc{}, nil)
 
 		doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "SigLock", OwnerID: ownerID})
 		_ = svc.AddSigner(ctx, doc.Document.ID, ownerID, collabID)
@@ -975,7 +985,7 @@ func TestDocumentService_LockDocument_Errors(t *testing.T) {
 
 	t.Run("lock with notif and collaborators", func(t *testing.T) {
 		docRepo := newMockDocumentRepo()
-		svc := NewDocumentService(docRepo, newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), &mockNotifSvc{})
+		svc := NewDocumentService(docRepo, newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), &mockNotifSvc{}, nil)
 
 		doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "NotifLock", OwnerID: ownerID})
 		_ = svc.AddCollaborator(ctx, doc.Document.ID, ownerID, collabID, model.CollaboratorRoleEditor)
@@ -994,13 +1004,13 @@ func TestDocumentService_UnlockDocument_Errors(t *testing.T) {
 	}}
 
 	t.Run("not found", func(t *testing.T) {
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 		err := svc.UnlockDocument(ctx, uuid.New(), ownerID)
 		require.Error(t, err)
 	})
 
 	t.Run("non-owner cannot unlock", func(t *testing.T) {
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 		doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "Locked", OwnerID: ownerID})
 		_ = svc.LockDocument(ctx, doc.Document.ID, ownerID, model.LockedByManual)
 		err := svc.UnlockDocument(ctx, doc.Document.ID, uuid.New())
@@ -1008,7 +1018,7 @@ func TestDocumentService_UnlockDocument_Errors(t *testing.T) {
 	})
 
 	t.Run("cannot unlock signed doc", func(t *testing.T) {
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 		doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "Signed", OwnerID: ownerID})
 		_ = svc.AddSigner(ctx, doc.Document.ID, ownerID, signerID)
 		_ = svc.LockDocument(ctx, doc.Document.ID, ownerID, model.LockedBySignatures)
@@ -1020,7 +1030,7 @@ func TestDocumentService_UnlockDocument_Errors(t *testing.T) {
 	})
 
 	t.Run("can unlock sig-locked unsigned doc", func(t *testing.T) {
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 		doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "SigNoSign", OwnerID: ownerID})
 		_ = svc.AddSigner(ctx, doc.Document.ID, ownerID, signerID)
 		_ = svc.LockDocument(ctx, doc.Document.ID, ownerID, model.LockedBySignatures)
@@ -1040,13 +1050,13 @@ func TestDocumentService_AddSigner_Errors(t *testing.T) {
 	}}
 
 	t.Run("not found", func(t *testing.T) {
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 		err := svc.AddSigner(ctx, uuid.New(), ownerID, signerID)
 		require.Error(t, err)
 	})
 
 	t.Run("add signer with notif", func(t *testing.T) {
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), &mockNotifSvc{})
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), &mockNotifSvc{}, nil)
 		doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "NotifSign", OwnerID: ownerID})
 		err := svc.AddSigner(ctx, doc.Document.ID, ownerID, signerID)
 		require.NoError(t, err)
@@ -1063,13 +1073,13 @@ func TestDocumentService_RemoveSigner_Errors(t *testing.T) {
 	}}
 
 	t.Run("not found doc", func(t *testing.T) {
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 		err := svc.RemoveSigner(ctx, uuid.New(), ownerID, signerID)
 		require.Error(t, err)
 	})
 
 	t.Run("non-owner cannot remove signer", func(t *testing.T) {
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 		doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "RS", OwnerID: ownerID})
 		_ = svc.AddSigner(ctx, doc.Document.ID, ownerID, signerID)
 		err := svc.RemoveSigner(ctx, doc.Document.ID, uuid.New(), signerID)
@@ -1077,7 +1087,7 @@ func TestDocumentService_RemoveSigner_Errors(t *testing.T) {
 	})
 
 	t.Run("cannot remove from locked doc", func(t *testing.T) {
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 		doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "LockedRS", OwnerID: ownerID})
 		_ = svc.AddSigner(ctx, doc.Document.ID, ownerID, signerID)
 		_ = svc.LockDocument(ctx, doc.Document.ID, ownerID, model.LockedBySignatures)
@@ -1093,7 +1103,7 @@ func TestDocumentService_RemoveCollaborator_Errors(t *testing.T) {
 	userRepo := &docTestUserRepo{users: make(map[uuid.UUID]*model.User)}
 
 	t.Run("non-owner cannot remove", func(t *testing.T) {
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 		doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "RC", OwnerID: ownerID})
 		_ = svc.AddCollaborator(ctx, doc.Document.ID, ownerID, collabID, model.CollaboratorRoleEditor)
 		err := svc.RemoveCollaborator(ctx, doc.Document.ID, uuid.New(), collabID)
@@ -1101,7 +1111,7 @@ func TestDocumentService_RemoveCollaborator_Errors(t *testing.T) {
 	})
 
 	t.Run("not found doc", func(t *testing.T) {
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 		err := svc.RemoveCollaborator(ctx, uuid.New(), ownerID, collabID)
 		require.Error(t, err)
 	})
@@ -1114,7 +1124,7 @@ func TestDocumentService_UpdateCollaboratorRole_Errors(t *testing.T) {
 	userRepo := &docTestUserRepo{users: make(map[uuid.UUID]*model.User)}
 
 	t.Run("non-owner cannot update role", func(t *testing.T) {
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 		doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "UCR", OwnerID: ownerID})
 		_ = svc.AddCollaborator(ctx, doc.Document.ID, ownerID, collabID, model.CollaboratorRoleEditor)
 		err := svc.UpdateCollaboratorRole(ctx, doc.Document.ID, uuid.New(), collabID, model.CollaboratorRoleViewer)
@@ -1122,7 +1132,7 @@ func TestDocumentService_UpdateCollaboratorRole_Errors(t *testing.T) {
 	})
 
 	t.Run("not found doc", func(t *testing.T) {
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 		err := svc.UpdateCollaboratorRole(ctx, uuid.New(), ownerID, collabID, model.CollaboratorRoleViewer)
 		require.Error(t, err)
 	})
@@ -1138,13 +1148,13 @@ func TestDocumentService_SignDocument_Errors(t *testing.T) {
 	}}
 
 	t.Run("not found doc", func(t *testing.T) {
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 		_, err := svc.SignDocument(ctx, uuid.New(), signerID, "Test")
 		require.Error(t, err)
 	})
 
 	t.Run("sign with empty name uses user name", func(t *testing.T) {
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 		doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "EmptyName", OwnerID: ownerID})
 		_ = svc.AddSigner(ctx, doc.Document.ID, ownerID, signerID)
 		_ = svc.LockDocument(ctx, doc.Document.ID, ownerID, model.LockedBySignatures)
@@ -1163,7 +1173,7 @@ func TestDocumentService_Duplicate_WithBlocks(t *testing.T) {
 	t.Run("duplicate with blocks", func(t *testing.T) {
 		docRepo := newMockDocumentRepo()
 		blockRepo := newMockBlockRepo()
-		svc := NewDocumentService(docRepo, blockRepo, &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(docRepo, blockRepo, &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 
 		doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "Orig", OwnerID: ownerID, TemplateID: "notulen-rapat"})
 		dup, err := svc.Duplicate(ctx, doc.Document.ID, ownerID)
@@ -1172,7 +1182,7 @@ func TestDocumentService_Duplicate_WithBlocks(t *testing.T) {
 	})
 
 	t.Run("not found", func(t *testing.T) {
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 		_, err := svc.Duplicate(ctx, uuid.New(), ownerID)
 		require.Error(t, err)
 	})
@@ -1183,7 +1193,7 @@ func TestDocumentService_ListByContext_Topic(t *testing.T) {
 	ownerID := uuid.New()
 	userRepo := &docTestUserRepo{users: make(map[uuid.UUID]*model.User)}
 
-	svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+	svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 	topicID := uuid.New()
 	_, _ = svc.Create(ctx, CreateDocumentInput{Title: "TopicDoc", OwnerID: ownerID, TopicID: &topicID})
 
@@ -1198,7 +1208,7 @@ func TestDocumentService_toListItems_ContextTypes(t *testing.T) {
 	ownerID := uuid.New()
 	userRepo := &docTestUserRepo{users: make(map[uuid.UUID]*model.User)}
 
-	svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+	svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 
 	// Standalone doc
 	standalone, _ := svc.Create(ctx, CreateDocumentInput{Title: "Standalone", OwnerID: ownerID, IsStandalone: true})
@@ -1227,7 +1237,7 @@ func TestDocumentService_Update_EditorCollaborator(t *testing.T) {
 	userRepo := &docTestUserRepo{users: make(map[uuid.UUID]*model.User)}
 	docRepo := newMockDocumentRepo()
 
-	svc := NewDocumentService(docRepo, newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+	svc := NewDocumentService(docRepo, newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 	doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "EditorTest", OwnerID: ownerID})
 	_ = svc.AddCollaborator(ctx, doc.Document.ID, ownerID, editorID, model.CollaboratorRoleEditor)
 
@@ -1243,7 +1253,7 @@ func TestDocumentService_Create_Errors(t *testing.T) {
 	ownerID := uuid.New()
 
 	t.Run("default title and icon", func(t *testing.T) {
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 		doc, err := svc.Create(ctx, CreateDocumentInput{OwnerID: ownerID})
 		require.NoError(t, err)
 		assert.Equal(t, "Dokumen Tanpa Judul", doc.Document.Title)
@@ -1252,7 +1262,7 @@ func TestDocumentService_Create_Errors(t *testing.T) {
 	})
 
 	t.Run("with template having rows and columns", func(t *testing.T) {
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 		doc, err := svc.Create(ctx, CreateDocumentInput{
 			OwnerID:    ownerID,
 			TemplateID: "inventaris-aset",
@@ -1262,7 +1272,7 @@ func TestDocumentService_Create_Errors(t *testing.T) {
 	})
 
 	t.Run("with template having emoji and color", func(t *testing.T) {
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 		// Use notulen-rapat or absensi which may have callout blocks
 		doc, err := svc.Create(ctx, CreateDocumentInput{
 			OwnerID:    ownerID,
@@ -1278,7 +1288,7 @@ func TestDocumentService_ListAll_Error(t *testing.T) {
 	userRepo := &docTestUserRepo{users: make(map[uuid.UUID]*model.User)}
 	docRepo := newMockDocumentRepo()
 
-	svc := NewDocumentService(docRepo, newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+	svc := NewDocumentService(docRepo, newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 	ownerID := uuid.New()
 
 	// Add doc owned by user
@@ -1296,7 +1306,7 @@ func TestDocumentService_Update_LockedDoc(t *testing.T) {
 	userRepo := &docTestUserRepo{users: make(map[uuid.UUID]*model.User)}
 	docRepo := newMockDocumentRepo()
 
-	svc := NewDocumentService(docRepo, newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+	svc := NewDocumentService(docRepo, newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 	doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "LockedDoc", OwnerID: ownerID})
 
 	// Lock manually
@@ -1314,7 +1324,7 @@ func TestDocumentService_Update_NonOwnerNonEditor(t *testing.T) {
 	userRepo := &docTestUserRepo{users: make(map[uuid.UUID]*model.User)}
 	docRepo := newMockDocumentRepo()
 
-	svc := NewDocumentService(docRepo, newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+	svc := NewDocumentService(docRepo, newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 	doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "ViewerDoc", OwnerID: ownerID})
 	_ = svc.AddCollaborator(ctx, doc.Document.ID, ownerID, viewerID, model.CollaboratorRoleViewer)
 
@@ -1329,7 +1339,7 @@ func TestDocumentService_Delete_LockedDoc(t *testing.T) {
 	userRepo := &docTestUserRepo{users: make(map[uuid.UUID]*model.User)}
 	docRepo := newMockDocumentRepo()
 
-	svc := NewDocumentService(docRepo, newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+	svc := NewDocumentService(docRepo, newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 	doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "LockedDoc", OwnerID: ownerID})
 	docRepo.docs[doc.Document.ID].Locked = true
 
@@ -1344,7 +1354,7 @@ func TestDocumentService_Delete_NonOwner(t *testing.T) {
 	userRepo := &docTestUserRepo{users: make(map[uuid.UUID]*model.User)}
 	docRepo := newMockDocumentRepo()
 
-	svc := NewDocumentService(docRepo, newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+	svc := NewDocumentService(docRepo, newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 	doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "NotMine", OwnerID: ownerID})
 
 	err := svc.Delete(ctx, doc.Document.ID, otherID)
@@ -1363,7 +1373,7 @@ func TestDocumentService_SignDocument_MoreErrors(t *testing.T) {
 	}}
 
 	t.Run("not in signature mode", func(t *testing.T) {
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 		doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "NoSigMode", OwnerID: ownerID})
 		// Lock manually (not in signature mode)
 		_, err := svc.SignDocument(ctx, doc.Document.ID, signerID, "Test")
@@ -1371,7 +1381,7 @@ func TestDocumentService_SignDocument_MoreErrors(t *testing.T) {
 	})
 
 	t.Run("not a signer", func(t *testing.T) {
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 		doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "NotSigner", OwnerID: ownerID})
 		_ = svc.AddSigner(ctx, doc.Document.ID, ownerID, signerID)
 		_ = svc.LockDocument(ctx, doc.Document.ID, ownerID, model.LockedBySignatures)
@@ -1381,7 +1391,7 @@ func TestDocumentService_SignDocument_MoreErrors(t *testing.T) {
 	})
 
 	t.Run("already signed", func(t *testing.T) {
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 		doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "AlreadySigned", OwnerID: ownerID})
 		_ = svc.AddSigner(ctx, doc.Document.ID, ownerID, signerID)
 		_ = svc.LockDocument(ctx, doc.Document.ID, ownerID, model.LockedBySignatures)
@@ -1398,7 +1408,7 @@ func TestDocumentService_SignDocument_MoreErrors(t *testing.T) {
 func TestDocumentService_ListByContext_Errors(t *testing.T) {
 	ctx := context.Background()
 	userRepo := &docTestUserRepo{users: make(map[uuid.UUID]*model.User)}
-	svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+	svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 
 	t.Run("invalid context type", func(t *testing.T) {
 		_, err := svc.ListByContext(ctx, "invalid", uuid.New())
@@ -1422,7 +1432,7 @@ func TestDocumentService_AddCollaborator_OwnerAsCollaborator(t *testing.T) {
 	ownerID := uuid.New()
 	userRepo := &docTestUserRepo{users: make(map[uuid.UUID]*model.User)}
 
-	svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+	svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 	doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "SelfCollab", OwnerID: ownerID})
 
 	err := svc.AddCollaborator(ctx, doc.Document.ID, ownerID, ownerID, model.CollaboratorRoleEditor)
@@ -1435,7 +1445,7 @@ func TestDocumentService_UnlockDocument_MoreErrors(t *testing.T) {
 	userRepo := &docTestUserRepo{users: make(map[uuid.UUID]*model.User)}
 
 	t.Run("not locked", func(t *testing.T) {
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 		doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "NotLocked", OwnerID: ownerID})
 
 		err := svc.UnlockDocument(ctx, doc.Document.ID, ownerID)
@@ -1443,7 +1453,7 @@ func TestDocumentService_UnlockDocument_MoreErrors(t *testing.T) {
 	})
 
 	t.Run("non-owner cannot unlock", func(t *testing.T) {
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 		doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "NotOwner", OwnerID: ownerID})
 		_ = svc.LockDocument(ctx, doc.Document.ID, ownerID, model.LockedByManual)
 
@@ -1457,7 +1467,7 @@ func TestDocumentService_UnlockDocument_MoreErrors(t *testing.T) {
 			ownerID:  {ID: ownerID, Name: "Owner"},
 			signerID: {ID: signerID, Name: "Signer"},
 		}}
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, ur, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, ur, NewTemplateService(), nil, nil)
 		doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "SignedDoc", OwnerID: ownerID})
 		_ = svc.AddSigner(ctx, doc.Document.ID, ownerID, signerID)
 		_ = svc.LockDocument(ctx, doc.Document.ID, ownerID, model.LockedBySignatures)
@@ -1474,7 +1484,7 @@ func TestDocumentService_LockDocument_MoreErrors(t *testing.T) {
 	userRepo := &docTestUserRepo{users: make(map[uuid.UUID]*model.User)}
 
 	t.Run("non-owner cannot lock", func(t *testing.T) {
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 		doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "NotOwner", OwnerID: ownerID})
 
 		err := svc.LockDocument(ctx, doc.Document.ID, uuid.New(), model.LockedByManual)
@@ -1482,7 +1492,7 @@ func TestDocumentService_LockDocument_MoreErrors(t *testing.T) {
 	})
 
 	t.Run("already locked", func(t *testing.T) {
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 		doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "AlreadyLocked", OwnerID: ownerID})
 		_ = svc.LockDocument(ctx, doc.Document.ID, ownerID, model.LockedByManual)
 
@@ -1491,7 +1501,7 @@ func TestDocumentService_LockDocument_MoreErrors(t *testing.T) {
 	})
 
 	t.Run("lock signatures without signers", func(t *testing.T) {
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 		doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "NoSigners", OwnerID: ownerID})
 
 		err := svc.LockDocument(ctx, doc.Document.ID, ownerID, model.LockedBySignatures)
@@ -1505,7 +1515,7 @@ func TestDocumentService_LockDocument_MoreErrors(t *testing.T) {
 			collabID: {ID: collabID, Name: "Collab"},
 		}}
 		notif := &mockNotifSvc{}
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, ur, NewTemplateService(), notif)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, ur, NewTemplateService(), notif, nil)
 		doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "WithNotif", OwnerID: ownerID})
 		_ = svc.AddCollaborator(ctx, doc.Document.ID, ownerID, collabID, model.CollaboratorRoleEditor)
 
@@ -1521,7 +1531,7 @@ func TestDocumentService_AddSigner_MoreErrors(t *testing.T) {
 
 	t.Run("non-owner cannot add signer", func(t *testing.T) {
 		userRepo := &docTestUserRepo{users: make(map[uuid.UUID]*model.User)}
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 		doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "NonOwner", OwnerID: ownerID})
 
 		err := svc.AddSigner(ctx, doc.Document.ID, uuid.New(), signerID)
@@ -1533,7 +1543,7 @@ func TestDocumentService_AddSigner_MoreErrors(t *testing.T) {
 			ownerID:  {ID: ownerID, Name: "Owner"},
 			signerID: {ID: signerID, Name: "Signer"},
 		}}
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, ur, NewTemplateService(), nil)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, ur, NewTemplateService(), nil, nil)
 		doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "Locked", OwnerID: ownerID})
 		_ = svc.AddSigner(ctx, doc.Document.ID, ownerID, signerID)
 		_ = svc.LockDocument(ctx, doc.Document.ID, ownerID, model.LockedBySignatures)
@@ -1549,7 +1559,7 @@ func TestDocumentService_AddSigner_MoreErrors(t *testing.T) {
 			signerID: {ID: signerID, Name: "Signer"},
 		}}
 		notif := &mockNotifSvc{}
-		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, ur, NewTemplateService(), notif)
+		svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, ur, NewTemplateService(), notif, nil)
 		doc, _ := svc.Create(ctx, CreateDocumentInput{Title: "WithNotif", OwnerID: ownerID})
 
 		err := svc.AddSigner(ctx, doc.Document.ID, ownerID, signerID)
@@ -1560,10 +1570,51 @@ func TestDocumentService_AddSigner_MoreErrors(t *testing.T) {
 func TestDocumentService_Tag_Errors(t *testing.T) {
 	ctx := context.Background()
 	userRepo := &docTestUserRepo{users: make(map[uuid.UUID]*model.User)}
-	svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil)
+	svc := NewDocumentService(newMockDocumentRepo(), newMockBlockRepo(), &mockDocHistoryRepo{}, userRepo, NewTemplateService(), nil, nil)
 
 	t.Run("empty tag", func(t *testing.T) {
 		err := svc.AddTag(ctx, uuid.New(), "")
 		require.Error(t, err)
 	})
 }
+
+func TestDocumentService_GetAccess(t *testing.T) {
+	ctx := context.Background()
+	docRepo := newMockDocumentRepo()
+	svc := NewDocumentService(docRepo, newMockBlockRepo(), &mockDocHistoryRepo{}, newMockUserRepo(), nil, nil, nil)
+	ownerID := uuid.New()
+	editorID := uuid.New()
+	viewerID := uuid.New()
+	doc := createTestDoc(docRepo, ownerID)
+	require.NoError(t, docRepo.AddCollaborator(ctx, doc.ID, editorID, model.CollaboratorRoleEditor))
+	require.NoError(t, docRepo.AddCollaborator(ctx, doc.ID, viewerID, model.CollaboratorRoleViewer))
+
+	for _, tc := range []struct {
+		name    string
+		userID  uuid.UUID
+		canEdit bool
+	}{
+		{"owner", ownerID, true},
+		{"editor", editorID, true},
+		{"viewer", viewerID, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			access, err := svc.GetAccess(ctx, doc.ID, tc.userID)
+			require.NoError(t, err)
+			assert.Equal(t, doc.ID, access.Document.ID)
+			assert.Equal(t, tc.canEdit, access.CanEdit)
+		})
+	}
+
+	t.Run("outsider", func(t *testing.T) {
+		_, err := svc.GetAccess(ctx, doc.ID, uuid.New())
+		assert.Error(t, err)
+	})
+
+	t.Run("locked document is read-only", func(t *testing.T) {
+		require.NoError(t, docRepo.Lock(ctx, doc.ID, model.LockedBySignatures))
+		access, err := svc.GetAccess(ctx, doc.ID, ownerID)
+		require.NoError(t, err)
+		assert.False(t, access.CanEdit)
+	})
+}
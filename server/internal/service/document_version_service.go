@@ -0,0 +1,472 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/internal/repository"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+const (
+	// editVersionInterval spaces out the versions captured while a document
+	// is being edited.
+	editVersionInterval   = 10 * time.Minute
+	maxVersionLabelLength = 100
+)
+
+// DocumentVersionService manages document version snapshots.
+type DocumentVersionService interface {
+	// Capture snapshots the document as a new version. Lock and signature
+	// milestones are kept even when the content did not change.
+	Capture(ctx context.Context, docID, userID uuid.UUID, reason model.VersionReason) (*model.DocumentVersion, error)
+	// CaptureEdit snapshots an edited document, at most once per editVersionInterval.
+	CaptureEdit(ctx context.Context, docID, userID uuid.UUID) error
+	// CreateVersion snapshots the document on the user's request.
+	CreateVersion(ctx context.Context, docID, userID uuid.UUID, label string) (*model.DocumentVersion, error)
+	ListVersions(ctx context.Context, docID, userID uuid.UUID) ([]*model.DocumentVersion, error)
+	GetVersion(ctx context.Context, docID, userID uuid.UUID, version int) (*model.DocumentVersion, error)
+	DiffVersions(ctx context.Context, docID, userID uuid.UUID, from, to int) (*model.DocumentVersionDiff, error)
+	// RestoreVersion brings back an earlier version's content and records
+	// it as the new latest version.
+	RestoreVersion(ctx context.Context, docID, userID uuid.UUID, version int) (*model.DocumentVersion, error)
+}
+
+type documentVersionService struct {
+	versionRepo repository.DocumentVersionRepository
+	docRepo     repository.DocumentRepository
+	blockRepo   repository.BlockRepository
+	historyRepo repository.DocumentHistoryRepository
+	live        LiveDocuments
+}
+
+// NewDocumentVersionService creates a new DocumentVersionService.
+func NewDocumentVersionService(
+	versionRepo repository.DocumentVersionRepository,
+	docRepo repository.DocumentRepository,
+	blockRepo repository.BlockRepository,
+	historyRepo repository.DocumentHistoryRepository,
+	live LiveDocuments,
+) DocumentVersionService {
+	return &documentVersionService{
+		versionRepo: versionRepo,
+		docRepo:     docRepo,
+		blockRepo:   blockRepo,
+		historyRepo: historyRepo,
+		live:        live,
+	}
+}
+
+func (s *documentVersionService) Capture(ctx context.Context, docID, userID uuid.UUID, reason model.VersionReason) (*model.DocumentVersion, error) {
+	return s.snapshot(ctx, docID, userID, reason, "", nil, false)
+}
+
+func (s *documentVersionService) CaptureEdit(ctx context.Context, docID, userID uuid.UUID) error {
+	latest, err := s.versionRepo.Latest(ctx, docID)
+	if err != nil && !apperror.IsNotFound(err) {
+		return fmt.Errorf("find latest version: %w", err)
+	}
+	if latest != nil && time.Since(latest.CreatedAt) < editVersionInterval {
+		return nil
+	}
+
+	_, err = s.snapshot(ctx, docID, userID, model.VersionReasonEdited, "", nil, true)
+	return err
+}
+
+func (s *documentVersionService) CreateVersion(ctx context.Context, docID, userID uuid.UUID, label string) (*model.DocumentVersion, error) {
+	if utf8.RuneCountInString(label) > maxVersionLabelLength {
+		return nil, apperror.Validation("label", fmt.Sprintf("label maksimal %d karakter", maxVersionLabelLength))
+	}
+	if _, err := s.requireEditor(ctx, docID, userID); err != nil {
+		return nil, err
+	}
+
+	// A labelled snapshot is kept even when nothing changed
+	return s.snapshot(ctx, docID, userID, model.VersionReasonManual, label, nil, false)
+}
+
+func (s *documentVersionService) ListVersions(ctx context.Context, docID, userID uuid.UUID) ([]*model.DocumentVersion, error) {
+	if err := s.requireAccess(ctx, docID, userID); err != nil {
+		return nil, err
+	}
+
+	versions, err := s.versionRepo.ListByDocument(ctx, docID)
+	if err != nil {
+		return nil, fmt.Errorf("list versions: %w", err)
+	}
+	return versions, nil
+}
+
+func (s *documentVersionService) GetVersion(ctx context.Context, docID, userID uuid.UUID, version int) (*model.DocumentVersion, error) {
+	if err := s.requireAccess(ctx, docID, userID); err != nil {
+		return nil, err
+	}
+	return s.versionRepo.FindByNumber(ctx, docID, version)
+}
+
+func (s *documentVersionService) DiffVersions(ctx context.Context, docID, userID uuid.UUID, from, to int) (*model.DocumentVersionDiff, error) {
+	if err := s.requireAccess(ctx, docID, userID); err != nil {
+		return nil, err
+	}
+
+	fromVersion, err := s.versionRepo.FindByNumber(ctx, docID, from)
+	if err != nil {
+		return nil, err
+	}
+	toVersion, err := s.versionRepo.FindByNumber(ctx, docID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffVersions(fromVersion, toVersion), nil
+}
+
+func (s *documentVersionService) RestoreVersion(ctx context.Context, docID, userID uuid.UUID, version int) (*model.DocumentVersion, error) {
+	doc, err := s.requireEditor(ctx, docID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if doc.Locked {
+		return nil, apperror.Forbidden("dokumen terkunci, tidak dapat memulihkan versi")
+	}
+
+	resume, err := suspendLive(ctx, s.live, docID)
+	if err != nil {
+		return nil, err
+	}
+	defer resume()
+
+	if _, err := s.versionRepo.FindByNumber(ctx, docID, version); err != nil {
+		return nil, err
+	}
+
+	// Keep the current content restorable before it is replaced
+	if _, err := s.snapshot(ctx, docID, userID, model.VersionReasonEdited, "", nil, true); err != nil {
+		return nil, err
+	}
+
+	if err := s.versionRepo.Restore(ctx, docID, version); err != nil {
+		return nil, fmt.Errorf("restore version: %w", err)
+	}
+
+	head, err := s.snapshot(ctx, docID, userID, model.VersionReasonRestored, "", &version, false)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.historyRepo.Create(ctx, docID, userID, "version_restored", fmt.Sprintf("Dikembalikan ke versi %d", version))
+
+	return head, nil
+}
+
+// --- Helper Methods ---
+
+// snapshot stores the document's current content as a new version. With
+// dedupe set, the latest version is returned instead when it has the same
+// content.
+func (s *documentVersionService) snapshot(ctx context.Context, docID, userID uuid.UUID, reason model.VersionReason, label string, restoredFrom *int, dedupe bool) (*model.DocumentVersion, error) {
+	doc, err := s.docRepo.FindByID(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := s.blockRepo.ListByDocument(ctx, docID)
+	if err != nil {
+		return nil, fmt.Errorf("list blocks: %w", err)
+	}
+
+	hash, err := versionContentHash(doc.Title, doc.Icon, doc.Cover, blocks)
+	if err != nil {
+		return nil, err
+	}
+
+	if dedupe {
+		latest, err := s.versionRepo.Latest(ctx, docID)
+		if err != nil && !apperror.IsNotFound(err) {
+			return nil, fmt.Errorf("find latest version: %w", err)
+		}
+		if latest != nil && latest.ContentHash == hash {
+			return latest, nil
+		}
+	}
+
+	version, err := s.versionRepo.Create(ctx, model.CreateDocumentVersionInput{
+		DocumentID:   docID,
+		Title:        doc.Title,
+		Icon:         doc.Icon,
+		Cover:        doc.Cover,
+		Blocks:       blocks,
+		ContentHash:  hash,
+		Reason:       reason,
+		Label:        label,
+		RestoredFrom: restoredFrom,
+		CreatedBy:    userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create version: %w", err)
+	}
+	return version, nil
+}
+
+// requireAccess checks that the user owns or collaborates on the document.
+func (s *documentVersionService) requireAccess(ctx context.Context, docID, userID uuid.UUID) error {
+	doc, err := s.docRepo.FindByID(ctx, docID)
+	if err != nil {
+		return err
+	}
+	if doc.OwnerID == userID {
+		return nil
+	}
+	if _, err := s.collaboratorRole(ctx, docID, userID); err != nil {
+		return err
+	}
+	return nil
+}
+
+// requireEditor checks that the user owns the document or edits it.
+func (s *documentVersionService) requireEditor(ctx context.Context, docID, userID uuid.UUID) (*model.Document, error) {
+	doc, err := s.docRepo.FindByID(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
+	if doc.OwnerID == userID {
+		return doc, nil
+	}
+	role, err := s.collaboratorRole(ctx, docID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role != model.CollaboratorRoleEditor {
+		return nil, apperror.Forbidden("anda tidak memiliki izin untuk mengubah dokumen ini")
+	}
+	return doc, nil
+}
+
+func (s *documentVersionService) collaboratorRole(ctx context.Context, docID, userID uuid.UUID) (model.CollaboratorRole, error) {
+	collabs, err := s.docRepo.ListCollaborators(ctx, docID)
+	if err != nil {
+		return "", fmt.Errorf("list collaborators: %w", err)
+	}
+	for _, c := range collabs {
+		if c.UserID == userID {
+			return c.Role, nil
+		}
+	}
+	return "", apperror.Forbidden("anda tidak memiliki akses ke dokumen ini")
+}
+
+// captureVersion snapshots a document without failing the caller: the
+// change that prompted it has already been saved.
+func captureVersion(ctx context.Context, svc DocumentVersionService, docID, userID uuid.UUID, reason model.VersionReason) {
+	if svc == nil {
+		return
+	}
+	if _, err := svc.Capture(ctx, docID, userID, reason); err != nil {
+		log.Warn().Err(err).Str("document_id", docID.String()).Msg("failed to capture document version")
+	}
+}
+
+// captureEditVersion is captureVersion for ordinary edits.
+func captureEditVersion(ctx context.Context, svc DocumentVersionService, docID, userID uuid.UUID) {
+	if svc == nil {
+		return
+	}
+	if err := svc.CaptureEdit(ctx, docID, userID); err != nil {
+		log.Warn().Err(err).Str("document_id", docID.String()).Msg("failed to capture document version")
+	}
+}
+
+// versionBlock is the part of a block that makes up a version's content.
+// Timestamps are left out so reordering alone does not look like an edit.
+type versionBlock struct {
+	ID            uuid.UUID       `json:"id"`
+	ParentBlockID *uuid.UUID      `json:"parentBlockId"`
+	Type          model.BlockType `json:"type"`
+	Content       string          `json:"content"`
+	Checked       *bool           `json:"checked"`
+	Rows          json.RawMessage `json:"rows"`
+	Columns       json.RawMessage `json:"columns"`
+	Language      string          `json:"language"`
+	Emoji         string          `json:"emoji"`
+	Color         string          `json:"color"`
+}
+
+func toVersionBlock(b *model.Block) versionBlock {
+	return versionBlock{
+		ID:            b.ID,
+		ParentBlockID: b.ParentBlockID,
+		Type:          b.Type,
+		Content:       b.Content,
+		Checked:       b.Checked,
+		Rows:          compactJSON(b.Rows),
+		Columns:       compactJSON(b.Columns),
+		Language:      b.Language,
+		Emoji:         b.Emoji,
+		Color:         b.Color,
+	}
+}
+
+// compactJSON strips insignificant whitespace so equal values compare equal.
+func compactJSON(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return raw
+	}
+	return buf.Bytes()
+}
+
+// versionContentHash fingerprints a document's content and block order.
+func versionContentHash(title, icon string, cover *string, blocks []*model.Block) (string, error) {
+	content := struct {
+		Title  string         `json:"title"`
+		Icon   string         `json:"icon"`
+		Cover  *string        `json:"cover"`
+		Blocks []versionBlock `json:"blocks"`
+	}{Title: title, Icon: icon, Cover: cover, Blocks: make([]versionBlock, len(blocks))}
+	for i, b := range blocks {
+		content.Blocks[i] = toVersionBlock(b)
+	}
+
+	data, err := json.Marshal(content)
+	if err != nil {
+		return "", fmt.Errorf("marshal version content: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// diffVersions compares two versions block by block. Blocks are matched by
+// ID; a block counts as moved when it falls out of the longest run of
+// blocks that kept their relative order.
+func diffVersions(from, to *model.DocumentVersion) *model.DocumentVersionDiff {
+	diff := &model.DocumentVersionDiff{
+		From:   from.Version,
+		To:     to.Version,
+		Fields: []model.DocumentFieldChange{},
+		Blocks: []model.BlockChange{},
+	}
+
+	for _, f := range []struct {
+		name          string
+		before, after string
+	}{
+		{"title", from.Title, to.Title},
+		{"icon", from.Icon, to.Icon},
+		{"cover", derefString(from.Cover), derefString(to.Cover)},
+	} {
+		if f.before != f.after {
+			diff.Fields = append(diff.Fields, model.DocumentFieldChange{Field: f.name, Before: f.before, After: f.after})
+		}
+	}
+
+	before := make(map[uuid.UUID]*model.Block, len(from.Blocks))
+	for _, b := range from.Blocks {
+		before[b.ID] = b
+	}
+	after := make(map[uuid.UUID]*model.Block, len(to.Blocks))
+	for _, b := range to.Blocks {
+		after[b.ID] = b
+	}
+
+	var fromOrder, toOrder []uuid.UUID
+	for _, b := range from.Blocks {
+		if after[b.ID] != nil {
+			fromOrder = append(fromOrder, b.ID)
+		}
+	}
+	for _, b := range to.Blocks {
+		if before[b.ID] != nil {
+			toOrder = append(toOrder, b.ID)
+		}
+	}
+	inOrder := longestCommonOrder(fromOrder, toOrder)
+
+	for _, b := range to.Blocks {
+		old := before[b.ID]
+		switch {
+		case old == nil:
+			diff.Blocks = append(diff.Blocks, model.BlockChange{BlockID: b.ID, Change: model.BlockChangeAdded, After: b})
+		case !sameBlockContent(old, b):
+			diff.Blocks = append(diff.Blocks, model.BlockChange{BlockID: b.ID, Change: model.BlockChangeModified, Before: old, After: b})
+		case !inOrder[b.ID]:
+			diff.Blocks = append(diff.Blocks, model.BlockChange{BlockID: b.ID, Change: model.BlockChangeMoved, Before: old, After: b})
+		}
+	}
+	for _, b := range from.Blocks {
+		if after[b.ID] == nil {
+			diff.Blocks = append(diff.Blocks, model.BlockChange{BlockID: b.ID, Change: model.BlockChangeRemoved, Before: b})
+		}
+	}
+
+	return diff
+}
+
+func sameBlockContent(a, b *model.Block) bool {
+	x, errX := json.Marshal(toVersionBlock(a))
+	y, errY := json.Marshal(toVersionBlock(b))
+	return errX == nil && errY == nil && bytes.Equal(x, y)
+}
+
+// longestCommonOrder returns the IDs in a longest common subsequence of a and
+// b. Block IDs are unique, so this is the longest increasing run of b's
+// positions in a, found in O(n log n).
+func longestCommonOrder(a, b []uuid.UUID) map[uuid.UUID]bool {
+	pos := make(map[uuid.UUID]int, len(a))
+	for i, id := range a {
+		pos[id] = i
+	}
+	var ids []uuid.UUID
+	var seq []int
+	for _, id := range b {
+		if i, ok := pos[id]; ok {
+			ids = append(ids, id)
+			seq = append(seq, i)
+		}
+	}
+
+	// tails[k] is the index in seq ending the best run of length k+1, and
+	// prev links each element to the one before it in its run
+	var tails []int
+	prev := make([]int, len(seq))
+	for i, p := range seq {
+		k := sort.Search(len(tails), func(k int) bool { return seq[tails[k]] >= p })
+		prev[i] = -1
+		if k > 0 {
+			prev[i] = tails[k-1]
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+
+	common := make(map[uuid.UUID]bool, len(tails))
+	if len(tails) > 0 {
+		for i := tails[len(tails)-1]; i >= 0; i = prev[i] {
+			common[ids[i]] = true
+		}
+	}
+	return common
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,443 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+// -- Mock DocumentVersionRepository --
+
+type mockDocumentVersionRepo struct {
+	versions  map[uuid.UUID][]*model.DocumentVersion
+	docRepo   *mockDocumentRepo
+	blockRepo *mockBlockRepo
+}
+
+func newMockDocumentVersionRepo(docRepo *mockDocumentRepo, blockRepo *mockBlockRepo) *mockDocumentVersionRepo {
+	return &mockDocumentVersionRepo{
+		versions:  make(map[uuid.UUID][]*model.DocumentVersion),
+		docRepo:   docRepo,
+		blockRepo: blockRepo,
+	}
+}
+
+func (m *mockDocumentVersionRepo) Create(_ context.Context, input model.CreateDocumentVersionInput) (*model.DocumentVersion, error) {
+	createdBy := input.CreatedBy
+	v := &model.DocumentVersion{
+		ID:           uuid.New(),
+		DocumentID:   input.DocumentID,
+		Version:      len(m.versions[input.DocumentID]) + 1,
+		Title:        input.Title,
+		Icon:         input.Icon,
+		Cover:        input.Cover,
+		Blocks:       input.Blocks,
+		BlockCount:   len(input.Blocks),
+		Reason:       input.Reason,
+		Label:        input.Label,
+		RestoredFrom: input.RestoredFrom,
+		CreatedBy:    &createdBy,
+		ContentHash:  input.ContentHash,
+		CreatedAt:    time.Now(),
+	}
+	m.versions[input.DocumentID] = append(m.versions[input.DocumentID], v)
+	return v, nil
+}
+
+func (m *mockDocumentVersionRepo) ListByDocument(_ context.Context, docID uuid.UUID) ([]*model.DocumentVersion, error) {
+	versions := m.versions[docID]
+	result := make([]*model.DocumentVersion, 0, len(versions))
+	for i := len(versions) - 1; i >= 0; i-- {
+		result = append(result, versions[i])
+	}
+	return result, nil
+}
+
+func (m *mockDocumentVersionRepo) Latest(_ context.Context, docID uuid.UUID) (*model.DocumentVersion, error) {
+	versions := m.versions[docID]
+	if len(versions) == 0 {
+		return nil, apperror.NotFound("document version", docID.String())
+	}
+	return versions[len(versions)-1], nil
+}
+
+func (m *mockDocumentVersionRepo) FindByNumber(_ context.Context, docID uuid.UUID, version int) (*model.DocumentVersion, error) {
+	versions := m.versions[docID]
+	if version < 1 || version > len(versions) {
+		return nil, apperror.NotFound("document version", docID.String())
+	}
+	return versions[version-1], nil
+}
+
+func (m *mockDocumentVersionRepo) Restore(ctx context.Context, docID uuid.UUID, version int) error {
+	v, err := m.FindByNumber(ctx, docID, version)
+	if err != nil {
+		return err
+	}
+	doc := m.docRepo.docs[docID]
+	doc.Title = v.Title
+	doc.Icon = v.Icon
+	doc.Cover = v.Cover
+	for id, b := range m.blockRepo.blocks {
+		if b.DocumentID == docID {
+			delete(m.blockRepo.blocks, id)
+		}
+	}
+	for _, b := range v.Blocks {
+		restored := *b
+		m.blockRepo.blocks[b.ID] = &restored
+	}
+	return nil
+}
+
+func createTestBlock(t *testing.T, blockRepo *mockBlockRepo, docID uuid.UUID, content string) *model.Block {
+	t.Helper()
+	b, err := blockRepo.Create(context.Background(), model.CreateBlockInput{
+		DocumentID: docID,
+		Type:       model.BlockTypeParagraph,
+		Content:    content,
+	})
+	require.NoError(t, err)
+	return b
+}
+
+// -- Tests --
+
+func TestDocumentVersionService_Capture(t *testing.T) {
+	ctx := context.Background()
+	docRepo := newMockDocumentRepo()
+	blockRepo := newMockBlockRepo()
+	versionRepo := newMockDocumentVersionRepo(docRepo, blockRepo)
+	svc := NewDocumentVersionService(versionRepo, docRepo, blockRepo, &mockDocHistoryRepo{}, nil)
+	ownerID := uuid.New()
+
+	t.Run("snapshots title and blocks", func(t *testing.T) {
+		doc := createTestDoc(docRepo, ownerID)
+		block := createTestBlock(t, blockRepo, doc.ID, "Agenda")
+
+		v, err := svc.Capture(ctx, doc.ID, ownerID, model.VersionReasonLocked)
+		require.NoError(t, err)
+		assert.Equal(t, 1, v.Version)
+		assert.Equal(t, "Test Doc", v.Title)
+		assert.Equal(t, model.VersionReasonLocked, v.Reason)
+		require.Len(t, v.Blocks, 1)
+		assert.Equal(t, block.ID, v.Blocks[0].ID)
+	})
+
+	t.Run("keeps milestones with unchanged content", func(t *testing.T) {
+		doc := createTestDoc(docRepo, ownerID)
+
+		_, err := svc.Capture(ctx, doc.ID, ownerID, model.VersionReasonLocked)
+		require.NoError(t, err)
+		v, err := svc.Capture(ctx, doc.ID, ownerID, model.VersionReasonSigned)
+		require.NoError(t, err)
+		assert.Equal(t, 2, v.Version)
+	})
+
+	t.Run("document not found", func(t *testing.T) {
+		_, err := svc.Capture(ctx, uuid.New(), ownerID, model.VersionReasonLocked)
+		assert.True(t, apperror.IsNotFound(err))
+	})
+}
+
+func TestDocumentVersionService_CaptureEdit(t *testing.T) {
+	ctx := context.Background()
+	docRepo := newMockDocumentRepo()
+	blockRepo := newMockBlockRepo()
+	versionRepo := newMockDocumentVersionRepo(docRepo, blockRepo)
+	svc := NewDocumentVersionService(versionRepo, docRepo, blockRepo, &mockDocHistoryRepo{}, nil)
+	ownerID := uuid.New()
+
+	t.Run("first edit is captured", func(t *testing.T) {
+		doc := createTestDoc(docRepo, ownerID)
+		createTestBlock(t, blockRepo, doc.ID, "Agenda")
+
+		require.NoError(t, svc.CaptureEdit(ctx, doc.ID, ownerID))
+		require.Len(t, versionRepo.versions[doc.ID], 1)
+		assert.Equal(t, model.VersionReasonEdited, versionRepo.versions[doc.ID][0].Reason)
+	})
+
+	t.Run("edits within the interval are skipped", func(t *testing.T) {
+		doc := createTestDoc(docRepo, ownerID)
+		require.NoError(t, svc.CaptureEdit(ctx, doc.ID, ownerID))
+
+		createTestBlock(t, blockRepo, doc.ID, "Agenda")
+		require.NoError(t, svc.CaptureEdit(ctx, doc.ID, ownerID))
+		assert.Len(t, versionRepo.versions[doc.ID], 1)
+	})
+
+	t.Run("edit after the interval is captured", func(t *testing.T) {
+		doc := createTestDoc(docRepo, ownerID)
+		require.NoError(t, svc.CaptureEdit(ctx, doc.ID, ownerID))
+		versionRepo.versions[doc.ID][0].CreatedAt = time.Now().Add(-editVersionInterval - time.Minute)
+
+		createTestBlock(t, blockRepo, doc.ID, "Agenda")
+		require.NoError(t, svc.CaptureEdit(ctx, doc.ID, ownerID))
+		assert.Len(t, versionRepo.versions[doc.ID], 2)
+	})
+
+	t.Run("unchanged content is not captured again", func(t *testing.T) {
+		doc := createTestDoc(docRepo, ownerID)
+		createTestBlock(t, blockRepo, doc.ID, "Agenda")
+		require.NoError(t, svc.CaptureEdit(ctx, doc.ID, ownerID))
+		versionRepo.versions[doc.ID][0].CreatedAt = time.Now().Add(-editVersionInterval - time.Minute)
+
+		require.NoError(t, svc.CaptureEdit(ctx, doc.ID, ownerID))
+		assert.Len(t, versionRepo.versions[doc.ID], 1)
+	})
+}
+
+func TestDocumentVersionService_CreateVersion(t *testing.T) {
+	ctx := context.Background()
+	docRepo := newMockDocumentRepo()
+	blockRepo := newMockBlockRepo()
+	versionRepo := newMockDocumentVersionRepo(docRepo, blockRepo)
+	svc := NewDocumentVersionService(versionRepo, docRepo, blockRepo, &mockDocHistoryRepo{}, nil)
+	ownerID := uuid.New()
+	doc := createTestDoc(docRepo, ownerID)
+
+	t.Run("labelled snapshot", func(t *testing.T) {
+		_, err := svc.Capture(ctx, doc.ID, ownerID, model.VersionReasonLocked)
+		require.NoError(t, err)
+
+		v, err := svc.CreateVersion(ctx, doc.ID, ownerID, "Draf final")
+		require.NoError(t, err)
+		assert.Equal(t, 2, v.Version)
+		assert.Equal(t, "Draf final", v.Label)
+		assert.Equal(t, model.VersionReasonManual, v.Reason)
+	})
+
+	t.Run("editor can create", func(t *testing.T) {
+		editorID := uuid.New()
+		require.NoError(t, docRepo.AddCollaborator(ctx, doc.ID, editorID, model.CollaboratorRoleEditor))
+
+		_, err := svc.CreateVersion(ctx, doc.ID, editorID, "")
+		assert.NoError(t, err)
+	})
+
+	t.Run("viewer cannot create", func(t *testing.T) {
+		viewerID := uuid.New()
+		require.NoError(t, docRepo.AddCollaborator(ctx, doc.ID, viewerID, model.CollaboratorRoleViewer))
+
+		_, err := svc.CreateVersion(ctx, doc.ID, viewerID, "")
+		assert.True(t, apperror.IsForbidden(err))
+	})
+
+	t.Run("label too long", func(t *testing.T) {
+		before := len(versionRepo.versions[doc.ID])
+
+		_, err := svc.CreateVersion(ctx, doc.ID, ownerID, strings.Repeat("a", maxVersionLabelLength+1))
+		assert.Error(t, err)
+		assert.Len(t, versionRepo.versions[doc.ID], before)
+	})
+}
+
+func TestDocumentVersionService_ListAndGet(t *testing.T) {
+	ctx := context.Background()
+	docRepo := newMockDocumentRepo()
+	blockRepo := newMockBlockRepo()
+	versionRepo := newMockDocumentVersionRepo(docRepo, blockRepo)
+	svc := NewDocumentVersionService(versionRepo, docRepo, blockRepo, &mockDocHistoryRepo{}, nil)
+	ownerID := uuid.New()
+	doc := createTestDoc(docRepo, ownerID)
+
+	createTestBlock(t, blockRepo, doc.ID, "Agenda")
+	_, err := svc.CreateVersion(ctx, doc.ID, ownerID, "satu")
+	require.NoError(t, err)
+	_, err = svc.CreateVersion(ctx, doc.ID, ownerID, "dua")
+	require.NoError(t, err)
+
+	t.Run("newest first", func(t *testing.T) {
+		versions, err := svc.ListVersions(ctx, doc.ID, ownerID)
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+		assert.Equal(t, 2, versions[0].Version)
+		assert.Equal(t, 1, versions[1].Version)
+	})
+
+	t.Run("viewer can read", func(t *testing.T) {
+		viewerID := uuid.New()
+		require.NoError(t, docRepo.AddCollaborator(ctx, doc.ID, viewerID, model.CollaboratorRoleViewer))
+
+		v, err := svc.GetVersion(ctx, doc.ID, viewerID, 1)
+		require.NoError(t, err)
+		assert.Len(t, v.Blocks, 1)
+	})
+
+	t.Run("outsider cannot read", func(t *testing.T) {
+		_, err := svc.ListVersions(ctx, doc.ID, uuid.New())
+		assert.True(t, apperror.IsForbidden(err))
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		_, err := svc.GetVersion(ctx, doc.ID, ownerID, 9)
+		assert.True(t, apperror.IsNotFound(err))
+	})
+}
+
+func TestDocumentVersionService_RestoreVersion(t *testing.T) {
+	ctx := context.Background()
+	docRepo := newMockDocumentRepo()
+	blockRepo := newMockBlockRepo()
+	versionRepo := newMockDocumentVersionRepo(docRepo, blockRepo)
+	historyRepo := &mockDocHistoryRepo{}
+	live := &fakeLiveDocuments{}
+	svc := NewDocumentVersionService(versionRepo, docRepo, blockRepo, historyRepo, live)
+	ownerID := uuid.New()
+
+	t.Run("restores content as a new version", func(t *testing.T) {
+		doc := createTestDoc(docRepo, ownerID)
+		original := createTestBlock(t, blockRepo, doc.ID, "Agenda")
+		_, err := svc.CreateVersion(ctx, doc.ID, ownerID, "awal")
+		require.NoError(t, err)
+
+		docRepo.docs[doc.ID].Title = "Rencana Baru"
+		delete(blockRepo.blocks, original.ID)
+		createTestBlock(t, blockRepo, doc.ID, "Catatan lain")
+
+		live.events = nil
+		head, err := svc.RestoreVersion(ctx, doc.ID, ownerID, 1)
+		require.NoError(t, err)
+
+		// Version 2 keeps the replaced content, version 3 is the restore
+		versions := versionRepo.versions[doc.ID]
+		require.Len(t, versions, 3)
+		assert.Equal(t, "Rencana Baru", versions[1].Title)
+		assert.Equal(t, 3, head.Version)
+		assert.Equal(t, model.VersionReasonRestored, head.Reason)
+		require.NotNil(t, head.RestoredFrom)
+		assert.Equal(t, 1, *head.RestoredFrom)
+
+		assert.Equal(t, "Test Doc", docRepo.docs[doc.ID].Title)
+		blocks, _ := blockRepo.ListByDocument(ctx, doc.ID)
+		require.Len(t, blocks, 1)
+		assert.Equal(t, original.ID, blocks[0].ID)
+
+		last := historyRepo.entries[len(historyRepo.entries)-1]
+		assert.Equal(t, "version_restored", last.Action)
+		assert.Equal(t, "Dikembalikan ke versi 1", last.Details)
+
+		// Realtime editing is suspended while the blocks are replaced
+		assert.Equal(t, []string{"suspend " + doc.ID.String(), "resume " + doc.ID.String()}, live.events)
+	})
+
+	t.Run("locked document", func(t *testing.T) {
+		doc := createTestDoc(docRepo, ownerID)
+		_, err := svc.CreateVersion(ctx, doc.ID, ownerID, "")
+		require.NoError(t, err)
+		docRepo.docs[doc.ID].Locked = true
+
+		_, err = svc.RestoreVersion(ctx, doc.ID, ownerID, 1)
+		assert.True(t, apperror.IsForbidden(err))
+	})
+
+	t.Run("viewer cannot restore", func(t *testing.T) {
+		doc := createTestDoc(docRepo, ownerID)
+		_, err := svc.CreateVersion(ctx, doc.ID, ownerID, "")
+		require.NoError(t, err)
+		viewerID := uuid.New()
+		require.NoError(t, docRepo.AddCollaborator(ctx, doc.ID, viewerID, model.CollaboratorRoleViewer))
+
+		_, err = svc.RestoreVersion(ctx, doc.ID, viewerID, 1)
+		assert.True(t, apperror.IsForbidden(err))
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		doc := createTestDoc(docRepo, ownerID)
+
+		_, err := svc.RestoreVersion(ctx, doc.ID, ownerID, 4)
+		assert.True(t, apperror.IsNotFound(err))
+		assert.Empty(t, versionRepo.versions[doc.ID])
+	})
+}
+
+func TestDiffVersions(t *testing.T) {
+	block := func(content string) *model.Block {
+		return &model.Block{ID: uuid.New(), Type: model.BlockTypeParagraph, Content: content}
+	}
+	a, b, c, d := block("a"), block("b"), block("c"), block("d")
+	modified := *b
+	modified.Content = "b2"
+	added := block("e")
+
+	from := &model.DocumentVersion{Version: 1, Title: "Lama", Icon: "📄", Blocks: []*model.Block{a, b, c, d}}
+	to := &model.DocumentVersion{Version: 2, Title: "Baru", Icon: "📄", Blocks: []*model.Block{c, a, &modified, added}}
+
+	diff := diffVersions(from, to)
+	assert.Equal(t, 1, diff.From)
+	assert.Equal(t, 2, diff.To)
+	assert.Equal(t, []model.DocumentFieldChange{{Field: "title", Before: "Lama", After: "Baru"}}, diff.Fields)
+
+	changes := make(map[uuid.UUID]model.BlockChangeType)
+	for _, ch := range diff.Blocks {
+		changes[ch.BlockID] = ch.Change
+	}
+	assert.Equal(t, map[uuid.UUID]model.BlockChangeType{
+		c.ID:     model.BlockChangeMoved,
+		b.ID:     model.BlockChangeModified,
+		added.ID: model.BlockChangeAdded,
+		d.ID:     model.BlockChangeRemoved,
+	}, changes)
+
+	t.Run("identical versions", func(t *testing.T) {
+		diff := diffVersions(from, from)
+		assert.Empty(t, diff.Fields)
+		assert.Empty(t, diff.Blocks)
+	})
+
+	t.Run("only the block moved to the front is moved", func(t *testing.T) {
+		blocks := make([]*model.Block, 10)
+		for i := range blocks {
+			blocks[i] = block(strconv.Itoa(i))
+		}
+		last := blocks[len(blocks)-1]
+		reordered := append([]*model.Block{last}, blocks[:len(blocks)-1]...)
+
+		diff := diffVersions(
+			&model.DocumentVersion{Version: 1, Blocks: blocks},
+			&model.DocumentVersion{Version: 2, Blocks: reordered},
+		)
+		require.Len(t, diff.Blocks, 1)
+		assert.Equal(t, last.ID, diff.Blocks[0].BlockID)
+		assert.Equal(t, model.BlockChangeMoved, diff.Blocks[0].Change)
+	})
+}
+
+func TestBlockService_CapturesVersions(t *testing.T) {
+	ctx := context.Background()
+	docRepo := newMockDocumentRepo()
+	blockRepo := newMockBlockRepo()
+	versionRepo := newMockDocumentVersionRepo(docRepo, blockRepo)
+	historyRepo := &mockDocHistoryRepo{}
+	versionSvc := NewDocumentVersionService(versionRepo, docRepo, blockRepo, historyRepo, nil)
+	svc := NewBlockService(blockRepo, docRepo, historyRepo, nil, versionSvc, nil)
+	ownerID := uuid.New()
+	doc := createTestDoc(docRepo, ownerID)
+
+	block, err := svc.AddBlock(ctx, doc.ID, ownerID, AddBlockInput{Type: model.BlockTypeParagraph, Content: "Agenda"})
+	require.NoError(t, err)
+	versions := versionRepo.versions[doc.ID]
+	require.Len(t, versions, 1)
+	assert.Len(t, versions[0].Blocks, 1)
+
+	// Deleting after the interval captures the block before it is removed
+	versions[0].CreatedAt = time.Now().Add(-editVersionInterval - time.Minute)
+	content := "Agenda rapat"
+	_, err = blockRepo.Update(ctx, block.ID, model.UpdateBlockInput{Content: &content})
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteBlock(ctx, block.ID, ownerID))
+
+	versions = versionRepo.versions[doc.ID]
+	require.Len(t, versions, 2)
+	require.Len(t, versions[1].Blocks, 1)
+	assert.Equal(t, "Agenda rapat", versions[1].Blocks[0].Content)
+}
@@ -0,0 +1,365 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/internal/repository"
+	"github.com/otoritech/chatat/internal/ws"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+const inviteCodeLength = 12
+
+// CreateInviteInput holds the options for a new group invite link.
+type CreateInviteInput struct {
+	RequiresApproval bool       `json:"requiresApproval"`
+	MaxUses          *int       `json:"maxUses"`
+	ExpiresAt        *time.Time `json:"expiresAt"`
+}
+
+// GroupPreview is what a user sees of a group before joining it with an invite.
+type GroupPreview struct {
+	ChatID           uuid.UUID `json:"chatId"`
+	Name             string    `json:"name"`
+	Icon             string    `json:"icon"`
+	Description      string    `json:"description"`
+	MemberCount      int       `json:"memberCount"`
+	RequiresApproval bool      `json:"requiresApproval"`
+}
+
+// JoinResult is the outcome of joining a group with an invite. Request is set
+// when the invite needs admin approval and the user is not a member yet.
+type JoinResult struct {
+	Chat    *model.Chat        `json:"chat"`
+	Joined  bool               `json:"joined"`
+	Request *model.JoinRequest `json:"request,omitempty"`
+}
+
+// GroupInviteService defines operations for group invite links and join requests.
+type GroupInviteService interface {
+	CreateInvite(ctx context.Context, chatID, userID uuid.UUID, input CreateInviteInput) (*model.GroupInvite, error)
+	ListInvites(ctx context.Context, chatID, userID uuid.UUID) ([]*model.GroupInvite, error)
+	RevokeInvite(ctx context.Context, chatID, inviteID, userID uuid.UUID) error
+	PreviewInvite(ctx context.Context, code string) (*GroupPreview, error)
+	JoinWithInvite(ctx context.Context, code string, userID uuid.UUID) (*JoinResult, error)
+	ListJoinRequests(ctx context.Context, chatID, userID uuid.UUID) ([]*model.JoinRequest, error)
+	ApproveJoinRequest(ctx context.Context, chatID, requestID, userID uuid.UUID) error
+	RejectJoinRequest(ctx context.Context, chatID, requestID, userID uuid.UUID) error
+}
+
+type groupInviteService struct {
+	inviteRepo repository.GroupInviteRepository
+	// group provides the admin checks, system messages and WS events shared
+	// with the rest of group management.
+	group *groupService
+}
+
+// NewGroupInviteService creates a new GroupInviteService. It takes the same
+// dependencies as NewGroupService.
+func NewGroupInviteService(
+	inviteRepo repository.GroupInviteRepository,
+	chatRepo repository.ChatRepository,
+	messageRepo repository.MessageRepository,
+	messageStatRepo repository.MessageStatusRepository,
+	userRepo repository.UserRepository,
+	blockRepo repository.UserBlockRepository,
+	privacy PrivacyPolicy,
+	hub *ws.Hub,
+	notifSvc NotificationService,
+) GroupInviteService {
+	return &groupInviteService{
+		inviteRepo: inviteRepo,
+		group:      newGroupService(chatRepo, messageRepo, messageStatRepo, userRepo, blockRepo, privacy, hub, notifSvc),
+	}
+}
+
+func (s *groupInviteService) CreateInvite(ctx context.Context, chatID, userID uuid.UUID, input CreateInviteInput) (*model.GroupInvite, error) {
+	if _, err := s.requireGroupAdmin(ctx, chatID, userID); err != nil {
+		return nil, err
+	}
+	if input.MaxUses != nil && *input.MaxUses <= 0 {
+		return nil, apperror.Validation("maxUses", "maxUses must be positive")
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, apperror.Validation("expiresAt", "expiresAt must be in the future")
+	}
+
+	code, err := generateAlphanumericCode(inviteCodeLength)
+	if err != nil {
+		return nil, fmt.Errorf("generate invite code: %w", err)
+	}
+
+	invite, err := s.inviteRepo.Create(ctx, model.CreateGroupInviteInput{
+		ChatID:           chatID,
+		Code:             code,
+		CreatedBy:        userID,
+		RequiresApproval: input.RequiresApproval,
+		MaxUses:          input.MaxUses,
+		ExpiresAt:        input.ExpiresAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create invite: %w", err)
+	}
+	return invite, nil
+}
+
+func (s *groupInviteService) ListInvites(ctx context.Context, chatID, userID uuid.UUID) ([]*model.GroupInvite, error) {
+	if _, err := s.requireGroupAdmin(ctx, chatID, userID); err != nil {
+		return nil, err
+	}
+
+	invites, err := s.inviteRepo.ListByChat(ctx, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("list invites: %w", err)
+	}
+	return invites, nil
+}
+
+func (s *groupInviteService) RevokeInvite(ctx context.Context, chatID, inviteID, userID uuid.UUID) error {
+	if _, err := s.requireGroupAdmin(ctx, chatID, userID); err != nil {
+		return err
+	}
+
+	invite, err := s.inviteRepo.FindByID(ctx, inviteID)
+	if err != nil {
+		return fmt.Errorf("find invite: %w", err)
+	}
+	if invite.ChatID != chatID {
+		return apperror.NotFound("invite", inviteID.String())
+	}
+
+	if err := s.inviteRepo.Revoke(ctx, inviteID); err != nil {
+		return fmt.Errorf("revoke invite: %w", err)
+	}
+	return nil
+}
+
+func (s *groupInviteService) PreviewInvite(ctx context.Context, code string) (*GroupPreview, error) {
+	invite, chat, err := s.usableInvite(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.group.chatRepo.GetMembers(ctx, chat.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get members: %w", err)
+	}
+
+	return &GroupPreview{
+		ChatID:           chat.ID,
+		Name:             chat.Name,
+		Icon:             chat.Icon,
+		Description:      chat.Description,
+		MemberCount:      len(members),
+		RequiresApproval: invite.RequiresApproval,
+	}, nil
+}
+
+func (s *groupInviteService) JoinWithInvite(ctx context.Context, code string, userID uuid.UUID) (*JoinResult, error) {
+	invite, chat, err := s.usableInvite(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.group.chatRepo.GetMembers(ctx, chat.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get members: %w", err)
+	}
+	if chatMemberSet(members)[userID] {
+		return nil, apperror.Conflict("you are already a member of this group")
+	}
+
+	user, err := s.group.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("find user: %w", err)
+	}
+	userName := "Seseorang"
+	if user.Name != "" {
+		userName = user.Name
+	}
+
+	if invite.RequiresApproval {
+		// A request to join counts as a use, whether or not it is approved
+		// later; the repository counts it together with the request
+		request, err := s.inviteRepo.CreateJoinRequest(ctx, chat.ID, userID, invite.ID)
+		if err != nil {
+			return nil, fmt.Errorf("create join request: %w", err)
+		}
+		s.notifyAdmins(chat, members, request, userName)
+		return &JoinResult{Chat: chat, Request: request}, nil
+	}
+
+	ok, err := s.inviteRepo.Use(ctx, invite.ID)
+	if err != nil {
+		return nil, fmt.Errorf("use invite: %w", err)
+	}
+	if !ok {
+		return nil, apperror.BadRequest("invite link is no longer valid")
+	}
+
+	if err := s.group.chatRepo.AddMember(ctx, chat.ID, userID, model.MemberRoleMember); err != nil {
+		return nil, fmt.Errorf("add member: %w", err)
+	}
+	s.group.sendSystemMessage(ctx, chat.ID, userID, userName+" bergabung menggunakan tautan undangan")
+	s.group.broadcastGroupEvent(chat.ID, "member_added", map[string]interface{}{
+		"chatId": chat.ID.String(),
+		"userId": userID.String(),
+		"name":   userName,
+	})
+
+	return &JoinResult{Chat: chat, Joined: true}, nil
+}
+
+func (s *groupInviteService) ListJoinRequests(ctx context.Context, chatID, userID uuid.UUID) ([]*model.JoinRequest, error) {
+	if _, err := s.requireGroupAdmin(ctx, chatID, userID); err != nil {
+		return nil, err
+	}
+
+	requests, err := s.inviteRepo.ListPendingJoinRequests(ctx, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("list join requests: %w", err)
+	}
+	return requests, nil
+}
+
+func (s *groupInviteService) ApproveJoinRequest(ctx context.Context, chatID, requestID, userID uuid.UUID) error {
+	chat, err := s.requireGroupAdmin(ctx, chatID, userID)
+	if err != nil {
+		return err
+	}
+	request, err := s.pendingRequest(ctx, chatID, requestID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.inviteRepo.DecideJoinRequest(ctx, requestID, model.JoinRequestApproved, userID); err != nil {
+		return fmt.Errorf("approve join request: %w", err)
+	}
+	if err := s.group.chatRepo.AddMember(ctx, chatID, request.UserID, model.MemberRoleMember); err != nil {
+		return fmt.Errorf("add member: %w", err)
+	}
+
+	approverName := "Seseorang"
+	if approver, _ := s.group.userRepo.FindByID(ctx, userID); approver != nil && approver.Name != "" {
+		approverName = approver.Name
+	}
+	newUserName := "seseorang"
+	if newUser, _ := s.group.userRepo.FindByID(ctx, request.UserID); newUser != nil && newUser.Name != "" {
+		newUserName = newUser.Name
+	}
+
+	s.group.sendSystemMessage(ctx, chatID, userID, approverName+" menyetujui "+newUserName+" bergabung")
+	s.group.broadcastGroupEvent(chatID, "member_added", map[string]interface{}{
+		"chatId": chatID.String(),
+		"userId": request.UserID.String(),
+		"name":   newUserName,
+	})
+
+	if s.group.notifSvc != nil {
+		go func() {
+			notif := BuildJoinApprovedNotif(chat.Name, chatID)
+			_ = s.group.notifSvc.SendToUser(context.Background(), request.UserID, notif)
+		}()
+	}
+
+	return nil
+}
+
+func (s *groupInviteService) RejectJoinRequest(ctx context.Context, chatID, requestID, userID uuid.UUID) error {
+	if _, err := s.requireGroupAdmin(ctx, chatID, userID); err != nil {
+		return err
+	}
+	if _, err := s.pendingRequest(ctx, chatID, requestID); err != nil {
+		return err
+	}
+
+	if err := s.inviteRepo.DecideJoinRequest(ctx, requestID, model.JoinRequestRejected, userID); err != nil {
+		return fmt.Errorf("reject join request: %w", err)
+	}
+	return nil
+}
+
+// requireGroupAdmin checks that chatID is a group userID administers.
+func (s *groupInviteService) requireGroupAdmin(ctx context.Context, chatID, userID uuid.UUID) (*model.Chat, error) {
+	if err := s.group.requireAdmin(ctx, chatID, userID); err != nil {
+		return nil, err
+	}
+
+	chat, err := s.group.chatRepo.FindByID(ctx, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("find chat: %w", err)
+	}
+	if chat.Type != model.ChatTypeGroup {
+		return nil, apperror.BadRequest("invite links are only available for group chats")
+	}
+	return chat, nil
+}
+
+// usableInvite looks up an invite by code together with its group, failing
+// when the invite can no longer be used.
+func (s *groupInviteService) usableInvite(ctx context.Context, code string) (*model.GroupInvite, *model.Chat, error) {
+	invite, err := s.inviteRepo.FindByCode(ctx, strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("find invite: %w", err)
+	}
+	if !invite.Usable(time.Now()) {
+		return nil, nil, apperror.BadRequest("invite link is no longer valid")
+	}
+
+	chat, err := s.group.chatRepo.FindByID(ctx, invite.ChatID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("find chat: %w", err)
+	}
+	return invite, chat, nil
+}
+
+func (s *groupInviteService) pendingRequest(ctx context.Context, chatID, requestID uuid.UUID) (*model.JoinRequest, error) {
+	request, err := s.inviteRepo.FindJoinRequest(ctx, requestID)
+	if err != nil {
+		return nil, fmt.Errorf("find join request: %w", err)
+	}
+	if request.ChatID != chatID || request.Status != model.JoinRequestPending {
+		return nil, apperror.NotFound("join request", requestID.String())
+	}
+	return request, nil
+}
+
+// notifyAdmins tells the group admins about a new join request over
+// WebSocket and push.
+func (s *groupInviteService) notifyAdmins(chat *model.Chat, members []*model.ChatMember, request *model.JoinRequest, userName string) {
+	var admins []uuid.UUID
+	for _, m := range members {
+		if m.Role.IsAdmin() {
+			admins = append(admins, m.UserID)
+		}
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"type": "join_request",
+		"payload": map[string]interface{}{
+			"chatId":    chat.ID.String(),
+			"requestId": request.ID.String(),
+			"userId":    request.UserID.String(),
+			"name":      userName,
+		},
+	})
+	if err == nil {
+		for _, id := range admins {
+			s.group.hub.SendToUser(id, data)
+		}
+	}
+
+	if s.group.notifSvc != nil && len(admins) > 0 {
+		go func() {
+			notif := BuildJoinRequestNotif(userName, chat.Name, chat.ID)
+			_ = s.group.notifSvc.SendToUsers(context.Background(), admins, notif)
+		}()
+	}
+}
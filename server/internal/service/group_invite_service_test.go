@@ -0,0 +1,378 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+type mockGroupInviteRepo struct {
+	invites  map[uuid.UUID]*model.GroupInvite
+	requests map[uuid.UUID]*model.JoinRequest
+}
+
+func newMockGroupInviteRepo() *mockGroupInviteRepo {
+	return &mockGroupInviteRepo{
+		invites:  make(map[uuid.UUID]*model.GroupInvite),
+		requests: make(map[uuid.UUID]*model.JoinRequest),
+	}
+}
+
+func (m *mockGroupInviteRepo) Create(_ context.Context, input model.CreateGroupInviteInput) (*model.GroupInvite, error) {
+	invite := &model.GroupInvite{
+		ID:               uuid.New(),
+		ChatID:           input.ChatID,
+		Code:             input.Code,
+		CreatedBy:        input.CreatedBy,
+		RequiresApproval: input.RequiresApproval,
+		MaxUses:          input.MaxUses,
+		ExpiresAt:        input.ExpiresAt,
+		CreatedAt:        time.Now(),
+	}
+	m.invites[invite.ID] = invite
+	return invite, nil
+}
+
+func (m *mockGroupInviteRepo) FindByID(_ context.Context, id uuid.UUID) (*model.GroupInvite, error) {
+	invite, ok := m.invites[id]
+	if !ok {
+		return nil, apperror.NotFound("invite", id.String())
+	}
+	return invite, nil
+}
+
+func (m *mockGroupInviteRepo) FindByCode(_ context.Context, code string) (*model.GroupInvite, error) {
+	for _, invite := range m.invites {
+		if invite.Code == code {
+			return invite, nil
+		}
+	}
+	return nil, apperror.NotFound("invite", code)
+}
+
+func (m *mockGroupInviteRepo) ListByChat(_ context.Context, chatID uuid.UUID) ([]*model.GroupInvite, error) {
+	invites := make([]*model.GroupInvite, 0)
+	for _, invite := range m.invites {
+		if invite.ChatID == chatID {
+			invites = append(invites, invite)
+		}
+	}
+	return invites, nil
+}
+
+func (m *mockGroupInviteRepo) Revoke(_ context.Context, id uuid.UUID) error {
+	invite, ok := m.invites[id]
+	if !ok {
+		return apperror.NotFound("invite", id.String())
+	}
+	now := time.Now()
+	invite.RevokedAt = &now
+	return nil
+}
+
+func (m *mockGroupInviteRepo) Use(_ context.Context, id uuid.UUID) (bool, error) {
+	invite, ok := m.invites[id]
+	if !ok || !invite.Usable(time.Now()) {
+		return false, nil
+	}
+	invite.UseCount++
+	return true, nil
+}
+
+func (m *mockGroupInviteRepo) CreateJoinRequest(_ context.Context, chatID, userID, inviteID uuid.UUID) (*model.JoinRequest, error) {
+	for _, jr := range m.requests {
+		if jr.ChatID == chatID && jr.UserID == userID && jr.Status == model.JoinRequestPending {
+			return nil, apperror.Conflict("you already asked to join this group")
+		}
+	}
+	invite, ok := m.invites[inviteID]
+	if !ok || !invite.Usable(time.Now()) {
+		return nil, apperror.BadRequest("invite link is no longer valid")
+	}
+	invite.UseCount++
+	jr := &model.JoinRequest{
+		ID:        uuid.New(),
+		ChatID:    chatID,
+		UserID:    userID,
+		InviteID:  inviteID,
+		Status:    model.JoinRequestPending,
+		CreatedAt: time.Now(),
+	}
+	m.requests[jr.ID] = jr
+	return jr, nil
+}
+
+func (m *mockGroupInviteRepo) FindJoinRequest(_ context.Context, id uuid.UUID) (*model.JoinRequest, error) {
+	jr, ok := m.requests[id]
+	if !ok {
+		return nil, apperror.NotFound("join request", id.String())
+	}
+	return jr, nil
+}
+
+func (m *mockGroupInviteRepo) ListPendingJoinRequests(_ context.Context, chatID uuid.UUID) ([]*model.JoinRequest, error) {
+	requests := make([]*model.JoinRequest, 0)
+	for _, jr := range m.requests {
+		if jr.ChatID == chatID && jr.Status == model.JoinRequestPending {
+			requests = append(requests, jr)
+		}
+	}
+	return requests, nil
+}
+
+func (m *mockGroupInviteRepo) DecideJoinRequest(_ context.Context, id uuid.UUID, status model.JoinRequestStatus, decidedBy uuid.UUID) error {
+	jr, ok := m.requests[id]
+	if !ok || jr.Status != model.JoinRequestPending {
+		return apperror.NotFound("join request", id.String())
+	}
+	now := time.Now()
+	jr.Status = status
+	jr.DecidedBy = &decidedBy
+	jr.DecidedAt = &now
+	return nil
+}
+
+// ==================== GroupInviteService Tests ====================
+
+func TestGroupInviteService_CreateInvite(t *testing.T) {
+	ctx := context.Background()
+	chatRepo := newMockChatRepo()
+	userRepo := newMockUserRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	svc := NewGroupInviteService(newMockGroupInviteRepo(), chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
+
+	admin := uuid.New()
+	member := uuid.New()
+	userRepo.addUser(&model.User{ID: admin, Phone: "+628111", Name: "Admin"})
+	userRepo.addUser(&model.User{ID: member, Phone: "+628222", Name: "Member"})
+	group := &model.Chat{ID: uuid.New(), Type: model.ChatTypeGroup, Name: "Keluarga", Icon: "🏠", CreatedBy: admin}
+	chatRepo.chats[group.ID] = group
+	_ = chatRepo.AddMember(ctx, group.ID, admin, model.MemberRoleAdmin)
+	_ = chatRepo.AddMember(ctx, group.ID, member, model.MemberRoleMember)
+
+	t.Run("admin creates invite", func(t *testing.T) {
+		maxUses := 5
+		invite, err := svc.CreateInvite(ctx, group.ID, admin, CreateInviteInput{MaxUses: &maxUses})
+		require.NoError(t, err)
+		assert.Len(t, invite.Code, inviteCodeLength)
+		assert.Equal(t, &maxUses, invite.MaxUses)
+
+		invites, err := svc.ListInvites(ctx, group.ID, admin)
+		require.NoError(t, err)
+		assert.Len(t, invites, 1)
+	})
+
+	t.Run("member cannot create invite", func(t *testing.T) {
+		_, err := svc.CreateInvite(ctx, group.ID, member, CreateInviteInput{})
+		assert.True(t, apperror.IsForbidden(err))
+		_, err = svc.ListInvites(ctx, group.ID, member)
+		assert.True(t, apperror.IsForbidden(err))
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		zero := 0
+		_, err := svc.CreateInvite(ctx, group.ID, admin, CreateInviteInput{MaxUses: &zero})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "maxUses")
+
+		past := time.Now().Add(-time.Minute)
+		_, err = svc.CreateInvite(ctx, group.ID, admin, CreateInviteInput{ExpiresAt: &past})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "expiresAt")
+	})
+}
+
+func TestGroupInviteService_JoinWithInvite(t *testing.T) {
+	ctx := context.Background()
+	chatRepo := newMockChatRepo()
+	msgRepo := newMockMessageRepo()
+	userRepo := newMockUserRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	svc := NewGroupInviteService(newMockGroupInviteRepo(), chatRepo, msgRepo, newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
+
+	admin := uuid.New()
+	member := uuid.New()
+	budi := uuid.New()
+	userA := uuid.New()
+	userB := uuid.New()
+	userC := uuid.New()
+	userRepo.addUser(&model.User{ID: admin, Phone: "+628111", Name: "Admin"})
+	userRepo.addUser(&model.User{ID: member, Phone: "+628222", Name: "Member"})
+	userRepo.addUser(&model.User{ID: budi, Phone: "+628333", Name: "Budi"})
+	userRepo.addUser(&model.User{ID: userA, Phone: "+628444", Name: "A"})
+	userRepo.addUser(&model.User{ID: userB, Phone: "+628555", Name: "B"})
+	userRepo.addUser(&model.User{ID: userC, Phone: "+628666", Name: "C"})
+	group := &model.Chat{ID: uuid.New(), Type: model.ChatTypeGroup, Name: "Keluarga", Icon: "🏠", CreatedBy: admin}
+	chatRepo.chats[group.ID] = group
+	_ = chatRepo.AddMember(ctx, group.ID, admin, model.MemberRoleAdmin)
+	_ = chatRepo.AddMember(ctx, group.ID, member, model.MemberRoleMember)
+
+	t.Run("join directly", func(t *testing.T) {
+		invite, err := svc.CreateInvite(ctx, group.ID, admin, CreateInviteInput{})
+		require.NoError(t, err)
+
+		preview, err := svc.PreviewInvite(ctx, invite.Code)
+		require.NoError(t, err)
+		assert.Equal(t, "Keluarga", preview.Name)
+		assert.Equal(t, 2, preview.MemberCount)
+		assert.False(t, preview.RequiresApproval)
+
+		// Codes are matched case-insensitively
+		result, err := svc.JoinWithInvite(ctx, " "+invite.Code+" ", budi)
+		require.NoError(t, err)
+		assert.True(t, result.Joined)
+		assert.Nil(t, result.Request)
+
+		members, _ := chatRepo.GetMembers(ctx, group.ID)
+		assert.True(t, chatMemberSet(members)[budi])
+		assert.Equal(t, 1, invite.UseCount)
+
+		msgs := msgRepo.byChat[group.ID]
+		require.NotEmpty(t, msgs)
+		assert.Equal(t, model.MessageTypeSystem, msgs[len(msgs)-1].Type)
+		assert.Contains(t, msgs[len(msgs)-1].Content, "Budi")
+
+		_, err = svc.JoinWithInvite(ctx, invite.Code, budi)
+		assert.True(t, apperror.IsConflict(err))
+	})
+
+	t.Run("max uses", func(t *testing.T) {
+		one := 1
+		invite, err := svc.CreateInvite(ctx, group.ID, admin, CreateInviteInput{MaxUses: &one})
+		require.NoError(t, err)
+
+		_, err = svc.JoinWithInvite(ctx, invite.Code, userA)
+		require.NoError(t, err)
+		_, err = svc.JoinWithInvite(ctx, invite.Code, userB)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no longer valid")
+	})
+
+	t.Run("expired and revoked", func(t *testing.T) {
+		invite, err := svc.CreateInvite(ctx, group.ID, admin, CreateInviteInput{})
+		require.NoError(t, err)
+
+		past := time.Now().Add(-time.Minute)
+		invite.ExpiresAt = &past
+		_, err = svc.PreviewInvite(ctx, invite.Code)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no longer valid")
+
+		invite.ExpiresAt = nil
+		require.NoError(t, svc.RevokeInvite(ctx, group.ID, invite.ID, admin))
+		_, err = svc.JoinWithInvite(ctx, invite.Code, userC)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no longer valid")
+	})
+
+	t.Run("unknown code", func(t *testing.T) {
+		_, err := svc.PreviewInvite(ctx, "NOPE")
+		assert.True(t, apperror.IsNotFound(err))
+	})
+
+	t.Run("revoke invite of another group", func(t *testing.T) {
+		other := &model.Chat{ID: uuid.New(), Type: model.ChatTypeGroup, Name: "Kantor", Icon: "💼", CreatedBy: admin}
+		chatRepo.chats[other.ID] = other
+		_ = chatRepo.AddMember(ctx, other.ID, admin, model.MemberRoleAdmin)
+		invite, err := svc.CreateInvite(ctx, other.ID, admin, CreateInviteInput{})
+		require.NoError(t, err)
+
+		err = svc.RevokeInvite(ctx, group.ID, invite.ID, admin)
+		assert.True(t, apperror.IsNotFound(err))
+	})
+}
+
+func TestGroupInviteService_JoinRequests(t *testing.T) {
+	ctx := context.Background()
+	inviteRepo := newMockGroupInviteRepo()
+	chatRepo := newMockChatRepo()
+	msgRepo := newMockMessageRepo()
+	userRepo := newMockUserRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	svc := NewGroupInviteService(inviteRepo, chatRepo, msgRepo, newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
+
+	admin := uuid.New()
+	member := uuid.New()
+	alice := uuid.New()
+	bob := uuid.New()
+	userRepo.addUser(&model.User{ID: admin, Phone: "+628111", Name: "Admin"})
+	userRepo.addUser(&model.User{ID: member, Phone: "+628222", Name: "Member"})
+	userRepo.addUser(&model.User{ID: alice, Phone: "+628333", Name: "Alice"})
+	userRepo.addUser(&model.User{ID: bob, Phone: "+628444", Name: "Bob"})
+	group := &model.Chat{ID: uuid.New(), Type: model.ChatTypeGroup, Name: "Keluarga", Icon: "🏠", CreatedBy: admin}
+	chatRepo.chats[group.ID] = group
+	_ = chatRepo.AddMember(ctx, group.ID, admin, model.MemberRoleAdmin)
+	_ = chatRepo.AddMember(ctx, group.ID, member, model.MemberRoleMember)
+
+	invite, err := svc.CreateInvite(ctx, group.ID, admin, CreateInviteInput{RequiresApproval: true})
+	require.NoError(t, err)
+
+	result, err := svc.JoinWithInvite(ctx, invite.Code, alice)
+	require.NoError(t, err)
+	assert.False(t, result.Joined)
+	require.NotNil(t, result.Request)
+	aliceReq := result.Request
+
+	// Asking twice while pending is rejected without spending a use
+	_, err = svc.JoinWithInvite(ctx, invite.Code, alice)
+	assert.True(t, apperror.IsConflict(err))
+	assert.Equal(t, 1, inviteRepo.invites[invite.ID].UseCount)
+
+	result, err = svc.JoinWithInvite(ctx, invite.Code, bob)
+	require.NoError(t, err)
+	bobReq := result.Request
+
+	members, _ := chatRepo.GetMembers(ctx, group.ID)
+	assert.False(t, chatMemberSet(members)[alice])
+
+	t.Run("only admins decide", func(t *testing.T) {
+		_, err := svc.ListJoinRequests(ctx, group.ID, member)
+		assert.True(t, apperror.IsForbidden(err))
+		err = svc.ApproveJoinRequest(ctx, group.ID, aliceReq.ID, member)
+		assert.True(t, apperror.IsForbidden(err))
+	})
+
+	t.Run("list pending", func(t *testing.T) {
+		requests, err := svc.ListJoinRequests(ctx, group.ID, admin)
+		require.NoError(t, err)
+		assert.Len(t, requests, 2)
+	})
+
+	t.Run("approve", func(t *testing.T) {
+		require.NoError(t, svc.ApproveJoinRequest(ctx, group.ID, aliceReq.ID, admin))
+		members, _ := chatRepo.GetMembers(ctx, group.ID)
+		assert.True(t, chatMemberSet(members)[alice])
+		assert.Equal(t, model.JoinRequestApproved, aliceReq.Status)
+
+		msgs := msgRepo.byChat[group.ID]
+		assert.Contains(t, msgs[len(msgs)-1].Content, "Alice")
+
+		// A decided request cannot be decided again
+		err := svc.RejectJoinRequest(ctx, group.ID, aliceReq.ID, admin)
+		assert.True(t, apperror.IsNotFound(err))
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		require.NoError(t, svc.RejectJoinRequest(ctx, group.ID, bobReq.ID, admin))
+		members, _ := chatRepo.GetMembers(ctx, group.ID)
+		assert.False(t, chatMemberSet(members)[bob])
+		assert.Equal(t, model.JoinRequestRejected, bobReq.Status)
+
+		requests, err := svc.ListJoinRequests(ctx, group.ID, admin)
+		require.NoError(t, err)
+		assert.Empty(t, requests)
+	})
+}
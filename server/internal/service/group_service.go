@@ -50,6 +50,9 @@ type GroupService interface {
 	AddMember(ctx context.Context, chatID, userID, addedBy uuid.UUID) error
 	RemoveMember(ctx context.Context, chatID, userID, removedBy uuid.UUID) error
 	PromoteToAdmin(ctx context.Context, chatID, userID, promotedBy uuid.UUID) error
+	DemoteAdmin(ctx context.Context, chatID, userID, demotedBy uuid.UUID) error
+	TransferOwnership(ctx context.Context, chatID, userID, ownerID uuid.UUID) error
+	UpdatePermissions(ctx context.Context, chatID, userID uuid.UUID, input model.UpdateGroupPermissionsInput) (*model.Chat, error)
 	LeaveGroup(ctx context.Context, chatID, userID uuid.UUID) error
 	DeleteGroup(ctx context.Context, chatID, userID uuid.UUID) error
 	GetGroupInfo(ctx context.Context, chatID, userID uuid.UUID) (*GroupInfo, error)
@@ -60,6 +63,8 @@ type groupService struct {
 	messageRepo     repository.MessageRepository
 	messageStatRepo repository.MessageStatusRepository
 	userRepo        repository.UserRepository
+	blockRepo       repository.UserBlockRepository
+	privacy         PrivacyPolicy
 	hub             *ws.Hub
 	notifSvc        NotificationService
 }
@@ -70,14 +75,33 @@ func NewGroupService(
 	messageRepo repository.MessageRepository,
 	messageStatRepo repository.MessageStatusRepository,
 	userRepo repository.UserRepository,
+	blockRepo repository.UserBlockRepository,
+	privacy PrivacyPolicy,
 	hub *ws.Hub,
 	notifSvc NotificationService,
 ) GroupService {
+	return newGroupService(chatRepo, messageRepo, messageStatRepo, userRepo, blockRepo, privacy, hub, notifSvc)
+}
+
+// newGroupService builds the concrete service, which GroupInviteService
+// embeds for its admin checks and group events.
+func newGroupService(
+	chatRepo repository.ChatRepository,
+	messageRepo repository.MessageRepository,
+	messageStatRepo repository.MessageStatusRepository,
+	userRepo repository.UserRepository,
+	blockRepo repository.UserBlockRepository,
+	privacy PrivacyPolicy,
+	hub *ws.Hub,
+	notifSvc NotificationService,
+) *groupService {
 	return &groupService{
 		chatRepo:        chatRepo,
 		messageRepo:     messageRepo,
 		messageStatRepo: messageStatRepo,
 		userRepo:        userRepo,
+		blockRepo:       blockRepo,
+		privacy:         privacy,
 		hub:             hub,
 		notifSvc:        notifSvc,
 	}
@@ -119,7 +143,7 @@ func (s *groupService) CreateGroup(ctx context.Context, creatorID uuid.UUID, inp
 		return nil, apperror.Validation("memberIds", "group must have at least 2 other members")
 	}
 
-	// Verify all member users exist
+	// Verify all member users exist and can be added by the creator
 	for _, memberID := range memberIDs {
 		if _, err := s.userRepo.FindByID(ctx, memberID); err != nil {
 			if apperror.IsNotFound(err) {
@@ -127,6 +151,9 @@ func (s *groupService) CreateGroup(ctx context.Context, creatorID uuid.UUID, inp
 			}
 			return nil, fmt.Errorf("verify member: %w", err)
 		}
+		if err := s.requireNotBlocked(ctx, creatorID, memberID); err != nil {
+			return nil, err
+		}
 	}
 
 	// Create the group chat
@@ -141,8 +168,8 @@ func (s *groupService) CreateGroup(ctx context.Context, creatorID uuid.UUID, inp
 		return nil, fmt.Errorf("create group: %w", err)
 	}
 
-	// Add creator as admin
-	if err := s.chatRepo.AddMember(ctx, chat.ID, creatorID, model.MemberRoleAdmin); err != nil {
+	// Add creator as owner
+	if err := s.chatRepo.AddMember(ctx, chat.ID, creatorID, model.MemberRoleOwner); err != nil {
 		return nil, fmt.Errorf("add creator: %w", err)
 	}
 
@@ -175,11 +202,6 @@ func (s *groupService) CreateGroup(ctx context.Context, creatorID uuid.UUID, inp
 }
 
 func (s *groupService) UpdateGroup(ctx context.Context, chatID, userID uuid.UUID, input UpdateGroupInput) (*model.Chat, error) {
-	// Verify user is admin
-	if err := s.requireAdmin(ctx, chatID, userID); err != nil {
-		return nil, err
-	}
-
 	// Get current chat
 	chat, err := s.chatRepo.FindByID(ctx, chatID)
 	if err != nil {
@@ -191,6 +213,11 @@ func (s *groupService) UpdateGroup(ctx context.Context, chatID, userID uuid.UUID
 		return nil, apperror.BadRequest("only group chats can be updated")
 	}
 
+	// Verify user may edit the group info
+	if err := s.requirePermission(ctx, chatID, userID, chat.Permissions.OnlyAdminsEditInfo); err != nil {
+		return nil, err
+	}
+
 	// Build update input
 	updateInput := model.UpdateChatInput{
 		Name:        input.Name,
@@ -225,11 +252,6 @@ func (s *groupService) UpdateGroup(ctx context.Context, chatID, userID uuid.UUID
 }
 
 func (s *groupService) AddMember(ctx context.Context, chatID, userID, addedBy uuid.UUID) error {
-	// Verify adder is admin
-	if err := s.requireAdmin(ctx, chatID, addedBy); err != nil {
-		return err
-	}
-
 	// Ensure it's a group
 	chat, err := s.chatRepo.FindByID(ctx, chatID)
 	if err != nil {
@@ -239,6 +261,11 @@ func (s *groupService) AddMember(ctx context.Context, chatID, userID, addedBy uu
 		return apperror.BadRequest("can only add members to group chats")
 	}
 
+	// Verify adder may add members
+	if err := s.requirePermission(ctx, chatID, addedBy, chat.Permissions.OnlyAdminsAddMembers); err != nil {
+		return err
+	}
+
 	// Check if user exists
 	newUser, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
@@ -247,6 +274,9 @@ func (s *groupService) AddMember(ctx context.Context, chatID, userID, addedBy uu
 		}
 		return fmt.Errorf("find user: %w", err)
 	}
+	if err := s.requireNotBlocked(ctx, addedBy, userID); err != nil {
+		return err
+	}
 
 	// Check if already a member
 	members, err := s.chatRepo.GetMembers(ctx, chatID)
@@ -307,7 +337,6 @@ func (s *groupService) RemoveMember(ctx context.Context, chatID, userID, removed
 		return apperror.BadRequest("use leave endpoint to leave the group")
 	}
 
-	// Cannot remove the creator
 	chat, err := s.chatRepo.FindByID(ctx, chatID)
 	if err != nil {
 		return fmt.Errorf("find chat: %w", err)
@@ -315,8 +344,14 @@ func (s *groupService) RemoveMember(ctx context.Context, chatID, userID, removed
 	if chat.Type != model.ChatTypeGroup {
 		return apperror.BadRequest("can only remove members from group chats")
 	}
-	if userID == chat.CreatedBy {
-		return apperror.Forbidden("cannot remove the group creator")
+
+	// Cannot remove the owner
+	target, err := s.findMember(ctx, chatID, userID)
+	if err != nil {
+		return err
+	}
+	if target != nil && target.Role == model.MemberRoleOwner {
+		return apperror.Forbidden("cannot remove the group owner")
 	}
 
 	// Get the user being removed
@@ -363,33 +398,21 @@ func (s *groupService) PromoteToAdmin(ctx context.Context, chatID, userID, promo
 	}
 
 	// Ensure the target user is a member
-	members, err := s.chatRepo.GetMembers(ctx, chatID)
+	targetMember, err := s.findMember(ctx, chatID, userID)
 	if err != nil {
-		return fmt.Errorf("get members: %w", err)
-	}
-
-	var targetMember *model.ChatMember
-	for _, m := range members {
-		if m.UserID == userID {
-			targetMember = m
-			break
-		}
+		return err
 	}
 	if targetMember == nil {
 		return apperror.NotFound("member", userID.String())
 	}
 
 	// Already admin
-	if targetMember.Role == model.MemberRoleAdmin {
+	if targetMember.Role.IsAdmin() {
 		return apperror.Conflict("user is already an admin")
 	}
 
-	// Remove and re-add as admin (since we don't have UpdateMemberRole)
-	if err := s.chatRepo.RemoveMember(ctx, chatID, userID); err != nil {
-		return fmt.Errorf("remove member for promotion: %w", err)
-	}
-	if err := s.chatRepo.AddMember(ctx, chatID, userID, model.MemberRoleAdmin); err != nil {
-		return fmt.Errorf("add member as admin: %w", err)
+	if err := s.chatRepo.UpdateMemberRole(ctx, chatID, userID, model.MemberRoleAdmin); err != nil {
+		return fmt.Errorf("promote member: %w", err)
 	}
 
 	// System message
@@ -416,6 +439,159 @@ func (s *groupService) PromoteToAdmin(ctx context.Context, chatID, userID, promo
 	return nil
 }
 
+func (s *groupService) DemoteAdmin(ctx context.Context, chatID, userID, demotedBy uuid.UUID) error {
+	// Verify demoter is admin
+	if err := s.requireAdmin(ctx, chatID, demotedBy); err != nil {
+		return err
+	}
+
+	// Ensure the target user is an admin other than the owner
+	targetMember, err := s.findMember(ctx, chatID, userID)
+	if err != nil {
+		return err
+	}
+	if targetMember == nil {
+		return apperror.NotFound("member", userID.String())
+	}
+	if targetMember.Role == model.MemberRoleOwner {
+		return apperror.Forbidden("the group owner cannot be demoted")
+	}
+	if targetMember.Role != model.MemberRoleAdmin {
+		return apperror.Conflict("user is not an admin")
+	}
+
+	if err := s.chatRepo.UpdateMemberRole(ctx, chatID, userID, model.MemberRoleMember); err != nil {
+		return fmt.Errorf("demote admin: %w", err)
+	}
+
+	// System message
+	demoter, _ := s.userRepo.FindByID(ctx, demotedBy)
+	demoterName := "Seseorang"
+	if demoter != nil && demoter.Name != "" {
+		demoterName = demoter.Name
+	}
+	target, _ := s.userRepo.FindByID(ctx, userID)
+	targetName := "seseorang"
+	if target != nil && target.Name != "" {
+		targetName = target.Name
+	}
+
+	sysMsg := demoterName + " memberhentikan " + targetName + " sebagai admin"
+	if userID == demotedBy {
+		sysMsg = demoterName + " berhenti sebagai admin"
+	}
+	s.sendSystemMessage(ctx, chatID, demotedBy, sysMsg)
+
+	// Broadcast
+	s.broadcastGroupEvent(chatID, "member_demoted", map[string]interface{}{
+		"chatId": chatID.String(),
+		"userId": userID.String(),
+	})
+
+	return nil
+}
+
+func (s *groupService) TransferOwnership(ctx context.Context, chatID, userID, ownerID uuid.UUID) error {
+	chat, err := s.chatRepo.FindByID(ctx, chatID)
+	if err != nil {
+		return fmt.Errorf("find chat: %w", err)
+	}
+	if chat.Type != model.ChatTypeGroup {
+		return apperror.BadRequest("can only transfer ownership of group chats")
+	}
+
+	// Only the owner can hand over the group
+	owner, err := s.findMember(ctx, chatID, ownerID)
+	if err != nil {
+		return err
+	}
+	if owner == nil || owner.Role != model.MemberRoleOwner {
+		return apperror.Forbidden("only the group owner can transfer ownership")
+	}
+	if userID == ownerID {
+		return apperror.BadRequest("you already own this group")
+	}
+
+	targetMember, err := s.findMember(ctx, chatID, userID)
+	if err != nil {
+		return err
+	}
+	if targetMember == nil {
+		return apperror.NotFound("member", userID.String())
+	}
+
+	// The previous owner stays on as an admin
+	if err := s.chatRepo.TransferOwnership(ctx, chatID, ownerID, userID); err != nil {
+		return fmt.Errorf("transfer ownership: %w", err)
+	}
+
+	// System message
+	prevOwner, _ := s.userRepo.FindByID(ctx, ownerID)
+	prevOwnerName := "Seseorang"
+	if prevOwner != nil && prevOwner.Name != "" {
+		prevOwnerName = prevOwner.Name
+	}
+	target, _ := s.userRepo.FindByID(ctx, userID)
+	targetName := "seseorang"
+	if target != nil && target.Name != "" {
+		targetName = target.Name
+	}
+
+	sysMsg := prevOwnerName + " menjadikan " + targetName + " sebagai pemilik grup"
+	s.sendSystemMessage(ctx, chatID, ownerID, sysMsg)
+
+	// Broadcast
+	s.broadcastGroupEvent(chatID, "owner_changed", map[string]interface{}{
+		"chatId":          chatID.String(),
+		"userId":          userID.String(),
+		"previousOwnerId": ownerID.String(),
+	})
+
+	return nil
+}
+
+func (s *groupService) UpdatePermissions(ctx context.Context, chatID, userID uuid.UUID, input model.UpdateGroupPermissionsInput) (*model.Chat, error) {
+	// Verify user is admin
+	if err := s.requireAdmin(ctx, chatID, userID); err != nil {
+		return nil, err
+	}
+
+	chat, err := s.chatRepo.FindByID(ctx, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("find chat: %w", err)
+	}
+	if chat.Type != model.ChatTypeGroup {
+		return nil, apperror.BadRequest("only group chats have permissions")
+	}
+
+	previous := chat.Permissions
+	updated, err := s.chatRepo.UpdatePermissions(ctx, chatID, input)
+	if err != nil {
+		return nil, fmt.Errorf("update permissions: %w", err)
+	}
+	if updated.Permissions == previous {
+		return updated, nil
+	}
+
+	// System message
+	user, _ := s.userRepo.FindByID(ctx, userID)
+	userName := "Seseorang"
+	if user != nil && user.Name != "" {
+		userName = user.Name
+	}
+
+	sysMsg := userName + " mengubah pengaturan grup"
+	s.sendSystemMessage(ctx, chatID, userID, sysMsg)
+
+	// Broadcast
+	s.broadcastGroupEvent(chatID, "group_permissions_updated", map[string]interface{}{
+		"chatId":      chatID.String(),
+		"permissions": updated.Permissions,
+	})
+
+	return updated, nil
+}
+
 func (s *groupService) LeaveGroup(ctx context.Context, chatID, userID uuid.UUID) error {
 	// Verify user is a member
 	members, err := s.chatRepo.GetMembers(ctx, chatID)
@@ -423,18 +599,17 @@ func (s *groupService) LeaveGroup(ctx context.Context, chatID, userID uuid.UUID)
 		return fmt.Errorf("get members: %w", err)
 	}
 
-	isMember := false
+	var member *model.ChatMember
 	for _, m := range members {
 		if m.UserID == userID {
-			isMember = true
+			member = m
 			break
 		}
 	}
-	if !isMember {
+	if member == nil {
 		return apperror.Forbidden("you are not a member of this group")
 	}
 
-	// Creator cannot leave
 	chat, err := s.chatRepo.FindByID(ctx, chatID)
 	if err != nil {
 		return fmt.Errorf("find chat: %w", err)
@@ -442,8 +617,10 @@ func (s *groupService) LeaveGroup(ctx context.Context, chatID, userID uuid.UUID)
 	if chat.Type != model.ChatTypeGroup {
 		return apperror.BadRequest("can only leave group chats")
 	}
-	if userID == chat.CreatedBy {
-		return apperror.Forbidden("group creator cannot leave the group, delete it instead")
+
+	// Owner cannot leave
+	if member.Role == model.MemberRoleOwner {
+		return apperror.Forbidden("group owner cannot leave the group, transfer ownership or delete it instead")
 	}
 
 	// Remove the member
@@ -471,7 +648,6 @@ func (s *groupService) LeaveGroup(ctx context.Context, chatID, userID uuid.UUID)
 }
 
 func (s *groupService) DeleteGroup(ctx context.Context, chatID, userID uuid.UUID) error {
-	// Only creator can delete
 	chat, err := s.chatRepo.FindByID(ctx, chatID)
 	if err != nil {
 		return fmt.Errorf("find chat: %w", err)
@@ -479,8 +655,14 @@ func (s *groupService) DeleteGroup(ctx context.Context, chatID, userID uuid.UUID
 	if chat.Type != model.ChatTypeGroup {
 		return apperror.BadRequest("can only delete group chats")
 	}
-	if chat.CreatedBy != userID {
-		return apperror.Forbidden("only the group creator can delete the group")
+
+	// Only owner can delete
+	member, err := s.findMember(ctx, chatID, userID)
+	if err != nil {
+		return err
+	}
+	if member == nil || member.Role != model.MemberRoleOwner {
+		return apperror.Forbidden("only the group owner can delete the group")
 	}
 
 	// Broadcast before deletion so members get notified
@@ -530,10 +712,14 @@ func (s *groupService) GetGroupInfo(ctx context.Context, chatID, userID uuid.UUI
 			}
 			return nil, fmt.Errorf("find member user: %w", err)
 		}
+		vis, err := visibilityOf(ctx, s.privacy, userID, user)
+		if err != nil {
+			return nil, fmt.Errorf("check member privacy: %w", err)
+		}
 		memberInfos = append(memberInfos, &MemberInfo{
-			User:     *user,
+			User:     *vis.Apply(user),
 			Role:     string(m.Role),
-			IsOnline: s.hub.IsOnline(m.UserID),
+			IsOnline: vis.Online && s.hub.IsOnline(m.UserID),
 			JoinedAt: m.JoinedAt,
 		})
 	}
@@ -546,23 +732,54 @@ func (s *groupService) GetGroupInfo(ctx context.Context, chatID, userID uuid.UUI
 
 // --- Helper Methods ---
 
-// requireAdmin checks that the user is an admin of the chat.
-func (s *groupService) requireAdmin(ctx context.Context, chatID, userID uuid.UUID) error {
+// findMember returns the user's membership in the chat, or nil if the user
+// is not a member.
+func (s *groupService) findMember(ctx context.Context, chatID, userID uuid.UUID) (*model.ChatMember, error) {
 	members, err := s.chatRepo.GetMembers(ctx, chatID)
 	if err != nil {
-		return fmt.Errorf("get members: %w", err)
+		return nil, fmt.Errorf("get members: %w", err)
 	}
 
 	for _, m := range members {
 		if m.UserID == userID {
-			if m.Role != model.MemberRoleAdmin {
-				return apperror.Forbidden("only admins can perform this action")
-			}
-			return nil
+			return m, nil
 		}
 	}
+	return nil, nil
+}
 
-	return apperror.Forbidden("you are not a member of this group")
+// requireAdmin checks that the user is an admin or the owner of the chat.
+func (s *groupService) requireAdmin(ctx context.Context, chatID, userID uuid.UUID) error {
+	return s.requirePermission(ctx, chatID, userID, true)
+}
+
+// requirePermission checks that the user is a member of the chat, and an
+// admin when adminOnly is set.
+func (s *groupService) requirePermission(ctx context.Context, chatID, userID uuid.UUID, adminOnly bool) error {
+	m, err := s.findMember(ctx, chatID, userID)
+	if err != nil {
+		return err
+	}
+	if m == nil {
+		return apperror.Forbidden("you are not a member of this group")
+	}
+	if adminOnly && !m.Role.IsAdmin() {
+		return apperror.Forbidden("only admins can perform this action")
+	}
+	return nil
+}
+
+// requireNotBlocked rejects adding a user to a group when either the adder
+// or the user has blocked the other.
+func (s *groupService) requireNotBlocked(ctx context.Context, addedBy, userID uuid.UUID) error {
+	blocked, err := isBlocked(ctx, s.blockRepo, addedBy, userID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return apperror.Forbidden("cannot add this user to the group")
+	}
+	return nil
 }
 
 // sendSystemMessage creates a system-type message in the chat.
@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/pkg/apperror"
 )
 
 func TestGroupService_CreateGroup(t *testing.T) {
@@ -20,7 +21,7 @@ func TestGroupService_CreateGroup(t *testing.T) {
 	hub := newTestHub()
 	defer hub.Shutdown()
 
-	svc := NewGroupService(chatRepo, msgRepo, msgStatRepo, userRepo, hub, nil)
+	svc := NewGroupService(chatRepo, msgRepo, msgStatRepo, userRepo, nil, nil, hub, nil)
 
 	creator := uuid.New()
 	memberA := uuid.New()
@@ -106,7 +107,7 @@ func TestGroupService_UpdateGroup(t *testing.T) {
 	hub := newTestHub()
 	defer hub.Shutdown()
 
-	svc := NewGroupService(chatRepo, msgRepo, msgStatRepo, userRepo, hub, nil)
+	svc := NewGroupService(chatRepo, msgRepo, msgStatRepo, userRepo, nil, nil, hub, nil)
 
 	creator := uuid.New()
 	memberA := uuid.New()
@@ -149,7 +150,7 @@ func TestGroupService_AddMember(t *testing.T) {
 	hub := newTestHub()
 	defer hub.Shutdown()
 
-	svc := NewGroupService(chatRepo, msgRepo, msgStatRepo, userRepo, hub, nil)
+	svc := NewGroupService(chatRepo, msgRepo, msgStatRepo, userRepo, nil, nil, hub, nil)
 
 	creator := uuid.New()
 	memberA := uuid.New()
@@ -198,7 +199,7 @@ func TestGroupService_RemoveMember(t *testing.T) {
 	hub := newTestHub()
 	defer hub.Shutdown()
 
-	svc := NewGroupService(chatRepo, msgRepo, msgStatRepo, userRepo, hub, nil)
+	svc := NewGroupService(chatRepo, msgRepo, msgStatRepo, userRepo, nil, nil, hub, nil)
 
 	creator := uuid.New()
 	memberA := uuid.New()
@@ -243,7 +244,7 @@ func TestGroupService_PromoteToAdmin(t *testing.T) {
 	hub := newTestHub()
 	defer hub.Shutdown()
 
-	svc := NewGroupService(chatRepo, msgRepo, msgStatRepo, userRepo, hub, nil)
+	svc := NewGroupService(chatRepo, msgRepo, msgStatRepo, userRepo, nil, nil, hub, nil)
 
 	creator := uuid.New()
 	memberA := uuid.New()
@@ -292,7 +293,7 @@ func TestGroupService_LeaveGroup(t *testing.T) {
 	hub := newTestHub()
 	defer hub.Shutdown()
 
-	svc := NewGroupService(chatRepo, msgRepo, msgStatRepo, userRepo, hub, nil)
+	svc := NewGroupService(chatRepo, msgRepo, msgStatRepo, userRepo, nil, nil, hub, nil)
 
 	creator := uuid.New()
 	memberA := uuid.New()
@@ -319,7 +320,7 @@ func TestGroupService_LeaveGroup(t *testing.T) {
 	t.Run("creator cannot leave", func(t *testing.T) {
 		err := svc.LeaveGroup(context.Background(), group.ID, creator)
 		require.Error(t, err)
-		assert.Contains(t, err.Error(), "creator")
+		assert.Contains(t, err.Error(), "owner")
 	})
 
 	t.Run("non-member cannot leave", func(t *testing.T) {
@@ -337,7 +338,7 @@ func TestGroupService_DeleteGroup(t *testing.T) {
 	hub := newTestHub()
 	defer hub.Shutdown()
 
-	svc := NewGroupService(chatRepo, msgRepo, msgStatRepo, userRepo, hub, nil)
+	svc := NewGroupService(chatRepo, msgRepo, msgStatRepo, userRepo, nil, nil, hub, nil)
 
 	creator := uuid.New()
 	memberA := uuid.New()
@@ -356,7 +357,7 @@ func TestGroupService_DeleteGroup(t *testing.T) {
 	t.Run("non-creator cannot delete", func(t *testing.T) {
 		err := svc.DeleteGroup(context.Background(), group.ID, memberA)
 		require.Error(t, err)
-		assert.Contains(t, err.Error(), "creator")
+		assert.Contains(t, err.Error(), "owner")
 	})
 
 	t.Run("creator can delete", func(t *testing.T) {
@@ -377,7 +378,7 @@ func TestGroupService_GetGroupInfo(t *testing.T) {
 	hub := newTestHub()
 	defer hub.Shutdown()
 
-	svc := NewGroupService(chatRepo, msgRepo, msgStatRepo, userRepo, hub, nil)
+	svc := NewGroupService(chatRepo, msgRepo, msgStatRepo, userRepo, nil, nil, hub, nil)
 
 	creator := uuid.New()
 	memberA := uuid.New()
@@ -412,7 +413,7 @@ func TestGroupService_CreateGroup_Errors(t *testing.T) {
 	defer hub.Shutdown()
 
 	t.Run("name too long", func(t *testing.T) {
-		svc := NewGroupService(newMockChatRepo(), newMockMessageRepo(), newMockMessageStatRepo(), newMockUserRepo(), hub, nil)
+		svc := NewGroupService(newMockChatRepo(), newMockMessageRepo(), newMockMessageStatRepo(), newMockUserRepo(), nil, nil, hub, nil)
 		longName := ""
 		for i := 0; i < 101; i++ {
 			longName += "a"
@@ -426,7 +427,7 @@ func TestGroupService_CreateGroup_Errors(t *testing.T) {
 	t.Run("verify member generic error", func(t *testing.T) {
 		userRepo := newMockUserRepo()
 		userRepo.createErr = fmt.Errorf("db") // won't help - FindByID uses map
-		svc := NewGroupService(newMockChatRepo(), newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(newMockChatRepo(), newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		// memberIDs are unknown UUIDs → FindByID returns NotFound
 		_, err := svc.CreateGroup(context.Background(), uuid.New(), CreateGroupInput{
 			Name: "G", Icon: "x", MemberIDs: []uuid.UUID{uuid.New(), uuid.New()},
@@ -441,7 +442,7 @@ func TestGroupService_CreateGroup_Errors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: a, Phone: "+1", Name: "A"})
 		userRepo.addUser(&model.User{ID: b, Phone: "+2", Name: "B"})
 		chatRepo.createErr = fmt.Errorf("db error")
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		_, err := svc.CreateGroup(context.Background(), uuid.New(), CreateGroupInput{
 			Name: "G", Icon: "x", MemberIDs: []uuid.UUID{a, b},
 		})
@@ -456,7 +457,7 @@ func TestGroupService_CreateGroup_Errors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: a, Phone: "+1", Name: "A"})
 		userRepo.addUser(&model.User{ID: b, Phone: "+2", Name: "B"})
 		chatRepo.addMemberErr = fmt.Errorf("db error")
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		_, err := svc.CreateGroup(context.Background(), uuid.New(), CreateGroupInput{
 			Name: "G", Icon: "x", MemberIDs: []uuid.UUID{a, b},
 		})
@@ -478,7 +479,7 @@ func TestGroupService_UpdateGroup_Errors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: a, Phone: "+2", Name: "A"})
 		userRepo.addUser(&model.User{ID: b, Phone: "+3", Name: "B"})
 
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		group, err := svc.CreateGroup(context.Background(), creator, CreateGroupInput{
 			Name: "G", Icon: "x", MemberIDs: []uuid.UUID{a, b},
 		})
@@ -502,7 +503,7 @@ func TestGroupService_UpdateGroup_Errors(t *testing.T) {
 		chatRepo.chats[personalChat.ID] = personalChat
 		_ = chatRepo.AddMember(context.Background(), personalChat.ID, creator, model.MemberRoleAdmin)
 
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		name := "New"
 		_, err := svc.UpdateGroup(context.Background(), personalChat.ID, creator, UpdateGroupInput{Name: &name})
 		require.Error(t, err)
@@ -510,6 +511,53 @@ func TestGroupService_UpdateGroup_Errors(t *testing.T) {
 	})
 }
 
+func TestGroupService_Blocked(t *testing.T) {
+	ctx := context.Background()
+	chatRepo := newMockChatRepo()
+	userRepo := newMockUserRepo()
+	blockRepo := newMockUserBlockRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, blockRepo, nil, hub, nil)
+
+	creator := uuid.New()
+	memberA := uuid.New()
+	memberB := uuid.New()
+	blocker := uuid.New()
+	userRepo.addUser(&model.User{ID: creator, Phone: "+628111", Name: "Creator"})
+	userRepo.addUser(&model.User{ID: memberA, Phone: "+628222", Name: "MemberA"})
+	userRepo.addUser(&model.User{ID: memberB, Phone: "+628333", Name: "MemberB"})
+	userRepo.addUser(&model.User{ID: blocker, Phone: "+628444", Name: "Blocker"})
+	_ = blockRepo.Block(ctx, blocker, creator)
+
+	t.Run("create group with blocked member", func(t *testing.T) {
+		_, err := svc.CreateGroup(ctx, creator, CreateGroupInput{
+			Name:      "Test Group",
+			Icon:      "💼",
+			MemberIDs: []uuid.UUID{memberA, blocker},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot add this user")
+	})
+
+	t.Run("add blocked member", func(t *testing.T) {
+		group, err := svc.CreateGroup(ctx, creator, CreateGroupInput{
+			Name:      "Test Group",
+			Icon:      "💼",
+			MemberIDs: []uuid.UUID{memberA, memberB},
+		})
+		require.NoError(t, err)
+
+		err = svc.AddMember(ctx, group.ID, blocker, creator)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot add this user")
+
+		members, _ := chatRepo.GetMembers(ctx, group.ID)
+		assert.Len(t, members, 3)
+	})
+}
+
 func TestGroupService_AddMember_Errors(t *testing.T) {
 	hub := newTestHub()
 	defer hub.Shutdown()
@@ -523,7 +571,7 @@ func TestGroupService_AddMember_Errors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: a, Phone: "+2", Name: "A"})
 		userRepo.addUser(&model.User{ID: b, Phone: "+3", Name: "B"})
 
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		group, err := svc.CreateGroup(context.Background(), creator, CreateGroupInput{
 			Name: "G", Icon: "x", MemberIDs: []uuid.UUID{a, b},
 		})
@@ -547,7 +595,7 @@ func TestGroupService_AddMember_Errors(t *testing.T) {
 		chatRepo.chats[personalChat.ID] = personalChat
 		_ = chatRepo.AddMember(context.Background(), personalChat.ID, creator, model.MemberRoleAdmin)
 
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		err := svc.AddMember(context.Background(), personalChat.ID, uuid.New(), creator)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "group chats")
@@ -562,7 +610,7 @@ func TestGroupService_AddMember_Errors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: a, Phone: "+2", Name: "A"})
 		userRepo.addUser(&model.User{ID: b, Phone: "+3", Name: "B"})
 
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		group, err := svc.CreateGroup(context.Background(), creator, CreateGroupInput{
 			Name: "G", Icon: "x", MemberIDs: []uuid.UUID{a, b},
 		})
@@ -586,7 +634,7 @@ func TestGroupService_RemoveMember_Errors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: a, Phone: "+2", Name: "A"})
 		userRepo.addUser(&model.User{ID: b, Phone: "+3", Name: "B"})
 
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		group, err := svc.CreateGroup(context.Background(), creator, CreateGroupInput{
 			Name: "G", Icon: "x", MemberIDs: []uuid.UUID{a, b},
 		})
@@ -606,7 +654,7 @@ func TestGroupService_RemoveMember_Errors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: a, Phone: "+2", Name: "A"})
 		userRepo.addUser(&model.User{ID: b, Phone: "+3", Name: "B"})
 
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		group, err := svc.CreateGroup(context.Background(), creator, CreateGroupInput{
 			Name: "G", Icon: "x", MemberIDs: []uuid.UUID{a, b},
 		})
@@ -626,7 +674,7 @@ func TestGroupService_LeaveGroup_Errors(t *testing.T) {
 	t.Run("get members error", func(t *testing.T) {
 		chatRepo := newMockChatRepo()
 		chatRepo.getMembersErr = fmt.Errorf("db error")
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), newMockUserRepo(), hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), newMockUserRepo(), nil, nil, hub, nil)
 		err := svc.LeaveGroup(context.Background(), uuid.New(), uuid.New())
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "get members")
@@ -640,7 +688,7 @@ func TestGroupService_DeleteGroup_Errors(t *testing.T) {
 	t.Run("find chat error", func(t *testing.T) {
 		chatRepo := newMockChatRepo()
 		chatRepo.findErr = fmt.Errorf("db error")
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), newMockUserRepo(), hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), newMockUserRepo(), nil, nil, hub, nil)
 		err := svc.DeleteGroup(context.Background(), uuid.New(), uuid.New())
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "find chat")
@@ -655,7 +703,7 @@ func TestGroupService_DeleteGroup_Errors(t *testing.T) {
 		personalChat := &model.Chat{ID: uuid.New(), Type: model.ChatTypePersonal, CreatedBy: creator}
 		chatRepo.chats[personalChat.ID] = personalChat
 
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		err := svc.DeleteGroup(context.Background(), personalChat.ID, creator)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "group chats")
@@ -669,7 +717,7 @@ func TestGroupService_GetGroupInfo_Errors(t *testing.T) {
 	t.Run("get members error", func(t *testing.T) {
 		chatRepo := newMockChatRepo()
 		chatRepo.getMembersErr = fmt.Errorf("db error")
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), newMockUserRepo(), hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), newMockUserRepo(), nil, nil, hub, nil)
 		_, err := svc.GetGroupInfo(context.Background(), uuid.New(), uuid.New())
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "get members")
@@ -684,7 +732,7 @@ func TestGroupService_GetGroupInfo_Errors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: a, Phone: "+2", Name: "A"})
 		userRepo.addUser(&model.User{ID: b, Phone: "+3", Name: "B"})
 
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		group, err := svc.CreateGroup(context.Background(), creator, CreateGroupInput{
 			Name: "G", Icon: "x", MemberIDs: []uuid.UUID{a, b},
 		})
@@ -705,7 +753,7 @@ func TestGroupService_GetGroupInfo_Errors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: a, Phone: "+2", Name: "A"})
 		userRepo.addUser(&model.User{ID: b, Phone: "+3", Name: "B"})
 
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		group, err := svc.CreateGroup(context.Background(), creator, CreateGroupInput{
 			Name: "G", Icon: "x", MemberIDs: []uuid.UUID{a, b},
 		})
@@ -728,7 +776,7 @@ func TestGroupService_GetGroupInfo_Errors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: a, Phone: "+2", Name: "A"})
 		userRepo.addUser(&model.User{ID: b, Phone: "+3", Name: "B"})
 
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		group, err := svc.CreateGroup(context.Background(), creator, CreateGroupInput{
 			Name: "G", Icon: "x", MemberIDs: []uuid.UUID{a, b},
 		})
@@ -758,7 +806,7 @@ func TestGroupService_RemoveMember_MoreErrors(t *testing.T) {
 		_ = chatRepo.AddMember(context.Background(), personalChat.ID, creator, model.MemberRoleAdmin)
 		_ = chatRepo.AddMember(context.Background(), personalChat.ID, target, model.MemberRoleMember)
 
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		err := svc.RemoveMember(context.Background(), personalChat.ID, target, creator)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "group chats")
@@ -774,7 +822,7 @@ func TestGroupService_RemoveMember_MoreErrors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: admin, Phone: "+2", Name: "Admin"})
 		userRepo.addUser(&model.User{ID: a, Phone: "+3", Name: "A"})
 
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		group, err := svc.CreateGroup(context.Background(), creator, CreateGroupInput{
 			Name: "G", Icon: "x", MemberIDs: []uuid.UUID{admin, a},
 		})
@@ -787,7 +835,7 @@ func TestGroupService_RemoveMember_MoreErrors(t *testing.T) {
 		// Try to remove creator by admin
 		err = svc.RemoveMember(context.Background(), group.ID, creator, admin)
 		require.Error(t, err)
-		assert.Contains(t, err.Error(), "creator")
+		assert.Contains(t, err.Error(), "owner")
 	})
 
 	t.Run("user find generic error", func(t *testing.T) {
@@ -799,7 +847,7 @@ func TestGroupService_RemoveMember_MoreErrors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: a, Phone: "+2", Name: "A"})
 		userRepo.addUser(&model.User{ID: b, Phone: "+3", Name: "B"})
 
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		group, err := svc.CreateGroup(context.Background(), creator, CreateGroupInput{
 			Name: "G", Icon: "x", MemberIDs: []uuid.UUID{a, b},
 		})
@@ -820,7 +868,7 @@ func TestGroupService_RemoveMember_MoreErrors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: a, Phone: "+2", Name: "A"})
 		userRepo.addUser(&model.User{ID: b, Phone: "+3", Name: "B"})
 
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		group, err := svc.CreateGroup(context.Background(), creator, CreateGroupInput{
 			Name: "G", Icon: "x", MemberIDs: []uuid.UUID{a, b},
 		})
@@ -846,7 +894,7 @@ func TestGroupService_AddMember_MoreErrors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: a, Phone: "+2", Name: "A"})
 		userRepo.addUser(&model.User{ID: b, Phone: "+3", Name: "B"})
 
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		group, err := svc.CreateGroup(context.Background(), creator, CreateGroupInput{
 			Name: "G", Icon: "x", MemberIDs: []uuid.UUID{a, b},
 		})
@@ -869,7 +917,7 @@ func TestGroupService_AddMember_MoreErrors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: b, Phone: "+3", Name: "B"})
 		userRepo.addUser(&model.User{ID: newMember, Phone: "+4", Name: "New"})
 
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		group, err := svc.CreateGroup(context.Background(), creator, CreateGroupInput{
 			Name: "G", Icon: "x", MemberIDs: []uuid.UUID{a, b},
 		})
@@ -895,7 +943,7 @@ func TestGroupService_AddMember_MoreErrors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: newMember, Phone: "+4", Name: "New"})
 
 		notif := &mockNotifSvc{}
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, notif)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, notif)
 		group, err := svc.CreateGroup(context.Background(), creator, CreateGroupInput{
 			Name: "TestGroup", Icon: "x", MemberIDs: []uuid.UUID{a, b},
 		})
@@ -914,7 +962,7 @@ func TestGroupService_AddMember_MoreErrors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: a, Phone: "+2", Name: "A"})
 		userRepo.addUser(&model.User{ID: b, Phone: "+3", Name: "B"})
 
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		group, err := svc.CreateGroup(context.Background(), creator, CreateGroupInput{
 			Name: "G", Icon: "x", MemberIDs: []uuid.UUID{a, b},
 		})
@@ -936,7 +984,7 @@ func TestGroupService_PromoteToAdmin_MoreErrors(t *testing.T) {
 	t.Run("get members error", func(t *testing.T) {
 		chatRepo := newMockChatRepo()
 		chatRepo.getMembersErr = fmt.Errorf("db error")
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), newMockUserRepo(), hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), newMockUserRepo(), nil, nil, hub, nil)
 		err := svc.PromoteToAdmin(context.Background(), uuid.New(), uuid.New(), uuid.New())
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "get members")
@@ -951,7 +999,7 @@ func TestGroupService_PromoteToAdmin_MoreErrors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: a, Phone: "+2", Name: "A"})
 		userRepo.addUser(&model.User{ID: b, Phone: "+3", Name: "B"})
 
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		group, err := svc.CreateGroup(context.Background(), creator, CreateGroupInput{
 			Name: "G", Icon: "x", MemberIDs: []uuid.UUID{a, b},
 		})
@@ -970,7 +1018,7 @@ func TestGroupService_PromoteToAdmin_MoreErrors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: a, Phone: "+2", Name: "A"})
 		userRepo.addUser(&model.User{ID: b, Phone: "+3", Name: "B"})
 
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		group, err := svc.CreateGroup(context.Background(), creator, CreateGroupInput{
 			Name: "G", Icon: "x", MemberIDs: []uuid.UUID{a, b},
 		})
@@ -985,7 +1033,7 @@ func TestGroupService_PromoteToAdmin_MoreErrors(t *testing.T) {
 		assert.Contains(t, err.Error(), "already an admin")
 	})
 
-	t.Run("remove member for promotion error", func(t *testing.T) {
+	t.Run("update role error", func(t *testing.T) {
 		chatRepo := newMockChatRepo()
 		userRepo := newMockUserRepo()
 		creator := uuid.New()
@@ -994,16 +1042,16 @@ func TestGroupService_PromoteToAdmin_MoreErrors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: a, Phone: "+2", Name: "A"})
 		userRepo.addUser(&model.User{ID: b, Phone: "+3", Name: "B"})
 
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		group, err := svc.CreateGroup(context.Background(), creator, CreateGroupInput{
 			Name: "G", Icon: "x", MemberIDs: []uuid.UUID{a, b},
 		})
 		require.NoError(t, err)
 
-		chatRepo.removeMemberErr = fmt.Errorf("db error")
+		chatRepo.updateRoleErr = fmt.Errorf("db error")
 		err = svc.PromoteToAdmin(context.Background(), group.ID, a, creator)
 		require.Error(t, err)
-		assert.Contains(t, err.Error(), "remove member for promotion")
+		assert.Contains(t, err.Error(), "promote member")
 	})
 }
 
@@ -1020,7 +1068,7 @@ func TestGroupService_LeaveGroup_MoreErrors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: a, Phone: "+2", Name: "A"})
 		userRepo.addUser(&model.User{ID: b, Phone: "+3", Name: "B"})
 
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		group, err := svc.CreateGroup(context.Background(), creator, CreateGroupInput{
 			Name: "G", Icon: "x", MemberIDs: []uuid.UUID{a, b},
 		})
@@ -1039,7 +1087,7 @@ func TestGroupService_LeaveGroup_MoreErrors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: a, Phone: "+2", Name: "A"})
 		userRepo.addUser(&model.User{ID: b, Phone: "+3", Name: "B"})
 
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		group, err := svc.CreateGroup(context.Background(), creator, CreateGroupInput{
 			Name: "G", Icon: "x", MemberIDs: []uuid.UUID{a, b},
 		})
@@ -1047,7 +1095,7 @@ func TestGroupService_LeaveGroup_MoreErrors(t *testing.T) {
 
 		err = svc.LeaveGroup(context.Background(), group.ID, creator)
 		require.Error(t, err)
-		assert.Contains(t, err.Error(), "creator")
+		assert.Contains(t, err.Error(), "owner")
 	})
 
 	t.Run("leave personal chat", func(t *testing.T) {
@@ -1063,7 +1111,7 @@ func TestGroupService_LeaveGroup_MoreErrors(t *testing.T) {
 		_ = chatRepo.AddMember(context.Background(), personalChat.ID, creator, model.MemberRoleAdmin)
 		_ = chatRepo.AddMember(context.Background(), personalChat.ID, other, model.MemberRoleMember)
 
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		err := svc.LeaveGroup(context.Background(), personalChat.ID, other)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "group chats")
@@ -1078,7 +1126,7 @@ func TestGroupService_LeaveGroup_MoreErrors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: a, Phone: "+2", Name: "A"})
 		userRepo.addUser(&model.User{ID: b, Phone: "+3", Name: "B"})
 
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		group, err := svc.CreateGroup(context.Background(), creator, CreateGroupInput{
 			Name: "G", Icon: "x", MemberIDs: []uuid.UUID{a, b},
 		})
@@ -1099,7 +1147,7 @@ func TestGroupService_LeaveGroup_MoreErrors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: a, Phone: "+2", Name: "A"})
 		userRepo.addUser(&model.User{ID: b, Phone: "+3", Name: "B"})
 
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		group, err := svc.CreateGroup(context.Background(), creator, CreateGroupInput{
 			Name: "G", Icon: "x", MemberIDs: []uuid.UUID{a, b},
 		})
@@ -1125,7 +1173,7 @@ func TestGroupService_DeleteGroup_MoreErrors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: a, Phone: "+2", Name: "A"})
 		userRepo.addUser(&model.User{ID: b, Phone: "+3", Name: "B"})
 
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		group, err := svc.CreateGroup(context.Background(), creator, CreateGroupInput{
 			Name: "G", Icon: "x", MemberIDs: []uuid.UUID{a, b},
 		})
@@ -1133,7 +1181,7 @@ func TestGroupService_DeleteGroup_MoreErrors(t *testing.T) {
 
 		err = svc.DeleteGroup(context.Background(), group.ID, a)
 		require.Error(t, err)
-		assert.Contains(t, err.Error(), "creator")
+		assert.Contains(t, err.Error(), "owner")
 	})
 
 	t.Run("delete repo error", func(t *testing.T) {
@@ -1145,7 +1193,7 @@ func TestGroupService_DeleteGroup_MoreErrors(t *testing.T) {
 		userRepo.addUser(&model.User{ID: a, Phone: "+2", Name: "A"})
 		userRepo.addUser(&model.User{ID: b, Phone: "+3", Name: "B"})
 
-		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, hub, nil)
+		svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
 		group, err := svc.CreateGroup(context.Background(), creator, CreateGroupInput{
 			Name: "G", Icon: "x", MemberIDs: []uuid.UUID{a, b},
 		})
@@ -1157,3 +1205,210 @@ func TestGroupService_DeleteGroup_MoreErrors(t *testing.T) {
 		assert.Contains(t, err.Error(), "delete group")
 	})
 }
+
+func TestGroupService_DemoteAdmin(t *testing.T) {
+	ctx := context.Background()
+	chatRepo := newMockChatRepo()
+	userRepo := newMockUserRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
+
+	owner, adminA, adminB, member := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	userRepo.addUser(&model.User{ID: owner, Phone: "+1", Name: "Owner"})
+	userRepo.addUser(&model.User{ID: adminA, Phone: "+2", Name: "AdminA"})
+	userRepo.addUser(&model.User{ID: adminB, Phone: "+3", Name: "AdminB"})
+	userRepo.addUser(&model.User{ID: member, Phone: "+4", Name: "Member"})
+
+	group, err := svc.CreateGroup(ctx, owner, CreateGroupInput{
+		Name: "G", Icon: "x", MemberIDs: []uuid.UUID{adminA, adminB, member},
+	})
+	require.NoError(t, err)
+	require.NoError(t, svc.PromoteToAdmin(ctx, group.ID, adminA, owner))
+	require.NoError(t, svc.PromoteToAdmin(ctx, group.ID, adminB, owner))
+
+	roleOf := func(userID uuid.UUID) model.MemberRole {
+		members, _ := chatRepo.GetMembers(ctx, group.ID)
+		for _, m := range members {
+			if m.UserID == userID {
+				return m.Role
+			}
+		}
+		return ""
+	}
+
+	t.Run("admin cannot demote owner", func(t *testing.T) {
+		err := svc.DemoteAdmin(ctx, group.ID, owner, adminA)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "owner")
+		assert.Equal(t, model.MemberRoleOwner, roleOf(owner))
+	})
+
+	t.Run("admin cannot remove owner", func(t *testing.T) {
+		err := svc.RemoveMember(ctx, group.ID, owner, adminA)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "owner")
+	})
+
+	t.Run("non-admin cannot demote", func(t *testing.T) {
+		err := svc.DemoteAdmin(ctx, group.ID, adminA, member)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "admin")
+	})
+
+	t.Run("admin demotes admin", func(t *testing.T) {
+		require.NoError(t, svc.DemoteAdmin(ctx, group.ID, adminB, adminA))
+		assert.Equal(t, model.MemberRoleMember, roleOf(adminB))
+	})
+
+	t.Run("target is not an admin", func(t *testing.T) {
+		err := svc.DemoteAdmin(ctx, group.ID, member, owner)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not an admin")
+	})
+
+	t.Run("target not a member", func(t *testing.T) {
+		err := svc.DemoteAdmin(ctx, group.ID, uuid.New(), owner)
+		assert.True(t, apperror.IsNotFound(err))
+	})
+
+	t.Run("admin steps down", func(t *testing.T) {
+		require.NoError(t, svc.DemoteAdmin(ctx, group.ID, adminA, adminA))
+		assert.Equal(t, model.MemberRoleMember, roleOf(adminA))
+	})
+}
+
+func TestGroupService_TransferOwnership(t *testing.T) {
+	ctx := context.Background()
+	chatRepo := newMockChatRepo()
+	userRepo := newMockUserRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	svc := NewGroupService(chatRepo, newMockMessageRepo(), newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
+
+	owner, a, b := uuid.New(), uuid.New(), uuid.New()
+	userRepo.addUser(&model.User{ID: owner, Phone: "+1", Name: "Owner"})
+	userRepo.addUser(&model.User{ID: a, Phone: "+2", Name: "A"})
+	userRepo.addUser(&model.User{ID: b, Phone: "+3", Name: "B"})
+
+	group, err := svc.CreateGroup(ctx, owner, CreateGroupInput{
+		Name: "G", Icon: "x", MemberIDs: []uuid.UUID{a, b},
+	})
+	require.NoError(t, err)
+	require.NoError(t, svc.PromoteToAdmin(ctx, group.ID, a, owner))
+
+	t.Run("admin cannot transfer", func(t *testing.T) {
+		err := svc.TransferOwnership(ctx, group.ID, b, a)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only the group owner")
+	})
+
+	t.Run("target not a member", func(t *testing.T) {
+		err := svc.TransferOwnership(ctx, group.ID, uuid.New(), owner)
+		assert.True(t, apperror.IsNotFound(err))
+	})
+
+	t.Run("transfer to self", func(t *testing.T) {
+		err := svc.TransferOwnership(ctx, group.ID, owner, owner)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already own")
+	})
+
+	t.Run("owner transfers to member", func(t *testing.T) {
+		require.NoError(t, svc.TransferOwnership(ctx, group.ID, b, owner))
+
+		info, err := svc.GetGroupInfo(ctx, group.ID, owner)
+		require.NoError(t, err)
+		roles := make(map[uuid.UUID]string)
+		for _, m := range info.Members {
+			roles[m.User.ID] = m.Role
+		}
+		assert.Equal(t, string(model.MemberRoleAdmin), roles[owner])
+		assert.Equal(t, string(model.MemberRoleOwner), roles[b])
+	})
+
+	t.Run("previous owner can leave and new owner can delete", func(t *testing.T) {
+		require.NoError(t, svc.LeaveGroup(ctx, group.ID, owner))
+
+		err := svc.DeleteGroup(ctx, group.ID, a)
+		require.Error(t, err)
+		require.NoError(t, svc.DeleteGroup(ctx, group.ID, b))
+	})
+
+	t.Run("personal chat", func(t *testing.T) {
+		personalChat := &model.Chat{ID: uuid.New(), Type: model.ChatTypePersonal, CreatedBy: owner}
+		chatRepo.chats[personalChat.ID] = personalChat
+		err := svc.TransferOwnership(ctx, personalChat.ID, a, owner)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "group chats")
+	})
+}
+
+func TestGroupService_Permissions(t *testing.T) {
+	ctx := context.Background()
+	chatRepo := newMockChatRepo()
+	msgRepo := newMockMessageRepo()
+	userRepo := newMockUserRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	svc := NewGroupService(chatRepo, msgRepo, newMockMessageStatRepo(), userRepo, nil, nil, hub, nil)
+
+	owner, a, b, newcomer := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	userRepo.addUser(&model.User{ID: owner, Phone: "+1", Name: "Owner"})
+	userRepo.addUser(&model.User{ID: a, Phone: "+2", Name: "A"})
+	userRepo.addUser(&model.User{ID: b, Phone: "+3", Name: "B"})
+	userRepo.addUser(&model.User{ID: newcomer, Phone: "+4", Name: "New"})
+
+	group, err := svc.CreateGroup(ctx, owner, CreateGroupInput{
+		Name: "G", Icon: "x", MemberIDs: []uuid.UUID{a, b},
+	})
+	require.NoError(t, err)
+
+	name := "Renamed"
+	yes, no := true, false
+
+	t.Run("defaults restrict editing and adding", func(t *testing.T) {
+		_, err := svc.UpdateGroup(ctx, group.ID, a, UpdateGroupInput{Name: &name})
+		assert.True(t, apperror.IsForbidden(err))
+
+		err = svc.AddMember(ctx, group.ID, newcomer, a)
+		assert.True(t, apperror.IsForbidden(err))
+	})
+
+	t.Run("non-admin cannot change permissions", func(t *testing.T) {
+		_, err := svc.UpdatePermissions(ctx, group.ID, a, model.UpdateGroupPermissionsInput{OnlyAdminsEditInfo: &no})
+		assert.True(t, apperror.IsForbidden(err))
+	})
+
+	t.Run("owner opens editing and adding to members", func(t *testing.T) {
+		before := len(msgRepo.byChat[group.ID])
+		updated, err := svc.UpdatePermissions(ctx, group.ID, owner, model.UpdateGroupPermissionsInput{
+			OnlyAdminsEditInfo:   &no,
+			OnlyAdminsAddMembers: &no,
+			OnlyAdminsSend:       &yes,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, model.GroupPermissions{OnlyAdminsSend: true}, updated.Permissions)
+		assert.Len(t, msgRepo.byChat[group.ID], before+1)
+
+		_, err = svc.UpdateGroup(ctx, group.ID, a, UpdateGroupInput{Name: &name})
+		require.NoError(t, err)
+		require.NoError(t, svc.AddMember(ctx, group.ID, newcomer, a))
+	})
+
+	t.Run("unchanged permissions send no system message", func(t *testing.T) {
+		before := len(msgRepo.byChat[group.ID])
+		_, err := svc.UpdatePermissions(ctx, group.ID, owner, model.UpdateGroupPermissionsInput{OnlyAdminsSend: &yes})
+		require.NoError(t, err)
+		assert.Len(t, msgRepo.byChat[group.ID], before)
+	})
+
+	t.Run("non-member still rejected", func(t *testing.T) {
+		_, err := svc.UpdateGroup(ctx, group.ID, uuid.New(), UpdateGroupInput{Name: &name})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not a member")
+	})
+}
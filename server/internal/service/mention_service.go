@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/internal/repository"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+// mentionPattern matches a mention token as inserted by the clients:
+// @[Display Name](user-id). The display name is only used for rendering.
+var mentionPattern = regexp.MustCompile(`@\[([^\[\]\n]{1,100})\]\(([0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})\)`)
+
+// MentionPage represents a paginated list of mentions.
+type MentionPage struct {
+	Mentions []*model.Mention `json:"mentions"`
+	Cursor   string           `json:"cursor"`
+	HasMore  bool             `json:"hasMore"`
+}
+
+// MentionService records @mentions and notifies the mentioned users.
+type MentionService interface {
+	// Record stores the users mentioned by a message or block, replacing the
+	// previous set, and notifies the ones that were not mentioned before.
+	Record(ctx context.Context, target model.MentionTarget, userIDs []uuid.UUID) error
+	Clear(ctx context.Context, itemID uuid.UUID) error
+	ForItems(ctx context.Context, itemIDs []uuid.UUID) (map[uuid.UUID][]uuid.UUID, error)
+	List(ctx context.Context, userID uuid.UUID, cursor string, limit int) (*MentionPage, error)
+}
+
+type mentionService struct {
+	mentionRepo repository.MentionRepository
+	userRepo    repository.UserRepository
+	notifSvc    NotificationService
+}
+
+// NewMentionService creates a new MentionService.
+func NewMentionService(
+	mentionRepo repository.MentionRepository,
+	userRepo repository.UserRepository,
+	notifSvc NotificationService,
+) MentionService {
+	return &mentionService{
+		mentionRepo: mentionRepo,
+		userRepo:    userRepo,
+		notifSvc:    notifSvc,
+	}
+}
+
+func (s *mentionService) Record(ctx context.Context, target model.MentionTarget, userIDs []uuid.UUID) error {
+	target.Preview = truncate(renderMentions(target.Preview), 100)
+
+	added, err := s.mentionRepo.Sync(ctx, target, userIDs)
+	if err != nil {
+		return fmt.Errorf("sync mentions: %w", err)
+	}
+
+	// Mentions bypass the chat's notification settings (fire-and-forget)
+	if s.notifSvc != nil && len(added) > 0 {
+		go func() {
+			senderName := "Seseorang"
+			if sender, err := s.userRepo.FindByID(context.Background(), target.MentionedBy); err == nil && sender.Name != "" {
+				senderName = sender.Name
+			}
+			notif := BuildMentionNotif(senderName, target)
+			_ = s.notifSvc.SendToUsers(context.Background(), added, notif)
+		}()
+	}
+
+	return nil
+}
+
+func (s *mentionService) Clear(ctx context.Context, itemID uuid.UUID) error {
+	if err := s.mentionRepo.DeleteByItem(ctx, itemID); err != nil {
+		return fmt.Errorf("clear mentions: %w", err)
+	}
+	return nil
+}
+
+func (s *mentionService) ForItems(ctx context.Context, itemIDs []uuid.UUID) (map[uuid.UUID][]uuid.UUID, error) {
+	mentions, err := s.mentionRepo.ListByItems(ctx, itemIDs)
+	if err != nil {
+		return nil, fmt.Errorf("list mentions: %w", err)
+	}
+	return mentions, nil
+}
+
+func (s *mentionService) List(ctx context.Context, userID uuid.UUID, cursor string, limit int) (*MentionPage, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	var cursorTime *time.Time
+	if cursor != "" {
+		t, err := time.Parse(time.RFC3339Nano, cursor)
+		if err != nil {
+			return nil, apperror.BadRequest("invalid cursor format")
+		}
+		cursorTime = &t
+	}
+
+	// Fetch limit+1 to check if there are more
+	mentions, err := s.mentionRepo.ListByUser(ctx, userID, cursorTime, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("list mentions: %w", err)
+	}
+
+	hasMore := len(mentions) > limit
+	if hasMore {
+		mentions = mentions[:limit]
+	}
+
+	var nextCursor string
+	if hasMore && len(mentions) > 0 {
+		nextCursor = mentions[len(mentions)-1].CreatedAt.Format(time.RFC3339Nano)
+	}
+
+	return &MentionPage{
+		Mentions: mentions,
+		Cursor:   nextCursor,
+		HasMore:  hasMore,
+	}, nil
+}
+
+// parseMentions returns the distinct users mentioned in content, in order of appearance.
+func parseMentions(content string) []uuid.UUID {
+	var ids []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		id, err := uuid.Parse(match[2])
+		if err != nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// renderMentions replaces mention tokens with their plain "@Name" form.
+func renderMentions(content string) string {
+	return mentionPattern.ReplaceAllString(content, "@$1")
+}
+
+// resolveMentions parses the mentions in content and checks that every
+// mentioned user is allowed in the context. The author is never included.
+func resolveMentions(content string, authorID uuid.UUID, allowed map[uuid.UUID]bool) ([]uuid.UUID, error) {
+	var mentioned []uuid.UUID
+	for _, id := range parseMentions(content) {
+		if id == authorID {
+			continue
+		}
+		if !allowed[id] {
+			return nil, apperror.Validation("content", fmt.Sprintf("mentioned user %s is not a member", id))
+		}
+		mentioned = append(mentioned, id)
+	}
+	return mentioned, nil
+}
+
+// recordMentions stores mentions without failing the caller: the message or
+// block they belong to has already been saved.
+func recordMentions(ctx context.Context, svc MentionService, target model.MentionTarget, userIDs []uuid.UUID) {
+	if svc == nil {
+		return
+	}
+	if err := svc.Record(ctx, target, userIDs); err != nil {
+		log.Warn().Err(err).Str("item_id", target.ItemID.String()).Msg("failed to record mentions")
+	}
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+// -- Mock mention repo --
+
+type mockMentionRepo struct {
+	mentions []*model.Mention
+	mu       sync.Mutex
+}
+
+func newMockMentionRepo() *mockMentionRepo {
+	return &mockMentionRepo{}
+}
+
+func (m *mockMentionRepo) Sync(_ context.Context, target model.MentionTarget, userIDs []uuid.UUID) ([]uuid.UUID, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	wanted := make(map[uuid.UUID]bool, len(userIDs))
+	for _, id := range userIDs {
+		wanted[id] = true
+	}
+
+	existing := make(map[uuid.UUID]bool)
+	kept := make([]*model.Mention, 0, len(m.mentions))
+	for _, mention := range m.mentions {
+		if mention.ItemID == target.ItemID {
+			if !wanted[mention.UserID] {
+				continue
+			}
+			existing[mention.UserID] = true
+			mention.Preview = target.Preview
+		}
+		kept = append(kept, mention)
+	}
+	m.mentions = kept
+
+	added := make([]uuid.UUID, 0)
+	for _, id := range userIDs {
+		if existing[id] {
+			continue
+		}
+		m.mentions = append(m.mentions, &model.Mention{
+			ID:          uuid.New(),
+			UserID:      id,
+			MentionedBy: target.MentionedBy,
+			SourceType:  target.SourceType,
+			SourceID:    target.SourceID,
+			ItemID:      target.ItemID,
+			Preview:     target.Preview,
+			CreatedAt:   time.Now(),
+		})
+		added = append(added, id)
+	}
+	return added, nil
+}
+
+func (m *mockMentionRepo) DeleteByItem(_ context.Context, itemID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	kept := make([]*model.Mention, 0, len(m.mentions))
+	for _, mention := range m.mentions {
+		if mention.ItemID != itemID {
+			kept = append(kept, mention)
+		}
+	}
+	m.mentions = kept
+	return nil
+}
+
+func (m *mockMentionRepo) ListByItems(_ context.Context, itemIDs []uuid.UUID) (map[uuid.UUID][]uuid.UUID, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	wanted := make(map[uuid.UUID]bool, len(itemIDs))
+	for _, id := range itemIDs {
+		wanted[id] = true
+	}
+	result := make(map[uuid.UUID][]uuid.UUID)
+	for _, mention := range m.mentions {
+		if wanted[mention.ItemID] {
+			result[mention.ItemID] = append(result[mention.ItemID], mention.UserID)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockMentionRepo) ListByUser(_ context.Context, userID uuid.UUID, cursor *time.Time, limit int) ([]*model.Mention, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*model.Mention
+	for _, mention := range m.mentions {
+		if mention.UserID != userID {
+			continue
+		}
+		if cursor != nil && !mention.CreatedAt.Before(*cursor) {
+			continue
+		}
+		result = append(result, mention)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// -- Recording notification service --
+
+type sentNotif struct {
+	UserIDs []uuid.UUID
+	Exclude []uuid.UUID
+	Notif   model.Notification
+}
+
+type recordingNotifSvc struct {
+	mockNotifSvc
+	toUsers []sentNotif
+	toChat  []sentNotif
+	mu      sync.Mutex
+}
+
+func (m *recordingNotifSvc) SendToUsers(_ context.Context, userIDs []uuid.UUID, notif model.Notification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.toUsers = append(m.toUsers, sentNotif{UserIDs: userIDs, Notif: notif})
+	return nil
+}
+
+func (m *recordingNotifSvc) SendToChat(_ context.Context, _ uuid.UUID, exclude []uuid.UUID, notif model.Notification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.toChat = append(m.toChat, sentNotif{Exclude: exclude, Notif: notif})
+	return nil
+}
+
+func (m *recordingNotifSvc) sentToUsers() []sentNotif {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]sentNotif(nil), m.toUsers...)
+}
+
+func (m *recordingNotifSvc) sentToChat() []sentNotif {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]sentNotif(nil), m.toChat...)
+}
+
+// mentionToken builds a mention as the clients insert it.
+func mentionToken(name string, id uuid.UUID) string {
+	return "@[" + name + "](" + id.String() + ")"
+}
+
+func TestParseMentions(t *testing.T) {
+	andi := uuid.New()
+	budi := uuid.New()
+
+	t.Run("distinct in order", func(t *testing.T) {
+		content := "hi " + mentionToken("Budi", budi) + " and " + mentionToken("Andi", andi) + " " + mentionToken("Budi", budi)
+		assert.Equal(t, []uuid.UUID{budi, andi}, parseMentions(content))
+	})
+
+	t.Run("ignores plain at-signs and malformed tokens", func(t *testing.T) {
+		assert.Empty(t, parseMentions("email me at budi@example.com or @Budi"))
+		assert.Empty(t, parseMentions("@[Budi](not-a-uuid)"))
+	})
+
+	t.Run("renders display names", func(t *testing.T) {
+		content := "tolong cek " + mentionToken("Andi Wijaya", andi)
+		assert.Equal(t, "tolong cek @Andi Wijaya", renderMentions(content))
+	})
+}
+
+func TestResolveMentions(t *testing.T) {
+	author := uuid.New()
+	member := uuid.New()
+	outsider := uuid.New()
+	allowed := map[uuid.UUID]bool{author: true, member: true}
+
+	t.Run("skips the author", func(t *testing.T) {
+		ids, err := resolveMentions(mentionToken("Me", author)+" "+mentionToken("You", member), author, allowed)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{member}, ids)
+	})
+
+	t.Run("rejects non-members", func(t *testing.T) {
+		_, err := resolveMentions(mentionToken("Stranger", outsider), author, allowed)
+		require.Error(t, err)
+		appErr, ok := err.(*apperror.AppError)
+		require.True(t, ok)
+		assert.Equal(t, "VALIDATION_ERROR", appErr.Code)
+	})
+}
+
+func TestMentionService_Record(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockMentionRepo()
+	userRepo := newMockUserRepo()
+	notif := &recordingNotifSvc{}
+	svc := NewMentionService(repo, userRepo, notif)
+
+	author := uuid.New()
+	andi := uuid.New()
+	budi := uuid.New()
+	userRepo.addUser(&model.User{ID: author, Name: "Citra"})
+
+	target := model.MentionTarget{
+		SourceType:  model.MentionSourceChat,
+		SourceID:    uuid.New(),
+		ItemID:      uuid.New(),
+		MentionedBy: author,
+		Preview:     "halo " + mentionToken("Andi", andi),
+	}
+
+	require.NoError(t, svc.Record(ctx, target, []uuid.UUID{andi}))
+	assert.Eventually(t, func() bool { return len(notif.sentToUsers()) == 1 }, time.Second, 10*time.Millisecond)
+	sent := notif.sentToUsers()[0]
+	assert.Equal(t, []uuid.UUID{andi}, sent.UserIDs)
+	assert.Equal(t, "Citra menyebut Anda", sent.Notif.Title)
+	assert.Equal(t, "halo @Andi", sent.Notif.Body)
+	assert.Equal(t, "high", sent.Notif.Priority)
+
+	// Re-recording after an edit only notifies users who are new to the item
+	target.Preview = "halo " + mentionToken("Budi", budi)
+	require.NoError(t, svc.Record(ctx, target, []uuid.UUID{budi}))
+	assert.Eventually(t, func() bool { return len(notif.sentToUsers()) == 2 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []uuid.UUID{budi}, notif.sentToUsers()[1].UserIDs)
+
+	mentions, err := svc.ForItems(ctx, []uuid.UUID{target.ItemID})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{budi}, mentions[target.ItemID])
+
+	require.NoError(t, svc.Clear(ctx, target.ItemID))
+	mentions, err = svc.ForItems(ctx, []uuid.UUID{target.ItemID})
+	require.NoError(t, err)
+	assert.Empty(t, mentions)
+}
+
+func TestMentionService_List(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockMentionRepo()
+	svc := NewMentionService(repo, newMockUserRepo(), nil)
+
+	user := uuid.New()
+	author := uuid.New()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, svc.Record(ctx, model.MentionTarget{
+			SourceType:  model.MentionSourceTopic,
+			SourceID:    uuid.New(),
+			ItemID:      uuid.New(),
+			MentionedBy: author,
+			Preview:     "ping",
+		}, []uuid.UUID{user}))
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	page, err := svc.List(ctx, user, "", 2)
+	require.NoError(t, err)
+	assert.Len(t, page.Mentions, 2)
+	assert.True(t, page.HasMore)
+	require.NotEmpty(t, page.Cursor)
+
+	next, err := svc.List(ctx, user, page.Cursor, 2)
+	require.NoError(t, err)
+	assert.Len(t, next.Mentions, 1)
+	assert.False(t, next.HasMore)
+
+	_, err = svc.List(ctx, user, "bad-cursor", 2)
+	require.Error(t, err)
+}
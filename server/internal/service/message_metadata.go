@@ -0,0 +1,226 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/internal/repository"
+	"github.com/otoritech/chatat/pkg/apperror"
+	"github.com/otoritech/chatat/pkg/phone"
+)
+
+const (
+	maxLocationLabelLength = 200
+	minLiveLocation        = time.Minute
+	maxLiveLocation        = 8 * time.Hour
+	maxVCardSize           = 8 * 1024
+)
+
+// locationInput is the metadata a client sends with a location message.
+// LiveDuration, in seconds, turns it into a live location.
+type locationInput struct {
+	Latitude     *float64 `json:"latitude"`
+	Longitude    *float64 `json:"longitude"`
+	Label        string   `json:"label"`
+	LiveDuration int      `json:"liveDuration"`
+}
+
+// contactInput is the metadata a client sends with a contact card message.
+type contactInput struct {
+	VCard string `json:"vcard"`
+}
+
+// prepareMessageMetadata validates the metadata of location and contact
+// messages and returns it normalized along with the content to store for
+// them, which is what makes them searchable. Other message types are
+// returned unchanged.
+func prepareMessageMetadata(ctx context.Context, userRepo repository.UserRepository, msgType model.MessageType, content string, raw json.RawMessage) (string, json.RawMessage, error) {
+	switch msgType {
+	case model.MessageTypeLocation:
+		return prepareLocation(raw, time.Now())
+	case model.MessageTypeContact:
+		return prepareContact(ctx, userRepo, raw)
+	default:
+		return content, raw, nil
+	}
+}
+
+func prepareLocation(raw json.RawMessage, now time.Time) (string, json.RawMessage, error) {
+	var in locationInput
+	if len(raw) == 0 || json.Unmarshal(raw, &in) != nil {
+		return "", nil, apperror.Validation("metadata", "location metadata is invalid")
+	}
+	if in.Latitude == nil || in.Longitude == nil {
+		return "", nil, apperror.Validation("metadata", "latitude and longitude are required")
+	}
+	if err := validateCoordinates(*in.Latitude, *in.Longitude); err != nil {
+		return "", nil, err
+	}
+
+	label := strings.TrimSpace(in.Label)
+	if utf8.RuneCountInString(label) > maxLocationLabelLength {
+		return "", nil, apperror.Validation("metadata", fmt.Sprintf("label must be at most %d characters", maxLocationLabelLength))
+	}
+
+	meta := model.LocationMetadata{
+		Latitude:  *in.Latitude,
+		Longitude: *in.Longitude,
+		Label:     label,
+	}
+	if in.LiveDuration != 0 {
+		live := time.Duration(in.LiveDuration) * time.Second
+		if live < minLiveLocation || live > maxLiveLocation {
+			return "", nil, apperror.Validation("metadata", fmt.Sprintf("liveDuration must be between %d and %d seconds",
+				int(minLiveLocation.Seconds()), int(maxLiveLocation.Seconds())))
+		}
+		until := now.Add(live)
+		meta.LiveUntil = &until
+		meta.UpdatedAt = &now
+	}
+
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return "", nil, fmt.Errorf("marshal location metadata: %w", err)
+	}
+	return label, data, nil
+}
+
+// validateCoordinates checks that a position lies on the globe.
+func validateCoordinates(lat, lng float64) error {
+	if lat < -90 || lat > 90 {
+		return apperror.Validation("latitude", "latitude must be between -90 and 90")
+	}
+	if lng < -180 || lng > 180 {
+		return apperror.Validation("longitude", "longitude must be between -180 and 180")
+	}
+	return nil
+}
+
+func prepareContact(ctx context.Context, userRepo repository.UserRepository, raw json.RawMessage) (string, json.RawMessage, error) {
+	var in contactInput
+	if len(raw) == 0 || json.Unmarshal(raw, &in) != nil {
+		return "", nil, apperror.Validation("metadata", "contact metadata is invalid")
+	}
+	if len(in.VCard) > maxVCardSize {
+		return "", nil, apperror.Validation("metadata", fmt.Sprintf("vcard must be at most %d bytes", maxVCardSize))
+	}
+
+	name, phones, err := parseVCard(in.VCard)
+	if err != nil {
+		return "", nil, err
+	}
+
+	meta := model.ContactMetadata{
+		VCard:  in.VCard,
+		Name:   name,
+		Phones: phones,
+	}
+	if userRepo != nil && len(phones) > 0 {
+		meta.UserID = matchContactUser(ctx, userRepo, phones)
+	}
+
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return "", nil, fmt.Errorf("marshal contact metadata: %w", err)
+	}
+	return strings.TrimSpace(name + " " + strings.Join(phones, " ")), data, nil
+}
+
+// matchContactUser returns the user owning the first of the card's phone
+// numbers that belongs to anyone. Lookup failures leave the card unlinked.
+func matchContactUser(ctx context.Context, userRepo repository.UserRepository, phones []string) *uuid.UUID {
+	users, err := userRepo.FindByPhones(ctx, phones)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to match contact card phones")
+		return nil
+	}
+
+	byPhone := make(map[string]uuid.UUID, len(users))
+	for _, u := range users {
+		byPhone[u.Phone] = u.ID
+	}
+	for _, p := range phones {
+		if id, ok := byPhone[p]; ok {
+			return &id
+		}
+	}
+	return nil
+}
+
+// parseVCard extracts the display name and phone numbers of a vCard.
+// Phone numbers are normalized to E.164 when they parse, so they can be
+// matched against users; others are kept as written.
+func parseVCard(card string) (string, []string, error) {
+	// Folded lines continue with a leading space or tab
+	unfolded := strings.NewReplacer("\r\n ", "", "\r\n\t", "", "\n ", "", "\n\t", "").Replace(card)
+
+	var (
+		lines          []string
+		name, fallback string
+		phones         []string
+	)
+	scanner := bufio.NewScanner(strings.NewReader(unfolded))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) < 2 || !strings.EqualFold(lines[0], "BEGIN:VCARD") || !strings.EqualFold(lines[len(lines)-1], "END:VCARD") {
+		return "", nil, apperror.Validation("metadata", "vcard is invalid")
+	}
+
+	seen := make(map[string]bool)
+	for _, line := range lines[1 : len(lines)-1] {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		// Properties may carry a group prefix ("item1.TEL") and parameters ("TEL;TYPE=CELL")
+		key, _, _ = strings.Cut(key, ";")
+		if i := strings.LastIndex(key, "."); i >= 0 {
+			key = key[i+1:]
+		}
+		value = strings.TrimSpace(value)
+
+		switch strings.ToUpper(key) {
+		case "FN":
+			name = value
+		case "N":
+			// Family;Given;Additional;Prefix;Suffix
+			parts := strings.Split(value, ";")
+			if len(parts) > 1 {
+				parts[0], parts[1] = parts[1], parts[0]
+			}
+			fallback = strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+		case "TEL":
+			number := strings.TrimSpace(strings.TrimPrefix(value, "tel:"))
+			if number == "" {
+				continue
+			}
+			if normalized, err := phone.Normalize(number, ""); err == nil {
+				number = normalized
+			}
+			if !seen[number] {
+				seen[number] = true
+				phones = append(phones, number)
+			}
+		}
+	}
+
+	if name == "" {
+		name = fallback
+	}
+	if name == "" {
+		return "", nil, apperror.Validation("metadata", "vcard must have a name")
+	}
+	return name, phones, nil
+}
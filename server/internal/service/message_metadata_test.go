@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otoritech/chatat/internal/model"
+)
+
+const testVCard = "BEGIN:VCARD\r\n" +
+	"VERSION:3.0\r\n" +
+	"N:Santoso;Budi;;;\r\n" +
+	"FN:Budi Santoso\r\n" +
+	"ORG:CV Baja\r\n" +
+	" Ringan\r\n" +
+	"TEL;TYPE=CELL:0812-3456-7890\r\n" +
+	"item1.TEL:+62 812 3456 7890\r\n" +
+	"TEL:ext 12\r\n" +
+	"END:VCARD\r\n"
+
+func TestPrepareLocation(t *testing.T) {
+	now := time.Now()
+
+	t.Run("static location", func(t *testing.T) {
+		content, raw, err := prepareLocation(json.RawMessage(`{"latitude":-6.2,"longitude":106.8,"label":"  Gudang Cikarang "}`), now)
+		require.NoError(t, err)
+		assert.Equal(t, "Gudang Cikarang", content)
+
+		var meta model.LocationMetadata
+		require.NoError(t, json.Unmarshal(raw, &meta))
+		assert.Equal(t, -6.2, meta.Latitude)
+		assert.Equal(t, 106.8, meta.Longitude)
+		assert.Nil(t, meta.LiveUntil)
+		assert.False(t, meta.IsLive(now))
+	})
+
+	t.Run("live location", func(t *testing.T) {
+		_, raw, err := prepareLocation(json.RawMessage(`{"latitude":0,"longitude":0,"liveDuration":900}`), now)
+		require.NoError(t, err)
+
+		var meta model.LocationMetadata
+		require.NoError(t, json.Unmarshal(raw, &meta))
+		require.NotNil(t, meta.LiveUntil)
+		assert.WithinDuration(t, now.Add(15*time.Minute), *meta.LiveUntil, time.Second)
+		assert.True(t, meta.IsLive(now))
+	})
+
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"missing metadata", ``, "location metadata is invalid"},
+		{"malformed", `{"latitude":"north"}`, "location metadata is invalid"},
+		{"missing longitude", `{"latitude":1}`, "latitude and longitude are required"},
+		{"latitude out of range", `{"latitude":91,"longitude":0}`, "latitude must be between"},
+		{"longitude out of range", `{"latitude":0,"longitude":-181}`, "longitude must be between"},
+		{"live too short", `{"latitude":0,"longitude":0,"liveDuration":10}`, "liveDuration must be between"},
+		{"live too long", `{"latitude":0,"longitude":0,"liveDuration":86400}`, "liveDuration must be between"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := prepareLocation(json.RawMessage(tt.raw), now)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestParseVCard(t *testing.T) {
+	t.Run("name and normalized phones", func(t *testing.T) {
+		name, phones, err := parseVCard(testVCard)
+		require.NoError(t, err)
+		assert.Equal(t, "Budi Santoso", name)
+		assert.Equal(t, []string{"+6281234567890", "ext 12"}, phones)
+	})
+
+	t.Run("structured name fallback", func(t *testing.T) {
+		name, phones, err := parseVCard("BEGIN:VCARD\nN:Santoso;Budi;;;\nEND:VCARD")
+		require.NoError(t, err)
+		assert.Equal(t, "Budi Santoso", name)
+		assert.Empty(t, phones)
+	})
+
+	t.Run("no name", func(t *testing.T) {
+		_, _, err := parseVCard("BEGIN:VCARD\nTEL:081234567890\nEND:VCARD")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "vcard must have a name")
+	})
+
+	t.Run("not a vcard", func(t *testing.T) {
+		_, _, err := parseVCard("FN:Budi")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "vcard is invalid")
+	})
+}
+
+func TestPrepareContact(t *testing.T) {
+	raw, err := json.Marshal(contactInput{VCard: testVCard})
+	require.NoError(t, err)
+
+	t.Run("linked to matching user", func(t *testing.T) {
+		userRepo := newMockUserRepo()
+		user := &model.User{ID: uuid.New(), Phone: "+6281234567890", Name: "Budi"}
+		userRepo.addUser(user)
+
+		content, data, err := prepareContact(context.Background(), userRepo, raw)
+		require.NoError(t, err)
+		assert.Contains(t, content, "Budi Santoso")
+		assert.Contains(t, content, "+6281234567890")
+
+		var meta model.ContactMetadata
+		require.NoError(t, json.Unmarshal(data, &meta))
+		assert.Equal(t, testVCard, meta.VCard)
+		require.NotNil(t, meta.UserID)
+		assert.Equal(t, user.ID, *meta.UserID)
+	})
+
+	t.Run("no matching user", func(t *testing.T) {
+		_, data, err := prepareContact(context.Background(), newMockUserRepo(), raw)
+		require.NoError(t, err)
+
+		var meta model.ContactMetadata
+		require.NoError(t, json.Unmarshal(data, &meta))
+		assert.Nil(t, meta.UserID)
+	})
+
+	t.Run("missing vcard", func(t *testing.T) {
+		_, _, err := prepareContact(context.Background(), nil, json.RawMessage(`{}`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "vcard is invalid")
+	})
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/otoritech/chatat/internal/repository"
+	"github.com/otoritech/chatat/internal/ws"
+)
+
+// MessagesExpiredEvent tells chat members which messages a disappearing timer removed.
+type MessagesExpiredEvent struct {
+	ChatID     uuid.UUID   `json:"chatId"`
+	MessageIDs []uuid.UUID `json:"messageIds"`
+}
+
+// reapBatchSize is how many expired messages one Reap call deletes.
+const reapBatchSize = 500
+
+// MessageReaper periodically deletes messages whose disappearing timer ran
+// out. Every replica may run one; batches are claimed with row locks, so
+// replicas never delete the same message twice.
+type MessageReaper struct {
+	messageRepo repository.MessageRepository
+	storageSvc  StorageService
+	hub         *ws.Hub
+	interval    time.Duration
+	done        chan struct{}
+}
+
+// NewMessageReaper creates a reaper that checks for expired messages every interval.
+func NewMessageReaper(messageRepo repository.MessageRepository, storageSvc StorageService, hub *ws.Hub, interval time.Duration) *MessageReaper {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &MessageReaper{
+		messageRepo: messageRepo,
+		storageSvc:  storageSvc,
+		hub:         hub,
+		interval:    interval,
+		done:        make(chan struct{}),
+	}
+}
+
+// Run starts the reap loop. Should be called in a goroutine.
+func (r *MessageReaper) Run() {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			r.reapAll()
+		}
+	}
+}
+
+// Stop ends the reap loop.
+func (r *MessageReaper) Stop() {
+	close(r.done)
+}
+
+// Reap deletes one batch of expired messages with their media and tells
+// the affected chats. It returns how many messages were deleted.
+func (r *MessageReaper) Reap(ctx context.Context) (int, error) {
+	expired, err := r.messageRepo.DeleteExpired(ctx, reapBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired messages: %w", err)
+	}
+
+	// The media rows are gone, so a failed object delete only leaks storage
+	for _, media := range expired.Media {
+		if err := r.storageSvc.Delete(ctx, media.StorageKey); err != nil {
+			log.Warn().Err(err).Str("media_id", media.ID.String()).Msg("failed to delete expired media object")
+		}
+		if media.ThumbnailKey != nil {
+			_ = r.storageSvc.Delete(ctx, *media.ThumbnailKey)
+		}
+	}
+
+	for chatID, messageIDs := range expired.ByChat {
+		data, err := json.Marshal(map[string]interface{}{
+			"type":    ws.WSTypeMessagesExpired,
+			"payload": MessagesExpiredEvent{ChatID: chatID, MessageIDs: messageIDs},
+		})
+		if err == nil {
+			r.hub.SendToRoom("chat:"+chatID.String(), data, uuid.Nil)
+		}
+	}
+
+	return expired.Count(), nil
+}
+
+// reapAll drains expired messages batch by batch so a backlog is cleared in one tick.
+func (r *MessageReaper) reapAll() {
+	for {
+		select {
+		case <-r.done:
+			return
+		default:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), r.interval)
+		deleted, err := r.Reap(ctx)
+		cancel()
+		if err != nil {
+			log.Error().Err(err).Msg("failed to reap expired messages")
+			return
+		}
+		if deleted < reapBatchSize {
+			return
+		}
+	}
+}
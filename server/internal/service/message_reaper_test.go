@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/internal/ws"
+)
+
+func TestMessageReaper_Reap(t *testing.T) {
+	ctx := context.Background()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	chatID := uuid.New()
+	msgID := uuid.New()
+	thumb := "thumbnails/photo.jpg"
+	storage := newMockStorageService()
+	storage.files["media/photo.jpg"] = []byte("photo")
+	storage.files[thumb] = []byte("thumb")
+
+	msgRepo := newMockMessageRepo()
+	msgRepo.expired = []*model.ExpiredMessages{{
+		ByChat: map[uuid.UUID][]uuid.UUID{chatID: {msgID}},
+		Media:  []*model.Media{{ID: uuid.New(), StorageKey: "media/photo.jpg", ThumbnailKey: &thumb}},
+	}}
+
+	observer := &ws.Client{UserID: uuid.New(), DeviceID: "d1", Send: make(chan []byte, 16), Hub: hub}
+	hub.RegisterClient(observer)
+	time.Sleep(20 * time.Millisecond)
+	hub.JoinRoom(observer, "chat:"+chatID.String())
+
+	reaper := NewMessageReaper(msgRepo, storage, hub, time.Minute)
+
+	deleted, err := reaper.Reap(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	assert.Empty(t, storage.files)
+
+	select {
+	case data := <-observer.Send:
+		var event struct {
+			Type    string               `json:"type"`
+			Payload MessagesExpiredEvent `json:"payload"`
+		}
+		require.NoError(t, json.Unmarshal(data, &event))
+		assert.Equal(t, ws.WSTypeMessagesExpired, event.Type)
+		assert.Equal(t, chatID, event.Payload.ChatID)
+		assert.Equal(t, []uuid.UUID{msgID}, event.Payload.MessageIDs)
+	case <-time.After(time.Second):
+		t.Fatal("expected messages_expired event")
+	}
+
+	t.Run("nothing expired", func(t *testing.T) {
+		deleted, err := reaper.Reap(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, deleted)
+	})
+}
+
+func TestMessageReaper_Run(t *testing.T) {
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	chatID := uuid.New()
+	msgRepo := newMockMessageRepo()
+	msgRepo.expired = []*model.ExpiredMessages{{ByChat: map[uuid.UUID][]uuid.UUID{chatID: {uuid.New()}}}}
+
+	observer := &ws.Client{UserID: uuid.New(), DeviceID: "d1", Send: make(chan []byte, 16), Hub: hub}
+	hub.RegisterClient(observer)
+	time.Sleep(20 * time.Millisecond)
+	hub.JoinRoom(observer, "chat:"+chatID.String())
+
+	reaper := NewMessageReaper(msgRepo, newMockStorageService(), hub, 10*time.Millisecond)
+	go reaper.Run()
+	defer reaper.Stop()
+
+	select {
+	case data := <-observer.Send:
+		assert.Contains(t, string(data), `"type":"messages_expired"`)
+	case <-time.After(time.Second):
+		t.Fatal("expected messages_expired event")
+	}
+}
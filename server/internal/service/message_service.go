@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
 
 	"github.com/otoritech/chatat/internal/model"
 	"github.com/otoritech/chatat/internal/repository"
@@ -22,6 +23,10 @@ type SendMessageInput struct {
 	ReplyToID *uuid.UUID        `json:"replyToId"`
 	Type      model.MessageType `json:"type"`
 	Metadata  json.RawMessage   `json:"metadata"`
+
+	// fromPoll is set by the poll service, which creates the poll before its
+	// message; every other caller is refused poll messages
+	fromPoll bool
 }
 
 // MessagePage represents a paginated list of messages.
@@ -31,6 +36,28 @@ type MessagePage struct {
 	HasMore  bool             `json:"hasMore"`
 }
 
+// ThreadPage is a page of replies in the thread rooted at Root.
+type ThreadPage struct {
+	Root      *model.Message   `json:"root"`
+	Replies   []*model.Message `json:"replies"`
+	Following bool             `json:"following"`
+	Cursor    string           `json:"cursor"`
+	HasMore   bool             `json:"hasMore"`
+}
+
+// MessageConfig holds message behaviour settings.
+type MessageConfig struct {
+	// EditWindow is how long after sending a message its sender may edit it.
+	EditWindow time.Duration
+}
+
+// DefaultMessageConfig returns sensible defaults.
+func DefaultMessageConfig() MessageConfig {
+	return MessageConfig{
+		EditWindow: 15 * time.Minute,
+	}
+}
+
 // WSMessageEvent is the WebSocket event payload for new messages.
 type WSMessageEvent struct {
 	Type    string         `json:"type"`
@@ -44,34 +71,64 @@ type MessageService interface {
 	ForwardMessage(ctx context.Context, messageID, senderID, targetChatID uuid.UUID) (*model.Message, error)
 	DeleteMessage(ctx context.Context, messageID, userID uuid.UUID, forAll bool) error
 	SearchMessages(ctx context.Context, chatID uuid.UUID, query string) ([]*model.Message, error)
-	MarkChatAsRead(ctx context.Context, chatID, userID uuid.UUID) error
+	// MarkChatAsRead records that the user has read the chat up to lastReadID,
+	// or up to the newest message when it is nil.
+	MarkChatAsRead(ctx context.Context, chatID, userID uuid.UUID, lastReadID *uuid.UUID) error
+	// GetReceipts lists who has received and read a message the user sent.
+	GetReceipts(ctx context.Context, chatID, messageID, userID uuid.UUID) ([]*model.MessageReceipt, error)
+	AddReaction(ctx context.Context, chatID, messageID, userID uuid.UUID, emoji string) ([]model.ReactionSummary, error)
+	RemoveReaction(ctx context.Context, chatID, messageID, userID uuid.UUID, emoji string) ([]model.ReactionSummary, error)
+	EditMessage(ctx context.Context, chatID, messageID, userID uuid.UUID, content string) (*model.Message, error)
+	GetEditHistory(ctx context.Context, chatID, messageID, userID uuid.UUID) ([]*model.MessageEdit, error)
+	GetThread(ctx context.Context, chatID, messageID, userID uuid.UUID, cursor string, limit int) (*ThreadPage, error)
+	FollowThread(ctx context.Context, chatID, messageID, userID uuid.UUID) error
+	UnfollowThread(ctx context.Context, chatID, messageID, userID uuid.UUID) error
+	// UpdateLiveLocation moves a live location the user is sharing.
+	UpdateLiveLocation(ctx context.Context, chatID, messageID, userID uuid.UUID, latitude, longitude float64) (*model.Message, error)
+	// StopLiveLocation ends a live location early, leaving its last position.
+	StopLiveLocation(ctx context.Context, chatID, messageID, userID uuid.UUID) (*model.Message, error)
 }
 
 type messageService struct {
 	messageRepo     repository.MessageRepository
 	messageStatRepo repository.MessageStatusRepository
+	reactionRepo    repository.ReactionRepository
+	threadRepo      repository.ThreadRepository
 	chatRepo        repository.ChatRepository
 	userRepo        repository.UserRepository
+	blockRepo       repository.UserBlockRepository
 	hub             *ws.Hub
 	notifSvc        NotificationService
+	mentionSvc      MentionService
+	config          MessageConfig
 }
 
 // NewMessageService creates a new MessageService.
 func NewMessageService(
 	messageRepo repository.MessageRepository,
 	messageStatRepo repository.MessageStatusRepository,
+	reactionRepo repository.ReactionRepository,
+	threadRepo repository.ThreadRepository,
 	chatRepo repository.ChatRepository,
 	userRepo repository.UserRepository,
+	blockRepo repository.UserBlockRepository,
 	hub *ws.Hub,
 	notifSvc NotificationService,
+	mentionSvc MentionService,
+	config MessageConfig,
 ) MessageService {
 	return &messageService{
 		messageRepo:     messageRepo,
 		messageStatRepo: messageStatRepo,
+		reactionRepo:    reactionRepo,
+		threadRepo:      threadRepo,
 		chatRepo:        chatRepo,
 		userRepo:        userRepo,
+		blockRepo:       blockRepo,
 		hub:             hub,
 		notifSvc:        notifSvc,
+		mentionSvc:      mentionSvc,
+		config:          config,
 	}
 }
 
@@ -83,26 +140,42 @@ func (s *messageService) SendMessage(ctx context.Context, input SendMessageInput
 	if input.Type == model.MessageTypeText && input.Content == "" {
 		return nil, apperror.Validation("content", "message content cannot be empty")
 	}
+	if input.Type == model.MessageTypePoll && !input.fromPoll {
+		return nil, apperror.BadRequest("use the polls endpoint to create a poll")
+	}
+	content, metadata, err := prepareMessageMetadata(ctx, s.userRepo, input.Type, input.Content, input.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	input.Content, input.Metadata = content, metadata
 
 	// Verify sender is member of the chat
 	members, err := s.chatRepo.GetMembers(ctx, input.ChatID)
 	if err != nil {
 		return nil, fmt.Errorf("get chat members: %w", err)
 	}
-	isMember := false
-	for _, m := range members {
-		if m.UserID == input.SenderID {
-			isMember = true
-			break
-		}
-	}
-	if !isMember {
+	memberIDs := chatMemberSet(members)
+	if !memberIDs[input.SenderID] {
 		return nil, apperror.Forbidden("you are not a member of this chat")
 	}
+	if err := s.requireNotBlocked(ctx, input.ChatID, input.SenderID, members); err != nil {
+		return nil, err
+	}
+	if err := s.requireSendPermission(ctx, input.ChatID, input.SenderID, members); err != nil {
+		return nil, err
+	}
+
+	// Only chat members can be mentioned
+	mentioned, err := resolveMentions(input.Content, input.SenderID, memberIDs)
+	if err != nil {
+		return nil, err
+	}
 
 	// Validate replyToID if provided
+	var replyMsg *model.Message
+	var threadID *uuid.UUID
 	if input.ReplyToID != nil {
-		replyMsg, err := s.messageRepo.FindByID(ctx, *input.ReplyToID)
+		replyMsg, err = s.messageRepo.FindByID(ctx, *input.ReplyToID)
 		if err != nil {
 			if apperror.IsNotFound(err) {
 				return nil, apperror.BadRequest("reply message not found")
@@ -113,6 +186,13 @@ func (s *messageService) SendMessage(ctx context.Context, input SendMessageInput
 		if replyMsg.ChatID != input.ChatID {
 			return nil, apperror.BadRequest("reply message must be in the same chat")
 		}
+
+		// Replies to a reply join the thread of the message it replied to
+		rootID := replyMsg.ID
+		if replyMsg.ThreadID != nil {
+			rootID = *replyMsg.ThreadID
+		}
+		threadID = &rootID
 	}
 
 	// Create message
@@ -121,12 +201,14 @@ func (s *messageService) SendMessage(ctx context.Context, input SendMessageInput
 		SenderID:  input.SenderID,
 		Content:   input.Content,
 		ReplyToID: input.ReplyToID,
+		ThreadID:  threadID,
 		Type:      input.Type,
 		Metadata:  input.Metadata,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create message: %w", err)
 	}
+	msg.Mentions = mentioned
 
 	// Create message_status entries for other members (status: sent)
 	for _, m := range members {
@@ -139,6 +221,26 @@ func (s *messageService) SendMessage(ctx context.Context, input SendMessageInput
 		}
 	}
 
+	if len(mentioned) > 0 {
+		recordMentions(ctx, s.mentionSvc, model.MentionTarget{
+			SourceType:  model.MentionSourceChat,
+			SourceID:    input.ChatID,
+			ItemID:      msg.ID,
+			MentionedBy: input.SenderID,
+			Preview:     input.Content,
+		}, mentioned)
+	}
+
+	// Replying makes the sender a participant; the thread starter follows too
+	var followers []uuid.UUID
+	if threadID != nil {
+		followers, err = s.joinThread(ctx, *threadID, input.SenderID, memberIDs)
+		if err != nil {
+			// Log but don't fail the send; the message is already saved
+			log.Warn().Err(err).Str("message_id", msg.ID.String()).Msg("failed to update thread followers")
+		}
+	}
+
 	// Broadcast via WebSocket to chat room
 	roomID := "chat:" + input.ChatID.String()
 	event := WSMessageEvent{
@@ -163,14 +265,40 @@ func (s *messageService) SendMessage(ctx context.Context, input SendMessageInput
 				return
 			}
 
+			content := renderMentions(input.Content)
 			var notif model.Notification
 			if chat.Type == model.ChatTypeGroup {
-				notif = BuildGroupMessageNotif(chat.Name, senderName, input.Content, input.ChatID)
+				notif = BuildGroupMessageNotif(chat.Name, senderName, content, input.ChatID)
 			} else {
-				notif = BuildMessageNotif(senderName, input.Content, input.ChatID, string(chat.Type))
+				notif = BuildMessageNotif(senderName, content, input.ChatID, string(chat.Type))
+			}
+
+			// Mentioned users get their own notification from the mention service
+			exclude := []uuid.UUID{input.SenderID}
+			if s.mentionSvc != nil {
+				exclude = append(exclude, mentioned...)
 			}
 
-			_ = s.notifSvc.SendToChat(context.Background(), input.ChatID, input.SenderID, notif)
+			// Thread followers are told about the reply instead of the chat message
+			if len(followers) > 0 {
+				skip := make(map[uuid.UUID]bool, len(exclude))
+				for _, id := range exclude {
+					skip[id] = true
+				}
+				var recipients []uuid.UUID
+				for _, id := range followers {
+					if !skip[id] {
+						recipients = append(recipients, id)
+					}
+				}
+				if len(recipients) > 0 {
+					threadNotif := BuildThreadReplyNotif(senderName, content, input.ChatID, *threadID)
+					_ = s.notifSvc.SendToUsers(context.Background(), recipients, threadNotif)
+				}
+				exclude = append(exclude, followers...)
+			}
+
+			_ = s.notifSvc.SendToChat(context.Background(), input.ChatID, exclude, notif)
 		}()
 	}
 
@@ -202,6 +330,10 @@ func (s *messageService) GetMessages(ctx context.Context, chatID uuid.UUID, curs
 		messages = messages[:limit]
 	}
 
+	if err := s.decorate(ctx, messages); err != nil {
+		return nil, err
+	}
+
 	var nextCursor string
 	if hasMore && len(messages) > 0 {
 		nextCursor = messages[len(messages)-1].CreatedAt.Format(time.RFC3339Nano)
@@ -220,6 +352,10 @@ func (s *messageService) ForwardMessage(ctx context.Context, messageID, senderID
 	if err != nil {
 		return nil, fmt.Errorf("find original message: %w", err)
 	}
+	// A poll's votes belong to the chat it was posted in
+	if originalMsg.Type == model.MessageTypePoll {
+		return nil, apperror.BadRequest("polls cannot be forwarded")
+	}
 
 	// Verify sender is member of target chat
 	members, err := s.chatRepo.GetMembers(ctx, targetChatID)
@@ -236,13 +372,24 @@ func (s *messageService) ForwardMessage(ctx context.Context, messageID, senderID
 	if !isMember {
 		return nil, apperror.Forbidden("you are not a member of the target chat")
 	}
+	if err := s.requireNotBlocked(ctx, targetChatID, senderID, members); err != nil {
+		return nil, err
+	}
+	if err := s.requireSendPermission(ctx, targetChatID, senderID, members); err != nil {
+		return nil, err
+	}
 
-	// Build forwarded metadata
-	meta := map[string]interface{}{
-		"forwarded":         true,
-		"originalChatId":    originalMsg.ChatID.String(),
-		"originalMessageId": originalMsg.ID.String(),
+	// Build forwarded metadata on top of the original's, so media, locations
+	// and contact cards survive the forward
+	meta := map[string]interface{}{}
+	if len(originalMsg.Metadata) > 0 {
+		_ = json.Unmarshal(originalMsg.Metadata, &meta)
 	}
+	// A forwarded live location is a snapshot of where it was
+	delete(meta, "liveUntil")
+	meta["forwarded"] = true
+	meta["originalChatId"] = originalMsg.ChatID.String()
+	meta["originalMessageId"] = originalMsg.ID.String()
 	metaJSON, _ := json.Marshal(meta)
 
 	// Create forwarded message in target chat
@@ -314,6 +461,10 @@ func (s *messageService) DeleteMessage(ctx context.Context, messageID, userID uu
 		}
 		data, _ := json.Marshal(event)
 		s.hub.SendToRoom(roomID, data, uuid.Nil)
+
+		if s.mentionSvc != nil {
+			_ = s.mentionSvc.Clear(ctx, messageID)
+		}
 	}
 
 	return nil
@@ -332,9 +483,544 @@ func (s *messageService) SearchMessages(ctx context.Context, chatID uuid.UUID, q
 	return messages, nil
 }
 
-func (s *messageService) MarkChatAsRead(ctx context.Context, chatID, userID uuid.UUID) error {
-	if err := s.messageStatRepo.MarkChatAsRead(ctx, chatID, userID); err != nil {
+func (s *messageService) MarkChatAsRead(ctx context.Context, chatID, userID uuid.UUID, lastReadID *uuid.UUID) error {
+	if err := s.messageStatRepo.MarkChatAsRead(ctx, chatID, userID, lastReadID); err != nil {
+		if apperror.IsNotFound(err) {
+			return err
+		}
 		return fmt.Errorf("mark chat as read: %w", err)
 	}
 	return nil
 }
+
+func (s *messageService) GetReceipts(ctx context.Context, chatID, messageID, userID uuid.UUID) ([]*model.MessageReceipt, error) {
+	msg, err := s.findMemberMessage(ctx, chatID, messageID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if msg.SenderID != userID {
+		return nil, apperror.Forbidden("only the sender can view message receipts")
+	}
+
+	statuses, err := s.messageStatRepo.GetStatus(ctx, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("get message status: %w", err)
+	}
+
+	receipts := make([]*model.MessageReceipt, 0, len(statuses))
+	for _, st := range statuses {
+		user, err := s.userRepo.FindByID(ctx, st.UserID)
+		if err != nil {
+			continue
+		}
+		receipt := &model.MessageReceipt{
+			User:        *user,
+			Status:      st.Status,
+			DeliveredAt: st.DeliveredAt,
+			ReadAt:      st.ReadAt,
+		}
+		// Users who turned off read receipts never show as having read
+		if receipt.Status == model.DeliveryStatusRead && !user.PrivacySettings.ReadReceipts {
+			receipt.Status = model.DeliveryStatusDelivered
+			receipt.ReadAt = nil
+		}
+		receipts = append(receipts, receipt)
+	}
+
+	return receipts, nil
+}
+
+func (s *messageService) AddReaction(ctx context.Context, chatID, messageID, userID uuid.UUID, emoji string) ([]model.ReactionSummary, error) {
+	emoji, err := validateEmoji(emoji)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.findMemberMessage(ctx, chatID, messageID, userID); err != nil {
+		return nil, err
+	}
+
+	added, err := s.reactionRepo.Add(ctx, messageID, userID, emoji)
+	if err != nil {
+		return nil, fmt.Errorf("add reaction: %w", err)
+	}
+	if !added {
+		// Already reacted with this emoji; nothing to broadcast
+		return messageReactions(ctx, s.reactionRepo, messageID)
+	}
+
+	return s.publishReaction(ctx, chatID, messageID, userID, emoji, ReactionActionAdded)
+}
+
+func (s *messageService) RemoveReaction(ctx context.Context, chatID, messageID, userID uuid.UUID, emoji string) ([]model.ReactionSummary, error) {
+	emoji, err := validateEmoji(emoji)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.findMemberMessage(ctx, chatID, messageID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.reactionRepo.Remove(ctx, messageID, userID, emoji); err != nil {
+		return nil, err
+	}
+
+	return s.publishReaction(ctx, chatID, messageID, userID, emoji, ReactionActionRemoved)
+}
+
+// findMemberMessage checks that the user is a chat member and the message
+// belongs to the chat and has not been deleted for everyone.
+func (s *messageService) findMemberMessage(ctx context.Context, chatID, messageID, userID uuid.UUID) (*model.Message, error) {
+	members, err := s.chatRepo.GetMembers(ctx, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("get chat members: %w", err)
+	}
+	if !chatMemberSet(members)[userID] {
+		return nil, apperror.Forbidden("you are not a member of this chat")
+	}
+
+	msg, err := s.messageRepo.FindByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if msg.ChatID != chatID {
+		return nil, apperror.NotFound("message", messageID.String())
+	}
+	if msg.IsDeleted && msg.DeletedForAll {
+		return nil, apperror.BadRequest("message has been deleted")
+	}
+
+	return msg, nil
+}
+
+// publishReaction broadcasts the message's updated reactions to the chat room.
+func (s *messageService) publishReaction(ctx context.Context, chatID, messageID, userID uuid.UUID, emoji, action string) ([]model.ReactionSummary, error) {
+	summaries, err := messageReactions(ctx, s.reactionRepo, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	broadcastReaction(s.hub, "chat:"+chatID.String(), ReactionEvent{
+		ChatID:    &chatID,
+		MessageID: messageID,
+		UserID:    userID,
+		Emoji:     emoji,
+		Action:    action,
+		Reactions: summaries,
+	})
+
+	return summaries, nil
+}
+
+func (s *messageService) EditMessage(ctx context.Context, chatID, messageID, userID uuid.UUID, content string) (*model.Message, error) {
+	msg, err := s.messageRepo.FindByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if msg.ChatID != chatID {
+		return nil, apperror.NotFound("message", messageID.String())
+	}
+
+	// Senders who left or were removed can no longer edit
+	members, err := s.chatRepo.GetMembers(ctx, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("get chat members: %w", err)
+	}
+	memberIDs := chatMemberSet(members)
+	if !memberIDs[userID] {
+		return nil, apperror.Forbidden("you are not a member of this chat")
+	}
+
+	if err := checkEditable(msg.SenderID, userID, msg.Type, msg.IsDeleted, msg.CreatedAt, content, s.config.EditWindow); err != nil {
+		return nil, err
+	}
+	if content == msg.Content {
+		return msg, nil
+	}
+
+	mentioned, err := resolveMentions(content, userID, memberIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	edited, err := s.messageRepo.Edit(ctx, messageID, content)
+	if err != nil {
+		return nil, fmt.Errorf("edit message: %w", err)
+	}
+	edited.Mentions = mentioned
+
+	// Users newly mentioned by the edit are notified; removed ones drop off their feed
+	recordMentions(ctx, s.mentionSvc, model.MentionTarget{
+		SourceType:  model.MentionSourceChat,
+		SourceID:    chatID,
+		ItemID:      messageID,
+		MentionedBy: userID,
+		Preview:     content,
+	}, mentioned)
+
+	// Broadcast via WebSocket to chat room
+	event := WSMessageEvent{
+		Type:    ws.WSTypeMessageEdited,
+		Payload: edited,
+	}
+	data, err := json.Marshal(event)
+	if err == nil {
+		s.hub.SendToRoom("chat:"+chatID.String(), data, uuid.Nil)
+	}
+
+	return edited, nil
+}
+
+func (s *messageService) GetEditHistory(ctx context.Context, chatID, messageID, userID uuid.UUID) ([]*model.MessageEdit, error) {
+	if _, err := s.findMemberMessage(ctx, chatID, messageID, userID); err != nil {
+		return nil, err
+	}
+
+	edits, err := s.messageRepo.ListEdits(ctx, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("list message edits: %w", err)
+	}
+	return edits, nil
+}
+
+func (s *messageService) UpdateLiveLocation(ctx context.Context, chatID, messageID, userID uuid.UUID, latitude, longitude float64) (*model.Message, error) {
+	if err := validateCoordinates(latitude, longitude); err != nil {
+		return nil, err
+	}
+
+	msg, loc, err := s.findLiveLocation(ctx, chatID, messageID, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !loc.IsLive(now) {
+		return nil, apperror.BadRequest("live location sharing has ended")
+	}
+
+	loc.Latitude, loc.Longitude = latitude, longitude
+	loc.UpdatedAt = &now
+	return s.saveLocation(ctx, msg, loc)
+}
+
+func (s *messageService) StopLiveLocation(ctx context.Context, chatID, messageID, userID uuid.UUID) (*model.Message, error) {
+	msg, loc, err := s.findLiveLocation(ctx, chatID, messageID, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !loc.IsLive(now) {
+		return msg, nil
+	}
+
+	loc.LiveUntil = &now
+	return s.saveLocation(ctx, msg, loc)
+}
+
+// findLiveLocation returns a location message the user sent in the chat
+// along with its decoded metadata.
+func (s *messageService) findLiveLocation(ctx context.Context, chatID, messageID, userID uuid.UUID) (*model.Message, *model.LocationMetadata, error) {
+	msg, err := s.findMemberMessage(ctx, chatID, messageID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if msg.Type != model.MessageTypeLocation {
+		return nil, nil, apperror.BadRequest("message is not a location")
+	}
+	if msg.SenderID != userID {
+		return nil, nil, apperror.Forbidden("only the sender can update a live location")
+	}
+
+	var loc model.LocationMetadata
+	if err := json.Unmarshal(msg.Metadata, &loc); err != nil {
+		return nil, nil, fmt.Errorf("decode location metadata: %w", err)
+	}
+	return msg, &loc, nil
+}
+
+// saveLocation stores a live location's new state and broadcasts it to the chat room.
+func (s *messageService) saveLocation(ctx context.Context, msg *model.Message, loc *model.LocationMetadata) (*model.Message, error) {
+	data, err := json.Marshal(loc)
+	if err != nil {
+		return nil, fmt.Errorf("marshal location metadata: %w", err)
+	}
+
+	updated, err := s.messageRepo.UpdateMetadata(ctx, msg.ID, data)
+	if err != nil {
+		return nil, fmt.Errorf("update location: %w", err)
+	}
+
+	event := map[string]interface{}{
+		"type": ws.WSTypeLocationUpdated,
+		"payload": map[string]interface{}{
+			"chatId":    msg.ChatID.String(),
+			"messageId": msg.ID.String(),
+			"location":  loc,
+		},
+	}
+	if payload, err := json.Marshal(event); err == nil {
+		s.hub.SendToRoom("chat:"+msg.ChatID.String(), payload, uuid.Nil)
+	}
+
+	return updated, nil
+}
+
+// checkEditable enforces the rules shared by chat and topic message edits:
+// only the sender may edit a live text, image or file message within the edit window.
+func checkEditable(senderID, userID uuid.UUID, msgType model.MessageType, isDeleted bool, createdAt time.Time, content string, window time.Duration) error {
+	if senderID != userID {
+		return apperror.Forbidden("only the sender can edit a message")
+	}
+	if isDeleted {
+		return apperror.BadRequest("cannot edit a deleted message")
+	}
+	switch msgType {
+	case model.MessageTypeText:
+		if content == "" {
+			return apperror.Validation("content", "message content cannot be empty")
+		}
+	case model.MessageTypeImage, model.MessageTypeFile:
+	default:
+		return apperror.BadRequest("this message type cannot be edited")
+	}
+	if time.Since(createdAt) > window {
+		return apperror.BadRequest(fmt.Sprintf("can only edit within %s of sending", window))
+	}
+	return nil
+}
+
+// chatMemberSet indexes chat members by user ID.
+func chatMemberSet(members []*model.ChatMember) map[uuid.UUID]bool {
+	set := make(map[uuid.UUID]bool, len(members))
+	for _, m := range members {
+		set[m.UserID] = true
+	}
+	return set
+}
+
+func (s *messageService) GetThread(ctx context.Context, chatID, messageID, userID uuid.UUID, cursor string, limit int) (*ThreadPage, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	var cursorTime *time.Time
+	if cursor != "" {
+		t, err := time.Parse(time.RFC3339Nano, cursor)
+		if err != nil {
+			return nil, apperror.BadRequest("invalid cursor format")
+		}
+		cursorTime = &t
+	}
+
+	root, err := s.findThreadRoot(ctx, chatID, messageID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch limit+1 to check if there are more
+	replies, err := s.messageRepo.ListThread(ctx, root.ID, cursorTime, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("list thread: %w", err)
+	}
+
+	hasMore := len(replies) > limit
+	if hasMore {
+		replies = replies[:limit]
+	}
+
+	if err := s.decorate(ctx, append([]*model.Message{root}, replies...)); err != nil {
+		return nil, err
+	}
+
+	following, err := s.threadRepo.IsFollowing(ctx, root.ID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("check thread follower: %w", err)
+	}
+
+	var nextCursor string
+	if hasMore && len(replies) > 0 {
+		nextCursor = replies[len(replies)-1].CreatedAt.Format(time.RFC3339Nano)
+	}
+
+	return &ThreadPage{
+		Root:      root,
+		Replies:   replies,
+		Following: following,
+		Cursor:    nextCursor,
+		HasMore:   hasMore,
+	}, nil
+}
+
+func (s *messageService) FollowThread(ctx context.Context, chatID, messageID, userID uuid.UUID) error {
+	root, err := s.findThreadRoot(ctx, chatID, messageID, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.threadRepo.Follow(ctx, root.ID, userID); err != nil {
+		return fmt.Errorf("follow thread: %w", err)
+	}
+	return nil
+}
+
+func (s *messageService) UnfollowThread(ctx context.Context, chatID, messageID, userID uuid.UUID) error {
+	root, err := s.findThreadRoot(ctx, chatID, messageID, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.threadRepo.Unfollow(ctx, root.ID, userID)
+}
+
+// findThreadRoot resolves the root of the thread a message belongs to, checking
+// that the user is a member of the chat.
+func (s *messageService) findThreadRoot(ctx context.Context, chatID, messageID, userID uuid.UUID) (*model.Message, error) {
+	msg, err := s.findMemberMessage(ctx, chatID, messageID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if msg.ThreadID == nil {
+		return msg, nil
+	}
+
+	root, err := s.messageRepo.FindByID(ctx, *msg.ThreadID)
+	if err != nil {
+		return nil, fmt.Errorf("find thread root: %w", err)
+	}
+	return root, nil
+}
+
+// requireNotBlocked rejects messages in a personal chat whose members have
+// blocked one another. Blocks do not stop messages in groups.
+func (s *messageService) requireNotBlocked(ctx context.Context, chatID, senderID uuid.UUID, members []*model.ChatMember) error {
+	if s.blockRepo == nil || len(members) != 2 {
+		return nil
+	}
+	chat, err := s.chatRepo.FindByID(ctx, chatID)
+	if err != nil {
+		return fmt.Errorf("find chat: %w", err)
+	}
+	if chat.Type != model.ChatTypePersonal {
+		return nil
+	}
+
+	for _, m := range members {
+		if m.UserID == senderID {
+			continue
+		}
+		blocked, err := isBlocked(ctx, s.blockRepo, senderID, m.UserID)
+		if err != nil {
+			return err
+		}
+		if blocked {
+			return apperror.Forbidden("you cannot message this user")
+		}
+	}
+	return nil
+}
+
+// requireSendPermission rejects senders who are not admins when the chat is
+// a group that only lets admins send messages.
+func (s *messageService) requireSendPermission(ctx context.Context, chatID, senderID uuid.UUID, members []*model.ChatMember) error {
+	for _, m := range members {
+		if m.UserID != senderID {
+			continue
+		}
+		if m.Role.IsAdmin() {
+			return nil
+		}
+		chat, err := s.chatRepo.FindByID(ctx, chatID)
+		if err != nil {
+			return fmt.Errorf("find chat: %w", err)
+		}
+		if chat.Type == model.ChatTypeGroup && chat.Permissions.OnlyAdminsSend {
+			return apperror.Forbidden("only admins can send messages in this group")
+		}
+		return nil
+	}
+	return nil
+}
+
+// joinThread makes the replier and the thread starter follow the thread and
+// returns the followers that are still members of the chat.
+func (s *messageService) joinThread(ctx context.Context, rootID, senderID uuid.UUID, memberIDs map[uuid.UUID]bool) ([]uuid.UUID, error) {
+	if err := s.threadRepo.Follow(ctx, rootID, senderID); err != nil {
+		return nil, fmt.Errorf("follow thread: %w", err)
+	}
+
+	root, err := s.messageRepo.FindByID(ctx, rootID)
+	if err != nil {
+		return nil, fmt.Errorf("find thread root: %w", err)
+	}
+	// Only auto-follow the starter on the first reply, so unfollowing sticks
+	if root.SenderID != senderID {
+		summaries, err := s.messageRepo.ThreadSummaries(ctx, []uuid.UUID{rootID})
+		if err != nil {
+			return nil, fmt.Errorf("summarize thread: %w", err)
+		}
+		if summary := summaries[rootID]; summary == nil || summary.ReplyCount <= 1 {
+			if err := s.threadRepo.Follow(ctx, rootID, root.SenderID); err != nil {
+				return nil, fmt.Errorf("follow thread: %w", err)
+			}
+		}
+	}
+
+	followers, err := s.threadRepo.ListFollowers(ctx, rootID)
+	if err != nil {
+		return nil, fmt.Errorf("list thread followers: %w", err)
+	}
+
+	members := make([]uuid.UUID, 0, len(followers))
+	for _, id := range followers {
+		if memberIDs[id] {
+			members = append(members, id)
+		}
+	}
+	return members, nil
+}
+
+// decorate attaches aggregated reactions, mentions and thread summaries to messages.
+func (s *messageService) decorate(ctx context.Context, messages []*model.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(messages))
+	for i, m := range messages {
+		ids[i] = m.ID
+	}
+
+	reactions, err := s.reactionRepo.ListByMessages(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("list reactions: %w", err)
+	}
+	for _, m := range messages {
+		if r := reactions[m.ID]; len(r) > 0 {
+			m.Reactions = model.SummarizeReactions(r)
+		}
+	}
+
+	if s.mentionSvc != nil {
+		mentions, err := s.mentionSvc.ForItems(ctx, ids)
+		if err != nil {
+			return err
+		}
+		for _, m := range messages {
+			m.Mentions = mentions[m.ID]
+		}
+	}
+
+	threads, err := s.messageRepo.ThreadSummaries(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("summarize threads: %w", err)
+	}
+	for _, m := range messages {
+		if t := threads[m.ID]; t != nil {
+			m.ReplyCount = t.ReplyCount
+			lastReplyAt := t.LastReplyAt
+			m.LastReplyAt = &lastReplyAt
+		}
+	}
+
+	return nil
+}
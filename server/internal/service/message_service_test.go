@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -11,6 +13,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/internal/ws"
+	"github.com/otoritech/chatat/pkg/apperror"
 )
 
 func TestMessageService_SendMessage(t *testing.T) {
@@ -20,7 +24,7 @@ func TestMessageService_SendMessage(t *testing.T) {
 	hub := newTestHub()
 	defer hub.Shutdown()
 
-	svc := NewMessageService(msgRepo, msgStatRepo, chatRepo, nil, hub, nil)
+	svc := NewMessageService(msgRepo, msgStatRepo, newMockReactionRepo(), newMockThreadRepo(), chatRepo, nil, nil, hub, nil, nil, DefaultMessageConfig())
 
 	// Create a chat with two members
 	userA := uuid.New()
@@ -65,6 +69,92 @@ func TestMessageService_SendMessage(t *testing.T) {
 	})
 }
 
+func TestMessageService_SendMessage_OnlyAdmins(t *testing.T) {
+	ctx := context.Background()
+	chatRepo := newMockChatRepo()
+	msgRepo := newMockMessageRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	svc := NewMessageService(msgRepo, newMockMessageStatRepo(), newMockReactionRepo(), newMockThreadRepo(), chatRepo, nil, nil, hub, nil, nil, DefaultMessageConfig())
+
+	owner, admin, member := uuid.New(), uuid.New(), uuid.New()
+	chat := &model.Chat{
+		ID:          uuid.New(),
+		Type:        model.ChatTypeGroup,
+		CreatedBy:   owner,
+		Permissions: model.GroupPermissions{OnlyAdminsSend: true},
+	}
+	chatRepo.chats[chat.ID] = chat
+	_ = chatRepo.AddMember(ctx, chat.ID, owner, model.MemberRoleOwner)
+	_ = chatRepo.AddMember(ctx, chat.ID, admin, model.MemberRoleAdmin)
+	_ = chatRepo.AddMember(ctx, chat.ID, member, model.MemberRoleMember)
+
+	send := func(senderID uuid.UUID) error {
+		_, err := svc.SendMessage(ctx, SendMessageInput{ChatID: chat.ID, SenderID: senderID, Content: "Hello"})
+		return err
+	}
+
+	require.NoError(t, send(owner))
+	require.NoError(t, send(admin))
+
+	err := send(member)
+	require.Error(t, err)
+	assert.True(t, apperror.IsForbidden(err))
+
+	original := msgRepo.byChat[chat.ID][0]
+	_, err = svc.ForwardMessage(ctx, original.ID, member, chat.ID)
+	assert.True(t, apperror.IsForbidden(err))
+
+	chat.Permissions.OnlyAdminsSend = false
+	require.NoError(t, send(member))
+}
+
+func TestMessageService_SendMessage_Blocked(t *testing.T) {
+	ctx := context.Background()
+	chatRepo := newMockChatRepo()
+	msgRepo := newMockMessageRepo()
+	blockRepo := newMockUserBlockRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	svc := NewMessageService(msgRepo, newMockMessageStatRepo(), newMockReactionRepo(), newMockThreadRepo(), chatRepo, nil, blockRepo, hub, nil, nil, DefaultMessageConfig())
+
+	userA := uuid.New()
+	userB := uuid.New()
+	personal := &model.Chat{ID: uuid.New(), Type: model.ChatTypePersonal, CreatedBy: userA}
+	group := &model.Chat{ID: uuid.New(), Type: model.ChatTypeGroup, CreatedBy: userA}
+	for _, c := range []*model.Chat{personal, group} {
+		chatRepo.chats[c.ID] = c
+		_ = chatRepo.AddMember(ctx, c.ID, userA, model.MemberRoleAdmin)
+		_ = chatRepo.AddMember(ctx, c.ID, userB, model.MemberRoleMember)
+	}
+	original, err := msgRepo.Create(ctx, model.CreateMessageInput{ChatID: group.ID, SenderID: userA, Content: "fwd me"})
+	require.NoError(t, err)
+	_ = blockRepo.Block(ctx, userB, userA)
+
+	t.Run("blocked in personal chat", func(t *testing.T) {
+		for _, sender := range []uuid.UUID{userA, userB} {
+			_, err := svc.SendMessage(ctx, SendMessageInput{ChatID: personal.ID, SenderID: sender, Content: "hi"})
+			require.Error(t, err)
+			appErr, ok := err.(*apperror.AppError)
+			require.True(t, ok)
+			assert.Equal(t, 403, appErr.HTTPStatus)
+		}
+	})
+
+	t.Run("forward into personal chat", func(t *testing.T) {
+		_, err := svc.ForwardMessage(ctx, original.ID, userA, personal.ID)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot message")
+	})
+
+	t.Run("groups are not affected", func(t *testing.T) {
+		_, err := svc.SendMessage(ctx, SendMessageInput{ChatID: group.ID, SenderID: userA, Content: "hi all"})
+		require.NoError(t, err)
+	})
+}
+
 func TestMessageService_GetMessages(t *testing.T) {
 	chatRepo := newMockChatRepo()
 	msgRepo := newMockMessageRepo()
@@ -72,7 +162,7 @@ func TestMessageService_GetMessages(t *testing.T) {
 	hub := newTestHub()
 	defer hub.Shutdown()
 
-	svc := NewMessageService(msgRepo, msgStatRepo, chatRepo, nil, hub, nil)
+	svc := NewMessageService(msgRepo, msgStatRepo, newMockReactionRepo(), newMockThreadRepo(), chatRepo, nil, nil, hub, nil, nil, DefaultMessageConfig())
 
 	chatID := uuid.New()
 	userA := uuid.New()
@@ -110,7 +200,7 @@ func TestMessageService_ReplyMessage(t *testing.T) {
 	hub := newTestHub()
 	defer hub.Shutdown()
 
-	svc := NewMessageService(msgRepo, msgStatRepo, chatRepo, nil, hub, nil)
+	svc := NewMessageService(msgRepo, msgStatRepo, newMockReactionRepo(), newMockThreadRepo(), chatRepo, nil, nil, hub, nil, nil, DefaultMessageConfig())
 
 	userA := uuid.New()
 	userB := uuid.New()
@@ -160,7 +250,7 @@ func TestMessageService_ForwardMessage(t *testing.T) {
 	hub := newTestHub()
 	defer hub.Shutdown()
 
-	svc := NewMessageService(msgRepo, msgStatRepo, chatRepo, nil, hub, nil)
+	svc := NewMessageService(msgRepo, msgStatRepo, newMockReactionRepo(), newMockThreadRepo(), chatRepo, nil, nil, hub, nil, nil, DefaultMessageConfig())
 
 	userA := uuid.New()
 	userB := uuid.New()
@@ -209,7 +299,7 @@ func TestMessageService_DeleteMessage(t *testing.T) {
 	hub := newTestHub()
 	defer hub.Shutdown()
 
-	svc := NewMessageService(msgRepo, msgStatRepo, chatRepo, nil, hub, nil)
+	svc := NewMessageService(msgRepo, msgStatRepo, newMockReactionRepo(), newMockThreadRepo(), chatRepo, nil, nil, hub, nil, nil, DefaultMessageConfig())
 
 	userA := uuid.New()
 	userB := uuid.New()
@@ -267,13 +357,80 @@ func TestMessageService_MarkChatAsRead(t *testing.T) {
 	hub := newTestHub()
 	defer hub.Shutdown()
 
-	svc := NewMessageService(msgRepo, msgStatRepo, chatRepo, nil, hub, nil)
+	svc := NewMessageService(msgRepo, msgStatRepo, newMockReactionRepo(), newMockThreadRepo(), chatRepo, nil, nil, hub, nil, nil, DefaultMessageConfig())
 
 	chatID := uuid.New()
 	userA := uuid.New()
 
-	err := svc.MarkChatAsRead(context.Background(), chatID, userA)
+	err := svc.MarkChatAsRead(context.Background(), chatID, userA, nil)
+	require.NoError(t, err)
+}
+
+func TestMessageService_GetReceipts(t *testing.T) {
+	ctx := context.Background()
+	chatRepo := newMockChatRepo()
+	msgStatRepo := newMockMessageStatRepo()
+	userRepo := newMockUserRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	svc := NewMessageService(newMockMessageRepo(), msgStatRepo, newMockReactionRepo(), newMockThreadRepo(), chatRepo, userRepo, nil, hub, nil, nil, DefaultMessageConfig())
+
+	sender := uuid.New()
+	reader := uuid.New()
+	private := uuid.New()
+	hidden := model.DefaultPrivacySettings()
+	hidden.ReadReceipts = false
+	userRepo.addUser(&model.User{ID: sender, Name: "Sender", PrivacySettings: model.DefaultPrivacySettings()})
+	userRepo.addUser(&model.User{ID: reader, Name: "Reader", PrivacySettings: model.DefaultPrivacySettings()})
+	userRepo.addUser(&model.User{ID: private, Name: "Private", PrivacySettings: hidden})
+
+	chat := &model.Chat{ID: uuid.New(), Type: model.ChatTypeGroup, CreatedBy: sender}
+	chatRepo.chats[chat.ID] = chat
+	for _, id := range []uuid.UUID{sender, reader, private} {
+		_ = chatRepo.AddMember(ctx, chat.ID, id, model.MemberRoleMember)
+	}
+
+	msg, err := svc.SendMessage(ctx, SendMessageInput{ChatID: chat.ID, SenderID: sender, Content: "Rapat jam 3"})
 	require.NoError(t, err)
+
+	now := time.Now()
+	for _, id := range []uuid.UUID{reader, private} {
+		st := msgStatRepo.statuses[msg.ID.String()+":"+id.String()]
+		require.NotNil(t, st)
+		st.Status = model.DeliveryStatusRead
+		st.DeliveredAt = &now
+		st.ReadAt = &now
+	}
+
+	t.Run("sender sees receipts", func(t *testing.T) {
+		receipts, err := svc.GetReceipts(ctx, chat.ID, msg.ID, sender)
+		require.NoError(t, err)
+		require.Len(t, receipts, 2)
+
+		byUser := make(map[uuid.UUID]*model.MessageReceipt)
+		for _, r := range receipts {
+			byUser[r.User.ID] = r
+		}
+		assert.Equal(t, model.DeliveryStatusRead, byUser[reader].Status)
+		assert.NotNil(t, byUser[reader].ReadAt)
+
+		// Read receipts turned off: only the delivery shows
+		assert.Equal(t, model.DeliveryStatusDelivered, byUser[private].Status)
+		assert.Nil(t, byUser[private].ReadAt)
+		assert.NotNil(t, byUser[private].DeliveredAt)
+	})
+
+	t.Run("other members cannot", func(t *testing.T) {
+		_, err := svc.GetReceipts(ctx, chat.ID, msg.ID, reader)
+		require.Error(t, err)
+		assert.True(t, apperror.IsForbidden(err))
+	})
+
+	t.Run("non member", func(t *testing.T) {
+		_, err := svc.GetReceipts(ctx, chat.ID, msg.ID, uuid.New())
+		assert.True(t, apperror.IsForbidden(err))
+	})
 }
 
 func TestMessageService_SearchMessages(t *testing.T) {
@@ -283,7 +440,7 @@ func TestMessageService_SearchMessages(t *testing.T) {
 	hub := newTestHub()
 	defer hub.Shutdown()
 
-	svc := NewMessageService(msgRepo, msgStatRepo, chatRepo, nil, hub, nil)
+	svc := NewMessageService(msgRepo, msgStatRepo, newMockReactionRepo(), newMockThreadRepo(), chatRepo, nil, nil, hub, nil, nil, DefaultMessageConfig())
 
 	chatID := uuid.New()
 
@@ -307,7 +464,7 @@ func TestMessageService_SendMessage_Errors(t *testing.T) {
 	t.Run("get members error", func(t *testing.T) {
 		chatRepo := newMockChatRepo()
 		chatRepo.getMembersErr = fmt.Errorf("db error")
-		svc := NewMessageService(newMockMessageRepo(), newMockMessageStatRepo(), chatRepo, nil, hub, nil)
+		svc := NewMessageService(newMockMessageRepo(), newMockMessageStatRepo(), newMockReactionRepo(), newMockThreadRepo(), chatRepo, nil, nil, hub, nil, nil, DefaultMessageConfig())
 		_, err := svc.SendMessage(context.Background(), SendMessageInput{
 			ChatID: uuid.New(), SenderID: uuid.New(), Content: "Hi", Type: model.MessageTypeText,
 		})
@@ -315,6 +472,15 @@ func TestMessageService_SendMessage_Errors(t *testing.T) {
 		assert.Contains(t, err.Error(), "get chat members")
 	})
 
+	t.Run("poll type rejected", func(t *testing.T) {
+		svc := NewMessageService(newMockMessageRepo(), newMockMessageStatRepo(), newMockReactionRepo(), newMockThreadRepo(), newMockChatRepo(), nil, nil, hub, nil, nil, DefaultMessageConfig())
+		_, err := svc.SendMessage(context.Background(), SendMessageInput{
+			ChatID: uuid.New(), SenderID: uuid.New(), Content: "Lunch?", Type: model.MessageTypePoll,
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "polls endpoint")
+	})
+
 	t.Run("create message error", func(t *testing.T) {
 		chatRepo := newMockChatRepo()
 		msgRepo := newMockMessageRepo()
@@ -324,7 +490,7 @@ func TestMessageService_SendMessage_Errors(t *testing.T) {
 		_ = chatRepo.AddMember(context.Background(), chat.ID, userA, model.MemberRoleAdmin)
 
 		msgRepo.createErr = fmt.Errorf("db error")
-		svc := NewMessageService(msgRepo, newMockMessageStatRepo(), chatRepo, nil, hub, nil)
+		svc := NewMessageService(msgRepo, newMockMessageStatRepo(), newMockReactionRepo(), newMockThreadRepo(), chatRepo, nil, nil, hub, nil, nil, DefaultMessageConfig())
 		_, err := svc.SendMessage(context.Background(), SendMessageInput{
 			ChatID: chat.ID, SenderID: userA, Content: "Hi", Type: model.MessageTypeText,
 		})
@@ -345,7 +511,7 @@ func TestMessageService_SendMessage_Errors(t *testing.T) {
 		chatRepo.chats[chat2.ID] = chat2
 		_ = chatRepo.AddMember(context.Background(), chat2.ID, userA, model.MemberRoleAdmin)
 
-		svc := NewMessageService(msgRepo, newMockMessageStatRepo(), chatRepo, nil, hub, nil)
+		svc := NewMessageService(msgRepo, newMockMessageStatRepo(), newMockReactionRepo(), newMockThreadRepo(), chatRepo, nil, nil, hub, nil, nil, DefaultMessageConfig())
 		original, err := svc.SendMessage(context.Background(), SendMessageInput{
 			ChatID: chat1.ID, SenderID: userA, Content: "Original", Type: model.MessageTypeText,
 		})
@@ -366,7 +532,7 @@ func TestMessageService_SendMessage_Errors(t *testing.T) {
 		chatRepo.chats[chat.ID] = chat
 		_ = chatRepo.AddMember(context.Background(), chat.ID, userA, model.MemberRoleAdmin)
 
-		svc := NewMessageService(newMockMessageRepo(), newMockMessageStatRepo(), chatRepo, nil, hub, nil)
+		svc := NewMessageService(newMockMessageRepo(), newMockMessageStatRepo(), newMockReactionRepo(), newMockThreadRepo(), chatRepo, nil, nil, hub, nil, nil, DefaultMessageConfig())
 		msg, err := svc.SendMessage(context.Background(), SendMessageInput{
 			ChatID: chat.ID, SenderID: userA, Content: "Hi",
 		})
@@ -380,7 +546,7 @@ func TestMessageService_GetMessages_Errors(t *testing.T) {
 	defer hub.Shutdown()
 
 	t.Run("invalid cursor", func(t *testing.T) {
-		svc := NewMessageService(newMockMessageRepo(), newMockMessageStatRepo(), newMockChatRepo(), nil, hub, nil)
+		svc := NewMessageService(newMockMessageRepo(), newMockMessageStatRepo(), newMockReactionRepo(), newMockThreadRepo(), newMockChatRepo(), nil, nil, hub, nil, nil, DefaultMessageConfig())
 		_, err := svc.GetMessages(context.Background(), uuid.New(), "not-a-time", 10)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "invalid cursor")
@@ -389,21 +555,21 @@ func TestMessageService_GetMessages_Errors(t *testing.T) {
 	t.Run("list error", func(t *testing.T) {
 		msgRepo := newMockMessageRepo()
 		msgRepo.listErr = fmt.Errorf("db error")
-		svc := NewMessageService(msgRepo, newMockMessageStatRepo(), newMockChatRepo(), nil, hub, nil)
+		svc := NewMessageService(msgRepo, newMockMessageStatRepo(), newMockReactionRepo(), newMockThreadRepo(), newMockChatRepo(), nil, nil, hub, nil, nil, DefaultMessageConfig())
 		_, err := svc.GetMessages(context.Background(), uuid.New(), "", 10)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "list messages")
 	})
 
 	t.Run("default limit", func(t *testing.T) {
-		svc := NewMessageService(newMockMessageRepo(), newMockMessageStatRepo(), newMockChatRepo(), nil, hub, nil)
+		svc := NewMessageService(newMockMessageRepo(), newMockMessageStatRepo(), newMockReactionRepo(), newMockThreadRepo(), newMockChatRepo(), nil, nil, hub, nil, nil, DefaultMessageConfig())
 		page, err := svc.GetMessages(context.Background(), uuid.New(), "", 0)
 		require.NoError(t, err)
 		assert.Empty(t, page.Messages)
 	})
 
 	t.Run("with valid cursor", func(t *testing.T) {
-		svc := NewMessageService(newMockMessageRepo(), newMockMessageStatRepo(), newMockChatRepo(), nil, hub, nil)
+		svc := NewMessageService(newMockMessageRepo(), newMockMessageStatRepo(), newMockReactionRepo(), newMockThreadRepo(), newMockChatRepo(), nil, nil, hub, nil, nil, DefaultMessageConfig())
 		cursor := time.Now().Format(time.RFC3339Nano)
 		page, err := svc.GetMessages(context.Background(), uuid.New(), cursor, 10)
 		require.NoError(t, err)
@@ -416,7 +582,7 @@ func TestMessageService_ForwardMessage_Errors(t *testing.T) {
 	defer hub.Shutdown()
 
 	t.Run("original not found", func(t *testing.T) {
-		svc := NewMessageService(newMockMessageRepo(), newMockMessageStatRepo(), newMockChatRepo(), nil, hub, nil)
+		svc := NewMessageService(newMockMessageRepo(), newMockMessageStatRepo(), newMockReactionRepo(), newMockThreadRepo(), newMockChatRepo(), nil, nil, hub, nil, nil, DefaultMessageConfig())
 		_, err := svc.ForwardMessage(context.Background(), uuid.New(), uuid.New(), uuid.New())
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "find original message")
@@ -431,7 +597,7 @@ func TestMessageService_ForwardMessage_Errors(t *testing.T) {
 		chatRepo.chats[chat.ID] = chat
 		_ = chatRepo.AddMember(context.Background(), chat.ID, userA, model.MemberRoleAdmin)
 
-		svc := NewMessageService(msgRepo, newMockMessageStatRepo(), chatRepo, nil, hub, nil)
+		svc := NewMessageService(msgRepo, newMockMessageStatRepo(), newMockReactionRepo(), newMockThreadRepo(), chatRepo, nil, nil, hub, nil, nil, DefaultMessageConfig())
 		msg, _ := svc.SendMessage(context.Background(), SendMessageInput{
 			ChatID: chat.ID, SenderID: userA, Content: "Hi", Type: model.MessageTypeText,
 		})
@@ -448,7 +614,7 @@ func TestMessageService_DeleteMessage_Errors(t *testing.T) {
 	defer hub.Shutdown()
 
 	t.Run("message not found", func(t *testing.T) {
-		svc := NewMessageService(newMockMessageRepo(), newMockMessageStatRepo(), newMockChatRepo(), nil, hub, nil)
+		svc := NewMessageService(newMockMessageRepo(), newMockMessageStatRepo(), newMockReactionRepo(), newMockThreadRepo(), newMockChatRepo(), nil, nil, hub, nil, nil, DefaultMessageConfig())
 		err := svc.DeleteMessage(context.Background(), uuid.New(), uuid.New(), false)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "find message")
@@ -461,7 +627,7 @@ func TestMessageService_SearchMessages_Error(t *testing.T) {
 
 	msgRepo := newMockMessageRepo()
 	msgRepo.searchErr = fmt.Errorf("db error")
-	svc := NewMessageService(msgRepo, newMockMessageStatRepo(), newMockChatRepo(), nil, hub, nil)
+	svc := NewMessageService(msgRepo, newMockMessageStatRepo(), newMockReactionRepo(), newMockThreadRepo(), newMockChatRepo(), nil, nil, hub, nil, nil, DefaultMessageConfig())
 	_, err := svc.SearchMessages(context.Background(), uuid.New(), "hello")
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "search messages")
@@ -473,8 +639,8 @@ func TestMessageService_MarkChatAsRead_Error(t *testing.T) {
 
 	msgStatRepo := newMockMessageStatRepo()
 	msgStatRepo.markReadErr = fmt.Errorf("db error")
-	svc := NewMessageService(newMockMessageRepo(), msgStatRepo, newMockChatRepo(), nil, hub, nil)
-	err := svc.MarkChatAsRead(context.Background(), uuid.New(), uuid.New())
+	svc := NewMessageService(newMockMessageRepo(), msgStatRepo, newMockReactionRepo(), newMockThreadRepo(), newMockChatRepo(), nil, nil, hub, nil, nil, DefaultMessageConfig())
+	err := svc.MarkChatAsRead(context.Background(), uuid.New(), uuid.New(), nil)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "mark chat as read")
 }
@@ -497,7 +663,7 @@ func TestMessageService_SendMessage_WithNotifSvc(t *testing.T) {
 		_ = chatRepo.AddMember(context.Background(), chat.ID, userA, model.MemberRoleAdmin)
 		_ = chatRepo.AddMember(context.Background(), chat.ID, userB, model.MemberRoleMember)
 
-		svc := NewMessageService(msgRepo, newMockMessageStatRepo(), chatRepo, userRepo, hub, notif)
+		svc := NewMessageService(msgRepo, newMockMessageStatRepo(), newMockReactionRepo(), newMockThreadRepo(), chatRepo, userRepo, nil, hub, notif, nil, DefaultMessageConfig())
 		msg, err := svc.SendMessage(context.Background(), SendMessageInput{
 			ChatID: chat.ID, SenderID: userA, Content: "Hi Bob", Type: model.MessageTypeText,
 		})
@@ -516,7 +682,7 @@ func TestMessageService_SendMessage_WithNotifSvc(t *testing.T) {
 		chatRepo.chats[chat.ID] = chat
 		_ = chatRepo.AddMember(context.Background(), chat.ID, userA, model.MemberRoleAdmin)
 
-		svc := NewMessageService(msgRepo, newMockMessageStatRepo(), chatRepo, userRepo, hub, notif)
+		svc := NewMessageService(msgRepo, newMockMessageStatRepo(), newMockReactionRepo(), newMockThreadRepo(), chatRepo, userRepo, nil, hub, notif, nil, DefaultMessageConfig())
 		msg, err := svc.SendMessage(context.Background(), SendMessageInput{
 			ChatID: chat.ID, SenderID: userA, Content: "Hello team", Type: model.MessageTypeText,
 		})
@@ -535,7 +701,7 @@ func TestMessageService_SendMessage_WithNotifSvc(t *testing.T) {
 		chatRepo.chats[chat.ID] = chat
 		_ = chatRepo.AddMember(context.Background(), chat.ID, userA, model.MemberRoleAdmin)
 
-		svc := NewMessageService(msgRepo, newMockMessageStatRepo(), chatRepo, userRepo, hub, notif)
+		svc := NewMessageService(msgRepo, newMockMessageStatRepo(), newMockReactionRepo(), newMockThreadRepo(), chatRepo, userRepo, nil, hub, notif, nil, DefaultMessageConfig())
 		msg, err := svc.SendMessage(context.Background(), SendMessageInput{
 			ChatID: chat.ID, SenderID: userA, Content: "anon msg",
 		})
@@ -561,7 +727,7 @@ func TestMessageService_ForwardMessage_CreateError(t *testing.T) {
 	chatRepo.chats[chat2.ID] = chat2
 	_ = chatRepo.AddMember(context.Background(), chat2.ID, userA, model.MemberRoleAdmin)
 
-	svc := NewMessageService(msgRepo, newMockMessageStatRepo(), chatRepo, nil, hub, nil)
+	svc := NewMessageService(msgRepo, newMockMessageStatRepo(), newMockReactionRepo(), newMockThreadRepo(), chatRepo, nil, nil, hub, nil, nil, DefaultMessageConfig())
 	original, err := svc.SendMessage(context.Background(), SendMessageInput{
 		ChatID: chat1.ID, SenderID: userA, Content: "Fwd me", Type: model.MessageTypeText,
 	})
@@ -586,7 +752,7 @@ func TestMessageService_DeleteMessage_MarkError(t *testing.T) {
 	chatRepo.chats[chat.ID] = chat
 	_ = chatRepo.AddMember(context.Background(), chat.ID, userA, model.MemberRoleAdmin)
 
-	svc := NewMessageService(msgRepo, newMockMessageStatRepo(), chatRepo, nil, hub, nil)
+	svc := NewMessageService(msgRepo, newMockMessageStatRepo(), newMockReactionRepo(), newMockThreadRepo(), chatRepo, nil, nil, hub, nil, nil, DefaultMessageConfig())
 	msg, err := svc.SendMessage(context.Background(), SendMessageInput{
 		ChatID: chat.ID, SenderID: userA, Content: "Hi", Type: model.MessageTypeText,
 	})
@@ -598,3 +764,603 @@ func TestMessageService_DeleteMessage_MarkError(t *testing.T) {
 	assert.Contains(t, err.Error(), "mark as deleted")
 	msgRepo.markDelErr = nil
 }
+
+func TestMessageService_Reactions(t *testing.T) {
+	chatRepo := newMockChatRepo()
+	msgRepo := newMockMessageRepo()
+	reactionRepo := newMockReactionRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	svc := NewMessageService(msgRepo, newMockMessageStatRepo(), reactionRepo, newMockThreadRepo(), chatRepo, nil, nil, hub, nil, nil, DefaultMessageConfig())
+
+	userA := uuid.New()
+	userB := uuid.New()
+	chat := &model.Chat{ID: uuid.New(), Type: model.ChatTypeGroup, CreatedBy: userA}
+	chatRepo.chats[chat.ID] = chat
+	_ = chatRepo.AddMember(context.Background(), chat.ID, userA, model.MemberRoleAdmin)
+	_ = chatRepo.AddMember(context.Background(), chat.ID, userB, model.MemberRoleMember)
+
+	msg, err := svc.SendMessage(context.Background(), SendMessageInput{
+		ChatID: chat.ID, SenderID: userA, Content: "React to me",
+	})
+	require.NoError(t, err)
+
+	observer := &ws.Client{UserID: userB, DeviceID: "d1", Send: make(chan []byte, 16), Hub: hub}
+	hub.RegisterClient(observer)
+	time.Sleep(20 * time.Millisecond)
+	hub.JoinRoom(observer, "chat:"+chat.ID.String())
+
+	t.Run("add aggregates per emoji", func(t *testing.T) {
+		_, err := svc.AddReaction(context.Background(), chat.ID, msg.ID, userA, "👍")
+		require.NoError(t, err)
+		summaries, err := svc.AddReaction(context.Background(), chat.ID, msg.ID, userB, "👍")
+		require.NoError(t, err)
+
+		require.Len(t, summaries, 1)
+		assert.Equal(t, "👍", summaries[0].Emoji)
+		assert.Equal(t, 2, summaries[0].Count)
+		assert.ElementsMatch(t, []uuid.UUID{userA, userB}, summaries[0].UserIDs)
+
+		select {
+		case data := <-observer.Send:
+			assert.Contains(t, string(data), `"type":"message_reaction"`)
+			assert.Contains(t, string(data), `"action":"added"`)
+		case <-time.After(time.Second):
+			t.Fatal("expected message_reaction event")
+		}
+	})
+
+	t.Run("adding twice is idempotent", func(t *testing.T) {
+		for len(observer.Send) > 0 {
+			<-observer.Send
+		}
+		summaries, err := svc.AddReaction(context.Background(), chat.ID, msg.ID, userA, "👍")
+		require.NoError(t, err)
+		assert.Equal(t, 2, summaries[0].Count)
+
+		select {
+		case data := <-observer.Send:
+			t.Fatalf("unexpected event for a repeated reaction: %s", data)
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	t.Run("GetMessages includes reactions", func(t *testing.T) {
+		page, err := svc.GetMessages(context.Background(), chat.ID, "", 50)
+		require.NoError(t, err)
+		require.Len(t, page.Messages, 1)
+		require.Len(t, page.Messages[0].Reactions, 1)
+		assert.Equal(t, 2, page.Messages[0].Reactions[0].Count)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		summaries, err := svc.RemoveReaction(context.Background(), chat.ID, msg.ID, userB, "👍")
+		require.NoError(t, err)
+		require.Len(t, summaries, 1)
+		assert.Equal(t, 1, summaries[0].Count)
+
+		_, err = svc.RemoveReaction(context.Background(), chat.ID, msg.ID, userB, "👍")
+		assert.True(t, apperror.IsNotFound(err))
+	})
+
+	t.Run("non-member cannot react", func(t *testing.T) {
+		_, err := svc.AddReaction(context.Background(), chat.ID, msg.ID, uuid.New(), "🔥")
+		require.Error(t, err)
+	})
+
+	t.Run("message from another chat", func(t *testing.T) {
+		_, err := svc.AddReaction(context.Background(), uuid.New(), msg.ID, userA, "🔥")
+		require.Error(t, err)
+	})
+
+	t.Run("invalid emoji", func(t *testing.T) {
+		_, err := svc.AddReaction(context.Background(), chat.ID, msg.ID, userA, " ")
+		require.Error(t, err)
+		_, err = svc.AddReaction(context.Background(), chat.ID, msg.ID, userA, "not an emoji")
+		require.Error(t, err)
+		_, err = svc.AddReaction(context.Background(), chat.ID, msg.ID, userA, "abc")
+		require.Error(t, err)
+	})
+
+	t.Run("deleted for everyone", func(t *testing.T) {
+		require.NoError(t, svc.DeleteMessage(context.Background(), msg.ID, userA, true))
+		_, err := svc.AddReaction(context.Background(), chat.ID, msg.ID, userA, "🔥")
+		require.Error(t, err)
+	})
+}
+
+func TestValidateEmoji(t *testing.T) {
+	valid := []string{
+		"👍", " 🔥 ", "❤️", "☺", "👍🏽", "🇮🇩", "1️⃣", "#⃣",
+		"👨‍👩‍👧‍👦", "🏳️‍🌈", "👩🏾‍💻", "🏴󠁧󠁢󠁥󠁮󠁧󠁿",
+	}
+	for _, emoji := range valid {
+		_, err := validateEmoji(emoji)
+		assert.NoError(t, err, emoji)
+	}
+
+	invalid := []string{
+		"", "abc", "a", "1", "👍👍", "👍 🔥", "🇮", "🇮🇩🇮🇩", "👍a", "‍👍", "👍‍", "🏽", "🏴󠁧󠁢",
+	}
+	for _, emoji := range invalid {
+		_, err := validateEmoji(emoji)
+		assert.Error(t, err, emoji)
+	}
+}
+
+func TestMessageService_EditMessage(t *testing.T) {
+	chatRepo := newMockChatRepo()
+	msgRepo := newMockMessageRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	svc := NewMessageService(msgRepo, newMockMessageStatRepo(), newMockReactionRepo(), newMockThreadRepo(), chatRepo, nil, nil, hub, nil, nil, MessageConfig{EditWindow: time.Hour})
+
+	userA := uuid.New()
+	userB := uuid.New()
+	chat := &model.Chat{ID: uuid.New(), Type: model.ChatTypePersonal, CreatedBy: userA}
+	chatRepo.chats[chat.ID] = chat
+	_ = chatRepo.AddMember(context.Background(), chat.ID, userA, model.MemberRoleAdmin)
+	_ = chatRepo.AddMember(context.Background(), chat.ID, userB, model.MemberRoleMember)
+
+	msg, err := svc.SendMessage(context.Background(), SendMessageInput{
+		ChatID: chat.ID, SenderID: userA, Content: "Helo",
+	})
+	require.NoError(t, err)
+
+	observer := &ws.Client{UserID: userB, DeviceID: "d1", Send: make(chan []byte, 16), Hub: hub}
+	hub.RegisterClient(observer)
+	time.Sleep(20 * time.Millisecond)
+	hub.JoinRoom(observer, "chat:"+chat.ID.String())
+
+	t.Run("sender edits within window", func(t *testing.T) {
+		edited, err := svc.EditMessage(context.Background(), chat.ID, msg.ID, userA, "Hello")
+		require.NoError(t, err)
+		assert.Equal(t, "Hello", edited.Content)
+		require.NotNil(t, edited.EditedAt)
+
+		select {
+		case data := <-observer.Send:
+			assert.Contains(t, string(data), `"type":"message_edited"`)
+			assert.Contains(t, string(data), "Hello")
+		case <-time.After(time.Second):
+			t.Fatal("expected message_edited event")
+		}
+
+		history, err := svc.GetEditHistory(context.Background(), chat.ID, msg.ID, userB)
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		assert.Equal(t, "Helo", history[0].Content)
+	})
+
+	t.Run("other member cannot edit", func(t *testing.T) {
+		_, err := svc.EditMessage(context.Background(), chat.ID, msg.ID, userB, "Hacked")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only the sender")
+	})
+
+	t.Run("removed sender cannot edit", func(t *testing.T) {
+		reply, err := svc.SendMessage(context.Background(), SendMessageInput{
+			ChatID: chat.ID, SenderID: userB, Content: "Sampai nanti",
+		})
+		require.NoError(t, err)
+		require.NoError(t, chatRepo.RemoveMember(context.Background(), chat.ID, userB))
+		defer func() { _ = chatRepo.AddMember(context.Background(), chat.ID, userB, model.MemberRoleMember) }()
+
+		_, err = svc.EditMessage(context.Background(), chat.ID, reply.ID, userB, "Diubah")
+		assert.True(t, apperror.IsForbidden(err))
+	})
+
+	t.Run("empty content", func(t *testing.T) {
+		_, err := svc.EditMessage(context.Background(), chat.ID, msg.ID, userA, "")
+		require.Error(t, err)
+	})
+
+	t.Run("wrong chat", func(t *testing.T) {
+		_, err := svc.EditMessage(context.Background(), uuid.New(), msg.ID, userA, "Hi")
+		assert.True(t, apperror.IsNotFound(err))
+	})
+
+	t.Run("outside edit window", func(t *testing.T) {
+		old, err := svc.SendMessage(context.Background(), SendMessageInput{
+			ChatID: chat.ID, SenderID: userA, Content: "Old",
+		})
+		require.NoError(t, err)
+		old.CreatedAt = time.Now().Add(-2 * time.Hour)
+
+		_, err = svc.EditMessage(context.Background(), chat.ID, old.ID, userA, "New")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "can only edit within")
+	})
+
+	t.Run("deleted message", func(t *testing.T) {
+		require.NoError(t, svc.DeleteMessage(context.Background(), msg.ID, userA, true))
+		_, err := svc.EditMessage(context.Background(), chat.ID, msg.ID, userA, "Again")
+		require.Error(t, err)
+	})
+}
+
+func TestMessageService_SendMessage_Mentions(t *testing.T) {
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	chatRepo := newMockChatRepo()
+	userRepo := newMockUserRepo()
+	mentionRepo := newMockMentionRepo()
+	notif := &recordingNotifSvc{}
+	mentionSvc := NewMentionService(mentionRepo, userRepo, notif)
+	svc := NewMessageService(newMockMessageRepo(), newMockMessageStatRepo(), newMockReactionRepo(), newMockThreadRepo(), chatRepo, userRepo, nil, hub, notif, mentionSvc, DefaultMessageConfig())
+
+	alice := uuid.New()
+	bob := uuid.New()
+	carol := uuid.New()
+	userRepo.addUser(&model.User{ID: alice, Name: "Alice"})
+	chat := &model.Chat{ID: uuid.New(), Type: model.ChatTypeGroup, Name: "Team", CreatedBy: alice}
+	chatRepo.chats[chat.ID] = chat
+	_ = chatRepo.AddMember(context.Background(), chat.ID, alice, model.MemberRoleAdmin)
+	_ = chatRepo.AddMember(context.Background(), chat.ID, bob, model.MemberRoleMember)
+	_ = chatRepo.AddMember(context.Background(), chat.ID, carol, model.MemberRoleMember)
+
+	t.Run("mentioned member gets a mention notification instead of the chat one", func(t *testing.T) {
+		msg, err := svc.SendMessage(context.Background(), SendMessageInput{
+			ChatID: chat.ID, SenderID: alice, Content: "cek ini " + mentionToken("Bob", bob),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{bob}, msg.Mentions)
+
+		assert.Eventually(t, func() bool {
+			return len(notif.sentToUsers()) == 1 && len(notif.sentToChat()) == 1
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, []uuid.UUID{bob}, notif.sentToUsers()[0].UserIDs)
+		assert.Equal(t, model.NotifTypeMention, notif.sentToUsers()[0].Notif.Type)
+		assert.ElementsMatch(t, []uuid.UUID{alice, bob}, notif.sentToChat()[0].Exclude)
+		assert.Equal(t, "Alice: cek ini @Bob", notif.sentToChat()[0].Notif.Body)
+
+		page, err := svc.GetMessages(context.Background(), chat.ID, "", 10)
+		require.NoError(t, err)
+		require.Len(t, page.Messages, 1)
+		assert.Equal(t, []uuid.UUID{bob}, page.Messages[0].Mentions)
+	})
+
+	t.Run("non-member mention rejected", func(t *testing.T) {
+		_, err := svc.SendMessage(context.Background(), SendMessageInput{
+			ChatID: chat.ID, SenderID: alice, Content: mentionToken("Eve", uuid.New()),
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not a member")
+	})
+
+	t.Run("edit adds a mention", func(t *testing.T) {
+		msg, err := svc.SendMessage(context.Background(), SendMessageInput{
+			ChatID: chat.ID, SenderID: alice, Content: "rapat jam 3",
+		})
+		require.NoError(t, err)
+
+		edited, err := svc.EditMessage(context.Background(), chat.ID, msg.ID, alice, "rapat jam 3 "+mentionToken("Carol", carol))
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{carol}, edited.Mentions)
+
+		mentions, err := mentionRepo.ListByUser(context.Background(), carol, nil, 10)
+		require.NoError(t, err)
+		require.Len(t, mentions, 1)
+		assert.Equal(t, msg.ID, mentions[0].ItemID)
+	})
+}
+
+func TestMessageService_SendMessage_ThreadFollowFailure(t *testing.T) {
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	ctx := context.Background()
+	chatRepo := newMockChatRepo()
+	msgRepo := newMockMessageRepo()
+	threadRepo := newMockThreadRepo()
+	svc := NewMessageService(msgRepo, newMockMessageStatRepo(), newMockReactionRepo(), threadRepo, chatRepo, nil, nil, hub, nil, nil, DefaultMessageConfig())
+
+	alice := uuid.New()
+	bob := uuid.New()
+	chat := &model.Chat{ID: uuid.New(), Type: model.ChatTypeGroup, Name: "Team", CreatedBy: alice}
+	chatRepo.chats[chat.ID] = chat
+	_ = chatRepo.AddMember(ctx, chat.ID, alice, model.MemberRoleAdmin)
+	_ = chatRepo.AddMember(ctx, chat.ID, bob, model.MemberRoleMember)
+
+	root, err := svc.SendMessage(ctx, SendMessageInput{ChatID: chat.ID, SenderID: alice, Content: "Rencana rilis?"})
+	require.NoError(t, err)
+
+	observer := &ws.Client{UserID: alice, DeviceID: "d1", Send: make(chan []byte, 16), Hub: hub}
+	hub.RegisterClient(observer)
+	time.Sleep(20 * time.Millisecond)
+	hub.JoinRoom(observer, "chat:"+chat.ID.String())
+
+	// A failed follow must not lose the already saved reply
+	threadRepo.followErr = errors.New("db down")
+	reply, err := svc.SendMessage(ctx, SendMessageInput{ChatID: chat.ID, SenderID: bob, Content: "Jumat", ReplyToID: &root.ID})
+	require.NoError(t, err)
+	require.NotNil(t, reply.ThreadID)
+
+	select {
+	case data := <-observer.Send:
+		assert.Contains(t, string(data), `"type":"new_message"`)
+		assert.Contains(t, string(data), reply.ID.String())
+	case <-time.After(time.Second):
+		t.Fatal("expected new_message broadcast")
+	}
+}
+
+func TestMessageService_Threads(t *testing.T) {
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	ctx := context.Background()
+	chatRepo := newMockChatRepo()
+	userRepo := newMockUserRepo()
+	threadRepo := newMockThreadRepo()
+	notif := &recordingNotifSvc{}
+	svc := NewMessageService(newMockMessageRepo(), newMockMessageStatRepo(), newMockReactionRepo(), threadRepo, chatRepo, userRepo, nil, hub, notif, nil, DefaultMessageConfig())
+
+	alice := uuid.New()
+	bob := uuid.New()
+	carol := uuid.New()
+	outsider := uuid.New()
+	userRepo.addUser(&model.User{ID: alice, Name: "Alice"})
+	userRepo.addUser(&model.User{ID: bob, Name: "Bob"})
+	chat := &model.Chat{ID: uuid.New(), Type: model.ChatTypeGroup, Name: "Team", CreatedBy: alice}
+	chatRepo.chats[chat.ID] = chat
+	_ = chatRepo.AddMember(ctx, chat.ID, alice, model.MemberRoleAdmin)
+	_ = chatRepo.AddMember(ctx, chat.ID, bob, model.MemberRoleMember)
+	_ = chatRepo.AddMember(ctx, chat.ID, carol, model.MemberRoleMember)
+
+	root, err := svc.SendMessage(ctx, SendMessageInput{ChatID: chat.ID, SenderID: alice, Content: "Rencana rilis?"})
+	require.NoError(t, err)
+	assert.Nil(t, root.ThreadID)
+
+	t.Run("replies join the root thread and notify followers", func(t *testing.T) {
+		reply, err := svc.SendMessage(ctx, SendMessageInput{ChatID: chat.ID, SenderID: bob, Content: "Jumat", ReplyToID: &root.ID})
+		require.NoError(t, err)
+		require.NotNil(t, reply.ThreadID)
+		assert.Equal(t, root.ID, *reply.ThreadID)
+
+		// The starter is auto-followed and told about the reply
+		assert.Eventually(t, func() bool { return len(notif.sentToUsers()) == 1 }, time.Second, 10*time.Millisecond)
+		sent := notif.sentToUsers()[0]
+		assert.Equal(t, []uuid.UUID{alice}, sent.UserIDs)
+		assert.Equal(t, model.NotifTypeThreadReply, sent.Notif.Type)
+		assert.Equal(t, root.ID.String(), sent.Notif.Data["messageId"])
+
+		// Replying to a reply stays in the same thread
+		time.Sleep(2 * time.Millisecond)
+		nested, err := svc.SendMessage(ctx, SendMessageInput{ChatID: chat.ID, SenderID: alice, Content: "Setuju", ReplyToID: &reply.ID})
+		require.NoError(t, err)
+		assert.Equal(t, root.ID, *nested.ThreadID)
+
+		assert.Eventually(t, func() bool { return len(notif.sentToUsers()) == 2 }, time.Second, 10*time.Millisecond)
+		assert.Equal(t, []uuid.UUID{bob}, notif.sentToUsers()[1].UserIDs)
+
+		chatNotifs := notif.sentToChat()
+		require.NotEmpty(t, chatNotifs)
+		assert.ElementsMatch(t, []uuid.UUID{alice, alice, bob}, chatNotifs[len(chatNotifs)-1].Exclude)
+	})
+
+	t.Run("message pages carry reply counts", func(t *testing.T) {
+		page, err := svc.GetMessages(ctx, chat.ID, "", 10)
+		require.NoError(t, err)
+		var found *model.Message
+		for _, m := range page.Messages {
+			if m.ID == root.ID {
+				found = m
+			}
+		}
+		require.NotNil(t, found)
+		assert.Equal(t, 2, found.ReplyCount)
+		require.NotNil(t, found.LastReplyAt)
+	})
+
+	t.Run("thread paginates oldest first", func(t *testing.T) {
+		page, err := svc.GetThread(ctx, chat.ID, root.ID, carol, "", 1)
+		require.NoError(t, err)
+		assert.Equal(t, root.ID, page.Root.ID)
+		assert.Equal(t, 2, page.Root.ReplyCount)
+		require.Len(t, page.Replies, 1)
+		assert.Equal(t, "Jumat", page.Replies[0].Content)
+		assert.True(t, page.HasMore)
+		assert.False(t, page.Following)
+
+		next, err := svc.GetThread(ctx, chat.ID, root.ID, carol, page.Cursor, 1)
+		require.NoError(t, err)
+		require.Len(t, next.Replies, 1)
+		assert.Equal(t, "Setuju", next.Replies[0].Content)
+		assert.False(t, next.HasMore)
+
+		// Opening the thread from a reply resolves to the same root
+		fromReply, err := svc.GetThread(ctx, chat.ID, page.Replies[0].ID, carol, "", 10)
+		require.NoError(t, err)
+		assert.Equal(t, root.ID, fromReply.Root.ID)
+		assert.Len(t, fromReply.Replies, 2)
+	})
+
+	t.Run("follow and unfollow", func(t *testing.T) {
+		require.NoError(t, svc.FollowThread(ctx, chat.ID, root.ID, carol))
+		page, err := svc.GetThread(ctx, chat.ID, root.ID, carol, "", 10)
+		require.NoError(t, err)
+		assert.True(t, page.Following)
+
+		require.NoError(t, svc.UnfollowThread(ctx, chat.ID, root.ID, alice))
+		_, err = svc.SendMessage(ctx, SendMessageInput{ChatID: chat.ID, SenderID: bob, Content: "Oke", ReplyToID: &root.ID})
+		require.NoError(t, err)
+		assert.Eventually(t, func() bool { return len(notif.sentToUsers()) == 3 }, time.Second, 10*time.Millisecond)
+		assert.Equal(t, []uuid.UUID{carol}, notif.sentToUsers()[2].UserIDs)
+
+		err = svc.UnfollowThread(ctx, chat.ID, root.ID, alice)
+		assert.True(t, apperror.IsNotFound(err))
+	})
+
+	t.Run("non-member cannot follow", func(t *testing.T) {
+		err := svc.FollowThread(ctx, chat.ID, root.ID, outsider)
+		require.Error(t, err)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		_, err := svc.GetThread(ctx, chat.ID, root.ID, carol, "bad", 10)
+		require.Error(t, err)
+	})
+}
+
+func TestMessageService_LiveLocation(t *testing.T) {
+	chatRepo := newMockChatRepo()
+	msgRepo := newMockMessageRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	svc := NewMessageService(msgRepo, newMockMessageStatRepo(), newMockReactionRepo(), newMockThreadRepo(), chatRepo, nil, nil, hub, nil, nil, DefaultMessageConfig())
+
+	userA := uuid.New()
+	userB := uuid.New()
+	chat := &model.Chat{ID: uuid.New(), Type: model.ChatTypePersonal, CreatedBy: userA}
+	chatRepo.chats[chat.ID] = chat
+	_ = chatRepo.AddMember(context.Background(), chat.ID, userA, model.MemberRoleAdmin)
+	_ = chatRepo.AddMember(context.Background(), chat.ID, userB, model.MemberRoleMember)
+
+	live, err := svc.SendMessage(context.Background(), SendMessageInput{
+		ChatID: chat.ID, SenderID: userA, Type: model.MessageTypeLocation,
+		Metadata: []byte(`{"latitude":-6.2,"longitude":106.8,"label":"Proyek Bekasi","liveDuration":3600}`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Proyek Bekasi", live.Content)
+
+	observer := &ws.Client{UserID: userB, DeviceID: "d1", Send: make(chan []byte, 16), Hub: hub}
+	hub.RegisterClient(observer)
+	time.Sleep(20 * time.Millisecond)
+	hub.JoinRoom(observer, "chat:"+chat.ID.String())
+
+	t.Run("sender moves the location", func(t *testing.T) {
+		msg, err := svc.UpdateLiveLocation(context.Background(), chat.ID, live.ID, userA, -6.25, 106.9)
+		require.NoError(t, err)
+
+		var loc model.LocationMetadata
+		require.NoError(t, json.Unmarshal(msg.Metadata, &loc))
+		assert.Equal(t, -6.25, loc.Latitude)
+		assert.Equal(t, 106.9, loc.Longitude)
+		assert.Equal(t, "Proyek Bekasi", loc.Label)
+
+		select {
+		case data := <-observer.Send:
+			assert.Contains(t, string(data), `"type":"location_updated"`)
+			assert.Contains(t, string(data), live.ID.String())
+		case <-time.After(time.Second):
+			t.Fatal("expected location_updated event")
+		}
+	})
+
+	t.Run("other member cannot move it", func(t *testing.T) {
+		_, err := svc.UpdateLiveLocation(context.Background(), chat.ID, live.ID, userB, 0, 0)
+		assert.True(t, apperror.IsForbidden(err))
+	})
+
+	t.Run("invalid coordinates", func(t *testing.T) {
+		_, err := svc.UpdateLiveLocation(context.Background(), chat.ID, live.ID, userA, 100, 0)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "latitude must be between")
+	})
+
+	t.Run("not a location", func(t *testing.T) {
+		text, err := svc.SendMessage(context.Background(), SendMessageInput{
+			ChatID: chat.ID, SenderID: userA, Content: "Hi",
+		})
+		require.NoError(t, err)
+		_, err = svc.UpdateLiveLocation(context.Background(), chat.ID, text.ID, userA, 0, 0)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not a location")
+	})
+
+	t.Run("stop ends updates", func(t *testing.T) {
+		msg, err := svc.StopLiveLocation(context.Background(), chat.ID, live.ID, userA)
+		require.NoError(t, err)
+
+		var loc model.LocationMetadata
+		require.NoError(t, json.Unmarshal(msg.Metadata, &loc))
+		assert.False(t, loc.IsLive(time.Now()))
+
+		_, err = svc.UpdateLiveLocation(context.Background(), chat.ID, live.ID, userA, 0, 0)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "has ended")
+
+		// Stopping again is a no-op
+		_, err = svc.StopLiveLocation(context.Background(), chat.ID, live.ID, userA)
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid metadata rejected on send", func(t *testing.T) {
+		_, err := svc.SendMessage(context.Background(), SendMessageInput{
+			ChatID: chat.ID, SenderID: userA, Type: model.MessageTypeLocation,
+			Metadata: []byte(`{"latitude":-6.2}`),
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestMessageService_ForwardMessage_Metadata(t *testing.T) {
+	chatRepo := newMockChatRepo()
+	msgRepo := newMockMessageRepo()
+	userRepo := newMockUserRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	svc := NewMessageService(msgRepo, newMockMessageStatRepo(), newMockReactionRepo(), newMockThreadRepo(), chatRepo, userRepo, nil, hub, nil, nil, DefaultMessageConfig())
+
+	userA := uuid.New()
+	chat1 := &model.Chat{ID: uuid.New(), Type: model.ChatTypeGroup, CreatedBy: userA}
+	chat2 := &model.Chat{ID: uuid.New(), Type: model.ChatTypeGroup, CreatedBy: userA}
+	for _, c := range []*model.Chat{chat1, chat2} {
+		chatRepo.chats[c.ID] = c
+		_ = chatRepo.AddMember(context.Background(), c.ID, userA, model.MemberRoleAdmin)
+	}
+
+	vendor := &model.User{ID: uuid.New(), Phone: "+6281234567890", Name: "Budi"}
+	userRepo.addUser(vendor)
+
+	t.Run("contact card keeps its link", func(t *testing.T) {
+		card, err := svc.SendMessage(context.Background(), SendMessageInput{
+			ChatID: chat1.ID, SenderID: userA, Type: model.MessageTypeContact,
+			Metadata: []byte(`{"vcard":"BEGIN:VCARD\nFN:Budi Santoso\nTEL:081234567890\nEND:VCARD"}`),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Budi Santoso +6281234567890", card.Content)
+
+		fwd, err := svc.ForwardMessage(context.Background(), card.ID, userA, chat2.ID)
+		require.NoError(t, err)
+
+		var meta map[string]interface{}
+		require.NoError(t, json.Unmarshal(fwd.Metadata, &meta))
+		assert.Equal(t, true, meta["forwarded"])
+		assert.Equal(t, vendor.ID.String(), meta["userId"])
+		assert.Equal(t, "Budi Santoso", meta["name"])
+	})
+
+	t.Run("live location forwarded as a snapshot", func(t *testing.T) {
+		live, err := svc.SendMessage(context.Background(), SendMessageInput{
+			ChatID: chat1.ID, SenderID: userA, Type: model.MessageTypeLocation,
+			Metadata: []byte(`{"latitude":1,"longitude":2,"liveDuration":600}`),
+		})
+		require.NoError(t, err)
+
+		fwd, err := svc.ForwardMessage(context.Background(), live.ID, userA, chat2.ID)
+		require.NoError(t, err)
+
+		var loc model.LocationMetadata
+		require.NoError(t, json.Unmarshal(fwd.Metadata, &loc))
+		assert.Equal(t, 1.0, loc.Latitude)
+		assert.Nil(t, loc.LiveUntil)
+	})
+
+	t.Run("polls cannot be forwarded", func(t *testing.T) {
+		poll, err := svc.SendMessage(context.Background(), SendMessageInput{
+			ChatID: chat1.ID, SenderID: userA, Content: "Lunch?", Type: model.MessageTypePoll, fromPoll: true,
+		})
+		require.NoError(t, err)
+
+		_, err = svc.ForwardMessage(context.Background(), poll.ID, userA, chat2.ID)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "polls cannot be forwarded")
+	})
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/internal/repository"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+const (
+	maxReportMessages = 50
+	maxReportDetails  = 1000
+)
+
+// ReportInput holds the data for reporting a user.
+type ReportInput struct {
+	UserID uuid.UUID `json:"userId"`
+	// ChatID is the chat the reported messages were sent in. It is required
+	// when MessageIDs is set.
+	ChatID     *uuid.UUID         `json:"chatId"`
+	MessageIDs []uuid.UUID        `json:"messageIds"`
+	Reason     model.ReportReason `json:"reason"`
+	Details    string             `json:"details"`
+	// Block also blocks the reported user.
+	Block bool `json:"block"`
+}
+
+// ModerationService manages user blocks and abuse reports.
+type ModerationService interface {
+	BlockUser(ctx context.Context, userID, targetID uuid.UUID) error
+	UnblockUser(ctx context.Context, userID, targetID uuid.UUID) error
+	ListBlocked(ctx context.Context, userID uuid.UUID) ([]*model.BlockedUser, error)
+	ReportUser(ctx context.Context, reporterID uuid.UUID, input ReportInput) (*model.AbuseReport, error)
+}
+
+type moderationService struct {
+	blockRepo   repository.UserBlockRepository
+	reportRepo  repository.AbuseReportRepository
+	userRepo    repository.UserRepository
+	chatRepo    repository.ChatRepository
+	messageRepo repository.MessageRepository
+}
+
+// NewModerationService creates a new ModerationService.
+func NewModerationService(
+	blockRepo repository.UserBlockRepository,
+	reportRepo repository.AbuseReportRepository,
+	userRepo repository.UserRepository,
+	chatRepo repository.ChatRepository,
+	messageRepo repository.MessageRepository,
+) ModerationService {
+	return &moderationService{
+		blockRepo:   blockRepo,
+		reportRepo:  reportRepo,
+		userRepo:    userRepo,
+		chatRepo:    chatRepo,
+		messageRepo: messageRepo,
+	}
+}
+
+func (s *moderationService) BlockUser(ctx context.Context, userID, targetID uuid.UUID) error {
+	if userID == targetID {
+		return apperror.BadRequest("cannot block yourself")
+	}
+	if err := s.requireUser(ctx, targetID); err != nil {
+		return err
+	}
+
+	if err := s.blockRepo.Block(ctx, userID, targetID); err != nil {
+		return fmt.Errorf("block user: %w", err)
+	}
+	return nil
+}
+
+func (s *moderationService) UnblockUser(ctx context.Context, userID, targetID uuid.UUID) error {
+	if err := s.blockRepo.Unblock(ctx, userID, targetID); err != nil {
+		return fmt.Errorf("unblock user: %w", err)
+	}
+	return nil
+}
+
+func (s *moderationService) ListBlocked(ctx context.Context, userID uuid.UUID) ([]*model.BlockedUser, error) {
+	blocked, err := s.blockRepo.ListBlocked(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list blocked users: %w", err)
+	}
+	return blocked, nil
+}
+
+func (s *moderationService) ReportUser(ctx context.Context, reporterID uuid.UUID, input ReportInput) (*model.AbuseReport, error) {
+	if input.UserID == reporterID {
+		return nil, apperror.BadRequest("cannot report yourself")
+	}
+	if !validReportReason(input.Reason) {
+		return nil, apperror.Validation("reason", "reason must be one of spam, harassment, inappropriate, other")
+	}
+	if utf8.RuneCountInString(input.Details) > maxReportDetails {
+		return nil, apperror.Validation("details", fmt.Sprintf("details must be at most %d characters", maxReportDetails))
+	}
+	if len(input.MessageIDs) > maxReportMessages {
+		return nil, apperror.Validation("messageIds", fmt.Sprintf("at most %d messages can be reported at once", maxReportMessages))
+	}
+	if len(input.MessageIDs) > 0 && input.ChatID == nil {
+		return nil, apperror.Validation("chatId", "chatId is required when reporting messages")
+	}
+	if err := s.requireUser(ctx, input.UserID); err != nil {
+		return nil, err
+	}
+
+	var messages []model.ReportedMessage
+	if input.ChatID != nil {
+		var err error
+		messages, err = s.captureMessages(ctx, reporterID, input)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	report := &model.AbuseReport{
+		ReporterID:     reporterID,
+		ReportedUserID: input.UserID,
+		ChatID:         input.ChatID,
+		Reason:         input.Reason,
+		Details:        input.Details,
+		Messages:       messages,
+	}
+	if err := s.reportRepo.Create(ctx, report); err != nil {
+		return nil, fmt.Errorf("create report: %w", err)
+	}
+
+	if input.Block {
+		if err := s.blockRepo.Block(ctx, reporterID, input.UserID); err != nil {
+			return nil, fmt.Errorf("block reported user: %w", err)
+		}
+	}
+
+	return report, nil
+}
+
+// captureMessages copies the reported messages so moderators can still read
+// them after they are deleted. Only messages the reported user sent in a chat
+// the reporter belongs to can be attached.
+func (s *moderationService) captureMessages(ctx context.Context, reporterID uuid.UUID, input ReportInput) ([]model.ReportedMessage, error) {
+	members, err := s.chatRepo.GetMembers(ctx, *input.ChatID)
+	if err != nil {
+		return nil, fmt.Errorf("get chat members: %w", err)
+	}
+	if !chatMemberSet(members)[reporterID] {
+		return nil, apperror.Forbidden("you are not a member of this chat")
+	}
+
+	seen := make(map[uuid.UUID]bool, len(input.MessageIDs))
+	messages := make([]model.ReportedMessage, 0, len(input.MessageIDs))
+	for _, id := range input.MessageIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		msg, err := s.messageRepo.FindByID(ctx, id)
+		if err != nil {
+			if apperror.IsNotFound(err) {
+				return nil, apperror.NotFound("message", id.String())
+			}
+			return nil, fmt.Errorf("find reported message: %w", err)
+		}
+		if msg.ChatID != *input.ChatID || msg.SenderID != input.UserID {
+			return nil, apperror.BadRequest("reported messages must be sent by the reported user in this chat")
+		}
+
+		messages = append(messages, model.ReportedMessage{
+			ID:        msg.ID,
+			SenderID:  msg.SenderID,
+			Content:   msg.Content,
+			Type:      msg.Type,
+			CreatedAt: msg.CreatedAt,
+		})
+	}
+	return messages, nil
+}
+
+func (s *moderationService) requireUser(ctx context.Context, userID uuid.UUID) error {
+	if _, err := s.userRepo.FindByID(ctx, userID); err != nil {
+		if apperror.IsNotFound(err) {
+			return apperror.NotFound("user", userID.String())
+		}
+		return fmt.Errorf("find user: %w", err)
+	}
+	return nil
+}
+
+func validReportReason(reason model.ReportReason) bool {
+	switch reason {
+	case model.ReportReasonSpam, model.ReportReasonHarassment, model.ReportReasonInappropriate, model.ReportReasonOther:
+		return true
+	}
+	return false
+}
+
+// isBlocked reports whether either user has blocked the other. A nil repo
+// means blocks are not enforced.
+func isBlocked(ctx context.Context, blockRepo repository.UserBlockRepository, userID, otherID uuid.UUID) (bool, error) {
+	if blockRepo == nil {
+		return false, nil
+	}
+	blocked, err := blockRepo.IsBlocked(ctx, userID, otherID)
+	if err != nil {
+		return false, fmt.Errorf("check block: %w", err)
+	}
+	return blocked, nil
+}
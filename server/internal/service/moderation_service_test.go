@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+type mockUserBlockRepo struct {
+	blocks map[uuid.UUID]map[uuid.UUID]time.Time
+}
+
+func newMockUserBlockRepo() *mockUserBlockRepo {
+	return &mockUserBlockRepo{blocks: make(map[uuid.UUID]map[uuid.UUID]time.Time)}
+}
+
+func (m *mockUserBlockRepo) Block(_ context.Context, blockerID, blockedID uuid.UUID) error {
+	if m.blocks[blockerID] == nil {
+		m.blocks[blockerID] = make(map[uuid.UUID]time.Time)
+	}
+	if _, ok := m.blocks[blockerID][blockedID]; !ok {
+		m.blocks[blockerID][blockedID] = time.Now()
+	}
+	return nil
+}
+
+func (m *mockUserBlockRepo) Unblock(_ context.Context, blockerID, blockedID uuid.UUID) error {
+	delete(m.blocks[blockerID], blockedID)
+	return nil
+}
+
+func (m *mockUserBlockRepo) ListBlocked(_ context.Context, blockerID uuid.UUID) ([]*model.BlockedUser, error) {
+	result := make([]*model.BlockedUser, 0)
+	for id, at := range m.blocks[blockerID] {
+		result = append(result, &model.BlockedUser{UserID: id, BlockedAt: at})
+	}
+	return result, nil
+}
+
+func (m *mockUserBlockRepo) IsBlocked(_ context.Context, userID, otherID uuid.UUID) (bool, error) {
+	_, ab := m.blocks[userID][otherID]
+	_, ba := m.blocks[otherID][userID]
+	return ab || ba, nil
+}
+
+func (m *mockUserBlockRepo) FindBlockedPeers(_ context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	seen := make(map[uuid.UUID]bool)
+	for id := range m.blocks[userID] {
+		seen[id] = true
+	}
+	for blocker, blocked := range m.blocks {
+		if _, ok := blocked[userID]; ok {
+			seen[blocker] = true
+		}
+	}
+	var peers []uuid.UUID
+	for id := range seen {
+		peers = append(peers, id)
+	}
+	return peers, nil
+}
+
+type mockAbuseReportRepo struct {
+	reports []*model.AbuseReport
+}
+
+func (m *mockAbuseReportRepo) Create(_ context.Context, report *model.AbuseReport) error {
+	report.ID = uuid.New()
+	report.Status = model.ReportStatusOpen
+	report.CreatedAt = time.Now()
+	m.reports = append(m.reports, report)
+	return nil
+}
+
+// ==================== ModerationService Tests ====================
+
+func TestModerationService_BlockUser(t *testing.T) {
+	ctx := context.Background()
+	blockRepo := newMockUserBlockRepo()
+	userRepo := newMockUserRepo()
+	svc := NewModerationService(blockRepo, &mockAbuseReportRepo{}, userRepo, newMockChatRepo(), newMockMessageRepo())
+
+	alice := uuid.New()
+	bob := uuid.New()
+	userRepo.addUser(&model.User{ID: alice, Phone: "+628111", Name: "Alice"})
+	userRepo.addUser(&model.User{ID: bob, Phone: "+628222", Name: "Bob"})
+
+	t.Run("block and list", func(t *testing.T) {
+		require.NoError(t, svc.BlockUser(ctx, alice, bob))
+		// Blocking again is a no-op
+		require.NoError(t, svc.BlockUser(ctx, alice, bob))
+
+		blocked, err := svc.ListBlocked(ctx, alice)
+		require.NoError(t, err)
+		require.Len(t, blocked, 1)
+		assert.Equal(t, bob, blocked[0].UserID)
+	})
+
+	t.Run("unblock", func(t *testing.T) {
+		require.NoError(t, svc.UnblockUser(ctx, alice, bob))
+		blocked, err := svc.ListBlocked(ctx, alice)
+		require.NoError(t, err)
+		assert.Empty(t, blocked)
+	})
+
+	t.Run("cannot block yourself", func(t *testing.T) {
+		err := svc.BlockUser(ctx, alice, alice)
+		require.Error(t, err)
+		appErr, ok := err.(*apperror.AppError)
+		require.True(t, ok)
+		assert.Equal(t, 400, appErr.HTTPStatus)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		err := svc.BlockUser(ctx, alice, uuid.New())
+		assert.True(t, apperror.IsNotFound(err))
+	})
+}
+
+func TestModerationService_ReportUser(t *testing.T) {
+	ctx := context.Background()
+	blockRepo := newMockUserBlockRepo()
+	reportRepo := &mockAbuseReportRepo{}
+	userRepo := newMockUserRepo()
+	chatRepo := newMockChatRepo()
+	msgRepo := newMockMessageRepo()
+	svc := NewModerationService(blockRepo, reportRepo, userRepo, chatRepo, msgRepo)
+
+	alice := uuid.New()
+	bob := uuid.New()
+	outsider := uuid.New()
+	userRepo.addUser(&model.User{ID: alice, Phone: "+628111", Name: "Alice"})
+	userRepo.addUser(&model.User{ID: bob, Phone: "+628222", Name: "Bob"})
+	userRepo.addUser(&model.User{ID: outsider, Phone: "+628333", Name: "Outsider"})
+
+	chat := &model.Chat{ID: uuid.New(), Type: model.ChatTypePersonal, CreatedBy: alice}
+	chatRepo.chats[chat.ID] = chat
+	_ = chatRepo.AddMember(ctx, chat.ID, alice, model.MemberRoleAdmin)
+	_ = chatRepo.AddMember(ctx, chat.ID, bob, model.MemberRoleMember)
+
+	spam, err := msgRepo.Create(ctx, model.CreateMessageInput{ChatID: chat.ID, SenderID: bob, Content: "buy now"})
+	require.NoError(t, err)
+	own, err := msgRepo.Create(ctx, model.CreateMessageInput{ChatID: chat.ID, SenderID: alice, Content: "stop"})
+	require.NoError(t, err)
+
+	t.Run("captures messages and blocks", func(t *testing.T) {
+		report, err := svc.ReportUser(ctx, alice, ReportInput{
+			UserID:     bob,
+			ChatID:     &chat.ID,
+			MessageIDs: []uuid.UUID{spam.ID, spam.ID},
+			Reason:     model.ReportReasonSpam,
+			Block:      true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, model.ReportStatusOpen, report.Status)
+		require.Len(t, report.Messages, 1)
+		assert.Equal(t, "buy now", report.Messages[0].Content)
+		require.Len(t, reportRepo.reports, 1)
+
+		blocked, err := blockRepo.IsBlocked(ctx, alice, bob)
+		require.NoError(t, err)
+		assert.True(t, blocked)
+	})
+
+	t.Run("report without messages", func(t *testing.T) {
+		report, err := svc.ReportUser(ctx, alice, ReportInput{UserID: bob, Reason: model.ReportReasonOther, Details: "fake profile"})
+		require.NoError(t, err)
+		assert.Empty(t, report.Messages)
+		assert.Equal(t, "fake profile", report.Details)
+	})
+
+	errorCases := []struct {
+		name       string
+		reporterID uuid.UUID
+		input      ReportInput
+		status     int
+	}{
+		{"yourself", alice, ReportInput{UserID: alice, Reason: model.ReportReasonSpam}, 400},
+		{"invalid reason", alice, ReportInput{UserID: bob, Reason: "boring"}, 422},
+		{"details too long", alice, ReportInput{UserID: bob, Reason: model.ReportReasonOther, Details: strings.Repeat("a", maxReportDetails+1)}, 422},
+		{"messages without chat", alice, ReportInput{UserID: bob, Reason: model.ReportReasonSpam, MessageIDs: []uuid.UUID{spam.ID}}, 422},
+		{"unknown user", alice, ReportInput{UserID: uuid.New(), Reason: model.ReportReasonSpam}, 404},
+		{"reporter not in chat", outsider, ReportInput{UserID: bob, ChatID: &chat.ID, MessageIDs: []uuid.UUID{spam.ID}, Reason: model.ReportReasonSpam}, 403},
+		{"message by someone else", alice, ReportInput{UserID: bob, ChatID: &chat.ID, MessageIDs: []uuid.UUID{own.ID}, Reason: model.ReportReasonSpam}, 400},
+		{"unknown message", alice, ReportInput{UserID: bob, ChatID: &chat.ID, MessageIDs: []uuid.UUID{uuid.New()}, Reason: model.ReportReasonSpam}, 404},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := svc.ReportUser(ctx, tc.reporterID, tc.input)
+			require.Error(t, err)
+			appErr, ok := err.(*apperror.AppError)
+			require.True(t, ok, "expected AppError, got %v", err)
+			assert.Equal(t, tc.status, appErr.HTTPStatus)
+		})
+	}
+}
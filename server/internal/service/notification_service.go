@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
@@ -24,7 +25,9 @@ type NotificationService interface {
 	UnregisterDevice(ctx context.Context, userID uuid.UUID, token string) error
 	SendToUser(ctx context.Context, userID uuid.UUID, notif model.Notification) error
 	SendToUsers(ctx context.Context, userIDs []uuid.UUID, notif model.Notification) error
-	SendToChat(ctx context.Context, chatID uuid.UUID, excludeUserID uuid.UUID, notif model.Notification) error
+	// SendToChat notifies the chat's members, skipping those who muted it and
+	// using each member's own notification sound.
+	SendToChat(ctx context.Context, chatID uuid.UUID, excludeUserIDs []uuid.UUID, notif model.Notification) error
 }
 
 type notificationService struct {
@@ -108,25 +111,39 @@ func (s *notificationService) SendToUsers(ctx context.Context, userIDs []uuid.UU
 	return nil
 }
 
-func (s *notificationService) SendToChat(ctx context.Context, chatID uuid.UUID, excludeUserID uuid.UUID, notif model.Notification) error {
+func (s *notificationService) SendToChat(ctx context.Context, chatID uuid.UUID, excludeUserIDs []uuid.UUID, notif model.Notification) error {
 	// Get chat members
 	members, err := s.chatRepo.GetMembers(ctx, chatID)
 	if err != nil {
 		return fmt.Errorf("get chat members: %w", err)
 	}
 
-	var userIDs []uuid.UUID
+	excluded := make(map[uuid.UUID]bool, len(excludeUserIDs))
+	for _, id := range excludeUserIDs {
+		excluded[id] = true
+	}
+
+	// Muted members get nothing; the rest are grouped by their chosen sound
+	now := time.Now()
+	bySound := make(map[string][]uuid.UUID)
 	for _, m := range members {
-		if m.UserID != excludeUserID {
-			userIDs = append(userIDs, m.UserID)
+		if excluded[m.UserID] || m.Settings.IsMuted(now) {
+			continue
 		}
+		bySound[m.Settings.NotificationSound] = append(bySound[m.Settings.NotificationSound], m.UserID)
 	}
 
-	if len(userIDs) == 0 {
-		return nil
+	var firstErr error
+	for sound, userIDs := range bySound {
+		soundNotif := notif
+		if sound != "" {
+			soundNotif.Sound = sound
+		}
+		if err := s.SendToUsers(ctx, userIDs, soundNotif); err != nil && firstErr == nil {
+			firstErr = err
+		}
 	}
-
-	return s.SendToUsers(ctx, userIDs, notif)
+	return firstErr
 }
 
 // -- Notification builder helpers --
@@ -243,6 +260,92 @@ func BuildGroupInviteNotif(inviterName, groupName string, chatID uuid.UUID) mode
 	}
 }
 
+// BuildJoinRequestNotif creates a notification telling group admins that a
+// user asked to join through an invite link.
+func BuildJoinRequestNotif(userName, groupName string, chatID uuid.UUID) model.Notification {
+	body := fmt.Sprintf("%s ingin bergabung ke grup '%s'", userName, groupName)
+
+	return model.Notification{
+		Type:  model.NotifTypeJoinRequest,
+		Title: "Permintaan Bergabung",
+		Body:  body,
+		Data: map[string]string{
+			"type":   string(model.NotifTypeJoinRequest),
+			"chatId": chatID.String(),
+		},
+		Sound:    "default",
+		Priority: "normal",
+	}
+}
+
+// BuildJoinApprovedNotif creates a notification for an approved join request.
+func BuildJoinApprovedNotif(groupName string, chatID uuid.UUID) model.Notification {
+	body := fmt.Sprintf("Permintaan Anda untuk bergabung ke grup '%s' telah disetujui", groupName)
+
+	return model.Notification{
+		Type:  model.NotifTypeJoinApproved,
+		Title: "Permintaan Disetujui",
+		Body:  body,
+		Data: map[string]string{
+			"type":   string(model.NotifTypeJoinApproved),
+			"chatId": chatID.String(),
+		},
+		Sound:    "default",
+		Priority: "high",
+	}
+}
+
+// BuildThreadReplyNotif creates a notification for a new reply in a followed thread.
+func BuildThreadReplyNotif(senderName, content string, chatID, rootID uuid.UUID) model.Notification {
+	preview := truncate(content, 50)
+	body := fmt.Sprintf("%s membalas utas: %s", senderName, preview)
+
+	return model.Notification{
+		Type:  model.NotifTypeThreadReply,
+		Title: senderName,
+		Body:  body,
+		Data: map[string]string{
+			"type":      string(model.NotifTypeThreadReply),
+			"chatId":    chatID.String(),
+			"messageId": rootID.String(),
+		},
+		Sound:    "default",
+		Priority: "high",
+	}
+}
+
+// BuildMentionNotif creates a high-priority notification for a user mentioned
+// in a chat message, topic message or document block.
+func BuildMentionNotif(senderName string, target model.MentionTarget) model.Notification {
+	data := map[string]string{
+		"type":       string(model.NotifTypeMention),
+		"sourceType": string(target.SourceType),
+	}
+	switch target.SourceType {
+	case model.MentionSourceChat:
+		data["chatId"] = target.SourceID.String()
+		data["messageId"] = target.ItemID.String()
+	case model.MentionSourceTopic:
+		data["topicId"] = target.SourceID.String()
+		data["messageId"] = target.ItemID.String()
+	case model.MentionSourceDocument:
+		data["documentId"] = target.SourceID.String()
+		data["blockId"] = target.ItemID.String()
+	case model.MentionSourceComment:
+		data["documentId"] = target.SourceID.String()
+		data["commentId"] = target.ItemID.String()
+	}
+
+	return model.Notification{
+		Type:     model.NotifTypeMention,
+		Title:    fmt.Sprintf("%s menyebut Anda", senderName),
+		Body:     truncate(target.Preview, 50),
+		Data:     data,
+		Sound:    "default",
+		Priority: "high",
+	}
+}
+
 func truncate(s string, maxLen int) string {
 	runes := []rune(s)
 	if len(runes) <= maxLen {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -174,8 +175,21 @@ func (m *mockNotifChatRepo) Update(_ context.Context, _ uuid.UUID, _ model.Updat
 	return nil, nil
 }
 func (m *mockNotifChatRepo) Delete(_ context.Context, _ uuid.UUID) error { return nil }
-func (m *mockNotifChatRepo) Pin(_ context.Context, _ uuid.UUID) error    { return nil }
-func (m *mockNotifChatRepo) Unpin(_ context.Context, _ uuid.UUID) error  { return nil }
+func (m *mockNotifChatRepo) UpdateSettings(_ context.Context, _, _ uuid.UUID, _ model.UpdateChatSettingsInput) (*model.ChatSettings, error) {
+	return &model.ChatSettings{}, nil
+}
+func (m *mockNotifChatRepo) SetDisappearingTimer(_ context.Context, _ uuid.UUID, _ int) (*model.Chat, error) {
+	return nil, nil
+}
+func (m *mockNotifChatRepo) UpdateMemberRole(_ context.Context, _, _ uuid.UUID, _ model.MemberRole) error {
+	return nil
+}
+func (m *mockNotifChatRepo) TransferOwnership(_ context.Context, _, _, _ uuid.UUID) error {
+	return nil
+}
+func (m *mockNotifChatRepo) UpdatePermissions(_ context.Context, _ uuid.UUID, _ model.UpdateGroupPermissionsInput) (*model.Chat, error) {
+	return nil, nil
+}
 
 // -- Tests --
 
@@ -309,7 +323,7 @@ func TestNotificationService_SendToChat(t *testing.T) {
 	}
 
 	t.Run("excludes sender", func(t *testing.T) {
-		err := svc.SendToChat(ctx, chatID, senderID, notif)
+		err := svc.SendToChat(ctx, chatID, []uuid.UUID{senderID}, notif)
 		require.NoError(t, err)
 		require.Len(t, sender.sentMulti, 1)
 		// Should have tokens for member1 and member2 only (2 tokens)
@@ -318,6 +332,99 @@ func TestNotificationService_SendToChat(t *testing.T) {
 			assert.NotEqual(t, "sender-token", token)
 		}
 	})
+
+	t.Run("excludes several users", func(t *testing.T) {
+		sender.sentMulti = nil
+		err := svc.SendToChat(ctx, chatID, []uuid.UUID{senderID, member1}, notif)
+		require.NoError(t, err)
+		require.Len(t, sender.sentMulti, 1)
+		assert.Equal(t, []string{"member2-token"}, sender.sentMulti[0].Tokens)
+	})
+
+	t.Run("skips muted members", func(t *testing.T) {
+		sender.sentMulti = nil
+		until := time.Now().Add(time.Hour)
+		chatRepo.members[chatID][1].Settings.MutedUntil = &until
+		defer func() { chatRepo.members[chatID][1].Settings.MutedUntil = nil }()
+
+		err := svc.SendToChat(ctx, chatID, []uuid.UUID{senderID}, notif)
+		require.NoError(t, err)
+		require.Len(t, sender.sentMulti, 1)
+		assert.Equal(t, []string{"member2-token"}, sender.sentMulti[0].Tokens)
+	})
+
+	t.Run("expired mute notifies again", func(t *testing.T) {
+		sender.sentMulti = nil
+		past := time.Now().Add(-time.Minute)
+		chatRepo.members[chatID][1].Settings.MutedUntil = &past
+		defer func() { chatRepo.members[chatID][1].Settings.MutedUntil = nil }()
+
+		err := svc.SendToChat(ctx, chatID, []uuid.UUID{senderID}, notif)
+		require.NoError(t, err)
+		require.Len(t, sender.sentMulti, 1)
+		assert.Len(t, sender.sentMulti[0].Tokens, 2)
+	})
+
+	t.Run("uses member notification sound", func(t *testing.T) {
+		sender.sentMulti = nil
+		chatRepo.members[chatID][2].Settings.NotificationSound = "chime"
+		defer func() { chatRepo.members[chatID][2].Settings.NotificationSound = "" }()
+
+		err := svc.SendToChat(ctx, chatID, []uuid.UUID{senderID}, notif)
+		require.NoError(t, err)
+		require.Len(t, sender.sentMulti, 2)
+		sounds := make(map[string][]string)
+		for _, sent := range sender.sentMulti {
+			sounds[sent.Notif.Sound] = sent.Tokens
+		}
+		assert.Equal(t, []string{"member2-token"}, sounds["chime"])
+		assert.Equal(t, []string{"member1-token"}, sounds[""])
+	})
+}
+
+func TestBuildMentionNotif(t *testing.T) {
+	chatID := uuid.New()
+	msgID := uuid.New()
+	notif := BuildMentionNotif("Budi", model.MentionTarget{
+		SourceType: model.MentionSourceChat,
+		SourceID:   chatID,
+		ItemID:     msgID,
+		Preview:    "@Andi tolong cek",
+	})
+
+	assert.Equal(t, model.NotifTypeMention, notif.Type)
+	assert.Equal(t, "Budi menyebut Anda", notif.Title)
+	assert.Equal(t, "@Andi tolong cek", notif.Body)
+	assert.Equal(t, "high", notif.Priority)
+	assert.Equal(t, chatID.String(), notif.Data["chatId"])
+	assert.Equal(t, msgID.String(), notif.Data["messageId"])
+
+	t.Run("comment", func(t *testing.T) {
+		docID := uuid.New()
+		commentID := uuid.New()
+		notif := BuildMentionNotif("Budi", model.MentionTarget{
+			SourceType: model.MentionSourceComment,
+			SourceID:   docID,
+			ItemID:     commentID,
+			Preview:    "@Andi cek paragraf ini",
+		})
+
+		assert.Equal(t, docID.String(), notif.Data["documentId"])
+		assert.Equal(t, commentID.String(), notif.Data["commentId"])
+		assert.Empty(t, notif.Data["blockId"])
+	})
+}
+
+func TestBuildThreadReplyNotif(t *testing.T) {
+	chatID := uuid.New()
+	rootID := uuid.New()
+	notif := BuildThreadReplyNotif("Budi", "Setuju, kita rilis Jumat", chatID, rootID)
+
+	assert.Equal(t, model.NotifTypeThreadReply, notif.Type)
+	assert.Equal(t, "Budi", notif.Title)
+	assert.Equal(t, "Budi membalas utas: Setuju, kita rilis Jumat", notif.Body)
+	assert.Equal(t, chatID.String(), notif.Data["chatId"])
+	assert.Equal(t, rootID.String(), notif.Data["messageId"])
 }
 
 func TestNotificationService_SendToUsers(t *testing.T) {
@@ -491,7 +598,7 @@ func TestNotificationService_SendToChat_Errors(t *testing.T) {
 		chatRepo := newMockNotifChatRepo()
 		chatRepo.getMembErr = errors.New("db error")
 		svc := NewNotificationService(newMockDeviceTokenRepo(), chatRepo, newMockPushSender())
-		err := svc.SendToChat(ctx, uuid.New(), uuid.New(), model.Notification{Title: "T"})
+		err := svc.SendToChat(ctx, uuid.New(), []uuid.UUID{uuid.New()}, model.Notification{Title: "T"})
 		require.Error(t, err)
 	})
 
@@ -501,7 +608,7 @@ func TestNotificationService_SendToChat_Errors(t *testing.T) {
 		chatID := uuid.New()
 		chatRepo.members[chatID] = []*model.ChatMember{{UserID: senderID}}
 		svc := NewNotificationService(newMockDeviceTokenRepo(), chatRepo, newMockPushSender())
-		err := svc.SendToChat(ctx, chatID, senderID, model.Notification{Title: "T"})
+		err := svc.SendToChat(ctx, chatID, []uuid.UUID{senderID}, model.Notification{Title: "T"})
 		require.NoError(t, err) // empty userIDs = no error
 	})
 }
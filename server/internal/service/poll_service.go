@@ -0,0 +1,458 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/internal/repository"
+	"github.com/otoritech/chatat/internal/ws"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+const (
+	maxPollQuestionLength = 300
+	maxPollOptionLength   = 100
+	minPollOptions        = 2
+	maxPollOptions        = 12
+)
+
+// CreatePollInput holds data for posting a poll.
+type CreatePollInput struct {
+	Question       string     `json:"question"`
+	Options        []string   `json:"options"`
+	MultipleChoice bool       `json:"multipleChoice"`
+	Anonymous      bool       `json:"anonymous"`
+	ClosesAt       *time.Time `json:"closesAt"`
+}
+
+// pollMessageMetadata is stored on a poll message so clients can render the
+// poll before fetching its tallies.
+type pollMessageMetadata struct {
+	PollID         uuid.UUID  `json:"pollId"`
+	Question       string     `json:"question"`
+	Options        []string   `json:"options"`
+	MultipleChoice bool       `json:"multipleChoice"`
+	Anonymous      bool       `json:"anonymous"`
+	ClosesAt       *time.Time `json:"closesAt,omitempty"`
+}
+
+// PollService defines operations for polls in chats and topics.
+type PollService interface {
+	CreateChatPoll(ctx context.Context, chatID, userID uuid.UUID, input CreatePollInput) (*model.Poll, error)
+	CreateTopicPoll(ctx context.Context, topicID, userID uuid.UUID, input CreatePollInput) (*model.Poll, error)
+	GetPoll(ctx context.Context, pollID, userID uuid.UUID) (*model.Poll, error)
+	// Vote replaces the user's votes with the options at positions.
+	Vote(ctx context.Context, pollID, userID uuid.UUID, positions []int) (*model.Poll, error)
+	RetractVote(ctx context.Context, pollID, userID uuid.UUID) (*model.Poll, error)
+	// ClosePoll ends voting early. Only the poll's creator can close it.
+	ClosePoll(ctx context.Context, pollID, userID uuid.UUID) (*model.Poll, error)
+}
+
+type pollService struct {
+	pollRepo    repository.PollRepository
+	chatRepo    repository.ChatRepository
+	topicRepo   repository.TopicRepository
+	messageSvc  MessageService
+	topicMsgSvc TopicMessageService
+	hub         *ws.Hub
+}
+
+// NewPollService creates a new PollService. Poll messages are posted
+// through messageSvc and topicMsgSvc so they follow the usual send rules.
+func NewPollService(
+	pollRepo repository.PollRepository,
+	chatRepo repository.ChatRepository,
+	topicRepo repository.TopicRepository,
+	messageSvc MessageService,
+	topicMsgSvc TopicMessageService,
+	hub *ws.Hub,
+) PollService {
+	return &pollService{
+		pollRepo:    pollRepo,
+		chatRepo:    chatRepo,
+		topicRepo:   topicRepo,
+		messageSvc:  messageSvc,
+		topicMsgSvc: topicMsgSvc,
+		hub:         hub,
+	}
+}
+
+func (s *pollService) CreateChatPoll(ctx context.Context, chatID, userID uuid.UUID, input CreatePollInput) (*model.Poll, error) {
+	input, err := normalizePollInput(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireChatMember(ctx, chatID, userID); err != nil {
+		return nil, err
+	}
+
+	poll, err := s.pollRepo.Create(ctx, pollCreateInput(input, userID, &chatID, nil))
+	if err != nil {
+		return nil, fmt.Errorf("create poll: %w", err)
+	}
+
+	msg, err := s.messageSvc.SendMessage(ctx, SendMessageInput{
+		ChatID:   chatID,
+		SenderID: userID,
+		Content:  poll.Question,
+		Type:     model.MessageTypePoll,
+		Metadata: pollMetadata(poll),
+		fromPoll: true,
+	})
+	if err != nil {
+		s.discard(ctx, poll.ID)
+		return nil, err
+	}
+
+	return s.attach(ctx, poll, msg.ID, userID)
+}
+
+func (s *pollService) CreateTopicPoll(ctx context.Context, topicID, userID uuid.UUID, input CreatePollInput) (*model.Poll, error) {
+	input, err := normalizePollInput(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireTopicMember(ctx, topicID, userID); err != nil {
+		return nil, err
+	}
+
+	poll, err := s.pollRepo.Create(ctx, pollCreateInput(input, userID, nil, &topicID))
+	if err != nil {
+		return nil, fmt.Errorf("create poll: %w", err)
+	}
+
+	msg, err := s.topicMsgSvc.SendMessage(ctx, SendTopicMessageInput{
+		TopicID:  topicID,
+		SenderID: userID,
+		Content:  poll.Question,
+		Type:     model.MessageTypePoll,
+		Metadata: pollMetadata(poll),
+		fromPoll: true,
+	})
+	if err != nil {
+		s.discard(ctx, poll.ID)
+		return nil, err
+	}
+
+	return s.attach(ctx, poll, msg.ID, userID)
+}
+
+func (s *pollService) GetPoll(ctx context.Context, pollID, userID uuid.UUID) (*model.Poll, error) {
+	poll, err := s.accessiblePoll(ctx, pollID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withVotes(ctx, poll, userID)
+}
+
+func (s *pollService) Vote(ctx context.Context, pollID, userID uuid.UUID, positions []int) (*model.Poll, error) {
+	if len(positions) == 0 {
+		return nil, apperror.Validation("positions", "choose at least one option")
+	}
+
+	poll, err := s.accessiblePoll(ctx, pollID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	positions, err = validatePollVote(poll, positions)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.setVotes(ctx, poll, userID, positions)
+}
+
+func (s *pollService) RetractVote(ctx context.Context, pollID, userID uuid.UUID) (*model.Poll, error) {
+	poll, err := s.accessiblePoll(ctx, pollID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.setVotes(ctx, poll, userID, nil)
+}
+
+func (s *pollService) ClosePoll(ctx context.Context, pollID, userID uuid.UUID) (*model.Poll, error) {
+	poll, err := s.accessiblePoll(ctx, pollID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if poll.CreatorID != userID {
+		return nil, apperror.Forbidden("only the poll creator can close it")
+	}
+	if poll.ClosedAt != nil {
+		return nil, apperror.BadRequest("this poll is already closed")
+	}
+
+	poll, err = s.close(ctx, poll)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withVotes(ctx, poll, userID)
+}
+
+// --- Helper Methods ---
+
+// accessiblePoll loads a poll the user can see, closing it first when its
+// deadline has passed so the caller sees the final results.
+func (s *pollService) accessiblePoll(ctx context.Context, pollID, userID uuid.UUID) (*model.Poll, error) {
+	poll, err := s.pollRepo.FindByID(ctx, pollID)
+	if err != nil {
+		return nil, fmt.Errorf("find poll: %w", err)
+	}
+
+	switch {
+	case poll.ChatID != nil:
+		err = s.requireChatMember(ctx, *poll.ChatID, userID)
+	case poll.TopicID != nil:
+		err = s.requireTopicMember(ctx, *poll.TopicID, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if poll.ClosedAt == nil && poll.IsClosed(time.Now()) {
+		return s.close(ctx, poll)
+	}
+
+	return poll, nil
+}
+
+func (s *pollService) setVotes(ctx context.Context, poll *model.Poll, userID uuid.UUID, positions []int) (*model.Poll, error) {
+	if poll.ClosedAt != nil {
+		return nil, apperror.BadRequest("this poll is closed")
+	}
+
+	ok, err := s.pollRepo.SetVotes(ctx, poll.ID, userID, positions)
+	if err != nil {
+		return nil, fmt.Errorf("set poll votes: %w", err)
+	}
+	if !ok {
+		// The deadline passed since the poll was loaded
+		if _, err := s.close(ctx, poll); err != nil {
+			return nil, err
+		}
+		return nil, apperror.BadRequest("this poll is closed")
+	}
+
+	updated, err := s.pollRepo.FindByID(ctx, poll.ID)
+	if err != nil {
+		return nil, fmt.Errorf("find poll: %w", err)
+	}
+	s.broadcastPoll(ctx, updated)
+
+	return s.withVotes(ctx, updated, userID)
+}
+
+// close freezes the poll's results and tells its room.
+func (s *pollService) close(ctx context.Context, poll *model.Poll) (*model.Poll, error) {
+	closedNow, err := s.pollRepo.Close(ctx, poll.ID)
+	if err != nil {
+		return nil, fmt.Errorf("close poll: %w", err)
+	}
+
+	closed, err := s.pollRepo.FindByID(ctx, poll.ID)
+	if err != nil {
+		return nil, fmt.Errorf("find poll: %w", err)
+	}
+	if closedNow {
+		s.broadcastPoll(ctx, closed)
+	}
+
+	return closed, nil
+}
+
+// attach links a freshly sent poll message to its poll.
+func (s *pollService) attach(ctx context.Context, poll *model.Poll, messageID, userID uuid.UUID) (*model.Poll, error) {
+	if err := s.pollRepo.AttachMessage(ctx, poll.ID, messageID); err != nil {
+		return nil, fmt.Errorf("attach poll message: %w", err)
+	}
+	poll.MessageID = &messageID
+
+	return s.withVotes(ctx, poll, userID)
+}
+
+// discard removes a poll whose message could not be sent.
+func (s *pollService) discard(ctx context.Context, pollID uuid.UUID) {
+	if err := s.pollRepo.Delete(ctx, pollID); err != nil {
+		log.Warn().Err(err).Str("poll_id", pollID.String()).Msg("failed to discard unsent poll")
+	}
+}
+
+// withVotes fills in the user's own votes and, unless the poll is
+// anonymous, who voted for each option.
+func (s *pollService) withVotes(ctx context.Context, poll *model.Poll, userID uuid.UUID) (*model.Poll, error) {
+	votes, err := s.pollRepo.ListVotes(ctx, poll.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list poll votes: %w", err)
+	}
+
+	poll.MyVotes = nil
+	for i := range poll.Options {
+		poll.Options[i].Voters = nil
+	}
+	for _, v := range votes {
+		if v.UserID == userID {
+			poll.MyVotes = append(poll.MyVotes, v.Position)
+		}
+		if !poll.Anonymous && v.Position >= 0 && v.Position < len(poll.Options) {
+			poll.Options[v.Position].Voters = append(poll.Options[v.Position].Voters, v.UserID)
+		}
+	}
+	sort.Ints(poll.MyVotes)
+
+	return poll, nil
+}
+
+// broadcastPoll pushes the poll's current tally to its chat or topic room.
+func (s *pollService) broadcastPoll(ctx context.Context, poll *model.Poll) {
+	if s.hub == nil {
+		return
+	}
+
+	var roomID string
+	switch {
+	case poll.ChatID != nil:
+		roomID = "chat:" + poll.ChatID.String()
+	case poll.TopicID != nil:
+		roomID = "topic:" + poll.TopicID.String()
+	default:
+		return
+	}
+
+	// Nobody's own votes go out to the whole room
+	tally, err := s.withVotes(ctx, poll, uuid.Nil)
+	if err != nil {
+		log.Warn().Err(err).Str("poll_id", poll.ID.String()).Msg("failed to load poll votes for broadcast")
+		return
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"type":    ws.WSTypePollUpdated,
+		"payload": tally,
+	})
+	if err == nil {
+		s.hub.SendToRoom(roomID, data, uuid.Nil)
+	}
+}
+
+func (s *pollService) requireChatMember(ctx context.Context, chatID, userID uuid.UUID) error {
+	members, err := s.chatRepo.GetMembers(ctx, chatID)
+	if err != nil {
+		return fmt.Errorf("get chat members: %w", err)
+	}
+	if !chatMemberSet(members)[userID] {
+		return apperror.Forbidden("you are not a member of this chat")
+	}
+	return nil
+}
+
+func (s *pollService) requireTopicMember(ctx context.Context, topicID, userID uuid.UUID) error {
+	members, err := s.topicRepo.GetMembers(ctx, topicID)
+	if err != nil {
+		return fmt.Errorf("get topic members: %w", err)
+	}
+	if !topicMemberSet(members)[userID] {
+		return apperror.Forbidden("you are not a member of this topic")
+	}
+	return nil
+}
+
+// normalizePollInput trims the question and options and checks their limits.
+func normalizePollInput(input CreatePollInput) (CreatePollInput, error) {
+	input.Question = strings.TrimSpace(input.Question)
+	if input.Question == "" {
+		return input, apperror.Validation("question", "poll question is required")
+	}
+	if utf8.RuneCountInString(input.Question) > maxPollQuestionLength {
+		return input, apperror.Validation("question", fmt.Sprintf("poll question must be at most %d characters", maxPollQuestionLength))
+	}
+
+	if len(input.Options) < minPollOptions || len(input.Options) > maxPollOptions {
+		return input, apperror.Validation("options", fmt.Sprintf("a poll needs %d to %d options", minPollOptions, maxPollOptions))
+	}
+	options := make([]string, len(input.Options))
+	seen := make(map[string]bool, len(input.Options))
+	for i, opt := range input.Options {
+		opt = strings.TrimSpace(opt)
+		if opt == "" {
+			return input, apperror.Validation("options", "poll options cannot be empty")
+		}
+		if utf8.RuneCountInString(opt) > maxPollOptionLength {
+			return input, apperror.Validation("options", fmt.Sprintf("poll options must be at most %d characters", maxPollOptionLength))
+		}
+		if seen[strings.ToLower(opt)] {
+			return input, apperror.Validation("options", "poll options must be unique")
+		}
+		seen[strings.ToLower(opt)] = true
+		options[i] = opt
+	}
+	input.Options = options
+
+	if input.ClosesAt != nil && !input.ClosesAt.After(time.Now()) {
+		return input, apperror.Validation("closesAt", "closing time must be in the future")
+	}
+
+	return input, nil
+}
+
+// validatePollVote checks positions against the poll and returns them
+// sorted without duplicates.
+func validatePollVote(poll *model.Poll, positions []int) ([]int, error) {
+	seen := make(map[int]bool, len(positions))
+	unique := make([]int, 0, len(positions))
+	for _, p := range positions {
+		if p < 0 || p >= len(poll.Options) {
+			return nil, apperror.Validation("positions", fmt.Sprintf("option %d does not exist", p))
+		}
+		if !seen[p] {
+			seen[p] = true
+			unique = append(unique, p)
+		}
+	}
+	if !poll.MultipleChoice && len(unique) > 1 {
+		return nil, apperror.Validation("positions", "this poll allows only one choice")
+	}
+	sort.Ints(unique)
+	return unique, nil
+}
+
+func pollCreateInput(input CreatePollInput, creatorID uuid.UUID, chatID, topicID *uuid.UUID) model.CreatePollInput {
+	return model.CreatePollInput{
+		ChatID:         chatID,
+		TopicID:        topicID,
+		CreatorID:      creatorID,
+		Question:       input.Question,
+		Options:        input.Options,
+		MultipleChoice: input.MultipleChoice,
+		Anonymous:      input.Anonymous,
+		ClosesAt:       input.ClosesAt,
+	}
+}
+
+func pollMetadata(poll *model.Poll) json.RawMessage {
+	options := make([]string, len(poll.Options))
+	for i, o := range poll.Options {
+		options[i] = o.Text
+	}
+	data, _ := json.Marshal(pollMessageMetadata{
+		PollID:         poll.ID,
+		Question:       poll.Question,
+		Options:        options,
+		MultipleChoice: poll.MultipleChoice,
+		Anonymous:      poll.Anonymous,
+		ClosesAt:       poll.ClosesAt,
+	})
+	return data
+}
@@ -0,0 +1,393 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+type mockPollRepo struct {
+	polls map[uuid.UUID]*model.Poll
+	votes map[uuid.UUID][]model.PollVote
+}
+
+func newMockPollRepo() *mockPollRepo {
+	return &mockPollRepo{
+		polls: make(map[uuid.UUID]*model.Poll),
+		votes: make(map[uuid.UUID][]model.PollVote),
+	}
+}
+
+func (m *mockPollRepo) Create(_ context.Context, input model.CreatePollInput) (*model.Poll, error) {
+	p := &model.Poll{
+		ID:             uuid.New(),
+		ChatID:         input.ChatID,
+		TopicID:        input.TopicID,
+		CreatorID:      input.CreatorID,
+		Question:       input.Question,
+		MultipleChoice: input.MultipleChoice,
+		Anonymous:      input.Anonymous,
+		ClosesAt:       input.ClosesAt,
+		CreatedAt:      time.Now(),
+	}
+	for i, text := range input.Options {
+		p.Options = append(p.Options, model.PollOption{Position: i, Text: text})
+	}
+	m.polls[p.ID] = p
+	return m.tally(p), nil
+}
+
+func (m *mockPollRepo) AttachMessage(_ context.Context, pollID, messageID uuid.UUID) error {
+	p, ok := m.polls[pollID]
+	if !ok {
+		return apperror.NotFound("poll", pollID.String())
+	}
+	p.MessageID = &messageID
+	return nil
+}
+
+func (m *mockPollRepo) Delete(_ context.Context, id uuid.UUID) error {
+	if _, ok := m.polls[id]; !ok {
+		return apperror.NotFound("poll", id.String())
+	}
+	delete(m.polls, id)
+	delete(m.votes, id)
+	return nil
+}
+
+func (m *mockPollRepo) FindByID(_ context.Context, id uuid.UUID) (*model.Poll, error) {
+	p, ok := m.polls[id]
+	if !ok {
+		return nil, apperror.NotFound("poll", id.String())
+	}
+	return m.tally(p), nil
+}
+
+// tally returns a copy of p with vote counts; closed polls keep the counts
+// frozen when they closed.
+func (m *mockPollRepo) tally(p *model.Poll) *model.Poll {
+	cp := *p
+	cp.Options = append([]model.PollOption(nil), p.Options...)
+	if p.ClosedAt != nil {
+		return &cp
+	}
+	voters := make(map[uuid.UUID]bool)
+	for i := range cp.Options {
+		cp.Options[i].Votes = 0
+	}
+	for _, v := range m.votes[p.ID] {
+		cp.Options[v.Position].Votes++
+		voters[v.UserID] = true
+	}
+	cp.TotalVoters = len(voters)
+	return &cp
+}
+
+func (m *mockPollRepo) ListVotes(_ context.Context, pollID uuid.UUID) ([]model.PollVote, error) {
+	return append([]model.PollVote(nil), m.votes[pollID]...), nil
+}
+
+func (m *mockPollRepo) SetVotes(_ context.Context, pollID, userID uuid.UUID, positions []int) (bool, error) {
+	p, ok := m.polls[pollID]
+	if !ok {
+		return false, apperror.NotFound("poll", pollID.String())
+	}
+	if p.IsClosed(time.Now()) {
+		return false, nil
+	}
+	var kept []model.PollVote
+	for _, v := range m.votes[pollID] {
+		if v.UserID != userID {
+			kept = append(kept, v)
+		}
+	}
+	for _, pos := range positions {
+		kept = append(kept, model.PollVote{Position: pos, UserID: userID})
+	}
+	m.votes[pollID] = kept
+	return true, nil
+}
+
+func (m *mockPollRepo) Close(_ context.Context, id uuid.UUID) (bool, error) {
+	p, ok := m.polls[id]
+	if !ok {
+		return false, apperror.NotFound("poll", id.String())
+	}
+	if p.ClosedAt != nil {
+		return false, nil
+	}
+	frozen := m.tally(p)
+	now := time.Now()
+	frozen.ClosedAt = &now
+	m.polls[id] = frozen
+	return true, nil
+}
+
+// ==================== PollService Tests ====================
+
+func TestPollService_Create(t *testing.T) {
+	ctx := context.Background()
+	pollRepo := newMockPollRepo()
+	chatRepo := newMockChatRepo()
+	msgRepo := newMockMessageRepo()
+	topicRepo := newMockTopicRepo()
+	topicMsgRepo := newMockTopicMsgRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	msgSvc := NewMessageService(msgRepo, newMockMessageStatRepo(), newMockReactionRepo(), newMockThreadRepo(), chatRepo, nil, nil, hub, nil, nil, DefaultMessageConfig())
+	topicMsgSvc := NewTopicMessageService(topicMsgRepo, newMockReactionRepo(), topicRepo, hub, nil, DefaultMessageConfig())
+	svc := NewPollService(pollRepo, chatRepo, topicRepo, msgSvc, topicMsgSvc, hub)
+
+	alice := uuid.New()
+	bob := uuid.New()
+	chatID := uuid.New()
+	chatRepo.chats[chatID] = &model.Chat{ID: chatID, Type: model.ChatTypeGroup, Name: "Keluarga"}
+	_ = chatRepo.AddMember(ctx, chatID, alice, model.MemberRoleOwner)
+	_ = chatRepo.AddMember(ctx, chatID, bob, model.MemberRoleMember)
+	topicID := uuid.New()
+	topicRepo.topics[topicID] = &model.Topic{ID: topicID, Name: "Liburan"}
+	topicRepo.members[topicID] = []*model.TopicMember{
+		{TopicID: topicID, UserID: alice, Role: model.MemberRoleAdmin},
+		{TopicID: topicID, UserID: bob, Role: model.MemberRoleMember},
+	}
+
+	t.Run("chat poll", func(t *testing.T) {
+		poll, err := svc.CreateChatPoll(ctx, chatID, alice, CreatePollInput{
+			Question: " Makan di mana? ",
+			Options:  []string{"Sate", " Bakso "},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Makan di mana?", poll.Question)
+		require.Len(t, poll.Options, 2)
+		assert.Equal(t, "Bakso", poll.Options[1].Text)
+		require.NotNil(t, poll.MessageID)
+
+		msgs := msgRepo.byChat[chatID]
+		require.Len(t, msgs, 1)
+		assert.Equal(t, model.MessageTypePoll, msgs[0].Type)
+		assert.Equal(t, *poll.MessageID, msgs[0].ID)
+
+		var meta pollMessageMetadata
+		require.NoError(t, json.Unmarshal(msgs[0].Metadata, &meta))
+		assert.Equal(t, poll.ID, meta.PollID)
+		assert.Equal(t, []string{"Sate", "Bakso"}, meta.Options)
+	})
+
+	t.Run("topic poll", func(t *testing.T) {
+		poll, err := svc.CreateTopicPoll(ctx, topicID, bob, CreatePollInput{
+			Question: "Ke mana?",
+			Options:  []string{"Bali", "Lombok", "Bromo"},
+		})
+		require.NoError(t, err)
+		require.NotNil(t, poll.TopicID)
+
+		msgs := topicMsgRepo.byTopic[topicID]
+		require.Len(t, msgs, 1)
+		assert.Equal(t, model.MessageTypePoll, msgs[0].Type)
+		assert.Contains(t, string(msgs[0].Metadata), poll.ID.String())
+	})
+
+	t.Run("non-member", func(t *testing.T) {
+		before := len(pollRepo.polls)
+		_, err := svc.CreateChatPoll(ctx, chatID, uuid.New(), CreatePollInput{Question: "Q", Options: []string{"A", "B"}})
+		assert.True(t, apperror.IsForbidden(err))
+		assert.Len(t, pollRepo.polls, before)
+	})
+
+	t.Run("discarded when the message cannot be sent", func(t *testing.T) {
+		chatRepo.chats[chatID].Permissions.OnlyAdminsSend = true
+		defer func() { chatRepo.chats[chatID].Permissions.OnlyAdminsSend = false }()
+
+		before := len(pollRepo.polls)
+		_, err := svc.CreateChatPoll(ctx, chatID, bob, CreatePollInput{Question: "Q", Options: []string{"A", "B"}})
+		assert.True(t, apperror.IsForbidden(err))
+		assert.Len(t, pollRepo.polls, before)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		past := time.Now().Add(-time.Minute)
+		cases := map[string]CreatePollInput{
+			"question": {Options: []string{"A", "B"}},
+			"options":  {Question: "Q", Options: []string{"A"}},
+			"unique":   {Question: "Q", Options: []string{"Ya", "ya"}},
+			"empty":    {Question: "Q", Options: []string{"A", " "}},
+			"closesAt": {Question: "Q", Options: []string{"A", "B"}, ClosesAt: &past},
+		}
+		for want, input := range cases {
+			_, err := svc.CreateChatPoll(ctx, chatID, alice, input)
+			require.Error(t, err, want)
+			assert.Contains(t, err.Error(), want)
+		}
+	})
+}
+
+func TestPollService_Vote(t *testing.T) {
+	ctx := context.Background()
+	pollRepo := newMockPollRepo()
+	chatRepo := newMockChatRepo()
+	msgRepo := newMockMessageRepo()
+	topicRepo := newMockTopicRepo()
+	topicMsgRepo := newMockTopicMsgRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	msgSvc := NewMessageService(msgRepo, newMockMessageStatRepo(), newMockReactionRepo(), newMockThreadRepo(), chatRepo, nil, nil, hub, nil, nil, DefaultMessageConfig())
+	topicMsgSvc := NewTopicMessageService(topicMsgRepo, newMockReactionRepo(), topicRepo, hub, nil, DefaultMessageConfig())
+	svc := NewPollService(pollRepo, chatRepo, topicRepo, msgSvc, topicMsgSvc, hub)
+
+	alice := uuid.New()
+	bob := uuid.New()
+	chatID := uuid.New()
+	chatRepo.chats[chatID] = &model.Chat{ID: chatID, Type: model.ChatTypeGroup, Name: "Keluarga"}
+	_ = chatRepo.AddMember(ctx, chatID, alice, model.MemberRoleOwner)
+	_ = chatRepo.AddMember(ctx, chatID, bob, model.MemberRoleMember)
+	topicID := uuid.New()
+	topicRepo.topics[topicID] = &model.Topic{ID: topicID, Name: "Liburan"}
+	topicRepo.members[topicID] = []*model.TopicMember{
+		{TopicID: topicID, UserID: alice, Role: model.MemberRoleAdmin},
+		{TopicID: topicID, UserID: bob, Role: model.MemberRoleMember},
+	}
+
+	t.Run("single choice", func(t *testing.T) {
+		poll, err := svc.CreateChatPoll(ctx, chatID, alice, CreatePollInput{Question: "Q", Options: []string{"A", "B", "C"}})
+		require.NoError(t, err)
+
+		updated, err := svc.Vote(ctx, poll.ID, bob, []int{1})
+		require.NoError(t, err)
+		assert.Equal(t, 1, updated.Options[1].Votes)
+		assert.Equal(t, []uuid.UUID{bob}, updated.Options[1].Voters)
+		assert.Equal(t, []int{1}, updated.MyVotes)
+
+		// Voting again replaces the previous choice
+		updated, err = svc.Vote(ctx, poll.ID, bob, []int{2})
+		require.NoError(t, err)
+		assert.Equal(t, 0, updated.Options[1].Votes)
+		assert.Equal(t, 1, updated.Options[2].Votes)
+		assert.Equal(t, 1, updated.TotalVoters)
+
+		_, err = svc.Vote(ctx, poll.ID, bob, []int{0, 1})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only one choice")
+
+		_, err = svc.Vote(ctx, poll.ID, bob, []int{3})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not exist")
+
+		_, err = svc.Vote(ctx, poll.ID, uuid.New(), []int{0})
+		assert.True(t, apperror.IsForbidden(err))
+	})
+
+	t.Run("multiple choice and retract", func(t *testing.T) {
+		poll, err := svc.CreateTopicPoll(ctx, topicID, alice, CreatePollInput{Question: "Q", Options: []string{"A", "B", "C"}, MultipleChoice: true})
+		require.NoError(t, err)
+
+		updated, err := svc.Vote(ctx, poll.ID, bob, []int{2, 0, 2})
+		require.NoError(t, err)
+		assert.Equal(t, []int{0, 2}, updated.MyVotes)
+		assert.Equal(t, 1, updated.TotalVoters)
+
+		updated, err = svc.RetractVote(ctx, poll.ID, bob)
+		require.NoError(t, err)
+		assert.Empty(t, updated.MyVotes)
+		assert.Equal(t, 0, updated.TotalVoters)
+	})
+
+	t.Run("anonymous hides voters", func(t *testing.T) {
+		poll, err := svc.CreateChatPoll(ctx, chatID, alice, CreatePollInput{Question: "Q", Options: []string{"A", "B"}, Anonymous: true})
+		require.NoError(t, err)
+
+		_, err = svc.Vote(ctx, poll.ID, bob, []int{0})
+		require.NoError(t, err)
+
+		got, err := svc.GetPoll(ctx, poll.ID, alice)
+		require.NoError(t, err)
+		assert.Equal(t, 1, got.Options[0].Votes)
+		assert.Empty(t, got.Options[0].Voters)
+		assert.Empty(t, got.MyVotes)
+	})
+}
+
+func TestPollService_Close(t *testing.T) {
+	ctx := context.Background()
+	pollRepo := newMockPollRepo()
+	chatRepo := newMockChatRepo()
+	msgRepo := newMockMessageRepo()
+	topicRepo := newMockTopicRepo()
+	topicMsgRepo := newMockTopicMsgRepo()
+	hub := newTestHub()
+	defer hub.Shutdown()
+
+	msgSvc := NewMessageService(msgRepo, newMockMessageStatRepo(), newMockReactionRepo(), newMockThreadRepo(), chatRepo, nil, nil, hub, nil, nil, DefaultMessageConfig())
+	topicMsgSvc := NewTopicMessageService(topicMsgRepo, newMockReactionRepo(), topicRepo, hub, nil, DefaultMessageConfig())
+	svc := NewPollService(pollRepo, chatRepo, topicRepo, msgSvc, topicMsgSvc, hub)
+
+	alice := uuid.New()
+	bob := uuid.New()
+	chatID := uuid.New()
+	chatRepo.chats[chatID] = &model.Chat{ID: chatID, Type: model.ChatTypeGroup, Name: "Keluarga"}
+	_ = chatRepo.AddMember(ctx, chatID, alice, model.MemberRoleOwner)
+	_ = chatRepo.AddMember(ctx, chatID, bob, model.MemberRoleMember)
+	topicID := uuid.New()
+	topicRepo.topics[topicID] = &model.Topic{ID: topicID, Name: "Liburan"}
+	topicRepo.members[topicID] = []*model.TopicMember{
+		{TopicID: topicID, UserID: alice, Role: model.MemberRoleAdmin},
+		{TopicID: topicID, UserID: bob, Role: model.MemberRoleMember},
+	}
+
+	t.Run("creator closes", func(t *testing.T) {
+		poll, err := svc.CreateChatPoll(ctx, chatID, alice, CreatePollInput{Question: "Q", Options: []string{"A", "B"}})
+		require.NoError(t, err)
+		_, err = svc.Vote(ctx, poll.ID, bob, []int{0})
+		require.NoError(t, err)
+
+		_, err = svc.ClosePoll(ctx, poll.ID, bob)
+		assert.True(t, apperror.IsForbidden(err))
+
+		closed, err := svc.ClosePoll(ctx, poll.ID, alice)
+		require.NoError(t, err)
+		require.NotNil(t, closed.ClosedAt)
+		assert.Equal(t, 1, closed.Options[0].Votes)
+
+		// Final results no longer change
+		_, err = svc.Vote(ctx, poll.ID, alice, []int{1})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "closed")
+		_, err = svc.RetractVote(ctx, poll.ID, bob)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "closed")
+
+		_, err = svc.ClosePoll(ctx, poll.ID, alice)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already closed")
+	})
+
+	t.Run("closes at its deadline", func(t *testing.T) {
+		closesAt := time.Now().Add(time.Hour)
+		poll, err := svc.CreateChatPoll(ctx, chatID, alice, CreatePollInput{Question: "Q", Options: []string{"A", "B"}, ClosesAt: &closesAt})
+		require.NoError(t, err)
+		_, err = svc.Vote(ctx, poll.ID, bob, []int{1})
+		require.NoError(t, err)
+
+		past := time.Now().Add(-time.Second)
+		pollRepo.polls[poll.ID].ClosesAt = &past
+
+		got, err := svc.GetPoll(ctx, poll.ID, bob)
+		require.NoError(t, err)
+		require.NotNil(t, got.ClosedAt)
+		assert.Equal(t, 1, got.Options[1].Votes)
+		assert.Equal(t, []int{1}, got.MyVotes)
+
+		_, err = svc.Vote(ctx, poll.ID, alice, []int{0})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "closed")
+	})
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/internal/repository"
+)
+
+// Values for the visibility fields of model.PrivacySettings.
+const (
+	VisibilityEveryone = "everyone"
+	VisibilityContacts = "contacts"
+	VisibilityNobody   = "nobody"
+)
+
+// Visibility says which parts of a user's presence and profile a viewer may see.
+type Visibility struct {
+	Online       bool
+	LastSeen     bool
+	ProfilePhoto bool
+}
+
+// fullVisibility is what users see of themselves.
+var fullVisibility = Visibility{Online: true, LastSeen: true, ProfilePhoto: true}
+
+// Apply returns a copy of user with the fields the viewer may not see
+// cleared: the avatar becomes empty and LastSeen the zero time.
+func (v Visibility) Apply(user *model.User) *model.User {
+	filtered := *user
+	if !v.ProfilePhoto {
+		filtered.Avatar = ""
+	}
+	if !v.LastSeen {
+		filtered.LastSeen = time.Time{}
+	}
+	return &filtered
+}
+
+// PrivacyPolicy decides what each viewer may see of another user. "contacts"
+// settings are checked against the owner's contacts, so a user who saved
+// someone's number does not gain access unless the owner saved theirs too.
+// Users who blocked each other see nothing of one another.
+type PrivacyPolicy interface {
+	// Visibility returns what viewerID may see of owner.
+	Visibility(ctx context.Context, viewerID uuid.UUID, owner *model.User) (Visibility, error)
+	// VisibilityFor returns what each viewer may see of owner, for broadcasts.
+	VisibilityFor(ctx context.Context, owner *model.User, viewerIDs []uuid.UUID) (map[uuid.UUID]Visibility, error)
+}
+
+type privacyPolicy struct {
+	contactRepo repository.ContactRepository
+	blockRepo   repository.UserBlockRepository
+}
+
+// NewPrivacyPolicy creates a PrivacyPolicy backed by the contacts graph.
+// blockRepo may be nil, in which case blocks are not taken into account.
+func NewPrivacyPolicy(contactRepo repository.ContactRepository, blockRepo repository.UserBlockRepository) PrivacyPolicy {
+	return &privacyPolicy{contactRepo: contactRepo, blockRepo: blockRepo}
+}
+
+func (p *privacyPolicy) Visibility(ctx context.Context, viewerID uuid.UUID, owner *model.User) (Visibility, error) {
+	if viewerID == owner.ID {
+		return fullVisibility, nil
+	}
+
+	if p.blockRepo != nil {
+		blocked, err := p.blockRepo.IsBlocked(ctx, owner.ID, viewerID)
+		if err != nil {
+			return Visibility{}, fmt.Errorf("check block: %w", err)
+		}
+		if blocked {
+			return Visibility{}, nil
+		}
+	}
+
+	isContact := false
+	if usesContacts(owner.PrivacySettings) {
+		var err error
+		isContact, err = p.contactRepo.IsContact(ctx, owner.ID, viewerID)
+		if err != nil {
+			return Visibility{}, fmt.Errorf("check contact: %w", err)
+		}
+	}
+
+	return evaluateVisibility(owner.PrivacySettings, isContact), nil
+}
+
+func (p *privacyPolicy) VisibilityFor(ctx context.Context, owner *model.User, viewerIDs []uuid.UUID) (map[uuid.UUID]Visibility, error) {
+	contacts := make(map[uuid.UUID]bool)
+	if usesContacts(owner.PrivacySettings) {
+		saved, err := p.contactRepo.FindByUserID(ctx, owner.ID)
+		if err != nil {
+			return nil, fmt.Errorf("find owner contacts: %w", err)
+		}
+		for _, c := range saved {
+			contacts[c.ContactUserID] = true
+		}
+	}
+
+	blocked := make(map[uuid.UUID]bool)
+	if p.blockRepo != nil {
+		peers, err := p.blockRepo.FindBlockedPeers(ctx, owner.ID)
+		if err != nil {
+			return nil, fmt.Errorf("find blocked peers: %w", err)
+		}
+		for _, id := range peers {
+			blocked[id] = true
+		}
+	}
+
+	result := make(map[uuid.UUID]Visibility, len(viewerIDs))
+	for _, viewerID := range viewerIDs {
+		if viewerID == owner.ID {
+			result[viewerID] = fullVisibility
+			continue
+		}
+		if blocked[viewerID] {
+			result[viewerID] = Visibility{}
+			continue
+		}
+		result[viewerID] = evaluateVisibility(owner.PrivacySettings, contacts[viewerID])
+	}
+	return result, nil
+}
+
+// visibilityOf asks policy what viewerID may see of owner. A nil policy
+// hides nothing.
+func visibilityOf(ctx context.Context, policy PrivacyPolicy, viewerID uuid.UUID, owner *model.User) (Visibility, error) {
+	if policy == nil {
+		return fullVisibility, nil
+	}
+	return policy.Visibility(ctx, viewerID, owner)
+}
+
+func usesContacts(ps model.PrivacySettings) bool {
+	return ps.OnlineVisibility == VisibilityContacts ||
+		ps.LastSeenVisibility == VisibilityContacts ||
+		ps.ProfilePhotoVisibility == VisibilityContacts
+}
+
+func evaluateVisibility(ps model.PrivacySettings, isContact bool) Visibility {
+	return Visibility{
+		Online:       visibleTo(ps.OnlineVisibility, isContact),
+		LastSeen:     visibleTo(ps.LastSeenVisibility, isContact),
+		ProfilePhoto: visibleTo(ps.ProfilePhotoVisibility, isContact),
+	}
+}
+
+// visibleTo treats unset values as "everyone", the default for new users.
+func visibleTo(setting string, isContact bool) bool {
+	switch setting {
+	case VisibilityNobody:
+		return false
+	case VisibilityContacts:
+		return isContact
+	default:
+		return true
+	}
+}
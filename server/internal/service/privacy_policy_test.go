@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otoritech/chatat/internal/model"
+)
+
+func TestPrivacyPolicy_Visibility(t *testing.T) {
+	ctx := context.Background()
+	contactRepo := newMockContactRepo()
+	policy := NewPrivacyPolicy(contactRepo, nil)
+
+	owner := &model.User{
+		ID: uuid.New(),
+		PrivacySettings: model.PrivacySettings{
+			LastSeenVisibility:     VisibilityContacts,
+			OnlineVisibility:       VisibilityNobody,
+			ProfilePhotoVisibility: VisibilityEveryone,
+		},
+	}
+	contact := uuid.New()
+	stranger := uuid.New()
+	_ = contactRepo.Upsert(ctx, owner.ID, contact, "Contact")
+
+	tests := []struct {
+		name     string
+		viewerID uuid.UUID
+		want     Visibility
+	}{
+		{"self", owner.ID, Visibility{Online: true, LastSeen: true, ProfilePhoto: true}},
+		{"contact", contact, Visibility{Online: false, LastSeen: true, ProfilePhoto: true}},
+		{"stranger", stranger, Visibility{Online: false, LastSeen: false, ProfilePhoto: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := policy.Visibility(ctx, tt.viewerID, owner)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("batch matches single lookups", func(t *testing.T) {
+		all, err := policy.VisibilityFor(ctx, owner, []uuid.UUID{owner.ID, contact, stranger})
+		require.NoError(t, err)
+		for _, tt := range tests {
+			assert.Equal(t, tt.want, all[tt.viewerID], tt.name)
+		}
+	})
+
+	t.Run("unset settings show everything", func(t *testing.T) {
+		got, err := policy.Visibility(ctx, stranger, &model.User{ID: uuid.New()})
+		require.NoError(t, err)
+		assert.Equal(t, fullVisibility, got)
+	})
+
+	t.Run("blocked users see nothing", func(t *testing.T) {
+		blockRepo := newMockUserBlockRepo()
+		_ = blockRepo.Block(ctx, owner.ID, contact)
+		blocking := NewPrivacyPolicy(contactRepo, blockRepo)
+
+		got, err := blocking.Visibility(ctx, contact, owner)
+		require.NoError(t, err)
+		assert.Equal(t, Visibility{}, got)
+
+		all, err := blocking.VisibilityFor(ctx, owner, []uuid.UUID{owner.ID, contact, stranger})
+		require.NoError(t, err)
+		assert.Equal(t, Visibility{}, all[contact])
+		assert.Equal(t, fullVisibility, all[owner.ID])
+		assert.Equal(t, tests[2].want, all[stranger])
+	})
+
+	t.Run("contacts lookup error", func(t *testing.T) {
+		failing := newMockContactRepo()
+		failing.findByUIDErr = assert.AnError
+		_, err := NewPrivacyPolicy(failing, nil).VisibilityFor(ctx, owner, []uuid.UUID{stranger})
+		require.Error(t, err)
+	})
+}
+
+func TestVisibility_Apply(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Name: "Rina", Avatar: "\U0001F60A", LastSeen: time.Now()}
+
+	hidden := Visibility{}.Apply(user)
+	assert.Empty(t, hidden.Avatar)
+	assert.True(t, hidden.LastSeen.IsZero())
+	assert.Equal(t, "Rina", hidden.Name)
+
+	// The original is not modified
+	assert.Equal(t, "\U0001F60A", user.Avatar)
+	assert.False(t, user.LastSeen.IsZero())
+
+	shown := fullVisibility.Apply(user)
+	assert.Equal(t, user.Avatar, shown.Avatar)
+	assert.Equal(t, user.LastSeen, shown.LastSeen)
+}
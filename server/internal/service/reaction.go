@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/internal/repository"
+	"github.com/otoritech/chatat/internal/ws"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+// maxEmojiLength bounds a reaction in bytes; multi-codepoint emoji such as
+// flags and skin-tone sequences fit comfortably.
+const maxEmojiLength = 32
+
+// Code points that join and modify the parts of an emoji sequence.
+const (
+	emojiZWJ       = '\u200D'
+	emojiVS16      = '\uFE0F'
+	emojiKeycap    = '\u20E3'
+	emojiCancelTag = '\U000E007F'
+	emojiTagFirst  = '\U000E0020'
+	emojiTagLast   = '\U000E007E'
+	emojiToneFirst = '\U0001F3FB'
+	emojiToneLast  = '\U0001F3FF'
+	emojiFlagFirst = '\U0001F1E6'
+	emojiFlagLast  = '\U0001F1FF'
+)
+
+// pictographicRanges are the code points that can start an emoji: the
+// Extended_Pictographic property. Regional indicators only come in flag
+// pairs and skin tones only modify a pictograph, so neither is included.
+var pictographicRanges = [][2]rune{
+	{0x00A9, 0x00A9}, {0x00AE, 0x00AE}, {0x203C, 0x203C}, {0x2049, 0x2049},
+	{0x2122, 0x2122}, {0x2139, 0x2139}, {0x2194, 0x2199}, {0x21A9, 0x21AA},
+	{0x231A, 0x231B}, {0x2328, 0x2328}, {0x2388, 0x2388}, {0x23CF, 0x23CF},
+	{0x23E9, 0x23F3}, {0x23F8, 0x23FA}, {0x24C2, 0x24C2}, {0x25AA, 0x25AB},
+	{0x25B6, 0x25B6}, {0x25C0, 0x25C0}, {0x25FB, 0x25FE}, {0x2600, 0x27BF},
+	{0x2934, 0x2935}, {0x2B05, 0x2B07}, {0x2B1B, 0x2B1C}, {0x2B50, 0x2B50},
+	{0x2B55, 0x2B55}, {0x3030, 0x3030}, {0x303D, 0x303D}, {0x3297, 0x3297},
+	{0x3299, 0x3299}, {0x1F000, 0x1F1E5}, {0x1F200, 0x1F3FA}, {0x1F400, 0x1FAFF},
+	{0x1FC00, 0x1FFFD},
+}
+
+// Reaction actions carried by ReactionEvent.
+const (
+	ReactionActionAdded   = "added"
+	ReactionActionRemoved = "removed"
+)
+
+// ReactionEvent is the WebSocket payload for a reaction change. Exactly one of
+// ChatID or TopicID is set. Reactions holds the message's updated aggregate.
+type ReactionEvent struct {
+	ChatID    *uuid.UUID              `json:"chatId,omitempty"`
+	TopicID   *uuid.UUID              `json:"topicId,omitempty"`
+	MessageID uuid.UUID               `json:"messageId"`
+	UserID    uuid.UUID               `json:"userId"`
+	Emoji     string                  `json:"emoji"`
+	Action    string                  `json:"action"`
+	Reactions []model.ReactionSummary `json:"reactions"`
+}
+
+// validateEmoji normalizes and checks a reaction emoji.
+func validateEmoji(emoji string) (string, error) {
+	emoji = strings.TrimSpace(emoji)
+	if emoji == "" {
+		return "", apperror.Validation("emoji", "emoji is required")
+	}
+	if len(emoji) > maxEmojiLength {
+		return "", apperror.Validation("emoji", "emoji is too long")
+	}
+	if !isSingleEmoji(emoji) {
+		return "", apperror.Validation("emoji", "emoji must be a single emoji")
+	}
+	return emoji, nil
+}
+
+// isSingleEmoji reports whether s is exactly one emoji: a flag, a keycap, or
+// pictographs with optional presentation selector, skin tone and tag
+// sequence, joined by zero-width joiners.
+func isSingleEmoji(s string) bool {
+	runes := []rune(s)
+	if len(runes) == 2 && isFlagLetter(runes[0]) && isFlagLetter(runes[1]) {
+		return true
+	}
+	if isKeycapEmoji(runes) {
+		return true
+	}
+
+	i := 0
+	for {
+		if i >= len(runes) || !isPictographic(runes[i]) {
+			return false
+		}
+		i++
+		if i < len(runes) && runes[i] == emojiVS16 {
+			i++
+		}
+		if i < len(runes) && runes[i] >= emojiToneFirst && runes[i] <= emojiToneLast {
+			i++
+		}
+		if i < len(runes) && runes[i] >= emojiTagFirst && runes[i] <= emojiTagLast {
+			for i < len(runes) && runes[i] >= emojiTagFirst && runes[i] <= emojiTagLast {
+				i++
+			}
+			if i >= len(runes) || runes[i] != emojiCancelTag {
+				return false
+			}
+			i++
+		}
+		if i == len(runes) {
+			return true
+		}
+		if runes[i] != emojiZWJ {
+			return false
+		}
+		i++
+	}
+}
+
+// isKeycapEmoji matches a digit, # or * followed by the keycap mark.
+func isKeycapEmoji(runes []rune) bool {
+	if len(runes) < 2 || len(runes) > 3 || !strings.ContainsRune("0123456789#*", runes[0]) {
+		return false
+	}
+	if len(runes) == 3 && runes[1] != emojiVS16 {
+		return false
+	}
+	return runes[len(runes)-1] == emojiKeycap
+}
+
+func isFlagLetter(r rune) bool {
+	return r >= emojiFlagFirst && r <= emojiFlagLast
+}
+
+func isPictographic(r rune) bool {
+	for _, rng := range pictographicRanges {
+		if r >= rng[0] && r <= rng[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// messageReactions returns the aggregated reactions of a single message.
+func messageReactions(ctx context.Context, repo repository.ReactionRepository, messageID uuid.UUID) ([]model.ReactionSummary, error) {
+	reactions, err := repo.ListByMessages(ctx, []uuid.UUID{messageID})
+	if err != nil {
+		return nil, fmt.Errorf("list reactions: %w", err)
+	}
+	return model.SummarizeReactions(reactions[messageID]), nil
+}
+
+// broadcastReaction pushes a reaction change to a chat or topic room.
+func broadcastReaction(hub *ws.Hub, roomID string, event ReactionEvent) {
+	if hub == nil {
+		return
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"type":    ws.WSTypeReaction,
+		"payload": event,
+	})
+	if err == nil {
+		hub.SendToRoom(roomID, data, uuid.Nil)
+	}
+}
@@ -0,0 +1,333 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/internal/repository"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+// ScheduleMessageInput holds the input for scheduling a message.
+type ScheduleMessageInput struct {
+	ChatID    uuid.UUID         `json:"-"`
+	SenderID  uuid.UUID         `json:"-"`
+	Content   string            `json:"content"`
+	ReplyToID *uuid.UUID        `json:"replyToId"`
+	Type      model.MessageType `json:"type"`
+	Metadata  json.RawMessage   `json:"metadata"`
+	SendAt    time.Time         `json:"sendAt"`
+}
+
+// UpdateScheduledMessageInput holds the fields that can change before delivery.
+type UpdateScheduledMessageInput struct {
+	Content *string    `json:"content"`
+	SendAt  *time.Time `json:"sendAt"`
+}
+
+// ScheduledMessageConfig holds scheduled delivery settings.
+type ScheduledMessageConfig struct {
+	// MaxAhead is how far in the future a message may be scheduled.
+	MaxAhead time.Duration
+	// BatchSize is how many due messages one dispatch claims.
+	BatchSize int
+	// ClaimTimeout is how long a claimed message is left alone before it can
+	// be claimed again, either because its dispatcher died or to retry it.
+	ClaimTimeout time.Duration
+	// MaxAttempts is how many times delivery is tried before giving up.
+	MaxAttempts int
+}
+
+// DefaultScheduledMessageConfig returns sensible defaults.
+func DefaultScheduledMessageConfig() ScheduledMessageConfig {
+	return ScheduledMessageConfig{
+		MaxAhead:     365 * 24 * time.Hour,
+		BatchSize:    50,
+		ClaimTimeout: 5 * time.Minute,
+		MaxAttempts:  3,
+	}
+}
+
+// ScheduledMessageService defines operations for scheduled messages.
+type ScheduledMessageService interface {
+	Schedule(ctx context.Context, input ScheduleMessageInput) (*model.ScheduledMessage, error)
+	List(ctx context.Context, chatID, userID uuid.UUID) ([]*model.ScheduledMessage, error)
+	Update(ctx context.Context, chatID, scheduledID, userID uuid.UUID, input UpdateScheduledMessageInput) (*model.ScheduledMessage, error)
+	Cancel(ctx context.Context, chatID, scheduledID, userID uuid.UUID) error
+	// DispatchDue sends the messages that are due and returns how many were sent.
+	DispatchDue(ctx context.Context) (int, error)
+}
+
+type scheduledMessageService struct {
+	scheduledRepo repository.ScheduledMessageRepository
+	chatRepo      repository.ChatRepository
+	messageSvc    MessageService
+	config        ScheduledMessageConfig
+}
+
+// NewScheduledMessageService creates a new ScheduledMessageService. Due
+// messages are delivered through messageSvc like any other message.
+func NewScheduledMessageService(
+	scheduledRepo repository.ScheduledMessageRepository,
+	chatRepo repository.ChatRepository,
+	messageSvc MessageService,
+	config ScheduledMessageConfig,
+) ScheduledMessageService {
+	return &scheduledMessageService{
+		scheduledRepo: scheduledRepo,
+		chatRepo:      chatRepo,
+		messageSvc:    messageSvc,
+		config:        config,
+	}
+}
+
+func (s *scheduledMessageService) Schedule(ctx context.Context, input ScheduleMessageInput) (*model.ScheduledMessage, error) {
+	if input.Type == "" {
+		input.Type = model.MessageTypeText
+	}
+	if input.Type == model.MessageTypeText && input.Content == "" {
+		return nil, apperror.Validation("content", "message content cannot be empty")
+	}
+	if input.Type == model.MessageTypePoll {
+		return nil, apperror.BadRequest("polls cannot be scheduled")
+	}
+	// Metadata is prepared again on delivery, when a live location starts
+	if _, _, err := prepareMessageMetadata(ctx, nil, input.Type, input.Content, input.Metadata); err != nil {
+		return nil, err
+	}
+	if err := s.validateSendAt(input.SendAt); err != nil {
+		return nil, err
+	}
+
+	if err := s.requireMember(ctx, input.ChatID, input.SenderID); err != nil {
+		return nil, err
+	}
+
+	msg, err := s.scheduledRepo.Create(ctx, model.CreateScheduledMessageInput{
+		ChatID:    input.ChatID,
+		SenderID:  input.SenderID,
+		Content:   input.Content,
+		ReplyToID: input.ReplyToID,
+		Type:      input.Type,
+		Metadata:  input.Metadata,
+		SendAt:    input.SendAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create scheduled message: %w", err)
+	}
+
+	return msg, nil
+}
+
+func (s *scheduledMessageService) List(ctx context.Context, chatID, userID uuid.UUID) ([]*model.ScheduledMessage, error) {
+	if err := s.requireMember(ctx, chatID, userID); err != nil {
+		return nil, err
+	}
+
+	messages, err := s.scheduledRepo.ListBySender(ctx, chatID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list scheduled messages: %w", err)
+	}
+	return messages, nil
+}
+
+func (s *scheduledMessageService) Update(ctx context.Context, chatID, scheduledID, userID uuid.UUID, input UpdateScheduledMessageInput) (*model.ScheduledMessage, error) {
+	msg, err := s.findOwned(ctx, chatID, scheduledID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	content := msg.Content
+	if input.Content != nil {
+		content = *input.Content
+	}
+	if msg.Type == model.MessageTypeText && content == "" {
+		return nil, apperror.Validation("content", "message content cannot be empty")
+	}
+
+	sendAt := msg.SendAt
+	if input.SendAt != nil {
+		sendAt = *input.SendAt
+	}
+	// A failed message is queued again, so it needs a fresh time as well
+	if input.SendAt != nil || msg.Status == model.ScheduledStatusFailed {
+		if err := s.validateSendAt(sendAt); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.scheduledRepo.Update(ctx, scheduledID, content, sendAt)
+}
+
+func (s *scheduledMessageService) Cancel(ctx context.Context, chatID, scheduledID, userID uuid.UUID) error {
+	if _, err := s.findOwned(ctx, chatID, scheduledID, userID); err != nil {
+		return err
+	}
+
+	return s.scheduledRepo.Cancel(ctx, scheduledID)
+}
+
+func (s *scheduledMessageService) DispatchDue(ctx context.Context) (int, error) {
+	due, err := s.scheduledRepo.ClaimDue(ctx, time.Now().Add(-s.config.ClaimTimeout), s.config.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("claim due messages: %w", err)
+	}
+
+	sent := 0
+	for _, scheduled := range due {
+		if s.deliver(ctx, scheduled) {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// deliver sends one claimed message and records the outcome. Messages the
+// sender is no longer allowed to send (a 4xx error) fail right away;
+// anything else, including internal errors, is retried until MaxAttempts.
+func (s *scheduledMessageService) deliver(ctx context.Context, scheduled *model.ScheduledMessage) bool {
+	logger := log.With().Str("scheduled_message_id", scheduled.ID.String()).Logger()
+
+	msg, err := s.messageSvc.SendMessage(ctx, SendMessageInput{
+		ChatID:    scheduled.ChatID,
+		SenderID:  scheduled.SenderID,
+		Content:   scheduled.Content,
+		ReplyToID: scheduled.ReplyToID,
+		Type:      scheduled.Type,
+		Metadata:  scheduled.Metadata,
+	})
+	if err == nil {
+		if err := s.scheduledRepo.MarkSent(ctx, scheduled.ID, msg.ID); err != nil {
+			logger.Error().Err(err).Msg("failed to mark scheduled message sent")
+		}
+		return true
+	}
+
+	var appErr *apperror.AppError
+	switch {
+	case errors.As(err, &appErr) && isClientError(appErr):
+		err = s.scheduledRepo.MarkFailed(ctx, scheduled.ID, appErr.Message)
+	case scheduled.Attempts >= s.config.MaxAttempts:
+		logger.Error().Err(err).Msg("giving up on scheduled message")
+		err = s.scheduledRepo.MarkFailed(ctx, scheduled.ID, "message could not be delivered")
+	default:
+		logger.Warn().Err(err).Int("attempts", scheduled.Attempts).Msg("scheduled message delivery failed, will retry")
+		err = s.scheduledRepo.Release(ctx, scheduled.ID)
+	}
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to record scheduled message failure")
+	}
+	return false
+}
+
+// isClientError reports whether an error rejects the message itself, so
+// sending it again cannot succeed.
+func isClientError(err *apperror.AppError) bool {
+	return err.HTTPStatus >= http.StatusBadRequest && err.HTTPStatus < http.StatusInternalServerError
+}
+
+func (s *scheduledMessageService) validateSendAt(sendAt time.Time) error {
+	now := time.Now()
+	if !sendAt.After(now) {
+		return apperror.Validation("sendAt", "send time must be in the future")
+	}
+	if sendAt.After(now.Add(s.config.MaxAhead)) {
+		return apperror.Validation("sendAt", "send time is too far in the future")
+	}
+	return nil
+}
+
+func (s *scheduledMessageService) requireMember(ctx context.Context, chatID, userID uuid.UUID) error {
+	members, err := s.chatRepo.GetMembers(ctx, chatID)
+	if err != nil {
+		return fmt.Errorf("get chat members: %w", err)
+	}
+	if !chatMemberSet(members)[userID] {
+		return apperror.Forbidden("you are not a member of this chat")
+	}
+	return nil
+}
+
+// findOwned loads a scheduled message that belongs to the user in the chat.
+func (s *scheduledMessageService) findOwned(ctx context.Context, chatID, scheduledID, userID uuid.UUID) (*model.ScheduledMessage, error) {
+	msg, err := s.scheduledRepo.FindByID(ctx, scheduledID)
+	if err != nil {
+		return nil, err
+	}
+	// Other users' scheduled messages are private, so they look missing
+	if msg.ChatID != chatID || msg.SenderID != userID {
+		return nil, apperror.NotFound("scheduled message", scheduledID.String())
+	}
+	return msg, nil
+}
+
+// ScheduledDispatcher periodically delivers due scheduled messages. Every
+// replica may run one; claims are exclusive, so each message is sent once.
+type ScheduledDispatcher struct {
+	svc      ScheduledMessageService
+	interval time.Duration
+	done     chan struct{}
+}
+
+// NewScheduledDispatcher creates a dispatcher that checks for due messages every interval.
+func NewScheduledDispatcher(svc ScheduledMessageService, interval time.Duration) *ScheduledDispatcher {
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	return &ScheduledDispatcher{
+		svc:      svc,
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+}
+
+// Run starts the dispatch loop. Should be called in a goroutine.
+func (d *ScheduledDispatcher) Run() {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.done:
+			return
+		case <-ticker.C:
+			d.dispatch()
+		}
+	}
+}
+
+// Stop ends the dispatch loop.
+func (d *ScheduledDispatcher) Stop() {
+	close(d.done)
+}
+
+// dispatch drains due messages batch by batch so a backlog is cleared in one tick.
+func (d *ScheduledDispatcher) dispatch() {
+	for {
+		select {
+		case <-d.done:
+			return
+		default:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), d.interval)
+		sent, err := d.svc.DispatchDue(ctx)
+		cancel()
+		if err != nil {
+			log.Error().Err(err).Msg("failed to dispatch scheduled messages")
+			return
+		}
+		if sent == 0 {
+			return
+		}
+		log.Debug().Int("sent", sent).Msg("dispatched scheduled messages")
+	}
+}
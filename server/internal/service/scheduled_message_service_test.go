@@ -0,0 +1,449 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otoritech/chatat/internal/model"
+	"github.com/otoritech/chatat/pkg/apperror"
+)
+
+// -- Mock scheduled message repo --
+
+type mockScheduledMsgRepo struct {
+	messages  map[uuid.UUID]*model.ScheduledMessage
+	claimedAt map[uuid.UUID]time.Time
+	mu        sync.Mutex
+}
+
+func newMockScheduledMsgRepo() *mockScheduledMsgRepo {
+	return &mockScheduledMsgRepo{
+		messages:  make(map[uuid.UUID]*model.ScheduledMessage),
+		claimedAt: make(map[uuid.UUID]time.Time),
+	}
+}
+
+func (m *mockScheduledMsgRepo) Create(_ context.Context, input model.CreateScheduledMessageInput) (*model.ScheduledMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	msg := &model.ScheduledMessage{
+		ID: uuid.New(), ChatID: input.ChatID, SenderID: input.SenderID, Content: input.Content,
+		ReplyToID: input.ReplyToID, Type: input.Type, Metadata: input.Metadata, SendAt: input.SendAt,
+		Status: model.ScheduledStatusPending, CreatedAt: now, UpdatedAt: now,
+	}
+	m.messages[msg.ID] = msg
+	copied := *msg
+	return &copied, nil
+}
+
+func (m *mockScheduledMsgRepo) FindByID(_ context.Context, id uuid.UUID) (*model.ScheduledMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	msg, ok := m.messages[id]
+	if !ok {
+		return nil, apperror.NotFound("scheduled message", id.String())
+	}
+	copied := *msg
+	return &copied, nil
+}
+
+func (m *mockScheduledMsgRepo) ListBySender(_ context.Context, chatID, senderID uuid.UUID) ([]*model.ScheduledMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*model.ScheduledMessage
+	for _, msg := range m.messages {
+		if msg.ChatID != chatID || msg.SenderID != senderID {
+			continue
+		}
+		if msg.Status == model.ScheduledStatusSent || msg.Status == model.ScheduledStatusCancelled {
+			continue
+		}
+		copied := *msg
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].SendAt.Before(result[j].SendAt) })
+	return result, nil
+}
+
+func (m *mockScheduledMsgRepo) Update(_ context.Context, id uuid.UUID, content string, sendAt time.Time) (*model.ScheduledMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	msg, ok := m.messages[id]
+	if !ok {
+		return nil, apperror.NotFound("scheduled message", id.String())
+	}
+	if msg.Status != model.ScheduledStatusPending && msg.Status != model.ScheduledStatusFailed {
+		return nil, apperror.Conflict("scheduled message is no longer pending")
+	}
+	msg.Content = content
+	msg.SendAt = sendAt
+	msg.Status = model.ScheduledStatusPending
+	msg.Attempts = 0
+	msg.Error = ""
+	delete(m.claimedAt, id)
+	copied := *msg
+	return &copied, nil
+}
+
+func (m *mockScheduledMsgRepo) Cancel(_ context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	msg, ok := m.messages[id]
+	if !ok {
+		return apperror.NotFound("scheduled message", id.String())
+	}
+	if msg.Status != model.ScheduledStatusPending && msg.Status != model.ScheduledStatusFailed {
+		return apperror.Conflict("scheduled message is no longer pending")
+	}
+	msg.Status = model.ScheduledStatusCancelled
+	return nil
+}
+
+func (m *mockScheduledMsgRepo) ClaimDue(_ context.Context, staleBefore time.Time, limit int) ([]*model.ScheduledMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	var due []*model.ScheduledMessage
+	for _, msg := range m.messages {
+		if msg.SendAt.After(now) {
+			continue
+		}
+		if msg.Status != model.ScheduledStatusPending && msg.Status != model.ScheduledStatusSending {
+			continue
+		}
+		if claimed, ok := m.claimedAt[msg.ID]; ok && !claimed.Before(staleBefore) {
+			continue
+		}
+		due = append(due, msg)
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].SendAt.Before(due[j].SendAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	result := make([]*model.ScheduledMessage, 0, len(due))
+	for _, msg := range due {
+		msg.Status = model.ScheduledStatusSending
+		msg.Attempts++
+		m.claimedAt[msg.ID] = now
+		copied := *msg
+		result = append(result, &copied)
+	}
+	return result, nil
+}
+
+func (m *mockScheduledMsgRepo) MarkSent(_ context.Context, id, messageID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages[id].Status = model.ScheduledStatusSent
+	m.messages[id].MessageID = &messageID
+	return nil
+}
+
+func (m *mockScheduledMsgRepo) MarkFailed(_ context.Context, id uuid.UUID, reason string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages[id].Status = model.ScheduledStatusFailed
+	m.messages[id].Error = reason
+	return nil
+}
+
+func (m *mockScheduledMsgRepo) Release(_ context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages[id].Status = model.ScheduledStatusPending
+	return nil
+}
+
+// makeDue moves a scheduled message's send time into the past.
+func (m *mockScheduledMsgRepo) makeDue(id uuid.UUID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages[id].SendAt = time.Now().Add(-time.Second)
+}
+
+func (m *mockScheduledMsgRepo) get(id uuid.UUID) model.ScheduledMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return *m.messages[id]
+}
+
+// -- Failing message service --
+
+// flakyMessageService fails SendMessage with err, for testing retries.
+type flakyMessageService struct {
+	MessageService
+	err error
+}
+
+func (f *flakyMessageService) SendMessage(_ context.Context, _ SendMessageInput) (*model.Message, error) {
+	return nil, f.err
+}
+
+func setupScheduledTest(t *testing.T) (ScheduledMessageService, *mockScheduledMsgRepo, *mockMessageRepo, *mockChatRepo, uuid.UUID, uuid.UUID) {
+	t.Helper()
+	hub := newTestHub()
+	t.Cleanup(hub.Shutdown)
+
+	chatRepo := newMockChatRepo()
+	msgRepo := newMockMessageRepo()
+	messageSvc := NewMessageService(msgRepo, newMockMessageStatRepo(), newMockReactionRepo(), newMockThreadRepo(), chatRepo, nil, nil, hub, nil, nil, DefaultMessageConfig())
+	repo := newMockScheduledMsgRepo()
+	svc := NewScheduledMessageService(repo, chatRepo, messageSvc, DefaultScheduledMessageConfig())
+
+	sender := uuid.New()
+	chat := &model.Chat{ID: uuid.New(), Type: model.ChatTypeGroup, Name: "Team", CreatedBy: sender}
+	chatRepo.chats[chat.ID] = chat
+	_ = chatRepo.AddMember(context.Background(), chat.ID, sender, model.MemberRoleAdmin)
+
+	return svc, repo, msgRepo, chatRepo, chat.ID, sender
+}
+
+func TestScheduledMessageService_Schedule(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _, _, chatID, sender := setupScheduledTest(t)
+
+	t.Run("success", func(t *testing.T) {
+		sendAt := time.Now().Add(time.Hour)
+		msg, err := svc.Schedule(ctx, ScheduleMessageInput{ChatID: chatID, SenderID: sender, Content: "Rapat jam 9", SendAt: sendAt})
+		require.NoError(t, err)
+		assert.Equal(t, model.ScheduledStatusPending, msg.Status)
+		assert.Equal(t, model.MessageTypeText, msg.Type)
+
+		list, err := svc.List(ctx, chatID, sender)
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.Equal(t, msg.ID, list[0].ID)
+	})
+
+	t.Run("past send time", func(t *testing.T) {
+		_, err := svc.Schedule(ctx, ScheduleMessageInput{ChatID: chatID, SenderID: sender, Content: "x", SendAt: time.Now().Add(-time.Minute)})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "future")
+	})
+
+	t.Run("too far ahead", func(t *testing.T) {
+		_, err := svc.Schedule(ctx, ScheduleMessageInput{ChatID: chatID, SenderID: sender, Content: "x", SendAt: time.Now().Add(2 * 365 * 24 * time.Hour)})
+		require.Error(t, err)
+	})
+
+	t.Run("empty content", func(t *testing.T) {
+		_, err := svc.Schedule(ctx, ScheduleMessageInput{ChatID: chatID, SenderID: sender, SendAt: time.Now().Add(time.Hour)})
+		require.Error(t, err)
+	})
+
+	t.Run("polls cannot be scheduled", func(t *testing.T) {
+		_, err := svc.Schedule(ctx, ScheduleMessageInput{ChatID: chatID, SenderID: sender, Content: "Q", Type: model.MessageTypePoll, SendAt: time.Now().Add(time.Hour)})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "polls")
+	})
+
+	t.Run("invalid location", func(t *testing.T) {
+		_, err := svc.Schedule(ctx, ScheduleMessageInput{ChatID: chatID, SenderID: sender, Type: model.MessageTypeLocation, Metadata: []byte(`{"latitude":120,"longitude":0}`), SendAt: time.Now().Add(time.Hour)})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "latitude")
+	})
+
+	t.Run("non-member", func(t *testing.T) {
+		_, err := svc.Schedule(ctx, ScheduleMessageInput{ChatID: chatID, SenderID: uuid.New(), Content: "x", SendAt: time.Now().Add(time.Hour)})
+		assert.True(t, apperror.IsForbidden(err))
+	})
+}
+
+func TestScheduledMessageService_UpdateAndCancel(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _, chatRepo, chatID, sender := setupScheduledTest(t)
+
+	msg, err := svc.Schedule(ctx, ScheduleMessageInput{ChatID: chatID, SenderID: sender, Content: "Draft", SendAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+
+	t.Run("edit content and time", func(t *testing.T) {
+		content := "Final"
+		sendAt := time.Now().Add(2 * time.Hour)
+		updated, err := svc.Update(ctx, chatID, msg.ID, sender, UpdateScheduledMessageInput{Content: &content, SendAt: &sendAt})
+		require.NoError(t, err)
+		assert.Equal(t, "Final", updated.Content)
+		assert.WithinDuration(t, sendAt, updated.SendAt, time.Millisecond)
+	})
+
+	t.Run("other users cannot see it", func(t *testing.T) {
+		other := uuid.New()
+		_ = chatRepo.AddMember(ctx, chatID, other, model.MemberRoleMember)
+		content := "Hijack"
+		_, err := svc.Update(ctx, chatID, msg.ID, other, UpdateScheduledMessageInput{Content: &content})
+		assert.True(t, apperror.IsNotFound(err))
+		assert.True(t, apperror.IsNotFound(svc.Cancel(ctx, chatID, msg.ID, other)))
+
+		list, err := svc.List(ctx, chatID, other)
+		require.NoError(t, err)
+		assert.Empty(t, list)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		require.NoError(t, svc.Cancel(ctx, chatID, msg.ID, sender))
+		assert.Equal(t, model.ScheduledStatusCancelled, repo.get(msg.ID).Status)
+
+		err := svc.Cancel(ctx, chatID, msg.ID, sender)
+		assert.True(t, apperror.IsConflict(err))
+
+		list, err := svc.List(ctx, chatID, sender)
+		require.NoError(t, err)
+		assert.Empty(t, list)
+	})
+}
+
+func TestScheduledMessageService_DispatchDue(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("delivers due messages through SendMessage", func(t *testing.T) {
+		svc, repo, msgRepo, _, chatID, sender := setupScheduledTest(t)
+		due, err := svc.Schedule(ctx, ScheduleMessageInput{ChatID: chatID, SenderID: sender, Content: "Selamat pagi", SendAt: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+		later, err := svc.Schedule(ctx, ScheduleMessageInput{ChatID: chatID, SenderID: sender, Content: "Nanti", SendAt: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+		repo.makeDue(due.ID)
+
+		sent, err := svc.DispatchDue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+
+		delivered := repo.get(due.ID)
+		assert.Equal(t, model.ScheduledStatusSent, delivered.Status)
+		require.NotNil(t, delivered.MessageID)
+		msg, err := msgRepo.FindByID(ctx, *delivered.MessageID)
+		require.NoError(t, err)
+		assert.Equal(t, "Selamat pagi", msg.Content)
+		assert.Equal(t, sender, msg.SenderID)
+
+		assert.Equal(t, model.ScheduledStatusPending, repo.get(later.ID).Status)
+
+		// Nothing left to send
+		sent, err = svc.DispatchDue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, sent)
+	})
+
+	t.Run("concurrent dispatchers send each message once", func(t *testing.T) {
+		svc, repo, msgRepo, _, chatID, sender := setupScheduledTest(t)
+		for i := 0; i < 20; i++ {
+			msg, err := svc.Schedule(ctx, ScheduleMessageInput{ChatID: chatID, SenderID: sender, Content: "Halo", SendAt: time.Now().Add(time.Hour)})
+			require.NoError(t, err)
+			repo.makeDue(msg.ID)
+		}
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		total := 0
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sent, err := svc.DispatchDue(ctx)
+				assert.NoError(t, err)
+				mu.Lock()
+				total += sent
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 20, total)
+		assert.Len(t, msgRepo.byChat[chatID], 20)
+	})
+
+	t.Run("sender who left the chat fails permanently", func(t *testing.T) {
+		svc, repo, _, chatRepo, chatID, sender := setupScheduledTest(t)
+		msg, err := svc.Schedule(ctx, ScheduleMessageInput{ChatID: chatID, SenderID: sender, Content: "Halo", SendAt: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+		repo.makeDue(msg.ID)
+		require.NoError(t, chatRepo.RemoveMember(ctx, chatID, sender))
+
+		sent, err := svc.DispatchDue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, sent)
+		failed := repo.get(msg.ID)
+		assert.Equal(t, model.ScheduledStatusFailed, failed.Status)
+		assert.Contains(t, failed.Error, "not a member")
+	})
+
+	t.Run("transient errors are retried until max attempts", func(t *testing.T) {
+		chatRepo := newMockChatRepo()
+		repo := newMockScheduledMsgRepo()
+		config := DefaultScheduledMessageConfig()
+		config.ClaimTimeout = 0
+		config.MaxAttempts = 2
+		svc := NewScheduledMessageService(repo, chatRepo, &flakyMessageService{err: errors.New("db down")}, config)
+
+		sender := uuid.New()
+		chatID := uuid.New()
+		chatRepo.chats[chatID] = &model.Chat{ID: chatID, Type: model.ChatTypeGroup}
+		_ = chatRepo.AddMember(ctx, chatID, sender, model.MemberRoleMember)
+		msg, err := svc.Schedule(ctx, ScheduleMessageInput{ChatID: chatID, SenderID: sender, Content: "Halo", SendAt: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+		repo.makeDue(msg.ID)
+
+		_, err = svc.DispatchDue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, model.ScheduledStatusPending, repo.get(msg.ID).Status)
+
+		time.Sleep(time.Millisecond)
+		_, err = svc.DispatchDue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, model.ScheduledStatusFailed, repo.get(msg.ID).Status)
+	})
+	t.Run("internal errors are retried", func(t *testing.T) {
+		chatRepo := newMockChatRepo()
+		repo := newMockScheduledMsgRepo()
+		config := DefaultScheduledMessageConfig()
+		config.ClaimTimeout = 0
+		config.MaxAttempts = 2
+		sendErr := fmt.Errorf("create message: %w", apperror.Internal(errors.New("db down")))
+		svc := NewScheduledMessageService(repo, chatRepo, &flakyMessageService{err: sendErr}, config)
+
+		sender := uuid.New()
+		chatID := uuid.New()
+		chatRepo.chats[chatID] = &model.Chat{ID: chatID, Type: model.ChatTypeGroup}
+		_ = chatRepo.AddMember(ctx, chatID, sender, model.MemberRoleMember)
+		msg, err := svc.Schedule(ctx, ScheduleMessageInput{ChatID: chatID, SenderID: sender, Content: "Halo", SendAt: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+		repo.makeDue(msg.ID)
+
+		_, err = svc.DispatchDue(ctx)
+		require.NoError(t, err)
+		pending := repo.get(msg.ID)
+		assert.Equal(t, model.ScheduledStatusPending, pending.Status)
+		assert.Empty(t, pending.Error)
+
+		time.Sleep(time.Millisecond)
+		_, err = svc.DispatchDue(ctx)
+		require.NoError(t, err)
+		failed := repo.get(msg.ID)
+		assert.Equal(t, model.ScheduledStatusFailed, failed.Status)
+		assert.Equal(t, "message could not be delivered", failed.Error)
+	})
+}
+
+func TestScheduledDispatcher_Run(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _, _, chatID, sender := setupScheduledTest(t)
+	msg, err := svc.Schedule(ctx, ScheduleMessageInput{ChatID: chatID, SenderID: sender, Content: "Pengumuman", SendAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	repo.makeDue(msg.ID)
+
+	dispatcher := NewScheduledDispatcher(svc, 10*time.Millisecond)
+	go dispatcher.Run()
+	defer dispatcher.Stop()
+
+	assert.Eventually(t, func() bool {
+		return repo.get(msg.ID).Status == model.ScheduledStatusSent
+	}, time.Second, 10*time.Millisecond)
+}
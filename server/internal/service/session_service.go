@@ -4,108 +4,319 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog/log"
 
+	"github.com/otoritech/chatat/internal/ws"
 	"github.com/otoritech/chatat/pkg/apperror"
 )
 
-// SessionService manages one-device-per-user sessions.
+// SessionMode controls how many devices a user may be signed in on.
+type SessionMode string
+
+const (
+	// SessionModeSingleDevice revokes every other device when a new one logs in.
+	SessionModeSingleDevice SessionMode = "single"
+	// SessionModeMultiDevice lets several devices stay signed in concurrently.
+	SessionModeMultiDevice SessionMode = "multi"
+)
+
+// IsValid checks if the session mode is valid.
+func (m SessionMode) IsValid() bool {
+	switch m {
+	case SessionModeSingleDevice, SessionModeMultiDevice:
+		return true
+	}
+	return false
+}
+
+// SessionService manages per-device login sessions.
 type SessionService interface {
-	Register(ctx context.Context, userID uuid.UUID, deviceID string, refreshToken string) error
+	Register(ctx context.Context, userID uuid.UUID, device SessionDevice) error
 	Validate(ctx context.Context, userID uuid.UUID, deviceID string) error
+	Touch(ctx context.Context, userID uuid.UUID, deviceID, ip string) error
+	List(ctx context.Context, userID uuid.UUID, currentDeviceID string) ([]*Session, error)
+	Revoke(ctx context.Context, userID uuid.UUID, deviceID string) error
+	RevokeOthers(ctx context.Context, userID uuid.UUID, currentDeviceID string) error
 	Invalidate(ctx context.Context, userID uuid.UUID) error
 }
 
-type deviceInfo struct {
-	DeviceID     string `json:"deviceId"`
-	RefreshToken string `json:"refreshToken"`
+// SessionDevice describes the device a session was opened from.
+type SessionDevice struct {
+	DeviceID   string `json:"deviceId"`
+	DeviceName string `json:"deviceName"`
+	Platform   string `json:"platform"`
+	IP         string `json:"ip"`
+}
+
+// Session is an active device session.
+type Session struct {
+	SessionDevice
+	CreatedAt  time.Time `json:"createdAt"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	Current    bool      `json:"current"`
 }
 
 type sessionService struct {
 	redis        *redis.Client
 	tokenService TokenService
+	hub          *ws.Hub
 	sessionTTL   time.Duration
+	mode         SessionMode
 }
 
-// NewSessionService creates a new session service.
-func NewSessionService(redisClient *redis.Client, tokenService TokenService, ttl time.Duration) SessionService {
+// NewSessionService creates a new session service. Revoked devices are
+// disconnected from hub, which may be nil.
+func NewSessionService(redisClient *redis.Client, tokenService TokenService, hub *ws.Hub, ttl time.Duration, mode SessionMode) SessionService {
 	if ttl == 0 {
 		ttl = 30 * 24 * time.Hour // 30 days
 	}
+	if !mode.IsValid() {
+		mode = SessionModeSingleDevice
+	}
 	return &sessionService{
 		redis:        redisClient,
 		tokenService: tokenService,
+		hub:          hub,
 		sessionTTL:   ttl,
+		mode:         mode,
 	}
 }
 
-func (s *sessionService) Register(ctx context.Context, userID uuid.UUID, deviceID string, refreshToken string) error {
-	key := fmt.Sprintf("device:%s", userID.String())
+func sessionsKey(userID uuid.UUID) string {
+	return fmt.Sprintf("sessions:%s", userID.String())
+}
 
-	// Check for existing device
-	raw, err := s.redis.Get(ctx, key).Bytes()
-	if err == nil {
-		var existing deviceInfo
-		if err := json.Unmarshal(raw, &existing); err == nil {
-			if existing.DeviceID != deviceID {
-				// Different device — revoke old session
-				log.Info().
-					Str("user_id", userID.String()).
-					Str("old_device", existing.DeviceID).
-					Str("new_device", deviceID).
-					Msg("revoking previous device session")
+// legacyDeviceKey is where the single session of a user was kept before
+// sessions were tracked per device.
+func legacyDeviceKey(userID uuid.UUID) string {
+	return fmt.Sprintf("device:%s", userID.String())
+}
 
-				_ = s.tokenService.Revoke(ctx, "", existing.RefreshToken)
-			}
-		}
-	} else if err != redis.Nil {
-		return apperror.Internal(err)
+func (s *sessionService) Register(ctx context.Context, userID uuid.UUID, device SessionDevice) error {
+	if device.DeviceID == "" {
+		return apperror.Validation("deviceId", "device ID is required")
 	}
 
-	// Store new device
-	data := deviceInfo{
-		DeviceID:     deviceID,
-		RefreshToken: refreshToken,
-	}
-	jsonData, err := json.Marshal(data)
+	existing, err := s.load(ctx, userID)
 	if err != nil {
-		return apperror.Internal(err)
+		return err
 	}
 
-	if err := s.redis.Set(ctx, key, jsonData, s.sessionTTL).Err(); err != nil {
-		return apperror.Internal(err)
+	if s.mode == SessionModeSingleDevice {
+		for deviceID := range existing {
+			if deviceID == device.DeviceID {
+				continue
+			}
+			// Different device — revoke old session
+			log.Info().
+				Str("user_id", userID.String()).
+				Str("old_device", deviceID).
+				Str("new_device", device.DeviceID).
+				Msg("revoking previous device session")
+
+			if err := s.Revoke(ctx, userID, deviceID); err != nil {
+				return err
+			}
+		}
 	}
 
-	return nil
+	now := time.Now()
+	session := &Session{
+		SessionDevice: device,
+		CreatedAt:     now,
+		LastSeenAt:    now,
+	}
+	if prev, ok := existing[device.DeviceID]; ok {
+		session.CreatedAt = prev.CreatedAt
+	}
+	return s.save(ctx, userID, session)
 }
 
 func (s *sessionService) Validate(ctx context.Context, userID uuid.UUID, deviceID string) error {
-	key := fmt.Sprintf("device:%s", userID.String())
-	raw, err := s.redis.Get(ctx, key).Bytes()
-	if err == redis.Nil {
+	sessions, err := s.load(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if len(sessions) == 0 {
 		return apperror.Unauthorized("no active session")
 	}
+	if _, ok := sessions[deviceID]; !ok {
+		if s.mode == SessionModeSingleDevice {
+			return apperror.Unauthorized("session active on another device")
+		}
+		return apperror.Unauthorized("session revoked or expired")
+	}
+	return nil
+}
+
+// Touch records activity on a session, e.g. when its tokens are refreshed.
+func (s *sessionService) Touch(ctx context.Context, userID uuid.UUID, deviceID, ip string) error {
+	sessions, err := s.load(ctx, userID)
 	if err != nil {
-		return apperror.Internal(err)
+		return err
 	}
+	session, ok := sessions[deviceID]
+	if !ok {
+		return apperror.NotFound("session", deviceID)
+	}
+
+	session.LastSeenAt = time.Now()
+	if ip != "" {
+		session.IP = ip
+	}
+	return s.save(ctx, userID, session)
+}
 
-	var info deviceInfo
-	if err := json.Unmarshal(raw, &info); err != nil {
+// List returns the user's active sessions, most recently seen first.
+func (s *sessionService) List(ctx context.Context, userID uuid.UUID, currentDeviceID string) ([]*Session, error) {
+	sessions, err := s.load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*Session, 0, len(sessions))
+	for _, session := range sessions {
+		session.Current = session.DeviceID == currentDeviceID
+		result = append(result, session)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].LastSeenAt.After(result[j].LastSeenAt)
+	})
+	return result, nil
+}
+
+// Revoke ends one device session and its refresh token, and closes the
+// device's live connections.
+func (s *sessionService) Revoke(ctx context.Context, userID uuid.UUID, deviceID string) error {
+	removed, err := s.redis.HDel(ctx, sessionsKey(userID), deviceID).Result()
+	if err != nil {
 		return apperror.Internal(err)
 	}
+	if removed == 0 {
+		return apperror.NotFound("session", deviceID)
+	}
 
-	if info.DeviceID != deviceID {
-		return apperror.Unauthorized("session active on another device")
+	if err := s.tokenService.RevokeDevice(ctx, userID, deviceID); err != nil {
+		return err
+	}
+	if s.hub != nil {
+		s.hub.DisconnectDevice(userID, deviceID)
 	}
+	return nil
+}
 
+// RevokeOthers ends every session except the caller's own.
+func (s *sessionService) RevokeOthers(ctx context.Context, userID uuid.UUID, currentDeviceID string) error {
+	sessions, err := s.load(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for deviceID := range sessions {
+		if deviceID == currentDeviceID {
+			continue
+		}
+		if err := s.Revoke(ctx, userID, deviceID); err != nil && !apperror.IsNotFound(err) {
+			return err
+		}
+	}
 	return nil
 }
 
+// Invalidate ends every session of the user.
 func (s *sessionService) Invalidate(ctx context.Context, userID uuid.UUID) error {
-	key := fmt.Sprintf("device:%s", userID.String())
-	return s.redis.Del(ctx, key).Err()
+	return s.RevokeOthers(ctx, userID, "")
+}
+
+// load reads all sessions of a user, dropping the ones idle for longer than the TTL.
+func (s *sessionService) load(ctx context.Context, userID uuid.UUID) (map[string]*Session, error) {
+	raw, err := s.redis.HGetAll(ctx, sessionsKey(userID)).Result()
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	if len(raw) == 0 {
+		migrated, err := s.migrateLegacy(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if migrated != nil {
+			return map[string]*Session{migrated.DeviceID: migrated}, nil
+		}
+	}
+
+	sessions := make(map[string]*Session, len(raw))
+	var expired []string
+	for deviceID, data := range raw {
+		var session Session
+		if err := json.Unmarshal([]byte(data), &session); err != nil {
+			expired = append(expired, deviceID)
+			continue
+		}
+		if time.Since(session.LastSeenAt) > s.sessionTTL {
+			expired = append(expired, deviceID)
+			continue
+		}
+		sessions[deviceID] = &session
+	}
+
+	if len(expired) > 0 {
+		_ = s.redis.HDel(ctx, sessionsKey(userID), expired...).Err()
+	}
+	return sessions, nil
+}
+
+// migrateLegacy moves a session stored under the legacy single-device key
+// into the sessions hash, so users signed in before the upgrade stay signed
+// in. Returns nil if there was none.
+func (s *sessionService) migrateLegacy(ctx context.Context, userID uuid.UUID) (*Session, error) {
+	raw, err := s.redis.Get(ctx, legacyDeviceKey(userID)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+
+	var legacy struct {
+		DeviceID string `json:"deviceId"`
+	}
+	var session *Session
+	if err := json.Unmarshal(raw, &legacy); err == nil && legacy.DeviceID != "" {
+		now := time.Now()
+		session = &Session{
+			SessionDevice: SessionDevice{DeviceID: legacy.DeviceID},
+			CreatedAt:     now,
+			LastSeenAt:    now,
+		}
+		if err := s.save(ctx, userID, session); err != nil {
+			return nil, err
+		}
+		log.Info().Str("user_id", userID.String()).Str("device_id", legacy.DeviceID).Msg("migrated legacy device session")
+	}
+
+	if err := s.redis.Del(ctx, legacyDeviceKey(userID)).Err(); err != nil {
+		return nil, apperror.Internal(err)
+	}
+	return session, nil
+}
+
+func (s *sessionService) save(ctx context.Context, userID uuid.UUID, session *Session) error {
+	session.Current = false
+	data, err := json.Marshal(session)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	key := sessionsKey(userID)
+	pipe := s.redis.TxPipeline()
+	pipe.HSet(ctx, key, session.DeviceID, data)
+	pipe.Expire(ctx, key, s.sessionTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return apperror.Internal(err)
+	}
+	return nil
 }
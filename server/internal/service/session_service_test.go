@@ -3,6 +3,7 @@ package service_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/alicebob/miniredis/v2"
 	"github.com/google/uuid"
@@ -11,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/otoritech/chatat/internal/service"
+	"github.com/otoritech/chatat/internal/ws"
 )
 
 func setupSessionTest(t *testing.T) (*miniredis.Miniredis, *redis.Client, service.TokenService) {
@@ -25,11 +27,11 @@ func TestSessionService_Register(t *testing.T) {
 	_, client, tokenSvc := setupSessionTest(t)
 	defer func() { _ = client.Close() }()
 
-	svc := service.NewSessionService(client, tokenSvc, 0)
+	svc := service.NewSessionService(client, tokenSvc, nil, 0, service.SessionModeSingleDevice)
 	ctx := context.Background()
 	userID := uuid.New()
 
-	err := svc.Register(ctx, userID, "device-1", "refresh-token-1")
+	err := svc.Register(ctx, userID, service.SessionDevice{DeviceID: "device-1"})
 	require.NoError(t, err)
 
 	err = svc.Validate(ctx, userID, "device-1")
@@ -40,11 +42,11 @@ func TestSessionService_Validate_WrongDevice(t *testing.T) {
 	_, client, tokenSvc := setupSessionTest(t)
 	defer func() { _ = client.Close() }()
 
-	svc := service.NewSessionService(client, tokenSvc, 0)
+	svc := service.NewSessionService(client, tokenSvc, nil, 0, service.SessionModeSingleDevice)
 	ctx := context.Background()
 	userID := uuid.New()
 
-	err := svc.Register(ctx, userID, "device-1", "refresh-token-1")
+	err := svc.Register(ctx, userID, service.SessionDevice{DeviceID: "device-1"})
 	require.NoError(t, err)
 
 	err = svc.Validate(ctx, userID, "device-2")
@@ -55,16 +57,16 @@ func TestSessionService_OneDevicePerUser(t *testing.T) {
 	_, client, tokenSvc := setupSessionTest(t)
 	defer func() { _ = client.Close() }()
 
-	svc := service.NewSessionService(client, tokenSvc, 0)
+	svc := service.NewSessionService(client, tokenSvc, nil, 0, service.SessionModeSingleDevice)
 	ctx := context.Background()
 	userID := uuid.New()
 
 	// Register device 1
-	err := svc.Register(ctx, userID, "device-1", "refresh-token-1")
+	err := svc.Register(ctx, userID, service.SessionDevice{DeviceID: "device-1"})
 	require.NoError(t, err)
 
 	// Register device 2 (should revoke device 1)
-	err = svc.Register(ctx, userID, "device-2", "refresh-token-2")
+	err = svc.Register(ctx, userID, service.SessionDevice{DeviceID: "device-2"})
 	require.NoError(t, err)
 
 	// Device 1 should no longer be valid
@@ -80,11 +82,11 @@ func TestSessionService_Invalidate(t *testing.T) {
 	_, client, tokenSvc := setupSessionTest(t)
 	defer func() { _ = client.Close() }()
 
-	svc := service.NewSessionService(client, tokenSvc, 0)
+	svc := service.NewSessionService(client, tokenSvc, nil, 0, service.SessionModeSingleDevice)
 	ctx := context.Background()
 	userID := uuid.New()
 
-	err := svc.Register(ctx, userID, "device-1", "refresh-token-1")
+	err := svc.Register(ctx, userID, service.SessionDevice{DeviceID: "device-1"})
 	require.NoError(t, err)
 
 	err = svc.Invalidate(ctx, userID)
@@ -98,7 +100,7 @@ func TestSessionService_Validate_NoSession(t *testing.T) {
 	_, client, tokenSvc := setupSessionTest(t)
 	defer func() { _ = client.Close() }()
 
-	svc := service.NewSessionService(client, tokenSvc, 0)
+	svc := service.NewSessionService(client, tokenSvc, nil, 0, service.SessionModeSingleDevice)
 	ctx := context.Background()
 
 	err := svc.Validate(ctx, uuid.New(), "device-1")
@@ -109,12 +111,12 @@ func TestSessionService_Register_RedisError(t *testing.T) {
 	s, client, tokenSvc := setupSessionTest(t)
 	defer func() { _ = client.Close() }()
 
-	svc := service.NewSessionService(client, tokenSvc, 0)
+	svc := service.NewSessionService(client, tokenSvc, nil, 0, service.SessionModeSingleDevice)
 
 	// Close miniredis to cause errors
 	s.Close()
 
-	err := svc.Register(context.Background(), uuid.New(), "device-1", "refresh-1")
+	err := svc.Register(context.Background(), uuid.New(), service.SessionDevice{DeviceID: "device-1"})
 	assert.Error(t, err)
 }
 
@@ -122,11 +124,11 @@ func TestSessionService_Validate_RedisError(t *testing.T) {
 	s, client, tokenSvc := setupSessionTest(t)
 	defer func() { _ = client.Close() }()
 
-	svc := service.NewSessionService(client, tokenSvc, 0)
+	svc := service.NewSessionService(client, tokenSvc, nil, 0, service.SessionModeSingleDevice)
 	userID := uuid.New()
 
 	// Register first
-	err := svc.Register(context.Background(), userID, "device-1", "refresh-1")
+	err := svc.Register(context.Background(), userID, service.SessionDevice{DeviceID: "device-1"})
 	require.NoError(t, err)
 
 	// Close miniredis to cause error on validate
@@ -140,18 +142,187 @@ func TestSessionService_Register_SameDevice(t *testing.T) {
 	_, client, tokenSvc := setupSessionTest(t)
 	defer func() { _ = client.Close() }()
 
-	svc := service.NewSessionService(client, tokenSvc, 0)
+	svc := service.NewSessionService(client, tokenSvc, nil, 0, service.SessionModeSingleDevice)
 	ctx := context.Background()
 	userID := uuid.New()
 
 	// Register same device twice - should not revoke
-	err := svc.Register(ctx, userID, "device-1", "refresh-1")
+	err := svc.Register(ctx, userID, service.SessionDevice{DeviceID: "device-1"})
 	require.NoError(t, err)
 
-	err = svc.Register(ctx, userID, "device-1", "refresh-2")
+	err = svc.Register(ctx, userID, service.SessionDevice{DeviceID: "device-1"})
 	require.NoError(t, err)
 
 	// Should still be valid
 	err = svc.Validate(ctx, userID, "device-1")
 	assert.NoError(t, err)
 }
+
+func TestSessionService_MultiDevice_List(t *testing.T) {
+	_, client, tokenSvc := setupSessionTest(t)
+	defer func() { _ = client.Close() }()
+
+	svc := service.NewSessionService(client, tokenSvc, nil, 0, service.SessionModeMultiDevice)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	require.NoError(t, svc.Register(ctx, userID, service.SessionDevice{DeviceID: "phone", DeviceName: "Pixel", Platform: "android"}))
+	require.NoError(t, svc.Register(ctx, userID, service.SessionDevice{DeviceID: "laptop", DeviceName: "Chrome", Platform: "web"}))
+
+	// Both devices stay signed in
+	assert.NoError(t, svc.Validate(ctx, userID, "phone"))
+	assert.NoError(t, svc.Validate(ctx, userID, "laptop"))
+
+	require.NoError(t, svc.Touch(ctx, userID, "phone", "10.0.0.1"))
+
+	sessions, err := svc.List(ctx, userID, "phone")
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, "phone", sessions[0].DeviceID)
+	assert.True(t, sessions[0].Current)
+	assert.Equal(t, "10.0.0.1", sessions[0].IP)
+	assert.Equal(t, "Pixel", sessions[0].DeviceName)
+	assert.False(t, sessions[1].Current)
+}
+
+func TestSessionService_Revoke(t *testing.T) {
+	_, client, tokenSvc := setupSessionTest(t)
+	defer func() { _ = client.Close() }()
+
+	svc := service.NewSessionService(client, tokenSvc, nil, 0, service.SessionModeMultiDevice)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	tokens, err := tokenSvc.GenerateForDevice(ctx, userID, "laptop")
+	require.NoError(t, err)
+	require.NoError(t, svc.Register(ctx, userID, service.SessionDevice{DeviceID: "phone"}))
+	require.NoError(t, svc.Register(ctx, userID, service.SessionDevice{DeviceID: "laptop"}))
+
+	require.NoError(t, svc.Revoke(ctx, userID, "laptop"))
+
+	assert.Error(t, svc.Validate(ctx, userID, "laptop"))
+	assert.NoError(t, svc.Validate(ctx, userID, "phone"))
+
+	// The revoked device can no longer refresh its tokens
+	_, err = tokenSvc.Refresh(ctx, tokens.RefreshToken)
+	assert.Error(t, err)
+
+	err = svc.Revoke(ctx, userID, "laptop")
+	assert.Error(t, err)
+}
+
+func TestSessionService_RevokeOthers(t *testing.T) {
+	_, client, tokenSvc := setupSessionTest(t)
+	defer func() { _ = client.Close() }()
+
+	svc := service.NewSessionService(client, tokenSvc, nil, 0, service.SessionModeMultiDevice)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	for _, device := range []string{"phone", "laptop", "tablet"} {
+		require.NoError(t, svc.Register(ctx, userID, service.SessionDevice{DeviceID: device}))
+	}
+
+	require.NoError(t, svc.RevokeOthers(ctx, userID, "phone"))
+
+	sessions, err := svc.List(ctx, userID, "phone")
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "phone", sessions[0].DeviceID)
+}
+
+func TestSessionService_SingleDevice_RevokesOldRefreshToken(t *testing.T) {
+	_, client, tokenSvc := setupSessionTest(t)
+	defer func() { _ = client.Close() }()
+
+	svc := service.NewSessionService(client, tokenSvc, nil, 0, service.SessionModeSingleDevice)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	old, err := tokenSvc.GenerateForDevice(ctx, userID, "device-1")
+	require.NoError(t, err)
+	require.NoError(t, svc.Register(ctx, userID, service.SessionDevice{DeviceID: "device-1"}))
+
+	_, err = tokenSvc.GenerateForDevice(ctx, userID, "device-2")
+	require.NoError(t, err)
+	require.NoError(t, svc.Register(ctx, userID, service.SessionDevice{DeviceID: "device-2"}))
+
+	_, err = tokenSvc.Refresh(ctx, old.RefreshToken)
+	assert.Error(t, err)
+}
+
+func TestSessionService_RefreshReuseEndsSession(t *testing.T) {
+	_, client, tokenSvc := setupSessionTest(t)
+	defer func() { _ = client.Close() }()
+
+	svc := service.NewSessionService(client, tokenSvc, nil, 0, service.SessionModeMultiDevice)
+	tokenSvc.OnRefreshReuse(func(ctx context.Context, userID uuid.UUID, deviceID string) {
+		_ = svc.Revoke(ctx, userID, deviceID)
+	})
+	ctx := context.Background()
+	userID := uuid.New()
+
+	tokens, err := tokenSvc.GenerateForDevice(ctx, userID, "phone")
+	require.NoError(t, err)
+	require.NoError(t, svc.Register(ctx, userID, service.SessionDevice{DeviceID: "phone"}))
+	require.NoError(t, svc.Register(ctx, userID, service.SessionDevice{DeviceID: "laptop"}))
+
+	_, err = tokenSvc.Refresh(ctx, tokens.RefreshToken)
+	require.NoError(t, err)
+	_, err = tokenSvc.Refresh(ctx, tokens.RefreshToken)
+	require.Error(t, err)
+
+	assert.Error(t, svc.Validate(ctx, userID, "phone"))
+	assert.NoError(t, svc.Validate(ctx, userID, "laptop"))
+}
+
+func TestSessionService_Revoke_DisconnectsDevice(t *testing.T) {
+	_, client, tokenSvc := setupSessionTest(t)
+	defer func() { _ = client.Close() }()
+
+	hub := ws.NewHub()
+	go hub.Run()
+	defer hub.Shutdown()
+
+	svc := service.NewSessionService(client, tokenSvc, hub, 0, service.SessionModeMultiDevice)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	require.NoError(t, svc.Register(ctx, userID, service.SessionDevice{DeviceID: "phone"}))
+	require.NoError(t, svc.Register(ctx, userID, service.SessionDevice{DeviceID: "tablet"}))
+
+	phone := &ws.Client{UserID: userID, DeviceID: "phone", Send: make(chan []byte, 1), Hub: hub}
+	tablet := &ws.Client{UserID: userID, DeviceID: "tablet", Send: make(chan []byte, 1), Hub: hub}
+	hub.RegisterClient(phone)
+	hub.RegisterClient(tablet)
+	time.Sleep(10 * time.Millisecond)
+
+	require.NoError(t, svc.Revoke(ctx, userID, "phone"))
+
+	select {
+	case _, ok := <-phone.Send:
+		assert.False(t, ok, "revoked device should be disconnected")
+	case <-time.After(time.Second):
+		t.Fatal("revoked device still connected")
+	}
+	assert.Equal(t, []string{"tablet"}, hub.GetUserDevices(userID))
+}
+
+func TestSessionService_MigratesLegacySession(t *testing.T) {
+	mr, client, tokenSvc := setupSessionTest(t)
+	defer func() { _ = client.Close() }()
+
+	svc := service.NewSessionService(client, tokenSvc, nil, 0, service.SessionModeSingleDevice)
+	ctx := context.Background()
+	userID := uuid.New()
+	legacyKey := "device:" + userID.String()
+	require.NoError(t, mr.Set(legacyKey, `{"deviceId":"device-1","refreshToken":"old"}`))
+
+	require.NoError(t, svc.Validate(ctx, userID, "device-1"))
+	assert.False(t, mr.Exists(legacyKey))
+
+	sessions, err := svc.List(ctx, userID, "device-1")
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "device-1", sessions[0].DeviceID)
+}
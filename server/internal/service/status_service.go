@@ -31,6 +31,7 @@ type StatusNotifier struct {
 	hub         *ws.Hub
 	contactRepo repository.ContactRepository
 	userRepo    repository.UserRepository
+	privacy     PrivacyPolicy
 	redis       *redis.Client
 }
 
@@ -39,12 +40,14 @@ func NewStatusNotifier(
 	hub *ws.Hub,
 	contactRepo repository.ContactRepository,
 	userRepo repository.UserRepository,
+	privacy PrivacyPolicy,
 	redisClient *redis.Client,
 ) *StatusNotifier {
 	sn := &StatusNotifier{
 		hub:         hub,
 		contactRepo: contactRepo,
 		userRepo:    userRepo,
+		privacy:     privacy,
 		redis:       redisClient,
 	}
 
@@ -97,37 +100,59 @@ func (sn *StatusNotifier) broadcastStatus(ctx context.Context, userID uuid.UUID,
 		return
 	}
 
-	payload := onlineStatusPayload{
-		UserID:   userID,
-		IsOnline: isOnline,
-		LastSeen: time.Now(),
-	}
-
-	payloadBytes, err := json.Marshal(payload)
+	user, err := sn.userRepo.FindByID(ctx, userID)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to marshal online_status payload")
+		log.Error().Err(err).Str("user_id", userID.String()).Msg("failed to load user for online_status")
 		return
 	}
 
-	msg := ws.WSMessage{
-		Type:    ws.WSTypeOnlineStatus,
-		Payload: payloadBytes,
-	}
-
-	msgBytes, err := json.Marshal(msg)
+	visibility, err := sn.privacy.VisibilityFor(ctx, user, observers)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to marshal online_status message")
+		log.Error().Err(err).Str("user_id", userID.String()).Msg("failed to evaluate privacy for online_status")
 		return
 	}
 
+	now := time.Now()
+	sent := 0
 	for _, observerID := range observers {
+		vis := visibility[observerID]
+		// Observers who may not see presence only hear about going offline,
+		// and only when that tells them a last-seen time they may see
+		if !vis.Online && (isOnline || !vis.LastSeen) {
+			continue
+		}
+
+		payload := onlineStatusPayload{
+			UserID:   userID,
+			IsOnline: isOnline,
+		}
+		if vis.LastSeen {
+			payload.LastSeen = now
+		}
+
+		payloadBytes, err := json.Marshal(payload)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to marshal online_status payload")
+			return
+		}
+
+		msgBytes, err := json.Marshal(ws.WSMessage{
+			Type:    ws.WSTypeOnlineStatus,
+			Payload: payloadBytes,
+		})
+		if err != nil {
+			log.Error().Err(err).Msg("failed to marshal online_status message")
+			return
+		}
+
 		sn.hub.SendToUser(observerID, msgBytes)
+		sent++
 	}
 
 	log.Debug().
 		Str("user_id", userID.String()).
 		Bool("is_online", isOnline).
-		Int("observers", len(observers)).
+		Int("observers", sent).
 		Msg("broadcasted online status")
 }
 
@@ -35,6 +35,10 @@ func (m *statusMockContactRepo) FindContactsOf(_ context.Context, contactUserID
 	return m.observers[contactUserID], nil
 }
 
+func (m *statusMockContactRepo) IsContact(_ context.Context, _, _ uuid.UUID) (bool, error) {
+	return false, nil
+}
+
 func (m *statusMockContactRepo) Delete(_ context.Context, _, _ uuid.UUID) error {
 	return nil
 }
@@ -115,7 +119,7 @@ func TestHub_ConnectBroadcast(t *testing.T) {
 	time.Sleep(20 * time.Millisecond)
 
 	// Create StatusNotifier (sets hub callbacks)
-	_ = service.NewStatusNotifier(hub, contactRepo, userRepo, nil)
+	_ = service.NewStatusNotifier(hub, contactRepo, userRepo, service.NewPrivacyPolicy(contactRepo, nil), nil)
 
 	// A connects → B should be notified
 	clientA := &ws.Client{
@@ -161,7 +165,7 @@ func TestHub_DisconnectDebounce(t *testing.T) {
 	hub.RegisterClient(clientB)
 	time.Sleep(20 * time.Millisecond)
 
-	_ = service.NewStatusNotifier(hub, contactRepo, userRepo, nil)
+	_ = service.NewStatusNotifier(hub, contactRepo, userRepo, service.NewPrivacyPolicy(contactRepo, nil), nil)
 
 	clientA := &ws.Client{
 		UserID: userA,
@@ -216,7 +220,7 @@ func TestHub_ReconnectCancelsOffline(t *testing.T) {
 	hub.RegisterClient(clientB)
 	time.Sleep(20 * time.Millisecond)
 
-	_ = service.NewStatusNotifier(hub, contactRepo, userRepo, nil)
+	_ = service.NewStatusNotifier(hub, contactRepo, userRepo, service.NewPrivacyPolicy(contactRepo, nil), nil)
 
 	clientA := &ws.Client{
 		UserID: userA,
@@ -259,3 +263,47 @@ func TestHub_ReconnectCancelsOffline(t *testing.T) {
 		// Good, no offline broadcast
 	}
 }
+
+func TestHub_ConnectBroadcastRespectsPrivacy(t *testing.T) {
+	hub := ws.NewHub()
+	go hub.Run()
+	defer hub.Shutdown()
+
+	userA := uuid.New()
+	userB := uuid.New()
+
+	// B saved A's number, but A hides presence from everyone
+	contactRepo := &statusMockContactRepo{
+		observers: map[uuid.UUID][]uuid.UUID{
+			userA: {userB},
+		},
+	}
+	hidden := model.DefaultPrivacySettings()
+	hidden.OnlineVisibility = service.VisibilityNobody
+	userRepo := &statusMockUserRepo{
+		user: &model.User{ID: userA, Name: "A", PrivacySettings: hidden},
+	}
+
+	clientB := &ws.Client{
+		UserID: userB,
+		Send:   make(chan []byte, 256),
+		Hub:    hub,
+	}
+	hub.RegisterClient(clientB)
+	time.Sleep(20 * time.Millisecond)
+
+	_ = service.NewStatusNotifier(hub, contactRepo, userRepo, service.NewPrivacyPolicy(contactRepo, nil), nil)
+
+	clientA := &ws.Client{
+		UserID: userA,
+		Send:   make(chan []byte, 256),
+		Hub:    hub,
+	}
+	hub.RegisterClient(clientA)
+
+	select {
+	case msg := <-clientB.Send:
+		t.Fatalf("observer B should not see A come online, got %s", msg)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
@@ -8,18 +8,32 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
 
 	"github.com/otoritech/chatat/pkg/apperror"
 )
 
 // TokenService handles JWT token generation, validation, and revocation.
+// Tokens issued for a device carry its ID and each device holds at most one
+// live refresh token family, so a single device can be signed out on its own.
+//
+// Every login starts a refresh token family. Refreshing rotates the family to
+// a new token; presenting a token that was already rotated away means it was
+// copied, so the whole family is revoked and the reuse handler is notified.
 type TokenService interface {
 	Generate(ctx context.Context, userID uuid.UUID) (*TokenPair, error)
+	GenerateForDevice(ctx context.Context, userID uuid.UUID, deviceID string) (*TokenPair, error)
 	Validate(tokenString string) (*Claims, error)
 	Refresh(ctx context.Context, refreshToken string) (*TokenPair, error)
 	Revoke(ctx context.Context, accessToken string, refreshToken string) error
+	RevokeDevice(ctx context.Context, userID uuid.UUID, deviceID string) error
+	OnRefreshReuse(handler RefreshReuseHandler)
 }
 
+// RefreshReuseHandler is called after a refresh token family was revoked
+// because one of its rotated tokens was presented again.
+type RefreshReuseHandler func(ctx context.Context, userID uuid.UUID, deviceID string)
+
 // TokenPair holds access and refresh tokens.
 type TokenPair struct {
 	AccessToken  string `json:"accessToken"`
@@ -29,7 +43,9 @@ type TokenPair struct {
 
 // Claims represents JWT claims.
 type Claims struct {
-	UserID uuid.UUID `json:"userId"`
+	UserID   uuid.UUID `json:"userId"`
+	DeviceID string    `json:"did,omitempty"`
+	FamilyID string    `json:"fam,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -50,8 +66,9 @@ func DefaultTokenConfig(secret string) TokenConfig {
 }
 
 type tokenService struct {
-	redis  *redis.Client
-	config TokenConfig
+	redis   *redis.Client
+	config  TokenConfig
+	onReuse RefreshReuseHandler
 }
 
 // NewTokenService creates a new token service.
@@ -63,13 +80,58 @@ func NewTokenService(redisClient *redis.Client, config TokenConfig) TokenService
 }
 
 func (s *tokenService) Generate(ctx context.Context, userID uuid.UUID) (*TokenPair, error) {
+	return s.GenerateForDevice(ctx, userID, "")
+}
+
+func (s *tokenService) GenerateForDevice(ctx context.Context, userID uuid.UUID, deviceID string) (*TokenPair, error) {
+	familyID := uuid.New().String()
+	pair, refreshTokenID, err := s.issue(userID, deviceID, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Store the refresh token and start its family
+	pipe := s.redis.TxPipeline()
+	pipe.Set(ctx, refreshKey(refreshTokenID), userID.String(), s.config.RefreshTokenTTL)
+	pipe.HSet(ctx, familyKey(familyID), map[string]interface{}{
+		"user":    userID.String(),
+		"device":  deviceID,
+		"current": refreshTokenID,
+	})
+	pipe.Expire(ctx, familyKey(familyID), s.config.RefreshTokenTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, apperror.Internal(err)
+	}
+
+	// Keep one family per device: revoke the device's previous one
+	if deviceID != "" {
+		previousID, err := s.redis.SetArgs(ctx, deviceRefreshKey(userID, deviceID), familyID, redis.SetArgs{
+			TTL: s.config.RefreshTokenTTL,
+			Get: true,
+		}).Result()
+		if err != nil && err != redis.Nil {
+			return nil, apperror.Internal(err)
+		}
+		if previousID != "" && previousID != familyID {
+			if _, _, err := s.revokeFamily(ctx, previousID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return pair, nil
+}
+
+// issue signs a new access/refresh token pair and returns the refresh token ID.
+func (s *tokenService) issue(userID uuid.UUID, deviceID, familyID string) (*TokenPair, string, error) {
 	now := time.Now()
 	accessExp := now.Add(s.config.AccessTokenTTL)
 	refreshExp := now.Add(s.config.RefreshTokenTTL)
 
 	// Generate access token
 	accessClaims := Claims{
-		UserID: userID,
+		UserID:   userID,
+		DeviceID: deviceID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   userID.String(),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -79,13 +141,15 @@ func (s *tokenService) Generate(ctx context.Context, userID uuid.UUID) (*TokenPa
 	}
 	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims).SignedString([]byte(s.config.Secret))
 	if err != nil {
-		return nil, apperror.Internal(err)
+		return nil, "", apperror.Internal(err)
 	}
 
 	// Generate refresh token
 	refreshTokenID := uuid.New().String()
 	refreshClaims := Claims{
-		UserID: userID,
+		UserID:   userID,
+		DeviceID: deviceID,
+		FamilyID: familyID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   userID.String(),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -95,20 +159,14 @@ func (s *tokenService) Generate(ctx context.Context, userID uuid.UUID) (*TokenPa
 	}
 	refreshToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims).SignedString([]byte(s.config.Secret))
 	if err != nil {
-		return nil, apperror.Internal(err)
-	}
-
-	// Store refresh token in Redis
-	refreshKey := fmt.Sprintf("refresh:%s", refreshTokenID)
-	if err := s.redis.Set(ctx, refreshKey, userID.String(), s.config.RefreshTokenTTL).Err(); err != nil {
-		return nil, apperror.Internal(err)
+		return nil, "", apperror.Internal(err)
 	}
 
 	return &TokenPair{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 		ExpiresAt:    accessExp.Unix(),
-	}, nil
+	}, refreshTokenID, nil
 }
 
 func (s *tokenService) Validate(tokenString string) (*Claims, error) {
@@ -130,15 +188,80 @@ func (s *tokenService) Validate(tokenString string) (*Claims, error) {
 	return claims, nil
 }
 
+// rotateScript moves a family from its current refresh token to a new one.
+// KEYS: family, old refresh token, new refresh token.
+// ARGV: old token ID, new token ID, user ID, ttl ms.
+// Returns 1 on success, 0 if the old token was already rotated away,
+// -1 if the family is gone and -2 if the old token was revoked.
+var rotateScript = redis.NewScript(`
+local current = redis.call('HGET', KEYS[1], 'current')
+if not current then
+	return -1
+end
+if current ~= ARGV[1] then
+	return 0
+end
+if redis.call('GET', KEYS[2]) ~= ARGV[3] then
+	return -2
+end
+redis.call('HSET', KEYS[1], 'current', ARGV[2])
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+redis.call('DEL', KEYS[2])
+redis.call('SET', KEYS[3], ARGV[3], 'PX', ARGV[4])
+return 1
+`)
+
 func (s *tokenService) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
 	claims, err := s.Validate(refreshToken)
 	if err != nil {
 		return nil, apperror.Unauthorized("invalid refresh token")
 	}
 
-	// Check if refresh token exists in Redis
-	refreshKey := fmt.Sprintf("refresh:%s", claims.ID)
-	storedUserID, err := s.redis.Get(ctx, refreshKey).Result()
+	// Tokens issued before families existed start one on their first refresh
+	if claims.FamilyID == "" {
+		return s.refreshLegacy(ctx, claims)
+	}
+
+	pair, newTokenID, err := s.issue(claims.UserID, claims.DeviceID, claims.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []string{familyKey(claims.FamilyID), refreshKey(claims.ID), refreshKey(newTokenID)}
+	result, err := rotateScript.Run(ctx, s.redis, keys,
+		claims.ID, newTokenID, claims.UserID.String(), s.config.RefreshTokenTTL.Milliseconds()).Int()
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+
+	switch result {
+	case 1:
+		if claims.DeviceID != "" {
+			_ = s.redis.Expire(ctx, deviceRefreshKey(claims.UserID, claims.DeviceID), s.config.RefreshTokenTTL).Err()
+		}
+		return pair, nil
+	case 0:
+		log.Warn().
+			Str("user_id", claims.UserID.String()).
+			Str("device_id", claims.DeviceID).
+			Str("family_id", claims.FamilyID).
+			Msg("refresh token reuse detected, revoking family")
+
+		userID, deviceID, err := s.revokeFamily(ctx, claims.FamilyID)
+		if err != nil {
+			return nil, err
+		}
+		if s.onReuse != nil && userID != uuid.Nil {
+			s.onReuse(ctx, userID, deviceID)
+		}
+		return nil, apperror.Unauthorized("refresh token reuse detected")
+	default:
+		return nil, apperror.Unauthorized("refresh token revoked or expired")
+	}
+}
+
+func (s *tokenService) refreshLegacy(ctx context.Context, claims *Claims) (*TokenPair, error) {
+	storedUserID, err := s.redis.Get(ctx, refreshKey(claims.ID)).Result()
 	if err == redis.Nil {
 		return nil, apperror.Unauthorized("refresh token revoked or expired")
 	}
@@ -151,10 +274,9 @@ func (s *tokenService) Refresh(ctx context.Context, refreshToken string) (*Token
 	}
 
 	// Delete old refresh token
-	_ = s.redis.Del(ctx, refreshKey).Err()
+	_ = s.redis.Del(ctx, refreshKey(claims.ID)).Err()
 
-	// Generate new token pair
-	return s.Generate(ctx, claims.UserID)
+	return s.GenerateForDevice(ctx, claims.UserID, claims.DeviceID)
 }
 
 func (s *tokenService) Revoke(ctx context.Context, accessToken string, refreshToken string) error {
@@ -170,14 +292,80 @@ func (s *tokenService) Revoke(ctx context.Context, accessToken string, refreshTo
 		}
 	}
 
-	// Delete refresh token
+	// Delete refresh token along with its family
 	if refreshToken != "" {
 		refreshClaims, err := s.Validate(refreshToken)
 		if err == nil {
-			refreshKey := fmt.Sprintf("refresh:%s", refreshClaims.ID)
-			_ = s.redis.Del(ctx, refreshKey).Err()
+			_ = s.redis.Del(ctx, refreshKey(refreshClaims.ID)).Err()
+			if refreshClaims.FamilyID != "" {
+				_, _, _ = s.revokeFamily(ctx, refreshClaims.FamilyID)
+			}
 		}
 	}
 
 	return nil
 }
+
+// RevokeDevice revokes the refresh token family of one device.
+func (s *tokenService) RevokeDevice(ctx context.Context, userID uuid.UUID, deviceID string) error {
+	familyID, err := s.redis.Get(ctx, deviceRefreshKey(userID, deviceID)).Result()
+	if err == redis.Nil {
+		return nil
+	}
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	_, _, err = s.revokeFamily(ctx, familyID)
+	return err
+}
+
+// OnRefreshReuse sets the handler notified when a family is revoked for reuse.
+func (s *tokenService) OnRefreshReuse(handler RefreshReuseHandler) {
+	s.onReuse = handler
+}
+
+// revokeFamily deletes a family, its live refresh token and the device index
+// pointing at it. It returns the user and device the family belonged to.
+func (s *tokenService) revokeFamily(ctx context.Context, familyID string) (uuid.UUID, string, error) {
+	family, err := s.redis.HGetAll(ctx, familyKey(familyID)).Result()
+	if err != nil {
+		return uuid.Nil, "", apperror.Internal(err)
+	}
+	if len(family) == 0 {
+		return uuid.Nil, "", nil
+	}
+
+	userID, _ := uuid.Parse(family["user"])
+	deviceID := family["device"]
+
+	keys := []string{familyKey(familyID)}
+	if current := family["current"]; current != "" {
+		keys = append(keys, refreshKey(current))
+	}
+	if err := s.redis.Del(ctx, keys...).Err(); err != nil {
+		return uuid.Nil, "", apperror.Internal(err)
+	}
+
+	// Only drop the device index if it still points at this family
+	if deviceID != "" && userID != uuid.Nil {
+		indexKey := deviceRefreshKey(userID, deviceID)
+		if current, err := s.redis.Get(ctx, indexKey).Result(); err == nil && current == familyID {
+			_ = s.redis.Del(ctx, indexKey).Err()
+		}
+	}
+
+	return userID, deviceID, nil
+}
+
+func refreshKey(tokenID string) string {
+	return fmt.Sprintf("refresh:%s", tokenID)
+}
+
+func familyKey(familyID string) string {
+	return fmt.Sprintf("refresh_family:%s", familyID)
+}
+
+func deviceRefreshKey(userID uuid.UUID, deviceID string) string {
+	return fmt.Sprintf("refresh_device:%s:%s", userID.String(), deviceID)
+}
@@ -202,3 +202,143 @@ func TestTokenService_Generate_RedisError(t *testing.T) {
 	_, err := svc.Generate(context.Background(), uuid.New())
 	assert.Error(t, err)
 }
+
+func TestTokenService_GenerateForDevice(t *testing.T) {
+	_, client := setupTokenTest(t)
+	defer func() { _ = client.Close() }()
+
+	svc := service.NewTokenService(client, service.DefaultTokenConfig("test-secret-key-123"))
+	ctx := context.Background()
+	userID := uuid.New()
+
+	tokens, err := svc.GenerateForDevice(ctx, userID, "phone")
+	require.NoError(t, err)
+
+	claims, err := svc.Validate(tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "phone", claims.DeviceID)
+
+	// Refreshed tokens stay bound to the device
+	refreshed, err := svc.Refresh(ctx, tokens.RefreshToken)
+	require.NoError(t, err)
+	claims, err = svc.Validate(refreshed.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "phone", claims.DeviceID)
+}
+
+func TestTokenService_RevokeDevice(t *testing.T) {
+	_, client := setupTokenTest(t)
+	defer func() { _ = client.Close() }()
+
+	svc := service.NewTokenService(client, service.DefaultTokenConfig("test-secret-key-123"))
+	ctx := context.Background()
+	userID := uuid.New()
+
+	phone, err := svc.GenerateForDevice(ctx, userID, "phone")
+	require.NoError(t, err)
+	laptop, err := svc.GenerateForDevice(ctx, userID, "laptop")
+	require.NoError(t, err)
+
+	require.NoError(t, svc.RevokeDevice(ctx, userID, "phone"))
+
+	_, err = svc.Refresh(ctx, phone.RefreshToken)
+	assert.Error(t, err)
+	_, err = svc.Refresh(ctx, laptop.RefreshToken)
+	assert.NoError(t, err)
+
+	// Revoking an unknown device is a no-op
+	assert.NoError(t, svc.RevokeDevice(ctx, userID, "tablet"))
+}
+
+func TestTokenService_Refresh_RotatesFamily(t *testing.T) {
+	_, client := setupTokenTest(t)
+	defer func() { _ = client.Close() }()
+
+	svc := service.NewTokenService(client, service.DefaultTokenConfig("test-secret-key-123"))
+	ctx := context.Background()
+
+	original, err := svc.GenerateForDevice(ctx, uuid.New(), "phone")
+	require.NoError(t, err)
+	first, err := svc.Refresh(ctx, original.RefreshToken)
+	require.NoError(t, err)
+	second, err := svc.Refresh(ctx, first.RefreshToken)
+	require.NoError(t, err)
+
+	originalClaims, err := svc.Validate(original.RefreshToken)
+	require.NoError(t, err)
+	secondClaims, err := svc.Validate(second.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEmpty(t, secondClaims.FamilyID)
+	assert.Equal(t, originalClaims.FamilyID, secondClaims.FamilyID)
+	assert.NotEqual(t, originalClaims.ID, secondClaims.ID)
+}
+
+func TestTokenService_Refresh_ReuseRevokesFamily(t *testing.T) {
+	_, client := setupTokenTest(t)
+	defer func() { _ = client.Close() }()
+
+	svc := service.NewTokenService(client, service.DefaultTokenConfig("test-secret-key-123"))
+	ctx := context.Background()
+	userID := uuid.New()
+
+	var reusedUser uuid.UUID
+	var reusedDevice string
+	svc.OnRefreshReuse(func(_ context.Context, userID uuid.UUID, deviceID string) {
+		reusedUser = userID
+		reusedDevice = deviceID
+	})
+
+	stolen, err := svc.GenerateForDevice(ctx, userID, "phone")
+	require.NoError(t, err)
+	legit, err := svc.Refresh(ctx, stolen.RefreshToken)
+	require.NoError(t, err)
+
+	// Replaying the rotated token revokes the whole family
+	_, err = svc.Refresh(ctx, stolen.RefreshToken)
+	require.Error(t, err)
+	assert.Equal(t, userID, reusedUser)
+	assert.Equal(t, "phone", reusedDevice)
+
+	_, err = svc.Refresh(ctx, legit.RefreshToken)
+	assert.Error(t, err)
+}
+
+func TestTokenService_Refresh_ReuseLeavesOtherFamilies(t *testing.T) {
+	_, client := setupTokenTest(t)
+	defer func() { _ = client.Close() }()
+
+	svc := service.NewTokenService(client, service.DefaultTokenConfig("test-secret-key-123"))
+	ctx := context.Background()
+	userID := uuid.New()
+
+	phone, err := svc.GenerateForDevice(ctx, userID, "phone")
+	require.NoError(t, err)
+	laptop, err := svc.GenerateForDevice(ctx, userID, "laptop")
+	require.NoError(t, err)
+
+	_, err = svc.Refresh(ctx, phone.RefreshToken)
+	require.NoError(t, err)
+	_, err = svc.Refresh(ctx, phone.RefreshToken)
+	require.Error(t, err)
+
+	_, err = svc.Refresh(ctx, laptop.RefreshToken)
+	assert.NoError(t, err)
+}
+
+func TestTokenService_Revoke_RevokesFamily(t *testing.T) {
+	_, client := setupTokenTest(t)
+	defer func() { _ = client.Close() }()
+
+	svc := service.NewTokenService(client, service.DefaultTokenConfig("test-secret-key-123"))
+	ctx := context.Background()
+
+	original, err := svc.GenerateForDevice(ctx, uuid.New(), "phone")
+	require.NoError(t, err)
+	rotated, err := svc.Refresh(ctx, original.RefreshToken)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Revoke(ctx, rotated.AccessToken, rotated.RefreshToken))
+
+	_, err = svc.Refresh(ctx, rotated.RefreshToken)
+	assert.Error(t, err)
+}
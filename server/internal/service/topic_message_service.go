@@ -21,6 +21,10 @@ type SendTopicMessageInput struct {
 	Content   string            `json:"content"`
 	ReplyToID *uuid.UUID        `json:"replyToId"`
 	Type      model.MessageType `json:"type"`
+	Metadata  json.RawMessage   `json:"metadata"`
+
+	// fromPoll is set by the poll service, see SendMessageInput
+	fromPoll bool
 }
 
 // TopicMessagePage represents a paginated list of topic messages.
@@ -35,24 +39,37 @@ type TopicMessageService interface {
 	SendMessage(ctx context.Context, input SendTopicMessageInput) (*model.TopicMessage, error)
 	GetMessages(ctx context.Context, topicID uuid.UUID, cursor string, limit int) (*TopicMessagePage, error)
 	DeleteMessage(ctx context.Context, messageID, userID uuid.UUID, forAll bool) error
+	AddReaction(ctx context.Context, topicID, messageID, userID uuid.UUID, emoji string) ([]model.ReactionSummary, error)
+	RemoveReaction(ctx context.Context, topicID, messageID, userID uuid.UUID, emoji string) ([]model.ReactionSummary, error)
+	EditMessage(ctx context.Context, topicID, messageID, userID uuid.UUID, content string) (*model.TopicMessage, error)
+	GetEditHistory(ctx context.Context, topicID, messageID, userID uuid.UUID) ([]*model.MessageEdit, error)
 }
 
 type topicMessageService struct {
 	topicMsgRepo repository.TopicMessageRepository
+	reactionRepo repository.ReactionRepository
 	topicRepo    repository.TopicRepository
 	hub          *ws.Hub
+	mentionSvc   MentionService
+	config       MessageConfig
 }
 
 // NewTopicMessageService creates a new TopicMessageService.
 func NewTopicMessageService(
 	topicMsgRepo repository.TopicMessageRepository,
+	reactionRepo repository.ReactionRepository,
 	topicRepo repository.TopicRepository,
 	hub *ws.Hub,
+	mentionSvc MentionService,
+	config MessageConfig,
 ) TopicMessageService {
 	return &topicMessageService{
 		topicMsgRepo: topicMsgRepo,
+		reactionRepo: reactionRepo,
 		topicRepo:    topicRepo,
 		hub:          hub,
+		mentionSvc:   mentionSvc,
+		config:       config,
 	}
 }
 
@@ -64,23 +81,29 @@ func (s *topicMessageService) SendMessage(ctx context.Context, input SendTopicMe
 	if input.Type == model.MessageTypeText && input.Content == "" {
 		return nil, apperror.Validation("content", "message content cannot be empty")
 	}
+	if input.Type == model.MessageTypePoll && !input.fromPoll {
+		return nil, apperror.BadRequest("use the polls endpoint to create a poll")
+	}
+	if input.Type == model.MessageTypeLocation || input.Type == model.MessageTypeContact {
+		return nil, apperror.BadRequest(fmt.Sprintf("%s messages can only be sent in chats", input.Type))
+	}
 
 	// Verify sender is topic member
 	members, err := s.topicRepo.GetMembers(ctx, input.TopicID)
 	if err != nil {
 		return nil, fmt.Errorf("get topic members: %w", err)
 	}
-	isMember := false
-	for _, m := range members {
-		if m.UserID == input.SenderID {
-			isMember = true
-			break
-		}
-	}
-	if !isMember {
+	memberIDs := topicMemberSet(members)
+	if !memberIDs[input.SenderID] {
 		return nil, apperror.Forbidden("you are not a member of this topic")
 	}
 
+	// Only topic members can be mentioned
+	mentioned, err := resolveMentions(input.Content, input.SenderID, memberIDs)
+	if err != nil {
+		return nil, err
+	}
+
 	// Validate replyToID
 	if input.ReplyToID != nil {
 		replyMsg, err := s.topicMsgRepo.FindByID(ctx, *input.ReplyToID)
@@ -102,10 +125,22 @@ func (s *topicMessageService) SendMessage(ctx context.Context, input SendTopicMe
 		Content:   input.Content,
 		ReplyToID: input.ReplyToID,
 		Type:      input.Type,
+		Metadata:  input.Metadata,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create topic message: %w", err)
 	}
+	msg.Mentions = mentioned
+
+	if len(mentioned) > 0 {
+		recordMentions(ctx, s.mentionSvc, model.MentionTarget{
+			SourceType:  model.MentionSourceTopic,
+			SourceID:    input.TopicID,
+			ItemID:      msg.ID,
+			MentionedBy: input.SenderID,
+			Preview:     input.Content,
+		}, mentioned)
+	}
 
 	// Broadcast via WebSocket to topic room
 	roomID := "topic:" + input.TopicID.String()
@@ -146,6 +181,32 @@ func (s *topicMessageService) GetMessages(ctx context.Context, topicID uuid.UUID
 		msgs = msgs[:limit]
 	}
 
+	// Attach aggregated reactions and mentions
+	if len(msgs) > 0 {
+		ids := make([]uuid.UUID, len(msgs))
+		for i, m := range msgs {
+			ids[i] = m.ID
+		}
+		reactions, err := s.reactionRepo.ListByMessages(ctx, ids)
+		if err != nil {
+			return nil, fmt.Errorf("list reactions: %w", err)
+		}
+		for _, m := range msgs {
+			if r := reactions[m.ID]; len(r) > 0 {
+				m.Reactions = model.SummarizeReactions(r)
+			}
+		}
+		if s.mentionSvc != nil {
+			mentions, err := s.mentionSvc.ForItems(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			for _, m := range msgs {
+				m.Mentions = mentions[m.ID]
+			}
+		}
+	}
+
 	var nextCursor string
 	if len(msgs) > 0 {
 		nextCursor = msgs[len(msgs)-1].CreatedAt.Format(time.RFC3339Nano)
@@ -186,6 +247,173 @@ func (s *topicMessageService) DeleteMessage(ctx context.Context, messageID, user
 		data, _ := json.Marshal(event)
 		s.hub.SendToRoom(roomID, data, uuid.Nil)
 	}
+	if forAll && s.mentionSvc != nil {
+		_ = s.mentionSvc.Clear(ctx, messageID)
+	}
 
 	return nil
 }
+
+func (s *topicMessageService) AddReaction(ctx context.Context, topicID, messageID, userID uuid.UUID, emoji string) ([]model.ReactionSummary, error) {
+	emoji, err := validateEmoji(emoji)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.findMemberMessage(ctx, topicID, messageID, userID); err != nil {
+		return nil, err
+	}
+
+	added, err := s.reactionRepo.Add(ctx, messageID, userID, emoji)
+	if err != nil {
+		return nil, fmt.Errorf("add reaction: %w", err)
+	}
+	if !added {
+		// Already reacted with this emoji; nothing to broadcast
+		return messageReactions(ctx, s.reactionRepo, messageID)
+	}
+
+	return s.publishReaction(ctx, topicID, messageID, userID, emoji, ReactionActionAdded)
+}
+
+func (s *topicMessageService) RemoveReaction(ctx context.Context, topicID, messageID, userID uuid.UUID, emoji string) ([]model.ReactionSummary, error) {
+	emoji, err := validateEmoji(emoji)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.findMemberMessage(ctx, topicID, messageID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.reactionRepo.Remove(ctx, messageID, userID, emoji); err != nil {
+		return nil, err
+	}
+
+	return s.publishReaction(ctx, topicID, messageID, userID, emoji, ReactionActionRemoved)
+}
+
+// findMemberMessage checks that the user is a topic member and the message
+// belongs to the topic and has not been deleted for everyone.
+func (s *topicMessageService) findMemberMessage(ctx context.Context, topicID, messageID, userID uuid.UUID) (*model.TopicMessage, error) {
+	members, err := s.topicRepo.GetMembers(ctx, topicID)
+	if err != nil {
+		return nil, fmt.Errorf("get topic members: %w", err)
+	}
+	isMember := false
+	for _, m := range members {
+		if m.UserID == userID {
+			isMember = true
+			break
+		}
+	}
+	if !isMember {
+		return nil, apperror.Forbidden("you are not a member of this topic")
+	}
+
+	msg, err := s.topicMsgRepo.FindByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if msg.TopicID != topicID {
+		return nil, apperror.NotFound("topic message", messageID.String())
+	}
+	if msg.IsDeleted && msg.DeletedForAll {
+		return nil, apperror.BadRequest("message has been deleted")
+	}
+
+	return msg, nil
+}
+
+// publishReaction broadcasts the message's updated reactions to the topic room.
+func (s *topicMessageService) publishReaction(ctx context.Context, topicID, messageID, userID uuid.UUID, emoji, action string) ([]model.ReactionSummary, error) {
+	summaries, err := messageReactions(ctx, s.reactionRepo, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	broadcastReaction(s.hub, "topic:"+topicID.String(), ReactionEvent{
+		TopicID:   &topicID,
+		MessageID: messageID,
+		UserID:    userID,
+		Emoji:     emoji,
+		Action:    action,
+		Reactions: summaries,
+	})
+
+	return summaries, nil
+}
+
+func (s *topicMessageService) EditMessage(ctx context.Context, topicID, messageID, userID uuid.UUID, content string) (*model.TopicMessage, error) {
+	msg, err := s.topicMsgRepo.FindByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if msg.TopicID != topicID {
+		return nil, apperror.NotFound("topic message", messageID.String())
+	}
+	if err := checkEditable(msg.SenderID, userID, msg.Type, msg.IsDeleted, msg.CreatedAt, content, s.config.EditWindow); err != nil {
+		return nil, err
+	}
+	if content == msg.Content {
+		return msg, nil
+	}
+
+	members, err := s.topicRepo.GetMembers(ctx, topicID)
+	if err != nil {
+		return nil, fmt.Errorf("get topic members: %w", err)
+	}
+	mentioned, err := resolveMentions(content, userID, topicMemberSet(members))
+	if err != nil {
+		return nil, err
+	}
+
+	edited, err := s.topicMsgRepo.Edit(ctx, messageID, content)
+	if err != nil {
+		return nil, fmt.Errorf("edit topic message: %w", err)
+	}
+	edited.Mentions = mentioned
+
+	recordMentions(ctx, s.mentionSvc, model.MentionTarget{
+		SourceType:  model.MentionSourceTopic,
+		SourceID:    topicID,
+		ItemID:      messageID,
+		MentionedBy: userID,
+		Preview:     content,
+	}, mentioned)
+
+	// Broadcast via WebSocket to topic room
+	if s.hub != nil {
+		event := map[string]interface{}{
+			"type":    ws.WSTypeMessageEdited,
+			"payload": edited,
+		}
+		data, err := json.Marshal(event)
+		if err == nil {
+			s.hub.SendToRoom("topic:"+topicID.String(), data, uuid.Nil)
+		}
+	}
+
+	return edited, nil
+}
+
+func (s *topicMessageService) GetEditHistory(ctx context.Context, topicID, messageID, userID uuid.UUID) ([]*model.MessageEdit, error) {
+	if _, err := s.findMemberMessage(ctx, topicID, messageID, userID); err != nil {
+		return nil, err
+	}
+
+	edits, err := s.topicMsgRepo.ListEdits(ctx, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("list topic message edits: %w", err)
+	}
+	return edits, nil
+}
+
+// topicMemberSet indexes topic members by user ID.
+func topicMemberSet(members []*model.TopicMember) map[uuid.UUID]bool {
+	set := make(map[uuid.UUID]bool, len(members))
+	for _, m := range members {
+		set[m.UserID] = true
+	}
+	return set
+}
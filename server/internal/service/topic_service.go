@@ -104,17 +104,20 @@ func (s *topicService) CreateTopic(ctx context.Context, userID uuid.UUID, input
 		return nil, fmt.Errorf("get parent members: %w", err)
 	}
 
-	creatorIsMember := false
+	var creatorRole model.MemberRole
 	parentMemberSet := make(map[uuid.UUID]bool)
 	for _, m := range parentMembers {
 		parentMemberSet[m.UserID] = true
 		if m.UserID == userID {
-			creatorIsMember = true
+			creatorRole = m.Role
 		}
 	}
-	if !creatorIsMember {
+	if creatorRole == "" {
 		return nil, apperror.Forbidden("you are not a member of the parent chat")
 	}
+	if parentChat.Type == model.ChatTypeGroup && parentChat.Permissions.OnlyAdminsCreateTopics && !creatorRole.IsAdmin() {
+		return nil, apperror.Forbidden("only admins can create topics in this group")
+	}
 
 	// Determine members
 	var memberIDs []uuid.UUID
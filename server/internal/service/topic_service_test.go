@@ -144,6 +144,7 @@ func (m *mockTopicRepo) Delete(_ context.Context, id uuid.UUID) error {
 type mockTopicMsgRepo struct {
 	messages map[uuid.UUID]*model.TopicMessage
 	byTopic  map[uuid.UUID][]*model.TopicMessage
+	edits    map[uuid.UUID][]*model.MessageEdit
 
 	createErr      error
 	findErr        error
@@ -155,6 +156,7 @@ func newMockTopicMsgRepo() *mockTopicMsgRepo {
 	return &mockTopicMsgRepo{
 		messages: make(map[uuid.UUID]*model.TopicMessage),
 		byTopic:  make(map[uuid.UUID][]*model.TopicMessage),
+		edits:    make(map[uuid.UUID][]*model.MessageEdit),
 	}
 }
 
@@ -173,6 +175,7 @@ func (m *mockTopicMsgRepo) Create(_ context.Context, input model.CreateTopicMess
 		Content:   input.Content,
 		ReplyToID: input.ReplyToID,
 		Type:      msgType,
+		Metadata:  input.Metadata,
 		CreatedAt: time.Now(),
 	}
 	m.messages[msg.ID] = msg
@@ -180,6 +183,24 @@ func (m *mockTopicMsgRepo) Create(_ context.Context, input model.CreateTopicMess
 	return msg, nil
 }
 
+func (m *mockTopicMsgRepo) Edit(_ context.Context, id uuid.UUID, content string) (*model.TopicMessage, error) {
+	msg, ok := m.messages[id]
+	if !ok {
+		return nil, apperror.NotFound("topic message", id.String())
+	}
+	now := time.Now()
+	m.edits[id] = append(m.edits[id], &model.MessageEdit{
+		ID: uuid.New(), MessageID: id, Content: msg.Content, EditedAt: now,
+	})
+	msg.Content = content
+	msg.EditedAt = &now
+	return msg, nil
+}
+
+func (m *mockTopicMsgRepo) ListEdits(_ context.Context, messageID uuid.UUID) ([]*model.MessageEdit, error) {
+	return m.edits[messageID], nil
+}
+
 func (m *mockTopicMsgRepo) FindByID(_ context.Context, id uuid.UUID) (*model.TopicMessage, error) {
 	if m.findErr != nil {
 		return nil, m.findErr
@@ -345,6 +366,39 @@ func TestTopicService_CreateInvalidMember(t *testing.T) {
 	assert.Contains(t, err.Error(), "not in parent chat")
 }
 
+func TestTopicService_CreateOnlyAdmins(t *testing.T) {
+	svc, _, _, chatRepo, userRepo := setupTopicService()
+
+	owner := uuid.New()
+	memberA := uuid.New()
+	userRepo.addUser(&model.User{ID: owner, Phone: "+628111", Name: "Owner"})
+	userRepo.addUser(&model.User{ID: memberA, Phone: "+628222", Name: "MemberA"})
+
+	chat, _ := chatRepo.Create(context.Background(), model.CreateChatInput{
+		Type:      model.ChatTypeGroup,
+		Name:      "Tim",
+		CreatedBy: owner,
+	})
+	chat.Permissions.OnlyAdminsCreateTopics = true
+	_ = chatRepo.AddMember(context.Background(), chat.ID, owner, model.MemberRoleOwner)
+	_ = chatRepo.AddMember(context.Background(), chat.ID, memberA, model.MemberRoleMember)
+
+	_, err := svc.CreateTopic(context.Background(), memberA, CreateTopicInput{
+		Name:     "Test",
+		Icon:     "📌",
+		ParentID: chat.ID,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "only admins")
+
+	_, err = svc.CreateTopic(context.Background(), owner, CreateTopicInput{
+		Name:     "Test",
+		Icon:     "📌",
+		ParentID: chat.ID,
+	})
+	require.NoError(t, err)
+}
+
 func TestTopicService_CreateValidation(t *testing.T) {
 	svc, _, _, chatRepo, userRepo := setupTopicService()
 
@@ -508,7 +562,7 @@ func TestTopicMessageService_SendMessage(t *testing.T) {
 	hub := newTestHub()
 	defer hub.Shutdown()
 
-	svc := NewTopicMessageService(topicMsgRepo, topicRepo, hub)
+	svc := NewTopicMessageService(topicMsgRepo, newMockReactionRepo(), topicRepo, hub, nil, DefaultMessageConfig())
 
 	user := uuid.New()
 	topicID := uuid.New()
@@ -546,6 +600,17 @@ func TestTopicMessageService_SendMessage(t *testing.T) {
 		})
 		require.Error(t, err)
 	})
+
+	t.Run("location only in chats", func(t *testing.T) {
+		_, err := svc.SendMessage(context.Background(), SendTopicMessageInput{
+			TopicID:  topicID,
+			SenderID: user,
+			Type:     model.MessageTypeLocation,
+			Metadata: []byte(`{"latitude":0,"longitude":0}`),
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only be sent in chats")
+	})
 }
 
 func TestTopicMessageService_GetMessages(t *testing.T) {
@@ -554,7 +619,7 @@ func TestTopicMessageService_GetMessages(t *testing.T) {
 	hub := newTestHub()
 	defer hub.Shutdown()
 
-	svc := NewTopicMessageService(topicMsgRepo, topicRepo, hub)
+	svc := NewTopicMessageService(topicMsgRepo, newMockReactionRepo(), topicRepo, hub, nil, DefaultMessageConfig())
 
 	user := uuid.New()
 	topicID := uuid.New()
@@ -585,7 +650,7 @@ func TestTopicMessageService_DeleteMessage(t *testing.T) {
 	hub := newTestHub()
 	defer hub.Shutdown()
 
-	svc := NewTopicMessageService(topicMsgRepo, topicRepo, hub)
+	svc := NewTopicMessageService(topicMsgRepo, newMockReactionRepo(), topicRepo, hub, nil, DefaultMessageConfig())
 
 	user := uuid.New()
 	other := uuid.New()
@@ -718,7 +783,7 @@ func TestTopicService_UpdateTopic(t *testing.T) {
 func TestTopicMessageService_SendMessage_Errors(t *testing.T) {
 	topicRepo := newMockTopicRepo()
 	topicMsgRepo := newMockTopicMsgRepo()
-	svc := NewTopicMessageService(topicMsgRepo, topicRepo, nil)
+	svc := NewTopicMessageService(topicMsgRepo, newMockReactionRepo(), topicRepo, nil, nil, DefaultMessageConfig())
 
 	topicID := uuid.New()
 	user := uuid.New()
@@ -735,6 +800,14 @@ func TestTopicMessageService_SendMessage_Errors(t *testing.T) {
 		assert.Equal(t, model.MessageTypeText, msg.Type)
 	})
 
+	t.Run("poll type rejected", func(t *testing.T) {
+		_, err := svc.SendMessage(context.Background(), SendTopicMessageInput{
+			TopicID: topicID, SenderID: user, Content: "Lunch?", Type: model.MessageTypePoll,
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "polls endpoint")
+	})
+
 	t.Run("reply to message in different topic", func(t *testing.T) {
 		otherMsg := &model.TopicMessage{ID: uuid.New(), TopicID: uuid.New()}
 		topicMsgRepo.messages[otherMsg.ID] = otherMsg
@@ -779,7 +852,7 @@ func TestTopicMessageService_SendMessage_Errors(t *testing.T) {
 func TestTopicMessageService_GetMessages_Errors(t *testing.T) {
 	topicRepo := newMockTopicRepo()
 	topicMsgRepo := newMockTopicMsgRepo()
-	svc := NewTopicMessageService(topicMsgRepo, topicRepo, nil)
+	svc := NewTopicMessageService(topicMsgRepo, newMockReactionRepo(), topicRepo, nil, nil, DefaultMessageConfig())
 
 	topicID := uuid.New()
 
@@ -812,7 +885,7 @@ func TestTopicMessageService_GetMessages_Errors(t *testing.T) {
 func TestTopicMessageService_DeleteMessage_Errors(t *testing.T) {
 	topicRepo := newMockTopicRepo()
 	topicMsgRepo := newMockTopicMsgRepo()
-	svc := NewTopicMessageService(topicMsgRepo, topicRepo, nil)
+	svc := NewTopicMessageService(topicMsgRepo, newMockReactionRepo(), topicRepo, nil, nil, DefaultMessageConfig())
 
 	topicID := uuid.New()
 	sender := uuid.New()
@@ -1063,3 +1136,71 @@ func TestTopicService_DeleteTopic_Errors(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+func TestTopicMessageService_Reactions(t *testing.T) {
+	topicRepo := newMockTopicRepo()
+	topicMsgRepo := newMockTopicMsgRepo()
+	svc := NewTopicMessageService(topicMsgRepo, newMockReactionRepo(), topicRepo, nil, nil, DefaultMessageConfig())
+
+	user := uuid.New()
+	topicID := uuid.New()
+	topicRepo.topics[topicID] = &model.Topic{ID: topicID, Name: "Test"}
+	topicRepo.members[topicID] = []*model.TopicMember{
+		{TopicID: topicID, UserID: user, Role: model.MemberRoleAdmin},
+	}
+
+	msg, err := svc.SendMessage(context.Background(), SendTopicMessageInput{
+		TopicID: topicID, SenderID: user, Content: "Hello topic",
+	})
+	require.NoError(t, err)
+
+	summaries, err := svc.AddReaction(context.Background(), topicID, msg.ID, user, "🎉")
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+	assert.Equal(t, 1, summaries[0].Count)
+
+	page, err := svc.GetMessages(context.Background(), topicID, "", 20)
+	require.NoError(t, err)
+	require.Len(t, page.Messages, 1)
+	assert.Equal(t, "🎉", page.Messages[0].Reactions[0].Emoji)
+
+	summaries, err = svc.RemoveReaction(context.Background(), topicID, msg.ID, user, "🎉")
+	require.NoError(t, err)
+	assert.Empty(t, summaries)
+
+	_, err = svc.AddReaction(context.Background(), topicID, msg.ID, uuid.New(), "🎉")
+	require.Error(t, err)
+}
+
+func TestTopicMessageService_EditMessage(t *testing.T) {
+	topicRepo := newMockTopicRepo()
+	topicMsgRepo := newMockTopicMsgRepo()
+	svc := NewTopicMessageService(topicMsgRepo, newMockReactionRepo(), topicRepo, nil, nil, DefaultMessageConfig())
+
+	user := uuid.New()
+	other := uuid.New()
+	topicID := uuid.New()
+	topicRepo.topics[topicID] = &model.Topic{ID: topicID, Name: "Test"}
+	topicRepo.members[topicID] = []*model.TopicMember{
+		{TopicID: topicID, UserID: user, Role: model.MemberRoleAdmin},
+		{TopicID: topicID, UserID: other, Role: model.MemberRoleMember},
+	}
+
+	msg, err := svc.SendMessage(context.Background(), SendTopicMessageInput{
+		TopicID: topicID, SenderID: user, Content: "Draft",
+	})
+	require.NoError(t, err)
+
+	edited, err := svc.EditMessage(context.Background(), topicID, msg.ID, user, "Final")
+	require.NoError(t, err)
+	assert.Equal(t, "Final", edited.Content)
+	assert.NotNil(t, edited.EditedAt)
+
+	history, err := svc.GetEditHistory(context.Background(), topicID, msg.ID, other)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, "Draft", history[0].Content)
+
+	_, err = svc.EditMessage(context.Background(), topicID, msg.ID, other, "Nope")
+	require.Error(t, err)
+}
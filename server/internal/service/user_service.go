@@ -149,9 +149,9 @@ func validateUpdateInput(input model.UpdateUserInput) error {
 
 // validVisibility values for privacy settings.
 var validVisibility = map[string]bool{
-	"everyone": true,
-	"contacts": true,
-	"nobody":   true,
+	VisibilityEveryone: true,
+	VisibilityContacts: true,
+	VisibilityNobody:   true,
 }
 
 // validatePrivacySettings validates privacy setting values.
@@ -61,7 +61,7 @@ func GetDBURL() string {
 func CleanTables(t *testing.T, pool *pgxpool.Pool) {
 	t.Helper()
 	ctx := context.Background()
-	_, err := pool.Exec(ctx, `TRUNCATE message_status, topic_message_status, document_entities, document_tags, document_history, blocks, document_signers, document_collaborators, topic_messages, topic_members, topics, messages, chat_members, chats, entities, documents, users CASCADE`)
+	_, err := pool.Exec(ctx, `TRUNCATE document_comments, document_versions, poll_votes, poll_options, polls, broadcast_messages, broadcasts, broadcast_list_recipients, broadcast_lists, group_join_requests, group_invites, abuse_reports, user_blocks, scheduled_messages, thread_followers, mentions, message_edits, topic_message_edits, message_reactions, topic_message_reactions, message_status, topic_message_status, document_entities, document_tags, document_history, blocks, document_signers, document_collaborators, topic_messages, topic_members, topics, messages, chat_members, chats, entities, documents, users CASCADE`)
 	require.NoError(t, err, "clean tables")
 }
 
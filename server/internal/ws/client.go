@@ -14,10 +14,16 @@ const (
 	pongWait       = 60 * time.Second
 	pingPeriod     = (pongWait * 9) / 10
 	maxMessageSize = 4096
+	// maxDocumentMessageSize is the read limit for document edits, which can
+	// carry a pasted block.
+	maxDocumentMessageSize = 256 * 1024
 
 	// WebSocket rate limiting: max messages per window
 	wsRateLimitMessages = 30
 	wsRateLimitWindow   = 60 * time.Second
+	// Document edits arrive with every keystroke, so they have their own
+	// budget
+	wsDocumentRateLimitMessages = 1200
 )
 
 // MessageHandler is a callback invoked when the client receives a typed message.
@@ -26,23 +32,47 @@ type MessageHandler func(client *Client, msg WSMessage)
 // Client represents a single WebSocket connection.
 type Client struct {
 	UserID         uuid.UUID
+	DeviceID       string
 	Conn           *websocket.Conn
 	Send           chan []byte
 	Hub            *Hub
 	MessageHandler MessageHandler
 
 	// Rate limiting state (per-client, not shared)
-	msgCount       int
-	msgWindowStart time.Time
+	chatRate     rateWindow
+	documentRate rateWindow
 }
 
-// NewClient creates a new client.
-func NewClient(hub *Hub, conn *websocket.Conn, userID uuid.UUID) *Client {
+// rateWindow counts the messages of a client in a fixed window.
+type rateWindow struct {
+	count       int
+	windowStart time.Time
+}
+
+// allow counts a message and reports whether it is within limit.
+func (w *rateWindow) allow(now time.Time, limit int) bool {
+	if now.Sub(w.windowStart) > wsRateLimitWindow {
+		w.count = 0
+		w.windowStart = now
+	}
+	w.count++
+	return w.count <= limit
+}
+
+// isDocumentMessage reports whether a client message is a document edit,
+// which gets the document size and rate limits.
+func isDocumentMessage(msgType string) bool {
+	return msgType == WSTypeDocUpdate
+}
+
+// NewClient creates a new client for one of the user's devices.
+func NewClient(hub *Hub, conn *websocket.Conn, userID uuid.UUID, deviceID string) *Client {
 	return &Client{
-		UserID: userID,
-		Conn:   conn,
-		Send:   make(chan []byte, 256),
-		Hub:    hub,
+		UserID:   userID,
+		DeviceID: deviceID,
+		Conn:     conn,
+		Send:     make(chan []byte, 256),
+		Hub:      hub,
 	}
 }
 
@@ -54,7 +84,7 @@ func (c *Client) ReadPump() {
 		_ = c.Conn.Close()
 	}()
 
-	c.Conn.SetReadLimit(maxMessageSize)
+	c.Conn.SetReadLimit(maxDocumentMessageSize)
 	if err := c.Conn.SetReadDeadline(time.Now().Add(pongWait)); err != nil {
 		log.Error().Err(err).Str("user_id", c.UserID.String()).Msg("failed to set read deadline")
 		return
@@ -72,21 +102,27 @@ func (c *Client) ReadPump() {
 			break
 		}
 
-		// Rate limiting: sliding window counter
-		now := time.Now()
-		if now.Sub(c.msgWindowStart) > wsRateLimitWindow {
-			c.msgCount = 0
-			c.msgWindowStart = now
+		var wsMsg WSMessage
+		jsonErr := json.Unmarshal(message, &wsMsg)
+		document := jsonErr == nil && isDocumentMessage(wsMsg.Type)
+
+		if !document && len(message) > maxMessageSize {
+			log.Warn().Str("user_id", c.UserID.String()).Int("size", len(message)).Msg("websocket message too large, disconnecting")
+			break
 		}
-		c.msgCount++
-		if c.msgCount > wsRateLimitMessages {
-			log.Warn().Str("user_id", c.UserID.String()).Msg("websocket rate limit exceeded, disconnecting")
+
+		// Rate limiting: fixed window counter per budget
+		budget, limit := &c.chatRate, wsRateLimitMessages
+		if document {
+			budget, limit = &c.documentRate, wsDocumentRateLimitMessages
+		}
+		if !budget.allow(time.Now(), limit) {
+			log.Warn().Str("user_id", c.UserID.String()).Bool("document", document).Msg("websocket rate limit exceeded, disconnecting")
 			break
 		}
 
-		var wsMsg WSMessage
-		if err := json.Unmarshal(message, &wsMsg); err != nil {
-			log.Warn().Err(err).Str("user_id", c.UserID.String()).Msg("invalid ws message format")
+		if jsonErr != nil {
+			log.Warn().Err(jsonErr).Str("user_id", c.UserID.String()).Msg("invalid ws message format")
 			continue
 		}
 
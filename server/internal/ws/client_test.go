@@ -0,0 +1,111 @@
+package ws_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otoritech/chatat/internal/ws"
+)
+
+// dialTestClient serves one websocket client on hub and returns the
+// connection to it and the message types it handled.
+func dialTestClient(t *testing.T, hub *ws.Hub, userID uuid.UUID) (*websocket.Conn, <-chan string) {
+	t.Helper()
+	handled := make(chan string, 1024)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		client := ws.NewClient(hub, conn, userID, "d1")
+		client.MessageHandler = func(_ *ws.Client, msg ws.WSMessage) { handled <- msg.Type }
+		hub.RegisterClient(client)
+		go client.WritePump()
+		go client.ReadPump()
+	}))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn, handled
+}
+
+func sendTestMessage(t *testing.T, conn *websocket.Conn, msgType string, payload any) {
+	t.Helper()
+	payloadBytes, err := json.Marshal(payload)
+	require.NoError(t, err)
+	require.NoError(t, conn.WriteJSON(ws.WSMessage{Type: msgType, Payload: payloadBytes}))
+}
+
+func TestClient_DocumentEditsHaveTheirOwnBudget(t *testing.T) {
+	hub := startHub(t)
+	userID := uuid.New()
+	conn, handled := dialTestClient(t, hub, userID)
+
+	// A burst of typing, one text op per keystroke
+	const keystrokes = 200
+	for i := 0; i < keystrokes; i++ {
+		sendTestMessage(t, conn, ws.WSTypeDocUpdate, map[string]any{
+			"documentId": uuid.NewString(),
+			"action":     "text",
+			"ops":        []map[string]any{{"type": "insert", "value": "a"}},
+		})
+	}
+	// A pasted block is larger than a chat message
+	sendTestMessage(t, conn, ws.WSTypeDocUpdate, map[string]any{
+		"action": "update",
+		"value":  strings.Repeat("x", 32*1024),
+	})
+
+	for i := 0; i < keystrokes+1; i++ {
+		select {
+		case msgType := <-handled:
+			assert.Equal(t, ws.WSTypeDocUpdate, msgType)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("handled %d of %d document edits", i, keystrokes+1)
+		}
+	}
+	assert.True(t, hub.IsOnline(userID))
+
+	// Chat messages still have their own budget
+	sendTestMessage(t, conn, ws.WSTypeTyping, map[string]any{"chatId": uuid.NewString()})
+	select {
+	case msgType := <-handled:
+		assert.Equal(t, ws.WSTypeTyping, msgType)
+	case <-time.After(time.Second):
+		t.Fatal("typing should have been handled")
+	}
+}
+
+func TestClient_ChatRateLimitDisconnects(t *testing.T) {
+	hub := startHub(t)
+	conn, _ := dialTestClient(t, hub, uuid.New())
+
+	for i := 0; i < 31; i++ {
+		sendTestMessage(t, conn, ws.WSTypeTyping, map[string]any{"chatId": uuid.NewString()})
+	}
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				t.Fatal("connection should have been closed")
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	clusterChannel      = "ws:cluster"
+	presenceKeyPrefix   = "ws:presence:"
+	defaultPresenceTTL  = 90 * time.Second
+	clusterEventBufSize = 256
+)
+
+// ClusterEvent is a hub broadcast relayed between server replicas.
+// Exactly one of Room, UserID or Node is set. A user event with a DeviceID
+// only goes to that device, or disconnects it when Disconnect is set. A node
+// event is only handled by the replica it names (see Hub.SendToNode).
+type ClusterEvent struct {
+	Origin     string    `json:"origin"`
+	Room       string    `json:"room,omitempty"`
+	UserID     uuid.UUID `json:"userId,omitempty"`
+	DeviceID   string    `json:"deviceId,omitempty"`
+	Disconnect bool      `json:"disconnect,omitempty"`
+	Node       string    `json:"node,omitempty"`
+	Exclude    uuid.UUID `json:"exclude,omitempty"`
+	EventID    string    `json:"eventId"`
+	Data       []byte    `json:"data"`
+}
+
+// Cluster relays hub broadcasts and connection presence between server
+// replicas so that every node can deliver to its own local clients.
+type Cluster interface {
+	// NodeID identifies this replica; events it publishes carry it as Origin.
+	NodeID() string
+	Publish(ctx context.Context, event ClusterEvent) error
+	// Subscribe returns once the subscription is active. The channel is closed
+	// when the cluster is closed.
+	Subscribe(ctx context.Context) (<-chan ClusterEvent, error)
+	SetPresence(ctx context.Context, userID uuid.UUID, deviceID string) error
+	ClearPresence(ctx context.Context, userID uuid.UUID, deviceID string) error
+	OnlineUsers(ctx context.Context, userIDs []uuid.UUID) ([]uuid.UUID, error)
+	// PresenceTTL is how long a presence entry lives without a refresh.
+	PresenceTTL() time.Duration
+	Close() error
+}
+
+// RedisCluster implements Cluster on top of Redis pub/sub. Presence is kept
+// in one sorted set per user whose members are "<node>|<device>" scored by
+// their expiry time, so entries left behind by a crashed node age out.
+type RedisCluster struct {
+	redis       *redis.Client
+	nodeID      string
+	presenceTTL time.Duration
+	pubsub      *redis.PubSub
+}
+
+// NewRedisCluster creates a Redis-backed cluster backend for the given node.
+// If presenceTTL is 0, a default of 90 seconds is used.
+func NewRedisCluster(client *redis.Client, nodeID string, presenceTTL time.Duration) *RedisCluster {
+	if presenceTTL == 0 {
+		presenceTTL = defaultPresenceTTL
+	}
+	return &RedisCluster{
+		redis:       client,
+		nodeID:      nodeID,
+		presenceTTL: presenceTTL,
+	}
+}
+
+// NodeID returns the identifier of this replica.
+func (c *RedisCluster) NodeID() string {
+	return c.nodeID
+}
+
+// PresenceTTL returns the lifetime of a presence entry.
+func (c *RedisCluster) PresenceTTL() time.Duration {
+	return c.presenceTTL
+}
+
+// Publish sends an event to every replica, including this one.
+func (c *RedisCluster) Publish(ctx context.Context, event ClusterEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal cluster event: %w", err)
+	}
+	if err := c.redis.Publish(ctx, clusterChannel, data).Err(); err != nil {
+		return fmt.Errorf("publish cluster event: %w", err)
+	}
+	return nil
+}
+
+// Subscribe subscribes to the cluster channel and streams decoded events.
+func (c *RedisCluster) Subscribe(ctx context.Context) (<-chan ClusterEvent, error) {
+	pubsub := c.redis.Subscribe(ctx, clusterChannel)
+	// Wait for the subscription confirmation so no event published after
+	// Subscribe returns can be missed.
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, fmt.Errorf("subscribe cluster channel: %w", err)
+	}
+	c.pubsub = pubsub
+
+	events := make(chan ClusterEvent, clusterEventBufSize)
+	go func() {
+		defer close(events)
+		for msg := range pubsub.Channel() {
+			var event ClusterEvent
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				log.Warn().Err(err).Msg("invalid cluster event")
+				continue
+			}
+			events <- event
+		}
+	}()
+	return events, nil
+}
+
+// SetPresence records (or refreshes) a live connection on this node.
+func (c *RedisCluster) SetPresence(ctx context.Context, userID uuid.UUID, deviceID string) error {
+	key := presenceKeyPrefix + userID.String()
+	expiresAt := time.Now().Add(c.presenceTTL).Unix()
+
+	pipe := c.redis.TxPipeline()
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(expiresAt), Member: c.presenceMember(deviceID)})
+	pipe.ZRemRangeByScore(ctx, key, "-inf", "("+strconv.FormatInt(time.Now().Unix(), 10))
+	pipe.Expire(ctx, key, c.presenceTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("set presence: %w", err)
+	}
+	return nil
+}
+
+// ClearPresence removes a connection of this node from the presence set.
+func (c *RedisCluster) ClearPresence(ctx context.Context, userID uuid.UUID, deviceID string) error {
+	key := presenceKeyPrefix + userID.String()
+	if err := c.redis.ZRem(ctx, key, c.presenceMember(deviceID)).Err(); err != nil {
+		return fmt.Errorf("clear presence: %w", err)
+	}
+	return nil
+}
+
+// OnlineUsers returns the subset of userIDs that have a live connection on any node.
+func (c *RedisCluster) OnlineUsers(ctx context.Context, userIDs []uuid.UUID) ([]uuid.UUID, error) {
+	if len(userIDs) == 0 {
+		return []uuid.UUID{}, nil
+	}
+
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	pipe := c.redis.Pipeline()
+	counts := make([]*redis.IntCmd, len(userIDs))
+	for i, id := range userIDs {
+		counts[i] = pipe.ZCount(ctx, presenceKeyPrefix+id.String(), now, "+inf")
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("get online users: %w", err)
+	}
+
+	online := make([]uuid.UUID, 0)
+	for i, cmd := range counts {
+		if cmd.Val() > 0 {
+			online = append(online, userIDs[i])
+		}
+	}
+	return online, nil
+}
+
+// Close stops the subscription.
+func (c *RedisCluster) Close() error {
+	if c.pubsub == nil {
+		return nil
+	}
+	return c.pubsub.Close()
+}
+
+func (c *RedisCluster) presenceMember(deviceID string) string {
+	return c.nodeID + "|" + deviceID
+}
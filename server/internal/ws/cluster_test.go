@@ -0,0 +1,185 @@
+package ws_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otoritech/chatat/internal/ws"
+)
+
+func startClusterHub(t *testing.T, addr, nodeID string) *ws.Hub {
+	t.Helper()
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	t.Cleanup(func() { _ = client.Close() })
+
+	hub := ws.NewHub()
+	require.NoError(t, hub.SetCluster(ws.NewRedisCluster(client, nodeID, 0)))
+	go hub.Run()
+	t.Cleanup(func() { hub.Shutdown() })
+	return hub
+}
+
+func expectMessage(t *testing.T, c *ws.Client, want string) {
+	t.Helper()
+	select {
+	case msg := <-c.Send:
+		assert.Equal(t, want, string(msg))
+	case <-time.After(time.Second):
+		t.Fatalf("expected %q for user %s", want, c.UserID)
+	}
+}
+
+func expectNoMessage(t *testing.T, c *ws.Client) {
+	t.Helper()
+	select {
+	case msg := <-c.Send:
+		t.Fatalf("unexpected message %q for user %s", msg, c.UserID)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestCluster_SendToRoom_AcrossNodes(t *testing.T) {
+	mr := miniredis.RunT(t)
+	nodeA := startClusterHub(t, mr.Addr(), "node-a")
+	nodeB := startClusterHub(t, mr.Addr(), "node-b")
+
+	sender := uuid.New()
+	local := &ws.Client{UserID: uuid.New(), DeviceID: "d1", Send: make(chan []byte, 16), Hub: nodeA}
+	remote := &ws.Client{UserID: uuid.New(), DeviceID: "d1", Send: make(chan []byte, 16), Hub: nodeB}
+	excluded := &ws.Client{UserID: sender, DeviceID: "d1", Send: make(chan []byte, 16), Hub: nodeB}
+
+	nodeA.RegisterClient(local)
+	nodeB.RegisterClient(remote)
+	nodeB.RegisterClient(excluded)
+	time.Sleep(20 * time.Millisecond)
+
+	nodeA.JoinRoom(local, "chat:cluster")
+	nodeB.JoinRoom(remote, "chat:cluster")
+	nodeB.JoinRoom(excluded, "chat:cluster")
+
+	nodeA.SendToRoom("chat:cluster", []byte("hello"), sender)
+
+	expectMessage(t, local, "hello")
+	expectMessage(t, remote, "hello")
+	expectNoMessage(t, excluded)
+
+	// The origin node must not deliver its own event twice
+	expectNoMessage(t, local)
+}
+
+func TestCluster_SendToUser_AcrossNodes(t *testing.T) {
+	mr := miniredis.RunT(t)
+	nodeA := startClusterHub(t, mr.Addr(), "node-a")
+	nodeB := startClusterHub(t, mr.Addr(), "node-b")
+
+	userID := uuid.New()
+	phone := &ws.Client{UserID: userID, DeviceID: "phone", Send: make(chan []byte, 16), Hub: nodeA}
+	tablet := &ws.Client{UserID: userID, DeviceID: "tablet", Send: make(chan []byte, 16), Hub: nodeB}
+
+	nodeA.RegisterClient(phone)
+	nodeB.RegisterClient(tablet)
+	time.Sleep(20 * time.Millisecond)
+
+	nodeB.SendToUser(userID, []byte("direct"))
+
+	expectMessage(t, phone, "direct")
+	expectMessage(t, tablet, "direct")
+	expectNoMessage(t, tablet)
+}
+
+func TestCluster_Presence(t *testing.T) {
+	mr := miniredis.RunT(t)
+	nodeA := startClusterHub(t, mr.Addr(), "node-a")
+	nodeB := startClusterHub(t, mr.Addr(), "node-b")
+
+	userID := uuid.New()
+	offline := uuid.New()
+	client := &ws.Client{UserID: userID, DeviceID: "phone", Send: make(chan []byte, 16), Hub: nodeA}
+
+	nodeA.RegisterClient(client)
+
+	assert.Eventually(t, func() bool { return nodeB.IsOnline(userID) }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []uuid.UUID{userID}, nodeB.GetOnlineUsers([]uuid.UUID{userID, offline}))
+	assert.False(t, nodeB.IsOnline(offline))
+
+	nodeA.UnregisterClient(client)
+	assert.Eventually(t, func() bool { return !nodeB.IsOnline(userID) }, time.Second, 10*time.Millisecond)
+}
+
+func TestRedisCluster_PresenceExpires(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer func() { _ = client.Close() }()
+
+	cluster := ws.NewRedisCluster(client, "node-a", 2*time.Second)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	require.NoError(t, cluster.SetPresence(ctx, userID, "phone"))
+	online, err := cluster.OnlineUsers(ctx, []uuid.UUID{userID})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{userID}, online)
+
+	// A crashed node stops refreshing; its entry must age out
+	mr.FastForward(3 * time.Second)
+	online, err = cluster.OnlineUsers(ctx, []uuid.UUID{userID})
+	require.NoError(t, err)
+	assert.Empty(t, online)
+}
+
+func TestCluster_SendToDevice_AcrossNodes(t *testing.T) {
+	mr := miniredis.RunT(t)
+	nodeA := startClusterHub(t, mr.Addr(), "node-a")
+	nodeB := startClusterHub(t, mr.Addr(), "node-b")
+
+	userID := uuid.New()
+	phone := &ws.Client{UserID: userID, DeviceID: "phone", Send: make(chan []byte, 16), Hub: nodeA}
+	tablet := &ws.Client{UserID: userID, DeviceID: "tablet", Send: make(chan []byte, 16), Hub: nodeB}
+
+	nodeA.RegisterClient(phone)
+	nodeB.RegisterClient(tablet)
+	time.Sleep(20 * time.Millisecond)
+
+	nodeA.SendToDevice(userID, "tablet", []byte("only tablet"))
+
+	expectMessage(t, tablet, "only tablet")
+	expectNoMessage(t, phone)
+	assert.Equal(t, []string{"tablet"}, nodeB.GetUserDevices(userID))
+}
+
+func TestCluster_SendToNode(t *testing.T) {
+	mr := miniredis.RunT(t)
+	nodeA := startClusterHub(t, mr.Addr(), "node-a")
+	nodeB := startClusterHub(t, mr.Addr(), "node-b")
+
+	received := make(chan string, 4)
+	nodeA.SetNodeHandler(func(data []byte) { received <- "a:" + string(data) })
+	nodeB.SetNodeHandler(func(data []byte) { received <- "b:" + string(data) })
+
+	nodeA.SendToNode("node-b", []byte("remote"))
+	nodeA.SendToNode("node-a", []byte("local"))
+
+	got := make([]string, 0, 2)
+	for len(got) < 2 {
+		select {
+		case msg := <-received:
+			got = append(got, msg)
+		case <-time.After(time.Second):
+			t.Fatalf("expected two node messages, got %v", got)
+		}
+	}
+	assert.ElementsMatch(t, []string{"a:local", "b:remote"}, got)
+
+	select {
+	case msg := <-received:
+		t.Fatalf("unexpected node message %q", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -1,10 +1,13 @@
 package ws
 
 import (
+	"context"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
 )
 
 // LWWRegister implements a Last-Writer-Wins Register CRDT.
@@ -37,10 +40,21 @@ func (r *LWWRegister) Merge(remote LWWRegister) bool {
 }
 
 // BlockCRDT tracks the CRDT state for a single block.
+// A block's place in the document is two registers: Parent holds the parent
+// block ID ("" for a top-level block) and Order a fractional index key
+// among its siblings (see KeyBetween). Keeping them apart lets a concurrent
+// move and reparent both take effect whatever order they arrive in.
+//
+// Once a text block receives its first text op, Text holds its content
+// and Content mirrors Text's visible string.
 type BlockCRDT struct {
 	BlockID   uuid.UUID   `json:"blockId"`
+	Type      string      `json:"type,omitempty"`
 	Content   LWWRegister `json:"content"`
 	Checked   LWWRegister `json:"checked"`
+	Parent    LWWRegister `json:"parent"`
+	Order     LWWRegister `json:"order"`
+	Text      *TextCRDT   `json:"text,omitempty"`
 	Deleted   bool        `json:"deleted"`
 	DeletedAt int64       `json:"deletedAt"`
 	DeletedBy uuid.UUID   `json:"deletedBy"`
@@ -48,19 +62,36 @@ type BlockCRDT struct {
 
 // DocumentCRDT manages CRDT state for an entire document.
 // It maintains a map of block CRDTs and provides merge operations.
+//
+// Epoch identifies one seeding of the document. Seeded order keys and
+// character IDs are derived from the persisted blocks, so the same IDs can
+// mean different things after the document is loaded again; edits made
+// against another epoch must not be applied.
 type DocumentCRDT struct {
 	DocumentID uuid.UUID                `json:"documentId"`
+	Epoch      string                   `json:"epoch"`
 	Blocks     map[uuid.UUID]*BlockCRDT `json:"blocks"`
 	Clock      int64                    `json:"clock"` // Lamport clock for this document
 	mu         sync.RWMutex
+
+	// Persistence state, see crdt_store.go
+	dirty     map[uuid.UUID]bool
+	editors   map[uuid.UUID]uuid.UUID
+	reordered bool
+	loadMu    sync.Mutex
+	loaded    bool
+	flushMu   sync.Mutex
 }
 
 // NewDocumentCRDT creates a new CRDT state for a document.
 func NewDocumentCRDT(docID uuid.UUID) *DocumentCRDT {
 	return &DocumentCRDT{
 		DocumentID: docID,
+		Epoch:      uuid.NewString(),
 		Blocks:     make(map[uuid.UUID]*BlockCRDT),
 		Clock:      time.Now().UnixMilli(),
+		dirty:      make(map[uuid.UUID]bool),
+		editors:    make(map[uuid.UUID]uuid.UUID),
 	}
 }
 
@@ -106,17 +137,56 @@ type CRDTDeleteEvent struct {
 	NodeID     uuid.UUID `json:"nodeId"`
 }
 
+// CRDTInsertEvent adds a block at a position. Content, when set, seeds the
+// block's content register.
+type CRDTInsertEvent struct {
+	DocumentID uuid.UUID  `json:"documentId"`
+	BlockID    uuid.UUID  `json:"blockId"`
+	ParentID   *uuid.UUID `json:"parentId"`
+	Key        string     `json:"key"`
+	Type       string     `json:"type"`
+	Content    string     `json:"content"`
+	Timestamp  int64      `json:"timestamp"`
+	NodeID     uuid.UUID  `json:"nodeId"`
+}
+
+// CRDTMoveEvent changes a block's position. A move only changes its order
+// key; a reparent also changes its parent, nil meaning the top level.
+type CRDTMoveEvent struct {
+	DocumentID uuid.UUID  `json:"documentId"`
+	BlockID    uuid.UUID  `json:"blockId"`
+	ParentID   *uuid.UUID `json:"parentId"`
+	Key        string     `json:"key"`
+	Timestamp  int64      `json:"timestamp"`
+	NodeID     uuid.UUID  `json:"nodeId"`
+}
+
+// CRDTTextEvent carries incremental text ops for a block. Base is the
+// content the client started editing from, used to seed the block's text
+// when the server knows none.
+type CRDTTextEvent struct {
+	DocumentID uuid.UUID `json:"documentId"`
+	BlockID    uuid.UUID `json:"blockId"`
+	Base       string    `json:"base"`
+	Ops        []TextOp  `json:"ops"`
+	NodeID     uuid.UUID `json:"nodeId"`
+}
+
+// OrderedBlock is a visible block's place in the document order.
+type OrderedBlock struct {
+	BlockID  uuid.UUID  `json:"blockId"`
+	ParentID *uuid.UUID `json:"parentId,omitempty"`
+	Key      string     `json:"key"`
+	Depth    int        `json:"depth"`
+}
+
 // ApplyUpdate merges a block field update into the document CRDT.
 // Returns true if the update was accepted (remote wins).
 func (d *DocumentCRDT) ApplyUpdate(event CRDTUpdateEvent) bool {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	block, exists := d.Blocks[event.BlockID]
-	if !exists {
-		block = &BlockCRDT{BlockID: event.BlockID}
-		d.Blocks[event.BlockID] = block
-	}
+	block := d.block(event.BlockID)
 
 	if block.Deleted {
 		return false
@@ -128,14 +198,18 @@ func (d *DocumentCRDT) ApplyUpdate(event CRDTUpdateEvent) bool {
 		NodeID:    event.NodeID,
 	}
 
+	var accepted bool
 	switch event.Field {
 	case "content":
-		return block.Content.Merge(remote)
+		// Text-backed content only changes through text ops; see ReplaceText
+		accepted = block.Text == nil && block.Content.Merge(remote)
 	case "checked":
-		return block.Checked.Merge(remote)
-	default:
-		return false
+		accepted = block.Checked.Merge(remote)
+	}
+	if accepted {
+		d.markChanged(event.BlockID, false)
 	}
+	return accepted
 }
 
 // ApplyDelete marks a block as deleted in the CRDT.
@@ -144,11 +218,7 @@ func (d *DocumentCRDT) ApplyDelete(event CRDTDeleteEvent) bool {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	block, exists := d.Blocks[event.BlockID]
-	if !exists {
-		block = &BlockCRDT{BlockID: event.BlockID}
-		d.Blocks[event.BlockID] = block
-	}
+	block := d.block(event.BlockID)
 
 	if block.Deleted && event.Timestamp <= block.DeletedAt {
 		return false
@@ -157,9 +227,304 @@ func (d *DocumentCRDT) ApplyDelete(event CRDTDeleteEvent) bool {
 	block.Deleted = true
 	block.DeletedAt = event.Timestamp
 	block.DeletedBy = event.NodeID
+	d.markChanged(event.BlockID, false)
 	return true
 }
 
+// ApplyInsert places a new block in the document. Replaying an insert is
+// harmless: its registers only win if they are newer than the block's.
+// Returns true if the insert changed the block's position.
+func (d *DocumentCRDT) ApplyInsert(event CRDTInsertEvent) bool {
+	if !ValidOrderKey(event.Key) || (event.ParentID != nil && *event.ParentID == event.BlockID) {
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	block := d.block(event.BlockID)
+	if block.Deleted {
+		return false
+	}
+	if block.Type == "" {
+		block.Type = event.Type
+	}
+
+	accepted := block.Parent.Merge(LWWRegister{Value: parentValue(event.ParentID), Timestamp: event.Timestamp, NodeID: event.NodeID})
+	if block.Order.Merge(LWWRegister{Value: event.Key, Timestamp: event.Timestamp, NodeID: event.NodeID}) {
+		accepted = true
+	}
+	if event.Content != "" {
+		block.Content.Merge(LWWRegister{Value: event.Content, Timestamp: event.Timestamp, NodeID: event.NodeID})
+	}
+	if accepted {
+		d.markChanged(event.BlockID, true)
+	}
+	return accepted
+}
+
+// ApplyMove changes a block's order key among its siblings. ParentID is
+// ignored. Returns true if the move was accepted.
+func (d *DocumentCRDT) ApplyMove(event CRDTMoveEvent) bool {
+	if !ValidOrderKey(event.Key) {
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	block := d.block(event.BlockID)
+	if block.Deleted {
+		return false
+	}
+	if !block.Order.Merge(LWWRegister{Value: event.Key, Timestamp: event.Timestamp, NodeID: event.NodeID}) {
+		return false
+	}
+	d.markChanged(event.BlockID, true)
+	return true
+}
+
+// ApplyReparent moves a block under another parent at the given order key.
+// Returns true if either register accepted the change.
+func (d *DocumentCRDT) ApplyReparent(event CRDTMoveEvent) bool {
+	if !ValidOrderKey(event.Key) || (event.ParentID != nil && *event.ParentID == event.BlockID) {
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	block := d.block(event.BlockID)
+	if block.Deleted {
+		return false
+	}
+
+	accepted := block.Parent.Merge(LWWRegister{Value: parentValue(event.ParentID), Timestamp: event.Timestamp, NodeID: event.NodeID})
+	if block.Order.Merge(LWWRegister{Value: event.Key, Timestamp: event.Timestamp, NodeID: event.NodeID}) {
+		accepted = true
+	}
+	if accepted {
+		d.markChanged(event.BlockID, true)
+	}
+	return accepted
+}
+
+// ApplyText merges text ops into a block's content. The first ops for a
+// block seed its text from the content the server already holds, or from
+// the event's Base if it holds none. Inserts may not claim the server's
+// own node ID. Returns the ops that changed the text.
+func (d *DocumentCRDT) ApplyText(event CRDTTextEvent) []TextOp {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	block := d.block(event.BlockID)
+	if block.Deleted || !IsTextBlockType(block.Type) {
+		return nil
+	}
+	if block.Text == nil {
+		seed := block.Content.Value
+		if block.Content.Timestamp == 0 {
+			seed = event.Base
+		}
+		block.Text = NewTextCRDT(seed)
+	}
+
+	var applied []TextOp
+	for _, op := range event.Ops {
+		if op.Type == "insert" && op.ID.NodeID == textSeedNode {
+			continue
+		}
+		if !block.Text.Apply(op) {
+			continue
+		}
+		applied = append(applied, op)
+		if op.Type == "insert" {
+			if last := op.ID.Timestamp + int64(len([]rune(op.Text))) - 1; last > d.Clock {
+				d.Clock = last
+			}
+		}
+	}
+	if len(applied) > 0 {
+		d.syncTextContent(block, event.NodeID)
+	}
+	return applied
+}
+
+// ReplaceText applies a whole-content update to a text-backed block as
+// text ops against the current text, so characters outside the changed
+// span keep their IDs and clients editing the block as text can apply it.
+// The update must still win over the block's content register. Returns the
+// ops applied, and false if the block is not text-backed and the update
+// should go through ApplyUpdate instead.
+func (d *DocumentCRDT) ReplaceText(event CRDTUpdateEvent) ([]TextOp, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	block, exists := d.Blocks[event.BlockID]
+	if !exists || block.Text == nil || event.Field != "content" {
+		return nil, false
+	}
+	if block.Deleted {
+		return nil, true
+	}
+	current := block.Content
+	if !current.Merge(LWWRegister{Value: event.Value, Timestamp: event.Timestamp, NodeID: event.NodeID}) {
+		return nil, true
+	}
+
+	ops := block.Text.Diff(event.Value, CharID{Timestamp: d.Clock + 1, NodeID: textSeedNode})
+	for _, op := range ops {
+		block.Text.Apply(op)
+		if op.Type == "insert" {
+			d.Clock += int64(len([]rune(op.Text)))
+		}
+	}
+	if len(ops) > 0 {
+		d.syncTextContent(block, event.NodeID)
+	}
+	return ops, true
+}
+
+// syncTextContent mirrors a block's text into its content register, on a
+// fresh tick so that text deletes also show in the state vector. Callers
+// must hold the lock.
+func (d *DocumentCRDT) syncTextContent(block *BlockCRDT, nodeID uuid.UUID) {
+	d.Clock = max(d.Clock, block.Content.Timestamp) + 1
+	block.Content = LWWRegister{
+		Value:     block.Text.String(),
+		Timestamp: d.Clock,
+		NodeID:    nodeID,
+	}
+	d.markChanged(block.BlockID, false)
+}
+
+// Order returns the visible blocks in document order: each parent is
+// followed by its children, and siblings are sorted by order key with the
+// block ID breaking ties between concurrent inserts at the same key. Blocks
+// without an order key are left out, as are the descendants of deleted
+// blocks. A block whose parent is unknown is placed at the top level.
+//
+// Concurrent reparents can form a cycle; the block in it that was reparented
+// last is then placed at the top level, so every replica settles on the
+// same tree.
+func (d *DocumentCRDT) Order() []OrderedBlock {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.orderLocked()
+}
+
+// orderLocked is Order for callers that hold the lock.
+func (d *DocumentCRDT) orderLocked() []OrderedBlock {
+	parents := make(map[uuid.UUID]*uuid.UUID)
+	for id, block := range d.Blocks {
+		if block.Deleted || block.Order.Value == "" {
+			continue
+		}
+		parents[id] = nil
+		if parentID, err := uuid.Parse(block.Parent.Value); err == nil {
+			if parent, ok := d.Blocks[parentID]; ok && (parent.Deleted || parent.Order.Value != "") {
+				parents[id] = &parentID
+			}
+		}
+	}
+	d.breakParentCycles(parents)
+
+	children := make(map[uuid.UUID][]uuid.UUID)
+	var roots []uuid.UUID
+	for id, parentID := range parents {
+		if parentID == nil {
+			roots = append(roots, id)
+			continue
+		}
+		children[*parentID] = append(children[*parentID], id)
+	}
+
+	order := make([]OrderedBlock, 0, len(parents))
+	var visit func(ids []uuid.UUID, parentID *uuid.UUID, depth int)
+	visit = func(ids []uuid.UUID, parentID *uuid.UUID, depth int) {
+		d.sortSiblings(ids)
+		for _, id := range ids {
+			order = append(order, OrderedBlock{BlockID: id, ParentID: parentID, Key: d.Blocks[id].Order.Value, Depth: depth})
+			blockID := id
+			visit(children[id], &blockID, depth+1)
+		}
+	}
+	visit(roots, nil, 0)
+	return order
+}
+
+// breakParentCycles detaches the most recently reparented block of each
+// parent cycle. Callers must hold the lock.
+func (d *DocumentCRDT) breakParentCycles(parents map[uuid.UUID]*uuid.UUID) {
+	for start := range parents {
+		for {
+			// Walk up from start until the top level or a repeated block
+			seen := map[uuid.UUID]bool{start: true}
+			path := []uuid.UUID{start}
+			current := start
+			cycleAt := -1
+			for parents[current] != nil {
+				current = *parents[current]
+				if seen[current] {
+					for i, id := range path {
+						if id == current {
+							cycleAt = i
+							break
+						}
+					}
+					break
+				}
+				seen[current] = true
+				path = append(path, current)
+			}
+			if cycleAt < 0 {
+				break
+			}
+
+			latest := path[cycleAt]
+			for _, id := range path[cycleAt+1:] {
+				candidate, best := d.Blocks[id].Parent, d.Blocks[latest].Parent
+				if candidate.Timestamp > best.Timestamp ||
+					(candidate.Timestamp == best.Timestamp && candidate.NodeID.String() > best.NodeID.String()) {
+					latest = id
+				}
+			}
+			parents[latest] = nil
+		}
+	}
+}
+
+// sortSiblings orders sibling blocks by order key, then block ID. Callers
+// must hold the lock.
+func (d *DocumentCRDT) sortSiblings(ids []uuid.UUID) {
+	sort.Slice(ids, func(i, j int) bool {
+		ki, kj := d.Blocks[ids[i]].Order.Value, d.Blocks[ids[j]].Order.Value
+		if ki != kj {
+			return ki < kj
+		}
+		return ids[i].String() < ids[j].String()
+	})
+}
+
+// block returns the state for a block, creating it if needed. Callers must
+// hold the lock.
+func (d *DocumentCRDT) block(blockID uuid.UUID) *BlockCRDT {
+	block, exists := d.Blocks[blockID]
+	if !exists {
+		block = &BlockCRDT{BlockID: blockID}
+		d.Blocks[blockID] = block
+	}
+	return block
+}
+
+// parentValue is the Parent register value for a parent block ID.
+func parentValue(parentID *uuid.UUID) string {
+	if parentID == nil {
+		return ""
+	}
+	return parentID.String()
+}
+
 // GetBlockState returns the current CRDT state for a block.
 func (d *DocumentCRDT) GetBlockState(blockID uuid.UUID) *BlockCRDT {
 	d.mu.RLock()
@@ -171,12 +536,33 @@ func (d *DocumentCRDT) GetBlockState(blockID uuid.UUID) *BlockCRDT {
 type DocumentCRDTManager struct {
 	documents map[uuid.UUID]*DocumentCRDT
 	mu        sync.RWMutex
+
+	// Persistence, see SetStore
+	store       CRDTStore
+	flushDelay  time.Duration
+	flushTimers map[uuid.UUID]*time.Timer
+	removing    map[uuid.UUID]chan struct{}
+
+	// Ownership, see SetCluster
+	lease     DocumentLease
+	nodeID    string
+	hub       *Hub
+	forward   func(DocumentRequest)
+	lastUsed  map[uuid.UUID]time.Time
+	renewOnce sync.Once
 }
 
-// NewDocumentCRDTManager creates a new CRDT manager.
+// NewDocumentCRDTManager creates a new CRDT manager. Until SetCluster is
+// called it is the only node hosting documents.
 func NewDocumentCRDTManager() *DocumentCRDTManager {
 	return &DocumentCRDTManager{
-		documents: make(map[uuid.UUID]*DocumentCRDT),
+		documents:   make(map[uuid.UUID]*DocumentCRDT),
+		flushDelay:  defaultCRDTFlushDelay,
+		flushTimers: make(map[uuid.UUID]*time.Timer),
+		removing:    make(map[uuid.UUID]chan struct{}),
+		lease:       NewMemoryDocumentLease(),
+		nodeID:      uuid.NewString(),
+		lastUsed:    make(map[uuid.UUID]time.Time),
 	}
 }
 
@@ -194,11 +580,15 @@ func (m *DocumentCRDTManager) GetOrCreate(docID uuid.UUID) *DocumentCRDT {
 	return crdt
 }
 
-// Remove removes a document CRDT (e.g., when all clients leave).
+// Remove unloads a document CRDT (e.g., when it is idle or another node
+// asks for it), first saving its unsaved edits when a store is set. A document whose edits
+// cannot be saved stays loaded so they are retried.
 func (m *DocumentCRDTManager) Remove(docID uuid.UUID) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	delete(m.documents, docID)
+	ctx, cancel := context.WithTimeout(context.Background(), crdtStoreTimeout)
+	defer cancel()
+	if err := m.unload(ctx, docID); err != nil {
+		log.Error().Err(err).Str("document_id", docID.String()).Msg("failed to save document edits on close")
+	}
 }
 
 // Has checks if a document CRDT exists.
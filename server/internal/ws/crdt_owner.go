@@ -0,0 +1,389 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// crdtLeaseTTL is how long a node keeps a document after it last renewed
+	// its lease; leases are renewed every third of it.
+	crdtLeaseTTL = 15 * time.Second
+	// crdtIdleTimeout unloads documents no one has edited or joined for a
+	// while. A node does not unload a document when its own clients leave,
+	// as clients on other nodes may still be editing it.
+	crdtIdleTimeout = 10 * time.Minute
+	// crdtSuspendTimeout bounds how long a REST write waits for the node
+	// hosting a document to save and let go of it.
+	crdtSuspendTimeout = 5 * time.Second
+	crdtSuspendPoll    = 25 * time.Millisecond
+	crdtReleaseRetry   = time.Second
+	// maxDocumentHops stops requests bouncing between nodes while a
+	// document changes hands.
+	maxDocumentHops = 3
+
+	// writeHolderPrefix marks leases held by a REST write rather than a node.
+	writeHolderPrefix = "write:"
+
+	docRequestRelease = "doc_release"
+	docRequestResync  = "doc_resync"
+)
+
+var (
+	// ErrDocumentSuspended is returned by Load while a REST write holds the
+	// document. The write resyncs the document's clients when it is done.
+	ErrDocumentSuspended = errors.New("document is being written")
+	// ErrDocumentBusy is returned by Suspend when the node hosting the
+	// document did not let go of it in time.
+	ErrDocumentBusy = errors.New("document is busy")
+)
+
+// RemoteDocumentError is returned by Load when another node hosts the
+// document. Edits for it should be forwarded there (see Forward).
+type RemoteDocumentError struct {
+	DocumentID uuid.UUID
+	Node       string
+}
+
+func (e *RemoteDocumentError) Error() string {
+	return fmt.Sprintf("document %s is hosted on node %s", e.DocumentID, e.Node)
+}
+
+// DocumentRequest is a message between the nodes of a cluster about a
+// document: a client message forwarded to the node hosting the document
+// (Type is the client message type), or a request to release or resync it.
+type DocumentRequest struct {
+	Type       string          `json:"type"`
+	DocumentID uuid.UUID       `json:"documentId"`
+	UserID     uuid.UUID       `json:"userId,omitempty"`
+	DeviceID   string          `json:"deviceId,omitempty"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	Hops       int             `json:"hops,omitempty"`
+}
+
+// SetCluster shares document ownership with the other replicas of hub
+// through lease (nil keeps the in-memory lease of a single replica), and
+// lets the manager broadcast resyncs to document rooms. Must be called
+// before the manager is used.
+func (m *DocumentCRDTManager) SetCluster(hub *Hub, lease DocumentLease) {
+	m.hub = hub
+	if lease != nil {
+		m.lease = lease
+	}
+	if nodeID := hub.NodeID(); nodeID != "" {
+		m.nodeID = nodeID
+	}
+	hub.SetNodeHandler(m.handleNodeMessage)
+}
+
+// SetForwardHandler sets the function handling client messages forwarded
+// from other nodes for documents hosted here.
+func (m *DocumentCRDTManager) SetForwardHandler(fn func(DocumentRequest)) {
+	m.forward = fn
+}
+
+// Forward sends a request to the node hosting its document. Requests that
+// have already been forwarded maxDocumentHops times are dropped.
+func (m *DocumentCRDTManager) Forward(node string, req DocumentRequest) {
+	req.Hops++
+	if req.Hops > maxDocumentHops {
+		log.Warn().
+			Str("document_id", req.DocumentID.String()).
+			Str("type", req.Type).
+			Msg("document request forwarded too often, dropping")
+		return
+	}
+	m.send(node, req)
+}
+
+func (m *DocumentCRDTManager) send(node string, req DocumentRequest) {
+	if node == m.nodeID {
+		go m.handleRequest(req)
+		return
+	}
+	if m.hub == nil {
+		return
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to marshal document request")
+		return
+	}
+	m.hub.SendToNode(node, data)
+}
+
+func (m *DocumentCRDTManager) handleNodeMessage(data []byte) {
+	var req DocumentRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		log.Warn().Err(err).Msg("invalid document request")
+		return
+	}
+	m.handleRequest(req)
+}
+
+func (m *DocumentCRDTManager) handleRequest(req DocumentRequest) {
+	switch req.Type {
+	case docRequestRelease:
+		go m.Remove(req.DocumentID)
+	case docRequestResync:
+		go m.resync(req)
+	default:
+		// Handled inline so a client's edits are applied in order
+		if m.forward != nil {
+			m.forward(req)
+		}
+	}
+}
+
+// claim takes the lease of a document this node does not host yet.
+func (m *DocumentCRDTManager) claim(ctx context.Context, docID uuid.UUID) error {
+	owner, err := m.lease.Acquire(ctx, docID, m.nodeID, crdtLeaseTTL)
+	if err != nil {
+		return fmt.Errorf("claim document: %w", err)
+	}
+	switch {
+	case owner == m.nodeID:
+		m.renewOnce.Do(func() { go m.renewLeases() })
+		return nil
+	case strings.HasPrefix(owner, writeHolderPrefix):
+		return ErrDocumentSuspended
+	default:
+		return &RemoteDocumentError{DocumentID: docID, Node: owner}
+	}
+}
+
+// unload saves a document's unsaved edits, removes it and releases its
+// lease. If the edits cannot be saved the document stays loaded.
+func (m *DocumentCRDTManager) unload(ctx context.Context, docID uuid.UUID) error {
+	m.mu.Lock()
+	crdt, ok := m.documents[docID]
+	delete(m.documents, docID)
+	delete(m.lastUsed, docID)
+	if timer, pending := m.flushTimers[docID]; pending {
+		timer.Stop()
+		delete(m.flushTimers, docID)
+	}
+	if !ok {
+		m.mu.Unlock()
+		return m.lease.Release(ctx, docID, m.nodeID)
+	}
+	done := make(chan struct{})
+	m.removing[docID] = done
+	m.mu.Unlock()
+
+	defer func() {
+		m.mu.Lock()
+		delete(m.removing, docID)
+		m.mu.Unlock()
+		close(done)
+	}()
+
+	if err := m.flush(ctx, crdt); err != nil {
+		m.mu.Lock()
+		if _, reloaded := m.documents[docID]; !reloaded {
+			m.documents[docID] = crdt
+			m.lastUsed[docID] = time.Now()
+		}
+		m.mu.Unlock()
+		m.Changed(docID)
+		return err
+	}
+	return m.lease.Release(ctx, docID, m.nodeID)
+}
+
+// drop forgets a document whose lease this node has lost, with any edits
+// it could not save in time.
+func (m *DocumentCRDTManager) drop(crdt *DocumentCRDT) {
+	m.mu.Lock()
+	if m.documents[crdt.DocumentID] == crdt {
+		delete(m.documents, crdt.DocumentID)
+		delete(m.lastUsed, crdt.DocumentID)
+		if timer, pending := m.flushTimers[crdt.DocumentID]; pending {
+			timer.Stop()
+			delete(m.flushTimers, crdt.DocumentID)
+		}
+	}
+	m.mu.Unlock()
+
+	log.Warn().
+		Str("document_id", crdt.DocumentID.String()).
+		Msg("lost document lease, discarding unsaved edits")
+}
+
+// renewLeases keeps the leases of hosted documents and unloads idle ones.
+func (m *DocumentCRDTManager) renewLeases() {
+	ticker := time.NewTicker(crdtLeaseTTL / 3)
+	defer ticker.Stop()
+	for range ticker.C {
+		m.renewAll()
+	}
+}
+
+func (m *DocumentCRDTManager) renewAll() {
+	now := time.Now()
+	var idle []uuid.UUID
+	var held []*DocumentCRDT
+	m.mu.RLock()
+	for docID, crdt := range m.documents {
+		if now.Sub(m.lastUsed[docID]) > crdtIdleTimeout {
+			idle = append(idle, docID)
+		} else {
+			held = append(held, crdt)
+		}
+	}
+	m.mu.RUnlock()
+
+	for _, docID := range idle {
+		m.Remove(docID)
+	}
+	for _, crdt := range held {
+		ctx, cancel := context.WithTimeout(context.Background(), crdtStoreTimeout)
+		owner, err := m.lease.Acquire(ctx, crdt.DocumentID, m.nodeID, crdtLeaseTTL)
+		cancel()
+		if err != nil {
+			log.Warn().Err(err).Str("document_id", crdt.DocumentID.String()).Msg("failed to renew document lease")
+			continue
+		}
+		if owner != m.nodeID {
+			m.drop(crdt)
+		}
+	}
+}
+
+// ReleaseAll saves and unloads every hosted document, e.g. on shutdown, so
+// other nodes can take them over right away.
+func (m *DocumentCRDTManager) ReleaseAll(ctx context.Context) error {
+	m.mu.RLock()
+	docIDs := make([]uuid.UUID, 0, len(m.documents))
+	for docID := range m.documents {
+		docIDs = append(docIDs, docID)
+	}
+	m.mu.RUnlock()
+
+	var errs []error
+	for _, docID := range docIDs {
+		if err := m.unload(ctx, docID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Suspend takes a document over for a write made outside realtime editing,
+// such as a REST block change or a version restore. The node hosting the
+// document saves and unloads it first, and until resume is called no node
+// loads it. resume reloads a document that was open from the store and
+// sends its clients a fresh doc_sync.
+func (m *DocumentCRDTManager) Suspend(ctx context.Context, docID uuid.UUID) (resume func(), err error) {
+	holder := writeHolderPrefix + uuid.NewString()
+	ctx, cancel := context.WithTimeout(ctx, crdtSuspendTimeout)
+	defer cancel()
+
+	live := false
+	var released time.Time
+	for {
+		owner, err := m.lease.Acquire(ctx, docID, holder, crdtLeaseTTL)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ErrDocumentBusy
+			}
+			return nil, fmt.Errorf("suspend document: %w", err)
+		}
+		if owner == holder {
+			break
+		}
+
+		if !strings.HasPrefix(owner, writeHolderPrefix) {
+			live = true
+			if owner == m.nodeID {
+				if err := m.unload(ctx, docID); err != nil {
+					return nil, fmt.Errorf("suspend document: %w", err)
+				}
+				continue
+			}
+			if time.Since(released) >= crdtReleaseRetry {
+				m.send(owner, DocumentRequest{Type: docRequestRelease, DocumentID: docID})
+				released = time.Now()
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ErrDocumentBusy
+		case <-time.After(crdtSuspendPoll):
+		}
+	}
+
+	stop := make(chan struct{})
+	go m.renewWrite(docID, holder, stop)
+
+	return func() {
+		close(stop)
+		ctx, cancel := context.WithTimeout(context.Background(), crdtStoreTimeout)
+		defer cancel()
+		if err := m.lease.Release(ctx, docID, holder); err != nil {
+			log.Warn().Err(err).Str("document_id", docID.String()).Msg("failed to release document after write")
+		}
+		if live {
+			m.resync(DocumentRequest{Type: docRequestResync, DocumentID: docID})
+		}
+	}, nil
+}
+
+// renewWrite keeps the lease of a write that takes longer than
+// crdtLeaseTTL until stop is closed.
+func (m *DocumentCRDTManager) renewWrite(docID uuid.UUID, holder string, stop <-chan struct{}) {
+	ticker := time.NewTicker(crdtLeaseTTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), crdtStoreTimeout)
+			owner, err := m.lease.Acquire(ctx, docID, holder, crdtLeaseTTL)
+			cancel()
+			if err != nil {
+				log.Warn().Err(err).Str("document_id", docID.String()).Msg("failed to renew document lease during write")
+			} else if owner != holder {
+				log.Warn().Str("document_id", docID.String()).Msg("lost document lease during write")
+				return
+			}
+		}
+	}
+}
+
+// resync loads a document and sends its whole state to its room, or asks
+// the node that hosts it by now to do so.
+func (m *DocumentCRDTManager) resync(req DocumentRequest) {
+	ctx, cancel := context.WithTimeout(context.Background(), crdtStoreTimeout)
+	defer cancel()
+
+	crdt, err := m.Load(ctx, req.DocumentID)
+	var remote *RemoteDocumentError
+	switch {
+	case errors.As(err, &remote):
+		m.Forward(remote.Node, req)
+		return
+	case errors.Is(err, ErrDocumentSuspended):
+		// The write holding it resyncs when it is done
+		return
+	case err != nil:
+		log.Warn().Err(err).Str("document_id", req.DocumentID.String()).Msg("failed to resync document")
+		return
+	}
+	if m.hub == nil {
+		return
+	}
+
+	payload, _ := json.Marshal(crdt.Sync(nil))
+	data, _ := json.Marshal(WSMessage{Type: WSTypeDocSync, Payload: payload})
+	m.hub.SendToRoom("doc:"+req.DocumentID.String(), data, uuid.Nil)
+}
@@ -0,0 +1,194 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentCRDTManager_Ownership(t *testing.T) {
+	nodeA := uuid.New()
+	blockID := uuid.New()
+	seeds := []BlockSeed{{BlockID: blockID, Type: "paragraph", Content: "Halo"}}
+	edit := func(crdt *DocumentCRDT, value string) {
+		crdt.ApplyUpdate(CRDTUpdateEvent{DocumentID: crdt.DocumentID, BlockID: blockID, Field: "content", Value: value, Timestamp: crdt.Tick(), NodeID: nodeA})
+	}
+	expectSync := func(t *testing.T, client *Client) DocumentSync {
+		t.Helper()
+		select {
+		case data := <-client.Send:
+			var msg WSMessage
+			require.NoError(t, json.Unmarshal(data, &msg))
+			require.Equal(t, WSTypeDocSync, msg.Type)
+			var sync DocumentSync
+			require.NoError(t, json.Unmarshal(msg.Payload, &sync))
+			return sync
+		case <-time.After(time.Second):
+			t.Fatal("expected a doc_sync")
+			return DocumentSync{}
+		}
+	}
+
+	t.Run("only one node hosts a document", func(t *testing.T) {
+		lease := NewMemoryDocumentLease()
+		a, b := NewDocumentCRDTManager(), NewDocumentCRDTManager()
+		a.lease, b.lease = lease, lease
+		docID := uuid.New()
+
+		_, err := a.Load(context.Background(), docID)
+		require.NoError(t, err)
+
+		_, err = b.Load(context.Background(), docID)
+		var remote *RemoteDocumentError
+		require.ErrorAs(t, err, &remote)
+		assert.Equal(t, a.nodeID, remote.Node)
+		assert.False(t, b.Has(docID))
+
+		a.Remove(docID)
+		_, err = b.Load(context.Background(), docID)
+		require.NoError(t, err)
+	})
+
+	t.Run("suspend saves the document and resume resyncs it", func(t *testing.T) {
+		hub := NewHub()
+		go hub.Run()
+		t.Cleanup(hub.Shutdown)
+		client := &Client{UserID: uuid.New(), DeviceID: "d1", Send: make(chan []byte, 16), Hub: hub}
+		hub.RegisterClient(client)
+
+		store := &fakeCRDTStore{seeds: seeds}
+		manager := NewDocumentCRDTManager()
+		manager.SetStore(store, time.Hour)
+		manager.SetCluster(hub, nil)
+		docID := uuid.New()
+		hub.JoinRoom(client, "doc:"+docID.String())
+
+		crdt, err := manager.Load(context.Background(), docID)
+		require.NoError(t, err)
+		edit(crdt, "Halo realtime")
+		manager.Changed(docID)
+
+		resume, err := manager.Suspend(context.Background(), docID)
+		require.NoError(t, err)
+		require.Len(t, store.savedBatches(), 1)
+		assert.Equal(t, "Halo realtime", *store.savedBatches()[0][0].Content)
+		assert.False(t, manager.Has(docID))
+
+		_, err = manager.Load(context.Background(), docID)
+		assert.ErrorIs(t, err, ErrDocumentSuspended)
+
+		// A REST write replaces the block while the document is suspended
+		store.mu.Lock()
+		store.seeds = []BlockSeed{{BlockID: blockID, Type: "paragraph", Content: "Halo REST"}}
+		store.mu.Unlock()
+		resume()
+
+		sync := expectSync(t, client)
+		assert.NotEqual(t, crdt.Epoch, sync.Epoch)
+		require.Len(t, sync.Blocks, 1)
+		assert.Equal(t, "Halo REST", sync.Blocks[0].Content.Value)
+		assert.True(t, manager.Has(docID))
+	})
+
+	t.Run("suspend of a document no one has open", func(t *testing.T) {
+		store := &fakeCRDTStore{seeds: seeds}
+		manager := NewDocumentCRDTManager()
+		manager.SetStore(store, time.Hour)
+		docID := uuid.New()
+
+		resume, err := manager.Suspend(context.Background(), docID)
+		require.NoError(t, err)
+		resume()
+		assert.False(t, manager.Has(docID))
+		assert.Zero(t, store.loads)
+	})
+
+	t.Run("suspend takes the document from another node", func(t *testing.T) {
+		mr := miniredis.RunT(t)
+		startNode := func(nodeID string, store CRDTStore) *DocumentCRDTManager {
+			client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+			t.Cleanup(func() { _ = client.Close() })
+			hub := NewHub()
+			require.NoError(t, hub.SetCluster(NewRedisCluster(client, nodeID, 0)))
+			go hub.Run()
+			t.Cleanup(hub.Shutdown)
+
+			manager := NewDocumentCRDTManager()
+			manager.SetStore(store, time.Hour)
+			manager.SetCluster(hub, NewRedisDocumentLease(client))
+			return manager
+		}
+		store := &fakeCRDTStore{seeds: seeds}
+		a := startNode("node-a", store)
+		b := startNode("node-b", store)
+		time.Sleep(20 * time.Millisecond)
+		docID := uuid.New()
+
+		crdt, err := a.Load(context.Background(), docID)
+		require.NoError(t, err)
+		edit(crdt, "Halo dari A")
+		a.Changed(docID)
+
+		resume, err := b.Suspend(context.Background(), docID)
+		require.NoError(t, err)
+		require.Len(t, store.savedBatches(), 1)
+		assert.Equal(t, "Halo dari A", *store.savedBatches()[0][0].Content)
+		assert.False(t, a.Has(docID))
+
+		// The resync reloads the document on the writing node
+		resume()
+		assert.True(t, b.Has(docID))
+		_, err = a.Load(context.Background(), docID)
+		var remote *RemoteDocumentError
+		require.ErrorAs(t, err, &remote)
+		assert.Equal(t, "node-b", remote.Node)
+	})
+
+	t.Run("a node that lost the lease does not save", func(t *testing.T) {
+		store := &fakeCRDTStore{seeds: seeds}
+		manager := NewDocumentCRDTManager()
+		manager.SetStore(store, time.Hour)
+		docID := uuid.New()
+		crdt, err := manager.Load(context.Background(), docID)
+		require.NoError(t, err)
+		edit(crdt, "Terlambat")
+
+		// The lease expired and another node took the document
+		ctx := context.Background()
+		require.NoError(t, manager.lease.Release(ctx, docID, manager.nodeID))
+		_, err = manager.lease.Acquire(ctx, docID, "node-x", time.Minute)
+		require.NoError(t, err)
+
+		require.NoError(t, manager.Flush(ctx, docID))
+		assert.Empty(t, store.savedBatches())
+		assert.False(t, manager.Has(docID))
+	})
+
+	t.Run("release keeps a document whose edits cannot be saved", func(t *testing.T) {
+		store := &fakeCRDTStore{seeds: seeds, saveErr: errors.New("db down")}
+		manager := NewDocumentCRDTManager()
+		manager.SetStore(store, time.Hour)
+		docID := uuid.New()
+		crdt, err := manager.Load(context.Background(), docID)
+		require.NoError(t, err)
+		edit(crdt, "Belum tersimpan")
+
+		require.Error(t, manager.ReleaseAll(context.Background()))
+		assert.True(t, manager.Has(docID))
+
+		store.mu.Lock()
+		store.saveErr = nil
+		store.mu.Unlock()
+		require.NoError(t, manager.ReleaseAll(context.Background()))
+		assert.False(t, manager.Has(docID))
+		require.Len(t, store.savedBatches(), 1)
+	})
+}
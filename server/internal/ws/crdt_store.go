@@ -0,0 +1,428 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// defaultCRDTFlushDelay is how long accepted edits wait before they are
+	// written back, so a burst of typing goes out as one batch.
+	defaultCRDTFlushDelay = 2 * time.Second
+	// crdtStoreTimeout bounds a load or save made outside a request.
+	crdtStoreTimeout = 10 * time.Second
+	// crdtSeedTimestamp stamps registers loaded from the store, so any live
+	// edit wins over the persisted state.
+	crdtSeedTimestamp = 1
+)
+
+// BlockSeed is a block as persisted, passed to Seed in document order.
+// Checked is "true", "false" or "" when unset.
+type BlockSeed struct {
+	BlockID  uuid.UUID
+	ParentID *uuid.UUID
+	Type     string
+	Content  string
+	Checked  string
+}
+
+// BlockChange is a block's state to write back to the store. Placed blocks
+// have a position (their index in Order) and parent and may need to be
+// created; the others only get their content and checked state updated.
+// Content and Checked are nil when no one has set them. EditedBy is the
+// user behind the block's latest change (see RecordEditor), or uuid.Nil
+// for blocks only included to renumber positions.
+type BlockChange struct {
+	BlockID  uuid.UUID
+	Deleted  bool
+	Placed   bool
+	ParentID *uuid.UUID
+	Type     string
+	Position int
+	Content  *string
+	Checked  *string
+	EditedBy uuid.UUID
+}
+
+// CRDTStore loads and saves the blocks behind document CRDTs.
+type CRDTStore interface {
+	// LoadBlocks returns a document's persisted blocks in document order.
+	LoadBlocks(ctx context.Context, docID uuid.UUID) ([]BlockSeed, error)
+	// SaveBlocks writes a batch of changes, parents before children.
+	SaveBlocks(ctx context.Context, docID uuid.UUID, changes []BlockChange) error
+}
+
+// DocumentSync is the state a client needs to catch up on a document.
+// Edits that depend on the seed must carry its Epoch.
+type DocumentSync struct {
+	DocumentID  uuid.UUID           `json:"documentId"`
+	Epoch       string              `json:"epoch"`
+	Clock       int64               `json:"clock"`
+	StateVector map[uuid.UUID]int64 `json:"stateVector"`
+	Blocks      []BlockCRDT         `json:"blocks"`
+	Order       []OrderedBlock      `json:"order"`
+}
+
+// Seed loads persisted blocks into the CRDT. Blocks get order keys in the
+// order given, and registers no one has written yet take the persisted
+// values at crdtSeedTimestamp. Seeded blocks are not marked as changed.
+func (d *DocumentCRDT) Seed(seeds []BlockSeed) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := ""
+	for _, seed := range seeds {
+		// Appending to a valid key cannot fail
+		key, _ = KeyBetween(key, "")
+
+		block := d.block(seed.BlockID)
+		if block.Type == "" {
+			block.Type = seed.Type
+		}
+		seedRegister(&block.Parent, parentValue(seed.ParentID))
+		seedRegister(&block.Order, key)
+		seedRegister(&block.Content, seed.Content)
+		if seed.Checked != "" {
+			seedRegister(&block.Checked, seed.Checked)
+		}
+	}
+}
+
+// Sync returns the document's clock and state vector, its order, and copies
+// of the blocks holding a write newer than since, a client's state vector.
+// A nil since returns every block.
+func (d *DocumentCRDT) Sync(since map[uuid.UUID]int64) DocumentSync {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	state := DocumentSync{
+		DocumentID:  d.DocumentID,
+		Epoch:       d.Epoch,
+		Clock:       d.Clock,
+		StateVector: make(map[uuid.UUID]int64),
+		Blocks:      []BlockCRDT{},
+		Order:       d.orderLocked(),
+	}
+
+	ids := make([]uuid.UUID, 0, len(d.Blocks))
+	for id := range d.Blocks {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+
+	for _, id := range ids {
+		block := d.Blocks[id]
+		newer := since == nil
+		block.eachWrite(func(ts int64, nodeID uuid.UUID) {
+			if ts > state.StateVector[nodeID] {
+				state.StateVector[nodeID] = ts
+			}
+			if seen, ok := since[nodeID]; !ok || ts > seen {
+				newer = true
+			}
+		})
+		if newer {
+			state.Blocks = append(state.Blocks, block.clone())
+		}
+	}
+	return state
+}
+
+// TakeChanges returns the blocks changed since the last call and clears
+// them: placed blocks first in document order, then the rest. Once a block
+// has been inserted or moved, every placed block is included so positions
+// can be renumbered.
+func (d *DocumentCRDT) TakeChanges() []BlockChange {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.dirty) == 0 && !d.reordered {
+		return nil
+	}
+
+	var changes []BlockChange
+	placed := make(map[uuid.UUID]bool)
+	for i, ordered := range d.orderLocked() {
+		placed[ordered.BlockID] = true
+		if !d.reordered && !d.dirty[ordered.BlockID] {
+			continue
+		}
+		block := d.Blocks[ordered.BlockID]
+		changes = append(changes, BlockChange{
+			BlockID:  ordered.BlockID,
+			Placed:   true,
+			ParentID: ordered.ParentID,
+			Type:     block.Type,
+			Position: i,
+			Content:  registerValue(block.Content),
+			Checked:  registerValue(block.Checked),
+			EditedBy: d.editors[ordered.BlockID],
+		})
+	}
+
+	rest := make([]uuid.UUID, 0, len(d.dirty))
+	for id := range d.dirty {
+		if !placed[id] {
+			rest = append(rest, id)
+		}
+	}
+	sort.Slice(rest, func(i, j int) bool { return rest[i].String() < rest[j].String() })
+	for _, id := range rest {
+		block := d.Blocks[id]
+		if block.Deleted {
+			changes = append(changes, BlockChange{BlockID: id, Deleted: true, EditedBy: d.editors[id]})
+			continue
+		}
+		changes = append(changes, BlockChange{
+			BlockID:  id,
+			Content:  registerValue(block.Content),
+			Checked:  registerValue(block.Checked),
+			EditedBy: d.editors[id],
+		})
+	}
+
+	d.dirty = make(map[uuid.UUID]bool)
+	d.editors = make(map[uuid.UUID]uuid.UUID)
+	d.reordered = false
+	return changes
+}
+
+// RecordEditor notes the user whose accepted edit last changed a block, to
+// be passed to the store with the block's next change.
+func (d *DocumentCRDT) RecordEditor(blockID, userID uuid.UUID) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.editors == nil {
+		d.editors = make(map[uuid.UUID]uuid.UUID)
+	}
+	d.editors[blockID] = userID
+}
+
+// requeue marks changes that failed to save as changed again, keeping
+// their editors unless someone has edited the block since.
+func (d *DocumentCRDT) requeue(changes []BlockChange) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, change := range changes {
+		d.markChanged(change.BlockID, change.Placed)
+		if _, edited := d.editors[change.BlockID]; !edited && change.EditedBy != uuid.Nil {
+			if d.editors == nil {
+				d.editors = make(map[uuid.UUID]uuid.UUID)
+			}
+			d.editors[change.BlockID] = change.EditedBy
+		}
+	}
+}
+
+// markChanged records a block for the next TakeChanges. Callers must hold
+// the lock.
+func (d *DocumentCRDT) markChanged(blockID uuid.UUID, reordered bool) {
+	if d.dirty == nil {
+		d.dirty = make(map[uuid.UUID]bool)
+	}
+	d.dirty[blockID] = true
+	if reordered {
+		d.reordered = true
+	}
+}
+
+// eachWrite calls fn with the timestamp and node of every write the block
+// holds.
+func (b *BlockCRDT) eachWrite(fn func(ts int64, nodeID uuid.UUID)) {
+	for _, reg := range []LWWRegister{b.Content, b.Checked, b.Parent, b.Order} {
+		if reg.Timestamp > 0 {
+			fn(reg.Timestamp, reg.NodeID)
+		}
+	}
+	if b.Deleted {
+		fn(b.DeletedAt, b.DeletedBy)
+	}
+	if b.Text != nil {
+		for _, c := range b.Text.Chars {
+			fn(c.ID.Timestamp, c.ID.NodeID)
+		}
+	}
+}
+
+// clone copies a block so it can be read without the document lock.
+func (b *BlockCRDT) clone() BlockCRDT {
+	copied := *b
+	if b.Text != nil {
+		copied.Text = &TextCRDT{Chars: append([]TextChar(nil), b.Text.Chars...)}
+	}
+	return copied
+}
+
+func seedRegister(reg *LWWRegister, value string) {
+	if reg.Timestamp == 0 {
+		*reg = LWWRegister{Value: value, Timestamp: crdtSeedTimestamp, NodeID: textSeedNode}
+	}
+}
+
+func registerValue(reg LWWRegister) *string {
+	if reg.Timestamp == 0 {
+		return nil
+	}
+	value := reg.Value
+	return &value
+}
+
+// SetStore makes the manager load documents from store and write accepted
+// edits back flushDelay after the first unsaved one (0 for the default).
+// Must be called before the manager is used.
+func (m *DocumentCRDTManager) SetStore(store CRDTStore, flushDelay time.Duration) {
+	if flushDelay <= 0 {
+		flushDelay = defaultCRDTFlushDelay
+	}
+	m.store = store
+	m.flushDelay = flushDelay
+}
+
+// Load returns the CRDT for a document, seeding it from the store the first
+// time. A failed load is retried on the next call. If the document is still
+// being saved after its last client left, Load waits for that first.
+//
+// Only one node hosts a document at a time. Load claims a document no node
+// hosts; it returns a *RemoteDocumentError if another node hosts it, and
+// ErrDocumentSuspended while a REST write holds it.
+func (m *DocumentCRDTManager) Load(ctx context.Context, docID uuid.UUID) (*DocumentCRDT, error) {
+	m.mu.RLock()
+	removing := m.removing[docID]
+	m.mu.RUnlock()
+	if removing != nil {
+		select {
+		case <-removing:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	m.mu.RLock()
+	_, hosted := m.documents[docID]
+	m.mu.RUnlock()
+	if !hosted {
+		if err := m.claim(ctx, docID); err != nil {
+			return nil, err
+		}
+	}
+
+	crdt := m.GetOrCreate(docID)
+	m.mu.Lock()
+	m.lastUsed[docID] = time.Now()
+	m.mu.Unlock()
+	if m.store == nil {
+		return crdt, nil
+	}
+
+	crdt.loadMu.Lock()
+	defer crdt.loadMu.Unlock()
+	if crdt.loaded {
+		return crdt, nil
+	}
+
+	seeds, err := m.store.LoadBlocks(ctx, docID)
+	if err != nil {
+		return nil, fmt.Errorf("load document blocks: %w", err)
+	}
+	crdt.Seed(seeds)
+	crdt.loaded = true
+	return crdt, nil
+}
+
+// Changed schedules a flush for a document with unsaved edits. Edits made
+// before the flush runs go out in the same batch.
+func (m *DocumentCRDTManager) Changed(docID uuid.UUID) {
+	if m.store == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, pending := m.flushTimers[docID]; pending {
+		return
+	}
+	m.flushTimers[docID] = time.AfterFunc(m.flushDelay, func() {
+		m.mu.Lock()
+		delete(m.flushTimers, docID)
+		m.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), crdtStoreTimeout)
+		defer cancel()
+		if err := m.Flush(ctx, docID); err != nil {
+			log.Warn().Err(err).Str("document_id", docID.String()).Msg("failed to save document edits, retrying")
+			m.Changed(docID)
+		}
+	})
+}
+
+// Flush writes a document's unsaved edits to the store. Edits that fail to
+// save are kept for the next flush.
+func (m *DocumentCRDTManager) Flush(ctx context.Context, docID uuid.UUID) error {
+	m.mu.RLock()
+	crdt, ok := m.documents[docID]
+	m.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return m.flush(ctx, crdt)
+}
+
+// FlushAll writes every document's unsaved edits, e.g. on shutdown.
+func (m *DocumentCRDTManager) FlushAll(ctx context.Context) error {
+	m.mu.Lock()
+	documents := make([]*DocumentCRDT, 0, len(m.documents))
+	for docID, crdt := range m.documents {
+		documents = append(documents, crdt)
+		if timer, pending := m.flushTimers[docID]; pending {
+			timer.Stop()
+			delete(m.flushTimers, docID)
+		}
+	}
+	m.mu.Unlock()
+
+	var errs []error
+	for _, crdt := range documents {
+		if err := m.flush(ctx, crdt); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *DocumentCRDTManager) flush(ctx context.Context, crdt *DocumentCRDT) error {
+	if m.store == nil {
+		return nil
+	}
+
+	crdt.flushMu.Lock()
+	defer crdt.flushMu.Unlock()
+
+	changes := crdt.TakeChanges()
+	if len(changes) == 0 {
+		return nil
+	}
+
+	// Only the lease holder saves, so a node that lost the document cannot
+	// overwrite what was saved after it
+	owner, err := m.lease.Acquire(ctx, crdt.DocumentID, m.nodeID, crdtLeaseTTL)
+	if err != nil {
+		crdt.requeue(changes)
+		return fmt.Errorf("renew document lease: %w", err)
+	}
+	if owner != m.nodeID {
+		m.drop(crdt)
+		return nil
+	}
+
+	if err := m.store.SaveBlocks(ctx, crdt.DocumentID, changes); err != nil {
+		crdt.requeue(changes)
+		return fmt.Errorf("save document blocks: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,325 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCRDTStore struct {
+	mu      sync.Mutex
+	seeds   []BlockSeed
+	loadErr error
+	saveErr error
+	loads   int
+	saved   [][]BlockChange
+}
+
+func (s *fakeCRDTStore) LoadBlocks(_ context.Context, _ uuid.UUID) ([]BlockSeed, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loads++
+	if s.loadErr != nil {
+		return nil, s.loadErr
+	}
+	return s.seeds, nil
+}
+
+func (s *fakeCRDTStore) SaveBlocks(_ context.Context, _ uuid.UUID, changes []BlockChange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.saveErr != nil {
+		return s.saveErr
+	}
+	s.saved = append(s.saved, changes)
+	return nil
+}
+
+func (s *fakeCRDTStore) savedBatches() [][]BlockChange {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]BlockChange(nil), s.saved...)
+}
+
+func TestDocumentCRDT_Seed(t *testing.T) {
+	docID := uuid.New()
+	nodeA := uuid.New()
+	first := uuid.MustParse("00000000-0000-0000-0000-000000000009")
+	second := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	child := uuid.New()
+
+	seeds := []BlockSeed{
+		{BlockID: first, Type: "heading1", Content: "Judul"},
+		{BlockID: child, ParentID: &first, Type: "checklist", Content: "Tugas", Checked: "true"},
+		{BlockID: second, Type: "paragraph", Content: "Isi"},
+	}
+
+	t.Run("keeps the persisted order and values", func(t *testing.T) {
+		crdt := NewDocumentCRDT(docID)
+		crdt.Seed(seeds)
+
+		order := crdt.Order()
+		require.Len(t, order, 3)
+		assert.Equal(t, []uuid.UUID{first, child, second}, orderedIDs(crdt))
+		assert.Equal(t, &first, order[1].ParentID)
+
+		state := crdt.GetBlockState(child)
+		assert.Equal(t, "checklist", state.Type)
+		assert.Equal(t, "Tugas", state.Content.Value)
+		assert.Equal(t, "true", state.Checked.Value)
+		assert.Equal(t, int64(crdtSeedTimestamp), state.Content.Timestamp)
+		assert.Equal(t, int64(0), crdt.GetBlockState(first).Checked.Timestamp)
+
+		assert.Nil(t, crdt.TakeChanges(), "seeded blocks are not unsaved edits")
+	})
+
+	t.Run("live edits win over the persisted state", func(t *testing.T) {
+		crdt := NewDocumentCRDT(docID)
+		require.True(t, crdt.ApplyUpdate(CRDTUpdateEvent{DocumentID: docID, BlockID: second, Field: "content", Value: "Baru", Timestamp: 100, NodeID: nodeA}))
+		crdt.Seed(seeds)
+		assert.Equal(t, "Baru", crdt.GetBlockState(second).Content.Value)
+
+		require.True(t, crdt.ApplyUpdate(CRDTUpdateEvent{DocumentID: docID, BlockID: first, Field: "content", Value: "Judul baru", Timestamp: 2, NodeID: nodeA}))
+	})
+}
+
+func TestDocumentCRDT_TakeChanges(t *testing.T) {
+	docID := uuid.New()
+	nodeA := uuid.New()
+	first := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	second := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	loose := uuid.MustParse("00000000-0000-0000-0000-000000000003")
+
+	newSeeded := func() *DocumentCRDT {
+		crdt := NewDocumentCRDT(docID)
+		crdt.Seed([]BlockSeed{
+			{BlockID: first, Type: "paragraph", Content: "Satu"},
+			{BlockID: second, Type: "paragraph", Content: "Dua"},
+		})
+		return crdt
+	}
+
+	t.Run("content edit only writes that block", func(t *testing.T) {
+		crdt := newSeeded()
+		crdt.ApplyUpdate(CRDTUpdateEvent{DocumentID: docID, BlockID: second, Field: "content", Value: "Dua!", Timestamp: 100, NodeID: nodeA})
+
+		changes := crdt.TakeChanges()
+		require.Len(t, changes, 1)
+		assert.Equal(t, second, changes[0].BlockID)
+		assert.True(t, changes[0].Placed)
+		assert.Equal(t, 1, changes[0].Position)
+		require.NotNil(t, changes[0].Content)
+		assert.Equal(t, "Dua!", *changes[0].Content)
+		assert.Nil(t, changes[0].Checked)
+
+		assert.Nil(t, crdt.TakeChanges())
+	})
+
+	t.Run("insert renumbers every placed block, parents first", func(t *testing.T) {
+		crdt := newSeeded()
+		child := uuid.New()
+		key := crdt.GetBlockState(first).Order.Value
+		crdt.ApplyInsert(CRDTInsertEvent{DocumentID: docID, BlockID: child, ParentID: &second, Key: key, Type: "quote", Content: "Kutipan", Timestamp: 100, NodeID: nodeA})
+
+		changes := crdt.TakeChanges()
+		require.Len(t, changes, 3)
+		assert.Equal(t, []uuid.UUID{first, second, child}, []uuid.UUID{changes[0].BlockID, changes[1].BlockID, changes[2].BlockID})
+		assert.Equal(t, 2, changes[2].Position)
+		assert.Equal(t, &second, changes[2].ParentID)
+		assert.Equal(t, "quote", changes[2].Type)
+	})
+
+	t.Run("deleted and unplaced blocks", func(t *testing.T) {
+		crdt := newSeeded()
+		crdt.ApplyDelete(CRDTDeleteEvent{DocumentID: docID, BlockID: first, Timestamp: 100, NodeID: nodeA})
+		crdt.ApplyUpdate(CRDTUpdateEvent{DocumentID: docID, BlockID: loose, Field: "checked", Value: "true", Timestamp: 100, NodeID: nodeA})
+
+		changes := crdt.TakeChanges()
+		require.Len(t, changes, 2)
+		assert.Equal(t, BlockChange{BlockID: first, Deleted: true}, changes[0])
+		assert.Equal(t, loose, changes[1].BlockID)
+		assert.False(t, changes[1].Placed)
+		assert.Nil(t, changes[1].Content)
+		require.NotNil(t, changes[1].Checked)
+		assert.Equal(t, "true", *changes[1].Checked)
+	})
+	t.Run("changes carry their editor", func(t *testing.T) {
+		crdt := newSeeded()
+		editor := uuid.New()
+		child := uuid.New()
+		crdt.ApplyInsert(CRDTInsertEvent{DocumentID: docID, BlockID: child, Key: crdt.GetBlockState(second).Order.Value + "V", Type: "paragraph", Timestamp: 100, NodeID: nodeA})
+		crdt.RecordEditor(child, editor)
+
+		changes := crdt.TakeChanges()
+		require.Len(t, changes, 3)
+		assert.Equal(t, uuid.Nil, changes[0].EditedBy, "renumbered blocks have no editor")
+		assert.Equal(t, editor, changes[2].EditedBy)
+
+		crdt.requeue(changes)
+		changes = crdt.TakeChanges()
+		require.Len(t, changes, 3)
+		assert.Equal(t, editor, changes[2].EditedBy, "failed saves keep their editor")
+	})
+}
+
+func TestDocumentCRDT_Sync(t *testing.T) {
+	docID := uuid.New()
+	nodeA := uuid.MustParse("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+	nodeB := uuid.MustParse("bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb")
+	first := uuid.New()
+	second := uuid.New()
+
+	crdt := NewDocumentCRDT(docID)
+	crdt.Seed([]BlockSeed{
+		{BlockID: first, Type: "paragraph", Content: "Halo"},
+		{BlockID: second, Type: "paragraph", Content: "Dunia"},
+	})
+	crdt.ApplyUpdate(CRDTUpdateEvent{DocumentID: docID, BlockID: first, Field: "checked", Value: "false", Timestamp: 100, NodeID: nodeA})
+	crdt.ApplyText(CRDTTextEvent{DocumentID: docID, BlockID: second, NodeID: nodeB, Ops: []TextOp{
+		{Type: "insert", ID: CharID{Timestamp: 200, NodeID: nodeB}, Origin: &CharID{Timestamp: 5, NodeID: textSeedNode}, Text: "!"},
+	}})
+
+	t.Run("full state", func(t *testing.T) {
+		state := crdt.Sync(nil)
+		assert.Equal(t, docID, state.DocumentID)
+		assert.Equal(t, crdt.Epoch, state.Epoch)
+		assert.Len(t, state.Blocks, 2)
+		assert.Len(t, state.Order, 2)
+		assert.Equal(t, int64(100), state.StateVector[nodeA])
+		assert.Equal(t, crdt.GetBlockState(second).Content.Timestamp, state.StateVector[nodeB])
+		assert.Equal(t, int64(5), state.StateVector[textSeedNode])
+	})
+
+	t.Run("only blocks newer than the client's state vector", func(t *testing.T) {
+		vector := crdt.Sync(nil).StateVector
+		assert.Empty(t, crdt.Sync(vector).Blocks)
+
+		vector[nodeB] = 0
+		state := crdt.Sync(vector)
+		require.Len(t, state.Blocks, 1)
+		assert.Equal(t, second, state.Blocks[0].BlockID)
+		require.NotNil(t, state.Blocks[0].Text)
+		assert.Equal(t, "Dunia!", state.Blocks[0].Text.String())
+	})
+
+	t.Run("blocks are copies", func(t *testing.T) {
+		state := crdt.Sync(nil)
+		for i := range state.Blocks {
+			if state.Blocks[i].Text != nil {
+				state.Blocks[i].Text.Chars[0].Value = "X"
+			}
+		}
+		assert.Equal(t, "Dunia!", crdt.GetBlockState(second).Content.Value)
+		assert.Equal(t, "Dunia!", crdt.GetBlockState(second).Text.String())
+	})
+}
+
+func TestDocumentCRDTManager_Store(t *testing.T) {
+	nodeA := uuid.New()
+	blockID := uuid.New()
+	seeds := []BlockSeed{{BlockID: blockID, Type: "paragraph", Content: "Halo"}}
+	edit := func(crdt *DocumentCRDT, value string) {
+		crdt.ApplyUpdate(CRDTUpdateEvent{DocumentID: crdt.DocumentID, BlockID: blockID, Field: "content", Value: value, Timestamp: crdt.Tick(), NodeID: nodeA})
+	}
+
+	t.Run("load seeds once and retries failures", func(t *testing.T) {
+		store := &fakeCRDTStore{seeds: seeds, loadErr: errors.New("db down")}
+		manager := NewDocumentCRDTManager()
+		manager.SetStore(store, time.Hour)
+		docID := uuid.New()
+
+		_, err := manager.Load(context.Background(), docID)
+		require.Error(t, err)
+
+		store.loadErr = nil
+		crdt, err := manager.Load(context.Background(), docID)
+		require.NoError(t, err)
+		assert.Equal(t, "Halo", crdt.GetBlockState(blockID).Content.Value)
+
+		_, err = manager.Load(context.Background(), docID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, store.loads)
+	})
+
+	t.Run("load without a store", func(t *testing.T) {
+		crdt, err := NewDocumentCRDTManager().Load(context.Background(), uuid.New())
+		require.NoError(t, err)
+		assert.Empty(t, crdt.Order())
+	})
+
+	t.Run("edits are flushed in one debounced batch", func(t *testing.T) {
+		store := &fakeCRDTStore{seeds: seeds}
+		manager := NewDocumentCRDTManager()
+		manager.SetStore(store, 20*time.Millisecond)
+		docID := uuid.New()
+		crdt, err := manager.Load(context.Background(), docID)
+		require.NoError(t, err)
+
+		edit(crdt, "Halo 1")
+		manager.Changed(docID)
+		edit(crdt, "Halo 2")
+		manager.Changed(docID)
+
+		require.Eventually(t, func() bool { return len(store.savedBatches()) == 1 }, time.Second, 5*time.Millisecond)
+		batch := store.savedBatches()[0]
+		require.Len(t, batch, 1)
+		assert.Equal(t, "Halo 2", *batch[0].Content)
+	})
+
+	t.Run("failed saves are kept for the next flush", func(t *testing.T) {
+		store := &fakeCRDTStore{seeds: seeds, saveErr: errors.New("db down")}
+		manager := NewDocumentCRDTManager()
+		manager.SetStore(store, time.Hour)
+		docID := uuid.New()
+		crdt, err := manager.Load(context.Background(), docID)
+		require.NoError(t, err)
+
+		edit(crdt, "Halo lagi")
+		require.Error(t, manager.Flush(context.Background(), docID))
+
+		store.saveErr = nil
+		require.NoError(t, manager.FlushAll(context.Background()))
+		require.Len(t, store.savedBatches(), 1)
+		assert.Equal(t, "Halo lagi", *store.savedBatches()[0][0].Content)
+	})
+
+	t.Run("remove saves unsaved edits", func(t *testing.T) {
+		store := &fakeCRDTStore{seeds: seeds}
+		manager := NewDocumentCRDTManager()
+		manager.SetStore(store, time.Hour)
+		docID := uuid.New()
+		crdt, err := manager.Load(context.Background(), docID)
+		require.NoError(t, err)
+
+		edit(crdt, "Sampai jumpa")
+		manager.Changed(docID)
+		manager.Remove(docID)
+
+		assert.False(t, manager.Has(docID))
+		require.Len(t, store.savedBatches(), 1)
+		assert.Equal(t, "Sampai jumpa", *store.savedBatches()[0][0].Content)
+		assert.NoError(t, manager.Flush(context.Background(), docID))
+	})
+
+	t.Run("reloading starts a new epoch", func(t *testing.T) {
+		store := &fakeCRDTStore{seeds: seeds}
+		manager := NewDocumentCRDTManager()
+		manager.SetStore(store, time.Hour)
+		docID := uuid.New()
+		first, err := manager.Load(context.Background(), docID)
+		require.NoError(t, err)
+		require.NotEmpty(t, first.Epoch)
+
+		manager.Remove(docID)
+		second, err := manager.Load(context.Background(), docID)
+		require.NoError(t, err)
+		assert.NotEqual(t, first.Epoch, second.Epoch)
+		assert.Equal(t, 2, store.loads)
+	})
+}
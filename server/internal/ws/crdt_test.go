@@ -208,6 +208,295 @@ func TestDocumentCRDT_Clock(t *testing.T) {
 	})
 }
 
+func orderedIDs(crdt *DocumentCRDT) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, block := range crdt.Order() {
+		ids = append(ids, block.BlockID)
+	}
+	return ids
+}
+
+func TestDocumentCRDT_Sequence(t *testing.T) {
+	docID := uuid.New()
+	nodeA := uuid.MustParse("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+	nodeB := uuid.MustParse("bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb")
+	first := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	second := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	third := uuid.MustParse("00000000-0000-0000-0000-000000000003")
+
+	insert := func(blockID uuid.UUID, parentID *uuid.UUID, key string, ts int64, node uuid.UUID) CRDTInsertEvent {
+		return CRDTInsertEvent{DocumentID: docID, BlockID: blockID, ParentID: parentID, Key: key, Type: "paragraph", Timestamp: ts, NodeID: node}
+	}
+
+	t.Run("insert orders blocks by key", func(t *testing.T) {
+		crdt := NewDocumentCRDT(docID)
+		assert.True(t, crdt.ApplyInsert(insert(second, nil, "b", 100, nodeA)))
+		assert.True(t, crdt.ApplyInsert(insert(first, nil, "a", 101, nodeA)))
+		assert.True(t, crdt.ApplyInsert(insert(third, nil, "c", 102, nodeA)))
+
+		assert.Equal(t, []uuid.UUID{first, second, third}, orderedIDs(crdt))
+		assert.Equal(t, "paragraph", crdt.GetBlockState(first).Type)
+	})
+
+	t.Run("insert seeds content", func(t *testing.T) {
+		crdt := NewDocumentCRDT(docID)
+		event := insert(first, nil, "V", 100, nodeA)
+		event.Content = "halo"
+		require.True(t, crdt.ApplyInsert(event))
+		assert.Equal(t, "halo", crdt.GetBlockState(first).Content.Value)
+	})
+
+	t.Run("concurrent inserts at the same key converge", func(t *testing.T) {
+		events := []CRDTInsertEvent{
+			insert(first, nil, "a", 100, nodeA),
+			insert(third, nil, "V", 101, nodeA),
+			insert(second, nil, "V", 101, nodeB),
+		}
+
+		forward := NewDocumentCRDT(docID)
+		for _, event := range events {
+			forward.ApplyInsert(event)
+		}
+		backward := NewDocumentCRDT(docID)
+		for i := len(events) - 1; i >= 0; i-- {
+			backward.ApplyInsert(events[i])
+		}
+
+		assert.Equal(t, []uuid.UUID{second, third, first}, orderedIDs(forward))
+		assert.Equal(t, orderedIDs(forward), orderedIDs(backward))
+	})
+
+	t.Run("replayed insert is idempotent", func(t *testing.T) {
+		crdt := NewDocumentCRDT(docID)
+		event := insert(first, nil, "V", 100, nodeA)
+		assert.True(t, crdt.ApplyInsert(event))
+		assert.False(t, crdt.ApplyInsert(event))
+		assert.Len(t, crdt.Order(), 1)
+	})
+
+	t.Run("invalid inserts are rejected", func(t *testing.T) {
+		crdt := NewDocumentCRDT(docID)
+		assert.False(t, crdt.ApplyInsert(insert(first, nil, "", 100, nodeA)))
+		assert.False(t, crdt.ApplyInsert(insert(first, nil, "a0", 100, nodeA)))
+		assert.False(t, crdt.ApplyInsert(insert(first, &first, "V", 100, nodeA)))
+
+		crdt.ApplyDelete(CRDTDeleteEvent{DocumentID: docID, BlockID: second, Timestamp: 100, NodeID: nodeA})
+		assert.False(t, crdt.ApplyInsert(insert(second, nil, "V", 200, nodeA)))
+		assert.Empty(t, crdt.Order())
+	})
+
+	t.Run("concurrent moves keep the latest", func(t *testing.T) {
+		moveA := CRDTMoveEvent{DocumentID: docID, BlockID: first, Key: "x", Timestamp: 200, NodeID: nodeA}
+		moveB := CRDTMoveEvent{DocumentID: docID, BlockID: first, Key: "m", Timestamp: 201, NodeID: nodeB}
+
+		for _, moves := range [][]CRDTMoveEvent{{moveA, moveB}, {moveB, moveA}} {
+			crdt := NewDocumentCRDT(docID)
+			crdt.ApplyInsert(insert(first, nil, "a", 100, nodeA))
+			crdt.ApplyInsert(insert(second, nil, "b", 100, nodeA))
+			crdt.ApplyInsert(insert(third, nil, "z", 100, nodeA))
+			for _, move := range moves {
+				crdt.ApplyMove(move)
+			}
+
+			assert.Equal(t, []uuid.UUID{second, first, third}, orderedIDs(crdt))
+			assert.Equal(t, "m", crdt.GetBlockState(first).Order.Value)
+		}
+	})
+
+	t.Run("move keeps the parent", func(t *testing.T) {
+		crdt := NewDocumentCRDT(docID)
+		crdt.ApplyInsert(insert(first, nil, "a", 100, nodeA))
+		crdt.ApplyInsert(insert(second, &first, "a", 100, nodeA))
+
+		require.True(t, crdt.ApplyMove(CRDTMoveEvent{DocumentID: docID, BlockID: second, Key: "b", Timestamp: 200, NodeID: nodeA}))
+		order := crdt.Order()
+		require.Len(t, order, 2)
+		assert.Equal(t, &first, order[1].ParentID)
+	})
+
+	t.Run("reparent nests and unnests a toggle child", func(t *testing.T) {
+		crdt := NewDocumentCRDT(docID)
+		crdt.ApplyInsert(insert(first, nil, "a", 100, nodeA))
+		crdt.ApplyInsert(insert(second, nil, "b", 100, nodeA))
+		crdt.ApplyInsert(insert(third, nil, "c", 100, nodeA))
+
+		require.True(t, crdt.ApplyReparent(CRDTMoveEvent{DocumentID: docID, BlockID: third, ParentID: &first, Key: "V", Timestamp: 200, NodeID: nodeA}))
+		order := crdt.Order()
+		require.Len(t, order, 3)
+		assert.Equal(t, []uuid.UUID{first, third, second}, orderedIDs(crdt))
+		assert.Equal(t, &first, order[1].ParentID)
+		assert.Equal(t, 1, order[1].Depth)
+		assert.Nil(t, order[2].ParentID)
+		assert.Equal(t, 0, order[2].Depth)
+
+		require.True(t, crdt.ApplyReparent(CRDTMoveEvent{DocumentID: docID, BlockID: third, Key: "d", Timestamp: 300, NodeID: nodeA}))
+		assert.Equal(t, []uuid.UUID{first, second, third}, orderedIDs(crdt))
+		assert.Nil(t, crdt.Order()[2].ParentID)
+	})
+
+	t.Run("concurrent move and reparent both apply", func(t *testing.T) {
+		reparent := CRDTMoveEvent{DocumentID: docID, BlockID: third, ParentID: &first, Key: "V", Timestamp: 200, NodeID: nodeA}
+		move := CRDTMoveEvent{DocumentID: docID, BlockID: third, Key: "b", Timestamp: 201, NodeID: nodeB}
+
+		for _, reparentFirst := range []bool{true, false} {
+			crdt := NewDocumentCRDT(docID)
+			crdt.ApplyInsert(insert(first, nil, "a", 100, nodeA))
+			crdt.ApplyInsert(insert(second, &first, "a", 100, nodeA))
+			crdt.ApplyInsert(insert(third, nil, "c", 100, nodeA))
+			if reparentFirst {
+				crdt.ApplyReparent(reparent)
+				crdt.ApplyMove(move)
+			} else {
+				crdt.ApplyMove(move)
+				crdt.ApplyReparent(reparent)
+			}
+
+			order := crdt.Order()
+			require.Len(t, order, 3)
+			assert.Equal(t, []uuid.UUID{first, second, third}, orderedIDs(crdt))
+			assert.Equal(t, &first, order[2].ParentID)
+		}
+	})
+
+	t.Run("deleting a parent hides its children", func(t *testing.T) {
+		crdt := NewDocumentCRDT(docID)
+		crdt.ApplyInsert(insert(first, nil, "a", 100, nodeA))
+		crdt.ApplyInsert(insert(second, &first, "a", 100, nodeA))
+		crdt.ApplyInsert(insert(third, nil, "b", 100, nodeA))
+
+		crdt.ApplyDelete(CRDTDeleteEvent{DocumentID: docID, BlockID: first, Timestamp: 200, NodeID: nodeA})
+		assert.Equal(t, []uuid.UUID{third}, orderedIDs(crdt))
+	})
+
+	t.Run("unknown parent falls back to the top level", func(t *testing.T) {
+		crdt := NewDocumentCRDT(docID)
+		missing := uuid.New()
+		crdt.ApplyInsert(insert(first, &missing, "a", 100, nodeA))
+
+		order := crdt.Order()
+		require.Len(t, order, 1)
+		assert.Nil(t, order[0].ParentID)
+	})
+
+	t.Run("reparent cycle is broken deterministically", func(t *testing.T) {
+		toB := CRDTMoveEvent{DocumentID: docID, BlockID: first, ParentID: &second, Key: "V", Timestamp: 200, NodeID: nodeA}
+		toA := CRDTMoveEvent{DocumentID: docID, BlockID: second, ParentID: &first, Key: "V", Timestamp: 201, NodeID: nodeB}
+
+		for _, events := range [][]CRDTMoveEvent{{toB, toA}, {toA, toB}} {
+			crdt := NewDocumentCRDT(docID)
+			crdt.ApplyInsert(insert(first, nil, "a", 100, nodeA))
+			crdt.ApplyInsert(insert(second, nil, "b", 100, nodeA))
+			for _, event := range events {
+				crdt.ApplyReparent(event)
+			}
+
+			// second was reparented last, so it is detached to the top level
+			order := crdt.Order()
+			require.Len(t, order, 2)
+			assert.Equal(t, []uuid.UUID{second, first}, orderedIDs(crdt))
+			assert.Nil(t, order[0].ParentID)
+			assert.Equal(t, &second, order[1].ParentID)
+		}
+	})
+}
+
+func TestDocumentCRDT_Text(t *testing.T) {
+	docID := uuid.New()
+	blockID := uuid.New()
+	nodeA := uuid.MustParse("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+	nodeB := uuid.MustParse("bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb")
+	endOfHalo := &CharID{Timestamp: 4, NodeID: textSeedNode}
+
+	textEvent := func(node uuid.UUID, ops ...TextOp) CRDTTextEvent {
+		return CRDTTextEvent{DocumentID: docID, BlockID: blockID, Ops: ops, NodeID: node}
+	}
+
+	t.Run("seeds from the server content and mirrors it", func(t *testing.T) {
+		crdt := NewDocumentCRDT(docID)
+		crdt.Clock = 0
+		crdt.ApplyUpdate(CRDTUpdateEvent{DocumentID: docID, BlockID: blockID, Field: "content", Value: "Halo", Timestamp: 50, NodeID: nodeA})
+
+		event := textEvent(nodeB, TextOp{Type: "insert", ID: CharID{Timestamp: 100, NodeID: nodeB}, Origin: endOfHalo, Text: "!!"})
+		event.Base = "ignored"
+		applied := crdt.ApplyText(event)
+		require.Len(t, applied, 1)
+
+		state := crdt.GetBlockState(blockID)
+		assert.Equal(t, "Halo!!", state.Content.Value)
+		assert.Equal(t, nodeB, state.Content.NodeID)
+		// The content register takes a fresh tick past the typed run
+		assert.Equal(t, int64(102), crdt.Clock)
+		assert.Equal(t, int64(102), state.Content.Timestamp)
+
+		// Whole-content updates no longer overwrite the text
+		assert.False(t, crdt.ApplyUpdate(CRDTUpdateEvent{DocumentID: docID, BlockID: blockID, Field: "content", Value: "x", Timestamp: 500, NodeID: nodeA}))
+	})
+
+	t.Run("seeds from the base when the server has no content", func(t *testing.T) {
+		crdt := NewDocumentCRDT(docID)
+		event := textEvent(nodeA, TextOp{Type: "insert", ID: CharID{Timestamp: crdt.Clock + 1, NodeID: nodeA}, Origin: endOfHalo, Text: "?"})
+		event.Base = "Halo"
+		require.Len(t, crdt.ApplyText(event), 1)
+		assert.Equal(t, "Halo?", crdt.GetBlockState(blockID).Content.Value)
+	})
+
+	t.Run("only new ops are returned", func(t *testing.T) {
+		crdt := NewDocumentCRDT(docID)
+		op := TextOp{Type: "insert", ID: CharID{Timestamp: 100, NodeID: nodeA}, Text: "a"}
+		require.Len(t, crdt.ApplyText(textEvent(nodeA, op)), 1)
+
+		applied := crdt.ApplyText(textEvent(nodeA, op, TextOp{Type: "insert", ID: CharID{Timestamp: 101, NodeID: nodeA}, Origin: &op.ID, Text: "b"}))
+		require.Len(t, applied, 1)
+		assert.Equal(t, "b", applied[0].Text)
+		assert.Equal(t, "ab", crdt.GetBlockState(blockID).Content.Value)
+	})
+
+	t.Run("rejects seed node inserts, non-text and deleted blocks", func(t *testing.T) {
+		crdt := NewDocumentCRDT(docID)
+		assert.Empty(t, crdt.ApplyText(textEvent(nodeA, TextOp{Type: "insert", ID: CharID{Timestamp: 100, NodeID: textSeedNode}, Text: "a"})))
+
+		table := uuid.New()
+		crdt.ApplyInsert(CRDTInsertEvent{DocumentID: docID, BlockID: table, Key: "V", Type: "table", Timestamp: 100, NodeID: nodeA})
+		assert.Empty(t, crdt.ApplyText(CRDTTextEvent{DocumentID: docID, BlockID: table, NodeID: nodeA, Ops: []TextOp{{Type: "insert", ID: CharID{Timestamp: 101, NodeID: nodeA}, Text: "a"}}}))
+
+		crdt.ApplyDelete(CRDTDeleteEvent{DocumentID: docID, BlockID: blockID, Timestamp: 100, NodeID: nodeA})
+		assert.Empty(t, crdt.ApplyText(textEvent(nodeA, TextOp{Type: "insert", ID: CharID{Timestamp: 101, NodeID: nodeA}, Text: "a"})))
+	})
+
+	t.Run("whole-content update becomes text ops", func(t *testing.T) {
+		crdt := NewDocumentCRDT(docID)
+		crdt.ApplyInsert(CRDTInsertEvent{DocumentID: docID, BlockID: blockID, Key: "V", Type: "paragraph", Content: "Halo dunia", Timestamp: 100, NodeID: nodeA})
+
+		_, ok := crdt.ReplaceText(CRDTUpdateEvent{DocumentID: docID, BlockID: blockID, Field: "content", Value: "Hai", Timestamp: 200, NodeID: nodeA})
+		assert.False(t, ok, "blocks without text go through ApplyUpdate")
+
+		crdt.ApplyText(textEvent(nodeB, TextOp{Type: "insert", ID: CharID{Timestamp: crdt.Clock + 1, NodeID: nodeB}, Origin: &CharID{Timestamp: 10, NodeID: textSeedNode}, Text: "!"}))
+		replica := NewTextCRDT("Halo dunia")
+		typed := crdt.GetBlockState(blockID).Text.Chars[10]
+		replica.Apply(TextOp{Type: "insert", ID: typed.ID, Origin: typed.Origin, Text: typed.Value})
+
+		ts := crdt.Tick()
+		ops, ok := crdt.ReplaceText(CRDTUpdateEvent{DocumentID: docID, BlockID: blockID, Field: "content", Value: "Hai dunia!", Timestamp: ts, NodeID: nodeA})
+		require.True(t, ok)
+		require.Len(t, ops, 2)
+		state := crdt.GetBlockState(blockID)
+		assert.Equal(t, "Hai dunia!", state.Content.Value)
+		assert.Equal(t, "!", state.Text.Chars[len(state.Text.Chars)-1].Value)
+		assert.Equal(t, nodeB, state.Text.Chars[len(state.Text.Chars)-1].ID.NodeID, "unchanged characters keep their IDs")
+
+		stale, ok := crdt.ReplaceText(CRDTUpdateEvent{DocumentID: docID, BlockID: blockID, Field: "content", Value: "Lama", Timestamp: 150, NodeID: nodeA})
+		assert.True(t, ok)
+		assert.Empty(t, stale)
+		assert.Equal(t, "Hai dunia!", crdt.GetBlockState(blockID).Content.Value)
+
+		// A replica applying the broadcast ops reads the same text
+		for _, op := range ops {
+			replica.Apply(op)
+		}
+		assert.Equal(t, "Hai dunia!", replica.String())
+	})
+}
+
 func TestDocumentCRDTManager(t *testing.T) {
 	manager := NewDocumentCRDTManager()
 	docID := uuid.New()
@@ -0,0 +1,123 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const documentLeaseKeyPrefix = "ws:doc-lease:"
+
+// DocumentLease records who a document's blocks belong to for a while: the
+// node hosting the document's CRDT, or a REST write that has taken the
+// document over (see DocumentCRDTManager.Suspend). Holders renew the lease
+// by acquiring it again before it expires.
+type DocumentLease interface {
+	// Acquire gives the document to holder for ttl unless someone else holds
+	// it, and returns the holder it now belongs to. Acquiring a lease the
+	// holder already has extends it.
+	Acquire(ctx context.Context, docID uuid.UUID, holder string, ttl time.Duration) (string, error)
+	// Release gives up the lease if holder still has it.
+	Release(ctx context.Context, docID uuid.UUID, holder string) error
+}
+
+// --- In-memory implementation ---
+
+type documentLeaseEntry struct {
+	holder    string
+	expiresAt time.Time
+}
+
+// MemoryDocumentLease is a node-local DocumentLease, for a single replica.
+type MemoryDocumentLease struct {
+	leases map[uuid.UUID]documentLeaseEntry
+	mu     sync.Mutex
+}
+
+// NewMemoryDocumentLease creates an in-memory document lease.
+func NewMemoryDocumentLease() *MemoryDocumentLease {
+	return &MemoryDocumentLease{leases: make(map[uuid.UUID]documentLeaseEntry)}
+}
+
+// Acquire gives the document to holder unless someone else holds it.
+func (l *MemoryDocumentLease) Acquire(_ context.Context, docID uuid.UUID, holder string, ttl time.Duration) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if entry, ok := l.leases[docID]; ok && entry.holder != holder && now.Before(entry.expiresAt) {
+		return entry.holder, nil
+	}
+	l.leases[docID] = documentLeaseEntry{holder: holder, expiresAt: now.Add(ttl)}
+	return holder, nil
+}
+
+// Release gives up the lease if holder still has it.
+func (l *MemoryDocumentLease) Release(_ context.Context, docID uuid.UUID, holder string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if entry, ok := l.leases[docID]; ok && entry.holder == holder {
+		delete(l.leases, docID)
+	}
+	return nil
+}
+
+// --- Redis implementation ---
+
+// acquireLeaseScript sets KEYS[1] to the holder ARGV[1] for ARGV[2]
+// milliseconds unless another holder has it, and returns the holder.
+var acquireLeaseScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if current and current ~= ARGV[1] then
+	return current
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+return ARGV[1]
+`)
+
+// releaseLeaseScript deletes KEYS[1] if it still belongs to ARGV[1].
+var releaseLeaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// RedisDocumentLease is a DocumentLease shared by every replica. Each lease
+// is a key that expires on its own, so one left behind by a crashed node
+// frees the document after its ttl.
+type RedisDocumentLease struct {
+	redis *redis.Client
+}
+
+// NewRedisDocumentLease creates a Redis-backed document lease.
+func NewRedisDocumentLease(client *redis.Client) *RedisDocumentLease {
+	return &RedisDocumentLease{redis: client}
+}
+
+// Acquire gives the document to holder unless someone else holds it.
+func (l *RedisDocumentLease) Acquire(ctx context.Context, docID uuid.UUID, holder string, ttl time.Duration) (string, error) {
+	current, err := acquireLeaseScript.Run(ctx, l.redis,
+		[]string{documentLeaseKeyPrefix + docID.String()}, holder, ttl.Milliseconds(),
+	).Text()
+	if err != nil {
+		return "", fmt.Errorf("acquire document lease: %w", err)
+	}
+	return current, nil
+}
+
+// Release gives up the lease if holder still has it.
+func (l *RedisDocumentLease) Release(ctx context.Context, docID uuid.UUID, holder string) error {
+	err := releaseLeaseScript.Run(ctx, l.redis,
+		[]string{documentLeaseKeyPrefix + docID.String()}, holder,
+	).Err()
+	if err != nil {
+		return fmt.Errorf("release document lease: %w", err)
+	}
+	return nil
+}
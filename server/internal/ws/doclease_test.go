@@ -0,0 +1,69 @@
+package ws_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otoritech/chatat/internal/ws"
+)
+
+func TestDocumentLease(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	leases := map[string]ws.DocumentLease{
+		"memory": ws.NewMemoryDocumentLease(),
+		"redis":  ws.NewRedisDocumentLease(client),
+	}
+	for name, lease := range leases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			docID := uuid.New()
+
+			owner, err := lease.Acquire(ctx, docID, "node-a", time.Minute)
+			require.NoError(t, err)
+			assert.Equal(t, "node-a", owner)
+
+			// Held by node-a, renewable only by it
+			owner, err = lease.Acquire(ctx, docID, "node-b", time.Minute)
+			require.NoError(t, err)
+			assert.Equal(t, "node-a", owner)
+			owner, err = lease.Acquire(ctx, docID, "node-a", time.Minute)
+			require.NoError(t, err)
+			assert.Equal(t, "node-a", owner)
+
+			// Only the holder can release it
+			require.NoError(t, lease.Release(ctx, docID, "node-b"))
+			owner, err = lease.Acquire(ctx, docID, "node-b", time.Minute)
+			require.NoError(t, err)
+			assert.Equal(t, "node-a", owner)
+
+			require.NoError(t, lease.Release(ctx, docID, "node-a"))
+			owner, err = lease.Acquire(ctx, docID, "node-b", time.Minute)
+			require.NoError(t, err)
+			assert.Equal(t, "node-b", owner)
+		})
+	}
+}
+
+func TestDocumentLease_Expires(t *testing.T) {
+	ctx := context.Background()
+	docID := uuid.New()
+	lease := ws.NewMemoryDocumentLease()
+
+	_, err := lease.Acquire(ctx, docID, "node-a", 20*time.Millisecond)
+	require.NoError(t, err)
+	time.Sleep(30 * time.Millisecond)
+
+	owner, err := lease.Acquire(ctx, docID, "node-b", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "node-b", owner)
+}
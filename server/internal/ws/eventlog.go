@@ -0,0 +1,329 @@
+package ws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultEventLogCapacity = 500
+	defaultEventLogTTL      = 24 * time.Hour
+	eventLogKeyPrefix       = "ws:eventlog:"
+)
+
+// LoggedEvent is a user-bound event kept for replay, already stamped with its sequence.
+type LoggedEvent struct {
+	Seq  int64           `json:"seq"`
+	Data json.RawMessage `json:"data"`
+}
+
+// EventLog assigns per-user sequence numbers to outgoing events and keeps the
+// most recent ones so reconnecting clients can catch up.
+type EventLog interface {
+	// Append records data for a user and returns its sequence number.
+	// Appending the same eventID twice for a user returns the original sequence
+	// and the second append is ignored, so replicas can log a relayed event
+	// independently.
+	Append(ctx context.Context, userID uuid.UUID, eventID string, data []byte) (int64, error)
+	// AppendAll records the same event for several users at once, with the
+	// same per-user semantics as Append, and returns each user's sequence.
+	AppendAll(ctx context.Context, userIDs []uuid.UUID, eventID string, data []byte) (map[uuid.UUID]int64, error)
+	// Since returns the events with a sequence greater than afterSeq. complete
+	// is false when some of those events have already been evicted.
+	Since(ctx context.Context, userID uuid.UUID, afterSeq int64) (events []LoggedEvent, complete bool, err error)
+}
+
+// stampSeq adds a top-level "seq" field to a JSON object. Data that is not a
+// JSON object is returned unchanged and reported as not stampable.
+func stampSeq(data []byte, seq int64) ([]byte, bool) {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	if len(trimmed) < 2 || trimmed[0] != '{' {
+		return data, false
+	}
+
+	rest := bytes.TrimLeft(trimmed[1:], " \t\r\n")
+	out := make([]byte, 0, len(trimmed)+24)
+	out = append(out, `{"seq":`...)
+	out = strconv.AppendInt(out, seq, 10)
+	if len(rest) > 0 && rest[0] != '}' {
+		out = append(out, ',')
+	}
+	return append(out, rest...), true
+}
+
+// isJSONObject reports whether data can carry a sequence number.
+func isJSONObject(data []byte) bool {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
+
+// ephemeralEventTypes are events only worth delivering live. Replaying them
+// would show stale state, and logging them would evict the durable events
+// resume exists for.
+var ephemeralEventTypes = map[string]bool{
+	WSTypeTyping:       true,
+	WSTypeOnlineStatus: true,
+	WSTypeDocPresence:  true,
+	WSTypeDocUpdate:    true,
+	WSTypeDocSync:      true,
+	WSTypeDocRejected:  true,
+}
+
+// isDurableEvent reports whether data is an event to sequence and log for
+// replay: a JSON object whose type is not ephemeral.
+func isDurableEvent(data []byte) bool {
+	if !isJSONObject(data) {
+		return false
+	}
+	var event struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return false
+	}
+	return !ephemeralEventTypes[event.Type]
+}
+
+// --- In-memory implementation ---
+
+type userEventLog struct {
+	seq        int64
+	events     []LoggedEvent
+	eventSeqs  map[string]int64
+	lastAppend time.Time
+}
+
+// MemoryEventLog is a node-local EventLog. Each user keeps at most capacity
+// events; users with no activity for longer than ttl are dropped.
+type MemoryEventLog struct {
+	capacity  int
+	ttl       time.Duration
+	users     map[uuid.UUID]*userEventLog
+	lastSweep time.Time
+	mu        sync.Mutex
+}
+
+// NewMemoryEventLog creates an in-memory event log. Zero values select the defaults.
+func NewMemoryEventLog(capacity int, ttl time.Duration) *MemoryEventLog {
+	if capacity <= 0 {
+		capacity = defaultEventLogCapacity
+	}
+	if ttl == 0 {
+		ttl = defaultEventLogTTL
+	}
+	return &MemoryEventLog{
+		capacity:  capacity,
+		ttl:       ttl,
+		users:     make(map[uuid.UUID]*userEventLog),
+		lastSweep: time.Now(),
+	}
+}
+
+// Append records an event for a user.
+func (l *MemoryEventLog) Append(_ context.Context, userID uuid.UUID, eventID string, data []byte) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) > l.ttl {
+		for id, ul := range l.users {
+			if now.Sub(ul.lastAppend) > l.ttl {
+				delete(l.users, id)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	ul, ok := l.users[userID]
+	if !ok {
+		ul = &userEventLog{eventSeqs: make(map[string]int64)}
+		l.users[userID] = ul
+	}
+	if seq, dup := ul.eventSeqs[eventID]; dup {
+		return seq, nil
+	}
+
+	ul.seq++
+	ul.lastAppend = now
+	stamped, _ := stampSeq(data, ul.seq)
+	ul.events = append(ul.events, LoggedEvent{Seq: ul.seq, Data: stamped})
+	ul.eventSeqs[eventID] = ul.seq
+
+	if len(ul.events) > l.capacity {
+		evicted := len(ul.events) - l.capacity
+		ul.events = append([]LoggedEvent(nil), ul.events[evicted:]...)
+		oldest := ul.events[0].Seq
+		for id, seq := range ul.eventSeqs {
+			if seq < oldest {
+				delete(ul.eventSeqs, id)
+			}
+		}
+	}
+	return ul.seq, nil
+}
+
+// AppendAll records an event for each of the users.
+func (l *MemoryEventLog) AppendAll(ctx context.Context, userIDs []uuid.UUID, eventID string, data []byte) (map[uuid.UUID]int64, error) {
+	seqs := make(map[uuid.UUID]int64, len(userIDs))
+	for _, userID := range userIDs {
+		seq, err := l.Append(ctx, userID, eventID, data)
+		if err != nil {
+			return nil, err
+		}
+		seqs[userID] = seq
+	}
+	return seqs, nil
+}
+
+// Since returns the events after afterSeq.
+func (l *MemoryEventLog) Since(_ context.Context, userID uuid.UUID, afterSeq int64) ([]LoggedEvent, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ul, ok := l.users[userID]
+	if !ok {
+		return []LoggedEvent{}, afterSeq == 0, nil
+	}
+	return eventsAfter(ul.events, ul.seq, afterSeq)
+}
+
+// eventsAfter filters a seq-ordered slice and reports whether it covers the gap.
+func eventsAfter(events []LoggedEvent, currentSeq, afterSeq int64) ([]LoggedEvent, bool, error) {
+	if afterSeq >= currentSeq {
+		return []LoggedEvent{}, afterSeq == currentSeq, nil
+	}
+
+	result := make([]LoggedEvent, 0)
+	for _, e := range events {
+		if e.Seq > afterSeq {
+			result = append(result, e)
+		}
+	}
+	complete := len(events) > 0 && events[0].Seq <= afterSeq+1
+	return result, complete, nil
+}
+
+// --- Redis implementation ---
+
+// appendEventScript appends to the logs of one or more users idempotently per
+// event ID, in a single round trip.
+// KEYS: seq counter, event-id marker, log list, repeated for each user.
+// ARGV: data, capacity, ttl ms. Returns the users' sequences in KEYS order.
+var appendEventScript = redis.NewScript(`
+local seqs = {}
+for i = 1, #KEYS, 3 do
+	local seq = redis.call('GET', KEYS[i + 1])
+	if seq then
+		seq = tonumber(seq)
+	else
+		seq = redis.call('INCR', KEYS[i])
+		redis.call('PEXPIRE', KEYS[i], ARGV[3])
+		redis.call('SET', KEYS[i + 1], seq, 'PX', ARGV[3])
+		redis.call('RPUSH', KEYS[i + 2], cjson.encode({seq = seq, data = ARGV[1]}))
+		redis.call('LTRIM', KEYS[i + 2], -tonumber(ARGV[2]), -1)
+		redis.call('PEXPIRE', KEYS[i + 2], ARGV[3])
+	end
+	seqs[#seqs + 1] = seq
+end
+return seqs
+`)
+
+// RedisEventLog is an EventLog shared by every replica, so a client can
+// resume on a different node than the one it was connected to.
+type RedisEventLog struct {
+	redis    *redis.Client
+	capacity int
+	ttl      time.Duration
+}
+
+// NewRedisEventLog creates a Redis-backed event log. Zero values select the defaults.
+func NewRedisEventLog(client *redis.Client, capacity int, ttl time.Duration) *RedisEventLog {
+	if capacity <= 0 {
+		capacity = defaultEventLogCapacity
+	}
+	if ttl == 0 {
+		ttl = defaultEventLogTTL
+	}
+	return &RedisEventLog{
+		redis:    client,
+		capacity: capacity,
+		ttl:      ttl,
+	}
+}
+
+type redisLogEntry struct {
+	Seq  int64  `json:"seq"`
+	Data string `json:"data"`
+}
+
+// Append records an event for a user.
+func (l *RedisEventLog) Append(ctx context.Context, userID uuid.UUID, eventID string, data []byte) (int64, error) {
+	seqs, err := l.AppendAll(ctx, []uuid.UUID{userID}, eventID, data)
+	if err != nil {
+		return 0, err
+	}
+	return seqs[userID], nil
+}
+
+// AppendAll records an event for each of the users with one script call.
+func (l *RedisEventLog) AppendAll(ctx context.Context, userIDs []uuid.UUID, eventID string, data []byte) (map[uuid.UUID]int64, error) {
+	if len(userIDs) == 0 {
+		return map[uuid.UUID]int64{}, nil
+	}
+
+	keys := make([]string, 0, 3*len(userIDs))
+	for _, userID := range userIDs {
+		prefix := eventLogKeyPrefix + userID.String()
+		keys = append(keys, prefix+":seq", prefix+":evt:"+eventID, prefix+":log")
+	}
+
+	values, err := appendEventScript.Run(ctx, l.redis, keys, string(data), l.capacity, l.ttl.Milliseconds()).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("append event log: %w", err)
+	}
+	if len(values) != len(userIDs) {
+		return nil, fmt.Errorf("append event log: got %d sequences for %d users", len(values), len(userIDs))
+	}
+
+	seqs := make(map[uuid.UUID]int64, len(userIDs))
+	for i, userID := range userIDs {
+		seqs[userID] = values[i]
+	}
+	return seqs, nil
+}
+
+// Since returns the events after afterSeq.
+func (l *RedisEventLog) Since(ctx context.Context, userID uuid.UUID, afterSeq int64) ([]LoggedEvent, bool, error) {
+	prefix := eventLogKeyPrefix + userID.String()
+
+	pipe := l.redis.Pipeline()
+	seqCmd := pipe.Get(ctx, prefix+":seq")
+	logCmd := pipe.LRange(ctx, prefix+":log", 0, -1)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, false, fmt.Errorf("read event log: %w", err)
+	}
+
+	currentSeq, err := seqCmd.Int64()
+	if err != nil && err != redis.Nil {
+		return nil, false, fmt.Errorf("read event log seq: %w", err)
+	}
+
+	events := make([]LoggedEvent, 0, len(logCmd.Val()))
+	for _, raw := range logCmd.Val() {
+		var entry redisLogEntry
+		if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+			continue
+		}
+		stamped, _ := stampSeq([]byte(entry.Data), entry.Seq)
+		events = append(events, LoggedEvent{Seq: entry.Seq, Data: stamped})
+	}
+	return eventsAfter(events, currentSeq, afterSeq)
+}
@@ -0,0 +1,328 @@
+package ws_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/otoritech/chatat/internal/ws"
+)
+
+func testEventLogs(t *testing.T, capacity int) map[string]ws.EventLog {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return map[string]ws.EventLog{
+		"memory": ws.NewMemoryEventLog(capacity, 0),
+		"redis":  ws.NewRedisEventLog(client, capacity, 0),
+	}
+}
+
+func TestEventLog_AppendAndSince(t *testing.T) {
+	for name, eventLog := range testEventLogs(t, 10) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			userID := uuid.New()
+
+			for i := 1; i <= 3; i++ {
+				seq, err := eventLog.Append(ctx, userID, uuid.NewString(), []byte(`{"type":"new_message"}`))
+				require.NoError(t, err)
+				assert.Equal(t, int64(i), seq)
+			}
+
+			events, complete, err := eventLog.Since(ctx, userID, 1)
+			require.NoError(t, err)
+			assert.True(t, complete)
+			require.Len(t, events, 2)
+			assert.Equal(t, int64(2), events[0].Seq)
+			assert.JSONEq(t, `{"seq":2,"type":"new_message"}`, string(events[0].Data))
+
+			events, complete, err = eventLog.Since(ctx, userID, 3)
+			require.NoError(t, err)
+			assert.True(t, complete)
+			assert.Empty(t, events)
+		})
+	}
+}
+
+func TestEventLog_DuplicateEventID(t *testing.T) {
+	for name, eventLog := range testEventLogs(t, 10) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			userID := uuid.New()
+
+			first, err := eventLog.Append(ctx, userID, "evt-1", []byte(`{"a":1}`))
+			require.NoError(t, err)
+			again, err := eventLog.Append(ctx, userID, "evt-1", []byte(`{"a":1}`))
+			require.NoError(t, err)
+			assert.Equal(t, first, again)
+
+			events, _, err := eventLog.Since(ctx, userID, 0)
+			require.NoError(t, err)
+			assert.Len(t, events, 1)
+		})
+	}
+}
+
+func TestEventLog_AppendAll(t *testing.T) {
+	for name, eventLog := range testEventLogs(t, 10) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			alice, bob := uuid.New(), uuid.New()
+
+			_, err := eventLog.Append(ctx, alice, uuid.NewString(), []byte(`{"n":0}`))
+			require.NoError(t, err)
+
+			seqs, err := eventLog.AppendAll(ctx, []uuid.UUID{alice, bob}, "evt-1", []byte(`{"n":1}`))
+			require.NoError(t, err)
+			assert.Equal(t, map[uuid.UUID]int64{alice: 2, bob: 1}, seqs)
+
+			again, err := eventLog.AppendAll(ctx, []uuid.UUID{alice, bob}, "evt-1", []byte(`{"n":1}`))
+			require.NoError(t, err)
+			assert.Equal(t, seqs, again)
+
+			events, _, err := eventLog.Since(ctx, bob, 0)
+			require.NoError(t, err)
+			require.Len(t, events, 1)
+			assert.JSONEq(t, `{"seq":1,"n":1}`, string(events[0].Data))
+		})
+	}
+}
+
+func TestEventLog_BoundedCapacity(t *testing.T) {
+	for name, eventLog := range testEventLogs(t, 3) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			userID := uuid.New()
+
+			for i := 0; i < 5; i++ {
+				_, err := eventLog.Append(ctx, userID, uuid.NewString(), []byte(`{}`))
+				require.NoError(t, err)
+			}
+
+			// seq 3..5 retained: resuming from 2 is still complete
+			events, complete, err := eventLog.Since(ctx, userID, 2)
+			require.NoError(t, err)
+			assert.True(t, complete)
+			assert.Len(t, events, 3)
+
+			// seq 2 was evicted: resuming from 1 leaves a gap
+			events, complete, err = eventLog.Since(ctx, userID, 1)
+			require.NoError(t, err)
+			assert.False(t, complete)
+			assert.Len(t, events, 3)
+			assert.JSONEq(t, `{"seq":3}`, string(events[0].Data))
+		})
+	}
+}
+
+func TestEventLog_UnknownFutureSeq(t *testing.T) {
+	for name, eventLog := range testEventLogs(t, 10) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			userID := uuid.New()
+
+			_, err := eventLog.Append(ctx, userID, uuid.NewString(), []byte(`{}`))
+			require.NoError(t, err)
+
+			// A client ahead of the log (e.g. after the log expired) must refetch
+			_, complete, err := eventLog.Since(ctx, userID, 42)
+			require.NoError(t, err)
+			assert.False(t, complete)
+		})
+	}
+}
+
+func readReplay(t *testing.T, c *ws.Client) (events []ws.LoggedEvent, complete bool) {
+	t.Helper()
+	select {
+	case raw := <-c.Send:
+		var msg ws.WSMessage
+		require.NoError(t, json.Unmarshal(raw, &msg))
+		require.Equal(t, ws.WSTypeResume, msg.Type)
+		var payload struct {
+			Events   []ws.LoggedEvent `json:"events"`
+			Complete bool             `json:"complete"`
+		}
+		require.NoError(t, json.Unmarshal(msg.Payload, &payload))
+		return payload.Events, payload.Complete
+	case <-time.After(time.Second):
+		t.Fatal("expected resume reply")
+	}
+	return nil, false
+}
+
+func TestHub_EventLog_StampsAndReplays(t *testing.T) {
+	hub := ws.NewHub()
+	hub.SetEventLog(ws.NewMemoryEventLog(0, 0))
+	go hub.Run()
+	t.Cleanup(func() { hub.Shutdown() })
+
+	userID := uuid.New()
+	client := &ws.Client{UserID: userID, DeviceID: "phone", Send: make(chan []byte, 16), Hub: hub}
+	hub.RegisterClient(client)
+	time.Sleep(10 * time.Millisecond)
+	hub.JoinRoom(client, "chat:seq")
+
+	hub.SendToRoom("chat:seq", []byte(`{"type":"new_message"}`), uuid.Nil)
+	hub.SendToUser(userID, []byte(`{"type":"notification"}`))
+
+	var seqs []int64
+	for i := 0; i < 2; i++ {
+		select {
+		case raw := <-client.Send:
+			var msg ws.WSMessage
+			require.NoError(t, json.Unmarshal(raw, &msg))
+			seqs = append(seqs, msg.Seq)
+		case <-time.After(time.Second):
+			t.Fatal("expected stamped event")
+		}
+	}
+	assert.ElementsMatch(t, []int64{1, 2}, seqs)
+
+	require.NoError(t, hub.Replay(client, 0))
+	events, complete := readReplay(t, client)
+	assert.True(t, complete)
+	assert.Len(t, events, 2)
+}
+
+func TestHub_EventLog_ReplaysMissedWhileOffline(t *testing.T) {
+	hub := ws.NewHub()
+	hub.SetEventLog(ws.NewMemoryEventLog(0, 0))
+	go hub.Run()
+	t.Cleanup(func() { hub.Shutdown() })
+
+	userID := uuid.New()
+	first := &ws.Client{UserID: userID, DeviceID: "phone", Send: make(chan []byte, 16), Hub: hub}
+	hub.RegisterClient(first)
+	time.Sleep(10 * time.Millisecond)
+	hub.JoinRoom(first, "chat:blip")
+
+	hub.SendToRoom("chat:blip", []byte(`{"type":"new_message","n":1}`), uuid.Nil)
+	<-first.Send
+
+	// Network blip: the connection drops, events keep flowing
+	hub.UnregisterClient(first)
+	time.Sleep(10 * time.Millisecond)
+	hub.SendToRoom("chat:blip", []byte(`{"type":"new_message","n":2}`), uuid.Nil)
+	time.Sleep(10 * time.Millisecond)
+	hub.SendToUser(userID, []byte(`{"type":"notification"}`))
+	time.Sleep(20 * time.Millisecond)
+
+	second := &ws.Client{UserID: userID, DeviceID: "phone", Send: make(chan []byte, 16), Hub: hub}
+	hub.RegisterClient(second)
+	time.Sleep(10 * time.Millisecond)
+
+	require.NoError(t, hub.Replay(second, 1))
+	events, complete := readReplay(t, second)
+	assert.True(t, complete)
+	require.Len(t, events, 2)
+	assert.JSONEq(t, `{"seq":2,"type":"new_message","n":2}`, string(events[0].Data))
+	assert.JSONEq(t, `{"seq":3,"type":"notification"}`, string(events[1].Data))
+}
+
+func TestHub_EventLog_SkipsEphemeralEvents(t *testing.T) {
+	hub := ws.NewHub()
+	hub.SetEventLog(ws.NewMemoryEventLog(0, 0))
+	go hub.Run()
+	t.Cleanup(func() { hub.Shutdown() })
+
+	userID := uuid.New()
+	client := &ws.Client{UserID: userID, DeviceID: "phone", Send: make(chan []byte, 16), Hub: hub}
+	hub.RegisterClient(client)
+	time.Sleep(10 * time.Millisecond)
+	hub.JoinRoom(client, "doc:seq")
+
+	hub.SendToRoom("doc:seq", []byte(`{"type":"typing"}`), uuid.Nil)
+	hub.SendToRoom("doc:seq", []byte(`{"type":"doc_update"}`), uuid.Nil)
+	hub.SendToUser(userID, []byte(`{"type":"online_status"}`))
+	hub.SendToRoom("doc:seq", []byte(`{"type":"doc_comment"}`), uuid.Nil)
+
+	seqs := make(map[string]int64)
+	for i := 0; i < 4; i++ {
+		select {
+		case raw := <-client.Send:
+			var msg ws.WSMessage
+			require.NoError(t, json.Unmarshal(raw, &msg))
+			seqs[msg.Type] = msg.Seq
+		case <-time.After(time.Second):
+			t.Fatal("expected event")
+		}
+	}
+	assert.Equal(t, map[string]int64{"typing": 0, "doc_update": 0, "online_status": 0, "doc_comment": 1}, seqs)
+
+	require.NoError(t, hub.Replay(client, 0))
+	events, complete := readReplay(t, client)
+	assert.True(t, complete)
+	require.Len(t, events, 1)
+	assert.JSONEq(t, `{"seq":1,"type":"doc_comment"}`, string(events[0].Data))
+}
+
+// stalledEventLog blocks every append until release is closed.
+type stalledEventLog struct {
+	*ws.MemoryEventLog
+	release chan struct{}
+}
+
+func (l *stalledEventLog) AppendAll(ctx context.Context, userIDs []uuid.UUID, eventID string, data []byte) (map[uuid.UUID]int64, error) {
+	<-l.release
+	return l.MemoryEventLog.AppendAll(ctx, userIDs, eventID, data)
+}
+
+func TestHub_EventLog_SlowAppendDoesNotStallHub(t *testing.T) {
+	eventLog := &stalledEventLog{MemoryEventLog: ws.NewMemoryEventLog(0, 0), release: make(chan struct{})}
+	hub := ws.NewHub()
+	hub.SetEventLog(eventLog)
+	go hub.Run()
+	t.Cleanup(func() { hub.Shutdown() })
+
+	sender := &ws.Client{UserID: uuid.New(), DeviceID: "phone", Send: make(chan []byte, 16), Hub: hub}
+	hub.RegisterClient(sender)
+	time.Sleep(10 * time.Millisecond)
+	hub.JoinRoom(sender, "chat:slow")
+	hub.SendToRoom("chat:slow", []byte(`{"type":"new_message"}`), uuid.Nil)
+
+	// The hub keeps registering clients while the append is stuck
+	other := &ws.Client{UserID: uuid.New(), DeviceID: "phone", Send: make(chan []byte, 16), Hub: hub}
+	registered := make(chan struct{})
+	go func() {
+		hub.RegisterClient(other)
+		close(registered)
+	}()
+	select {
+	case <-registered:
+	case <-time.After(time.Second):
+		t.Fatal("hub stalled on event log append")
+	}
+
+	close(eventLog.release)
+	select {
+	case raw := <-sender.Send:
+		var msg ws.WSMessage
+		require.NoError(t, json.Unmarshal(raw, &msg))
+		assert.Equal(t, int64(1), msg.Seq)
+	case <-time.After(time.Second):
+		t.Fatal("expected stamped event")
+	}
+}
+
+func TestHub_Replay_WithoutEventLog(t *testing.T) {
+	hub := startHub(t)
+	client := &ws.Client{UserID: uuid.New(), DeviceID: "phone", Send: make(chan []byte, 16), Hub: hub}
+	hub.RegisterClient(client)
+	time.Sleep(10 * time.Millisecond)
+
+	require.NoError(t, hub.Replay(client, 5))
+	events, complete := readReplay(t, client)
+	assert.False(t, complete)
+	assert.Empty(t, events)
+}
@@ -0,0 +1,89 @@
+package ws
+
+import (
+	"errors"
+	"strings"
+)
+
+// orderKeyDigits are the digits of a block order key, in ascending byte
+// order so keys compare with plain string comparison.
+const orderKeyDigits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// ErrInvalidOrderKeys is returned by KeyBetween when its bounds are not valid
+// order keys or are not in ascending order.
+var ErrInvalidOrderKeys = errors.New("invalid order key bounds")
+
+// ValidOrderKey reports whether key is a usable block order key: a non-empty
+// run of base-62 digits that does not end in zero. The trailing-zero rule
+// guarantees there is always room for a key before it.
+func ValidOrderKey(key string) bool {
+	if key == "" || key[len(key)-1] == orderKeyDigits[0] {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if strings.IndexByte(orderKeyDigits, key[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// KeyBetween returns an order key that sorts strictly between a and b. An
+// empty a means the start of the list and an empty b its end, so
+// KeyBetween("", "") gives the key for the first block of an empty list.
+func KeyBetween(a, b string) (string, error) {
+	if (a != "" && !ValidOrderKey(a)) || (b != "" && !ValidOrderKey(b)) {
+		return "", ErrInvalidOrderKeys
+	}
+	if a != "" && b != "" && a >= b {
+		return "", ErrInvalidOrderKeys
+	}
+	return orderKeyMidpoint(a, b), nil
+}
+
+// orderKeyMidpoint treats keys as base-62 fractions and returns a short key
+// between them. b == "" stands for 1.
+func orderKeyMidpoint(a, b string) string {
+	if b != "" {
+		// Keep the shared prefix and split the remainder
+		n := 0
+		for n < len(b) && orderKeyDigitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(a) {
+				rest = a[n:]
+			}
+			return b[:n] + orderKeyMidpoint(rest, b[n:])
+		}
+	}
+
+	digitA := 0
+	if a != "" {
+		digitA = strings.IndexByte(orderKeyDigits, a[0])
+	}
+	digitB := len(orderKeyDigits)
+	if b != "" {
+		digitB = strings.IndexByte(orderKeyDigits, b[0])
+	}
+
+	if digitB-digitA > 1 {
+		return string(orderKeyDigits[(digitA+digitB+1)/2])
+	}
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if len(a) > 1 {
+		rest = a[1:]
+	}
+	return string(orderKeyDigits[digitA]) + orderKeyMidpoint(rest, "")
+}
+
+func orderKeyDigitAt(key string, i int) byte {
+	if i < len(key) {
+		return key[i]
+	}
+	return orderKeyDigits[0]
+}
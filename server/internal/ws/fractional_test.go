@@ -0,0 +1,87 @@
+package ws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyBetween(t *testing.T) {
+	t.Run("first key of an empty list", func(t *testing.T) {
+		key, err := KeyBetween("", "")
+		require.NoError(t, err)
+		assert.True(t, ValidOrderKey(key))
+	})
+
+	t.Run("appending keeps keys ascending", func(t *testing.T) {
+		prev := ""
+		for i := 0; i < 200; i++ {
+			key, err := KeyBetween(prev, "")
+			require.NoError(t, err)
+			assert.True(t, ValidOrderKey(key))
+			assert.Greater(t, key, prev)
+			prev = key
+		}
+	})
+
+	t.Run("prepending keeps keys descending", func(t *testing.T) {
+		next := ""
+		for i := 0; i < 200; i++ {
+			key, err := KeyBetween("", next)
+			require.NoError(t, err)
+			assert.True(t, ValidOrderKey(key))
+			if next != "" {
+				assert.Less(t, key, next)
+			}
+			next = key
+		}
+	})
+
+	t.Run("inserting between the same pair", func(t *testing.T) {
+		a, b := "V", "W"
+		for i := 0; i < 100; i++ {
+			key, err := KeyBetween(a, b)
+			require.NoError(t, err)
+			assert.True(t, ValidOrderKey(key))
+			assert.Greater(t, key, a)
+			assert.Less(t, key, b)
+			b = key
+		}
+	})
+
+	t.Run("adjacent digits", func(t *testing.T) {
+		key, err := KeyBetween("a", "b")
+		require.NoError(t, err)
+		assert.Greater(t, key, "a")
+		assert.Less(t, key, "b")
+
+		key, err = KeyBetween("az", "b")
+		require.NoError(t, err)
+		assert.Greater(t, key, "az")
+		assert.Less(t, key, "b")
+	})
+
+	t.Run("invalid bounds", func(t *testing.T) {
+		_, err := KeyBetween("b", "a")
+		assert.ErrorIs(t, err, ErrInvalidOrderKeys)
+
+		_, err = KeyBetween("a", "a")
+		assert.ErrorIs(t, err, ErrInvalidOrderKeys)
+
+		_, err = KeyBetween("a0", "")
+		assert.ErrorIs(t, err, ErrInvalidOrderKeys)
+
+		_, err = KeyBetween("", "a-b")
+		assert.ErrorIs(t, err, ErrInvalidOrderKeys)
+	})
+}
+
+func TestValidOrderKey(t *testing.T) {
+	assert.True(t, ValidOrderKey("V"))
+	assert.True(t, ValidOrderKey("0V"))
+	assert.True(t, ValidOrderKey("az1"))
+	assert.False(t, ValidOrderKey(""))
+	assert.False(t, ValidOrderKey("a0"))
+	assert.False(t, ValidOrderKey("a b"))
+}
@@ -1,6 +1,9 @@
 package ws
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"sync"
 	"time"
 
@@ -8,13 +11,40 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-// disconnectDebounce is the time to wait before broadcasting offline status.
-const disconnectDebounce = 5 * time.Second
+const (
+	// disconnectDebounce is the time to wait before broadcasting offline status.
+	disconnectDebounce = 5 * time.Second
+
+	// clusterOpTimeout bounds every cluster (Redis) call made by the hub.
+	clusterOpTimeout = 3 * time.Second
+
+	// recordQueueSize bounds the events waiting to be appended to the event
+	// log. Events that do not fit are delivered unsequenced.
+	recordQueueSize = 256
+
+	// nodeQueueSize bounds the node messages waiting for the node handler.
+	nodeQueueSize = 256
+
+	// replayLingerWindow is how long a disconnected user keeps receiving room
+	// events into their replay log, covering short network blips.
+	replayLingerWindow = 2 * time.Minute
+)
 
 // Hub maintains the set of active clients and broadcasts messages to rooms.
+// A user may hold several live connections at once (one per device); the hub
+// tracks each of them and only treats the user as offline once the last one
+// has gone away.
+//
+// When a Cluster is attached, room and user broadcasts are also relayed to the
+// other server replicas and presence queries become cluster-wide.
+//
+// When an EventLog is attached, every JSON event delivered to a user is
+// stamped with a per-user sequence number and kept for replay. Appending runs
+// on a separate recorder goroutine, which hands the stamped copies back to
+// the event loop, so a slow log delays those events without stalling the hub.
 type Hub struct {
-	clients          map[uuid.UUID]*Client
-	rooms            map[string]map[uuid.UUID]*Client
+	clients          map[uuid.UUID]map[string]*Client
+	rooms            map[string]map[*Client]struct{}
 	register         chan *Client
 	unregister       chan *Client
 	broadcast        chan *BroadcastMessage
@@ -23,6 +53,33 @@ type Hub struct {
 	onConnect        func(userID uuid.UUID)
 	onDisconnect     func(userID uuid.UUID)
 	disconnectTimers map[uuid.UUID]*time.Timer
+	cluster          Cluster
+	clusterEvents    <-chan ClusterEvent
+	eventLog         EventLog
+	records          chan *recordJob
+	recorded         chan *recordJob
+	lingerRooms      map[string]map[uuid.UUID]struct{}
+	lingering        map[uuid.UUID]*lingerState
+	nodeMessages     chan []byte
+	nodeHandler      func(data []byte)
+}
+
+// lingerState tracks the rooms a recently disconnected user still logs events for.
+type lingerState struct {
+	rooms []string
+	timer *time.Timer
+}
+
+// recordJob is an event waiting to be appended to its recipients' replay
+// logs. Room is empty for an event sent to a single user. The recorder fills
+// in stamped, each recipient's copy carrying their sequence number.
+type recordJob struct {
+	room    string
+	userIDs []uuid.UUID
+	exclude uuid.UUID
+	eventID string
+	data    []byte
+	stamped map[uuid.UUID][]byte
 }
 
 // BroadcastMessage represents a message to be broadcast to a room.
@@ -30,106 +87,510 @@ type BroadcastMessage struct {
 	Room    string
 	Data    []byte
 	Exclude uuid.UUID
+	EventID string
 }
 
 // NewHub creates a new Hub instance.
 func NewHub() *Hub {
 	return &Hub{
-		clients:          make(map[uuid.UUID]*Client),
-		rooms:            make(map[string]map[uuid.UUID]*Client),
+		clients:          make(map[uuid.UUID]map[string]*Client),
+		rooms:            make(map[string]map[*Client]struct{}),
 		register:         make(chan *Client),
 		unregister:       make(chan *Client),
 		broadcast:        make(chan *BroadcastMessage, 256),
 		done:             make(chan struct{}),
+		records:          make(chan *recordJob, recordQueueSize),
+		recorded:         make(chan *recordJob),
 		disconnectTimers: make(map[uuid.UUID]*time.Timer),
+		lingerRooms:      make(map[string]map[uuid.UUID]struct{}),
+		lingering:        make(map[uuid.UUID]*lingerState),
+		nodeMessages:     make(chan []byte, nodeQueueSize),
 	}
 }
 
 // SetEventCallbacks sets the callbacks for client connect/disconnect events.
 // The callbacks are invoked asynchronously from the Hub's event loop.
+// onConnect fires when a user's first connection is registered and
+// onDisconnect (debounced) fires once the user's last connection is gone.
 func (h *Hub) SetEventCallbacks(onConnect, onDisconnect func(uuid.UUID)) {
 	h.onConnect = onConnect
 	h.onDisconnect = onDisconnect
 }
 
+// SetCluster attaches a cluster backend and subscribes to events from the
+// other replicas. Must be called before Run.
+func (h *Hub) SetCluster(cluster Cluster) error {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+
+	events, err := cluster.Subscribe(ctx)
+	if err != nil {
+		return err
+	}
+	h.cluster = cluster
+	h.clusterEvents = events
+	return nil
+}
+
+// SetNodeHandler sets the function that receives the data sent to this node
+// with SendToNode. It is called from a single goroutine, in arrival order.
+func (h *Hub) SetNodeHandler(handler func(data []byte)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.nodeHandler = handler
+}
+
+// NodeID returns the cluster node ID of this replica, or "" without a cluster.
+func (h *Hub) NodeID() string {
+	if h.cluster == nil {
+		return ""
+	}
+	return h.cluster.NodeID()
+}
+
+// SetEventLog enables per-user sequencing and replay. Must be called before Run.
+func (h *Hub) SetEventLog(eventLog EventLog) {
+	h.eventLog = eventLog
+}
+
 // Run starts the hub event loop. Should be called in a goroutine.
 func (h *Hub) Run() {
+	var heartbeat <-chan time.Time
+	if h.cluster != nil {
+		ticker := time.NewTicker(h.cluster.PresenceTTL() / 3)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+	if h.eventLog != nil {
+		go h.runRecorder()
+	}
+	go h.runNodeHandler()
+
 	for {
 		select {
 		case <-h.done:
 			return
 
 		case client := <-h.register:
-			h.mu.Lock()
-			// Cancel pending disconnect timer if user reconnects quickly
-			if timer, ok := h.disconnectTimers[client.UserID]; ok {
-				timer.Stop()
-				delete(h.disconnectTimers, client.UserID)
-				log.Debug().Str("user_id", client.UserID.String()).Msg("reconnect: cancelled offline broadcast")
+			h.addClient(client)
+
+		case client := <-h.unregister:
+			h.removeClient(client)
+
+		case msg := <-h.broadcast:
+			h.deliverToRoom(msg.Room, msg.Data, msg.Exclude, msg.EventID)
+
+		case job := <-h.recorded:
+			h.deliverRecorded(job)
+
+		case event, ok := <-h.clusterEvents:
+			if !ok {
+				h.clusterEvents = nil
+				continue
+			}
+			if event.Origin == h.cluster.NodeID() {
+				// Already delivered locally when it was sent
+				continue
 			}
-			h.clients[client.UserID] = client
-			h.mu.Unlock()
-			log.Debug().Str("user_id", client.UserID.String()).Msg("client registered")
+			if event.Node != "" {
+				if event.Node == h.cluster.NodeID() {
+					h.queueNodeMessage(event.Data)
+				}
+			} else if event.Room != "" {
+				h.deliverToRoom(event.Room, event.Data, event.Exclude, event.EventID)
+			} else if event.DeviceID != "" {
+				h.mu.RLock()
+				client := h.clients[event.UserID][event.DeviceID]
+				if client != nil && !event.Disconnect {
+					h.deliverToClientLocked(client, event.Data)
+				}
+				h.mu.RUnlock()
+				if client != nil && event.Disconnect {
+					h.removeClient(client)
+				}
+			} else {
+				// User events are sequenced by the origin node
+				h.mu.RLock()
+				h.deliverToUserLocked(event.UserID, event.Data)
+				h.mu.RUnlock()
+			}
+
+		case <-heartbeat:
+			h.refreshPresence()
+		}
+	}
+}
 
-			if h.onConnect != nil {
-				go h.onConnect(client.UserID)
+// deliverToRoom pushes data to the local connections in a room. With an event
+// log attached, a durable event is queued for the recorder instead, which
+// logs it for each recipient user (including lingering ones) and hands it
+// back for delivery. Ephemeral events are delivered unsequenced.
+func (h *Hub) deliverToRoom(roomID string, data []byte, exclude uuid.UUID, eventID string) {
+	if h.eventLog != nil && isDurableEvent(data) {
+		h.mu.RLock()
+		seen := make(map[uuid.UUID]struct{})
+		userIDs := make([]uuid.UUID, 0)
+		for client := range h.rooms[roomID] {
+			if _, dup := seen[client.UserID]; !dup && client.UserID != exclude {
+				seen[client.UserID] = struct{}{}
+				userIDs = append(userIDs, client.UserID)
 			}
+		}
+		for userID := range h.lingerRooms[roomID] {
+			if _, dup := seen[userID]; !dup && userID != exclude {
+				seen[userID] = struct{}{}
+				userIDs = append(userIDs, userID)
+			}
+		}
+		h.mu.RUnlock()
 
-		case client := <-h.unregister:
-			h.mu.Lock()
-			if _, ok := h.clients[client.UserID]; ok {
-				delete(h.clients, client.UserID)
-				close(client.Send)
-
-				// Remove from all rooms
-				for roomID, members := range h.rooms {
-					delete(members, client.UserID)
-					if len(members) == 0 {
-						delete(h.rooms, roomID)
-					}
-				}
+		if len(userIDs) == 0 {
+			return
+		}
+		if h.queueRecord(&recordJob{room: roomID, userIDs: userIDs, exclude: exclude, eventID: eventID, data: data}) {
+			return
+		}
+	}
 
-				// Debounce disconnect: wait before broadcasting offline
-				if h.onDisconnect != nil {
-					userID := client.UserID
-					h.disconnectTimers[userID] = time.AfterFunc(disconnectDebounce, func() {
-						h.mu.Lock()
-						delete(h.disconnectTimers, userID)
-						h.mu.Unlock()
-
-						// Only broadcast offline if user is still disconnected
-						if !h.IsOnline(userID) {
-							h.onDisconnect(userID)
-						}
-					})
-				}
+	h.mu.RLock()
+	h.deliverToRoomLocked(roomID, data, exclude, nil)
+	h.mu.RUnlock()
+}
+
+// deliverToRoomLocked pushes data to the local connections in a room, using
+// the per-user copy from stamped when there is one.
+// Caller must hold h.mu (read or write).
+func (h *Hub) deliverToRoomLocked(roomID string, data []byte, exclude uuid.UUID, stamped map[uuid.UUID][]byte) {
+	for client := range h.rooms[roomID] {
+		if client.UserID == exclude {
+			continue
+		}
+		payload := data
+		if userData, ok := stamped[client.UserID]; ok {
+			payload = userData
+		}
+		select {
+		case client.Send <- payload:
+		default:
+			// Client buffer full, skip
+			log.Warn().
+				Str("user_id", client.UserID.String()).
+				Str("device_id", client.DeviceID).
+				Msg("client send buffer full, skipping")
+		}
+	}
+}
+
+// queueRecord hands an event to the recorder without blocking. It returns
+// false when the queue is full, in which case the caller delivers the event
+// unsequenced.
+func (h *Hub) queueRecord(job *recordJob) bool {
+	select {
+	case h.records <- job:
+		return true
+	default:
+		log.Warn().Str("room", job.room).Msg("event log queue full, delivering unsequenced")
+		return false
+	}
+}
+
+// runRecorder appends queued events to the event log in arrival order and
+// hands the stamped copies back to the event loop. Events sent to a single
+// user are then relayed to the other replicas, already sequenced.
+func (h *Hub) runRecorder() {
+	for {
+		select {
+		case <-h.done:
+			return
+		case job := <-h.records:
+			h.record(job)
+			select {
+			case h.recorded <- job:
+			case <-h.done:
+				return
+			}
+			if job.room == "" {
+				userID := job.userIDs[0]
+				h.publish(ClusterEvent{UserID: userID, Data: job.payload(userID), EventID: job.eventID})
 			}
-			h.mu.Unlock()
-			log.Debug().Str("user_id", client.UserID.String()).Msg("client unregistered")
+		}
+	}
+}
 
-		case msg := <-h.broadcast:
-			h.mu.RLock()
-			if members, ok := h.rooms[msg.Room]; ok {
-				for userID, client := range members {
-					if userID == msg.Exclude {
-						continue
-					}
-					select {
-					case client.Send <- msg.Data:
-					default:
-						// Client buffer full, skip
-						log.Warn().Str("user_id", userID.String()).Msg("client send buffer full, skipping")
-					}
-				}
+// record appends an event to its recipients' replay logs in one call and
+// fills in their stamped copies. On failure the event is delivered
+// unsequenced.
+func (h *Hub) record(job *recordJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+
+	seqs, err := h.eventLog.AppendAll(ctx, job.userIDs, job.eventID, job.data)
+	if err != nil {
+		log.Warn().Err(err).Str("room", job.room).Int("recipients", len(job.userIDs)).Msg("failed to append event log")
+		return
+	}
+	job.stamped = make(map[uuid.UUID][]byte, len(seqs))
+	for userID, seq := range seqs {
+		job.stamped[userID], _ = stampSeq(job.data, seq)
+	}
+}
+
+// payload returns the copy of a recorded event meant for a user.
+func (j *recordJob) payload(userID uuid.UUID) []byte {
+	if stamped, ok := j.stamped[userID]; ok {
+		return stamped
+	}
+	return j.data
+}
+
+// deliverRecorded pushes an event the recorder has logged to the local
+// connections of its recipients.
+func (h *Hub) deliverRecorded(job *recordJob) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if job.room == "" {
+		h.deliverToUserLocked(job.userIDs[0], job.payload(job.userIDs[0]))
+		return
+	}
+	h.deliverToRoomLocked(job.room, job.data, job.exclude, job.stamped)
+}
+
+// deliverToUserLocked pushes data to every local connection of a user.
+// Caller must hold h.mu (read or write).
+func (h *Hub) deliverToUserLocked(userID uuid.UUID, data []byte) {
+	for deviceID, client := range h.clients[userID] {
+		select {
+		case client.Send <- data:
+		default:
+			log.Warn().Str("user_id", userID.String()).Str("device_id", deviceID).Msg("send to user: buffer full")
+		}
+	}
+}
+
+// addClient records a new connection for its user. A connection that reuses
+// the device ID of a still-registered one replaces it and closes the stale one.
+func (h *Hub) addClient(client *Client) {
+	h.mu.Lock()
+	// Cancel pending disconnect timer if user reconnects quickly
+	if timer, ok := h.disconnectTimers[client.UserID]; ok {
+		timer.Stop()
+		delete(h.disconnectTimers, client.UserID)
+		log.Debug().Str("user_id", client.UserID.String()).Msg("reconnect: cancelled offline broadcast")
+	}
+
+	devices, ok := h.clients[client.UserID]
+	if !ok {
+		devices = make(map[string]*Client)
+		h.clients[client.UserID] = devices
+	}
+	firstConnection := len(devices) == 0
+
+	if stale, ok := devices[client.DeviceID]; ok && stale != client {
+		h.detachLocked(stale)
+		close(stale.Send)
+		log.Debug().
+			Str("user_id", client.UserID.String()).
+			Str("device_id", client.DeviceID).
+			Msg("replaced stale connection for device")
+	}
+	devices[client.DeviceID] = client
+	h.mu.Unlock()
+
+	log.Debug().
+		Str("user_id", client.UserID.String()).
+		Str("device_id", client.DeviceID).
+		Msg("client registered")
+
+	if h.cluster != nil {
+		go h.announceClusterConnect(client, firstConnection)
+	} else if firstConnection && h.onConnect != nil {
+		go h.onConnect(client.UserID)
+	}
+}
+
+// announceClusterConnect publishes a new connection's presence. onConnect only
+// fires if the user had no live connection on any replica beforehand.
+func (h *Hub) announceClusterConnect(client *Client, firstLocal bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+
+	onlineElsewhere := false
+	if firstLocal {
+		online, err := h.cluster.OnlineUsers(ctx, []uuid.UUID{client.UserID})
+		if err != nil {
+			log.Warn().Err(err).Str("user_id", client.UserID.String()).Msg("cluster presence lookup failed")
+		}
+		onlineElsewhere = len(online) > 0
+	}
+
+	if err := h.cluster.SetPresence(ctx, client.UserID, client.DeviceID); err != nil {
+		log.Warn().Err(err).Str("user_id", client.UserID.String()).Msg("failed to publish cluster presence")
+	}
+
+	if firstLocal && !onlineElsewhere && h.onConnect != nil {
+		h.onConnect(client.UserID)
+	}
+}
+
+// refreshPresence renews the cluster presence entries of all local connections.
+func (h *Hub) refreshPresence() {
+	h.mu.RLock()
+	clients := make([]*Client, 0, len(h.clients))
+	for _, devices := range h.clients {
+		for _, client := range devices {
+			clients = append(clients, client)
+		}
+	}
+	h.mu.RUnlock()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+		defer cancel()
+		for _, client := range clients {
+			if err := h.cluster.SetPresence(ctx, client.UserID, client.DeviceID); err != nil {
+				log.Warn().Err(err).Msg("failed to refresh cluster presence")
+				return
 			}
-			h.mu.RUnlock()
+		}
+	}()
+}
+
+// removeClient drops a connection. Other connections of the same user keep
+// their room memberships; the offline callback is scheduled only when the user
+// has no connections left.
+func (h *Hub) removeClient(client *Client) {
+	h.mu.Lock()
+	devices := h.clients[client.UserID]
+	if current, ok := devices[client.DeviceID]; !ok || current != client {
+		// Already replaced by a newer connection for the same device
+		h.mu.Unlock()
+		return
+	}
+
+	delete(devices, client.DeviceID)
+	if len(devices) == 0 && h.eventLog != nil {
+		h.lingerLocked(client)
+	}
+	h.detachLocked(client)
+	close(client.Send)
+
+	if h.cluster != nil {
+		go h.clearClusterPresence(client)
+	}
+
+	if len(devices) == 0 {
+		delete(h.clients, client.UserID)
+
+		// Debounce disconnect: wait before broadcasting offline
+		if h.onDisconnect != nil {
+			userID := client.UserID
+			h.disconnectTimers[userID] = time.AfterFunc(disconnectDebounce, func() {
+				h.mu.Lock()
+				delete(h.disconnectTimers, userID)
+				h.mu.Unlock()
+
+				// Only broadcast offline if user is still disconnected
+				if !h.IsOnline(userID) {
+					h.onDisconnect(userID)
+				}
+			})
+		}
+	}
+	h.mu.Unlock()
+
+	log.Debug().
+		Str("user_id", client.UserID.String()).
+		Str("device_id", client.DeviceID).
+		Msg("client unregistered")
+}
+
+// clearClusterPresence removes a closed connection from the cluster presence set.
+func (h *Hub) clearClusterPresence(client *Client) {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+	if err := h.cluster.ClearPresence(ctx, client.UserID, client.DeviceID); err != nil {
+		log.Warn().Err(err).Str("user_id", client.UserID.String()).Msg("failed to clear cluster presence")
+	}
+}
+
+// lingerLocked keeps logging the rooms of a user's last connection for
+// replayLingerWindow so a quick reconnect can replay what it missed.
+// Caller must hold h.mu.
+func (h *Hub) lingerLocked(client *Client) {
+	userID := client.UserID
+	h.stopLingerLocked(userID)
+
+	state := &lingerState{}
+	for roomID, members := range h.rooms {
+		if _, ok := members[client]; !ok {
+			continue
+		}
+		state.rooms = append(state.rooms, roomID)
+		if _, ok := h.lingerRooms[roomID]; !ok {
+			h.lingerRooms[roomID] = make(map[uuid.UUID]struct{})
+		}
+		h.lingerRooms[roomID][userID] = struct{}{}
+	}
+	state.timer = time.AfterFunc(replayLingerWindow, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if h.lingering[userID] == state {
+			h.stopLingerLocked(userID)
+		}
+	})
+	h.lingering[userID] = state
+}
+
+// stopLingerLocked forgets a lingering user. Caller must hold h.mu.
+func (h *Hub) stopLingerLocked(userID uuid.UUID) {
+	state, ok := h.lingering[userID]
+	if !ok {
+		return
+	}
+	state.timer.Stop()
+	for _, roomID := range state.rooms {
+		delete(h.lingerRooms[roomID], userID)
+		if len(h.lingerRooms[roomID]) == 0 {
+			delete(h.lingerRooms, roomID)
 		}
 	}
+	delete(h.lingering, userID)
 }
 
-// Shutdown stops the hub event loop.
+// detachLocked removes a connection from every room. Caller must hold h.mu.
+func (h *Hub) detachLocked(client *Client) {
+	for roomID, members := range h.rooms {
+		delete(members, client)
+		if len(members) == 0 {
+			delete(h.rooms, roomID)
+		}
+	}
+}
+
+// Shutdown stops the hub event loop. With a cluster attached, this node's
+// presence entries are withdrawn and the subscription is closed.
 func (h *Hub) Shutdown() {
 	close(h.done)
+
+	if h.cluster == nil {
+		return
+	}
+
+	h.mu.RLock()
+	clients := make([]*Client, 0, len(h.clients))
+	for _, devices := range h.clients {
+		for _, client := range devices {
+			clients = append(clients, client)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, client := range clients {
+		h.clearClusterPresence(client)
+	}
+	if err := h.cluster.Close(); err != nil {
+		log.Warn().Err(err).Msg("failed to close cluster backend")
+	}
 }
 
 // RegisterClient registers a client with the hub.
@@ -142,78 +603,261 @@ func (h *Hub) UnregisterClient(client *Client) {
 	h.unregister <- client
 }
 
-// JoinRoom adds a client to a room.
+// JoinRoom adds a client connection to a room.
 func (h *Hub) JoinRoom(client *Client, roomID string) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
 	if _, ok := h.rooms[roomID]; !ok {
-		h.rooms[roomID] = make(map[uuid.UUID]*Client)
+		h.rooms[roomID] = make(map[*Client]struct{})
 	}
-	h.rooms[roomID][client.UserID] = client
+	h.rooms[roomID][client] = struct{}{}
 	log.Debug().Str("user_id", client.UserID.String()).Str("room", roomID).Msg("client joined room")
 }
 
-// LeaveRoom removes a client from a room.
+// LeaveRoom removes a client connection from a room.
 func (h *Hub) LeaveRoom(client *Client, roomID string) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
 	if members, ok := h.rooms[roomID]; ok {
-		delete(members, client.UserID)
+		delete(members, client)
 		if len(members) == 0 {
 			delete(h.rooms, roomID)
 		}
 	}
 }
 
-// SendToUser sends data directly to a specific user.
+// SendToUser sends data directly to every live connection of a user,
+// on this node and, with a cluster attached, on every other replica.
+// With an event log attached a durable event is logged even if the user is
+// offline, and is delivered and relayed once the recorder has sequenced it.
 func (h *Hub) SendToUser(userID uuid.UUID, data []byte) {
+	eventID := uuid.NewString()
+	if h.eventLog != nil && isDurableEvent(data) {
+		if h.queueRecord(&recordJob{userIDs: []uuid.UUID{userID}, eventID: eventID, data: data}) {
+			return
+		}
+	}
+
 	h.mu.RLock()
-	client, ok := h.clients[userID]
+	h.deliverToUserLocked(userID, data)
 	h.mu.RUnlock()
 
-	if ok {
+	h.publish(ClusterEvent{UserID: userID, Data: data, EventID: eventID})
+}
+
+// DisconnectDevice closes a device's connection to this node and, with a
+// cluster attached, to every other replica, e.g. once its session is revoked.
+func (h *Hub) DisconnectDevice(userID uuid.UUID, deviceID string) {
+	h.mu.RLock()
+	client := h.clients[userID][deviceID]
+	h.mu.RUnlock()
+	if client != nil {
+		h.UnregisterClient(client)
+	}
+
+	h.publish(ClusterEvent{UserID: userID, DeviceID: deviceID, Disconnect: true, EventID: uuid.NewString()})
+}
+
+// SendToDevice sends data to one connection of a user, on whichever node it
+// is connected to. Like SendToClient, the event is not sequenced.
+func (h *Hub) SendToDevice(userID uuid.UUID, deviceID string, data []byte) {
+	h.mu.RLock()
+	client := h.clients[userID][deviceID]
+	if client != nil {
+		h.deliverToClientLocked(client, data)
+	}
+	h.mu.RUnlock()
+
+	if client == nil {
+		h.publish(ClusterEvent{UserID: userID, DeviceID: deviceID, Data: data, EventID: uuid.NewString()})
+	}
+}
+
+// SendToNode hands data to the node handler of one replica: directly when
+// nodeID is this node, otherwise over the cluster.
+func (h *Hub) SendToNode(nodeID string, data []byte) {
+	if nodeID == h.NodeID() {
+		h.queueNodeMessage(data)
+		return
+	}
+	h.publish(ClusterEvent{Node: nodeID, Data: data, EventID: uuid.NewString()})
+}
+
+// queueNodeMessage hands data to the node handler without blocking.
+func (h *Hub) queueNodeMessage(data []byte) {
+	select {
+	case h.nodeMessages <- data:
+	default:
+		log.Warn().Msg("node message queue full, dropping message")
+	}
+}
+
+// runNodeHandler passes queued node messages to the node handler.
+func (h *Hub) runNodeHandler() {
+	for {
 		select {
-		case client.Send <- data:
-		default:
-			log.Warn().Str("user_id", userID.String()).Msg("send to user: buffer full")
+		case <-h.done:
+			return
+		case data := <-h.nodeMessages:
+			h.mu.RLock()
+			handler := h.nodeHandler
+			h.mu.RUnlock()
+			if handler != nil {
+				handler(data)
+			}
 		}
 	}
 }
 
-// SendToRoom broadcasts data to all clients in a room, optionally excluding one.
+// SendToRoom broadcasts data to all clients in a room, optionally excluding one user.
+// Every connection of the excluded user is skipped.
 func (h *Hub) SendToRoom(roomID string, data []byte, excludeUserID uuid.UUID) {
+	eventID := uuid.NewString()
 	h.broadcast <- &BroadcastMessage{
 		Room:    roomID,
 		Data:    data,
 		Exclude: excludeUserID,
+		EventID: eventID,
 	}
+
+	h.publish(ClusterEvent{Room: roomID, Exclude: excludeUserID, Data: data, EventID: eventID})
 }
 
-// IsOnline checks if a user is currently connected.
-func (h *Hub) IsOnline(userID uuid.UUID) bool {
+// replayPayload is the reply to a client's resume request.
+type replayPayload struct {
+	Events   []LoggedEvent `json:"events"`
+	Complete bool          `json:"complete"`
+}
+
+// Replay sends a client every logged event after afterSeq in a single resume
+// message. Complete is false when the gap can no longer be filled (or no event
+// log is configured), in which case the client should refetch its state.
+func (h *Hub) Replay(client *Client, afterSeq int64) error {
+	payload := replayPayload{Events: []LoggedEvent{}}
+	if h.eventLog != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+		defer cancel()
+
+		events, complete, err := h.eventLog.Since(ctx, client.UserID, afterSeq)
+		if err != nil {
+			return fmt.Errorf("read event log: %w", err)
+		}
+		payload.Events = events
+		payload.Complete = complete
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal replay payload: %w", err)
+	}
+	data, err := json.Marshal(WSMessage{Type: WSTypeResume, Payload: payloadBytes})
+	if err != nil {
+		return fmt.Errorf("marshal replay message: %w", err)
+	}
+
 	h.mu.RLock()
 	defer h.mu.RUnlock()
-	_, ok := h.clients[userID]
-	return ok
+	if h.clients[client.UserID][client.DeviceID] != client {
+		return nil
+	}
+	select {
+	case client.Send <- data:
+	default:
+		log.Warn().Str("user_id", client.UserID.String()).Msg("replay: client send buffer full")
+	}
+	return nil
 }
 
-// GetOnlineUsers returns the list of online user IDs from the given set.
-func (h *Hub) GetOnlineUsers(userIDs []uuid.UUID) []uuid.UUID {
+// SendToClient sends data to a single connection, if it is still registered.
+func (h *Hub) SendToClient(client *Client, data []byte) {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
+	if h.clients[client.UserID][client.DeviceID] != client {
+		return
+	}
+	h.deliverToClientLocked(client, data)
+}
+
+// deliverToClientLocked pushes data to one connection without blocking.
+// Caller must hold h.mu (read or write).
+func (h *Hub) deliverToClientLocked(client *Client, data []byte) {
+	select {
+	case client.Send <- data:
+	default:
+		log.Warn().Str("user_id", client.UserID.String()).Msg("send to client: buffer full")
+	}
+}
+
+// publish relays an event to the other replicas when a cluster is attached.
+func (h *Hub) publish(event ClusterEvent) {
+	if h.cluster == nil {
+		return
+	}
+	event.Origin = h.cluster.NodeID()
+
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+	if err := h.cluster.Publish(ctx, event); err != nil {
+		log.Warn().Err(err).Str("room", event.Room).Msg("failed to publish cluster event")
+	}
+}
+
+// IsOnline checks if a user has at least one live connection on any node.
+func (h *Hub) IsOnline(userID uuid.UUID) bool {
+	h.mu.RLock()
+	local := len(h.clients[userID]) > 0
+	h.mu.RUnlock()
+
+	if local || h.cluster == nil {
+		return local
+	}
+	return len(h.GetOnlineUsers([]uuid.UUID{userID})) > 0
+}
 
+// GetOnlineUsers returns the list of online user IDs from the given set.
+// With a cluster attached, users connected to other replicas are included.
+func (h *Hub) GetOnlineUsers(userIDs []uuid.UUID) []uuid.UUID {
+	h.mu.RLock()
 	online := make([]uuid.UUID, 0)
+	remote := make([]uuid.UUID, 0)
 	for _, id := range userIDs {
-		if _, ok := h.clients[id]; ok {
+		if len(h.clients[id]) > 0 {
 			online = append(online, id)
+		} else {
+			remote = append(remote, id)
 		}
 	}
-	return online
+	h.mu.RUnlock()
+
+	if h.cluster == nil || len(remote) == 0 {
+		return online
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), clusterOpTimeout)
+	defer cancel()
+	elsewhere, err := h.cluster.OnlineUsers(ctx, remote)
+	if err != nil {
+		log.Warn().Err(err).Msg("cluster presence lookup failed, using local presence")
+		return online
+	}
+	return append(online, elsewhere...)
 }
 
-// GetRoomMembers returns the user IDs of all clients in a room.
+// GetUserDevices returns the device IDs of a user's live connections.
+func (h *Hub) GetUserDevices(userID uuid.UUID) []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	devices := make([]string, 0, len(h.clients[userID]))
+	for deviceID := range h.clients[userID] {
+		devices = append(devices, deviceID)
+	}
+	return devices
+}
+
+// GetRoomMembers returns the distinct user IDs of all clients in a room.
 func (h *Hub) GetRoomMembers(roomID string) []uuid.UUID {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
@@ -223,9 +867,14 @@ func (h *Hub) GetRoomMembers(roomID string) []uuid.UUID {
 		return nil
 	}
 
+	seen := make(map[uuid.UUID]struct{}, len(members))
 	result := make([]uuid.UUID, 0, len(members))
-	for userID := range members {
-		result = append(result, userID)
+	for client := range members {
+		if _, dup := seen[client.UserID]; dup {
+			continue
+		}
+		seen[client.UserID] = struct{}{}
+		result = append(result, client.UserID)
 	}
 	return result
 }
@@ -141,6 +141,51 @@ func TestHub_SendToUser(t *testing.T) {
 	}
 }
 
+func TestHub_SendToClient(t *testing.T) {
+	hub := startHub(t)
+	userID := uuid.New()
+
+	phone := &ws.Client{UserID: userID, DeviceID: "phone", Send: make(chan []byte, 256), Hub: hub}
+	tablet := &ws.Client{UserID: userID, DeviceID: "tablet", Send: make(chan []byte, 256), Hub: hub}
+	hub.RegisterClient(phone)
+	hub.RegisterClient(tablet)
+	time.Sleep(10 * time.Millisecond)
+
+	hub.SendToClient(phone, []byte("only phone"))
+
+	select {
+	case msg := <-phone.Send:
+		assert.Equal(t, "only phone", string(msg))
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("phone should have received the message")
+	}
+	assert.Empty(t, tablet.Send)
+
+	// Unregistered connections are skipped
+	stale := &ws.Client{UserID: userID, DeviceID: "old", Send: make(chan []byte, 1), Hub: hub}
+	hub.SendToClient(stale, []byte("dropped"))
+	assert.Empty(t, stale.Send)
+}
+
+func TestHub_DisconnectDevice(t *testing.T) {
+	hub := startHub(t)
+	userID := uuid.New()
+
+	phone := &ws.Client{UserID: userID, DeviceID: "phone", Send: make(chan []byte, 1), Hub: hub}
+	tablet := &ws.Client{UserID: userID, DeviceID: "tablet", Send: make(chan []byte, 1), Hub: hub}
+	hub.RegisterClient(phone)
+	hub.RegisterClient(tablet)
+	time.Sleep(10 * time.Millisecond)
+
+	hub.DisconnectDevice(userID, "phone")
+	hub.DisconnectDevice(userID, "unknown")
+
+	_, ok := <-phone.Send
+	assert.False(t, ok)
+	assert.Equal(t, []string{"tablet"}, hub.GetUserDevices(userID))
+	assert.True(t, hub.IsOnline(userID))
+}
+
 func TestHub_GetOnlineUsers(t *testing.T) {
 	hub := startHub(t)
 
@@ -377,3 +422,134 @@ func TestHub_LeaveRoom_LastMember(t *testing.T) {
 	// LeaveRoom on nonexistent room should not panic
 	hub.LeaveRoom(client, "nonexistent")
 }
+
+func TestHub_MultiDevice_SendToUser(t *testing.T) {
+	hub := startHub(t)
+	userID := uuid.New()
+
+	phone := &ws.Client{UserID: userID, DeviceID: "phone", Send: make(chan []byte, 256), Hub: hub}
+	tablet := &ws.Client{UserID: userID, DeviceID: "tablet", Send: make(chan []byte, 256), Hub: hub}
+
+	hub.RegisterClient(phone)
+	hub.RegisterClient(tablet)
+	time.Sleep(10 * time.Millisecond)
+
+	assert.ElementsMatch(t, []string{"phone", "tablet"}, hub.GetUserDevices(userID))
+
+	hub.SendToUser(userID, []byte("both"))
+
+	for _, c := range []*ws.Client{phone, tablet} {
+		select {
+		case msg := <-c.Send:
+			assert.Equal(t, "both", string(msg))
+		case <-time.After(100 * time.Millisecond):
+			t.Fatalf("device %s should have received message", c.DeviceID)
+		}
+	}
+}
+
+func TestHub_MultiDevice_SendToRoom(t *testing.T) {
+	hub := startHub(t)
+	userID := uuid.New()
+	sender := uuid.New()
+
+	phone := &ws.Client{UserID: userID, DeviceID: "phone", Send: make(chan []byte, 256), Hub: hub}
+	tablet := &ws.Client{UserID: userID, DeviceID: "tablet", Send: make(chan []byte, 256), Hub: hub}
+
+	hub.RegisterClient(phone)
+	hub.RegisterClient(tablet)
+	time.Sleep(10 * time.Millisecond)
+
+	hub.JoinRoom(phone, "chat:multi")
+	hub.JoinRoom(tablet, "chat:multi")
+	assert.Equal(t, []uuid.UUID{userID}, hub.GetRoomMembers("chat:multi"))
+
+	hub.SendToRoom("chat:multi", []byte("room"), sender)
+
+	for _, c := range []*ws.Client{phone, tablet} {
+		select {
+		case msg := <-c.Send:
+			assert.Equal(t, "room", string(msg))
+		case <-time.After(100 * time.Millisecond):
+			t.Fatalf("device %s should have received room message", c.DeviceID)
+		}
+	}
+}
+
+func TestHub_MultiDevice_UnregisterKeepsOtherDevice(t *testing.T) {
+	hub := ws.NewHub()
+
+	var mu sync.Mutex
+	connects := 0
+	disconnected := false
+	hub.SetEventCallbacks(
+		func(id uuid.UUID) {
+			mu.Lock()
+			connects++
+			mu.Unlock()
+		},
+		func(id uuid.UUID) {
+			mu.Lock()
+			disconnected = true
+			mu.Unlock()
+		},
+	)
+
+	go hub.Run()
+	t.Cleanup(func() { hub.Shutdown() })
+
+	userID := uuid.New()
+	phone := &ws.Client{UserID: userID, DeviceID: "phone", Send: make(chan []byte, 256), Hub: hub}
+	tablet := &ws.Client{UserID: userID, DeviceID: "tablet", Send: make(chan []byte, 256), Hub: hub}
+
+	hub.RegisterClient(phone)
+	hub.RegisterClient(tablet)
+	time.Sleep(10 * time.Millisecond)
+
+	hub.JoinRoom(phone, "chat:keep")
+	hub.JoinRoom(tablet, "chat:keep")
+
+	hub.UnregisterClient(phone)
+	time.Sleep(10 * time.Millisecond)
+
+	assert.True(t, hub.IsOnline(userID))
+	assert.Equal(t, []uuid.UUID{userID}, hub.GetRoomMembers("chat:keep"))
+
+	hub.SendToRoom("chat:keep", []byte("still here"), uuid.Nil)
+	select {
+	case msg := <-tablet.Send:
+		assert.Equal(t, "still here", string(msg))
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("tablet should still receive room messages")
+	}
+
+	time.Sleep(6 * time.Second)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, connects, "onConnect should fire once per user")
+	assert.False(t, disconnected, "onDisconnect should not fire while another device is connected")
+}
+
+func TestHub_SameDevice_ReplacesStaleConnection(t *testing.T) {
+	hub := startHub(t)
+	userID := uuid.New()
+
+	stale := &ws.Client{UserID: userID, DeviceID: "phone", Send: make(chan []byte, 256), Hub: hub}
+	fresh := &ws.Client{UserID: userID, DeviceID: "phone", Send: make(chan []byte, 256), Hub: hub}
+
+	hub.RegisterClient(stale)
+	time.Sleep(10 * time.Millisecond)
+	hub.RegisterClient(fresh)
+	time.Sleep(10 * time.Millisecond)
+
+	// The stale connection's send channel is closed
+	_, ok := <-stale.Send
+	assert.False(t, ok)
+
+	// Late unregister of the stale connection must not drop the fresh one
+	hub.UnregisterClient(stale)
+	time.Sleep(10 * time.Millisecond)
+	assert.True(t, hub.IsOnline(userID))
+	assert.Equal(t, []string{"phone"}, hub.GetUserDevices(userID))
+}
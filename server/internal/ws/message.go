@@ -3,23 +3,34 @@ package ws
 import "encoding/json"
 
 // WSMessage is the envelope for all WebSocket messages.
+// Seq is set on server events that are kept in the user's replay log.
 type WSMessage struct {
+	Seq     int64           `json:"seq,omitempty"`
 	Type    string          `json:"type"`
 	Payload json.RawMessage `json:"payload"`
 }
 
 // WebSocket message types.
 const (
-	WSTypeMessage       = "message"
-	WSTypeMessageAck    = "message_ack"
-	WSTypeMessageStatus = "message_status"
-	WSTypeTyping        = "typing"
-	WSTypeOnlineStatus  = "online_status"
-	WSTypeReadReceipt   = "read_receipt"
-	WSTypeDocUpdate     = "doc_update"
-	WSTypeDocLock       = "doc_lock"
-	WSTypeDocJoin       = "doc_join"
-	WSTypeDocLeave      = "doc_leave"
-	WSTypeDocPresence   = "doc_presence"
-	WSTypeNotification  = "notification"
+	WSTypeMessage         = "message"
+	WSTypeMessageAck      = "message_ack"
+	WSTypeMessageStatus   = "message_status"
+	WSTypeReaction        = "message_reaction"
+	WSTypeMessageEdited   = "message_edited"
+	WSTypeMessagesExpired = "messages_expired"
+	WSTypePollUpdated     = "poll_updated"
+	WSTypeLocationUpdated = "location_updated"
+	WSTypeTyping          = "typing"
+	WSTypeOnlineStatus    = "online_status"
+	WSTypeReadReceipt     = "read_receipt"
+	WSTypeDocUpdate       = "doc_update"
+	WSTypeDocLock         = "doc_lock"
+	WSTypeDocJoin         = "doc_join"
+	WSTypeDocLeave        = "doc_leave"
+	WSTypeDocPresence     = "doc_presence"
+	WSTypeDocComment      = "doc_comment"
+	WSTypeDocSync         = "doc_sync"
+	WSTypeDocRejected     = "doc_rejected"
+	WSTypeNotification    = "notification"
+	WSTypeResume          = "resume"
 )
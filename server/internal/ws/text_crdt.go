@@ -0,0 +1,213 @@
+package ws
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// textBlockTypes are the block types whose content is edited character by
+// character through a TextCRDT.
+var textBlockTypes = map[string]bool{
+	"paragraph":     true,
+	"heading1":      true,
+	"heading2":      true,
+	"heading3":      true,
+	"bullet-list":   true,
+	"numbered-list": true,
+	"checklist":     true,
+	"quote":         true,
+	"callout":       true,
+	"code":          true,
+}
+
+// IsTextBlockType reports whether blocks of the given type carry a TextCRDT.
+// An empty type counts as text, since blocks created over REST reach the
+// CRDT without one.
+func IsTextBlockType(blockType string) bool {
+	return blockType == "" || textBlockTypes[blockType]
+}
+
+// textSeedNode is the node ID of characters the server creates itself: the
+// persisted content a block's text is seeded from, and the diffs of
+// whole-content updates. Clients seed a block the same way, so the seeded
+// characters get the same IDs on every replica that synced the same
+// DocumentCRDT epoch.
+var textSeedNode = uuid.Nil
+
+// CharID identifies one character of a block's text: the node that typed it
+// and its Lamport timestamp. A run of characters typed together takes
+// consecutive timestamps.
+type CharID struct {
+	Timestamp int64     `json:"ts"`
+	NodeID    uuid.UUID `json:"node"`
+}
+
+// follows reports whether c wins over o when both are inserted at the same
+// place, using the same ordering as LWWRegister.
+func (c CharID) follows(o CharID) bool {
+	if c.Timestamp != o.Timestamp {
+		return c.Timestamp > o.Timestamp
+	}
+	return c.NodeID.String() > o.NodeID.String()
+}
+
+func (c CharID) offset(n int) CharID {
+	return CharID{Timestamp: c.Timestamp + int64(n), NodeID: c.NodeID}
+}
+
+// TextOp is one incremental change to a block's text. An insert places Text
+// right after the character Origin (nil for the start of the block), its
+// characters taking the IDs ID, ID+1, ...; a delete removes the Length
+// characters with IDs ID, ID+1, ....
+type TextOp struct {
+	Type   string  `json:"type"` // "insert" or "delete"
+	ID     CharID  `json:"id"`
+	Origin *CharID `json:"origin,omitempty"`
+	Text   string  `json:"text,omitempty"`
+	Length int     `json:"length,omitempty"`
+}
+
+// TextChar is a single character of a TextCRDT. Deleted characters stay as
+// tombstones so later inserts can still find their origin.
+type TextChar struct {
+	ID      CharID  `json:"id"`
+	Origin  *CharID `json:"origin,omitempty"`
+	Value   string  `json:"value"`
+	Deleted bool    `json:"deleted,omitempty"`
+}
+
+// TextCRDT is a replicated growable array (RGA) holding a block's text.
+// Concurrent inserts after the same character are ordered by ID, newest
+// first, so every replica that has applied the same ops reads the same
+// text whatever order they arrived in. It is not safe for concurrent use;
+// DocumentCRDT guards it with its own lock.
+type TextCRDT struct {
+	Chars []TextChar `json:"chars"`
+}
+
+// NewTextCRDT creates a text seeded with content, each character an insert
+// by textSeedNode after the one before it.
+func NewTextCRDT(content string) *TextCRDT {
+	t := &TextCRDT{}
+	var origin *CharID
+	for i, r := range []rune(content) {
+		id := CharID{Timestamp: int64(i + 1), NodeID: textSeedNode}
+		t.Chars = append(t.Chars, TextChar{ID: id, Origin: origin, Value: string(r)})
+		origin = &id
+	}
+	return t
+}
+
+// String returns the visible text.
+func (t *TextCRDT) String() string {
+	var b strings.Builder
+	for _, c := range t.Chars {
+		if !c.Deleted {
+			b.WriteString(c.Value)
+		}
+	}
+	return b.String()
+}
+
+// Apply merges an op into the text. Characters already inserted or deleted
+// are skipped, and an insert whose origin is unknown is dropped. Returns
+// true if the text changed.
+func (t *TextCRDT) Apply(op TextOp) bool {
+	switch op.Type {
+	case "insert":
+		if op.Text == "" || (op.Origin != nil && t.indexOf(*op.Origin) < 0) {
+			return false
+		}
+		changed := false
+		origin := op.Origin
+		for i, r := range []rune(op.Text) {
+			id := op.ID.offset(i)
+			if t.indexOf(id) < 0 {
+				t.integrate(TextChar{ID: id, Origin: origin, Value: string(r)})
+				changed = true
+			}
+			origin = &id
+		}
+		return changed
+	case "delete":
+		changed := false
+		for i := 0; i < op.Length; i++ {
+			if idx := t.indexOf(op.ID.offset(i)); idx >= 0 && !t.Chars[idx].Deleted {
+				t.Chars[idx].Deleted = true
+				changed = true
+			}
+		}
+		return changed
+	default:
+		return false
+	}
+}
+
+// Diff returns the ops that turn the visible text into content: a delete for
+// each run of removed characters, then one insert whose characters take IDs
+// from start onwards. Only the middle that differs from the current text is
+// touched, so characters outside it keep their IDs.
+func (t *TextCRDT) Diff(content string, start CharID) []TextOp {
+	var visible []int
+	for i, c := range t.Chars {
+		if !c.Deleted {
+			visible = append(visible, i)
+		}
+	}
+	runes := []rune(content)
+
+	prefix := 0
+	for prefix < len(visible) && prefix < len(runes) && t.Chars[visible[prefix]].Value == string(runes[prefix]) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(visible)-prefix && suffix < len(runes)-prefix &&
+		t.Chars[visible[len(visible)-1-suffix]].Value == string(runes[len(runes)-1-suffix]) {
+		suffix++
+	}
+
+	var ops []TextOp
+	for _, idx := range visible[prefix : len(visible)-suffix] {
+		id := t.Chars[idx].ID
+		if n := len(ops); n > 0 && ops[n-1].ID.offset(ops[n-1].Length) == id {
+			ops[n-1].Length++
+			continue
+		}
+		ops = append(ops, TextOp{Type: "delete", ID: id, Length: 1})
+	}
+
+	if inserted := runes[prefix : len(runes)-suffix]; len(inserted) > 0 {
+		op := TextOp{Type: "insert", ID: start, Text: string(inserted)}
+		if prefix > 0 {
+			origin := t.Chars[visible[prefix-1]].ID
+			op.Origin = &origin
+		}
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+// integrate places a character after its origin, past any concurrent
+// inserts at the same place that win over it.
+func (t *TextCRDT) integrate(c TextChar) {
+	pos := 0
+	if c.Origin != nil {
+		pos = t.indexOf(*c.Origin) + 1
+	}
+	for pos < len(t.Chars) && t.Chars[pos].ID.follows(c.ID) {
+		pos++
+	}
+	t.Chars = append(t.Chars, TextChar{})
+	copy(t.Chars[pos+1:], t.Chars[pos:])
+	t.Chars[pos] = c
+}
+
+func (t *TextCRDT) indexOf(id CharID) int {
+	for i := range t.Chars {
+		if t.Chars[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
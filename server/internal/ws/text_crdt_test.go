@@ -0,0 +1,142 @@
+package ws
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextCRDT(t *testing.T) {
+	nodeA := uuid.MustParse("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+	nodeB := uuid.MustParse("bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb")
+	seedID := func(i int) *CharID { return &CharID{Timestamp: int64(i), NodeID: textSeedNode} }
+
+	t.Run("seeded text", func(t *testing.T) {
+		text := NewTextCRDT("Halo")
+		assert.Equal(t, "Halo", text.String())
+		require.Len(t, text.Chars, 4)
+		assert.Nil(t, text.Chars[0].Origin)
+		assert.Equal(t, seedID(3), text.Chars[3].Origin)
+		assert.Equal(t, NewTextCRDT("Halo").Chars, text.Chars)
+	})
+
+	t.Run("insert and delete", func(t *testing.T) {
+		text := NewTextCRDT("Halo")
+		require.True(t, text.Apply(TextOp{Type: "insert", ID: CharID{Timestamp: 100, NodeID: nodeA}, Origin: seedID(4), Text: " dunia"}))
+		assert.Equal(t, "Halo dunia", text.String())
+
+		require.True(t, text.Apply(TextOp{Type: "insert", ID: CharID{Timestamp: 200, NodeID: nodeA}, Text: ">"}))
+		assert.Equal(t, ">Halo dunia", text.String())
+
+		require.True(t, text.Apply(TextOp{Type: "delete", ID: CharID{Timestamp: 1, NodeID: textSeedNode}, Length: 2}))
+		assert.Equal(t, ">lo dunia", text.String())
+	})
+
+	t.Run("concurrent typing at the same place converges without interleaving", func(t *testing.T) {
+		opA := TextOp{Type: "insert", ID: CharID{Timestamp: 100, NodeID: nodeA}, Origin: seedID(4), Text: " dunia"}
+		opB := TextOp{Type: "insert", ID: CharID{Timestamp: 100, NodeID: nodeB}, Origin: seedID(4), Text: "!!"}
+
+		ab := NewTextCRDT("Halo")
+		ab.Apply(opA)
+		ab.Apply(opB)
+		ba := NewTextCRDT("Halo")
+		ba.Apply(opB)
+		ba.Apply(opA)
+
+		assert.Equal(t, "Halo!! dunia", ab.String())
+		assert.Equal(t, ab.String(), ba.String())
+	})
+
+	t.Run("typing after a concurrent insert keeps its place", func(t *testing.T) {
+		// B saw A's " dunia" and typed after it; C typed at the old end concurrently
+		opA := TextOp{Type: "insert", ID: CharID{Timestamp: 100, NodeID: nodeA}, Origin: seedID(4), Text: " dunia"}
+		opB := TextOp{Type: "insert", ID: CharID{Timestamp: 200, NodeID: nodeB}, Origin: &CharID{Timestamp: 105, NodeID: nodeA}, Text: "!"}
+		opC := TextOp{Type: "insert", ID: CharID{Timestamp: 150, NodeID: uuid.New()}, Origin: seedID(4), Text: ","}
+
+		orders := [][]TextOp{{opA, opB, opC}, {opA, opC, opB}, {opC, opA, opB}}
+		var results []string
+		for _, ops := range orders {
+			text := NewTextCRDT("Halo")
+			for _, op := range ops {
+				text.Apply(op)
+			}
+			results = append(results, text.String())
+		}
+		assert.Equal(t, "Halo, dunia!", results[0])
+		assert.Equal(t, results[0], results[1])
+		assert.Equal(t, results[0], results[2])
+	})
+
+	t.Run("concurrent delete and insert", func(t *testing.T) {
+		del := TextOp{Type: "delete", ID: CharID{Timestamp: 3, NodeID: textSeedNode}, Length: 2}
+		ins := TextOp{Type: "insert", ID: CharID{Timestamp: 100, NodeID: nodeA}, Origin: seedID(4), Text: "!"}
+
+		first := NewTextCRDT("Halo")
+		first.Apply(del)
+		first.Apply(ins)
+		second := NewTextCRDT("Halo")
+		second.Apply(ins)
+		second.Apply(del)
+
+		assert.Equal(t, "Ha!", first.String())
+		assert.Equal(t, first.String(), second.String())
+	})
+
+	t.Run("replays and unknown origins are ignored", func(t *testing.T) {
+		text := NewTextCRDT("Halo")
+		op := TextOp{Type: "insert", ID: CharID{Timestamp: 100, NodeID: nodeA}, Origin: seedID(4), Text: "!"}
+		assert.True(t, text.Apply(op))
+		assert.False(t, text.Apply(op))
+
+		del := TextOp{Type: "delete", ID: CharID{Timestamp: 1, NodeID: textSeedNode}, Length: 1}
+		assert.True(t, text.Apply(del))
+		assert.False(t, text.Apply(del))
+
+		assert.False(t, text.Apply(TextOp{Type: "insert", ID: CharID{Timestamp: 300, NodeID: nodeA}, Origin: &CharID{Timestamp: 999, NodeID: nodeB}, Text: "x"}))
+		assert.False(t, text.Apply(TextOp{Type: "insert", ID: CharID{Timestamp: 300, NodeID: nodeA}}))
+		assert.False(t, text.Apply(TextOp{Type: "replace"}))
+		assert.Equal(t, "alo!", text.String())
+	})
+
+	t.Run("diff only touches the changed middle", func(t *testing.T) {
+		text := NewTextCRDT("Halo dunia")
+		ops := text.Diff("Halo semua dunia", CharID{Timestamp: 100, NodeID: nodeA})
+		require.Len(t, ops, 1)
+		assert.Equal(t, "insert", ops[0].Type)
+		assert.Equal(t, seedID(5), ops[0].Origin)
+		assert.Equal(t, "semua ", ops[0].Text)
+
+		ops = text.Diff("Hai dunia", CharID{Timestamp: 100, NodeID: nodeA})
+		require.Len(t, ops, 2)
+		assert.Equal(t, TextOp{Type: "delete", ID: *seedID(3), Length: 2}, ops[0])
+		assert.Equal(t, "i", ops[1].Text)
+
+		for _, op := range ops {
+			text.Apply(op)
+		}
+		assert.Equal(t, "Hai dunia", text.String())
+		assert.Empty(t, text.Diff("Hai dunia", CharID{Timestamp: 200, NodeID: nodeA}))
+	})
+
+	t.Run("diff groups deletes by ID run", func(t *testing.T) {
+		text := NewTextCRDT("ab")
+		text.Apply(TextOp{Type: "insert", ID: CharID{Timestamp: 100, NodeID: nodeA}, Origin: seedID(1), Text: "xy"})
+		require.Equal(t, "axyb", text.String())
+
+		ops := text.Diff("", CharID{Timestamp: 200, NodeID: nodeA})
+		require.Len(t, ops, 3)
+		assert.Equal(t, 1, ops[0].Length)
+		assert.Equal(t, 2, ops[1].Length)
+		assert.Equal(t, 1, ops[2].Length)
+	})
+}
+
+func TestIsTextBlockType(t *testing.T) {
+	assert.True(t, IsTextBlockType("paragraph"))
+	assert.True(t, IsTextBlockType("code"))
+	assert.True(t, IsTextBlockType(""))
+	assert.False(t, IsTextBlockType("table"))
+	assert.False(t, IsTextBlockType("divider"))
+}
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS topic_message_edits;
+DROP TABLE IF EXISTS message_edits;
+
+ALTER TABLE topic_messages DROP COLUMN IF EXISTS edited_at;
+ALTER TABLE messages DROP COLUMN IF EXISTS edited_at;
@@ -0,0 +1,21 @@
+ALTER TABLE messages ADD COLUMN edited_at TIMESTAMPTZ;
+ALTER TABLE topic_messages ADD COLUMN edited_at TIMESTAMPTZ;
+
+-- Previous versions of edited messages, newest last
+CREATE TABLE message_edits (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+  content TEXT NOT NULL,
+  edited_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_message_edits_message_id ON message_edits(message_id, edited_at);
+
+CREATE TABLE topic_message_edits (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  message_id UUID NOT NULL REFERENCES topic_messages(id) ON DELETE CASCADE,
+  content TEXT NOT NULL,
+  edited_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_topic_message_edits_message_id ON topic_message_edits(message_id, edited_at);
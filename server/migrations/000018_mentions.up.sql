@@ -0,0 +1,15 @@
+-- One row per user mentioned in a chat message, topic message or document block
+CREATE TABLE mentions (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  mentioned_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  source_type VARCHAR(10) NOT NULL
+    CHECK(source_type IN ('chat', 'topic', 'document')),
+  source_id UUID NOT NULL,
+  item_id UUID NOT NULL,
+  preview TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE (item_id, user_id)
+);
+
+CREATE INDEX idx_mentions_user_id ON mentions(user_id, created_at DESC);
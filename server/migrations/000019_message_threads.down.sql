@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS thread_followers;
+
+DROP INDEX IF EXISTS idx_messages_thread_id;
+ALTER TABLE messages DROP COLUMN IF EXISTS thread_id;
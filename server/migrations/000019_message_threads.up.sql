@@ -0,0 +1,20 @@
+-- Root of the reply chain a message belongs to; NULL for messages outside a thread
+ALTER TABLE messages ADD COLUMN thread_id UUID REFERENCES messages(id) ON DELETE SET NULL;
+
+WITH RECURSIVE chain AS (
+  SELECT id, id AS root FROM messages WHERE reply_to_id IS NULL
+  UNION ALL
+  SELECT m.id, c.root FROM messages m JOIN chain c ON m.reply_to_id = c.id
+)
+UPDATE messages SET thread_id = chain.root
+FROM chain
+WHERE messages.id = chain.id AND chain.root <> messages.id;
+
+CREATE INDEX idx_messages_thread_id ON messages(thread_id, created_at) WHERE thread_id IS NOT NULL;
+
+CREATE TABLE thread_followers (
+  message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  followed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (message_id, user_id)
+);
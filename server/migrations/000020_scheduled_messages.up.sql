@@ -0,0 +1,22 @@
+-- Messages queued to be sent into a chat at a later time
+CREATE TABLE scheduled_messages (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  chat_id UUID NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+  sender_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  content TEXT NOT NULL,
+  reply_to_id UUID REFERENCES messages(id) ON DELETE SET NULL,
+  type VARCHAR(20) NOT NULL DEFAULT 'text',
+  metadata JSONB,
+  send_at TIMESTAMPTZ NOT NULL,
+  status VARCHAR(10) NOT NULL DEFAULT 'pending'
+    CHECK(status IN ('pending', 'sending', 'sent', 'failed', 'cancelled')),
+  attempts INT NOT NULL DEFAULT 0,
+  claimed_at TIMESTAMPTZ,
+  message_id UUID REFERENCES messages(id) ON DELETE SET NULL,
+  error TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_scheduled_messages_due ON scheduled_messages(send_at) WHERE status IN ('pending', 'sending');
+CREATE INDEX idx_scheduled_messages_chat_sender ON scheduled_messages(chat_id, sender_id, send_at);
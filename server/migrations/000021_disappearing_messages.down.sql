@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_messages_media_id;
+DROP INDEX IF EXISTS idx_messages_expires_at;
+ALTER TABLE messages DROP COLUMN IF EXISTS expires_at;
+
+ALTER TABLE chats DROP COLUMN IF EXISTS disappearing_timer;
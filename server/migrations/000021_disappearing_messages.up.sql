@@ -0,0 +1,12 @@
+-- Seconds a new message in the chat lives before it is deleted; 0 keeps messages forever
+ALTER TABLE chats ADD COLUMN disappearing_timer INTEGER NOT NULL DEFAULT 0
+  CHECK(disappearing_timer >= 0);
+
+-- Stamped from the chat's timer when the message is sent, so changing the timer
+-- only affects messages sent afterwards
+ALTER TABLE messages ADD COLUMN expires_at TIMESTAMPTZ;
+
+CREATE INDEX idx_messages_expires_at ON messages(expires_at) WHERE expires_at IS NOT NULL;
+
+-- Lets the reaper check whether an expired message's media is still used by another message
+CREATE INDEX idx_messages_media_id ON messages((metadata->>'id')) WHERE type IN ('image', 'file');
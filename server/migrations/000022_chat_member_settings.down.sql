@@ -0,0 +1,11 @@
+ALTER TABLE chats ADD COLUMN pinned_at TIMESTAMPTZ;
+
+UPDATE chats c SET pinned_at = (
+  SELECT MAX(cm.pinned_at) FROM chat_members cm WHERE cm.chat_id = c.id
+);
+
+ALTER TABLE chat_members
+  DROP COLUMN IF EXISTS notification_sound,
+  DROP COLUMN IF EXISTS muted_until,
+  DROP COLUMN IF EXISTS archived_at,
+  DROP COLUMN IF EXISTS pinned_at;
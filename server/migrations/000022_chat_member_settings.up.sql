@@ -0,0 +1,13 @@
+-- Each member's own preferences for a chat
+ALTER TABLE chat_members
+  ADD COLUMN pinned_at TIMESTAMPTZ,
+  ADD COLUMN archived_at TIMESTAMPTZ,
+  ADD COLUMN muted_until TIMESTAMPTZ,
+  ADD COLUMN notification_sound VARCHAR(100) NOT NULL DEFAULT '';
+
+-- Pins used to be shared by every member; keep them for everyone who saw them
+UPDATE chat_members cm SET pinned_at = c.pinned_at
+FROM chats c
+WHERE c.id = cm.chat_id AND c.pinned_at IS NOT NULL;
+
+ALTER TABLE chats DROP COLUMN pinned_at;
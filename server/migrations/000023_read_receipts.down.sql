@@ -0,0 +1,7 @@
+ALTER TABLE chat_members
+  DROP COLUMN IF EXISTS last_read_at,
+  DROP COLUMN IF EXISTS last_read_message_id;
+
+ALTER TABLE message_status
+  DROP COLUMN IF EXISTS read_at,
+  DROP COLUMN IF EXISTS delivered_at;
@@ -0,0 +1,22 @@
+-- When each recipient received and read a message
+ALTER TABLE message_status
+  ADD COLUMN delivered_at TIMESTAMPTZ,
+  ADD COLUMN read_at TIMESTAMPTZ;
+
+UPDATE message_status SET delivered_at = updated_at WHERE status IN ('delivered', 'read');
+UPDATE message_status SET read_at = updated_at WHERE status = 'read';
+
+-- Each member's read position; messages after it are unread
+ALTER TABLE chat_members
+  ADD COLUMN last_read_message_id UUID REFERENCES messages(id) ON DELETE SET NULL,
+  ADD COLUMN last_read_at TIMESTAMPTZ;
+
+UPDATE chat_members cm SET last_read_at = r.read_up_to
+FROM (
+  SELECT m.chat_id, ms.user_id, MAX(m.created_at) AS read_up_to
+  FROM message_status ms
+  JOIN messages m ON m.id = ms.message_id
+  WHERE ms.status = 'read'
+  GROUP BY m.chat_id, ms.user_id
+) r
+WHERE r.chat_id = cm.chat_id AND r.user_id = cm.user_id;
@@ -0,0 +1,27 @@
+CREATE TABLE user_blocks (
+  blocker_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  blocked_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (blocker_id, blocked_id),
+  CHECK (blocker_id <> blocked_id)
+);
+
+CREATE INDEX idx_user_blocks_blocked_id ON user_blocks(blocked_id);
+
+-- Moderation queue. Reported messages are copied into the report so the
+-- evidence survives the sender deleting them or the chat expiring them.
+CREATE TABLE abuse_reports (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  reported_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  chat_id UUID REFERENCES chats(id) ON DELETE SET NULL,
+  reason VARCHAR(32) NOT NULL,
+  details TEXT NOT NULL DEFAULT '',
+  messages JSONB NOT NULL DEFAULT '[]',
+  status VARCHAR(16) NOT NULL DEFAULT 'open',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  reviewed_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_abuse_reports_status ON abuse_reports(status, created_at);
+CREATE INDEX idx_abuse_reports_reported_user_id ON abuse_reports(reported_user_id);
@@ -0,0 +1,29 @@
+CREATE TABLE group_invites (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  chat_id UUID NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+  code VARCHAR(32) NOT NULL UNIQUE,
+  created_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  requires_approval BOOLEAN NOT NULL DEFAULT FALSE,
+  max_uses INT CHECK (max_uses > 0),
+  use_count INT NOT NULL DEFAULT 0,
+  expires_at TIMESTAMPTZ,
+  revoked_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_group_invites_chat_id ON group_invites(chat_id, created_at DESC);
+
+CREATE TABLE group_join_requests (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  chat_id UUID NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  invite_id UUID NOT NULL REFERENCES group_invites(id) ON DELETE CASCADE,
+  status VARCHAR(16) NOT NULL DEFAULT 'pending',
+  decided_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  decided_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- A user has at most one pending request per group
+CREATE UNIQUE INDEX idx_group_join_requests_pending
+  ON group_join_requests(chat_id, user_id) WHERE status = 'pending';
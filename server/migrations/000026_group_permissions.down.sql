@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_chat_members_owner;
+
+UPDATE chat_members SET role = 'admin' WHERE role = 'owner';
+
+ALTER TABLE chat_members DROP CONSTRAINT IF EXISTS chat_members_role_check;
+ALTER TABLE chat_members ADD CONSTRAINT chat_members_role_check
+  CHECK(role IN ('admin', 'member'));
+
+ALTER TABLE chats
+  DROP COLUMN IF EXISTS only_admins_add_members,
+  DROP COLUMN IF EXISTS only_admins_create_topics,
+  DROP COLUMN IF EXISTS only_admins_edit_info,
+  DROP COLUMN IF EXISTS only_admins_send;
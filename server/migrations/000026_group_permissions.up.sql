@@ -0,0 +1,18 @@
+-- Group-level settings restricting actions to admins
+ALTER TABLE chats
+  ADD COLUMN only_admins_send BOOLEAN NOT NULL DEFAULT FALSE,
+  ADD COLUMN only_admins_edit_info BOOLEAN NOT NULL DEFAULT TRUE,
+  ADD COLUMN only_admins_create_topics BOOLEAN NOT NULL DEFAULT FALSE,
+  ADD COLUMN only_admins_add_members BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- Owner: an admin that other admins cannot remove or demote
+ALTER TABLE chat_members DROP CONSTRAINT IF EXISTS chat_members_role_check;
+ALTER TABLE chat_members ADD CONSTRAINT chat_members_role_check
+  CHECK(role IN ('owner', 'admin', 'member'));
+
+CREATE UNIQUE INDEX idx_chat_members_owner ON chat_members(chat_id) WHERE role = 'owner';
+
+-- Existing group creators become owners
+UPDATE chat_members cm SET role = 'owner'
+FROM chats c
+WHERE c.id = cm.chat_id AND c.type = 'group' AND cm.user_id = c.created_by;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS broadcast_messages;
+DROP TABLE IF EXISTS broadcasts;
+DROP TABLE IF EXISTS broadcast_list_recipients;
+DROP TABLE IF EXISTS broadcast_lists;
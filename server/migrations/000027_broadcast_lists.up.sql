@@ -0,0 +1,38 @@
+-- Named sets of contacts a user sends the same message to
+CREATE TABLE broadcast_lists (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name VARCHAR(100) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_broadcast_lists_owner ON broadcast_lists(owner_id, created_at DESC);
+
+CREATE TABLE broadcast_list_recipients (
+  list_id UUID NOT NULL REFERENCES broadcast_lists(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  added_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (list_id, user_id)
+);
+
+-- One send to a list; each delivered copy is a personal chat message
+CREATE TABLE broadcasts (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  list_id UUID NOT NULL REFERENCES broadcast_lists(id) ON DELETE CASCADE,
+  sender_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  content TEXT NOT NULL,
+  type VARCHAR(20) NOT NULL DEFAULT 'text',
+  metadata JSONB,
+  skipped_count INT NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_broadcasts_list ON broadcasts(list_id, created_at DESC);
+
+CREATE TABLE broadcast_messages (
+  broadcast_id UUID NOT NULL REFERENCES broadcasts(id) ON DELETE CASCADE,
+  message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+  recipient_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  PRIMARY KEY (broadcast_id, message_id)
+);
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS poll_votes;
+DROP TABLE IF EXISTS poll_options;
+DROP TABLE IF EXISTS polls;
+
+DELETE FROM topic_messages WHERE type = 'poll';
+ALTER TABLE topic_messages DROP CONSTRAINT IF EXISTS topic_messages_type_check;
+ALTER TABLE topic_messages ADD CONSTRAINT topic_messages_type_check
+  CHECK(type IN ('text', 'image', 'file', 'document_card', 'system'));
+
+DELETE FROM messages WHERE type = 'poll';
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS messages_type_check;
+ALTER TABLE messages ADD CONSTRAINT messages_type_check
+  CHECK(type IN ('text', 'image', 'file', 'document_card', 'system'));
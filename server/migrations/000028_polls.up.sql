@@ -0,0 +1,47 @@
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS messages_type_check;
+ALTER TABLE messages ADD CONSTRAINT messages_type_check
+  CHECK(type IN ('text', 'image', 'file', 'document_card', 'system', 'poll'));
+
+ALTER TABLE topic_messages DROP CONSTRAINT IF EXISTS topic_messages_type_check;
+ALTER TABLE topic_messages ADD CONSTRAINT topic_messages_type_check
+  CHECK(type IN ('text', 'image', 'file', 'document_card', 'system', 'poll'));
+
+-- A poll belongs to exactly one chat or topic. Its message is attached
+-- once sent, and the poll goes away with it.
+CREATE TABLE polls (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  chat_id UUID REFERENCES chats(id) ON DELETE CASCADE,
+  topic_id UUID REFERENCES topics(id) ON DELETE CASCADE,
+  message_id UUID UNIQUE REFERENCES messages(id) ON DELETE CASCADE,
+  topic_message_id UUID UNIQUE REFERENCES topic_messages(id) ON DELETE CASCADE,
+  creator_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  question VARCHAR(300) NOT NULL,
+  multiple_choice BOOLEAN NOT NULL DEFAULT FALSE,
+  anonymous BOOLEAN NOT NULL DEFAULT FALSE,
+  closes_at TIMESTAMPTZ,
+  closed_at TIMESTAMPTZ,
+  final_voters INT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CHECK ((chat_id IS NULL) <> (topic_id IS NULL))
+);
+
+-- final_votes and polls.final_voters are written when the poll closes so the
+-- results no longer change, even if voters later delete their accounts.
+CREATE TABLE poll_options (
+  poll_id UUID NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+  position INT NOT NULL CHECK (position >= 0),
+  text VARCHAR(100) NOT NULL,
+  final_votes INT,
+  PRIMARY KEY (poll_id, position)
+);
+
+CREATE TABLE poll_votes (
+  poll_id UUID NOT NULL,
+  position INT NOT NULL,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (poll_id, position, user_id),
+  FOREIGN KEY (poll_id, position) REFERENCES poll_options(poll_id, position) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_poll_votes_user ON poll_votes(poll_id, user_id);
@@ -0,0 +1,4 @@
+DELETE FROM messages WHERE type IN ('location', 'contact');
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS messages_type_check;
+ALTER TABLE messages ADD CONSTRAINT messages_type_check
+  CHECK(type IN ('text', 'image', 'file', 'document_card', 'system', 'poll'));
@@ -0,0 +1,3 @@
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS messages_type_check;
+ALTER TABLE messages ADD CONSTRAINT messages_type_check
+  CHECK(type IN ('text', 'image', 'file', 'document_card', 'system', 'poll', 'location', 'contact'));
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS document_versions;
+
+ALTER TABLE document_history ALTER COLUMN details DROP NOT NULL;
+ALTER TABLE document_history ALTER COLUMN details DROP DEFAULT;
+ALTER TABLE document_history ALTER COLUMN details TYPE JSONB USING to_jsonb(details);
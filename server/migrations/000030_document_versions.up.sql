@@ -0,0 +1,24 @@
+-- History details are plain descriptions, not JSON
+ALTER TABLE document_history ALTER COLUMN details TYPE TEXT USING COALESCE(details #>> '{}', '');
+ALTER TABLE document_history ALTER COLUMN details SET DEFAULT '';
+ALTER TABLE document_history ALTER COLUMN details SET NOT NULL;
+
+-- A version is a full snapshot of a document's title, icon, cover and
+-- blocks. content_hash lets automatic snapshots skip unchanged content.
+CREATE TABLE document_versions (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+  version INTEGER NOT NULL,
+  title VARCHAR(200) NOT NULL,
+  icon VARCHAR(10) NOT NULL,
+  cover VARCHAR(50),
+  blocks JSONB NOT NULL DEFAULT '[]',
+  content_hash VARCHAR(64) NOT NULL,
+  reason VARCHAR(20) NOT NULL
+    CHECK(reason IN ('manual', 'edited', 'locked', 'signed', 'restored')),
+  label VARCHAR(100) NOT NULL DEFAULT '',
+  restored_from INTEGER,
+  created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE (document_id, version)
+);
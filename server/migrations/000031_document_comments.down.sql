@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS document_comments;
+
+DELETE FROM mentions WHERE source_type = 'comment';
+ALTER TABLE mentions DROP CONSTRAINT IF EXISTS mentions_source_type_check;
+ALTER TABLE mentions ADD CONSTRAINT mentions_source_type_check
+  CHECK(source_type IN ('chat', 'topic', 'document'));
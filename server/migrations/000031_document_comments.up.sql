@@ -0,0 +1,27 @@
+ALTER TABLE mentions DROP CONSTRAINT IF EXISTS mentions_source_type_check;
+ALTER TABLE mentions ADD CONSTRAINT mentions_source_type_check
+  CHECK(source_type IN ('chat', 'topic', 'document', 'comment'));
+
+-- Comment threads anchored to a block. block_id has no foreign key so a
+-- thread comes back with its block when a document version is restored.
+-- Replies point at the thread's first comment; only threads are resolved.
+CREATE TABLE document_comments (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+  block_id UUID NOT NULL,
+  parent_id UUID REFERENCES document_comments(id) ON DELETE CASCADE,
+  author_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  content TEXT NOT NULL,
+  range_start INTEGER,
+  range_end INTEGER,
+  quoted_text TEXT NOT NULL DEFAULT '',
+  resolved_at TIMESTAMPTZ,
+  resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CHECK ((range_start IS NULL) = (range_end IS NULL)),
+  CHECK (range_start IS NULL OR (range_start >= 0 AND range_end > range_start))
+);
+
+CREATE INDEX idx_document_comments_document_id ON document_comments(document_id, block_id, created_at);
+CREATE INDEX idx_document_comments_parent_id ON document_comments(parent_id);